@@ -38,6 +38,9 @@ func genLDFlags(version string) string {
 	ldflagsStr = ldflagsStr + "-X github.com/minio/mc/cmd.ReleaseTag=" + releaseTag + " "
 	ldflagsStr = ldflagsStr + "-X github.com/minio/mc/cmd.CommitID=" + commitID() + " "
 	ldflagsStr = ldflagsStr + "-X github.com/minio/mc/cmd.ShortCommitID=" + commitID()[:12]
+	if endpoint := os.Getenv("MC_SERVER_ENDPOINT"); endpoint != "" {
+		ldflagsStr = ldflagsStr + " -X github.com/minio/mc/cmd.DefaultServerEndpoint=" + endpoint
+	}
 	return ldflagsStr
 }
 
@@ -82,6 +82,7 @@ func (a *accounter) writer() {
 // accountStat cantainer for current stats captured.
 type accountStat struct {
 	Status      string  `json:"status"`
+	Type        string  `json:"type"`
 	Total       int64   `json:"total"`
 	Transferred int64   `json:"transferred"`
 	Speed       float64 `json:"speed"`
@@ -89,6 +90,7 @@ type accountStat struct {
 
 func (c accountStat) JSON() string {
 	c.Status = "success"
+	c.Type = "summary"
 	accountMessageBytes, e := json.MarshalIndent(c, "", " ")
 	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
 
@@ -96,14 +98,14 @@ func (c accountStat) JSON() string {
 }
 
 func (c accountStat) String() string {
-	speedBox := pb.Format(int64(c.Speed)).To(pb.U_BYTES).String()
+	speedBox := pb.Format(int64(c.Speed)).To(globalUnits.pbUnits()).String()
 	if speedBox == "" {
 		speedBox = "0 MB"
 	} else {
 		speedBox = speedBox + "/s"
 	}
-	message := fmt.Sprintf("Total: %s, Transferred: %s, Speed: %s", pb.Format(c.Total).To(pb.U_BYTES),
-		pb.Format(c.Transferred).To(pb.U_BYTES), speedBox)
+	message := fmt.Sprintf("Total: %s, Transferred: %s, Speed: %s", pb.Format(c.Total).To(globalUnits.pbUnits()),
+		pb.Format(c.Transferred).To(globalUnits.pbUnits()), speedBox)
 	return message
 }
 
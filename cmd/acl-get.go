@@ -0,0 +1,125 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+var aclGetCmd = cli.Command{
+	Name:         "get",
+	Usage:        "show the ACL of an object",
+	Action:       mainACLGet,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Show the owner of an object and the grants of its ACL, with the canned ACL
+  they amount to if any.
+
+EXAMPLES:
+  1. Show the ACL of an object.
+     {{.Prompt}} {{.HelpName}} s3/mybucket/report.pdf
+
+  2. Show the ACL of an object in JSON.
+     {{.Prompt}} {{.HelpName}} --json s3/mybucket/report.pdf
+`,
+}
+
+// aclGetMessage is the ACL of an object.
+type aclGetMessage struct {
+	Status    string     `json:"status"`
+	URL       string     `json:"url"`
+	Owner     aclOwner   `json:"owner"`
+	CannedACL string     `json:"cannedACL,omitempty"`
+	Grants    []aclGrant `json:"grants"`
+}
+
+func (m aclGetMessage) String() string {
+	owner := m.Owner.ID
+	if m.Owner.DisplayName != "" {
+		owner += " (" + m.Owner.DisplayName + ")"
+	}
+	canned := m.CannedACL
+	if canned == "" {
+		canned = "none"
+	}
+	strs := []string{
+		fmt.Sprintf("%v %v", console.Colorize("Key", "Name      :"), console.Colorize("Name", m.URL)),
+		fmt.Sprintf("%v %v", console.Colorize("Key", "Owner     :"), owner),
+		fmt.Sprintf("%v %v", console.Colorize("Key", "Canned ACL:"), console.Colorize("Value", canned)),
+		console.Colorize("Key", "Grants    :"),
+	}
+	for _, g := range m.Grants {
+		strs = append(strs, fmt.Sprintf("  %-12s %v", g.Permission, g.Grantee))
+	}
+	return strings.Join(strs, "\n")
+}
+
+func (m aclGetMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+func mainACLGet(cliCtx *cli.Context) error {
+	ctx, cancelACLGet := context.WithCancel(globalContext)
+	defer cancelACLGet()
+
+	console.SetColor("Name", color.New(color.Bold, color.FgCyan))
+	console.SetColor("Key", color.New(color.FgGreen))
+	console.SetColor("Value", color.New(color.FgYellow))
+
+	if len(cliCtx.Args()) != 1 {
+		showCommandHelpAndExit(cliCtx, globalErrorExitStatus)
+	}
+	targetURL := cliCtx.Args().Get(0)
+
+	clnt, err := newClient(targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+	defer clnt.Close()
+
+	policy, err := clnt.GetObjectACL(ctx)
+	fatalIf(err.Trace(targetURL), "Unable to get the ACL of `"+targetURL+"`.")
+
+	printMsg(aclGetMessage{
+		Status:    "success",
+		URL:       targetURL,
+		Owner:     policy.Owner,
+		CannedACL: policy.cannedACL(),
+		Grants:    policy.Grants,
+	})
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// aclGrantFlags are the flags granting a permission, as the x-amz-grant-*
+// headers.
+var aclGrantFlags = []struct {
+	name, permission string
+}{
+	{"grant-read", aclRead},
+	{"grant-read-acp", aclReadACP},
+	{"grant-write-acp", aclWriteACP},
+	{"grant-full-control", aclFullControl},
+}
+
+var aclSetFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  "grant-read",
+		Usage: "grant reading the object to a grantee, as id=ID, emailAddress=EMAIL or uri=GROUP-URI",
+	},
+	cli.StringSliceFlag{
+		Name:  "grant-read-acp",
+		Usage: "grant reading the ACL of the object to a grantee",
+	},
+	cli.StringSliceFlag{
+		Name:  "grant-write-acp",
+		Usage: "grant changing the ACL of the object to a grantee",
+	},
+	cli.StringSliceFlag{
+		Name:  "grant-full-control",
+		Usage: "grant all the permissions on the object to a grantee",
+	},
+}
+
+var aclSetCmd = cli.Command{
+	Name:         "set",
+	Usage:        "replace the ACL of an object",
+	Action:       mainACLSet,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(aclSetFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET CANNED-ACL
+  {{.HelpName}} [FLAGS] --grant-PERMISSION GRANTEE [--grant-PERMISSION GRANTEE...] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Replace the ACL of an existing object without uploading it again, with a canned
+  ACL or with grants. CANNED-ACL is one of private, public-read, public-read-write,
+  authenticated-read, aws-exec-read, bucket-owner-read or bucket-owner-full-control.
+
+  With grants, the owner of the object keeps full control and the ACL is made of
+  the given grants. A grantee is id=ID, emailAddress=EMAIL or uri=GROUP-URI, such
+  as uri=http://acs.amazonaws.com/groups/global/AllUsers for everyone.
+
+EXAMPLES:
+  1. Allow anyone to download an object.
+     {{.Prompt}} {{.HelpName}} s3/mybucket/report.pdf public-read
+
+  2. Make an object private again.
+     {{.Prompt}} {{.HelpName}} s3/mybucket/report.pdf private
+
+  3. Allow another account to read an object and its ACL.
+     {{.Prompt}} {{.HelpName}} --grant-read id=79a59df900b949e55d96a1e698fbaced --grant-read-acp id=79a59df900b949e55d96a1e698fbaced s3/mybucket/report.pdf
+`,
+}
+
+// aclSetMessage is the ACL set on an object.
+type aclSetMessage struct {
+	Status    string     `json:"status"`
+	URL       string     `json:"url"`
+	CannedACL string     `json:"cannedACL,omitempty"`
+	Grants    []aclGrant `json:"grants,omitempty"`
+}
+
+func (m aclSetMessage) String() string {
+	acl := m.CannedACL
+	if acl == "" {
+		acl = strconv.Itoa(len(m.Grants)) + " grants"
+	}
+	return console.Colorize("ACL", "ACL of `"+m.URL+"` set to "+acl+".")
+}
+
+func (m aclSetMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// parseACLGrants returns the grants of the --grant-* flags.
+func parseACLGrants(cliCtx *cli.Context) ([]aclGrant, *probe.Error) {
+	var grants []aclGrant
+	for _, flag := range aclGrantFlags {
+		for _, value := range cliCtx.StringSlice(flag.name) {
+			for _, s := range strings.Split(value, ",") {
+				grantee, err := parseGrantee(s)
+				if err != nil {
+					return nil, err.Trace("--" + flag.name)
+				}
+				grants = append(grants, aclGrant{Grantee: grantee, Permission: flag.permission})
+			}
+		}
+	}
+	return grants, nil
+}
+
+func mainACLSet(cliCtx *cli.Context) error {
+	ctx, cancelACLSet := context.WithCancel(globalContext)
+	defer cancelACLSet()
+
+	console.SetColor("ACL", color.New(color.FgGreen))
+
+	grants, err := parseACLGrants(cliCtx)
+	fatalIf(err, "Invalid grantee, expected id=ID, emailAddress=EMAIL or uri=GROUP-URI.")
+	args := cliCtx.Args()
+	var cannedACL string
+	switch {
+	case len(grants) == 0 && len(args) == 2:
+		cannedACL = args.Get(1)
+		valid := false
+		for _, canned := range objectCannedACLs {
+			valid = valid || canned == cannedACL
+		}
+		if !valid {
+			fatalIf(errInvalidArgument().Trace(cannedACL), "Invalid canned ACL, expected one of "+strings.Join(objectCannedACLs, ", ")+".")
+		}
+	case len(grants) > 0 && len(args) == 2:
+		fatalIf(errInvalidArgument().Trace(args...), "A canned ACL cannot be set with --grant-* flags.")
+	case len(grants) == 0 || len(args) != 1:
+		showCommandHelpAndExit(cliCtx, globalErrorExitStatus)
+	}
+	targetURL := args.Get(0)
+
+	clnt, err := newClient(targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+	defer clnt.Close()
+
+	var policy accessControlPolicy
+	if cannedACL == "" {
+		// The owner is part of the ACL and keeps full control.
+		current, err := clnt.GetObjectACL(ctx)
+		fatalIf(err.Trace(targetURL), "Unable to get the owner of `"+targetURL+"`.")
+		policy.Owner = current.Owner
+		policy.Grants, _ = cannedACLGrants("private", current.Owner)
+		policy.Grants = append(policy.Grants, grants...)
+	}
+	err = clnt.PutObjectACL(ctx, cannedACL, policy)
+	fatalIf(err.Trace(targetURL), "Unable to set the ACL of `"+targetURL+"`.")
+
+	printMsg(aclSetMessage{
+		Status:    "success",
+		URL:       targetURL,
+		CannedACL: cannedACL,
+		Grants:    policy.Grants,
+	})
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Grantee types and groups of the ACLs of objects.
+const (
+	s3Namespace  = "http://s3.amazonaws.com/doc/2006-03-01/"
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+	granteeCanonicalUser = "CanonicalUser"
+	granteeEmail         = "AmazonCustomerByEmail"
+	granteeGroup         = "Group"
+
+	aclGroupAllUsers           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	aclGroupAuthenticatedUsers = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// Permissions which can be granted on an object.
+const (
+	aclRead        = "READ"
+	aclWrite       = "WRITE"
+	aclReadACP     = "READ_ACP"
+	aclWriteACP    = "WRITE_ACP"
+	aclFullControl = "FULL_CONTROL"
+)
+
+// objectCannedACLs are the canned ACLs of objects.
+var objectCannedACLs = []string{
+	"private", "public-read", "public-read-write", "authenticated-read",
+	"aws-exec-read", "bucket-owner-read", "bucket-owner-full-control",
+}
+
+type aclOwner struct {
+	ID          string `xml:"ID" json:"id"`
+	DisplayName string `xml:"DisplayName,omitempty" json:"displayName,omitempty"`
+}
+
+type aclGrantee struct {
+	Type         string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr" json:"type"`
+	ID           string `xml:"ID,omitempty" json:"id,omitempty"`
+	DisplayName  string `xml:"DisplayName,omitempty" json:"displayName,omitempty"`
+	EmailAddress string `xml:"EmailAddress,omitempty" json:"emailAddress,omitempty"`
+	URI          string `xml:"URI,omitempty" json:"uri,omitempty"`
+}
+
+// MarshalXML writes the type of the grantee with the xsi prefix S3
+// expects, where encoding/xml would make up its own prefix.
+func (g aclGrantee) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:xsi"}, Value: xsiNamespace},
+		{Name: xml.Name{Local: "xsi:type"}, Value: g.Type},
+	}
+	return e.EncodeElement(struct {
+		ID           string `xml:"ID,omitempty"`
+		DisplayName  string `xml:"DisplayName,omitempty"`
+		EmailAddress string `xml:"EmailAddress,omitempty"`
+		URI          string `xml:"URI,omitempty"`
+	}{g.ID, g.DisplayName, g.EmailAddress, g.URI}, start)
+}
+
+// String returns the grantee as in the x-amz-grant-* headers.
+func (g aclGrantee) String() string {
+	switch {
+	case g.URI != "":
+		return "uri=" + g.URI
+	case g.EmailAddress != "":
+		return "emailAddress=" + g.EmailAddress
+	}
+	return "id=" + g.ID
+}
+
+type aclGrant struct {
+	Grantee    aclGrantee `xml:"Grantee" json:"grantee"`
+	Permission string     `xml:"Permission" json:"permission"`
+}
+
+// accessControlPolicy is the ACL of an object, as read and written
+// with ?acl.
+type accessControlPolicy struct {
+	XMLName xml.Name   `xml:"AccessControlPolicy" json:"-"`
+	XMLNS   string     `xml:"xmlns,attr,omitempty" json:"-"`
+	Owner   aclOwner   `xml:"Owner" json:"owner"`
+	Grants  []aclGrant `xml:"AccessControlList>Grant" json:"grants"`
+}
+
+// parseGrantee parses a grantee as in the x-amz-grant-* headers, such
+// as id=ID, emailAddress=EMAIL or uri=URI, the value optionally quoted.
+func parseGrantee(s string) (aclGrantee, *probe.Error) {
+	k, v, ok := strings.Cut(strings.TrimSpace(s), "=")
+	v = strings.Trim(v, `"`)
+	if !ok || v == "" {
+		return aclGrantee{}, errInvalidArgument().Trace(s)
+	}
+	switch strings.ToLower(k) {
+	case "id":
+		return aclGrantee{Type: granteeCanonicalUser, ID: v}, nil
+	case "emailaddress":
+		return aclGrantee{Type: granteeEmail, EmailAddress: v}, nil
+	case "uri":
+		return aclGrantee{Type: granteeGroup, URI: v}, nil
+	}
+	return aclGrantee{}, errInvalidArgument().Trace(s)
+}
+
+// cannedACLGrants returns the grants of a canned ACL of an object of
+// owner, false for the canned ACLs which depend on the bucket.
+func cannedACLGrants(canned string, owner aclOwner) ([]aclGrant, bool) {
+	grants := []aclGrant{{
+		Grantee:    aclGrantee{Type: granteeCanonicalUser, ID: owner.ID, DisplayName: owner.DisplayName},
+		Permission: aclFullControl,
+	}}
+	group := func(uri, permission string) aclGrant {
+		return aclGrant{Grantee: aclGrantee{Type: granteeGroup, URI: uri}, Permission: permission}
+	}
+	switch canned {
+	case "private":
+	case "public-read":
+		grants = append(grants, group(aclGroupAllUsers, aclRead))
+	case "public-read-write":
+		grants = append(grants, group(aclGroupAllUsers, aclRead), group(aclGroupAllUsers, aclWrite))
+	case "authenticated-read":
+		grants = append(grants, group(aclGroupAuthenticatedUsers, aclRead))
+	default:
+		return nil, false
+	}
+	return grants, true
+}
+
+// cannedACL returns the canned ACL the grants of policy amount to, or
+// "" if they are not those of a canned ACL.
+func (p accessControlPolicy) cannedACL() string {
+	key := func(grants []aclGrant) string {
+		keys := make([]string, 0, len(grants))
+		for _, g := range grants {
+			keys = append(keys, g.Grantee.String()+" "+g.Permission)
+		}
+		sort.Strings(keys)
+		return strings.Join(keys, "\n")
+	}
+	grants := key(p.Grants)
+	for _, canned := range objectCannedACLs {
+		if cannedGrants, ok := cannedACLGrants(canned, p.Owner); ok && key(cannedGrants) == grants {
+			return canned
+		}
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestParseGrantee(t *testing.T) {
+	for i, testCase := range []struct {
+		grantee string
+		want    aclGrantee
+		wantErr bool
+	}{
+		{"id=79a59df9", aclGrantee{Type: granteeCanonicalUser, ID: "79a59df9"}, false},
+		{`id="79a59df9"`, aclGrantee{Type: granteeCanonicalUser, ID: "79a59df9"}, false},
+		{"emailAddress=xyz@example.com", aclGrantee{Type: granteeEmail, EmailAddress: "xyz@example.com"}, false},
+		{" uri=" + aclGroupAllUsers, aclGrantee{Type: granteeGroup, URI: aclGroupAllUsers}, false},
+		{"79a59df9", aclGrantee{}, true},
+		{"id=", aclGrantee{}, true},
+		{"name=xyz", aclGrantee{}, true},
+	} {
+		got, err := parseGrantee(testCase.grantee)
+		if (err != nil) != testCase.wantErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.wantErr, err)
+		}
+		if got != testCase.want {
+			t.Fatalf("Test %d: expected %+v, got %+v", i+1, testCase.want, got)
+		}
+	}
+}
+
+func TestAccessControlPolicyXML(t *testing.T) {
+	owner := aclOwner{ID: "owner"}
+	grants, _ := cannedACLGrants("public-read", owner)
+	policy := accessControlPolicy{XMLNS: s3Namespace, Owner: owner, Grants: grants}
+	data, e := xml.Marshal(policy)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for _, s := range []string{
+		`<AccessControlPolicy xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`,
+		`<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>` + aclGroupAllUsers + `</URI></Grantee>`,
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Fatalf("expected %s in %s", s, data)
+		}
+	}
+
+	var parsed accessControlPolicy
+	if e = xml.Unmarshal(data, &parsed); e != nil {
+		t.Fatal(e)
+	}
+	if !reflect.DeepEqual(parsed.Grants, policy.Grants) || parsed.Owner != owner {
+		t.Fatalf("expected %+v, got %+v", policy, parsed)
+	}
+	if canned := parsed.cannedACL(); canned != "public-read" {
+		t.Fatalf("expected public-read, got %q", canned)
+	}
+	parsed.Grants = append(parsed.Grants, aclGrant{Grantee: aclGrantee{Type: granteeCanonicalUser, ID: "other"}, Permission: aclRead})
+	if canned := parsed.cannedACL(); canned != "" {
+		t.Fatalf("expected no canned ACL, got %q", canned)
+	}
+}
+
+func TestObjectACL(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.MakeBucket("bucket")
+	server.PutObject("bucket", "report.pdf", []byte("report"))
+	t.Setenv(mcEnvHostPrefix+"acltest", server.AliasURL())
+
+	ctx := context.Background()
+	alias, urlStr, _ := mustExpandAlias("acltest/bucket/report.pdf")
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymousGet := func() int {
+		resp, e := http.Get(server.URL + "/bucket/report.pdf")
+		if e != nil {
+			t.Fatal(e)
+		}
+		defer resp.Body.Close()
+		if data, _ := io.ReadAll(resp.Body); resp.StatusCode == http.StatusOK && string(data) != "report" {
+			t.Fatalf("unexpected anonymous download %q", data)
+		}
+		return resp.StatusCode
+	}
+
+	policy, err := clnt.GetObjectACL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Owner.ID == "" || policy.cannedACL() != "private" {
+		t.Fatalf("expected a private object, got %+v", policy)
+	}
+	if status := anonymousGet(); status != http.StatusForbidden {
+		t.Fatalf("expected anonymous reads of a private object to be denied, got %d", status)
+	}
+
+	if err = clnt.PutObjectACL(ctx, "public-read", accessControlPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if policy, err = clnt.GetObjectACL(ctx); err != nil || policy.cannedACL() != "public-read" {
+		t.Fatalf("expected a public-read object, got %+v, %v", policy, err)
+	}
+	if status := anonymousGet(); status != http.StatusOK {
+		t.Fatalf("expected anonymous reads of a public-read object, got %d", status)
+	}
+	if _, ok := server.Object("bucket", "report.pdf"); !ok || server.RequestCount(http.MethodPut) != 1 {
+		t.Fatal("expected the ACL to be set without uploading the object again")
+	}
+
+	// Grants replace the ACL, the owner keeping full control.
+	grants, _ := cannedACLGrants("private", policy.Owner)
+	grants = append(grants, aclGrant{Grantee: aclGrantee{Type: granteeCanonicalUser, ID: "other"}, Permission: aclReadACP})
+	if err = clnt.PutObjectACL(ctx, "", accessControlPolicy{Owner: policy.Owner, Grants: grants}); err != nil {
+		t.Fatal(err)
+	}
+	if policy, err = clnt.GetObjectACL(ctx); err != nil || fmt.Sprint(policy.Grants) != fmt.Sprint(grants) || policy.cannedACL() != "" {
+		t.Fatalf("expected the grants %+v, got %+v, %v", grants, policy, err)
+	}
+	if status := anonymousGet(); status != http.StatusForbidden {
+		t.Fatalf("expected anonymous reads to be denied again, got %d", status)
+	}
+
+	if err = clnt.PutObjectACL(ctx, "public-write-only", accessControlPolicy{}); err == nil {
+		t.Fatal("expected an invalid canned ACL to fail")
+	}
+	alias, urlStr, _ = mustExpandAlias("acltest/bucket/missing")
+	if clnt, err = newClientFromAlias(alias, urlStr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = clnt.GetObjectACL(ctx); err == nil || !strings.Contains(err.ToGoError().Error(), "does not exist") {
+		t.Fatalf("expected the ACL of a missing object to fail, got %v", err)
+	}
+}
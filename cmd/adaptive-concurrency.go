@@ -0,0 +1,169 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/minio/pkg/v2/console"
+)
+
+// adaptiveConcurrency limits the number of part uploads in flight to
+// what the server sustains, AIMD-style: the limit is halved when the
+// server asks to slow down, and grows by one part once a limit worth of
+// parts went through while others were waiting. There is no limit until
+// the server first throttles, --parallel bounds the uploads anyway.
+type adaptiveConcurrency struct {
+	host      string
+	mu        sync.Mutex
+	limit     int
+	inflight  int
+	waiting   int
+	successes int
+	// epoch counts the decreases, the throttled parts which were
+	// started before the last one do not lower the limit again.
+	epoch int
+	// wake is closed, and replaced, whenever a part is done.
+	wake chan struct{}
+}
+
+func newAdaptiveConcurrency(host string) *adaptiveConcurrency {
+	return &adaptiveConcurrency{host: host, wake: make(chan struct{})}
+}
+
+// hostConcurrency holds an adaptiveConcurrency per host, a server which
+// throttles does not slow down the part uploads to the others.
+type hostConcurrency struct {
+	mu    sync.Mutex
+	hosts map[string]*adaptiveConcurrency
+}
+
+func newHostConcurrency() *hostConcurrency {
+	return &hostConcurrency{hosts: make(map[string]*adaptiveConcurrency)}
+}
+
+// forHost returns the concurrency of the part uploads to host.
+func (h *hostConcurrency) forHost(host string) *adaptiveConcurrency {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	a, ok := h.hosts[host]
+	if !ok {
+		a = newAdaptiveConcurrency(host)
+		h.hosts[host] = a
+	}
+	return a
+}
+
+// acquire waits for a part upload to be allowed, it returns the epoch
+// to pass to release.
+func (a *adaptiveConcurrency) acquire(ctx context.Context) (int, error) {
+	for {
+		a.mu.Lock()
+		if a.limit == 0 || a.inflight < a.limit {
+			a.inflight++
+			epoch := a.epoch
+			a.mu.Unlock()
+			return epoch, nil
+		}
+		wake := a.wake
+		a.waiting++
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			a.mu.Lock()
+			a.waiting--
+			a.mu.Unlock()
+			return 0, ctx.Err()
+		case <-wake:
+			a.mu.Lock()
+			a.waiting--
+			a.mu.Unlock()
+		}
+	}
+}
+
+// release ends a part upload acquired at epoch, throttled if the server
+// asked to slow down.
+func (a *adaptiveConcurrency) release(epoch int, throttled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	concurrency := a.inflight
+	a.inflight--
+	switch {
+	case throttled && epoch == a.epoch:
+		if a.limit > 0 && a.limit < concurrency {
+			concurrency = a.limit
+		}
+		a.limit = concurrency / 2
+		if a.limit < 1 {
+			a.limit = 1
+		}
+		a.epoch++
+		a.successes = 0
+		if globalDebug {
+			console.Debugln(fmt.Sprintf("Server %s is throttling part uploads, lowering their concurrency to %d", a.host, a.limit))
+		}
+	case !throttled && a.limit > 0:
+		a.successes++
+		if a.successes >= a.limit && a.waiting > 0 {
+			a.limit++
+			a.successes = 0
+		}
+	}
+	close(a.wake)
+	a.wake = make(chan struct{})
+}
+
+// adaptiveConcurrencyTransport holds the part uploads to the limit of
+// concurrency of their host, every attempt of a retried part included.
+type adaptiveConcurrencyTransport struct {
+	transport   http.RoundTripper
+	concurrency *hostConcurrency
+}
+
+func newAdaptiveConcurrencyTransport(concurrency *hostConcurrency, transport http.RoundTripper) http.RoundTripper {
+	if concurrency == nil {
+		return transport
+	}
+	return &adaptiveConcurrencyTransport{transport: transport, concurrency: concurrency}
+}
+
+// isPartUpload returns true if req uploads a part of a multipart upload.
+func isPartUpload(req *http.Request) bool {
+	query := req.URL.Query()
+	return req.Method == http.MethodPut && query.Has("partNumber") && query.Has("uploadId")
+}
+
+func (t *adaptiveConcurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isPartUpload(req) {
+		return t.transport.RoundTrip(req)
+	}
+	concurrency := t.concurrency.forHost(req.URL.Host)
+	epoch, e := concurrency.acquire(req.Context())
+	if e != nil {
+		return nil, e
+	}
+	resp, err := t.transport.RoundTrip(req)
+	concurrency.release(epoch, err == nil && throttleCode(resp) != "")
+	return resp, err
+}
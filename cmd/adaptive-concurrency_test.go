@@ -0,0 +1,136 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+// concurrencyThrottlingHandler answers SlowDown to the part uploads
+// above maxConcurrency in flight.
+type concurrencyThrottlingHandler struct {
+	*miniotest.Server
+	maxConcurrency int64
+
+	inflight int64
+	// peak is the most attempts in flight once settleAfter parts were
+	// uploaded.
+	uploaded, settleAfter, peak int64
+}
+
+func (h *concurrencyThrottlingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !isPartUpload(r) {
+		h.Server.ServeHTTP(w, r)
+		return
+	}
+	inflight := atomic.AddInt64(&h.inflight, 1)
+	defer atomic.AddInt64(&h.inflight, -1)
+	if atomic.LoadInt64(&h.uploaded) >= h.settleAfter && inflight > atomic.LoadInt64(&h.peak) {
+		atomic.StoreInt64(&h.peak, inflight)
+	}
+	if inflight > h.maxConcurrency {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>"))
+		return
+	}
+	time.Sleep(5 * time.Millisecond)
+	h.Server.ServeHTTP(w, r)
+	atomic.AddInt64(&h.uploaded, 1)
+}
+
+func TestAdaptivePartConcurrency(t *testing.T) {
+	defer func(unit time.Duration) { throttleRetryUnit = unit }(throttleRetryUnit)
+	throttleRetryUnit = time.Millisecond
+
+	handler := &concurrencyThrottlingHandler{maxConcurrency: 2, settleAfter: 24}
+	_, front := newS3TestFrontServer(t, func(server *miniotest.Server) http.Handler {
+		handler.Server = server
+		return handler
+	})
+
+	conf := newTestS3Config(front.URL + "/bucket/object")
+	conf.AccessKey, conf.SecretKey = miniotest.AccessKey, miniotest.SecretKey
+	conf.MaxRetryTime = time.Minute
+	conf.PartConcurrency = newHostConcurrency()
+	clnt, err := S3New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	uploadID, err := clnt.NewMultipartUpload(ctx, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upload the parts with more workers than the server sustains.
+	const parts, workers = 48, 8
+	partCh := make(chan int, parts)
+	for n := 1; n <= parts; n++ {
+		partCh <- n
+	}
+	close(partCh)
+	var wg sync.WaitGroup
+	errCh := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range partCh {
+				data := bytes.Repeat([]byte{byte(n)}, 1024)
+				if _, err := clnt.UploadPart(ctx, uploadID, n, bytes.NewReader(data), int64(len(data)), PutOptions{}); err != nil {
+					errCh <- err.ToGoError()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	for e := range errCh {
+		t.Fatal(e)
+	}
+
+	if uploaded := atomic.LoadInt64(&handler.uploaded); uploaded != parts {
+		t.Fatalf("expected %d uploaded parts, got %d", parts, uploaded)
+	}
+	// Past the first decreases, the uploads only go above the limit of
+	// the server by one part, probing for a higher limit.
+	if handler.peak > handler.maxConcurrency+1 {
+		t.Fatalf("expected at most %d attempts in flight, got %d", handler.maxConcurrency+1, handler.peak)
+	}
+	concurrency := conf.PartConcurrency.forHost(strings.TrimPrefix(front.URL, "http://"))
+	concurrency.mu.Lock()
+	defer concurrency.mu.Unlock()
+	if limit := concurrency.limit; limit < 1 || limit > int(handler.maxConcurrency)+1 {
+		t.Fatalf("expected the limit to converge to %d, got %d", handler.maxConcurrency, limit)
+	}
+	// The uploads to the other hosts are not limited.
+	if limit := conf.PartConcurrency.forHost("other:9000").limit; limit != 0 {
+		t.Fatalf("expected no limit for another host, got %d", limit)
+	}
+}
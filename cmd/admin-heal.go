@@ -636,7 +636,7 @@ func mainAdminHeal(ctx *cli.Context) error {
 
 	clnt, err := newClient(aliasedURL)
 	if err != nil {
-		fatalIf(err.Trace(clnt.GetURL().String()), "Unable to create client for URL ", aliasedURL)
+		fatalIf(err.Trace(clnt.GetURL().String()), "Unable to create client for URL `%s`.", aliasedURL)
 		return nil
 	}
 
@@ -190,8 +190,9 @@ func probeS3Signature(ctx context.Context, accessKey, secretKey, url string, pee
 		Debug:             globalDebug,
 		ConnReadDeadline:  globalConnReadDeadline,
 		ConnWriteDeadline: globalConnWriteDeadline,
-		UploadLimit:       int64(globalLimitUpload),
-		DownloadLimit:     int64(globalLimitDownload),
+		MaxRetryTime:      globalMaxRetryTime,
+		UploadBucket:      globalUploadBucket,
+		DownloadBucket:    globalDownloadBucket,
 	}
 	if peerCert != nil {
 		configurePeerCertificate(s3Config, peerCert)
@@ -279,10 +279,17 @@ var completeCmds = map[string]complete.Predictor{
 	"/replicate/resync/start":  s3Complete{deepLevel: 3},
 	"/replicate/resync/status": s3Complete{deepLevel: 3},
 
+	"/acl/get": s3Completer,
+	"/acl/set": s3Completer,
+
 	"/tag/list":   s3Completer,
 	"/tag/remove": s3Completer,
 	"/tag/set":    s3Completer,
 
+	"/trash/list":    s3Completer,
+	"/trash/restore": s3Completer,
+	"/trash/empty":   s3Completer,
+
 	"/version/info":    s3Complete{deepLevel: 2},
 	"/version/enable":  s3Complete{deepLevel: 2},
 	"/version/suspend": s3Complete{deepLevel: 2},
@@ -292,6 +299,9 @@ var completeCmds = map[string]complete.Predictor{
 	"/lock/clear":      s3Completer,
 	"/lock/info":       s3Completer,
 
+	"/session/list":   nil,
+	"/session/resume": nil,
+
 	"/share/download": s3Completer,
 	"/share/list":     nil,
 	"/share/upload":   s3Completer,
@@ -314,6 +324,12 @@ var completeCmds = map[string]complete.Predictor{
 
 	"/undo": s3Completer,
 
+	"/rotate-key": s3Completer,
+
+	"/verify": complete.PredictOr(s3Completer, fsCompleter),
+
+	"/explore": complete.PredictOr(s3Completer, fsCompleter),
+
 	// Admin API commands MinIO only.
 	"/admin/heal": s3Completer,
 
@@ -510,6 +526,10 @@ var completeCmds = map[string]complete.Predictor{
 	"/quota/clear": aliasCompleter,
 	"/put":         complete.PredictOr(s3Completer, fsCompleter),
 	"/get":         complete.PredictOr(s3Completer, fsCompleter),
+	"/restore":     s3Completer,
+	"/whoami":      s3Completer,
+	"/lb":          aliasCompleter,
+	"/auth":        nil,
 }
 
 // flagsToCompleteFlags transforms a cli.Flag to complete.Flags
@@ -28,4 +28,7 @@ var (
 	ShortCommitID = CommitID[:12]
 	// CopyrightYear - dynamic value of the copyright end year
 	CopyrightYear = "0000"
+	// DefaultServerEndpoint - gpumall server used unless --endpoint or
+	// GPU_MALL_SERVER is set, distribution builds set it with -ldflags.
+	DefaultServerEndpoint = "http://localhost:9000"
 )
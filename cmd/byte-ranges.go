@@ -0,0 +1,198 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// byteRange is a range of bytes of an object or file.
+type byteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// end returns the offset following the last byte of the range.
+func (r byteRange) end() int64 {
+	return r.Offset + r.Length
+}
+
+func (r byteRange) String() string {
+	return fmt.Sprintf("%d,%d", r.Offset, r.Length)
+}
+
+// parseByteRangeSpec reads a --ranges spec: one "offset,length" pair per
+// line, blank lines and lines starting with '#' are ignored.
+func parseByteRangeSpec(r io.Reader) ([]byteRange, error) {
+	var ranges []byteRange
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		offsetStr, lengthStr, ok := strings.Cut(line, ",")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected `offset,length`, got `%s`", lineNum, line)
+		}
+		offset, e := strconv.ParseInt(strings.TrimSpace(offsetStr), 10, 64)
+		if e != nil || offset < 0 {
+			return nil, fmt.Errorf("line %d: invalid offset `%s`", lineNum, offsetStr)
+		}
+		length, e := strconv.ParseInt(strings.TrimSpace(lengthStr), 10, 64)
+		if e != nil || length <= 0 {
+			return nil, fmt.Errorf("line %d: invalid length `%s`", lineNum, lengthStr)
+		}
+		ranges = append(ranges, byteRange{Offset: offset, Length: length})
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, e
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no ranges")
+	}
+	return ranges, nil
+}
+
+// readByteRangeSpec reads the --ranges spec at path.
+func readByteRangeSpec(path string) ([]byteRange, *probe.Error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer f.Close()
+	ranges, e := parseByteRangeSpec(f)
+	if e != nil {
+		return nil, probe.NewError(fmt.Errorf("invalid range spec `%s`: %w", path, e))
+	}
+	return ranges, nil
+}
+
+// validateByteRanges sorts ranges by offset and checks that they neither
+// overlap nor go past size.
+func validateByteRanges(ranges []byteRange, size int64) error {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Offset < ranges[j].Offset })
+	for i, r := range ranges {
+		if r.end() > size {
+			return fmt.Errorf("range %s ends past the size of %d bytes", r, size)
+		}
+		if i > 0 && r.Offset < ranges[i-1].end() {
+			return fmt.Errorf("range %s overlaps range %s", r, ranges[i-1])
+		}
+	}
+	return nil
+}
+
+// byteRangesSize returns the number of bytes in ranges.
+func byteRangesSize(ranges []byteRange) (size int64) {
+	for _, r := range ranges {
+		size += r.Length
+	}
+	return size
+}
+
+// getRangesMessage is printed by `get --ranges`.
+type getRangesMessage struct {
+	Status string      `json:"status"`
+	Source string      `json:"source"`
+	Target string      `json:"target"`
+	Ranges []byteRange `json:"ranges"`
+	Size   int64       `json:"size"`
+}
+
+func (g getRangesMessage) String() string {
+	return fmt.Sprintf("Patched %d range(s), %s, of `%s` from `%s`.", len(g.Ranges), formatSize(g.Size), g.Target, g.Source)
+}
+
+func (g getRangesMessage) JSON() string {
+	g.Status = "success"
+	msgBytes, e := json.MarshalIndent(g, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// getByteRanges writes the given ranges of the object of clnt at the
+// same offsets of the local file targetPath, which is created if missing
+// and otherwise left untouched outside of the ranges. Only the bytes of
+// the ranges are downloaded, with one ranged GET each.
+func getByteRanges(ctx context.Context, clnt Client, ranges []byteRange, targetPath string, opts GetOptions, progress io.Reader) *probe.Error {
+	st, err := clnt.Stat(ctx, StatOptions{versionID: opts.VersionID, sse: opts.SSE})
+	if err != nil {
+		return err
+	}
+	if e := validateByteRanges(ranges, st.Size); e != nil {
+		return probe.NewError(e)
+	}
+
+	f, e := os.OpenFile(targetPath, os.O_RDWR|os.O_CREATE, 0o666)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	defer f.Close()
+	for _, r := range ranges {
+		opts.RangeStart, opts.RangeLength = r.Offset, r.Length
+		reader, _, err := clnt.Get(ctx, opts)
+		if err != nil {
+			return err.Trace(r.String())
+		}
+		if _, e = f.Seek(r.Offset, io.SeekStart); e == nil {
+			_, e = io.CopyN(f, hookreader.NewHook(reader, progress), r.Length)
+		}
+		reader.Close()
+		if e != nil {
+			return probe.NewError(e).Trace(r.String())
+		}
+	}
+	return probe.NewError(f.Sync())
+}
+
+// byteRangeParts returns the numbers of the parts of partSize bytes
+// holding ranges of a file of size bytes. Every range must start on a
+// part boundary and end on one or at the end of the file, as parts
+// cannot be uploaded partially.
+func byteRangeParts(ranges []byteRange, partSize, size int64) ([]int, error) {
+	if e := validateByteRanges(ranges, size); e != nil {
+		return nil, e
+	}
+	var parts []int
+	for _, r := range ranges {
+		if r.Offset%partSize != 0 || (r.end()%partSize != 0 && r.end() != size) {
+			return nil, fmt.Errorf("range %s is not aligned on parts of %d bytes", r, partSize)
+		}
+		first := int(r.Offset/partSize) + 1
+		last := int((r.end() + partSize - 1) / partSize)
+		if last > maxPartNumber {
+			return nil, fmt.Errorf("range %s ends past part %d", r, maxPartNumber)
+		}
+		for n := first; n <= last; n++ {
+			parts = append(parts, n)
+		}
+	}
+	return parts, nil
+}
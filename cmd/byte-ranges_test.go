@@ -0,0 +1,270 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestParseByteRangeSpec(t *testing.T) {
+	for _, testCase := range []struct {
+		spec     string
+		expected []byteRange
+		fail     bool
+	}{
+		{spec: "0,10\n# comment\n\n 100 , 5 \n", expected: []byteRange{{0, 10}, {100, 5}}},
+		{spec: "10", fail: true},
+		{spec: "-1,10", fail: true},
+		{spec: "0,0", fail: true},
+		{spec: "a,10", fail: true},
+		{spec: "# nothing\n", fail: true},
+	} {
+		got, e := parseByteRangeSpec(strings.NewReader(testCase.spec))
+		if (e != nil) != testCase.fail {
+			t.Fatalf("%q: unexpected error %v", testCase.spec, e)
+		}
+		if !testCase.fail && !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%q: expected %v, got %v", testCase.spec, testCase.expected, got)
+		}
+	}
+}
+
+func TestValidateByteRanges(t *testing.T) {
+	for _, testCase := range []struct {
+		ranges []byteRange
+		errMsg string
+	}{
+		{ranges: []byteRange{{90, 10}, {0, 10}, {50, 5}}},
+		{ranges: []byteRange{{0, 10}, {5, 10}}, errMsg: "overlaps"},
+		{ranges: []byteRange{{10, 5}, {0, 11}}, errMsg: "overlaps"},
+		{ranges: []byteRange{{95, 10}}, errMsg: "past the size"},
+	} {
+		e := validateByteRanges(testCase.ranges, 100)
+		if testCase.errMsg == "" {
+			if e != nil {
+				t.Fatalf("%v: unexpected error %v", testCase.ranges, e)
+			}
+			continue
+		}
+		if e == nil || !strings.Contains(e.Error(), testCase.errMsg) {
+			t.Fatalf("%v: expected an error with %q, got %v", testCase.ranges, testCase.errMsg, e)
+		}
+	}
+}
+
+func TestByteRangeParts(t *testing.T) {
+	for _, testCase := range []struct {
+		ranges   []byteRange
+		expected []int
+		fail     bool
+	}{
+		{ranges: []byteRange{{200, 100}, {0, 100}}, expected: []int{1, 3}},
+		{ranges: []byteRange{{100, 200}, {900, 50}}, expected: []int{2, 3, 10}},
+		{ranges: []byteRange{{50, 100}}, fail: true},
+		{ranges: []byteRange{{100, 50}}, fail: true},
+	} {
+		got, e := byteRangeParts(testCase.ranges, 100, 950)
+		if (e != nil) != testCase.fail {
+			t.Fatalf("%v: unexpected error %v", testCase.ranges, e)
+		}
+		if !testCase.fail && !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%v: expected %v, got %v", testCase.ranges, testCase.expected, got)
+		}
+	}
+}
+
+// countingResponseWriter counts the bytes of the response bodies.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	n *int64
+}
+
+func (w countingResponseWriter) Write(b []byte) (int, error) {
+	n, e := w.ResponseWriter.Write(b)
+	*w.n += int64(n)
+	return n, e
+}
+
+func TestGetByteRanges(t *testing.T) {
+	object := make([]byte, 1000)
+	for i := range object {
+		object[i] = byte(i % 251)
+	}
+	var mu sync.Mutex
+	var served int64
+	var requestedRanges []string
+	newS3TestFrontServer(t, func(server *miniotest.Server) http.Handler {
+		server.PutObject("bucket", "object", object)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.Method == http.MethodGet && r.URL.Path == "/bucket/object" {
+				requestedRanges = append(requestedRanges, r.Header.Get("Range"))
+				w = countingResponseWriter{w, &served}
+			}
+			server.ServeHTTP(w, r)
+		})
+	})
+	clnt := newS3TestClient(t, "s3test/bucket/object")
+
+	target := filepath.Join(t.TempDir(), "object")
+	local := bytes.Repeat([]byte("x"), len(object))
+	if e := os.WriteFile(target, local, 0o600); e != nil {
+		t.Fatal(e)
+	}
+	ranges, e := parseByteRangeSpec(strings.NewReader("500,20\n10,5\n990,10\n"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if err := getByteRanges(context.Background(), clnt, ranges, target, GetOptions{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range ranges {
+		copy(local[r.Offset:r.end()], object[r.Offset:r.end()])
+	}
+	got, e := os.ReadFile(target)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !bytes.Equal(got, local) {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if expected := []string{"bytes=10-14", "bytes=500-519", "bytes=990-999"}; !reflect.DeepEqual(requestedRanges, expected) {
+		t.Fatalf("expected ranged requests %v, got %v", expected, requestedRanges)
+	}
+	if served != byteRangesSize(ranges) {
+		t.Fatalf("expected %d bytes to be transferred, got %d", byteRangesSize(ranges), served)
+	}
+
+	// Ranges past the end of the object are rejected before any download.
+	ranges = []byteRange{{990, 20}}
+	if err := getByteRanges(context.Background(), clnt, ranges, target, GetOptions{}, nil); err == nil {
+		t.Fatal("expected a range past the end of the object to be rejected")
+	}
+}
+
+func TestUploadByteRangeParts(t *testing.T) {
+	server := newS3TestServer(t)
+	clnt := newS3TestClient(t, "s3test/bucket/object")
+
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4000)
+	path := filepath.Join(dir, "disk.img")
+	if e := os.WriteFile(path, data, 0o600); e != nil {
+		t.Fatal(e)
+	}
+	rangesFile := filepath.Join(dir, "ranges.txt")
+	if e := os.WriteFile(rangesFile, []byte("60000,4000\n10000,10000\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+
+	const partSize = 10000
+	partNumbers, err := rangesPartNumbers(rangesFile, path, partSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	uploadID, err := clnt.NewMultipartUpload(ctx, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uploadFileParts(ctx, clnt, uploadID, path, partNumbers, partSize, PutOptions{}, func(putPartMessage) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	parts := server.UploadParts("bucket", "object", uploadID)
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 uploaded parts, got %d", len(parts))
+	}
+	if !bytes.Equal(parts[2], data[10000:20000]) || !bytes.Equal(parts[7], data[60000:]) {
+		t.Fatal("unexpected content of the uploaded parts")
+	}
+}
+
+func TestUploadByteRangePartsComplete(t *testing.T) {
+	server := newS3TestServer(t)
+	original := bytes.Repeat([]byte("0123456789abcdef"), 4000)
+	server.PutObject("bucket", "disk.img", original)
+	clnt := newS3TestClient(t, "s3test/bucket/disk.img")
+
+	dir := t.TempDir()
+	patched := append([]byte(nil), original...)
+	copy(patched[10000:20000], bytes.Repeat([]byte("x"), 10000))
+	copy(patched[60000:], bytes.Repeat([]byte("y"), 4000))
+	path := filepath.Join(dir, "disk.img")
+	if e := os.WriteFile(path, patched, 0o600); e != nil {
+		t.Fatal(e)
+	}
+	rangesFile := filepath.Join(dir, "ranges.txt")
+	if e := os.WriteFile(rangesFile, []byte("60000,4000\n10000,10000\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+
+	const partSize = 10000
+	ctx := context.Background()
+	uploadID, err := clnt.NewMultipartUpload(ctx, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uploaded, copied []int
+	err = uploadByteRangeParts(ctx, clnt, uploadID, path, rangesFile, partSize, PutOptions{}, func(msg putPartMessage) {
+		if msg.Copied {
+			copied = append(copied, msg.PartNumber)
+		} else {
+			uploaded = append(uploaded, msg.PartNumber)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(uploaded, []int{2, 7}) || !reflect.DeepEqual(copied, []int{1, 3, 4, 5, 6}) {
+		t.Fatalf("expected parts 2 and 7 to be uploaded and the others copied, got %v and %v", uploaded, copied)
+	}
+	if _, parts, err := clnt.CompleteMultipartUpload(ctx, uploadID, PutOptions{}); err != nil || parts != 7 {
+		t.Fatalf("expected an upload of 7 parts, got %d, %v", parts, err)
+	}
+	object, _ := server.Object("bucket", "disk.img")
+	if !bytes.Equal(object.Data, patched) {
+		t.Fatal("expected the completed object to be the whole patched file")
+	}
+
+	// Only an object of the size of the file is patched.
+	if e := os.WriteFile(path, patched[:50000], 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.WriteFile(rangesFile, []byte("10000,10000\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if uploadID, err = clnt.NewMultipartUpload(ctx, PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = uploadByteRangeParts(ctx, clnt, uploadID, path, rangesFile, partSize, PutOptions{}, func(putPartMessage) {}); err == nil {
+		t.Fatal("expected an object of another size to be refused")
+	}
+}
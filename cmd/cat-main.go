@@ -76,6 +76,7 @@ FLAGS:
   {{end}}
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 EXAMPLES:
   1. Stream an object from Amazon S3 cloud storage to mplayer standard input.
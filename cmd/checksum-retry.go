@@ -0,0 +1,162 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/v2/console"
+)
+
+// checksumRetryUnit is the base backoff in between two uploads of an
+// object whose checksum did not match.
+var checksumRetryUnit = time.Second
+
+// Results of the uploads of an object reported by checksumRetryMessage.
+const (
+	checksumRetrying   = "retrying"
+	checksumRecovered  = "recovered"
+	checksumPersistent = "persistent"
+)
+
+// checksumRetryMessage reports the checksum mismatches of the uploads of
+// an object. A mismatch which goes away on retry is likely a transient
+// network corruption, one which does not is likely a bug.
+type checksumRetryMessage struct {
+	Status     string `json:"status"`
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	Attempt    int    `json:"attempt"`
+	Attempts   int    `json:"attempts"`
+	Mismatches int    `json:"mismatches"`
+	Result     string `json:"result"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (m checksumRetryMessage) String() string {
+	switch m.Result {
+	case checksumRecovered:
+		return console.Colorize("ChecksumRetry", fmt.Sprintf("Uploaded `%s` on attempt %d, the checksum mismatches before were transient.", m.Source, m.Attempt))
+	case checksumPersistent:
+		return console.Colorize("ChecksumPersistent", fmt.Sprintf("Checksum of `%s` mismatched on all %d attempts, the mismatch is persistent: %s", m.Target, m.Attempts, m.Error))
+	}
+	return console.Colorize("ChecksumRetry", fmt.Sprintf("Checksum of `%s` mismatched on attempt %d of %d, uploading `%s` again: %s", m.Target, m.Attempt, m.Attempts, m.Source, m.Error))
+}
+
+func (m checksumRetryMessage) JSON() string {
+	m.Status = "success"
+	if m.Result == checksumPersistent {
+		m.Status = "error"
+	}
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+func setChecksumRetryColors() {
+	console.SetColor("ChecksumRetry", color.New(color.FgYellow))
+	console.SetColor("ChecksumPersistent", color.New(color.FgRed, color.Bold))
+}
+
+// isChecksumMismatch returns true if the upload failed because the
+// uploaded bytes did not match their checksum, either the ETag returned
+// or the Content-Md5 sent.
+func isChecksumMismatch(err *probe.Error) bool {
+	if err == nil {
+		return false
+	}
+	var mismatch ChecksumMismatch
+	return errors.As(err.ToGoError(), &mismatch) || minio.ToErrorResponse(err.ToGoError()).Code == "BadDigest"
+}
+
+// doCopyWithChecksumRetry uploads the object of copyOpts as doCopy does,
+// uploading it again up to retries times, with backoff, while its
+// checksum mismatches. Every mismatch is reported.
+func doCopyWithChecksumRetry(ctx context.Context, copyOpts doCopyOpts, retries int) URLs {
+	if retries <= 0 {
+		return doCopy(ctx, copyOpts)
+	}
+	var urls URLs
+	var mismatches int
+	done := copyOpts.pg.Get()
+	newRetryManager(ctx, checksumRetryUnit, retries).retry(func(rm *retryManager) *probe.Error {
+		if rm.retries > 0 {
+			rewindProgress(copyOpts.pg, done)
+		}
+		urls = doCopy(ctx, copyOpts)
+		if !isChecksumMismatch(urls.Error) {
+			return nil
+		}
+		mismatches++
+		msg := checksumRetryMessage{
+			Source:     copyOpts.cpURLs.SourceContent.URL.String(),
+			Target:     copyOpts.cpURLs.TargetContent.URL.String(),
+			Attempt:    rm.retries + 1,
+			Attempts:   retries + 1,
+			Mismatches: mismatches,
+			Result:     checksumRetrying,
+			Error:      urls.Error.ToGoError().Error(),
+		}
+		if rm.retries == retries {
+			msg.Result = checksumPersistent
+		}
+		printChecksumRetry(copyOpts.pg, msg)
+		if msg.Result == checksumPersistent {
+			return nil
+		}
+		return urls.Error
+	})
+	if urls.Error == nil && mismatches > 0 {
+		printChecksumRetry(copyOpts.pg, checksumRetryMessage{
+			Source:     copyOpts.cpURLs.SourceContent.URL.String(),
+			Target:     copyOpts.cpURLs.TargetContent.URL.String(),
+			Attempt:    mismatches + 1,
+			Attempts:   retries + 1,
+			Mismatches: mismatches,
+			Result:     checksumRecovered,
+		})
+	}
+	return urls
+}
+
+// rewindProgress sets the transferred bytes of pg back to n, before an
+// object is uploaded again.
+func rewindProgress(pg ProgressReader, n int64) {
+	switch p := pg.(type) {
+	case *progressBar:
+		p.Set64(n)
+	case *accounter:
+		p.Set(n)
+	case *progressRecords:
+		p.Set(n)
+	}
+}
+
+func printChecksumRetry(pg ProgressReader, msg checksumRetryMessage) {
+	if _, ok := pg.(*progressBar); ok && !globalQuiet && !globalJSON {
+		eraseStatusLine()
+	}
+	printMsg(msg)
+}
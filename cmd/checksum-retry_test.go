@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestChecksumRetry(t *testing.T) {
+	initTestConfig(t)
+	defer func(unit time.Duration) { checksumRetryUnit = unit }(checksumRetryUnit)
+	checksumRetryUnit = time.Millisecond
+	server := miniotest.NewServer()
+	defer server.Close()
+	t.Setenv(mcEnvHostPrefix+"flaky", server.AliasURL())
+	server.MakeBucket("bucket")
+
+	// The first upload of every object is corrupted, those of "broken"
+	// always are.
+	puts := map[string]int{}
+	server.CorruptPut = func(key string) bool {
+		puts[key]++
+		return puts[key] == 1 || key == "broken"
+	}
+
+	path := filepath.Join(t.TempDir(), "object")
+	if e := os.WriteFile(path, []byte("checksummed content"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	upload := func(key string, retries int) URLs {
+		t.Helper()
+		ctx := context.Background()
+		var urls URLs
+		for cpURLs := range prepareCopyURLs(ctx, prepareCopyURLsOpts{
+			sourceURLs: []string{path},
+			targetURL:  "flaky/bucket/" + key,
+		}) {
+			if cpURLs.Error != nil {
+				t.Fatal(cpURLs.Error)
+			}
+			cpURLs.MD5 = true
+			urls = doCopyWithChecksumRetry(ctx, doCopyOpts{cpURLs: cpURLs, pg: newAccounter(0)}, retries)
+		}
+		return urls
+	}
+
+	testCases := []struct {
+		key        string
+		retries    int
+		mismatch   bool
+		expectPuts int
+	}{
+		// Without retries, the first corrupted upload fails.
+		{key: "once", retries: 0, mismatch: true, expectPuts: 1},
+		// A transient mismatch is fixed by the next upload.
+		{key: "transient", retries: 2, mismatch: false, expectPuts: 2},
+		// A persistent mismatch fails after every retry.
+		{key: "broken", retries: 2, mismatch: true, expectPuts: 3},
+	}
+	for _, tc := range testCases {
+		urls := upload(tc.key, tc.retries)
+		if isChecksumMismatch(urls.Error) != tc.mismatch {
+			t.Fatalf("%s: expected a checksum mismatch %v, got %v", tc.key, tc.mismatch, urls.Error)
+		}
+		if !tc.mismatch && urls.Error != nil {
+			t.Fatalf("%s: %v", tc.key, urls.Error)
+		}
+		if puts[tc.key] != tc.expectPuts {
+			t.Fatalf("%s: expected %d uploads, got %d", tc.key, tc.expectPuts, puts[tc.key])
+		}
+	}
+	if o, ok := server.Object("bucket", "transient"); !ok || string(o.Data) != "checksummed content" {
+		t.Fatal("expected the retried upload to store the content")
+	}
+}
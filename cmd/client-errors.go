@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 	"time"
 )
 
@@ -34,6 +35,20 @@ func (e APINotImplemented) Error() string {
 	return "`" + e.API + "` is not supported for `" + e.APIType + "`."
 }
 
+// FeatureNotSupported - the server answered NotImplemented to a feature.
+type FeatureNotSupported struct {
+	Feature string
+	Err     error
+}
+
+func (e FeatureNotSupported) Error() string {
+	return "Your endpoint does not support " + e.Feature + "; use a different method."
+}
+
+func (e FeatureNotSupported) Unwrap() error {
+	return e.Err
+}
+
 // InvalidArgument - passed argument is invalid for this operation
 type InvalidArgument struct{}
 
@@ -176,6 +191,25 @@ func (e ObjectMissing) Error() string {
 	return "Object does not exist"
 }
 
+// ObjectRestoreInProgress - a restore of the object is already running.
+type ObjectRestoreInProgress struct {
+	Object string
+}
+
+func (e ObjectRestoreInProgress) Error() string {
+	return "Object `" + e.Object + "` is already being restored."
+}
+
+// UploadPartMissing - a multipart upload lacks a part before its last one.
+type UploadPartMissing struct {
+	UploadID   string
+	PartNumber int
+}
+
+func (e UploadPartMissing) Error() string {
+	return fmt.Sprintf("Part %d of upload `%s` has not been uploaded.", e.PartNumber, e.UploadID)
+}
+
 // ObjectIsDeleteMarker - object is a delete marker as latest
 type ObjectIsDeleteMarker struct{}
 
@@ -194,6 +228,33 @@ func (e UnexpectedShortWrite) Error() string {
 	return msg
 }
 
+// DiskFull - the filesystem of a downloaded file ran out of space.
+type DiskFull struct {
+	Path         string
+	TotalSize    int64 // -1 if unknown
+	TotalWritten int64
+}
+
+func (e DiskFull) Error() string {
+	dir := filepath.Dir(e.Path)
+	if e.TotalSize < 0 {
+		return fmt.Sprintf("No space left on the filesystem of `%s`, only `%d` bytes of `%s` were written.", dir, e.TotalWritten, e.Path)
+	}
+	return fmt.Sprintf("No space left on the filesystem of `%s`, only `%d` of `%d` bytes of `%s` were written, `%d` bytes remaining.",
+		dir, e.TotalWritten, e.TotalSize, e.Path, e.TotalSize-e.TotalWritten)
+}
+
+// CrossDeviceRename (EXDEV) - a download cannot be renamed into place.
+type CrossDeviceRename struct {
+	Source string
+	Target string
+}
+
+func (e CrossDeviceRename) Error() string {
+	return fmt.Sprintf("Unable to rename `%s` to `%s` across filesystems. Downloads are written to a temporary file in the folder of their target, make sure this folder is on the same filesystem as `%s`.",
+		e.Source, e.Target, e.Target)
+}
+
 // UnexpectedEOF (EPIPE) - reader closed prematurely.
 type UnexpectedEOF struct {
 	TotalSize    int64
@@ -213,6 +274,16 @@ func (e UnexpectedExcessRead) Error() string {
 	return msg
 }
 
+// ChecksumMismatch - transferred object does not match its checksum.
+type ChecksumMismatch struct {
+	Object        string
+	Expected, Got string
+}
+
+func (e ChecksumMismatch) Error() string {
+	return "Checksum mismatch for `" + e.Object + "`. Expected `" + e.Expected + "`, but got `" + e.Got + "`."
+}
+
 // SameFile - source and destination are same files.
 type SameFile struct {
 	Source, Destination string
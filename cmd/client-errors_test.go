@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+	minio "github.com/minio/minio-go/v7"
+)
+
+// notImplemented fails the multi-delete requests, so that miniotest, which
+// does not implement tagging, implements neither like some servers.
+func notImplemented(r miniotest.Request) *miniotest.Fault {
+	if r.Method != http.MethodPost || !r.Query.Has("delete") {
+		return nil
+	}
+	return &miniotest.Fault{
+		Status:  http.StatusNotImplemented,
+		Code:    "NotImplemented",
+		Message: "A header you provided implies functionality that is not implemented",
+	}
+}
+
+func TestNotImplementedFeature(t *testing.T) {
+	server := newS3TestServer(t)
+	server.PutObject("bucket", "object", []byte("data"))
+
+	_, err := newS3TestClient(t, "s3test/bucket/object").GetTags(context.Background(), "")
+	if _, ok := err.ToGoError().(FeatureNotSupported); !ok {
+		t.Fatalf("expected FeatureNotSupported, got %v", err)
+	}
+	if !strings.Contains(err.ToGoError().Error(), "does not support tagging") {
+		t.Fatalf("unexpected error message %q", err.ToGoError())
+	}
+	var errResp minio.ErrorResponse
+	if !errors.As(err.ToGoError(), &errResp) || errResp.Code != "NotImplemented" {
+		t.Fatalf("expected the response of the server to be kept, got %v", err)
+	}
+
+	for _, testCase := range []struct {
+		e        error
+		explains bool
+	}{
+		{minio.ErrorResponse{Code: "NotImplemented"}, true},
+		{minio.ErrorResponse{StatusCode: http.StatusNotImplemented}, true},
+		{minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}, false},
+		{errors.New("NotImplemented"), false},
+	} {
+		_, explains := notSupportedError(testCase.e).(FeatureNotSupported)
+		if explains != testCase.explains {
+			t.Fatalf("%#v: expected %v, got %v", testCase.e, testCase.explains, explains)
+		}
+	}
+}
+
+func TestRemoveWithoutMultiDelete(t *testing.T) {
+	server := newS3TestServer(t)
+	server.Faults = notImplemented
+	clnt := newS3TestClient(t, "s3test/bucket")
+	remove := func(keys ...string) {
+		contentCh := make(chan *ClientContent, len(keys))
+		for _, key := range keys {
+			server.PutObject("bucket", key, []byte(key))
+			contentCh <- &ClientContent{URL: *newClientURL(server.URL + "/bucket/" + key)}
+		}
+		close(contentCh)
+
+		var removed []string
+		for result := range clnt.Remove(context.Background(), false, false, false, false, contentCh) {
+			if result.Err != nil {
+				t.Fatalf("unexpected error %v", result.Err)
+			}
+			removed = append(removed, result.ObjectName)
+		}
+		sort.Strings(removed)
+		if strings.Join(removed, ",") != strings.Join(keys, ",") {
+			t.Fatalf("expected %v to be removed, got %v", keys, removed)
+		}
+	}
+
+	remove("a", "b", "c")
+	// The server is not asked for a multi-delete again.
+	remove("d", "e")
+
+	var multiDeletes int
+	var removed []string
+	for _, r := range server.Requests() {
+		switch {
+		case r.Method == http.MethodPost && r.Query.Has("delete"):
+			multiDeletes++
+		case r.Method == http.MethodDelete:
+			removed = append(removed, r.Key)
+		}
+	}
+	if multiDeletes != 1 {
+		t.Fatalf("expected a single multi-delete request, got %d", multiDeletes)
+	}
+	if strings.Join(removed, ",") != "a,b,c,d,e" || len(server.Keys("bucket")) != 0 {
+		t.Fatalf("unexpected removed objects %v", removed)
+	}
+}
@@ -19,6 +19,8 @@ package cmd
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -53,7 +55,8 @@ type fsClient struct {
 }
 
 const (
-	partSuffix       = ".part.minio"
+	partFileInfix    = ".mc-part-"
+	legacyPartSuffix = ".part.minio"
 	slashSeperator   = "/"
 	metadataKey      = "X-Amz-Meta-Mc-Attrs"
 	metadataKeyS3Cmd = "X-Amz-Meta-S3cmd-Attrs"
@@ -128,6 +131,9 @@ func isIgnoredFile(filename string) bool {
 	return false
 }
 
+// Close does nothing, a fsClient holds no connections.
+func (f *fsClient) Close() {}
+
 // URL get url.
 func (f *fsClient) GetURL() ClientURL {
 	return *f.PathURL
@@ -300,19 +306,35 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 
 	objectPath := f.PathURL.Path
 
-	// Write to a temporary file "object.part.minio" before commit.
-	objectPartPath := objectPath + partSuffix
-
-	// We cannot resume this operation, then we
-	// should remove any partial download if any.
-	defer os.Remove(objectPartPath)
-
-	tmpFile, e := os.OpenFile(objectPartPath, os.O_CREATE|os.O_WRONLY, 0o666)
+	// Write to a temporary file "object.mc-part-<random>" before commit,
+	// or append to the one kept by the interrupted download resumed.
+	var (
+		tmpFile        *os.File
+		objectPartPath string
+		e              error
+	)
+	switch {
+	case opts.resumeOffset > 0:
+		tmpFile, objectPartPath, e = openResumedPartFile(objectPath, opts.resumeOffset)
+	case opts.resume:
+		// The leftovers which cannot be resumed are started over.
+		for _, partPath := range partFiles(objectPath) {
+			os.Remove(partPath)
+		}
+		fallthrough
+	default:
+		tmpFile, objectPartPath, e = openPartFile(objectPath)
+	}
 	if e != nil {
 		err := f.toClientError(e, f.PathURL.Path)
 		return 0, err.Trace(f.PathURL.Path)
 	}
 
+	// Remove any partial download, unless it is kept to be resumed.
+	if !opts.resume {
+		defer os.Remove(objectPartPath)
+	}
+
 	attr := make(map[string]string)
 	if _, ok := opts.metadata[metadataKey]; ok && opts.isPreserve {
 		attr, e = parseAttribute(opts.metadata)
@@ -326,10 +348,11 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 		}
 	}
 
-	totalWritten, e := io.Copy(tmpFile, hookreader.NewHook(reader, progress))
+	fw := &fileWriter{file: tmpFile}
+	totalWritten, e := io.Copy(fw, hookreader.NewHook(reader, progress))
 	if e != nil {
 		tmpFile.Close()
-		return 0, probe.NewError(e)
+		return 0, writeError(fw.err, e, objectPath, totalWritten, size)
 	}
 
 	// Close the input reader as well, if possible.
@@ -341,11 +364,18 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 		}
 	}
 
+	// Flush the content to disk, so that a crash after the rename
+	// never leaves a partially written file at the final path.
+	if e = tmpFile.Sync(); e != nil {
+		tmpFile.Close()
+		return totalWritten, writeError(e, e, objectPath, totalWritten, size)
+	}
+
 	// Close the file before renaming, we need to do this
 	// specifically for windows users - windows explicitly
 	// disallows renames on Open() fd's by default.
 	if e = tmpFile.Close(); e != nil {
-		return totalWritten, probe.NewError(e)
+		return totalWritten, writeError(e, e, objectPath, totalWritten, size)
 	}
 
 	// Following verification is needed only for input size greater than '0'.
@@ -367,7 +397,7 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 	}
 
 	// Safely completed put. Now commit by renaming to actual filename.
-	if e = os.Rename(objectPartPath, objectPath); e != nil {
+	if e = renamePartFile(objectPartPath, objectPath); e != nil {
 		err := f.toClientError(e, objectPath)
 		return totalWritten, err.Trace(objectPartPath, objectPath)
 	}
@@ -387,6 +417,130 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 	return totalWritten, nil
 }
 
+// fileWriter writes to a file and keeps the error of the writes, to tell
+// them apart from the errors of the reader. A short write is an error.
+type fileWriter struct {
+	file *os.File
+	err  error
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	n, e := w.file.Write(p)
+	if e == nil && n < len(p) {
+		e = io.ErrShortWrite
+	}
+	if e != nil && w.err == nil {
+		w.err = e
+	}
+	return n, e
+}
+
+// openPartFile creates the temporary file a download to objectPath is
+// written to, next to objectPath so that renaming it into place stays
+// on the same filesystem. It is a variable to be replaced in tests.
+var openPartFile = func(objectPath string) (*os.File, string, error) {
+	var e error
+	for i := 0; i < 10; i++ {
+		var suffix [4]byte
+		if _, e = rand.Read(suffix[:]); e != nil {
+			return nil, "", e
+		}
+		partPath := objectPath + partFileInfix + hex.EncodeToString(suffix[:])
+		f, e := os.OpenFile(partPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o666)
+		if e == nil {
+			return f, partPath, nil
+		}
+		if !os.IsExist(e) {
+			return nil, "", e
+		}
+	}
+	return nil, "", e
+}
+
+// openResumedPartFile opens the temporary file of the interrupted
+// download to objectPath for appending to its first offset bytes.
+func openResumedPartFile(objectPath string, offset int64) (*os.File, string, error) {
+	partPath, size := resumablePartFile(objectPath)
+	if partPath == "" || size != offset {
+		return nil, "", fmt.Errorf("the partial download of `%s` changed since it was resumed", objectPath)
+	}
+	f, e := os.OpenFile(partPath, os.O_WRONLY|os.O_APPEND, 0o666)
+	return f, partPath, e
+}
+
+// resumablePartFile returns the temporary file kept by an interrupted
+// download to objectPath and its size, if there is exactly one.
+func resumablePartFile(objectPath string) (string, int64) {
+	parts := partFiles(objectPath)
+	if len(parts) != 1 {
+		return "", 0
+	}
+	st, e := os.Stat(parts[0])
+	if e != nil || !st.Mode().IsRegular() {
+		return "", 0
+	}
+	return parts[0], st.Size()
+}
+
+// partFileObject returns the path of the download whose temporary file
+// is at path, if path is one. The ".part.minio" suffix of the previous
+// releases is still recognized so that their leftovers are found.
+func partFileObject(path string) (string, bool) {
+	if strings.HasSuffix(path, legacyPartSuffix) && len(path) > len(legacyPartSuffix) {
+		return strings.TrimSuffix(path, legacyPartSuffix), true
+	}
+	i := strings.LastIndex(path, partFileInfix)
+	if i <= 0 {
+		return "", false
+	}
+	suffix := path[i+len(partFileInfix):]
+	if b, e := hex.DecodeString(suffix); e != nil || len(b) != 4 {
+		return "", false
+	}
+	return path[:i], true
+}
+
+// partFiles returns the temporary files of the downloads to objectPath.
+func partFiles(objectPath string) []string {
+	entries, e := os.ReadDir(filepath.Dir(objectPath))
+	if e != nil {
+		return nil
+	}
+	var parts []string
+	for _, entry := range entries {
+		path := filepath.Join(filepath.Dir(objectPath), entry.Name())
+		if p, ok := partFileObject(path); ok && p == filepath.Clean(objectPath) {
+			parts = append(parts, path)
+		}
+	}
+	return parts
+}
+
+// renamePartFile moves a complete download into place. Renames across
+// filesystems fail, such as when the folder of objectPath is a mount
+// point of another filesystem than objectPath.
+func renamePartFile(partPath, objectPath string) error {
+	e := os.Rename(partPath, objectPath)
+	if errors.Is(e, syscall.EXDEV) {
+		return CrossDeviceRename{Source: partPath, Target: objectPath}
+	}
+	return e
+}
+
+// writeError returns the error of a download to objectPath which failed
+// with e after written of size bytes, writeErr is the error of the
+// writes, if any. A full filesystem is reported with the bytes left to
+// download, the partial file is removed by the caller.
+func writeError(writeErr, e error, objectPath string, written, size int64) *probe.Error {
+	if writeErr != nil && errors.Is(writeErr, syscall.ENOSPC) {
+		if size <= 0 {
+			size = -1
+		}
+		return probe.NewError(DiskFull{Path: objectPath, TotalSize: size, TotalWritten: written})
+	}
+	return probe.NewError(e)
+}
+
 // Put - create a new file with metadata.
 func (f *fsClient) Put(ctx context.Context, reader io.Reader, size int64, progress io.Reader, opts PutOptions) (int64, *probe.Error) {
 	return f.put(ctx, reader, size, progress, opts)
@@ -414,19 +568,17 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 
 	objectPath := f.PathURL.Path
 
-	// Write to a temporary file "object.part.minio" before commit.
-	objectPartPath := objectPath + partSuffix
-
-	// We cannot resume this operation, then we
-	// should remove any partial download if any.
-	defer os.Remove(objectPartPath)
-
-	tmpFile, e := os.OpenFile(objectPartPath, os.O_CREATE|os.O_WRONLY, 0o666)
+	// Write to a temporary file "object.mc-part-<random>" before commit.
+	tmpFile, objectPartPath, e := openPartFile(objectPath)
 	if e != nil {
 		err := f.toClientError(e, f.PathURL.Path)
 		return 0, err.Trace(f.PathURL.Path)
 	}
 
+	// We cannot resume this operation, then we
+	// should remove any partial download if any.
+	defer os.Remove(objectPartPath)
+
 	attr := make(map[string]string)
 	if _, ok := opts.metadata[metadataKey]; ok && opts.isPreserve {
 		attr, e = parseAttribute(opts.metadata)
@@ -440,10 +592,11 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 		}
 	}
 
-	totalWritten, e := io.CopyN(tmpFile, hookreader.NewHook(reader, progress), size)
+	fw := &fileWriter{file: tmpFile}
+	totalWritten, e := io.CopyN(fw, hookreader.NewHook(reader, progress), size)
 	if e != nil {
 		tmpFile.Close()
-		return 0, probe.NewError(e)
+		return 0, writeError(fw.err, e, objectPath, totalWritten, size)
 	}
 
 	// Close the input reader as well, if possible.
@@ -455,11 +608,18 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 		}
 	}
 
+	// Flush the content to disk, so that a crash after the rename
+	// never leaves a partially written file at the final path.
+	if e = tmpFile.Sync(); e != nil {
+		tmpFile.Close()
+		return totalWritten, writeError(e, e, objectPath, totalWritten, size)
+	}
+
 	// Close the file before renaming, we need to do this
 	// specifically for windows users - windows explicitly
 	// disallows renames on Open() fd's by default.
 	if e = tmpFile.Close(); e != nil {
-		return totalWritten, probe.NewError(e)
+		return totalWritten, writeError(e, e, objectPath, totalWritten, size)
 	}
 
 	// Following verification is needed only for input size greater than '0'.
@@ -474,7 +634,7 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 	}
 
 	// Safely completed put. Now commit by renaming to actual filename.
-	if e = os.Rename(objectPartPath, objectPath); e != nil {
+	if e = renamePartFile(objectPartPath, objectPath); e != nil {
 		err := f.toClientError(e, objectPath)
 		return totalWritten, err.Trace(objectPartPath, objectPath)
 	}
@@ -586,6 +746,12 @@ func (f *fsClient) Get(_ context.Context, opts GetOptions) (io.ReadCloser, *Clie
 		content.Metadata[metadataKey] = fileAttr
 	}
 
+	if opts.RangeLength > 0 {
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(fileData, opts.RangeLength), fileData}, content, nil
+	}
 	return fileData, content, nil
 }
 
@@ -658,12 +824,20 @@ func (f *fsClient) Remove(_ context.Context, isIncomplete, _, _, _ bool, content
 				}
 				continue
 			}
-			name := content.URL.Path
-			// Add partSuffix for incomplete uploads.
+			names := []string{content.URL.Path}
+			// Remove the temporary files of incomplete downloads.
 			if isIncomplete {
-				name += partSuffix
+				if names = partFiles(content.URL.Path); len(names) == 0 {
+					// ignore if path already removed.
+					continue
+				}
+			}
+			var e error
+			for _, name := range names {
+				if e = deleteFile(f.PathURL.Path, name); e != nil {
+					break
+				}
 			}
-			e := deleteFile(f.PathURL.Path, name)
 			if e == nil {
 				res := RemoveResult{}
 				res.ObjectName = content.URL.Path
@@ -779,14 +953,15 @@ func (f *fsClient) List(_ context.Context, opts ListOptions) <-chan *ClientConte
 	// only show partly uploaded files,
 	go func() {
 		for c := range contentCh {
+			objectPath, isPart := partFileObject(c.URL.Path)
 			if opts.Incomplete {
-				if !strings.HasSuffix(c.URL.Path, partSuffix) {
+				if !isPart {
 					continue
 				}
 				// Strip part suffix
-				c.URL.Path = strings.Split(c.URL.Path, partSuffix)[0]
+				c.URL.Path = objectPath
 			} else {
-				if strings.HasSuffix(c.URL.Path, partSuffix) {
+				if isPart {
 					continue
 				}
 			}
@@ -1342,7 +1517,10 @@ func (f *fsClient) fsStat(isIncomplete bool) (os.FileInfo, *probe.Error) {
 	}
 
 	if isIncomplete {
-		fpath += partSuffix
+		fpath += partFileInfix
+		if parts := partFiles(f.PathURL.Path); len(parts) > 0 {
+			fpath = parts[0]
+		}
 	}
 
 	st, e = os.Stat(fpath)
@@ -1355,6 +1533,22 @@ func (f *fsClient) fsStat(isIncomplete bool) (os.FileInfo, *probe.Error) {
 func (f *fsClient) AddUserAgent(_, _ string) {
 }
 
+// GetObjectACL - unsupported API
+func (f *fsClient) GetObjectACL(_ context.Context) (accessControlPolicy, *probe.Error) {
+	return accessControlPolicy{}, probe.NewError(APINotImplemented{
+		API:     "GetObjectACL",
+		APIType: "filesystem",
+	})
+}
+
+// PutObjectACL - unsupported API
+func (f *fsClient) PutObjectACL(_ context.Context, _ string, _ accessControlPolicy) *probe.Error {
+	return probe.NewError(APINotImplemented{
+		API:     "PutObjectACL",
+		APIType: "filesystem",
+	})
+}
+
 // Get Object Tags
 func (f *fsClient) GetTags(_ context.Context, _ string) (map[string]string, *probe.Error) {
 	return nil, probe.NewError(APINotImplemented{
@@ -1493,13 +1687,53 @@ func (f *fsClient) GetBucketInfo(_ context.Context) (BucketInfo, *probe.Error) {
 }
 
 // Restore object - not implemented
-func (f *fsClient) Restore(_ context.Context, _ string, _ int) *probe.Error {
+func (f *fsClient) Restore(_ context.Context, _ string, _ int, _ minio.TierType) *probe.Error {
 	return probe.NewError(APINotImplemented{
 		API:     "Restore",
 		APIType: "filesystem",
 	})
 }
 
+// NewMultipartUpload - multipart uploads not implemented for filesystem.
+func (f *fsClient) NewMultipartUpload(_ context.Context, _ PutOptions) (string, *probe.Error) {
+	return "", probe.NewError(APINotImplemented{
+		API:     "NewMultipartUpload",
+		APIType: "filesystem",
+	})
+}
+
+// UploadPart - multipart uploads not implemented for filesystem.
+func (f *fsClient) UploadPart(_ context.Context, _ string, _ int, _ io.Reader, _ int64, _ PutOptions) (string, *probe.Error) {
+	return "", probe.NewError(APINotImplemented{
+		API:     "UploadPart",
+		APIType: "filesystem",
+	})
+}
+
+// CopyPart - multipart uploads not implemented for filesystem.
+func (f *fsClient) CopyPart(_ context.Context, _ string, _ int, _, _ int64, _ string, _ PutOptions) (string, *probe.Error) {
+	return "", probe.NewError(APINotImplemented{
+		API:     "CopyPart",
+		APIType: "filesystem",
+	})
+}
+
+// UploadedParts - multipart uploads not implemented for filesystem.
+func (f *fsClient) UploadedParts(_ context.Context, _ string) ([]int, *probe.Error) {
+	return nil, probe.NewError(APINotImplemented{
+		API:     "UploadedParts",
+		APIType: "filesystem",
+	})
+}
+
+// CompleteMultipartUpload - multipart uploads not implemented for filesystem.
+func (f *fsClient) CompleteMultipartUpload(_ context.Context, _ string, _ PutOptions) (string, int, *probe.Error) {
+	return "", 0, probe.NewError(APINotImplemented{
+		API:     "CompleteMultipartUpload",
+		APIType: "filesystem",
+	})
+}
+
 // OD Get - not implemented
 func (f *fsClient) GetPart(_ context.Context, _ int) (io.ReadCloser, *probe.Error) {
 	return nil, probe.NewError(APINotImplemented{
@@ -269,6 +269,144 @@ func (s *TestSuite) TestPut(c *checkv1.C) {
 	c.Assert(n, checkv1.Equals, int64(len(data)))
 }
 
+// Test a download to a full filesystem.
+func (s *TestSuite) TestPutDiskFull(c *checkv1.C) {
+	if _, e := os.Stat("/dev/full"); e != nil {
+		c.Skip("/dev/full is not available")
+	}
+	root, e := os.MkdirTemp(os.TempDir(), "fs-")
+	c.Assert(e, checkv1.IsNil)
+	defer os.RemoveAll(root)
+
+	// Writes to /dev/full fail with ENOSPC.
+	objectPath := filepath.Join(root, "object")
+	partPath := objectPath + partFileInfix + "00000000"
+	c.Assert(os.Symlink("/dev/full", partPath), checkv1.IsNil)
+	defer func(open func(string) (*os.File, string, error)) { openPartFile = open }(openPartFile)
+	openPartFile = func(string) (*os.File, string, error) {
+		f, e := os.OpenFile(partPath, os.O_WRONLY, 0)
+		return f, partPath, e
+	}
+	fsClient, err := fsNew(objectPath)
+	c.Assert(err, checkv1.IsNil)
+
+	data := "hello"
+	_, err = fsClient.Put(context.Background(), bytes.NewReader([]byte(data)), int64(len(data)), nil, PutOptions{})
+	c.Assert(err, checkv1.NotNil)
+	diskFull, ok := err.ToGoError().(DiskFull)
+	c.Assert(ok, checkv1.Equals, true)
+	c.Assert(diskFull, checkv1.DeepEquals, DiskFull{Path: objectPath, TotalSize: 5, TotalWritten: 0})
+	c.Assert(diskFull.Error(), checkv1.Matches, ".*`5` bytes remaining.*")
+
+	// Nothing that looks like a complete download is left.
+	_, e = os.Lstat(objectPath)
+	c.Assert(os.IsNotExist(e), checkv1.Equals, true)
+	_, e = os.Lstat(partPath)
+	c.Assert(os.IsNotExist(e), checkv1.Equals, true)
+}
+
+// Test that a download is only visible at its final path once complete.
+func (s *TestSuite) TestPutAtomic(c *checkv1.C) {
+	root, e := os.MkdirTemp(os.TempDir(), "fs-")
+	c.Assert(e, checkv1.IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	c.Assert(os.WriteFile(objectPath, []byte("previous"), 0o600), checkv1.IsNil)
+	fsClient, err := fsNew(objectPath)
+	c.Assert(err, checkv1.IsNil)
+
+	// The reader fails halfway, while the temporary file is listed as an
+	// incomplete download and the previous content is untouched.
+	reader := io.MultiReader(bytes.NewReader([]byte("hel")), readerFunc(func([]byte) (int, error) {
+		parts := partFiles(objectPath)
+		c.Assert(parts, checkv1.HasLen, 1)
+		object, ok := partFileObject(parts[0])
+		c.Assert(ok, checkv1.Equals, true)
+		c.Assert(object, checkv1.Equals, objectPath)
+		_, err := fsClient.Stat(context.Background(), StatOptions{incomplete: true})
+		c.Assert(err, checkv1.IsNil)
+		data, e := os.ReadFile(objectPath)
+		c.Assert(e, checkv1.IsNil)
+		c.Assert(string(data), checkv1.Equals, "previous")
+		return 0, io.ErrUnexpectedEOF
+	}))
+	_, err = fsClient.Put(context.Background(), reader, 5, nil, PutOptions{})
+	c.Assert(err, checkv1.NotNil)
+	data, e := os.ReadFile(objectPath)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(data), checkv1.Equals, "previous")
+	c.Assert(partFiles(objectPath), checkv1.HasLen, 0)
+
+	_, err = fsClient.Put(context.Background(), bytes.NewReader([]byte("hello")), 5, nil, PutOptions{})
+	c.Assert(err, checkv1.IsNil)
+	data, e = os.ReadFile(objectPath)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(data), checkv1.Equals, "hello")
+	c.Assert(partFiles(objectPath), checkv1.HasLen, 0)
+
+	for _, name := range []string{"object.mc-part-", "object.mc-part-xyz", "object.mc-part-0123"} {
+		_, ok := partFileObject(filepath.Join(root, name))
+		c.Assert(ok, checkv1.Equals, false)
+	}
+
+	// The temporary files of the previous releases are still incomplete
+	// downloads, which are neither listed nor left by rm --incomplete.
+	legacyPath := objectPath + legacyPartSuffix
+	c.Assert(os.WriteFile(legacyPath, []byte("he"), 0o600), checkv1.IsNil)
+	object, ok := partFileObject(legacyPath)
+	c.Assert(ok, checkv1.Equals, true)
+	c.Assert(object, checkv1.Equals, objectPath)
+	rootClient, err := fsNew(root + string(os.PathSeparator))
+	c.Assert(err, checkv1.IsNil)
+	var names []string
+	for content := range rootClient.List(context.Background(), ListOptions{ShowDir: DirNone}) {
+		c.Assert(content.Err, checkv1.IsNil)
+		names = append(names, filepath.Base(content.URL.Path))
+	}
+	c.Assert(names, checkv1.DeepEquals, []string{"object"})
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: *newClientURL(objectPath)}
+	close(contentCh)
+	for result := range rootClient.Remove(context.Background(), true, false, false, false, contentCh) {
+		c.Assert(result.Err, checkv1.IsNil)
+	}
+	_, e = os.Stat(legacyPath)
+	c.Assert(os.IsNotExist(e), checkv1.Equals, true)
+}
+
+// Test that a resumed download keeps its temporary file on failure, and
+// appends to it once tried again.
+func (s *TestSuite) TestPutResume(c *checkv1.C) {
+	root, e := os.MkdirTemp(os.TempDir(), "fs-")
+	c.Assert(e, checkv1.IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsClient, err := fsNew(objectPath)
+	c.Assert(err, checkv1.IsNil)
+
+	reader := io.MultiReader(bytes.NewReader([]byte("hel")), readerFunc(func([]byte) (int, error) {
+		return 0, io.ErrUnexpectedEOF
+	}))
+	_, err = fsClient.Put(context.Background(), reader, 5, nil, PutOptions{resume: true})
+	c.Assert(err, checkv1.NotNil)
+	partPath, size := resumablePartFile(objectPath)
+	c.Assert(partPath, checkv1.Not(checkv1.Equals), "")
+	c.Assert(size, checkv1.Equals, int64(3))
+
+	_, err = fsClient.Put(context.Background(), bytes.NewReader([]byte("lo")), 2, nil, PutOptions{resume: true, resumeOffset: 3})
+	c.Assert(err, checkv1.IsNil)
+	data, e := os.ReadFile(objectPath)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(data), checkv1.Equals, "hello")
+	c.Assert(partFiles(objectPath), checkv1.HasLen, 0)
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
 // Test read a file.
 func (s *TestSuite) TestGet(c *checkv1.C) {
 	root, e := os.MkdirTemp(os.TempDir(), "fs-")
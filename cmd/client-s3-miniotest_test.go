@@ -0,0 +1,389 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+)
+
+// newS3TestServer starts a miniotest server with a bucket, as the alias
+// "s3test".
+func newS3TestServer(t *testing.T) *miniotest.Server {
+	t.Helper()
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	t.Cleanup(server.Close)
+	t.Setenv(mcEnvHostPrefix+"s3test", server.AliasURL())
+	server.MakeBucket("bucket")
+	return server
+}
+
+// newS3TestFrontServer starts a miniotest server with a bucket, behind a
+// front server whose handler is returned by front, to inject the faults
+// miniotest does not. The alias "s3test" is the front server.
+func newS3TestFrontServer(t *testing.T, front func(*miniotest.Server) http.Handler) (*miniotest.Server, *httptest.Server) {
+	t.Helper()
+	server := newS3TestServer(t)
+	frontServer := httptest.NewServer(front(server))
+	t.Cleanup(frontServer.Close)
+	t.Setenv(mcEnvHostPrefix+"s3test", "http://"+miniotest.AccessKey+":"+miniotest.SecretKey+"@"+strings.TrimPrefix(frontServer.URL, "http://"))
+	return server, frontServer
+}
+
+// newMiniotestConfig returns the configuration of a client of a miniotest
+// server at url.
+func newMiniotestConfig(url string) *Config {
+	conf := newTestS3Config(url)
+	conf.AccessKey, conf.SecretKey = miniotest.AccessKey, miniotest.SecretKey
+	return conf
+}
+
+// headerWriter sets headers of a response over those set by its handler,
+// to fake the responses of other servers.
+type headerWriter struct {
+	http.ResponseWriter
+	header map[string]string
+}
+
+func (w headerWriter) WriteHeader(status int) {
+	for k, v := range w.header {
+		w.ResponseWriter.Header().Set(k, v)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func newS3TestClient(t *testing.T, urlStr string) Client {
+	t.Helper()
+	clnt, err := newClient(urlStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clnt
+}
+
+// fastRetries shortens the waits of retried requests for the test.
+func fastRetries(t *testing.T) {
+	unit, throttleUnit := minio.DefaultRetryUnit, throttleRetryUnit
+	t.Cleanup(func() { minio.DefaultRetryUnit, throttleRetryUnit = unit, throttleUnit })
+	minio.DefaultRetryUnit, throttleRetryUnit = time.Millisecond, time.Millisecond
+}
+
+// listRequests returns the number of object listing requests received
+// by server, without those of the bucket location.
+func listRequests(server *miniotest.Server) (n int) {
+	for _, r := range server.Requests() {
+		if r.Method == http.MethodGet && r.Key == "" && !r.Query.Has("location") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestS3ClientListPagination(t *testing.T) {
+	server := newS3TestServer(t)
+	for _, key := range []string{"a", "b/1", "b/2", "b/3", "c/d/1", "c/e", "d"} {
+		server.PutObject("bucket", key, []byte(key))
+	}
+	ctx := context.Background()
+
+	for _, testCase := range []struct {
+		url     string
+		maxKeys int
+		opts    ListOptions
+		keys    []string
+		pages   int
+	}{
+		{"s3test/bucket/", 0, ListOptions{Recursive: true, ShowDir: DirNone}, []string{"a", "b/1", "b/2", "b/3", "c/d/1", "c/e", "d"}, 1},
+		{"s3test/bucket/", 2, ListOptions{Recursive: true, ShowDir: DirNone}, []string{"a", "b/1", "b/2", "b/3", "c/d/1", "c/e", "d"}, 4},
+		{"s3test/bucket/", 1, ListOptions{ShowDir: DirFirst}, []string{"a", "b/", "c/", "d"}, 4},
+		{"s3test/bucket/b/", 2, ListOptions{Recursive: true, ShowDir: DirNone}, []string{"b/1", "b/2", "b/3"}, 2},
+		{"s3test/bucket/c/", 1, ListOptions{ShowDir: DirFirst}, []string{"c/d/", "c/e"}, 2},
+	} {
+		server.MaxKeys = testCase.maxKeys
+		server.ResetRequests()
+		var keys []string
+		for content := range newS3TestClient(t, testCase.url).List(ctx, testCase.opts) {
+			if content.Err != nil {
+				t.Fatalf("%s: %v", testCase.url, content.Err)
+			}
+			keys = append(keys, strings.TrimPrefix(content.URL.Path, "/bucket/"))
+		}
+		sort.Strings(keys)
+		if strings.Join(keys, " ") != strings.Join(testCase.keys, " ") {
+			t.Errorf("%s with %d keys per page: expected %v, got %v", testCase.url, testCase.maxKeys, testCase.keys, keys)
+		}
+		if pages := listRequests(server); pages != testCase.pages {
+			t.Errorf("%s with %d keys per page: expected %d pages, got %d", testCase.url, testCase.maxKeys, testCase.pages, pages)
+		}
+	}
+}
+
+func TestS3ClientRetries(t *testing.T) {
+	fastRetries(t)
+	server := newS3TestServer(t)
+	server.PutObject("bucket", "object", []byte("data"))
+	ctx := context.Background()
+
+	put := func(clnt Client) *probe.Error {
+		_, err := clnt.Put(ctx, bytes.NewReader([]byte("data")), 4, nil, PutOptions{})
+		return err
+	}
+	get := func(clnt Client) *probe.Error {
+		reader, _, err := clnt.Get(ctx, GetOptions{})
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		if data, e := io.ReadAll(reader); e != nil || string(data) != "data" {
+			t.Errorf("unexpected data %q, %v", data, e)
+		}
+		return nil
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		method   string
+		faults   func(miniotest.Request) *miniotest.Fault
+		do       func(Client) *probe.Error
+		err      string
+		requests int
+	}{
+		{"throttled put", http.MethodPut, miniotest.FailFirst(2, http.MethodPut, miniotest.SlowDown), put, "", 3},
+		{"throttled get", http.MethodGet, miniotest.FailFirst(1, http.MethodGet, miniotest.SlowDown), get, "", 2},
+		{"internal error put", http.MethodPut, miniotest.FailFirst(2, http.MethodPut, miniotest.InternalError), put, "", 3},
+		{"internal error get", http.MethodGet, miniotest.FailFirst(1, http.MethodGet, miniotest.InternalError), get, "", 2},
+		{"denied put", http.MethodPut, miniotest.FailFirst(1, http.MethodPut, func() *miniotest.Fault {
+			return &miniotest.Fault{Status: http.StatusForbidden, Code: "AccessDenied", Message: "Access Denied."}
+		}), put, "Insufficient permissions", 1},
+		{"quota exceeded put", http.MethodPut, miniotest.FailFirst(1, http.MethodPut, func() *miniotest.Fault {
+			return &miniotest.Fault{Status: http.StatusBadRequest, Code: "XMinioAdminBucketQuotaExceeded", Message: "Bucket quota exceeded."}
+		}), put, "Bucket quota exceeded.", 1},
+	} {
+		server.Faults = testCase.faults
+		server.ResetRequests()
+		err := testCase.do(newS3TestClient(t, "s3test/bucket/object"))
+		switch {
+		case testCase.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", testCase.name, err)
+		case testCase.err != "" && (err == nil || !strings.Contains(err.ToGoError().Error(), testCase.err)):
+			t.Errorf("%s: expected %q, got %v", testCase.name, testCase.err, err)
+		}
+		if n := server.RequestCount(testCase.method); n != testCase.requests {
+			t.Errorf("%s: expected %d %s requests, got %d", testCase.name, testCase.requests, testCase.method, n)
+		}
+	}
+}
+
+// A Config without MaxRetryTime, like those built outside of
+// newS3Config, still backs off on a throttling server.
+func TestS3ClientZeroConfigRetries(t *testing.T) {
+	fastRetries(t)
+	server := newS3TestServer(t)
+	server.PutObject("bucket", "object", []byte("data"))
+	server.Faults = miniotest.FailFirst(2, http.MethodHead, miniotest.SlowDown)
+
+	clnt, err := S3New(&Config{
+		HostURL:   server.URL + "/bucket/object",
+		AccessKey: miniotest.AccessKey,
+		SecretKey: miniotest.SecretKey,
+		Signature: "S3v4",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = clnt.Stat(context.Background(), StatOptions{}); err != nil {
+		t.Fatalf("expected the throttled stat to be retried, got %v", err)
+	}
+	if n := server.RequestCount(http.MethodHead); n != 3 {
+		t.Fatalf("expected 3 HEAD requests, got %d", n)
+	}
+}
+
+func TestS3ClientRedirects(t *testing.T) {
+	origin := newS3TestServer(t)
+	target := miniotest.NewServer()
+	defer target.Close()
+	targetHost := strings.TrimPrefix(target.URL, "http://")
+	ctx := context.Background()
+
+	// Redirected requests are signed again for the endpoint they are
+	// sent to, the later requests of the bucket are sent there directly.
+	for _, testCase := range []struct {
+		bucket string
+		fault  *miniotest.Fault
+	}{
+		{"permanent", miniotest.PermanentRedirect(targetHost)},
+		{"temporary", miniotest.TemporaryRedirect(target.URL + "/temporary/object")},
+	} {
+		target.PutObject(testCase.bucket, "object", []byte(testCase.bucket))
+		origin.Faults = func(r miniotest.Request) *miniotest.Fault {
+			if r.Bucket != testCase.bucket {
+				return nil
+			}
+			return testCase.fault
+		}
+		origin.ResetRequests()
+		clnt := newS3TestClient(t, "s3test/"+testCase.bucket+"/object")
+		for i := 0; i < 2; i++ {
+			reader, _, err := clnt.Get(ctx, GetOptions{})
+			if err != nil {
+				t.Fatalf("%s: %v", testCase.bucket, err)
+			}
+			data, e := io.ReadAll(reader)
+			reader.Close()
+			if e != nil || string(data) != testCase.bucket {
+				t.Fatalf("%s: unexpected data %q, %v", testCase.bucket, data, e)
+			}
+		}
+		if n := len(origin.Requests()); n != 1 {
+			t.Errorf("%s: expected a single request to the original endpoint, got %d", testCase.bucket, n)
+		}
+	}
+}
+
+func TestS3ClientMultipartComplete(t *testing.T) {
+	fastRetries(t)
+	server := newS3TestServer(t)
+	server.MinPartSize = 5 << 20
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("0123456789abcdef"), (12<<20)/16)
+
+	isComplete := func(r miniotest.Request) bool {
+		return r.Method == http.MethodPost && r.Query.Has("uploadId")
+	}
+	for _, testCase := range []struct {
+		name      string
+		failures  int
+		completes int
+	}{
+		{"complete", 0, 1},
+		{"complete retried", 2, 3},
+	} {
+		failures := testCase.failures
+		server.Faults = func(r miniotest.Request) *miniotest.Fault {
+			if !isComplete(r) || failures == 0 {
+				return nil
+			}
+			failures--
+			return miniotest.InternalError()
+		}
+		server.ResetRequests()
+		clnt := newS3TestClient(t, "s3test/bucket/"+testCase.name)
+		n, err := clnt.Put(ctx, bytes.NewReader(data), int64(len(data)), nil, PutOptions{multipartSize: 5 << 20, multipartThreads: 1})
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.name, err)
+		}
+		if n != int64(len(data)) {
+			t.Fatalf("%s: expected %d bytes uploaded, got %d", testCase.name, len(data), n)
+		}
+		stored, ok := server.Object("bucket", testCase.name)
+		if !ok || !bytes.Equal(stored.Data, data) {
+			t.Fatalf("%s: unexpected stored object", testCase.name)
+		}
+		if sizes := stored.PartSizes; len(sizes) != 3 || sizes[0] != 5<<20 || sizes[1] != 5<<20 || sizes[2] != 2<<20 {
+			t.Errorf("%s: unexpected part sizes %v", testCase.name, sizes)
+		}
+		if !strings.HasSuffix(stored.ETag, "-3") {
+			t.Errorf("%s: expected the ETag of 3 parts, got %s", testCase.name, stored.ETag)
+		}
+		completes := 0
+		for _, r := range server.Requests() {
+			if isComplete(r) {
+				completes++
+			}
+		}
+		if completes != testCase.completes {
+			t.Errorf("%s: expected %d completions, got %d", testCase.name, testCase.completes, completes)
+		}
+		if uploads := server.Uploads("bucket"); len(uploads) != 0 {
+			t.Errorf("%s: expected no upload in progress, got %v", testCase.name, uploads)
+		}
+	}
+}
+
+func TestS3ClientKeyEscaping(t *testing.T) {
+	server := newS3TestServer(t)
+	ctx := context.Background()
+
+	keys := []string{
+		"space in name.txt",
+		"dir/sub dir/file.txt",
+		"plus+sign",
+		"percent%20encoded",
+		"question?mark",
+		"hash#tag",
+		"semi;colon&amp=equals",
+		"tilde~star*paren(1)",
+		"quote'double\"",
+		"unicode/ünïcödé/日本語.txt",
+		"nfd/café",
+		"brackets[0]{1}",
+		"dollar$at@comma,colon:",
+		"back\\slash",
+	}
+	for _, key := range keys {
+		clnt := newS3TestClient(t, "s3test/bucket/"+key)
+		if _, err := clnt.Put(ctx, strings.NewReader(key), int64(len(key)), nil, PutOptions{}); err != nil {
+			t.Fatalf("%q: %v", key, err)
+		}
+		if stored, ok := server.Object("bucket", key); !ok || string(stored.Data) != key {
+			t.Fatalf("%q: stored as %v", key, server.Keys("bucket"))
+		}
+		st, err := clnt.Stat(ctx, StatOptions{})
+		if err != nil {
+			t.Fatalf("%q: %v", key, err)
+		}
+		if st.Size != int64(len(key)) {
+			t.Errorf("%q: expected size %d, got %d", key, len(key), st.Size)
+		}
+		reader, _, err := clnt.Get(ctx, GetOptions{})
+		if err != nil {
+			t.Fatalf("%q: %v", key, err)
+		}
+		data, e := io.ReadAll(reader)
+		reader.Close()
+		if e != nil || string(data) != key {
+			t.Errorf("%q: unexpected data %q, %v", key, data, e)
+		}
+	}
+
+	var listed []string
+	for content := range newS3TestClient(t, "s3test/bucket/").List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			t.Fatal(content.Err)
+		}
+		listed = append(listed, strings.TrimPrefix(content.URL.Path, "/bucket/"))
+	}
+	sort.Strings(listed)
+	sort.Strings(keys)
+	if strings.Join(listed, "\n") != strings.Join(keys, "\n") {
+		t.Errorf("expected %q, got %q", keys, listed)
+	}
+}
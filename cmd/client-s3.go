@@ -20,12 +20,19 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"hash"
 	"hash/fnv"
 	"io"
+	"math"
 	"math/rand"
 	"net"
 	"net/http"
@@ -37,9 +44,11 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/klauspost/compress/gzhttp"
+	"github.com/minio/pkg/v2/console"
 	"github.com/minio/pkg/v2/env"
 
 	"github.com/minio/minio-go/v7"
@@ -66,6 +75,24 @@ type S3Client struct {
 	targetURL    *ClientURL
 	api          *minio.Client
 	virtualStyle bool
+
+	// transport is that of api, for the requests minio-go has no API
+	// for.
+	transport http.RoundTripper
+	// baseTransport is the innermost transport of transport, whose
+	// idle connections Close closes.
+	baseTransport http.RoundTripper
+
+	// sessionExpiry is when the session token of the
+	// credentials expires, zero if unknown.
+	sessionExpiry time.Time
+
+	// noMultiDelete is set once the server answered a multi-delete with
+	// NotImplemented, objects are then removed one by one.
+	noMultiDelete bool
+
+	// closed is set by Close, the requests of the client fail then.
+	closed atomic.Bool
 }
 
 const (
@@ -96,12 +123,32 @@ const (
 
 type dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
 
+// defaultTCPKeepAlive is the default --tcp-keepalive.
+const defaultTCPKeepAlive = 15 * time.Second
+
+// jitterKeepAlive returns the keep-alive period d of a new connection
+// moved by up to 10% either way, so that the probes of the connections
+// opened together are not all sent at the same time. Like for
+// net.Dialer, 0 is the default period and a negative d disables them.
+func jitterKeepAlive(d time.Duration) time.Duration {
+	if d < 0 {
+		return d
+	}
+	if d == 0 {
+		d = defaultTCPKeepAlive
+	}
+	if spread := int64(d / 5); spread > 0 {
+		d += time.Duration(rand.Int63n(spread)) - d/10
+	}
+	return d
+}
+
 // newCustomDialContext setups a custom dialer for any external communication and proxies.
 func newCustomDialContext(c *Config) dialContext {
 	return func(ctx context.Context, network, addr string) (net.Conn, error) {
 		dialer := &net.Dialer{
 			Timeout:   10 * time.Second,
-			KeepAlive: 15 * time.Second,
+			KeepAlive: jitterKeepAlive(c.TCPKeepAlive),
 		}
 
 		conn, err := dialer.DialContext(ctx, network, addr)
@@ -113,7 +160,7 @@ func newCustomDialContext(c *Config) dialContext {
 			WithReadDeadline(c.ConnReadDeadline).
 			WithWriteDeadline(c.ConnWriteDeadline)
 
-		return dconn, nil
+		return newActivityConn(dconn), nil
 	}
 }
 
@@ -129,7 +176,7 @@ func getConfigHash(config *Config) uint32 {
 
 	// Generate a hash out of s3Conf.
 	confHash := fnv.New32a()
-	confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey + config.SessionToken))
+	confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey + config.SessionToken + config.signingRegion()))
 	confSum := confHash.Sum32()
 	return confSum
 }
@@ -148,6 +195,16 @@ func isHostTLS(config *Config) bool {
 // getTransportForConfig returns a corresponding *http.Transport for the *Config
 // set withS3v2 bool to true to add traceV2 tracer.
 func getTransportForConfig(config *Config, withS3v2 bool) http.RoundTripper {
+	return wrapTransportForConfig(config, newBaseTransport(config), withS3v2)
+}
+
+// newBaseTransport returns the transport of config, or a new
+// *http.Transport for it, or the recorded responses of --replay-file.
+func newBaseTransport(config *Config) http.RoundTripper {
+	if globalHTTPReplay != nil {
+		return globalHTTPReplay
+	}
+
 	var transport http.RoundTripper
 
 	useTLS := isHostTLS(config)
@@ -159,11 +216,12 @@ func getTransportForConfig(config *Config, withS3v2 bool) http.RoundTripper {
 			Proxy:                 http.ProxyFromEnvironment,
 			DialContext:           newCustomDialContext(config),
 			MaxIdleConnsPerHost:   1024,
+			MaxConnsPerHost:       config.MaxHostConns,
 			WriteBufferSize:       32 << 10, // 32KiB moving up from 4KiB default
 			ReadBufferSize:        32 << 10, // 32KiB moving up from 4KiB default
 			IdleConnTimeout:       90 * time.Second,
 			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 10 * time.Second,
+			ExpectContinueTimeout: expectContinueTimeout,
 			// Set this value so that the underlying transport round-tripper
 			// doesn't try to auto decode the body of objects with
 			// content-encoding set to `gzip`.
@@ -189,16 +247,36 @@ func getTransportForConfig(config *Config, withS3v2 bool) http.RoundTripper {
 			// Because we create a custom TLSClientConfig, we have to opt-in to HTTP/2.
 			// See https://github.com/golang/go/issues/14275
 			//
-			// TODO: Enable http2.0 when upstream issues related to HTTP/2 are fixed.
-			//
-			// if e = http2.ConfigureTransport(tr); e != nil {
-			// 	return nil, probe.NewError(e)
-			// }
+			// Some load balancers stall or send GOAWAY storms over HTTP/2,
+			// it is only negotiated with --http2. --http1 only offers
+			// HTTP/1.1 in the ALPN negotiation.
+			switch {
+			case config.HTTP1:
+				tlsConfig.NextProtos = []string{"http/1.1"}
+			case config.HTTP2:
+				tr.ForceAttemptHTTP2 = true
+			}
 		}
 		transport = tr
 	}
+	return transport
+}
 
-	transport = limiter.New(config.UploadLimit, config.DownloadLimit, transport)
+// wrapTransportForConfig wraps transport in the transports implementing
+// the options of config.
+func wrapTransportForConfig(config *Config, transport http.RoundTripper, withS3v2 bool) http.RoundTripper {
+	// Innermost, to record every attempt as sent by the middleware.
+	transport = globalHTTPTrace.wrap(transport)
+	// Inside the other transports, to see every attempt as sent.
+	for _, middleware := range config.Middleware {
+		transport = middleware(transport)
+	}
+	transport = limiter.New(config.UploadBucket, config.DownloadBucket, transport)
+	transport = limiter.NewRequestLimiter(config.RequestBucket, logRequestThrottle, transport)
+	transport = newHostConnsTransport(config.MaxHostConns, transport)
+	transport = newStallTransport(config.StallTimeout, transport)
+	transport = newRequestTimeoutTransport(config.RequestTimeout, transport)
+	transport = newExpectContinueTransport(transport)
 
 	if config.Debug {
 		if strings.EqualFold(config.Signature, "S3v4") {
@@ -207,10 +285,29 @@ func getTransportForConfig(config *Config, withS3v2 bool) http.RoundTripper {
 			transport = httptracer.GetNewTraceTransport(newTraceV2(), transport)
 		}
 	}
+	// Outside the tracer, to trace the requests as signed again.
+	transport = newSyncTimeTransport(config, transport)
+	transport = newEndpointErrorTransport(config.Alias, transport)
+	transport = newAdaptiveConcurrencyTransport(config.PartConcurrency, transport)
+	transport = newThrottleTransport(config.MaxRetryTime, transport)
+	transport = newRetryTransport(transport)
+	transport = newRedirectTransport(config, transport)
+	transport = newRequestIDTransport(transport)
 	transport = gzhttp.Transport(transport)
+	// Outside gzhttp, to read the decompressed responses.
+	transport = newUploadIDTransport(transport)
 	return transport
 }
 
+// requestThrottleDebugThreshold is the smallest --req-limit delay logged with --debug.
+const requestThrottleDebugThreshold = 250 * time.Millisecond
+
+func logRequestThrottle(req *http.Request, wait time.Duration) {
+	if globalDebug && wait >= requestThrottleDebugThreshold {
+		console.Debugln(fmt.Sprintf("Request %s %s delayed by %s to honor --req-limit", req.Method, req.URL.Redacted(), wait))
+	}
+}
+
 // getCredentialsChainForConfig returns an []credentials.Provider array for the config
 // and the STS configuration (if present)
 func getCredentialsChainForConfig(config *Config, transport http.RoundTripper) ([]credentials.Provider, *probe.Error) {
@@ -257,6 +354,8 @@ func getCredentialsChainForConfig(config *Config, transport http.RoundTripper) (
 // newFactory encloses New function with client cache.
 func newFactory() func(config *Config) (Client, *probe.Error) {
 	clientCache := make(map[uint32]*minio.Client)
+	transportCache := make(map[uint32]http.RoundTripper)
+	baseTransportCache := make(map[uint32]http.RoundTripper)
 	var mutex sync.Mutex
 
 	// Return New function.
@@ -275,6 +374,7 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 		s3Clnt := &S3Client{}
 		// Save the target URL.
 		s3Clnt.targetURL = targetURL
+		s3Clnt.sessionExpiry = config.SessionExpiry
 
 		s3Clnt.virtualStyle = isVirtualHostStyle(hostName, config.Lookup)
 		isS3AcceleratedEndpoint := isAmazonAccelerated(hostName)
@@ -291,9 +391,16 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 		defer mutex.Unlock()
 		var api *minio.Client
 		var found bool
-		if api, found = clientCache[confSum]; !found {
-
-			transport := getTransportForConfig(config, true)
+		// Middleware cannot be compared, such clients are never cached.
+		cached := len(config.Middleware) == 0
+		if api, found = clientCache[confSum]; found && cached {
+			s3Clnt.transport = transportCache[confSum]
+			s3Clnt.baseTransport = baseTransportCache[confSum]
+		} else {
+			baseTransport := newBaseTransport(config)
+			transport := wrapTransportForConfig(config, baseTransport, true)
+			s3Clnt.transport = transport
+			s3Clnt.baseTransport = baseTransport
 
 			credsChain, err := getCredentialsChainForConfig(config, transport)
 			if err != nil {
@@ -312,14 +419,23 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 			credsChain = append(credsChain, credsV2)
 
 			creds := credentials.NewChainCredentials(credsChain)
+			if config.CredsProvider != nil {
+				// Unlike a chain, which falls back to anonymous
+				// requests, the provider reports its errors.
+				creds = credentials.New(config.CredsProvider)
+			}
 
 			// Not found. Instantiate a new MinIO
 			var e error
 
+			region := config.signingRegion()
+			if region == "" {
+				region = env.Get("MC_REGION", env.Get("AWS_REGION", ""))
+			}
 			options := minio.Options{
 				Creds:        creds,
 				Secure:       useTLS,
-				Region:       env.Get("MC_REGION", env.Get("AWS_REGION", "")),
+				Region:       region,
 				BucketLookup: config.Lookup,
 				Transport:    transport,
 			}
@@ -338,7 +454,11 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 			api.SetAppInfo(config.AppName, config.AppVersion)
 
 			// Cache the new MinIO Client with hash of config as key.
-			clientCache[confSum] = api
+			if cached {
+				clientCache[confSum] = api
+				transportCache[confSum] = transport
+				baseTransportCache[confSum] = baseTransport
+			}
 		}
 
 		// Store the new api object.
@@ -352,6 +472,28 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 // it also enables an internal trace transport.
 var S3New = newFactory()
 
+// errClientClosed is the error of the requests of a closed client.
+var errClientClosed = errors.New("the client is closed")
+
+// Close closes the idle connections of the transport of the client. The
+// transport is shared with the other clients of the same alias, they open
+// new connections as needed. The requests of the client fail with
+// errClientClosed afterwards.
+func (c *S3Client) Close() {
+	c.closed.Store(true)
+	if tr, ok := c.baseTransport.(*http.Transport); ok {
+		tr.CloseIdleConnections()
+	}
+}
+
+// checkClosed returns errClientClosed once the client is closed.
+func (c *S3Client) checkClosed() *probe.Error {
+	if c.closed.Load() {
+		return probe.NewError(errClientClosed)
+	}
+	return nil
+}
+
 // GetURL get url.
 func (c *S3Client) GetURL() ClientURL {
 	return c.targetURL.Clone()
@@ -359,11 +501,14 @@ func (c *S3Client) GetURL() ClientURL {
 
 // AddNotificationConfig - Add bucket notification
 func (c *S3Client) AddNotificationConfig(ctx context.Context, arn string, events []string, prefix, suffix string, ignoreExisting bool) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, _ := c.url2BucketAndObject()
 
 	accountArn, err := notification.NewArnFromString(arn)
 	if err != nil {
-		return probe.NewError(invalidArgumentErr(err)).Untrace()
+		return probe.NewError(invalidArgumentErr{err}).Untrace()
 	}
 	nc := notification.NewConfig(accountArn)
 
@@ -430,6 +575,9 @@ func (c *S3Client) AddNotificationConfig(ctx context.Context, arn string, events
 
 // RemoveNotificationConfig - Remove bucket notification
 func (c *S3Client) RemoveNotificationConfig(ctx context.Context, arn, event, prefix, suffix string) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	// Remove all notification configs if arn is empty
 	if arn == "" {
@@ -446,7 +594,7 @@ func (c *S3Client) RemoveNotificationConfig(ctx context.Context, arn, event, pre
 
 	accountArn, err := notification.NewArnFromString(arn)
 	if err != nil {
-		return probe.NewError(invalidArgumentErr(err)).Untrace()
+		return probe.NewError(invalidArgumentErr{err}).Untrace()
 	}
 
 	// if we are passed filters for either events, suffix or prefix, then only delete the single event that matches
@@ -523,6 +671,9 @@ type NotificationConfig struct {
 
 // ListNotificationConfigs - List notification configs
 func (c *S3Client) ListNotificationConfigs(ctx context.Context, arn string) ([]NotificationConfig, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	var configs []NotificationConfig
 	bucket, _ := c.url2BucketAndObject()
 	mb, e := c.api.GetBucketNotification(ctx, bucket)
@@ -753,6 +904,9 @@ func selectCompressionType(selOpts SelectObjectOpts, object string) minio.Select
 
 // Select - select object content wrapper.
 func (c *S3Client) Select(ctx context.Context, expression string, sse encrypt.ServerSide, selOpts SelectObjectOpts) (io.ReadCloser, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	opts := minio.SelectObjectOptions{
 		Expression:     expression,
 		ExpressionType: minio.QueryExpressionTypeSQL,
@@ -852,6 +1006,9 @@ func (c *S3Client) notificationToEventsInfo(ninfo notification.Info) []EventInfo
 
 // Watch - Start watching on all bucket events for a given account ID.
 func (c *S3Client) Watch(ctx context.Context, options WatchOptions) (*WatchObject, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	// Extract bucket and object.
 	bucket, object := c.url2BucketAndObject()
 
@@ -943,6 +1100,9 @@ func (c *S3Client) Watch(ctx context.Context, options WatchOptions) (*WatchObjec
 
 // Get - get object with GET options.
 func (c *S3Client) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *ClientContent, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, nil, err
+	}
 	bucket, object := c.url2BucketAndObject()
 	o := minio.GetObjectOptions{
 		ServerSideEncryption: opts.SSE,
@@ -951,58 +1111,221 @@ func (c *S3Client) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *Cl
 	if opts.Zip {
 		o.Set("x-minio-extract", "true")
 	}
-	if opts.RangeStart != 0 {
-		err := o.SetRange(opts.RangeStart, 0)
-		if err != nil {
-			return nil, nil, probe.NewError(err)
-		}
-	}
 	// Disallow automatic decompression for some objects with content-encoding set.
 	o.Set("Accept-Encoding", "identity")
+	if opts.RangeLength > 0 {
+		return c.getRange(ctx, bucket, object, o, opts)
+	}
 
 	reader, e := c.api.GetObject(ctx, bucket, object, o)
 	if e != nil {
-		errResponse := minio.ToErrorResponse(e)
-		if errResponse.Code == "NoSuchBucket" {
-			return nil, nil, probe.NewError(BucketDoesNotExist{
-				Bucket: bucket,
-			})
-		}
-		if errResponse.Code == "InvalidBucketName" {
-			return nil, nil, probe.NewError(BucketInvalid{
-				Bucket: bucket,
-			})
+		return nil, nil, getObjectError(bucket, e)
+	}
+	if opts.RangeStart != 0 {
+		// minio.Object tracks the read offset itself and drops any Range
+		// header set on the options, seek so the body starts at RangeStart.
+		if _, e = reader.Seek(opts.RangeStart, io.SeekStart); e != nil {
+			reader.Close()
+			return nil, nil, probe.NewError(e)
 		}
-		if errResponse.Code == "NoSuchKey" {
-			return nil, nil, probe.NewError(ObjectMissing{})
+	}
+	objStat, e := reader.Stat()
+	if e != nil {
+		return nil, nil, getObjectError(bucket, e)
+	}
+	if opts.Zip {
+		// Offsets into an extracted file cannot be resumed with a ranged GET.
+		return reader, c.objectInfo2ClientContent(bucket, objStat), nil
+	}
+	var rc io.ReadCloser = &resumableReader{
+		ctx:       ctx,
+		api:       c.api,
+		bucket:    bucket,
+		object:    object,
+		sse:       opts.SSE,
+		versionID: opts.VersionID,
+		etag:      objStat.ETag,
+		offset:    opts.RangeStart,
+		reader:    reader,
+	}
+	if opts.VerifyResponse {
+		if reason := etagVerifySkipReason(objStat, opts); reason != "" {
+			if globalDebug {
+				console.Debugln(fmt.Sprintf("Not verifying %s/%s against its ETag: %s", bucket, object, reason))
+			}
+		} else {
+			rc = newETagVerifyReader(rc, object, objStat.ETag, objStat.Size)
 		}
+	}
+	return rc, c.objectInfo2ClientContent(bucket, objStat), nil
+}
+
+// getRange downloads RangeLength bytes of an object from RangeStart with
+// a single ranged GET, minio.Object would drop the Range header.
+func (c *S3Client) getRange(ctx context.Context, bucket, object string, o minio.GetObjectOptions, opts GetOptions) (io.ReadCloser, *ClientContent, *probe.Error) {
+	if e := o.SetRange(opts.RangeStart, opts.RangeStart+opts.RangeLength-1); e != nil {
 		return nil, nil, probe.NewError(e)
 	}
-	objStat, e := reader.Stat()
+	reader, objInfo, _, e := minio.Core{Client: c.api}.GetObject(ctx, bucket, object, o)
 	if e != nil {
-		errResponse := minio.ToErrorResponse(e)
-		if errResponse.Code == "NoSuchBucket" {
-			return nil, nil, probe.NewError(BucketDoesNotExist{
-				Bucket: bucket,
-			})
+		return nil, nil, getObjectError(bucket, e)
+	}
+	return reader, c.objectInfo2ClientContent(bucket, objInfo), nil
+}
+
+// isNotImplemented returns true if e is the answer of a server which
+// does not implement the API of the request.
+func isNotImplemented(e error) bool {
+	errResp := minio.ToErrorResponse(e)
+	return errResp.Code == "NotImplemented" || errResp.StatusCode == http.StatusNotImplemented
+}
+
+// featureError converts the error of a request for feature, telling
+// apart the servers which do not implement it.
+func featureError(feature string, e error) *probe.Error {
+	if isNotImplemented(e) {
+		return probe.NewError(FeatureNotSupported{Feature: feature, Err: e})
+	}
+	return probe.NewError(e)
+}
+
+// getObjectError converts the error of a GET of an object of bucket.
+func getObjectError(bucket string, e error) *probe.Error {
+	errResponse := minio.ToErrorResponse(e)
+	switch errResponse.Code {
+	case "NoSuchBucket":
+		return withRequestIDs(probe.NewError(BucketDoesNotExist{
+			Bucket: bucket,
+		}), errResponse.RequestID, errResponse.HostID)
+	case "InvalidBucketName":
+		return withRequestIDs(probe.NewError(BucketInvalid{
+			Bucket: bucket,
+		}), errResponse.RequestID, errResponse.HostID)
+	case "NoSuchKey":
+		return withRequestIDs(probe.NewError(ObjectMissing{}), errResponse.RequestID, errResponse.HostID)
+	}
+	return probe.NewError(e)
+}
+
+// getResumeMaxRetries is the number of times a download is resumed
+// after the connection dropped in the middle of the object body.
+const getResumeMaxRetries = 5
+
+// getResumeRetryUnit is the base backoff before resuming a download.
+var getResumeRetryUnit = 500 * time.Millisecond
+
+// resumableReader reads an object body and transparently re-issues a
+// ranged GET from the current offset when the body ends prematurely
+// with io.ErrUnexpectedEOF. The ETag is pinned so that a resumed
+// download never mixes the bytes of two different object versions.
+type resumableReader struct {
+	ctx       context.Context
+	api       *minio.Client
+	bucket    string
+	object    string
+	sse       encrypt.ServerSide
+	versionID string
+	etag      string
+	offset    int64
+	retries   int
+
+	// mu guards reader and closed, Close may be called
+	// concurrently with a Read to abort it.
+	mu     sync.Mutex
+	reader io.ReadCloser
+	closed bool
+}
+
+func (r *resumableReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.offset += int64(n)
+	for isResumableReadError(err) && r.retries < getResumeMaxRetries && r.ctx.Err() == nil {
+		r.retries++
+		if globalDebug {
+			console.Debugln(fmt.Sprintf("Resuming download of %s/%s at offset %d (attempt %d/%d)",
+				r.bucket, r.object, r.offset, r.retries, getResumeMaxRetries))
+		}
+		select {
+		case <-r.ctx.Done():
+			return n, err
+		case <-time.After(exponentialBackoff(getResumeRetryUnit, defaultRetryCap, maxJitter, r.retries-1)):
+		}
+		if e := r.reopen(); e != nil {
+			if globalDebug {
+				console.Debugln(fmt.Sprintf("Unable to resume download of %s/%s: %v", r.bucket, r.object, e))
+			}
+			continue
 		}
-		if errResponse.Code == "InvalidBucketName" {
-			return nil, nil, probe.NewError(BucketInvalid{
-				Bucket: bucket,
-			})
+		if n > 0 {
+			// Hand over what was read so far, the next Read continues
+			// from the resumed body.
+			return n, nil
 		}
-		if errResponse.Code == "NoSuchKey" {
-			return nil, nil, probe.NewError(ObjectMissing{})
+		n, err = r.reader.Read(p)
+		r.offset += int64(n)
+	}
+	return n, err
+}
+
+// isResumableReadError returns true if a download can be resumed after
+// err, the connection was closed early or the transfer stalled.
+func isResumableReadError(err error) bool {
+	var stalled transferStalledError
+	return err == io.ErrUnexpectedEOF || errors.As(err, &stalled)
+}
+
+// reopen replaces the current body with one starting at offset.
+func (r *resumableReader) reopen() error {
+	r.mu.Lock()
+	closed := r.closed
+	r.mu.Unlock()
+	if closed {
+		return errors.New("reader closed")
+	}
+	r.reader.Close()
+	opts := minio.GetObjectOptions{
+		ServerSideEncryption: r.sse,
+		VersionID:            r.versionID,
+	}
+	opts.Set("Accept-Encoding", "identity")
+	if r.etag != "" {
+		if e := opts.SetMatchETag(r.etag); e != nil {
+			return e
 		}
-		return nil, nil, probe.NewError(e)
 	}
-	return reader, c.objectInfo2ClientContent(bucket, objStat), nil
+	reader, e := r.api.GetObject(r.ctx, r.bucket, r.object, opts)
+	if e != nil {
+		return e
+	}
+	// Seeking makes the next Read issue a GET ranged from offset.
+	if _, e = reader.Seek(r.offset, io.SeekStart); e != nil {
+		reader.Close()
+		return e
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		reader.Close()
+		return errors.New("reader closed")
+	}
+	r.reader = reader
+	return nil
+}
+
+func (r *resumableReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return r.reader.Close()
 }
 
 // Copy - copy object, uses server side copy API. Also uses an abstracted API
 // such that large file sizes will be copied in multipart manner on server
 // side.
 func (c *S3Client) Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	dstBucket, dstObject := c.url2BucketAndObject()
 	if dstBucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -1057,40 +1380,45 @@ func (c *S3Client) Copy(ctx context.Context, source string, opts CopyOptions, pr
 	destOpts.UserMetadata = metadata
 	destOpts.ReplaceMetadata = len(metadata) > 0
 
+	var ui minio.UploadInfo
 	var e error
 	if opts.disableMultipart || opts.size < 64*1024*1024 {
-		_, e = c.api.CopyObject(ctx, destOpts, srcOpts)
+		ui, e = c.api.CopyObject(ctx, destOpts, srcOpts)
 	} else {
-		_, e = c.api.ComposeObject(ctx, destOpts, srcOpts)
+		ui, e = c.api.ComposeObject(ctx, destOpts, srcOpts)
 	}
 
 	if e != nil {
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse.Code == "AccessDenied" {
-			return probe.NewError(PathInsufficientPermission{
+			return withRequestIDs(probe.NewError(PathInsufficientPermission{
 				Path: c.targetURL.String(),
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "NoSuchBucket" {
-			return probe.NewError(BucketDoesNotExist{
+			return withRequestIDs(probe.NewError(BucketDoesNotExist{
 				Bucket: dstBucket,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "InvalidBucketName" {
-			return probe.NewError(BucketInvalid{
+			return withRequestIDs(probe.NewError(BucketInvalid{
 				Bucket: dstBucket,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "NoSuchKey" {
-			return probe.NewError(ObjectMissing{})
+			return withRequestIDs(probe.NewError(ObjectMissing{}), errResponse.RequestID, errResponse.HostID)
 		}
 		return probe.NewError(e)
 	}
+	recordUploadResult(ctx, ui)
 	return nil
 }
 
 // Put - upload an object with custom metadata.
 func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progress io.Reader, putOpts PutOptions) (int64, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return 0, err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return 0, probe.NewError(BucketNameEmpty{})
@@ -1192,50 +1520,118 @@ func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progre
 		opts.SendContentMd5 = true
 	}
 
+	// With --temp-dir, a stream of unknown size is buffered in a file
+	// and uploaded with its size, where minio-go would buffer its parts
+	// in memory.
+	if size < 0 && globalTempDir != "" {
+		f, n, e := spoolStream(reader, math.MaxInt64)
+		if e != nil {
+			return n, probe.NewError(e)
+		}
+		defer removeTempFile(f)
+		size = n
+		reader = io.NewSectionReader(f, 0, n)
+	}
+
+	reader = streamReadAtParts(reader, size, &opts)
+
+	// --md5 uploads of unencrypted objects are verified against the ETag
+	// returned by the server. The bytes are hashed as minio-go reads them,
+	// which also hides io.ReaderAt so that the source is not read twice,
+	// once to compute Content-MD5 and once to upload it.
+	var hasher *etagHasher
+	if putOpts.md5 && putOpts.sse == nil && size >= 0 {
+		hasher = newETagHasher(size, putOpts.multipartSize)
+		reader = io.TeeReader(reader, hasher)
+	}
+
+	// The SHA-256 sum of --store-checksum is computed as the object is
+	// uploaded, and stored in its metadata once it is.
+	var sha256Hash hash.Hash
+	if putOpts.storeSHA256 {
+		sha256Hash = sha256.New()
+		reader = io.TeeReader(reader, sha256Hash)
+	}
+
+	var uploads *uploadIDRecorder
+	ctx, uploads = withUploadIDRecorder(ctx)
+	// Multipart uploads are verified against the MD5 sums of their
+	// parts, hashed the same way.
+	partSums := hasher
+	if partSums == nil && putOpts.sse == nil && isMultipartPut(size, opts) {
+		partSums = newETagHasher(size, opts.PartSize)
+		reader = io.TeeReader(reader, partSums)
+	}
 	ui, e := c.api.PutObject(ctx, bucket, object, reader, size, opts)
+	if e != nil && ctx.Err() != nil {
+		c.abortUploads(bucket, object, uploads.get())
+	}
+	if e == nil && partSums != nil && putOpts.sse == nil {
+		e = verifyMultipartETag(bucket, object, ui.ETag, uploads.parts(), partSums.sums())
+	}
+	if e != nil && ctx.Err() != nil {
+		// A canceled upload leaves no multipart upload behind and
+		// fails with the error of its context, whatever the request
+		// in flight failed with.
+		return ui.Size, probe.NewError(ctx.Err())
+	}
 	if e != nil {
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse.Code == "UnexpectedEOF" || e == io.EOF {
-			return ui.Size, probe.NewError(UnexpectedEOF{
+			return ui.Size, withRequestIDs(probe.NewError(UnexpectedEOF{
 				TotalSize:    size,
 				TotalWritten: ui.Size,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "AccessDenied" {
-			return ui.Size, probe.NewError(PathInsufficientPermission{
+			return ui.Size, withRequestIDs(probe.NewError(PathInsufficientPermission{
 				Path: c.targetURL.String(),
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "MethodNotAllowed" {
-			return ui.Size, probe.NewError(ObjectAlreadyExists{
+			return ui.Size, withRequestIDs(probe.NewError(ObjectAlreadyExists{
 				Object: object,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "XMinioObjectExistsAsDirectory" {
-			return ui.Size, probe.NewError(ObjectAlreadyExistsAsDirectory{
+			return ui.Size, withRequestIDs(probe.NewError(ObjectAlreadyExistsAsDirectory{
 				Object: object,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "NoSuchBucket" {
-			return ui.Size, probe.NewError(BucketDoesNotExist{
+			return ui.Size, withRequestIDs(probe.NewError(BucketDoesNotExist{
 				Bucket: bucket,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "InvalidBucketName" {
-			return ui.Size, probe.NewError(BucketInvalid{
+			return ui.Size, withRequestIDs(probe.NewError(BucketInvalid{
 				Bucket: bucket,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "NoSuchKey" {
-			return ui.Size, probe.NewError(ObjectMissing{})
+			return ui.Size, withRequestIDs(probe.NewError(ObjectMissing{}), errResponse.RequestID, errResponse.HostID)
 		}
 		return ui.Size, probe.NewError(e)
 	}
+	if hasher != nil {
+		if e = hasher.verify(object, ui.ETag); e != nil {
+			return ui.Size, probe.NewError(e)
+		}
+	}
+	if sha256Hash != nil {
+		if e = c.storeSHA256(ctx, bucket, object, ui, hex.EncodeToString(sha256Hash.Sum(nil)), opts); e != nil {
+			return ui.Size, probe.NewError(e)
+		}
+	}
+	recordUploadResult(ctx, ui)
 	return ui.Size, nil
 }
 
 // PutPart - upload an object with custom metadata. (Same as Put)
 func (c *S3Client) PutPart(ctx context.Context, reader io.Reader, size int64, progress io.Reader, putOpts PutOptions) (int64, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return 0, err
+	}
 	return c.Put(ctx, reader, size, progress, putOpts)
 }
 
@@ -1257,6 +1653,204 @@ func (c *S3Client) removeIncompleteObjects(ctx context.Context, bucket string, o
 	return removeObjectErrorCh
 }
 
+// maxMultiDeleteObjects is the most objects of a multi-delete request.
+const maxMultiDeleteObjects = 1000
+
+// removeObjects removes the objects of objectsCh with multi-delete
+// requests, falling back to removing them one by one if the server does
+// not implement multi-delete.
+func (c *S3Client) removeObjects(ctx context.Context, bucket string, objectsCh <-chan minio.ObjectInfo, opts minio.RemoveObjectsOptions) <-chan minio.RemoveObjectResult {
+	resultCh := make(chan minio.RemoveObjectResult)
+
+	go func() {
+		defer close(resultCh)
+
+		for {
+			var batch []minio.ObjectInfo
+			for info := range objectsCh {
+				if batch = append(batch, info); len(batch) == maxMultiDeleteObjects {
+					break
+				}
+			}
+			if len(batch) == 0 {
+				return
+			}
+
+			c.Lock()
+			noMultiDelete := c.noMultiDelete
+			c.Unlock()
+			if !noMultiDelete && c.removeObjectsBatch(ctx, bucket, batch, opts, resultCh) {
+				continue
+			}
+			for _, info := range batch {
+				if result, ok := c.removeObject(ctx, bucket, info, opts); ok {
+					resultCh <- result
+				}
+			}
+		}
+	}()
+
+	return resultCh
+}
+
+const (
+	// multiDeleteMaxAttempts bounds the multi-delete requests sent for
+	// the objects of a batch the server failed to remove.
+	multiDeleteMaxAttempts = 5
+
+	// multiDeleteRetryCap is the maximum wait in between two attempts.
+	multiDeleteRetryCap = 30 * time.Second
+)
+
+// multiDeleteRetryUnit is the base backoff in between two attempts.
+var multiDeleteRetryUnit = time.Second
+
+// isRetryableDeleteError returns true for the errors a multi-delete
+// reports for a single object which are likely to go away when the
+// object is removed again.
+func isRetryableDeleteError(err error) bool {
+	switch minio.ToErrorResponse(err).Code {
+	case "SlowDown", "SlowDownWrite", "ServiceUnavailable", "InternalError", "RequestTimeout", "OperationAborted":
+		return true
+	}
+	return false
+}
+
+// DeleteAll removes the keys of bucket with multi-delete requests. The
+// keys the server fails to remove with a transient error are removed
+// again, alone, with backoff. It returns the keys which still could not
+// be removed with their errors.
+func (c *S3Client) DeleteAll(ctx context.Context, bucket string, keys []string, opts minio.RemoveObjectsOptions) []minio.RemoveObjectResult {
+	if err := c.checkClosed(); err != nil {
+		failed := make([]minio.RemoveObjectResult, 0, len(keys))
+		for _, key := range keys {
+			failed = append(failed, minio.RemoveObjectResult{ObjectName: key, Err: err.ToGoError()})
+		}
+		return failed
+	}
+	objectsCh := make(chan minio.ObjectInfo, len(keys))
+	for _, key := range keys {
+		objectsCh <- minio.ObjectInfo{Key: key}
+	}
+	close(objectsCh)
+
+	var failed []minio.RemoveObjectResult
+	for result := range c.removeObjects(ctx, bucket, objectsCh, opts) {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// removeObjectsBatch removes batch with multi-delete requests, sending
+// the objects which failed with a transient error again until they are
+// removed or multiDeleteMaxAttempts requests were sent. It returns false
+// with nothing removed if the server does not implement multi-delete.
+func (c *S3Client) removeObjectsBatch(ctx context.Context, bucket string, batch []minio.ObjectInfo, opts minio.RemoveObjectsOptions, resultCh chan<- minio.RemoveObjectResult) bool {
+	for attempt := 1; ; attempt++ {
+		results, notImplemented := c.multiDelete(ctx, bucket, batch, opts)
+		if notImplemented {
+			// Objects with names invalid in XML are always removed one
+			// by one, report them and drop the errors of the failed
+			// request.
+			for i := len(batch) - 1; i >= 0; i-- {
+				for _, result := range results {
+					if result.ObjectName != "" && result.ObjectName == batch[i].Key && result.ObjectVersionID == batch[i].VersionID {
+						resultCh <- result
+						batch = append(batch[:i], batch[i+1:]...)
+						break
+					}
+				}
+			}
+			for _, info := range batch {
+				if result, ok := c.removeObject(ctx, bucket, info, opts); ok {
+					resultCh <- result
+				}
+			}
+			return true
+		}
+
+		var retry []minio.ObjectInfo
+		var retryResults []minio.RemoveObjectResult
+		for _, result := range results {
+			if result.ObjectName != "" && isRetryableDeleteError(result.Err) && attempt < multiDeleteMaxAttempts {
+				retry = append(retry, minio.ObjectInfo{Key: result.ObjectName, VersionID: result.ObjectVersionID})
+				retryResults = append(retryResults, result)
+				continue
+			}
+			resultCh <- result
+		}
+		if len(retry) == 0 {
+			return true
+		}
+		if globalDebug {
+			console.Debugln(fmt.Sprintf("Retrying the removal of %d objects of %s (attempt %d/%d)",
+				len(retry), bucket, attempt+1, multiDeleteMaxAttempts))
+		}
+		select {
+		case <-ctx.Done():
+			for _, result := range retryResults {
+				resultCh <- result
+			}
+			return true
+		case <-time.After(exponentialBackoff(multiDeleteRetryUnit, multiDeleteRetryCap, maxJitter, attempt-1)):
+		}
+		batch = retry
+	}
+}
+
+// multiDelete removes batch with one multi-delete request, it returns
+// true if the server does not implement multi-delete, in which case only
+// the results of the objects minio-go removed one by one are valid.
+func (c *S3Client) multiDelete(ctx context.Context, bucket string, batch []minio.ObjectInfo, opts minio.RemoveObjectsOptions) ([]minio.RemoveObjectResult, bool) {
+	batchCh := make(chan minio.ObjectInfo, len(batch))
+	for _, info := range batch {
+		batchCh <- info
+	}
+	close(batchCh)
+
+	// A batch is removed with a single request, whose response reports
+	// the errors of each object without its request IDs.
+	ctx, recorder := withRequestIDRecorder(ctx, nil)
+	var results []minio.RemoveObjectResult
+	notImplemented := false
+	for result := range c.api.RemoveObjectsWithResult(ctx, bucket, batchCh, opts) {
+		if result.ObjectName == "" && isNotImplemented(result.Err) {
+			notImplemented = true
+		}
+		var errResp minio.ErrorResponse
+		if errors.As(result.Err, &errResp) && errResp.RequestID == "" {
+			errResp.RequestID, errResp.HostID = recorder.get()
+			result.Err = errResp
+		}
+		results = append(results, result)
+	}
+	if !notImplemented {
+		return results, false
+	}
+
+	c.Lock()
+	c.noMultiDelete = true
+	c.Unlock()
+	return results, true
+}
+
+// removeObject removes a single object of a bulk removal, it returns
+// false if there is nothing to report, like minio-go does for versions
+// which do not exist.
+func (c *S3Client) removeObject(ctx context.Context, bucket string, info minio.ObjectInfo, opts minio.RemoveObjectsOptions) (minio.RemoveObjectResult, bool) {
+	e := c.api.RemoveObject(ctx, bucket, info.Key, minio.RemoveObjectOptions{
+		VersionID:        info.VersionID,
+		GovernanceBypass: opts.GovernanceBypass,
+	})
+	switch minio.ToErrorResponse(e).Code {
+	case "InvalidArgument", "NoSuchVersion":
+		return minio.RemoveObjectResult{}, false
+	}
+	return minio.RemoveObjectResult{ObjectName: info.Key, ObjectVersionID: info.VersionID, Err: e}, true
+}
+
 // AddUserAgent - add custom user agent.
 func (c *S3Client) AddUserAgent(app, version string) {
 	c.api.SetAppInfo(app, version)
@@ -1284,6 +1878,11 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 	go func() {
 		defer close(resultCh)
 
+		if err := c.checkClosed(); err != nil {
+			resultCh <- RemoveResult{Err: err}
+			return
+		}
+
 		if isForceDel {
 			bucket, object := c.url2BucketAndObject()
 			if e := c.api.RemoveObject(ctx, bucket, object, minio.RemoveObjectOptions{
@@ -1343,7 +1942,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 					if isIncomplete {
 						statusCh = c.removeIncompleteObjects(ctx, bucket, objectsCh)
 					} else {
-						statusCh = c.api.RemoveObjectsWithResult(ctx, bucket, objectsCh, opts)
+						statusCh = c.removeObjects(ctx, bucket, objectsCh, opts)
 					}
 				}
 
@@ -1381,7 +1980,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 					if isIncomplete {
 						statusCh = c.removeIncompleteObjects(ctx, bucket, objectsCh)
 					} else {
-						statusCh = c.api.RemoveObjectsWithResult(ctx, bucket, objectsCh, opts)
+						statusCh = c.removeObjects(ctx, bucket, objectsCh, opts)
 					}
 					prevBucket = bucket
 				}
@@ -1431,10 +2030,16 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 		if statusCh != nil {
 			for removeStatus := range statusCh {
 				if removeStatus.Err != nil {
-					removeStatus.Err = errors.New(strings.Replace(
-						removeStatus.Err.Error(), "Object is WORM protected",
-						"Object, '"+removeStatus.ObjectName+" (Version ID="+
-							removeStatus.ObjectVersionID+")' is WORM protected", 1))
+					wormProtected := "Object, '" + removeStatus.ObjectName + " (Version ID=" +
+						removeStatus.ObjectVersionID + ")' is WORM protected"
+					if errResp, ok := removeStatus.Err.(minio.ErrorResponse); ok && errResp.Message != "" {
+						// Keep the request IDs of the response.
+						errResp.Message = strings.Replace(errResp.Message, "Object is WORM protected", wormProtected, 1)
+						removeStatus.Err = errResp
+					} else {
+						removeStatus.Err = errors.New(strings.Replace(
+							removeStatus.Err.Error(), "Object is WORM protected", wormProtected, 1))
+					}
 
 					// If the removeStatus error message is:
 					// "Object is WORM protected and cannot be overwritten",
@@ -1467,6 +2072,9 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 
 // MakeBucket - make a new bucket.
 func (c *S3Client) MakeBucket(ctx context.Context, region string, ignoreExisting, withLock bool) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -1521,6 +2129,9 @@ func (c *S3Client) MakeBucket(ctx context.Context, region string, ignoreExisting
 
 // RemoveBucket removes a bucket, forcibly if asked
 func (c *S3Client) RemoveBucket(ctx context.Context, forceRemove bool) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -1538,6 +2149,9 @@ func (c *S3Client) RemoveBucket(ctx context.Context, forceRemove bool) *probe.Er
 
 // GetAccessRules - get configured policies from the server
 func (c *S3Client) GetAccessRules(ctx context.Context) (map[string]string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return map[string]string{}, probe.NewError(BucketNameEmpty{})
@@ -1564,6 +2178,9 @@ func (c *S3Client) GetAccessRules(ctx context.Context) (map[string]string, *prob
 
 // GetAccess get access policy permissions.
 func (c *S3Client) GetAccess(ctx context.Context) (string, string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", "", err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return "", "", probe.NewError(BucketNameEmpty{})
@@ -1588,6 +2205,9 @@ func (c *S3Client) GetAccess(ctx context.Context) (string, string, *probe.Error)
 
 // SetAccess set access policy permissions.
 func (c *S3Client) SetAccess(ctx context.Context, bucketPolicy string, isJSON bool) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -1626,17 +2246,17 @@ func (c *S3Client) SetAccess(ctx context.Context, bucketPolicy string, isJSON bo
 }
 
 // listObjectWrapper - select ObjectList mode depending on arguments
-func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object string, isRecursive bool, timeRef time.Time, withVersions, withDeleteMarkers, metadata bool, maxKeys int, zip bool) <-chan minio.ObjectInfo {
+func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object string, isRecursive bool, timeRef time.Time, withVersions, withDeleteMarkers, metadata bool, maxKeys int, zip bool, startAfter string) <-chan minio.ObjectInfo {
 	if !timeRef.IsZero() || withVersions {
-		return c.listVersions(ctx, bucket, object, ListOptions{Recursive: isRecursive, TimeRef: timeRef, WithOlderVersions: withVersions, WithDeleteMarkers: withDeleteMarkers})
+		return c.listVersions(ctx, bucket, object, ListOptions{Recursive: isRecursive, TimeRef: timeRef, WithOlderVersions: withVersions, WithDeleteMarkers: withDeleteMarkers, StartAfter: startAfter})
 	}
 
 	if isGoogle(c.targetURL.Host) {
 		// Google Cloud S3 layer doesn't implement ListObjectsV2 implementation
 		// https://github.com/minio/mc/issues/3073
-		return c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, UseV1: true, MaxKeys: maxKeys})
+		return c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, UseV1: true, MaxKeys: maxKeys, StartAfter: startAfter})
 	}
-	opts := minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, WithMetadata: metadata, MaxKeys: maxKeys}
+	opts := minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, WithMetadata: metadata, MaxKeys: maxKeys, StartAfter: startAfter}
 	if zip {
 		// If prefix ends with .zip, add a slash.
 		if strings.HasSuffix(object, ".zip") {
@@ -1647,6 +2267,94 @@ func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object string,
 	return c.api.ListObjects(ctx, bucket, opts)
 }
 
+// listMetadataConcurrency bounds the HEAD requests sent at once to
+// fill the metadata missing from a listing.
+const listMetadataConcurrency = 16
+
+// listLatestObjects lists the latest versions of the objects according
+// to opts, sending a HEAD only for the objects whose metadata the
+// listing did not carry when opts.StatMissingMetadata is set.
+func (c *S3Client) listLatestObjects(ctx context.Context, bucket, object string, isRecursive bool, opts ListOptions) <-chan minio.ObjectInfo {
+	objects := c.listObjectWrapper(ctx, bucket, object, isRecursive, time.Time{}, false, false, opts.WithMetadata, opts.listMaxKeys(), opts.ListZip, opts.StartAfter)
+	if !opts.WithMetadata || !opts.StatMissingMetadata {
+		return objects
+	}
+	return c.statMissingMetadata(ctx, bucket, objects)
+}
+
+// statMissingMetadata fills the metadata of the listed objects that
+// the server did not return along with the listing, only MinIO does.
+// At most listMetadataConcurrency HEAD requests are in flight, objects
+// are sent in the order of the listing.
+func (c *S3Client) statMissingMetadata(ctx context.Context, bucket string, objects <-chan minio.ObjectInfo) <-chan minio.ObjectInfo {
+	pending := make(chan chan minio.ObjectInfo, listMetadataConcurrency)
+	slots := make(chan struct{}, listMetadataConcurrency)
+	go func() {
+		defer close(pending)
+		for object := range objects {
+			result := make(chan minio.ObjectInfo, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+			// A nil UserMetadata means the listing carried no metadata.
+			if object.Err != nil || object.UserMetadata != nil || strings.HasSuffix(object.Key, "/") {
+				result <- object
+				continue
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(object minio.ObjectInfo) {
+				defer func() { <-slots }()
+				result <- c.statListedObject(ctx, bucket, object)
+			}(object)
+		}
+	}()
+
+	objectCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectCh)
+		for result := range pending {
+			var object minio.ObjectInfo
+			select {
+			case object = <-result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case objectCh <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return objectCh
+}
+
+// statListedObject adds the metadata of a HEAD request to a listed
+// object, in the form MinIO lists it. The listed object is kept as is
+// if the HEAD fails, for instance on SSE-C encrypted objects.
+func (c *S3Client) statListedObject(ctx context.Context, bucket string, object minio.ObjectInfo) minio.ObjectInfo {
+	st, e := c.api.StatObject(ctx, bucket, object.Key, minio.StatObjectOptions{})
+	if e != nil {
+		return object
+	}
+	object.UserMetadata = make(minio.StringMap, len(st.UserMetadata)+1)
+	for k, v := range st.UserMetadata {
+		object.UserMetadata["X-Amz-Meta-"+k] = v
+	}
+	if st.ContentType != "" {
+		object.UserMetadata["content-type"] = st.ContentType
+	}
+	object.ContentType = st.ContentType
+	object.Metadata = st.Metadata
+	return object
+}
+
 func (c *S3Client) statIncompleteUpload(ctx context.Context, bucket, object string) (*ClientContent, *probe.Error) {
 	nonRecursive := false
 	objectMetadata := &ClientContent{}
@@ -1682,6 +2390,9 @@ func (c *S3Client) statIncompleteUpload(ctx context.Context, bucket, object stri
 // Stat - send a 'HEAD' on a bucket or object to fetch its metadata. It also returns
 // a DIR type content if a prefix does exist in the server.
 func (c *S3Client) Stat(ctx context.Context, opts StatOptions) (*ClientContent, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	c.Lock()
 	defer c.Unlock()
 	bucket, path := c.url2BucketAndObject()
@@ -1740,7 +2451,7 @@ func (c *S3Client) Stat(ctx context.Context, opts StatOptions) (*ClientContent,
 
 	nonRecursive := false
 	maxKeys := 1
-	for objectStat := range c.listObjectWrapper(ctx, bucket, path, nonRecursive, opts.timeRef, false, false, false, maxKeys, opts.isZip) {
+	for objectStat := range c.listObjectWrapper(ctx, bucket, path, nonRecursive, opts.timeRef, false, false, false, maxKeys, opts.isZip, "") {
 		if objectStat.Err != nil {
 			return nil, probe.NewError(objectStat.Err)
 		}
@@ -1764,23 +2475,23 @@ func (c *S3Client) getObjectStat(ctx context.Context, bucket, object string, opt
 	if e != nil {
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse.Code == "AccessDenied" {
-			return nil, probe.NewError(PathInsufficientPermission{Path: c.targetURL.String()})
+			return nil, withRequestIDs(probe.NewError(PathInsufficientPermission{Path: c.targetURL.String()}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "NoSuchBucket" {
-			return nil, probe.NewError(BucketDoesNotExist{
+			return nil, withRequestIDs(probe.NewError(BucketDoesNotExist{
 				Bucket: bucket,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "InvalidBucketName" {
-			return nil, probe.NewError(BucketInvalid{
+			return nil, withRequestIDs(probe.NewError(BucketInvalid{
 				Bucket: bucket,
-			})
+			}), errResponse.RequestID, errResponse.HostID)
 		}
 		if errResponse.Code == "NoSuchKey" {
 			if objectMetadata.IsDeleteMarker {
-				return nil, probe.NewError(ObjectIsDeleteMarker{})
+				return nil, withRequestIDs(probe.NewError(ObjectIsDeleteMarker{}), errResponse.RequestID, errResponse.HostID)
 			}
-			return nil, probe.NewError(ObjectMissing{})
+			return nil, withRequestIDs(probe.NewError(ObjectMissing{}), errResponse.RequestID, errResponse.HostID)
 		}
 		return nil, probe.NewError(e)
 	}
@@ -1824,6 +2535,19 @@ func isVirtualHostStyle(host string, lookup minio.BucketLookupType) bool {
 	return isAmazon(host) && !isAmazonChina(host) || isGoogle(host) || isAmazonAccelerated(host)
 }
 
+// PartSize returns the size of the first part of an object, the part
+// size of its upload when it was uploaded in parts.
+func (c *S3Client) PartSize(ctx context.Context, bucket, object string) (int64, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return 0, err
+	}
+	info, e := c.api.StatObject(ctx, bucket, object, minio.StatObjectOptions{PartNumber: 1})
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	return info.Size, nil
+}
+
 func url2BucketAndObject(u *ClientURL) (bucketName, objectName string) {
 	tokens := splitStr(u.Path, string(u.Separator), 3)
 	return tokens[1], tokens[2]
@@ -1906,6 +2630,10 @@ func (c *S3Client) listVersionsRoutine(ctx context.Context, b, o string, opts Li
 				continue
 			}
 
+			if opts.StartAfter != "" && objectVersion.Key <= opts.StartAfter {
+				// Versions are not listed after a key by the API.
+				continue
+			}
 			if !opts.WithOlderVersions && skipKey == objectVersion.Key {
 				// Skip current version if not asked to list all versions
 				// and we already listed the current object key name
@@ -1932,6 +2660,9 @@ func (c *S3Client) listVersionsRoutine(ctx context.Context, b, o string, opts Li
 
 // ListBuckets - list buckets
 func (c *S3Client) ListBuckets(ctx context.Context) ([]*ClientContent, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	buckets, err := c.api.ListBuckets(ctx)
 	if err != nil {
 		return nil, probe.NewError(err)
@@ -1951,6 +2682,13 @@ func (c *S3Client) List(ctx context.Context, opts ListOptions) <-chan *ClientCon
 	contentCh := make(chan *ClientContent)
 	go func() {
 		defer close(contentCh)
+		if err := c.checkClosed(); err != nil {
+			select {
+			case contentCh <- &ClientContent{Err: err}:
+			case <-ctx.Done():
+			}
+			return
+		}
 		if !opts.TimeRef.IsZero() || opts.WithOlderVersions {
 			c.versionedList(ctx, contentCh, opts)
 		} else {
@@ -2296,6 +3034,12 @@ func (c *S3Client) objectInfo2ClientContent(bucket string, entry minio.ObjectInf
 	content.BucketName = bucket
 	content.Size = entry.Size
 	content.ETag = entry.ETag
+	// minio-go decodes <DisplayName> into Owner.ID and <ID> into
+	// Owner.DisplayName, prefer the display name of the owner.
+	content.Owner = entry.Owner.ID
+	if content.Owner == "" {
+		content.Owner = entry.Owner.DisplayName
+	}
 	content.Time = entry.LastModified
 	content.Expires = entry.Expires
 	content.Expiration = entry.Expiration
@@ -2343,6 +3087,29 @@ func (c *S3Client) objectInfo2ClientContent(bucket string, entry minio.ObjectInf
 	return content
 }
 
+// probeBucket checks with a HEAD request that the bucket of the client
+// exists and that its credentials can access it, and returns the region
+// of the bucket, empty if unknown.
+func (c *S3Client) probeBucket(ctx context.Context) (string, *probe.Error) {
+	bucket, _ := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(BucketNameEmpty{})
+	}
+	exists, e := c.api.BucketExists(ctx, bucket)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	if !exists {
+		return "", probe.NewError(BucketDoesNotExist{Bucket: bucket})
+	}
+	// Resolved and cached by minio-go to sign the HEAD request.
+	region, e := c.api.GetBucketLocation(ctx, bucket)
+	if e != nil {
+		return "", nil
+	}
+	return region, nil
+}
+
 // Returns bucket stat info of current bucket.
 func (c *S3Client) bucketStat(ctx context.Context, opts BucketStatOptions) (*ClientContent, *probe.Error) {
 	if !opts.ignoreBucketExists {
@@ -2363,44 +3130,68 @@ func (c *S3Client) listInRoutine(ctx context.Context, contentCh chan *ClientCont
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
 	if opts.ListZip && (b == "" || o == "") {
-		contentCh <- &ClientContent{
+		sendContent(ctx, contentCh, &ClientContent{
 			Err: probe.NewError(errors.New("listing zip files must provide bucket and object")),
-		}
+		})
 		return
 	}
 	switch {
 	case b == "" && o == "":
 		buckets, e := c.api.ListBuckets(ctx)
 		if e != nil {
-			contentCh <- &ClientContent{
+			sendContent(ctx, contentCh, &ClientContent{
 				Err: probe.NewError(e),
-			}
+			})
 			return
 		}
 		for _, bucket := range buckets {
-			contentCh <- c.bucketInfo2ClientContent(bucket)
+			if !sendContent(ctx, contentCh, c.bucketInfo2ClientContent(bucket)) {
+				return
+			}
 		}
 	case b != "" && !strings.HasSuffix(c.targetURL.Path, string(c.targetURL.Separator)) && o == "":
 		content, err := c.bucketStat(ctx, BucketStatOptions{bucket: b})
 		if err != nil {
-			contentCh <- &ClientContent{Err: err.Trace(b)}
+			sendContent(ctx, contentCh, &ClientContent{Err: err.Trace(b)})
 			return
 		}
-		contentCh <- content
+		sendContent(ctx, contentCh, content)
 	default:
 		isRecursive := false
-		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
+		for object := range c.listLatestObjects(ctx, b, o, isRecursive, opts) {
 			if object.Err != nil {
-				contentCh <- &ClientContent{
+				sendContent(ctx, contentCh, &ClientContent{
 					Err: probe.NewError(object.Err),
-				}
+				})
+				return
+			}
+			if !sendContent(ctx, contentCh, c.objectInfo2ClientContent(b, object)) {
 				return
 			}
-			contentCh <- c.objectInfo2ClientContent(b, object)
 		}
 	}
 }
 
+// sendContent sends content unless ctx is canceled first, so that the
+// listing stops when its consumer does. It returns false if canceled.
+func sendContent(ctx context.Context, contentCh chan<- *ClientContent, content *ClientContent) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case contentCh <- content:
+		return true
+	}
+}
+
+// listMaxKeys returns the max-keys of the listing requests, -1 leaves
+// the default of the server.
+func (opts ListOptions) listMaxKeys() int {
+	if opts.MaxKeys > 0 {
+		return opts.MaxKeys
+	}
+	return -1
+}
+
 // S3 offers a range of storage classes designed for
 // different use cases, following list captures these.
 const (
@@ -2430,54 +3221,69 @@ func (c *S3Client) listRecursiveInRoutine(ctx context.Context, contentCh chan *C
 	case b == "" && o == "":
 		buckets, err := c.api.ListBuckets(ctx)
 		if err != nil {
-			contentCh <- &ClientContent{
+			sendContent(ctx, contentCh, &ClientContent{
 				Err: probe.NewError(err),
-			}
+			})
 			return
 		}
 		sortBucketsNameWithSlash(buckets)
 		for _, bucket := range buckets {
 			if opts.ShowDir == DirFirst {
-				contentCh <- c.bucketInfo2ClientContent(bucket)
+				if !sendContent(ctx, contentCh, c.bucketInfo2ClientContent(bucket)) {
+					return
+				}
 			}
 
 			isRecursive := true
-			for object := range c.listObjectWrapper(ctx, bucket.Name, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
+			for object := range c.listLatestObjects(ctx, bucket.Name, o, isRecursive, opts) {
 				if object.Err != nil {
-					contentCh <- &ClientContent{
+					sendContent(ctx, contentCh, &ClientContent{
 						Err: probe.NewError(object.Err),
-					}
+					})
+					return
+				}
+				if !sendContent(ctx, contentCh, c.objectInfo2ClientContent(bucket.Name, object)) {
 					return
 				}
-				contentCh <- c.objectInfo2ClientContent(bucket.Name, object)
 			}
 
 			if opts.ShowDir == DirLast {
-				contentCh <- c.bucketInfo2ClientContent(bucket)
+				if !sendContent(ctx, contentCh, c.bucketInfo2ClientContent(bucket)) {
+					return
+				}
 			}
 		}
 	default:
 		isRecursive := true
-		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
+		for object := range c.listLatestObjects(ctx, b, o, isRecursive, opts) {
 			if object.Err != nil {
-				contentCh <- &ClientContent{
+				sendContent(ctx, contentCh, &ClientContent{
 					Err: probe.NewError(object.Err),
-				}
+				})
+				return
+			}
+			if !sendContent(ctx, contentCh, c.objectInfo2ClientContent(b, object)) {
 				return
 			}
-			contentCh <- c.objectInfo2ClientContent(b, object)
 		}
 	}
 }
 
 // ShareDownload - get a usable presigned object url to share.
 func (c *S3Client) ShareDownload(ctx context.Context, versionID string, expires time.Duration) (string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", err
+	}
 	bucket, object := c.url2BucketAndObject()
 	// No additional request parameters are set for the time being.
 	reqParams := make(url.Values)
 	if versionID != "" {
 		reqParams.Set("versionId", versionID)
 	}
+	expires, err := c.presignExpiry(expires)
+	if err != nil {
+		return "", err.Trace(c.GetURL().String())
+	}
 	presignedURL, e := c.api.PresignedGetObject(ctx, bucket, object, expires, reqParams)
 	if e != nil {
 		return "", probe.NewError(e)
@@ -2485,9 +3291,34 @@ func (c *S3Client) ShareDownload(ctx context.Context, versionID string, expires
 	return presignedURL.String(), nil
 }
 
+// presignExpiry returns expires clamped to the remaining lifetime of the
+// session token, a presigned URL stops working along with the token
+// that signed it. A token with less than a second left is an error, the
+// URL would be dead on arrival.
+func (c *S3Client) presignExpiry(expires time.Duration) (time.Duration, *probe.Error) {
+	if c.sessionExpiry.IsZero() {
+		return expires, nil
+	}
+	remaining := time.Until(c.sessionExpiry).Truncate(time.Second)
+	if remaining < time.Second {
+		return 0, errSessionTokenExpired(c.sessionExpiry)
+	}
+	if remaining < expires {
+		return remaining, nil
+	}
+	return expires, nil
+}
+
 // ShareUpload - get data for presigned post http form upload.
 func (c *S3Client) ShareUpload(ctx context.Context, isRecursive bool, expires time.Duration, contentType string) (string, map[string]string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", nil, err
+	}
 	bucket, object := c.url2BucketAndObject()
+	expires, err := c.presignExpiry(expires)
+	if err != nil {
+		return "", nil, err.Trace(c.GetURL().String())
+	}
 	p := minio.NewPostPolicy()
 	if e := p.SetExpires(UTCNow().Add(expires)); e != nil {
 		return "", nil, probe.NewError(e)
@@ -2517,6 +3348,9 @@ func (c *S3Client) ShareUpload(ctx context.Context, isRecursive bool, expires ti
 
 // SetObjectLockConfig - Set object lock configurataion of bucket.
 func (c *S3Client) SetObjectLockConfig(ctx context.Context, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, object := c.url2BucketAndObject()
 
 	if bucket == "" || object != "" {
@@ -2528,14 +3362,14 @@ func (c *S3Client) SetObjectLockConfig(ctx context.Context, mode minio.Retention
 	if mode != "" && vuint > 0 && unit != "" {
 		e := c.api.SetBucketObjectLockConfig(ctx, bucket, &mode, &vuint, &unit)
 		if e != nil {
-			return probe.NewError(e).Trace(c.GetURL().String())
+			return featureError("object locking", e).Trace(c.GetURL().String())
 		}
 		return nil
 	}
 	if mode == "" && vuint == 0 && unit == "" {
 		e := c.api.SetBucketObjectLockConfig(ctx, bucket, nil, nil, nil)
 		if e != nil {
-			return probe.NewError(e).Trace(c.GetURL().String())
+			return featureError("object locking", e).Trace(c.GetURL().String())
 		}
 		return nil
 	}
@@ -2544,6 +3378,9 @@ func (c *S3Client) SetObjectLockConfig(ctx context.Context, mode minio.Retention
 
 // PutObjectRetention - Set object retention for a given object.
 func (c *S3Client) PutObjectRetention(ctx context.Context, versionID string, mode minio.RetentionMode, retainUntilDate time.Time, bypassGovernance bool) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, object := c.url2BucketAndObject()
 
 	var (
@@ -2568,20 +3405,23 @@ func (c *S3Client) PutObjectRetention(ctx context.Context, versionID string, mod
 	}
 	e := c.api.PutObjectRetention(ctx, bucket, object, opts)
 	if e != nil {
-		return probe.NewError(e).Trace(c.GetURL().String())
+		return featureError("object retention", e).Trace(c.GetURL().String())
 	}
 	return nil
 }
 
 // GetObjectRetention - Get object retention for a given object.
 func (c *S3Client) GetObjectRetention(ctx context.Context, versionID string) (minio.RetentionMode, time.Time, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", time.Time{}, err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if object == "" {
 		return "", time.Time{}, probe.NewError(ObjectNameEmpty{}).Trace(c.GetURL().String())
 	}
 	modePtr, untilPtr, e := c.api.GetObjectRetention(ctx, bucket, object, versionID)
 	if e != nil {
-		return "", time.Time{}, probe.NewError(e).Trace(c.GetURL().String())
+		return "", time.Time{}, featureError("object retention", e).Trace(c.GetURL().String())
 	}
 	var (
 		mode  minio.RetentionMode
@@ -2598,6 +3438,9 @@ func (c *S3Client) GetObjectRetention(ctx context.Context, versionID string) (mi
 
 // PutObjectLegalHold - Set object legal hold for a given object.
 func (c *S3Client) PutObjectLegalHold(ctx context.Context, versionID string, lhold minio.LegalHoldStatus) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if lhold.IsValid() {
 		opts := minio.PutObjectLegalHoldOptions{
@@ -2606,7 +3449,7 @@ func (c *S3Client) PutObjectLegalHold(ctx context.Context, versionID string, lho
 		}
 		e := c.api.PutObjectLegalHold(ctx, bucket, object, opts)
 		if e != nil {
-			return probe.NewError(e).Trace(c.GetURL().String())
+			return featureError("legal hold", e).Trace(c.GetURL().String())
 		}
 		return nil
 	}
@@ -2615,6 +3458,9 @@ func (c *S3Client) PutObjectLegalHold(ctx context.Context, versionID string, lho
 
 // GetObjectLegalHold - Get object legal hold for a given object.
 func (c *S3Client) GetObjectLegalHold(ctx context.Context, versionID string) (minio.LegalHoldStatus, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", err
+	}
 	var lhold minio.LegalHoldStatus
 	bucket, object := c.url2BucketAndObject()
 	opts := minio.GetObjectLegalHoldOptions{
@@ -2624,7 +3470,7 @@ func (c *S3Client) GetObjectLegalHold(ctx context.Context, versionID string) (mi
 	if e != nil {
 		errResp := minio.ToErrorResponse(e)
 		if errResp.Code != "NoSuchObjectLockConfiguration" {
-			return "", probe.NewError(e).Trace(c.GetURL().String())
+			return "", featureError("legal hold", e).Trace(c.GetURL().String())
 		}
 		return "", nil
 	}
@@ -2637,6 +3483,9 @@ func (c *S3Client) GetObjectLegalHold(ctx context.Context, versionID string) (mi
 
 // GetObjectLockConfig - Get object lock configuration of bucket.
 func (c *S3Client) GetObjectLockConfig(ctx context.Context) (string, minio.RetentionMode, uint64, minio.ValidityUnit, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", "", 0, "", err
+	}
 	bucket, object := c.url2BucketAndObject()
 
 	if bucket == "" || object != "" {
@@ -2645,7 +3494,7 @@ func (c *S3Client) GetObjectLockConfig(ctx context.Context) (string, minio.Reten
 
 	status, mode, validity, unit, e := c.api.GetObjectLockConfig(ctx, bucket)
 	if e != nil {
-		return "", "", 0, "", probe.NewError(e).Trace(c.GetURL().String())
+		return "", "", 0, "", featureError("object locking", e).Trace(c.GetURL().String())
 	}
 
 	if mode != nil && validity != nil && unit != nil {
@@ -2659,6 +3508,9 @@ func (c *S3Client) GetObjectLockConfig(ctx context.Context) (string, minio.Reten
 
 // GetTags - Get tags of bucket or object.
 func (c *S3Client) GetTags(ctx context.Context, versionID string) (map[string]string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	bucketName, objectName := c.url2BucketAndObject()
 	if bucketName == "" {
 		return nil, probe.NewError(BucketNameEmpty{})
@@ -2671,7 +3523,7 @@ func (c *S3Client) GetTags(ctx context.Context, versionID string) (map[string]st
 
 		tags, err := c.api.GetBucketTagging(ctx, bucketName)
 		if err != nil {
-			return nil, probe.NewError(err)
+			return nil, featureError("tagging", err)
 		}
 
 		return tags.ToMap(), nil
@@ -2679,7 +3531,7 @@ func (c *S3Client) GetTags(ctx context.Context, versionID string) (map[string]st
 
 	tags, err := c.api.GetObjectTagging(ctx, bucketName, objectName, minio.GetObjectTaggingOptions{VersionID: versionID})
 	if err != nil {
-		return nil, probe.NewError(err)
+		return nil, featureError("tagging", err)
 	}
 
 	return tags.ToMap(), nil
@@ -2687,6 +3539,9 @@ func (c *S3Client) GetTags(ctx context.Context, versionID string) (map[string]st
 
 // SetTags - Set tags of bucket or object.
 func (c *S3Client) SetTags(ctx context.Context, versionID, tagString string) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucketName, objectName := c.url2BucketAndObject()
 	if bucketName == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2707,14 +3562,110 @@ func (c *S3Client) SetTags(ctx context.Context, versionID, tagString string) *pr
 	}
 
 	if err != nil {
-		return probe.NewError(err)
+		return featureError("tagging", err)
 	}
 
 	return nil
 }
 
+// aclRequestExpiry is the validity of the presigned ?acl requests.
+const aclRequestExpiry = 5 * time.Minute
+
+// objectACLRequest sends a ?acl request for the object with the
+// transport of the client. minio-go cannot set the ACL of an object, so
+// it only presigns the request, with the headers given.
+func (c *S3Client) objectACLRequest(ctx context.Context, method string, header http.Header, body []byte) ([]byte, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return nil, probe.NewError(BucketNameEmpty{})
+	}
+	if object == "" {
+		return nil, probe.NewError(ObjectNameEmpty{})
+	}
+	expires, err := c.presignExpiry(aclRequestExpiry)
+	if err != nil {
+		return nil, err.Trace(c.GetURL().String())
+	}
+	u, e := c.api.PresignHeader(ctx, method, bucket, object, expires, url.Values{"acl": []string{""}}, header)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	req, e := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if len(body) > 0 {
+		sum := md5.Sum(body)
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	client := &http.Client{
+		Transport: c.transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, e := client.Do(req)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer resp.Body.Close()
+	data, e := io.ReadAll(resp.Body)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if resp.StatusCode != http.StatusOK {
+		errResp := minio.ErrorResponse{StatusCode: resp.StatusCode}
+		if xml.Unmarshal(data, &errResp) != nil || errResp.Code == "" {
+			errResp.Code, errResp.Message = resp.Status, resp.Status
+		}
+		return nil, withRequestIDs(featureError("object ACLs", errResp), errResp.RequestID, errResp.HostID)
+	}
+	return data, nil
+}
+
+// GetObjectACL - Get the ACL of an object.
+func (c *S3Client) GetObjectACL(ctx context.Context) (accessControlPolicy, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return accessControlPolicy{}, err
+	}
+	var policy accessControlPolicy
+	data, err := c.objectACLRequest(ctx, http.MethodGet, nil, nil)
+	if err != nil {
+		return policy, err
+	}
+	if e := xml.Unmarshal(data, &policy); e != nil {
+		return policy, probe.NewError(e)
+	}
+	return policy, nil
+}
+
+// PutObjectACL - Replace the ACL of an object with a canned ACL, or with
+// the grants of policy when cannedACL is empty.
+func (c *S3Client) PutObjectACL(ctx context.Context, cannedACL string, policy accessControlPolicy) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
+	if cannedACL != "" {
+		_, err := c.objectACLRequest(ctx, http.MethodPut, http.Header{"X-Amz-Acl": []string{cannedACL}}, nil)
+		return err
+	}
+	policy.XMLNS = s3Namespace
+	data, e := xml.Marshal(policy)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	_, err := c.objectACLRequest(ctx, http.MethodPut, http.Header{"Content-Type": []string{"application/xml"}}, data)
+	return err
+}
+
 // DeleteTags - Delete tags of bucket or object
 func (c *S3Client) DeleteTags(ctx context.Context, versionID string) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucketName, objectName := c.url2BucketAndObject()
 	if bucketName == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2731,7 +3682,7 @@ func (c *S3Client) DeleteTags(ctx context.Context, versionID string) *probe.Erro
 	}
 
 	if err != nil {
-		return probe.NewError(err)
+		return featureError("tagging", err)
 	}
 
 	return nil
@@ -2739,6 +3690,9 @@ func (c *S3Client) DeleteTags(ctx context.Context, versionID string) *probe.Erro
 
 // GetLifecycle - Get current lifecycle configuration.
 func (c *S3Client) GetLifecycle(ctx context.Context) (*lifecycle.Configuration, time.Time, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, time.Time{}, err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return nil, time.Time{}, probe.NewError(BucketNameEmpty{})
@@ -2754,6 +3708,9 @@ func (c *S3Client) GetLifecycle(ctx context.Context) (*lifecycle.Configuration,
 
 // SetLifecycle - Set lifecycle configuration on a bucket
 func (c *S3Client) SetLifecycle(ctx context.Context, config *lifecycle.Configuration) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2768,6 +3725,9 @@ func (c *S3Client) SetLifecycle(ctx context.Context, config *lifecycle.Configura
 
 // GetVersion - gets bucket version info.
 func (c *S3Client) GetVersion(ctx context.Context) (config minio.BucketVersioningConfiguration, err *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return minio.BucketVersioningConfiguration{}, err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return config, probe.NewError(BucketNameEmpty{})
@@ -2783,6 +3743,9 @@ func (c *S3Client) GetVersion(ctx context.Context) (config minio.BucketVersionin
 
 // SetVersion - Set version configuration on a bucket
 func (c *S3Client) SetVersion(ctx context.Context, status string, prefixes []string, excludeFolders bool) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2817,6 +3780,9 @@ func (c *S3Client) SetVersion(ctx context.Context, status string, prefixes []str
 
 // GetReplication - gets replication configuration for a given bucket.
 func (c *S3Client) GetReplication(ctx context.Context) (replication.Config, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return replication.Config{}, err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return replication.Config{}, probe.NewError(BucketNameEmpty{})
@@ -2831,6 +3797,9 @@ func (c *S3Client) GetReplication(ctx context.Context) (replication.Config, *pro
 
 // RemoveReplication - removes replication configuration for a given bucket.
 func (c *S3Client) RemoveReplication(ctx context.Context) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2842,6 +3811,9 @@ func (c *S3Client) RemoveReplication(ctx context.Context) *probe.Error {
 
 // SetReplication sets replication configuration for a given bucket.
 func (c *S3Client) SetReplication(ctx context.Context, cfg *replication.Config, opts replication.Options) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, objectPrefix := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2872,6 +3844,9 @@ func (c *S3Client) SetReplication(ctx context.Context, cfg *replication.Config,
 
 // GetReplicationMetrics - Get replication metrics for a given bucket.
 func (c *S3Client) GetReplicationMetrics(ctx context.Context) (replication.MetricsV2, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return replication.MetricsV2{}, err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return replication.MetricsV2{}, probe.NewError(BucketNameEmpty{})
@@ -2887,6 +3862,9 @@ func (c *S3Client) GetReplicationMetrics(ctx context.Context) (replication.Metri
 // ResetReplication - kicks off replication again on previously replicated objects if existing object
 // replication is enabled in the replication config.Optional to provide a timestamp
 func (c *S3Client) ResetReplication(ctx context.Context, before time.Duration, tgtArn string) (rinfo replication.ResyncTargetsInfo, err *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return replication.ResyncTargetsInfo{}, err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return rinfo, probe.NewError(BucketNameEmpty{})
@@ -2901,6 +3879,9 @@ func (c *S3Client) ResetReplication(ctx context.Context, before time.Duration, t
 
 // ReplicationResyncStatus - gets status of replication resync for this target arn
 func (c *S3Client) ReplicationResyncStatus(ctx context.Context, arn string) (rinfo replication.ResyncTargetsInfo, err *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return replication.ResyncTargetsInfo{}, err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return rinfo, probe.NewError(BucketNameEmpty{})
@@ -2915,6 +3896,9 @@ func (c *S3Client) ReplicationResyncStatus(ctx context.Context, arn string) (rin
 
 // GetEncryption - gets bucket encryption info.
 func (c *S3Client) GetEncryption(ctx context.Context) (algorithm, keyID string, err *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", "", err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return "", "", probe.NewError(BucketNameEmpty{})
@@ -2936,6 +3920,9 @@ func (c *S3Client) GetEncryption(ctx context.Context) (algorithm, keyID string,
 
 // SetEncryption - Set encryption configuration on a bucket
 func (c *S3Client) SetEncryption(ctx context.Context, encType, kmsKeyID string) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2957,6 +3944,9 @@ func (c *S3Client) SetEncryption(ctx context.Context, encType, kmsKeyID string)
 
 // DeleteEncryption - removes encryption configuration on a bucket
 func (c *S3Client) DeleteEncryption(ctx context.Context) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, _ := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -2969,6 +3959,9 @@ func (c *S3Client) DeleteEncryption(ctx context.Context) *probe.Error {
 
 // GetBucketInfo gets info about a bucket
 func (c *S3Client) GetBucketInfo(ctx context.Context) (BucketInfo, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return BucketInfo{}, err
+	}
 	var b BucketInfo
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
@@ -3032,8 +4025,12 @@ func (c *S3Client) GetBucketInfo(ctx context.Context) (BucketInfo, *probe.Error)
 	return b, nil
 }
 
-// Restore gets a copy of an archived object
-func (c *S3Client) Restore(ctx context.Context, versionID string, days int) *probe.Error {
+// Restore gets a copy of an archived object, ObjectRestoreInProgress is
+// returned if a restore of the object is already running.
+func (c *S3Client) Restore(ctx context.Context, versionID string, days int, tier minio.TierType) *probe.Error {
+	if err := c.checkClosed(); err != nil {
+		return err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return probe.NewError(BucketNameEmpty{})
@@ -3044,15 +4041,182 @@ func (c *S3Client) Restore(ctx context.Context, versionID string, days int) *pro
 
 	req := minio.RestoreRequest{}
 	req.SetDays(days)
-	req.SetGlacierJobParameters(minio.GlacierJobParameters{Tier: minio.TierExpedited})
+	req.SetGlacierJobParameters(minio.GlacierJobParameters{Tier: tier})
 	if err := c.api.RestoreObject(ctx, bucket, object, versionID, req); err != nil {
+		if minio.ToErrorResponse(err).Code == "RestoreAlreadyInProgress" {
+			return probe.NewError(ObjectRestoreInProgress{Object: object})
+		}
 		return probe.NewError(err)
 	}
 	return nil
 }
 
+// NewMultipartUpload starts a multipart upload of the object and returns
+// its upload ID, the parts can then be uploaded by any number of clients.
+func (c *S3Client) NewMultipartUpload(ctx context.Context, opts PutOptions) (string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", err
+	}
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(BucketNameEmpty{})
+	}
+	if object == "" {
+		return "", probe.NewError(ObjectNameEmpty{})
+	}
+	uploadID, e := minio.Core{Client: c.api}.NewMultipartUpload(ctx, bucket, object, minio.PutObjectOptions{
+		ContentType:          "application/octet-stream",
+		ServerSideEncryption: opts.sse,
+		StorageClass:         strings.ToUpper(opts.storageClass),
+	})
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return uploadID, nil
+}
+
+// UploadPart uploads a part of a multipart upload and returns its ETag.
+func (c *S3Client) UploadPart(ctx context.Context, uploadID string, partNumber int, reader io.Reader, size int64, opts PutOptions) (string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", err
+	}
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(BucketNameEmpty{})
+	}
+	if object == "" {
+		return "", probe.NewError(ObjectNameEmpty{})
+	}
+	partOpts := minio.PutObjectPartOptions{}
+	// Only SSE-C keys are sent along with each part.
+	if opts.sse != nil && opts.sse.Type() == encrypt.SSEC {
+		partOpts.SSE = opts.sse
+	}
+	part, e := minio.Core{Client: c.api}.PutObjectPart(ctx, bucket, object, uploadID, partNumber, reader, size, partOpts)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return part.ETag, nil
+}
+
+// CopyPart copies length bytes at offset of the object itself to a part
+// of a multipart upload of the object, on the server. The copy fails if
+// the ETag of the object is no longer etag.
+func (c *S3Client) CopyPart(ctx context.Context, uploadID string, partNumber int, offset, length int64, etag string, opts PutOptions) (string, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", err
+	}
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", probe.NewError(BucketNameEmpty{})
+	}
+	if object == "" {
+		return "", probe.NewError(ObjectNameEmpty{})
+	}
+	header := make(http.Header)
+	header.Set("X-Amz-Copy-Source-If-Match", "\""+etag+"\"")
+	// The object and the part are encrypted with the same SSE-C key.
+	if opts.sse != nil && opts.sse.Type() == encrypt.SSEC {
+		encrypt.SSECopy(opts.sse).Marshal(header)
+		opts.sse.Marshal(header)
+	}
+	metadata := make(map[string]string, len(header))
+	for k := range header {
+		metadata[k] = header.Get(k)
+	}
+	part, e := minio.Core{Client: c.api}.CopyObjectPart(ctx, bucket, object, bucket, object, uploadID, partNumber, offset, length, metadata)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return part.ETag, nil
+}
+
+// listUploadParts returns the parts of a multipart upload sorted by
+// part number.
+func (c *S3Client) listUploadParts(ctx context.Context, bucket, object, uploadID string) ([]minio.ObjectPart, error) {
+	var parts []minio.ObjectPart
+	marker := 0
+	for {
+		result, e := minio.Core{Client: c.api}.ListObjectParts(ctx, bucket, object, uploadID, marker, 1000)
+		if e != nil {
+			return nil, e
+		}
+		parts = append(parts, result.ObjectParts...)
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+// UploadedParts returns the numbers of the uploaded parts of a
+// multipart upload.
+func (c *S3Client) UploadedParts(ctx context.Context, uploadID string) ([]int, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return nil, probe.NewError(BucketNameEmpty{})
+	}
+	if object == "" {
+		return nil, probe.NewError(ObjectNameEmpty{})
+	}
+	parts, e := c.listUploadParts(ctx, bucket, object, uploadID)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	partNumbers := make([]int, len(parts))
+	for i, part := range parts {
+		partNumbers[i] = part.PartNumber
+	}
+	return partNumbers, nil
+}
+
+// CompleteMultipartUpload completes a multipart upload with all of its
+// uploaded parts, UploadPartMissing is returned if a part before the
+// last one has not been uploaded.
+func (c *S3Client) CompleteMultipartUpload(ctx context.Context, uploadID string, opts PutOptions) (string, int, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return "", 0, err
+	}
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return "", 0, probe.NewError(BucketNameEmpty{})
+	}
+	if object == "" {
+		return "", 0, probe.NewError(ObjectNameEmpty{})
+	}
+	uploaded, e := c.listUploadParts(ctx, bucket, object, uploadID)
+	if e != nil {
+		return "", 0, probe.NewError(e)
+	}
+	var parts []minio.CompletePart
+	for _, part := range uploaded {
+		if part.PartNumber != len(parts)+1 {
+			return "", 0, probe.NewError(UploadPartMissing{UploadID: uploadID, PartNumber: len(parts) + 1})
+		}
+		parts = append(parts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	if len(parts) == 0 {
+		return "", 0, probe.NewError(UploadPartMissing{UploadID: uploadID, PartNumber: 1})
+	}
+	completeOpts := minio.PutObjectOptions{}
+	if opts.sse != nil && opts.sse.Type() == encrypt.SSEC {
+		completeOpts.ServerSideEncryption = opts.sse
+	}
+	info, e := minio.Core{Client: c.api}.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, completeOpts)
+	if e != nil {
+		return "", 0, probe.NewError(e)
+	}
+	return info.ETag, len(parts), nil
+}
+
 // GetPart gets an object in a given number of parts
 func (c *S3Client) GetPart(ctx context.Context, part int) (io.ReadCloser, *probe.Error) {
+	if err := c.checkClosed(); err != nil {
+		return nil, err
+	}
 	bucket, object := c.url2BucketAndObject()
 	if bucket == "" {
 		return nil, probe.NewError(BucketNameEmpty{})
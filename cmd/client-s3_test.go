@@ -21,15 +21,29 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
 
+	"github.com/minio/mc/internal/miniotest"
 	minio "github.com/minio/minio-go/v7"
 	checkv1 "gopkg.in/check.v1"
 )
 
+// Credentials of the clients of the test servers.
+const testAccessKey, testSecretKey = "WLGDGYAQYIGI833EV05A", "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+
 type bucketHandler struct {
 	resource string
 }
@@ -191,6 +205,27 @@ func (h stsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// newTestS3Config returns the Config of a client of the test server at
+// url, with the credentials the test servers accept.
+func newTestS3Config(url string) *Config {
+	return &Config{
+		HostURL:   url,
+		AccessKey: testAccessKey,
+		SecretKey: testSecretKey,
+		Signature: "S3v4",
+	}
+}
+
+// newTestS3Client returns a client of the test server at url.
+func newTestS3Client(t *testing.T, url string) Client {
+	t.Helper()
+	clnt, err := S3New(newTestS3Config(url))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clnt
+}
+
 // Test bucket operations.
 func (s *TestSuite) TestBucketOperations(c *checkv1.C) {
 	bucket := bucketHandler{
@@ -275,6 +310,289 @@ func (s *TestSuite) TestObjectOperations(c *checkv1.C) {
 	}
 }
 
+// truncatingWriter writes the first n bytes of a response body only,
+// less than its Content-Length, which makes the server close the
+// connection.
+type truncatingWriter struct {
+	http.ResponseWriter
+	n int
+}
+
+func (w *truncatingWriter) Write(b []byte) (int, error) {
+	if len(b) > w.n {
+		b = b[:w.n]
+	}
+	w.n -= len(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Test that a download interrupted in the middle of the body is resumed.
+func (s *TestSuite) TestGetResumeOnUnexpectedEOF(c *checkv1.C) {
+	defer func(unit time.Duration) { getResumeRetryUnit = unit }(getResumeRetryUnit)
+	getResumeRetryUnit = time.Millisecond
+
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	for _, testCase := range []struct {
+		drops   int32
+		wantErr bool
+	}{
+		{drops: 3},
+		{drops: getResumeMaxRetries + 1, wantErr: true},
+	} {
+		drops := testCase.drops
+		var ranges []string
+		server := miniotest.NewServer()
+		server.PutObject("bucket", "object", data)
+		// The first drops GET requests of the object are dropped after
+		// 12345 bytes of its body.
+		front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Path == "/bucket/object" && len(r.URL.Query()) == 0 {
+				ranges = append(ranges, r.Header.Get("Range"))
+				if atomic.AddInt32(&drops, -1) >= 0 {
+					w = &truncatingWriter{ResponseWriter: w, n: 12345}
+				}
+			}
+			server.ServeHTTP(w, r)
+		}))
+
+		s3c, err := S3New(newMiniotestConfig(front.URL + "/bucket/object"))
+		c.Assert(err, checkv1.IsNil)
+
+		reader, _, err := s3c.Get(context.Background(), GetOptions{})
+		c.Assert(err, checkv1.IsNil)
+		got, e := io.ReadAll(reader)
+		reader.Close()
+		front.Close()
+		server.Close()
+
+		if testCase.wantErr {
+			c.Assert(e, checkv1.Equals, io.ErrUnexpectedEOF)
+			continue
+		}
+		c.Assert(e, checkv1.IsNil)
+		c.Assert(bytes.Equal(got, data), checkv1.Equals, true)
+		c.Assert(ranges, checkv1.DeepEquals, []string{"", "bytes=12345-", "bytes=24690-", "bytes=37035-"})
+	}
+}
+
+// Test that presigned URLs do not outlive the session token.
+func (s *TestSuite) TestPresignExpiryClamp(c *checkv1.C) {
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.PutObject("bucket", "object", []byte("Hello, World"))
+
+	auth := AuthData{
+		AccessKey:    miniotest.AccessKey,
+		SecretKey:    miniotest.SecretKey,
+		SessionToken: "token",
+		ExpireAt:     UTCNow().Add(10 * time.Minute).Format("2006-01-02 15:04:05"),
+	}
+	sessionExpiry, e := auth.expiry()
+	c.Assert(e, checkv1.IsNil)
+
+	conf := NewS3Config(AuthAlias, server.URL+"/bucket/object", &aliasConfigV10{
+		API:           "S3v4",
+		AccessKey:     auth.AccessKey,
+		SecretKey:     auth.SecretKey,
+		SessionToken:  auth.SessionToken,
+		SessionExpiry: sessionExpiry,
+	})
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	for _, testCase := range []struct {
+		requested time.Duration
+		min, max  int
+	}{
+		{requested: 7 * 24 * time.Hour, min: 9*60 - 10, max: 10 * 60},
+		{requested: 5 * time.Minute, min: 5 * 60, max: 5 * 60},
+	} {
+		shareURL, err := s3c.ShareDownload(context.Background(), "", testCase.requested)
+		c.Assert(err, checkv1.IsNil)
+		u, e := url.Parse(shareURL)
+		c.Assert(e, checkv1.IsNil)
+		expires, e := strconv.Atoi(u.Query().Get("X-Amz-Expires"))
+		c.Assert(e, checkv1.IsNil)
+		c.Assert(expires >= testCase.min && expires <= testCase.max, checkv1.Equals, true,
+			checkv1.Commentf("X-Amz-Expires=%d for a requested expiry of %s", expires, testCase.requested))
+	}
+
+	// No URL is presigned with an expired session token.
+	s3c.(*S3Client).sessionExpiry = UTCNow().Add(-time.Minute)
+	_, err = s3c.ShareDownload(context.Background(), "", time.Hour)
+	c.Assert(err, checkv1.NotNil)
+	_, ok := err.ToGoError().(sessionTokenExpiredErr)
+	c.Assert(ok, checkv1.Equals, true)
+	_, _, err = s3c.ShareUpload(context.Background(), false, time.Hour, "")
+	c.Assert(err, checkv1.NotNil)
+}
+
+// countingReader counts the bytes read from a seekable source.
+type countingReader struct {
+	*bytes.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, e := r.Reader.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, e
+}
+
+func (r *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	n, e := r.Reader.ReadAt(p, off)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, e
+}
+
+// Test that --md5 uploads read the source once and verify the ETag.
+func (s *TestSuite) TestPutMD5SinglePass(c *checkv1.C) {
+	defer func(n int) { minio.MaxRetry = n }(minio.MaxRetry)
+	minio.MaxRetry = 1
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 11<<20/16)
+	for _, testCase := range []struct {
+		size    int
+		corrupt bool
+	}{
+		{size: 1 << 20},
+		{size: len(data)},
+		{size: 1 << 20, corrupt: true},
+		{size: len(data), corrupt: true},
+	} {
+		server := miniotest.NewServer()
+		server.MakeBucket("bucket")
+		if testCase.corrupt {
+			server.CorruptPut = func(string) bool { return true }
+			server.CorruptPart = func(string, int) bool { return true }
+		}
+
+		s3c, err := S3New(newMiniotestConfig(server.URL + "/bucket/object"))
+		c.Assert(err, checkv1.IsNil)
+
+		reader := &countingReader{Reader: bytes.NewReader(data[:testCase.size])}
+		n, err := s3c.Put(context.Background(), reader, int64(testCase.size), nil, PutOptions{
+			md5:              true,
+			multipartSize:    5 << 20,
+			multipartThreads: 4,
+		})
+		server.Close()
+
+		c.Assert(atomic.LoadInt64(&reader.n), checkv1.Equals, int64(testCase.size))
+		if testCase.corrupt {
+			c.Assert(err, checkv1.NotNil)
+			_, ok := err.ToGoError().(ChecksumMismatch)
+			c.Assert(ok, checkv1.Equals, true)
+			continue
+		}
+		c.Assert(err, checkv1.IsNil)
+		c.Assert(n, checkv1.Equals, int64(testCase.size))
+	}
+}
+
+// restoreObjectHandler serves restore requests of an archived object,
+// which miniotest does not, the first request starts a restore and the
+// next ones find it running.
+type restoreObjectHandler struct {
+	*miniotest.Server
+	requests []string
+}
+
+func (h *restoreObjectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Query().Has("restore"):
+		body, _ := io.ReadAll(r.Body)
+		h.requests = append(h.requests, string(body))
+		if len(h.requests) == 1 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("<Error><Code>RestoreAlreadyInProgress</Code><Message>Object restore is already in progress</Message></Error>"))
+	case r.Method == http.MethodHead:
+		h.Server.ServeHTTP(headerWriter{w, map[string]string{
+			"X-Amz-Storage-Class": "GLACIER",
+			"X-Amz-Restore":       `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`,
+		}}, r)
+	default:
+		h.Server.ServeHTTP(w, r)
+	}
+}
+
+// Test restore requests of archived objects and the restore status of stat.
+func (s *TestSuite) TestRestore(c *checkv1.C) {
+	handler := &restoreObjectHandler{Server: miniotest.NewServer()}
+	defer handler.Close()
+	handler.PutObject("bucket", "object", []byte("archived"))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	s3c, err := S3New(newMiniotestConfig(server.URL + "/bucket/object"))
+	c.Assert(err, checkv1.IsNil)
+
+	err = s3c.Restore(context.Background(), "", 2, minio.TierBulk)
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(handler.requests, checkv1.HasLen, 1)
+	c.Assert(strings.Contains(handler.requests[0], "<Days>2</Days>"), checkv1.Equals, true)
+	c.Assert(strings.Contains(handler.requests[0], "<Tier>Bulk</Tier>"), checkv1.Equals, true)
+
+	err = s3c.Restore(context.Background(), "", 2, minio.TierBulk)
+	c.Assert(err, checkv1.NotNil)
+	_, ok := err.ToGoError().(ObjectRestoreInProgress)
+	c.Assert(ok, checkv1.Equals, true)
+
+	content, err := s3c.Stat(context.Background(), StatOptions{})
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(content.Restore, checkv1.NotNil)
+	c.Assert(content.Restore.OngoingRestore, checkv1.Equals, false)
+	c.Assert(content.Restore.ExpiryTime.Equal(time.Date(2012, time.December, 21, 0, 0, 0, 0, time.UTC)), checkv1.Equals, true)
+}
+
+// Test that downloads are verified against single part, unencrypted ETags.
+func (s *TestSuite) TestGetVerifyResponse(c *checkv1.C) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	goodETag := fmt.Sprintf("%x", md5.Sum(data))
+	badETag := fmt.Sprintf("%x", md5.Sum([]byte("corrupted")))
+	for i, testCase := range []struct {
+		etag     string
+		headers  map[string]string
+		verify   bool
+		mismatch bool
+	}{
+		{etag: goodETag, verify: true},
+		{etag: badETag, verify: true, mismatch: true},
+		{etag: badETag},
+		{etag: badETag + "-2", verify: true},
+		{etag: badETag, headers: map[string]string{"X-Amz-Server-Side-Encryption": "aws:kms"}, verify: true},
+	} {
+		server := miniotest.NewServer()
+		server.SetObject("bucket", miniotest.Object{Key: "object", Data: data, ETag: testCase.etag})
+		front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			server.ServeHTTP(headerWriter{w, testCase.headers}, r)
+		}))
+
+		s3c, err := S3New(newMiniotestConfig(front.URL + "/bucket/object"))
+		c.Assert(err, checkv1.IsNil)
+
+		reader, _, err := s3c.Get(context.Background(), GetOptions{VerifyResponse: testCase.verify})
+		c.Assert(err, checkv1.IsNil)
+		got, e := io.ReadAll(io.LimitReader(reader, int64(len(data))))
+		reader.Close()
+		front.Close()
+		server.Close()
+
+		c.Assert(bytes.Equal(got, data), checkv1.Equals, true, checkv1.Commentf("Test %d", i+1))
+		if !testCase.mismatch {
+			c.Assert(e, checkv1.IsNil, checkv1.Commentf("Test %d", i+1))
+			continue
+		}
+		mismatch, ok := e.(ChecksumMismatch)
+		c.Assert(ok, checkv1.Equals, true, checkv1.Commentf("Test %d: %v", i+1, e))
+		c.Assert(mismatch.Expected, checkv1.Equals, badETag)
+		c.Assert(mismatch.Got, checkv1.Equals, goodETag)
+	}
+}
+
 var testSelectCompressionTypeCases = []struct {
 	opts            SelectObjectOpts
 	object          string
@@ -299,3 +617,140 @@ func (s *TestSuite) TestSelectCompressionType(c *checkv1.C) {
 		c.Assert(cType, checkv1.DeepEquals, test.compressionType)
 	}
 }
+
+func (s *TestSuite) TestHTTP1(c *checkv1.C) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, test := range []struct {
+		http1, http2 bool
+		expected     string
+	}{
+		{false, false, "HTTP/1.1"},
+		{false, true, "HTTP/2.0"},
+		{true, false, "HTTP/1.1"},
+		{true, true, "HTTP/1.1"},
+	} {
+		expected := test.expected
+		transport := getTransportForConfig(&Config{HostURL: server.URL, Insecure: true, HTTP1: test.http1, HTTP2: test.http2}, false)
+		resp, e := (&http.Client{Transport: transport}).Get(server.URL)
+		c.Assert(e, checkv1.IsNil)
+		body, e := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		c.Assert(e, checkv1.IsNil)
+		c.Assert(resp.Proto, checkv1.Equals, expected)
+		c.Assert(string(body), checkv1.Equals, expected)
+	}
+}
+
+func (s *TestSuite) TestSigningRegion(c *checkv1.C) {
+	var scopes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scopes = append(scopes, requestRegion(r))
+		io.WriteString(w, `<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`)
+	}))
+	defer server.Close()
+
+	// The host encodes the location sh-01, which is not what the
+	// server expects in the credential scope.
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+	for signingRegion, expected := range map[string]string{"": "sh-01", "us-east-1": "us-east-1"} {
+		conf := &Config{
+			HostURL:       "http://minio-sh-01.example.com",
+			AccessKey:     testAccessKey,
+			SecretKey:     testSecretKey,
+			Signature:     "S3v4",
+			Region:        "sh-01",
+			SigningRegion: signingRegion,
+			Transport:     transport,
+		}
+		s3c, err := S3New(conf)
+		c.Assert(err, checkv1.IsNil)
+		scopes = nil
+		_, err = s3c.ListBuckets(context.Background())
+		c.Assert(err, checkv1.IsNil)
+		c.Assert(scopes, checkv1.DeepEquals, []string{expected})
+	}
+}
+
+// closeRecordingConn counts the connections closed.
+type closeRecordingConn struct {
+	net.Conn
+	closed *int32
+}
+
+func (c closeRecordingConn) Close() error {
+	atomic.AddInt32(c.closed, 1)
+	return c.Conn.Close()
+}
+
+// Test that Close closes the idle connections of the client, and that the
+// client fails afterwards.
+func (s *TestSuite) TestClientClose(c *checkv1.C) {
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.PutObject("bucket", "object", []byte("data"))
+
+	var dialed, closed int32
+	conf := newMiniotestConfig(server.URL + "/bucket/object")
+	conf.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, e := (&net.Dialer{}).DialContext(ctx, network, addr)
+			if e != nil {
+				return nil, e
+			}
+			atomic.AddInt32(&dialed, 1)
+			return closeRecordingConn{Conn: conn, closed: &closed}, nil
+		},
+	}
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	_, err = s3c.Stat(context.Background(), StatOptions{})
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(atomic.LoadInt32(&dialed) > 0, checkv1.Equals, true)
+	c.Assert(atomic.LoadInt32(&closed), checkv1.Equals, int32(0))
+
+	s3c.Close()
+	c.Assert(atomic.LoadInt32(&closed), checkv1.Equals, atomic.LoadInt32(&dialed))
+
+	before := atomic.LoadInt32(&dialed)
+	_, err = s3c.Stat(context.Background(), StatOptions{})
+	c.Assert(err, checkv1.NotNil)
+	c.Assert(errors.Is(err.ToGoError(), errClientClosed), checkv1.Equals, true)
+	c.Assert(atomic.LoadInt32(&dialed), checkv1.Equals, before)
+}
+
+// Test that a client can be closed while it sends requests.
+func (s *TestSuite) TestClientCloseConcurrent(c *checkv1.C) {
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.PutObject("bucket", "object", []byte("data"))
+
+	s3c, err := S3New(newMiniotestConfig(server.URL + "/bucket/object"))
+	c.Assert(err, checkv1.IsNil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				s3c.Stat(context.Background(), StatOptions{})
+			}
+		}()
+	}
+	s3c.Close()
+	wg.Wait()
+
+	_, err = s3c.Stat(context.Background(), StatOptions{})
+	c.Assert(errors.Is(err.ToGoError(), errClientClosed), checkv1.Equals, true)
+}
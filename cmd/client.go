@@ -24,8 +24,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/minio/mc/pkg/limiter"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/minio/minio-go/v7/pkg/encrypt"
 	"github.com/minio/minio-go/v7/pkg/lifecycle"
 	"github.com/minio/minio-go/v7/pkg/replication"
@@ -50,6 +52,14 @@ type GetOptions struct {
 	Zip        bool
 	RangeStart int64
 	Preserve   bool
+
+	// RangeLength reads only that many bytes from RangeStart, 0 reads
+	// up to the end of the object.
+	RangeLength int64
+
+	// VerifyResponse checks full object downloads against a single part,
+	// unencrypted ETag, see newETagVerifyReader.
+	VerifyResponse bool
 }
 
 // PutOptions holds options for PUT operation
@@ -62,6 +72,12 @@ type PutOptions struct {
 	multipartSize         uint64
 	multipartThreads      uint
 	concurrentStream      bool
+	storeSHA256           bool
+
+	// resume keeps the temporary file of a failed download to a local
+	// file, the next download appends to it from resumeOffset.
+	resume       bool
+	resumeOffset int64
 }
 
 // StatOptions holds options of the HEAD operation
@@ -92,6 +108,15 @@ type ListOptions struct {
 	TimeRef           time.Time
 	ShowDir           DirOpt
 	Count             int
+
+	// StartAfter lists the keys after this one only, MaxKeys bounds
+	// the keys of each listing request when positive.
+	StartAfter string
+	MaxKeys    int
+
+	// StatMissingMetadata sends a HEAD for the objects whose metadata
+	// the listing did not carry, WithMetadata must be set as well.
+	StatMissingMetadata bool
 }
 
 // CopyOptions holds options for copying operation
@@ -125,6 +150,10 @@ type Client interface {
 	GetAccessRules(ctx context.Context) (policyRules map[string]string, error *probe.Error)
 	SetAccess(ctx context.Context, access string, isJSON bool) *probe.Error
 
+	// Object ACL operations.
+	GetObjectACL(ctx context.Context) (accessControlPolicy, *probe.Error)
+	PutObjectACL(ctx context.Context, cannedACL string, policy accessControlPolicy) *probe.Error
+
 	// I/O operations
 	Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error
 
@@ -153,6 +182,9 @@ type Client interface {
 	// GetURL returns back internal url
 	GetURL() ClientURL
 	AddUserAgent(app, version string)
+	// Close closes the idle connections of the client, it must not be
+	// used afterwards.
+	Close()
 
 	// Tagging operations
 	GetTags(ctx context.Context, versionID string) (map[string]string, *probe.Error)
@@ -182,7 +214,15 @@ type Client interface {
 	GetBucketInfo(ctx context.Context) (BucketInfo, *probe.Error)
 
 	// Restore an object
-	Restore(ctx context.Context, versionID string, days int) *probe.Error
+	Restore(ctx context.Context, versionID string, days int, tier minio.TierType) *probe.Error
+
+	// Multipart upload operations, the parts of an upload may be sent
+	// by different processes.
+	NewMultipartUpload(ctx context.Context, opts PutOptions) (uploadID string, err *probe.Error)
+	UploadPart(ctx context.Context, uploadID string, partNumber int, reader io.Reader, size int64, opts PutOptions) (etag string, err *probe.Error)
+	CopyPart(ctx context.Context, uploadID string, partNumber int, offset, length int64, etag string, opts PutOptions) (string, *probe.Error)
+	UploadedParts(ctx context.Context, uploadID string) (partNumbers []int, err *probe.Error)
+	CompleteMultipartUpload(ctx context.Context, uploadID string, opts PutOptions) (etag string, parts int, err *probe.Error)
 
 	// OD operations
 	GetPart(ctx context.Context, part int) (io.ReadCloser, *probe.Error)
@@ -201,6 +241,7 @@ type ClientContent struct {
 	Tags         map[string]string
 	UserMetadata map[string]string
 	ETag         string
+	Owner        string
 	Expires      time.Time
 
 	Expiration       time.Time
@@ -220,6 +261,10 @@ type ClientContent struct {
 	Restore *minio.RestoreInfo
 
 	Err *probe.Error
+
+	// targetSuffix is the path of a source relative to the target, when
+	// it differs from its path relative to the source.
+	targetSuffix string
 }
 
 // Config - see http://docs.amazonwebservices.com/AmazonS3/latest/dev/index.html?RESTAuthentication.html
@@ -228,6 +273,9 @@ type Config struct {
 	AccessKey         string
 	SecretKey         string
 	SessionToken      string
+	SessionExpiry     time.Time
+	Region            string
+	SigningRegion     string
 	Signature         string
 	HostURL           string
 	AppName           string
@@ -237,9 +285,36 @@ type Config struct {
 	Lookup            minio.BucketLookupType
 	ConnReadDeadline  time.Duration
 	ConnWriteDeadline time.Duration
-	UploadLimit       int64
-	DownloadLimit     int64
+	UploadBucket      *limiter.Bucket
+	DownloadBucket    *limiter.Bucket
+	MaxHostConns      int
+	StallTimeout      time.Duration
+	RequestTimeout    time.Duration
+	TCPKeepAlive      time.Duration
+	MaxRetryTime      time.Duration
+	RequestBucket     *limiter.RequestBucket
+	PartConcurrency   *hostConcurrency
+	HTTP1             bool
+	HTTP2             bool
+	SyncTime          bool
 	Transport         *http.Transport
+	// Middleware wraps the transport, in order, so that the last one
+	// is outermost. Every request passes through it once per attempt,
+	// after it was signed. Clients with middleware are not cached.
+	Middleware []func(http.RoundTripper) http.RoundTripper
+	// CredsProvider provides credentials which expire instead of the
+	// static keys, when set.
+	CredsProvider credentials.Provider
+}
+
+// signingRegion returns the region requests are signed for, SigningRegion
+// when set and the region of the alias otherwise. Unlike the host, it
+// is the canonical region name the server expects in the credential scope.
+func (c *Config) signingRegion() string {
+	if c.SigningRegion != "" {
+		return c.SigningRegion
+	}
+	return c.Region
 }
 
 // SelectObjectOpts - opts entered for select API
@@ -18,9 +18,11 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -28,6 +30,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/http/httpguts"
@@ -42,18 +45,24 @@ import (
 
 // decode if the key is encoded key and returns the key
 func getDecodedKey(sseKeys string) (key string, err *probe.Error) {
-	keyString := ""
-	for i, sse := range strings.Split(sseKeys, ",") {
-		if i > 0 {
-			keyString = keyString + ","
-		}
+	keys, err := getDecodedKeys(sseKeys)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(keys, ","), nil
+}
+
+// getDecodedKeys returns each key of sseKeys, decoded.
+func getDecodedKeys(sseKeys string) ([]string, *probe.Error) {
+	var keys []string
+	for _, sse := range strings.Split(sseKeys, ",") {
 		sseString, err := parseKey(sse)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		keyString = keyString + sseString
+		keys = append(keys, sseString)
 	}
-	return keyString, nil
+	return keys, nil
 }
 
 // Validate the key
@@ -74,22 +83,102 @@ func parseKey(sseKeys string) (sse string, err *probe.Error) {
 	return encryptString[0] + "=" + string(decodedString), nil
 }
 
-// parse and return encryption key pairs per alias.
-func getEncKeys(ctx *cli.Context) (map[string][]prefixSSEPair, *probe.Error) {
+// readEncryptKeyFile returns the decoded prefix=secret keys of the file
+// at path, one per line, skipping the blank lines and the comments
+// starting with '#'.
+func readEncryptKeyFile(path string) ([]string, *probe.Error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer f.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		// Only the line ending and the indentation are trimmed, the
+		// trailing spaces may belong to a plain text secret.
+		line := strings.TrimLeft(strings.TrimSuffix(scanner.Text(), "\r"), " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := parseKey(line)
+		if err != nil {
+			return nil, probe.NewError(fmt.Errorf("line %d of `%s`: %w", n, path, err.ToGoError()))
+		}
+		keys = append(keys, key)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return keys, nil
+}
+
+// mergeEncKeys returns the decoded keys of keys and fileKeys, the keys
+// of fileKeys replacing those of keys with the same prefix.
+func mergeEncKeys(keys, fileKeys []string) []string {
+	fromFile := make(map[string]string, len(fileKeys))
+	for _, key := range fileKeys {
+		prefix, _, _ := strings.Cut(key, "=")
+		fromFile[prefix] = key
+	}
+	var merged []string
+	for _, key := range keys {
+		prefix, _, _ := strings.Cut(key, "=")
+		if fileKey, ok := fromFile[prefix]; ok {
+			key = fileKey
+			delete(fromFile, prefix)
+		}
+		merged = append(merged, key)
+	}
+	for _, key := range fileKeys {
+		prefix, _, _ := strings.Cut(key, "=")
+		if fromFile[prefix] == key {
+			merged = append(merged, key)
+			delete(fromFile, prefix)
+		}
+	}
+	return merged
+}
+
+// getDecodedEncKeys returns the decoded keys of --encrypt-key merged with
+// those of --encrypt-key-file, which take precedence for the same prefix.
+func getDecodedEncKeys(ctx *cli.Context) (string, *probe.Error) {
 	sseServer := ctx.String("encrypt")
-	var sseKeys string
+	var keys []string
 	if keyPrefix := ctx.String("encrypt-key"); keyPrefix != "" {
 		if sseServer != "" && strings.Contains(keyPrefix, sseServer) {
-			return nil, errConflictSSE(sseServer, keyPrefix).Trace(ctx.Args()...)
+			return "", errConflictSSE(sseServer, keyPrefix).Trace(ctx.Args()...)
+		}
+		var err *probe.Error
+		if keys, err = getDecodedKeys(keyPrefix); err != nil {
+			return "", err.Trace(keyPrefix)
 		}
-		sseKeys = keyPrefix
 	}
-	var err *probe.Error
-	if sseKeys != "" {
-		sseKeys, err = getDecodedKey(sseKeys)
+	if keyFile := ctx.String("encrypt-key-file"); keyFile != "" {
+		fileKeys, err := readEncryptKeyFile(keyFile)
 		if err != nil {
-			return nil, err.Trace(sseKeys)
+			return "", err.Trace(keyFile)
 		}
+		for _, key := range fileKeys {
+			prefix, _, _ := strings.Cut(key, "=")
+			for _, ssePrefix := range strings.Split(sseServer, ",") {
+				if ssePrefix == prefix {
+					return "", errConflictSSE(sseServer, prefix).Trace(ctx.Args()...)
+				}
+			}
+		}
+		keys = mergeEncKeys(keys, fileKeys)
+	}
+	return strings.Join(keys, ","), nil
+}
+
+// parse and return encryption key pairs per alias.
+func getEncKeys(ctx *cli.Context) (map[string][]prefixSSEPair, *probe.Error) {
+	sseServer := ctx.String("encrypt")
+	sseKeys, err := getDecodedEncKeys(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	encKeyDB, err := parseAndValidateEncryptionKeys(sseKeys, sseServer)
@@ -189,6 +278,9 @@ func getSourceStreamFromURL(ctx context.Context, urlStr string, encKeyDB map[str
 
 // Verify if reader is a generic ReaderAt
 func isReadAt(reader io.Reader) (ok bool) {
+	if r, isContextReader := reader.(*contextReaderAt); isContextReader {
+		reader = r.reader
+	}
 	var v *os.File
 	v, ok = reader.(*os.File)
 	if ok {
@@ -206,6 +298,11 @@ func isReadAt(reader io.Reader) (ok bool) {
 				break
 			}
 		}
+		// Named pipes and devices cannot be read at an offset either.
+		if ok {
+			fi, e := v.Stat()
+			ok = e == nil && fi.Mode().IsRegular()
+		}
 	}
 	return
 }
@@ -222,7 +319,83 @@ func getSourceStream(ctx context.Context, alias, urlStr string, opts getSourceOp
 		return nil, nil, err.Trace(alias, urlStr)
 	}
 
-	return reader, content, nil
+	return newContextReader(ctx, reader), content, nil
+}
+
+// contextReader aborts reads as soon as its context is canceled. The
+// underlying reader is closed on cancellation so that a read blocked on
+// a file or a network connection returns right away instead of once the
+// whole in-flight buffer (up to a multipart part) has been transferred.
+type contextReader struct {
+	ctx    context.Context
+	reader io.ReadCloser
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newContextReader(ctx context.Context, reader io.ReadCloser) io.ReadCloser {
+	r := &contextReader{ctx: ctx, reader: reader, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			r.reader.Close()
+		case <-r.done:
+		}
+	}()
+	if readerAt, ok := reader.(io.ReaderAt); ok {
+		if seeker, ok := reader.(io.Seeker); ok {
+			// Keep local files seekable so that uploads still read parts in parallel.
+			return &contextReaderAt{contextReader: r, readerAt: readerAt, seeker: seeker}
+		}
+	}
+	return r
+}
+
+func (r *contextReader) Read(p []byte) (n int, err error) {
+	if e := r.ctx.Err(); e != nil {
+		return 0, e
+	}
+	n, err = r.reader.Read(p)
+	if err != nil && err != io.EOF {
+		if e := r.ctx.Err(); e != nil {
+			// Report the cancellation rather than the error of the closed reader.
+			return n, e
+		}
+	}
+	return n, err
+}
+
+func (r *contextReader) Close() (err error) {
+	r.once.Do(func() {
+		close(r.done)
+		err = r.reader.Close()
+	})
+	return err
+}
+
+// contextReaderAt is a contextReader over a source which is also an
+// io.ReaderAt and an io.Seeker, such as a local file.
+type contextReaderAt struct {
+	*contextReader
+	readerAt io.ReaderAt
+	seeker   io.Seeker
+}
+
+func (r *contextReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if e := r.ctx.Err(); e != nil {
+		return 0, e
+	}
+	n, err = r.readerAt.ReadAt(p, off)
+	if err != nil && err != io.EOF {
+		if e := r.ctx.Err(); e != nil {
+			return n, e
+		}
+	}
+	return n, err
+}
+
+func (r *contextReaderAt) Seek(offset int64, whence int) (int64, error) {
+	return r.seeker.Seek(offset, whence)
 }
 
 // putTargetRetention sets retention headers if any
@@ -252,6 +425,39 @@ func putTargetRetention(ctx context.Context, alias, urlStr string, metadata map[
 	return nil
 }
 
+// uploadResult is the object written by the Put or Copy of a context
+// of withUploadResult, so that its ETag is known without a HEAD.
+type uploadResult struct {
+	mu   sync.Mutex
+	etag string
+}
+
+type uploadResultKey struct{}
+
+// withUploadResult returns a context recording the object written by
+// its Put or Copy.
+func withUploadResult(ctx context.Context) (context.Context, *uploadResult) {
+	r := &uploadResult{}
+	return context.WithValue(ctx, uploadResultKey{}, r), r
+}
+
+// recordUploadResult records ui in the uploadResult of ctx, if any.
+func recordUploadResult(ctx context.Context, ui minio.UploadInfo) {
+	if r, ok := ctx.Value(uploadResultKey{}).(*uploadResult); ok {
+		r.mu.Lock()
+		r.etag = strings.Trim(ui.ETag, "\"")
+		r.mu.Unlock()
+	}
+}
+
+// ETag returns the ETag of the written object, "" when unknown, such as
+// for a local target.
+func (r *uploadResult) ETag() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.etag
+}
+
 // putTargetStream writes to URL from Reader.
 func putTargetStream(ctx context.Context, alias, urlStr, mode, until, legalHold string, reader io.Reader, size int64, progress io.Reader, opts PutOptions) (int64, *probe.Error) {
 	targetClnt, err := newClientFromAlias(alias, urlStr)
@@ -354,12 +560,28 @@ func getAllMetadata(ctx context.Context, sourceAlias, sourceURLStr string, srcSS
 // optionally optimizes copy for object sizes <= 5GiB by using
 // server side copy operation.
 func uploadSourceToTargetURL(ctx context.Context, uploadOpts uploadSourceToTargetURLOpts) URLs {
+	start := time.Now()
+	ctx, result := withUploadResult(ctx)
+	urls := doUploadSourceToTargetURL(ctx, uploadOpts)
+	if urls.Error == nil && urls.TargetContent != nil {
+		// The target is now the uploaded object.
+		urls.TargetContent.ETag = result.ETag()
+	}
+	globalTransferLog.logURLs(urls, start)
+	return urls
+}
+
+// doUploadSourceToTargetURL - uploads to targetURL from source.
+func doUploadSourceToTargetURL(ctx context.Context, uploadOpts uploadSourceToTargetURLOpts) URLs {
 	sourceAlias := uploadOpts.urls.SourceAlias
 	sourceURL := uploadOpts.urls.SourceContent.URL
 	sourceVersion := uploadOpts.urls.SourceContent.VersionID
 	targetAlias := uploadOpts.urls.TargetAlias
 	targetURL := uploadOpts.urls.TargetContent.URL
 	length := uploadOpts.urls.SourceContent.Size
+	if err := checkMaxObjectSize(sourceURL.String(), length, uploadOpts.maxObjectSize); err != nil {
+		return uploadOpts.urls.WithError(err.Trace(sourceURL.String()))
+	}
 	sourcePath := filepath.ToSlash(filepath.Join(sourceAlias, uploadOpts.urls.SourceContent.URL.Path))
 	targetPath := filepath.ToSlash(filepath.Join(targetAlias, uploadOpts.urls.TargetContent.URL.Path))
 
@@ -487,18 +709,30 @@ func uploadSourceToTargetURL(ctx context.Context, uploadOpts uploadSourceToTarge
 			reader  io.ReadCloser
 		)
 
+		// A resumed download to a local file only reads what its kept
+		// temporary file misses.
+		var offset int64
+		if uploadOpts.resume && length > 0 {
+			offset = resumeOffset(targetAlias, targetURL.String(), length)
+		}
+
 		reader, content, err = getSourceStream(ctx, sourceAlias, sourceURL.String(), getSourceOpts{
 			GetOptions: GetOptions{
-				VersionID: sourceVersion,
-				SSE:       srcSSE,
-				Zip:       uploadOpts.isZip,
-				Preserve:  uploadOpts.preserve,
+				VersionID:      sourceVersion,
+				SSE:            srcSSE,
+				Zip:            uploadOpts.isZip,
+				RangeStart:     offset,
+				Preserve:       uploadOpts.preserve,
+				VerifyResponse: uploadOpts.verifyResponse,
 			},
 		})
 		if err != nil {
 			return uploadOpts.urls.WithError(err.Trace(sourceURL.String()))
 		}
 		defer reader.Close()
+		if pg, ok := uploadOpts.progress.(*progressBar); ok && offset > 0 {
+			pg.ProgressBar.Add64(offset)
+		}
 
 		if uploadOpts.updateProgressTotal {
 			pg, ok := uploadOpts.progress.(*progressBar)
@@ -537,6 +771,9 @@ func uploadSourceToTargetURL(ctx context.Context, uploadOpts uploadSourceToTarge
 				return uploadOpts.urls.WithError(probe.NewError(e))
 			}
 		}
+		if multipartSize, err = fitPartSize(sourceURL.String(), length, multipartSize, uploadOpts.autoPartSize); err != nil {
+			return uploadOpts.urls.WithError(err.Trace(sourceURL.String()))
+		}
 
 		if uploadOpts.multipartThreads == "" {
 			multipartThreads, e = strconv.Atoi(env.Get("MC_UPLOAD_MULTIPART_THREADS", "4"))
@@ -547,6 +784,12 @@ func uploadSourceToTargetURL(ctx context.Context, uploadOpts uploadSourceToTarge
 			return uploadOpts.urls.WithError(probe.NewError(e))
 		}
 
+		if uploadOpts.urls.StoreChecksum {
+			if length < 0 {
+				return uploadOpts.urls.WithError(probe.NewError(errors.New("unable to compute the SHA-256 sum of a stream")).Trace(sourceURL.String()))
+			}
+		}
+
 		putOpts := PutOptions{
 			metadata:         filterMetadata(metadata),
 			sse:              tgtSSE,
@@ -556,15 +799,24 @@ func uploadSourceToTargetURL(ctx context.Context, uploadOpts uploadSourceToTarge
 			isPreserve:       uploadOpts.preserve,
 			multipartSize:    multipartSize,
 			multipartThreads: uint(multipartThreads),
+			storeSHA256:      uploadOpts.urls.StoreChecksum,
+			resume:           uploadOpts.resume,
+			resumeOffset:     offset,
 		}
+		length -= offset
 
-		if isReadAt(reader) || length == 0 {
-			_, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
+		var n int64
+		if isReadAt(reader) || length <= 0 {
+			n, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
 				legalHold, reader, length, uploadOpts.progress, putOpts)
 		} else {
-			_, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
+			n, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
 				legalHold, io.LimitReader(reader, length), length, uploadOpts.progress, putOpts)
 		}
+		if err == nil && length < 0 {
+			// A stream is only sized once uploaded.
+			uploadOpts.urls.SourceContent.Size = n
+		}
 	}
 	if err != nil {
 		return uploadOpts.urls.WithError(err.Trace(sourceURL.String()))
@@ -638,4 +890,27 @@ type uploadSourceToTargetURLOpts struct {
 	multipartSize       string
 	multipartThreads    string
 	updateProgressTotal bool
+	verifyResponse      bool
+	maxObjectSize       int64
+	autoPartSize        bool
+	resume              bool
+}
+
+// resumeOffset returns the size of the temporary file kept by the
+// interrupted download of length bytes to a local file at urlStr, or 0
+// if the download cannot be resumed.
+func resumeOffset(alias, urlStr string, length int64) int64 {
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return 0
+	}
+	f, ok := clnt.(*fsClient)
+	if !ok {
+		return 0
+	}
+	partPath, size := resumablePartFile(f.PathURL.Path)
+	if partPath == "" || size >= length {
+		return 0
+	}
+	return size
 }
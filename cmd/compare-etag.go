@@ -0,0 +1,231 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// md5CacheFile is the file, in the config folder, caching the MD5 sums
+// of local files compared with --compare etag.
+const md5CacheFile = "md5-cache.json"
+
+// md5CacheEntry is the MD5 sum of a local file of a size and a
+// modification time.
+type md5CacheEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	MD5     string    `json:"md5"`
+}
+
+// md5Cache caches the MD5 sums of local files by path, an entry is
+// used as long as the size and the modification time of its file are
+// unchanged.
+type md5Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]md5CacheEntry
+	dirty   bool
+}
+
+var (
+	globalMD5Cache     *md5Cache
+	globalMD5CacheOnce sync.Once
+)
+
+// getMD5Cache loads the MD5 cache of the config folder on first use.
+// Loading errors start an empty cache, it is only an optimization.
+func getMD5Cache() *md5Cache {
+	globalMD5CacheOnce.Do(func() {
+		if globalMD5Cache != nil {
+			return
+		}
+		var path string
+		if configDir, err := getMcConfigDir(); err == nil {
+			path = filepath.Join(configDir, md5CacheFile)
+		}
+		globalMD5Cache = loadMD5Cache(path)
+	})
+	return globalMD5Cache
+}
+
+// loadMD5Cache reads the cache at path, an empty path is never saved.
+func loadMD5Cache(path string) *md5Cache {
+	c := &md5Cache{path: path, entries: map[string]md5CacheEntry{}}
+	if path == "" {
+		return c
+	}
+	if data, e := os.ReadFile(path); e == nil {
+		if e = json.Unmarshal(data, &c.entries); e != nil || c.entries == nil {
+			c.entries = map[string]md5CacheEntry{}
+		}
+	}
+	return c
+}
+
+// sum returns the MD5 sum of the local file of content, computing it
+// only if the cache has none for its size and modification time.
+func (c *md5Cache) sum(content *ClientContent) (string, *probe.Error) {
+	path, e := filepath.Abs(content.URL.Path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.Size == content.Size && entry.ModTime.Equal(content.Time) {
+		return entry.MD5, nil
+	}
+
+	f, e := os.Open(path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	defer f.Close()
+	fi, e := f.Stat()
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	h := md5.New()
+	if _, e = io.Copy(h, f); e != nil {
+		return "", probe.NewError(e)
+	}
+	entry = md5CacheEntry{Size: fi.Size(), ModTime: fi.ModTime(), MD5: hex.EncodeToString(h.Sum(nil))}
+
+	c.mu.Lock()
+	c.entries[path] = entry
+	c.dirty = true
+	c.mu.Unlock()
+	return entry.MD5, nil
+}
+
+// save writes the cache if it changed, dropping the entries of files
+// which were removed or changed since.
+func (c *md5Cache) save() *probe.Error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+	for path, entry := range c.entries {
+		fi, e := os.Stat(path)
+		if e != nil || fi.Size() != entry.Size || !fi.ModTime().Equal(entry.ModTime) {
+			delete(c.entries, path)
+		}
+	}
+	data, e := json.Marshal(c.entries)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if err := writeFileAtomic(c.path, data); err != nil {
+		return err.Trace(c.path)
+	}
+	c.dirty = false
+	return nil
+}
+
+// etagCompareStats counts the objects --compare etag skipped because
+// their MD5 sum matched, and by their size only when it could not
+// tell, such as for multipart ETags.
+type etagCompareStats struct {
+	identical int64
+	sameSize  int64
+}
+
+var globalETagCompareStats etagCompareStats
+
+// etagDiffer compares a source and a target of the same size by the
+// MD5 sum of the source, cached for local files, and the ETag of the
+// target.
+func etagDiffer(src, dst *ClientContent) differType {
+	dstSum, ok := contentMD5(dst)
+	if !ok {
+		return sameSizeOnly(dst)
+	}
+	var srcSum string
+	if src.URL.Type == fileSystem {
+		sum, err := getMD5Cache().sum(src)
+		if err != nil {
+			// Uploading it reports the error.
+			return differInChecksum
+		}
+		srcSum = sum
+	} else if srcSum, ok = contentMD5(src); !ok {
+		return sameSizeOnly(src)
+	}
+	if srcSum != dstSum {
+		return differInChecksum
+	}
+	atomic.AddInt64(&globalETagCompareStats.identical, 1)
+	return differInNone
+}
+
+// sameSizeOnly counts an object compared by size only, because the
+// ETag of content is not an MD5 sum.
+func sameSizeOnly(content *ClientContent) differType {
+	if globalDebug {
+		console.Debugln(fmt.Sprintf("ETag %s of `%s` is not an MD5 sum, comparing by size only", content.ETag, content.URL))
+	}
+	atomic.AddInt64(&globalETagCompareStats.sameSize, 1)
+	return differInNone
+}
+
+// etagCompareMessage reports the objects skipped by --compare etag.
+type etagCompareMessage struct {
+	Status            string `json:"status"`
+	SkippedIdentical  int64  `json:"skippedIdentical"`
+	SkippedBySameSize int64  `json:"skippedBySameSize"`
+}
+
+func newETagCompareMessage() etagCompareMessage {
+	return etagCompareMessage{
+		SkippedIdentical:  atomic.LoadInt64(&globalETagCompareStats.identical),
+		SkippedBySameSize: atomic.LoadInt64(&globalETagCompareStats.sameSize),
+	}
+}
+
+func (m etagCompareMessage) String() string {
+	msg := fmt.Sprintf("Skipped %d identical object(s) with a matching ETag", m.SkippedIdentical)
+	if m.SkippedBySameSize > 0 {
+		msg += fmt.Sprintf(" and %d object(s) of the same size without an MD5 ETag", m.SkippedBySameSize)
+	}
+	return msg + "."
+}
+
+func (m etagCompareMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// compatFlag prints the listed objects like another tool, for the
+// scripts written against the output of that tool.
+var compatFlag = cli.StringFlag{
+	Name:  "compat",
+	Usage: "print the listing like 'aws s3 ls' (aws) or 'rclone lsjson' (rclone)",
+}
+
+var errCompatOutput = errors.New("--compat cannot be used with --csv, --tsv, --json or --format")
+
+// compatItem is what the formatters of --compat print of a listed
+// object or folder.
+type compatItem struct {
+	key     string
+	isDir   bool
+	size    int64
+	modTime time.Time
+}
+
+// compatItemOf returns the item of a record, false for records which
+// are not listed objects.
+func compatItemOf(record recordMessage) (compatItem, bool) {
+	var c contentMessage
+	switch record := record.(type) {
+	case contentMessage:
+		c = record
+	case findMessage:
+		c = record.contentMessage
+	default:
+		return compatItem{}, false
+	}
+	return compatItem{
+		key:     c.Key,
+		isDir:   c.Filetype == "folder",
+		size:    c.Size,
+		modTime: c.Time,
+	}, true
+}
+
+// compatFormatter formats the items of a listing, open and close
+// return what is printed before the first item and after the last.
+type compatFormatter interface {
+	open() string
+	item(item compatItem, first bool) string
+	close(empty bool) string
+}
+
+// awsFormatter prints a line per item like 'aws s3 ls', the local
+// time, the size right aligned on ten columns and the key. Folders
+// are printed as "PRE" followed by their name.
+type awsFormatter struct{}
+
+func (awsFormatter) open() string { return "" }
+
+func (awsFormatter) item(item compatItem, _ bool) string {
+	if item.isDir {
+		return fmt.Sprintf("%30s %s\n", "PRE", item.key)
+	}
+	return fmt.Sprintf("%s %10d %s\n", item.modTime.Format("2006-01-02 15:04:05"), item.size, item.key)
+}
+
+func (awsFormatter) close(bool) string { return "" }
+
+// rcloneItem is an entry of 'rclone lsjson'.
+type rcloneItem struct {
+	Path     string
+	Name     string
+	Size     int64
+	MimeType string
+	ModTime  string
+	IsDir    bool
+}
+
+// rcloneFormatter prints a JSON array like 'rclone lsjson', with an
+// entry per line so that the array can be read as it is printed.
+// Folders have a size of -1 like in rclone.
+type rcloneFormatter struct{}
+
+func (rcloneFormatter) open() string { return "[\n" }
+
+func (rcloneFormatter) item(item compatItem, first bool) string {
+	entry := rcloneItem{
+		Path:     strings.TrimSuffix(item.key, "/"),
+		Size:     item.size,
+		MimeType: "inode/directory",
+		ModTime:  item.modTime.Format(time.RFC3339Nano),
+		IsDir:    item.isDir,
+	}
+	entry.Name = path.Base(entry.Path)
+	if !item.isDir {
+		entry.MimeType = mime.TypeByExtension(path.Ext(entry.Name))
+		if entry.MimeType == "" {
+			entry.MimeType = "application/octet-stream"
+		}
+	} else {
+		entry.Size = -1
+	}
+	b, e := json.Marshal(entry)
+	if e != nil {
+		return ""
+	}
+	if first {
+		return string(b)
+	}
+	return ",\n" + string(b)
+}
+
+func (rcloneFormatter) close(empty bool) string {
+	if empty {
+		return "]\n"
+	}
+	return "\n]\n"
+}
+
+// compatOutput writes the records of a listing with the formatter of
+// --compat.
+type compatOutput struct {
+	mu        sync.Mutex
+	w         io.Writer
+	formatter compatFormatter
+	opened    bool
+	empty     bool
+}
+
+// newCompatOutput returns the output of --compat name.
+func newCompatOutput(w io.Writer, name string) (*compatOutput, error) {
+	var formatter compatFormatter
+	switch name {
+	case "aws":
+		formatter = awsFormatter{}
+	case "rclone":
+		formatter = rcloneFormatter{}
+	default:
+		return nil, fmt.Errorf("--compat should be aws or rclone, not `%s`", name)
+	}
+	return &compatOutput{w: w, formatter: formatter, empty: true}, nil
+}
+
+// write writes the item of record, false for records which are not
+// listed objects.
+func (o *compatOutput) write(record recordMessage) (bool, error) {
+	item, ok := compatItemOf(record)
+	if !ok {
+		return false, nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := o.formatter.item(item, o.empty)
+	if !o.opened {
+		s = o.formatter.open() + s
+		o.opened = true
+	}
+	o.empty = false
+	_, e := io.WriteString(o.w, s)
+	return true, e
+}
+
+// close writes the end of the listing, once all records are written.
+func (o *compatOutput) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := o.formatter.close(o.empty)
+	if !o.opened {
+		s = o.formatter.open() + s
+	}
+	o.opened, o.empty = false, true
+	_, e := io.WriteString(o.w, s)
+	return e
+}
+
+// closeCompatOutput ends the listing printed with --compat, if set.
+func closeCompatOutput() {
+	if globalCompat != nil {
+		fatalIf(probe.NewError(globalCompat.close()), "Unable to write the output.")
+	}
+}
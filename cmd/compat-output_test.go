@@ -0,0 +1,108 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func compatTestRecords() []recordMessage {
+	modTime := time.Date(2024, 11, 5, 10, 30, 0, 0, time.UTC)
+	return []recordMessage{
+		contentMessage{Filetype: "folder", Key: "photos/"},
+		contentMessage{Filetype: "file", Time: modTime, Size: 12345, Key: "notes.txt"},
+		findMessage{contentMessage{Filetype: "file", Time: modTime.Add(90 * time.Minute), Size: 1 << 40, Key: "data/disk"}},
+		contentMessage{Filetype: "file", Time: modTime, Key: "empty"},
+		// Not a listed object, left to the caller.
+		duMessage{Prefix: "photos/", Size: 10},
+	}
+}
+
+func TestCompatOutput(t *testing.T) {
+	for _, testCase := range []struct {
+		compat   string
+		expected string
+	}{
+		{"aws", "" +
+			"                           PRE photos/\n" +
+			"2024-11-05 10:30:00      12345 notes.txt\n" +
+			"2024-11-05 12:00:00 1099511627776 data/disk\n" +
+			"2024-11-05 10:30:00          0 empty\n"},
+		{"rclone", "" +
+			"[\n" +
+			`{"Path":"photos","Name":"photos","Size":-1,"MimeType":"inode/directory","ModTime":"0001-01-01T00:00:00Z","IsDir":true},` + "\n" +
+			`{"Path":"notes.txt","Name":"notes.txt","Size":12345,"MimeType":"text/plain; charset=utf-8","ModTime":"2024-11-05T10:30:00Z","IsDir":false},` + "\n" +
+			`{"Path":"data/disk","Name":"disk","Size":1099511627776,"MimeType":"application/octet-stream","ModTime":"2024-11-05T12:00:00Z","IsDir":false},` + "\n" +
+			`{"Path":"empty","Name":"empty","Size":0,"MimeType":"application/octet-stream","ModTime":"2024-11-05T10:30:00Z","IsDir":false}` + "\n" +
+			"]\n"},
+	} {
+		var buf bytes.Buffer
+		out, e := newCompatOutput(&buf, testCase.compat)
+		if e != nil {
+			t.Fatal(e)
+		}
+		var skipped int
+		for _, record := range compatTestRecords() {
+			written, e := out.write(record)
+			if e != nil {
+				t.Fatal(e)
+			}
+			if !written {
+				skipped++
+			}
+		}
+		if e = out.close(); e != nil {
+			t.Fatal(e)
+		}
+		if skipped != 1 {
+			t.Fatalf("%s: expected the du record to be skipped, %d skipped", testCase.compat, skipped)
+		}
+		if buf.String() != testCase.expected {
+			t.Fatalf("%s: expected\n%s\ngot\n%s", testCase.compat, testCase.expected, buf.String())
+		}
+	}
+}
+
+func TestCompatOutputEmpty(t *testing.T) {
+	for compat, expected := range map[string]string{"aws": "", "rclone": "[\n]\n"} {
+		var buf bytes.Buffer
+		out, e := newCompatOutput(&buf, compat)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if e = out.close(); e != nil {
+			t.Fatal(e)
+		}
+		if buf.String() != expected {
+			t.Fatalf("%s: expected %q, got %q", compat, expected, buf.String())
+		}
+		if compat == "rclone" {
+			var entries []rcloneItem
+			if e = json.Unmarshal(buf.Bytes(), &entries); e != nil || len(entries) != 0 {
+				t.Fatalf("rclone: expected an empty array, got %v, %v", entries, e)
+			}
+		}
+	}
+
+	if _, e := newCompatOutput(nil, "gsutil"); e == nil {
+		t.Fatal("expected an error for an unknown --compat")
+	}
+}
@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"sync"
+	"time"
 
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/quick"
@@ -46,6 +47,17 @@ type aliasConfigV10 struct {
 	Path         string `json:"path"`
 	License      string `json:"license,omitempty"`
 	APIKey       string `json:"apiKey,omitempty"`
+
+	// SessionExpiry is when SessionToken expires, it is only
+	// known for the gpumall alias and never saved.
+	SessionExpiry time.Time `json:"-"`
+
+	// Region is only set by --credentials-file and never saved.
+	Region string `json:"-"`
+
+	// SigningRegion overrides Region in the credential scope of signed
+	// requests, it is only set for gpumall and never saved.
+	SigningRegion string `json:"-"`
 }
 
 // configV10 config version.
@@ -0,0 +1,79 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// confirmListLimit bounds the listing done to summarize a removal, the
+// summary then gives a lower bound.
+const confirmListLimit = 100000
+
+// canConfirm returns true if the user can be asked to confirm a
+// destructive operation, scripts have to pass --force instead.
+func canConfirm() bool {
+	return isTerminal() && !globalQuiet && !globalJSON
+}
+
+// summarizeObjects counts the objects under url and their total size.
+func summarizeObjects(ctx context.Context, url string, withVersions bool) (string, *probe.Error) {
+	clnt, err := newClient(url)
+	if err != nil {
+		return "", err.Trace(url)
+	}
+	var count, size int64
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, WithOlderVersions: withVersions, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return "", content.Err.Trace(url)
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		count++
+		size += content.Size
+		if count == confirmListLimit {
+			return fmt.Sprintf("more than %d object(s) of at least %s", count, formatSize(size)), nil
+		}
+	}
+	return fmt.Sprintf("%d object(s) of %s", count, formatSize(size)), nil
+}
+
+// confirmTarget prints summary and returns true if the user then types
+// target, answering "y" is not enough.
+func confirmTarget(in io.Reader, out io.Writer, summary, target string) bool {
+	fmt.Fprintln(out, summary)
+	fmt.Fprintf(out, "This operation is *IRREVERSIBLE*. Type `%s` to continue: ", target)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(answer) == target
+}
+
+// mustConfirmTarget asks the user to confirm a destructive operation on
+// target described by summary, it exits if the user does not.
+func mustConfirmTarget(summary, target string) {
+	if !confirmTarget(os.Stdin, statusOutput, summary, target) {
+		fatalIf(errDummy().Trace(target), "Aborted, the answer does not match `"+target+"`.")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfirmTarget(t *testing.T) {
+	for answer, expected := range map[string]bool{
+		"play/bucket/prefix\n":    true,
+		"  play/bucket/prefix \n": true,
+		"y\n":                     false,
+		"yes\n":                   false,
+		"play/bucket\n":           false,
+		"":                        false,
+	} {
+		var out bytes.Buffer
+		if got := confirmTarget(strings.NewReader(answer), &out, "About to remove 2 object(s).", "play/bucket/prefix"); got != expected {
+			t.Errorf("%q: expected %v, got %v", answer, expected, got)
+		}
+		if !strings.Contains(out.String(), "About to remove 2 object(s).") || !strings.Contains(out.String(), "Type `play/bucket/prefix`") {
+			t.Fatalf("unexpected prompt %q", out.String())
+		}
+	}
+}
+
+func TestCanConfirm(t *testing.T) {
+	defer func(f func() bool, quiet, json bool) {
+		isTerminal, globalQuiet, globalJSON = f, quiet, json
+	}(isTerminal, globalQuiet, globalJSON)
+
+	isTerminal = func() bool { return false }
+	globalQuiet, globalJSON = false, false
+	if canConfirm() {
+		t.Fatal("expected no confirmation without a terminal")
+	}
+	isTerminal = func() bool { return true }
+	if !canConfirm() {
+		t.Fatal("expected a confirmation on a terminal")
+	}
+	globalJSON = true
+	if canConfirm() {
+		t.Fatal("expected no confirmation with --json")
+	}
+}
+
+func TestSummarizeObjects(t *testing.T) {
+	initTestConfig(t)
+	dir := t.TempDir()
+	for name, data := range map[string]string{"a": "1234", "sub/b": "123456"} {
+		path := filepath.Join(dir, name)
+		if e := os.MkdirAll(filepath.Dir(path), 0o700); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(data), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	summary, err := summarizeObjects(context.Background(), dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != "2 object(s) of 10 B" {
+		t.Fatalf("unexpected summary %q", summary)
+	}
+}
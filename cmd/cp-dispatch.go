@@ -0,0 +1,167 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// cp command, described in the help of put and get.
+var cpCmd = cli.Command{
+	Name:            "cp",
+	Usage:           "copy objects, runs 'put' or 'get' depending on the direction",
+	Action:          mainCp,
+	OnUsageError:    onUsageError,
+	SkipFlagParsing: true,
+	Hidden:          true,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] SOURCE [SOURCE...] TARGET
+
+DESCRIPTION:
+  A path is remote when it starts with 'gpumall/' or with the name of an
+  alias set with 'mc alias set', paths starting with 'gpumall/' are
+  relative to your gpumall directory. The aliases mc comes with, such as
+  'play' and 's3', are only used once they are set with 'mc alias set'.
+
+  Local sources and a remote target run 'put', remote sources and a local
+  target run 'get', remote sources and a remote target copy on the server.
+  FLAGS are those of the command which runs.
+
+EXAMPLES:
+  1. Upload a local file to your gpumall directory, same as 'put file.txt data/'
+    {{.Prompt}} {{.HelpName}} file.txt gpumall/data/
+  2. Download an object of the alias 'myminio', same as 'get'
+    {{.Prompt}} {{.HelpName}} myminio/mybucket/object.txt ./
+`,
+}
+
+// errLocalCopy is returned by cp when neither side is remote.
+var errLocalCopy = errors.New("neither the source nor the target is remote, start remote paths with 'gpumall/' or the name of an alias set with 'mc alias set'")
+
+// isRemotePath returns true if path starts with gpumall or an alias.
+func isRemotePath(path string) bool {
+	alias, _, found := strings.Cut(path, "/")
+	return (found && alias == AuthAlias) || pathAlias(path) != ""
+}
+
+// gpumallRelativePath returns the path of a 'gpumall/' path relative to
+// the gpumall directory, as put expects it, other paths are returned as is.
+func gpumallRelativePath(path string) string {
+	if alias, rest, found := strings.Cut(path, "/"); found && alias == AuthAlias {
+		return "/" + rest
+	}
+	return path
+}
+
+// positionalArgs returns the indices of the arguments of args which are
+// neither flags nor flag values, given the flags args may hold.
+func positionalArgs(args []string, flags []cli.Flag) []int {
+	set := flag.NewFlagSet("", flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	for _, f := range flags {
+		if set.Lookup(strings.TrimSpace(strings.Split(f.GetName(), ",")[0])) == nil {
+			f.Apply(set)
+		}
+	}
+
+	var positional []int
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			for i++; i < len(args); i++ {
+				positional = append(positional, i)
+			}
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			positional = append(positional, i)
+		case !strings.Contains(arg, "="):
+			f := set.Lookup(strings.TrimLeft(arg, "-"))
+			if f == nil {
+				continue
+			}
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				i++ // skip the value of the flag.
+			}
+		}
+	}
+	return positional
+}
+
+// mainCp runs put, get or the server side copy for the direction of the
+// copy, rewriting 'gpumall/' paths for the command which runs.
+func mainCp(cliCtx *cli.Context) error {
+	args := append([]string{}, cliCtx.Args()...)
+	for _, arg := range args {
+		if arg == "-h" || arg == "--help" {
+			showCommandHelpAndExit(cliCtx, 0)
+		}
+	}
+	var allFlags []cli.Flag
+	for _, c := range []cli.Command{putCmd, getCmd, copyCmd} {
+		allFlags = append(allFlags, c.Flags...)
+	}
+	positional := positionalArgs(args, allFlags)
+	if len(positional) < 2 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	sources, target := positional[:len(positional)-1], positional[len(positional)-1]
+
+	remoteSource := false
+	for _, i := range sources {
+		remoteSource = remoteSource || isRemotePath(args[i])
+	}
+	remoteTarget := isRemotePath(args[target])
+
+	var cmd cli.Command
+	switch {
+	case !remoteSource && !remoteTarget:
+		fatalIf(probe.NewError(errLocalCopy).Trace(args...), "Unable to copy, use the cp of your system for local files.")
+	case remoteSource && remoteTarget:
+		cmd = copyCmd
+		for _, i := range positional {
+			if args[i] != gpumallRelativePath(args[i]) {
+				args[i] = getFullPath(gpumallRelativePath(args[i]))
+			}
+		}
+	case remoteTarget:
+		cmd = putCmd
+		args[target] = gpumallRelativePath(args[target])
+	default:
+		cmd = getCmd
+		for _, i := range sources {
+			if args[i] != gpumallRelativePath(args[i]) {
+				args[i] = getFullPath(gpumallRelativePath(args[i]))
+			}
+		}
+	}
+
+	set := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	if e := set.Parse(append([]string{cmd.Name}, args...)); e != nil {
+		return e
+	}
+	return cmd.Run(cli.NewContext(cliCtx.App, set, cliCtx.Parent()))
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/cli"
+)
+
+func TestExpandCommandPrefix(t *testing.T) {
+	flags := append(mcFlags, globalFlags...)
+	for _, testCase := range []struct {
+		args     []string
+		expected []string
+		errText  string
+	}{
+		{args: []string{"mc", "mirr", "a", "b"}, expected: []string{"mc", "mirror", "a", "b"}},
+		{args: []string{"mc", "--json", "--max-host-conns", "4", "mirr", "a"}, expected: []string{"mc", "--json", "--max-host-conns", "4", "mirror", "a"}},
+		{args: []string{"mc", "cp", "a", "gpumall/b"}, expected: []string{"mc", "cp", "a", "gpumall/b"}},
+		{args: []string{"mc", "nosuchcommand"}, expected: []string{"mc", "nosuchcommand"}},
+		{args: []string{"mc", "--version"}, expected: []string{"mc", "--version"}},
+		{args: []string{"mc", "ca"}, expected: []string{"mc", "cat"}},
+		{args: []string{"mc", "m"}, errText: "ambiguous command `m`, did you mean one of `mb`, `mirror`, `mv`?"},
+	} {
+		got, e := expandCommandPrefix(testCase.args, appCmds, flags)
+		if testCase.errText != "" {
+			if e == nil || e.Error() != testCase.errText {
+				t.Fatalf("%v: expected error %q, got %v", testCase.args, testCase.errText, e)
+			}
+			continue
+		}
+		if e != nil || !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%v: expected %v, got %v, %v", testCase.args, testCase.expected, got, e)
+		}
+	}
+}
+
+func TestCpDirection(t *testing.T) {
+	initTestConfig(t)
+	mcCfg, err := loadMcConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcCfg.Aliases["minio1"] = aliasConfigV10{URL: "https://minio1.invalid", API: "S3v4"}
+
+	args := []string{"--recursive", "-P", "4", "--encrypt-key", "minio1/=key", "dir/", "gpumall/data/"}
+	if got := positionalArgs(args, putCmd.Flags); !reflect.DeepEqual(got, []int{5, 6}) {
+		t.Fatalf("unexpected positional args %v", got)
+	}
+	for path, remote := range map[string]bool{
+		"dir/":          false,
+		"gpumall":       false,
+		"gpumall/data/": true,
+		"minio1/bucket": true,
+		"play/bucket":   false,
+		"s3/bucket":     false,
+		"unknown/x":     false,
+	} {
+		if isRemotePath(path) != remote {
+			t.Errorf("%s: expected remote %v", path, remote)
+		}
+	}
+	if got := gpumallRelativePath("gpumall/data/a.txt"); got != "/data/a.txt" {
+		t.Fatalf("unexpected gpumall path %s", got)
+	}
+	if !strings.Contains(errLocalCopy.Error(), "mc alias set") {
+		t.Fatalf("expected the local copy error to hint at aliases, got %v", errLocalCopy)
+	}
+}
+
+// Only cpCmd answers to cp, whatever the order of appCmds.
+func TestCpCommand(t *testing.T) {
+	var names []string
+	for _, cmd := range appCmds {
+		for _, name := range cmd.Names() {
+			if name == "cp" {
+				names = append(names, cmd.Name)
+			}
+		}
+	}
+	if len(names) != 1 || names[0] != cpCmd.Name {
+		t.Fatalf("expected only the cp command to be named cp, got %v", names)
+	}
+	if cmd := (&cli.App{Commands: appCmds}).Command("cp"); cmd == nil || cmd.Usage != cpCmd.Usage {
+		t.Fatalf("expected cp to run the cp command, got %v", cmd)
+	}
+}
@@ -27,7 +27,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/minio/cli"
@@ -103,6 +102,8 @@ var (
 			Name:  "zip",
 			Usage: "Extract from remote zip file (MinIO server source only)",
 		},
+		maxObjectSizeFlag,
+		storeChecksumFlag,
 	}
 )
 
@@ -115,8 +116,8 @@ var (
 // ErrInvalidMetadata reflects invalid metadata format
 var ErrInvalidMetadata = errors.New("specified metadata should be of form key1=value1;key2=value2;... and so on")
 
-// Copy command.
-var cpCmd = cli.Command{
+// Copy command, run by 'cp' when both the sources and the target are remote.
+var copyCmd = cli.Command{
 	Name:         "cp",
 	Usage:        "copy objects",
 	Action:       mainCopy,
@@ -135,6 +136,7 @@ FLAGS:
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT:      list of comma delimited prefixes
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 EXAMPLES:
   01. Copy a list of objects from local file system to Amazon S3 cloud storage.
@@ -198,6 +200,9 @@ EXAMPLES:
   20. Set tags to the uploaded objects
       {{.Prompt}} {{.HelpName}} -r --tags "category=prod&type=backup" ./data/ play/another-bucket/
 
+  21. Store the SHA-256 sums of the uploaded files in the metadata of their objects
+      {{.Prompt}} {{.HelpName}} -r --store-checksum ./data/ play/another-bucket/
+
 `,
 }
 
@@ -255,7 +260,7 @@ func doCopy(ctx context.Context, copyOpts doCopyOpts) URLs {
 
 	if progressReader, ok := copyOpts.pg.(*progressBar); ok {
 		progressReader.SetCaption(copyOpts.cpURLs.SourceContent.URL.String() + ":")
-	} else {
+	} else if !copyOpts.isSummaryOnly {
 		targetPath := filepath.ToSlash(filepath.Join(targetAlias, targetURL.Path))
 		printMsg(copyMessage{
 			Source:     sourcePath,
@@ -266,6 +271,7 @@ func doCopy(ctx context.Context, copyOpts doCopyOpts) URLs {
 		})
 	}
 
+	ctx, recorder := withRequestIDRecorder(ctx, isObjectWrite)
 	urls := uploadSourceToTargetURL(ctx, uploadSourceToTargetURLOpts{
 		urls:                copyOpts.cpURLs,
 		progress:            copyOpts.pg,
@@ -275,7 +281,15 @@ func doCopy(ctx context.Context, copyOpts doCopyOpts) URLs {
 		multipartSize:       copyOpts.multipartSize,
 		multipartThreads:    copyOpts.multipartThreads,
 		updateProgressTotal: copyOpts.updateProgressTotal,
+		verifyResponse:      copyOpts.verifyResponse,
+		maxObjectSize:       copyOpts.maxObjectSize,
+		autoPartSize:        copyOpts.autoPartSize,
+		resume:              copyOpts.resume,
 	})
+	if requestID, hostID := recorder.get(); urls.Error == nil && globalDebug && requestID != "" {
+		console.Debugln(fmt.Sprintf("Copied `%s` to `%s` requestID=%s hostID=%s", sourcePath,
+			filepath.ToSlash(filepath.Join(targetAlias, targetURL.Path)), requestID, hostID))
+	}
 	if copyOpts.isMvCmd && urls.Error == nil {
 		rmManager.add(ctx, sourceAlias, sourceURL.String())
 	}
@@ -283,6 +297,22 @@ func doCopy(ctx context.Context, copyOpts doCopyOpts) URLs {
 	return urls
 }
 
+// doMoveDryRun prints the move of cpURLs, without copying or removing
+// anything.
+func doMoveDryRun(cpURLs URLs, pg ProgressReader) URLs {
+	if _, ok := pg.(*progressBar); ok && !globalQuiet && !globalJSON {
+		eraseStatusLine()
+	}
+	printMsg(copyMessage{
+		Source:     filepath.ToSlash(filepath.Join(cpURLs.SourceAlias, cpURLs.SourceContent.URL.Path)),
+		Target:     filepath.ToSlash(filepath.Join(cpURLs.TargetAlias, cpURLs.TargetContent.URL.Path)),
+		Size:       cpURLs.SourceContent.Size,
+		TotalCount: cpURLs.TotalCount,
+		TotalSize:  cpURLs.TotalSize,
+	})
+	return doCopyFake(cpURLs, pg)
+}
+
 // doCopyFake - Perform a fake copy to update the progress bar appropriately.
 func doCopyFake(cpURLs URLs, pg Progress) URLs {
 	if progressReader, ok := pg.(*progressBar); ok {
@@ -363,7 +393,7 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 			cancelCopy()
 			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
 			if !globalQuiet && !globalJSON {
-				console.Eraseline()
+				eraseStatusLine()
 			}
 			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
 			os.Exit(0)
@@ -380,7 +410,7 @@ func printCopyURLsError(cpURLs *URLs) {
 	// Print in new line and adjust to top so that we
 	// don't print over the ongoing scan bar
 	if !globalQuiet && !globalJSON {
-		console.Eraseline()
+		eraseStatusLine()
 	}
 
 	if strings.Contains(cpURLs.Error.ToGoError().Error(),
@@ -405,7 +435,7 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 
 	// Enable progress bar reader only during default mode.
 	if !globalQuiet && !globalJSON { // set up progress bar
-		pg = newProgressBar(totalBytes)
+		pg = newProgressBar(totalBytes, statusOutput)
 	} else {
 		pg = newAccounter(totalBytes)
 	}
@@ -545,6 +575,7 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 
 				preserve := cli.Bool("preserve")
 				isZip := cli.Bool("zip")
+				maxObjectSize := parseMaxObjectSize(cli)
 				if cli.String("attr") != "" {
 					userMetaMap, _ := getMetaDataEntry(cli.String("attr"))
 					for metadataKey, metaDataVal := range userMetaMap {
@@ -553,6 +584,7 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 				}
 
 				cpURLs.MD5 = cli.Bool("md5") || withLock
+				cpURLs.StoreChecksum = cli.Bool("store-checksum")
 				cpURLs.DisableMultipart = cli.Bool("disable-multipart")
 
 				// Verify if previously copied, notify progress bar.
@@ -560,24 +592,30 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 					parallel.queueTask(func() URLs {
 						return doCopyFake(cpURLs, pg)
 					}, 0)
+				} else if isMvCmd && cli.Bool("dry-run") {
+					parallel.queueTask(func() URLs {
+						return doMoveDryRun(cpURLs, pg)
+					}, 0)
 				} else {
 					// Print the copy resume summary once in start
 					if startContinue && cli.Bool("continue") {
 						if pb, ok := pg.(*progressBar); ok {
-							startSize := humanize.IBytes(uint64(pb.Start().Get()))
-							totalSize := humanize.IBytes(uint64(pb.Total))
-							console.Println("Resuming copy from ", startSize, " / ", totalSize)
+							startSize := formatSize(pb.Start().Get())
+							totalSize := formatSize(pb.Total)
+							fmt.Fprintln(statusOutput, "Resuming copy from ", startSize, " / ", totalSize)
 						}
 						startContinue = false
 					}
 					parallel.queueTask(func() URLs {
 						return doCopy(ctx, doCopyOpts{
-							cpURLs:   cpURLs,
-							pg:       pg,
-							encKeyDB: encKeyDB,
-							isMvCmd:  isMvCmd,
-							preserve: preserve,
-							isZip:    isZip,
+							cpURLs:        cpURLs,
+							pg:            pg,
+							encKeyDB:      encKeyDB,
+							isMvCmd:       isMvCmd,
+							preserve:      preserve,
+							isZip:         isZip,
+							maxObjectSize: maxObjectSize,
+							resume:        session != nil,
 						})
 					}, cpURLs.SourceContent.Size)
 				}
@@ -587,16 +625,21 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 
 	var retErr error
 	cpAllFilesErr := true
+	// diskFull is set once a download ran out of space, the copies in
+	// progress are canceled and no other copy is started.
+	diskFull := false
 
 loop:
 	for {
 		select {
 		case <-globalContext.Done():
-			close(quitCh)
+			if !diskFull {
+				close(quitCh)
+			}
 			cancelCopy()
 			// Receive interrupt notification.
 			if !globalQuiet && !globalJSON {
-				console.Eraseline()
+				eraseStatusLine()
 			}
 			if session != nil {
 				session.CloseAndDie()
@@ -607,6 +650,10 @@ loop:
 			if !ok {
 				break loop
 			}
+			if diskFull {
+				// Drain the copies canceled after the disk filled up.
+				continue loop
+			}
 			if cpURLs.Error == nil {
 				if session != nil {
 					session.Header.LastCopied = cpURLs.SourceContent.URL.String()
@@ -621,7 +668,7 @@ loop:
 				// Print in new line and adjust to top so that we
 				// don't print over the ongoing progress bar.
 				if !globalQuiet && !globalJSON {
-					console.Eraseline()
+					eraseStatusLine()
 				}
 				errorIf(cpURLs.Error.Trace(cpURLs.SourceContent.URL.String()),
 					fmt.Sprintf("Failed to copy `%s`.", cpURLs.SourceContent.URL.String()))
@@ -631,6 +678,12 @@ loop:
 				}
 
 				errSeen = true
+				if _, ok := cpURLs.Error.ToGoError().(DiskFull); ok && session == nil {
+					// The next downloads would fail as well.
+					diskFull = true
+					close(quitCh)
+					cancelCopy()
+				}
 				if progressReader, pgok := pg.(*progressBar); pgok {
 					if progressReader.ProgressBar.Get() > 0 {
 						writeContSize := (int)(cpURLs.SourceContent.Size)
@@ -657,7 +710,7 @@ loop:
 		if errSeen || (cpAllFilesErr && totalObjects > 0) {
 			// We only erase a line if we are displaying a progress bar
 			if !globalQuiet && !globalJSON {
-				console.Eraseline()
+				eraseStatusLine()
 			}
 		} else if progressReader.ProgressBar.Get() > 0 {
 			progressReader.Finish()
@@ -667,7 +720,7 @@ loop:
 			if errSeen || (cpAllFilesErr && totalObjects > 0) {
 				// We only erase a line if we are displaying a progress bar
 				if !globalQuiet && !globalJSON {
-					console.Eraseline()
+					eraseStatusLine()
 				}
 			} else {
 				printMsg(accntReader.Stat())
@@ -701,6 +754,7 @@ func mainCopy(cliCtx *cli.Context) error {
 
 	// check 'copy' cli arguments.
 	checkCopySyntax(cliCtx)
+	parseMaxObjectSize(cliCtx)
 	// Additional command specific theme customization.
 	console.SetColor("Copy", color.New(color.FgGreen, color.Bold))
 
@@ -714,15 +768,8 @@ func mainCopy(cliCtx *cli.Context) error {
 	retentionDuration := cliCtx.String(rdFlag)
 	legalHold := strings.ToUpper(cliCtx.String(lhFlag))
 	tags := cliCtx.String("tags")
-	sseKeys := os.Getenv("MC_ENCRYPT_KEY")
-	if key := cliCtx.String("encrypt-key"); key != "" {
-		sseKeys = key
-	}
-
-	if sseKeys != "" {
-		sseKeys, err = getDecodedKey(sseKeys)
-		fatalIf(err, "Unable to parse encryption keys.")
-	}
+	sseKeys, err := getDecodedEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
 	sse := cliCtx.String("encrypt")
 
 	var session *sessionV8
@@ -754,6 +801,7 @@ func mainCopy(cliCtx *cli.Context) error {
 			}
 			session.Header.UserMetaData = userMetaMap
 			session.Header.CommandBoolFlags["md5"] = cliCtx.Bool("md5")
+			session.Header.CommandBoolFlags["store-checksum"] = cliCtx.Bool("store-checksum")
 			session.Header.CommandBoolFlags["disable-multipart"] = cliCtx.Bool("disable-multipart")
 
 			var e error
@@ -781,6 +829,11 @@ type doCopyOpts struct {
 	encKeyDB                 map[string][]prefixSSEPair
 	isMvCmd, preserve, isZip bool
 	updateProgressTotal      bool
+	isSummaryOnly            bool
+	verifyResponse           bool
 	multipartSize            string
 	multipartThreads         string
+	maxObjectSize            int64
+	autoPartSize             bool
+	resume                   bool
 }
@@ -121,7 +121,7 @@ func prepareCopyURLsTypeA(ctx context.Context, cc copyURLsContent, o prepareCopy
 		}
 	}
 
-	if !cc.sourceContent.Type.IsRegular() {
+	if !cc.sourceContent.Type.IsRegular() && !(o.allowStreams && isStreamSource(cc.sourceContent)) {
 		// Source is not a regular file
 		return URLs{Error: errInvalidSource(cc.sourceURL).Trace(cc.sourceURL)}
 	}
@@ -152,7 +152,7 @@ func prepareCopyURLsTypeB(ctx context.Context, cc copyURLsContent, o prepareCopy
 		}
 	}
 
-	if !cc.sourceContent.Type.IsRegular() {
+	if !cc.sourceContent.Type.IsRegular() && !(o.allowStreams && isStreamSource(cc.sourceContent)) {
 		if cc.sourceContent.Type.IsDir() {
 			return URLs{Error: errSourceIsDir(cc.sourceURL).Trace(cc.sourceURL)}
 		}
@@ -253,16 +253,20 @@ func prepareCopyURLsTypeC(ctx context.Context, cc copyURLsContent, o prepareCopy
 
 // makeCopyContentTypeC - CopyURLs content for copying.
 func makeCopyContentTypeC(cc copyURLsContent, sourceClientURL ClientURL) URLs {
-	newSourceURL := cc.sourceContent.URL
+	cc.targetURL = urlJoinPath(cc.targetURL, copySourceSuffix(cc, sourceClientURL))
+	return makeCopyContentTypeA(cc)
+}
+
+// copySourceSuffix returns the path of the listed source under the
+// target folder, relative to the parent of the listed folder.
+func copySourceSuffix(cc copyURLsContent, sourceClientURL ClientURL) string {
 	pathSeparatorIndex := strings.LastIndex(sourceClientURL.Path, string(sourceClientURL.Separator))
-	newSourceSuffix := filepath.ToSlash(newSourceURL.Path)
+	newSourceSuffix := filepath.ToSlash(cc.sourceContent.URL.Path)
 	if pathSeparatorIndex > 1 {
 		sourcePrefix := filepath.ToSlash(sourceClientURL.Path[:pathSeparatorIndex])
 		newSourceSuffix = strings.TrimPrefix(newSourceSuffix, sourcePrefix)
 	}
-	newTargetURL := urlJoinPath(cc.targetURL, newSourceSuffix)
-	cc.targetURL = newTargetURL
-	return makeCopyContentTypeA(cc)
+	return newSourceSuffix
 }
 
 // MULTI-SOURCE - Type D: copy([](f|d...), d) -> []B
@@ -316,6 +320,12 @@ type prepareCopyURLsOpts struct {
 	versionID               string
 	isZip                   bool
 	ignoreBucketExistsCheck bool
+	pathMapping             pathMapping
+	partition               timePartition
+	filesFrom               []filesFromEntry
+	continueOnError         bool
+	allowEmpty              bool
+	allowStreams            bool
 }
 
 type copyURLsContent struct {
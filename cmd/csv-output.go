@@ -0,0 +1,135 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/cli"
+)
+
+// csvFlags print the records of listing commands as CSV or TSV.
+var csvFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "csv",
+		Usage: "print a header row and a comma separated row per entry, with the columns of --json",
+	},
+	cli.BoolFlag{
+		Name:  "tsv",
+		Usage: "print a header row and a tab separated row per entry, with the columns of --json",
+	},
+}
+
+var errCSVOutput = errors.New("--csv, --tsv, --json and --format cannot be used together")
+
+// csvOutput writes records as the rows of a CSV or TSV table, whose
+// columns are the JSON fields of the records. Sizes are written in
+// bytes and times in RFC3339. Every row is flushed as soon as it is
+// written, so that an interrupted listing still leaves complete rows.
+type csvOutput struct {
+	mu      sync.Mutex
+	writer  *csv.Writer
+	columns []string
+}
+
+func newCSVOutput(w io.Writer, comma rune) *csvOutput {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	return &csvOutput{writer: writer}
+}
+
+// write writes the row of record, after the header row for the first
+// record.
+func (o *csvOutput) write(record recordMessage) error {
+	columns, values := csvRecord(record)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.columns == nil {
+		o.columns = columns
+		if e := o.writer.Write(columns); e != nil {
+			return e
+		}
+	}
+	if e := o.writer.Write(values); e != nil {
+		return e
+	}
+	o.writer.Flush()
+	return o.writer.Error()
+}
+
+// csvRecord returns the JSON field names of record, except its status,
+// and the values of these fields.
+func csvRecord(record interface{}) (columns, values []string) {
+	v := reflect.ValueOf(record)
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || name == "status" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		columns = append(columns, name)
+		values = append(values, csvValue(v.FieldByIndex(f.Index)))
+	}
+	return columns, values
+}
+
+// csvValue returns the text of a field, times in RFC3339 and maps or
+// slices in JSON. Zero times and empty maps are left empty.
+func csvValue(v reflect.Value) string {
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return csvValue(v.Elem())
+	case reflect.Map, reflect.Slice:
+		if v.Len() == 0 {
+			return ""
+		}
+		b, e := json.Marshal(v.Interface())
+		if e != nil {
+			return ""
+		}
+		return string(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+	return fmt.Sprint(v.Interface())
+}
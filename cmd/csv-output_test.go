@@ -0,0 +1,81 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCSVOutput(t *testing.T) {
+	modTime := time.Date(2024, 11, 5, 10, 30, 0, 0, time.UTC)
+	keys := []string{"plain.txt", "a,b.txt", `say "hi".txt`, "two\nlines.txt", " leading space"}
+	for _, comma := range []rune{',', '\t'} {
+		var buf bytes.Buffer
+		out := newCSVOutput(&buf, comma)
+		for _, key := range keys {
+			msg := findMessage{contentMessage{Status: "success", Filetype: "file", Time: modTime, Size: 1 << 40, Key: key, ETag: "abc"}}
+			if e := out.write(msg); e != nil {
+				t.Fatal(e)
+			}
+		}
+
+		reader := csv.NewReader(&buf)
+		reader.Comma = comma
+		rows, e := reader.ReadAll()
+		if e != nil {
+			t.Fatalf("comma %q: %v", comma, e)
+		}
+		columns, _ := csvRecord(contentMessage{})
+		if !reflect.DeepEqual(rows[0], columns) || columns[0] != "type" || columns[1] != "lastModified" {
+			t.Fatalf("comma %q: unexpected header %v", comma, rows[0])
+		}
+		if len(rows) != len(keys)+1 {
+			t.Fatalf("comma %q: expected %d rows, got %d", comma, len(keys)+1, len(rows))
+		}
+		for i, key := range keys {
+			row := rows[i+1]
+			if row[0] != "file" || row[1] != "2024-11-05T10:30:00Z" || row[2] != "1099511627776" || row[3] != key || row[4] != "abc" {
+				t.Fatalf("comma %q: unexpected row %q for key %q", comma, row, key)
+			}
+		}
+	}
+}
+
+func TestCSVRecord(t *testing.T) {
+	columns, values := csvRecord(duMessage{Prefix: "bucket/dir/", Size: 2048, Objects: 3, Status: "success"})
+	if !reflect.DeepEqual(columns, []string{"prefix", "size", "objects", "isVersions"}) {
+		t.Fatalf("unexpected columns %v", columns)
+	}
+	if !reflect.DeepEqual(values, []string{"bucket/dir/", "2048", "3", "false"}) {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	// Maps are written as JSON, empty values are left empty.
+	columns, values = csvRecord(contentMessage{Key: "a", Tags: map[string]string{"k": "v"}})
+	row := map[string]string{}
+	for i, column := range columns {
+		row[column] = values[i]
+	}
+	if row["tags"] != `{"k":"v"}` || row["metadata"] != "" || row["lastModified"] != "" {
+		t.Fatalf("unexpected row %v", row)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// errDeadlineExceeded is reported for the errors caused by --deadline.
+var errDeadlineExceeded = errors.New("operation deadline exceeded")
+
+// setGlobalDeadline cancels globalContext after deadline, the first
+// time it is called with a deadline.
+func setGlobalDeadline(deadline time.Duration) {
+	if deadline <= 0 {
+		return
+	}
+	if _, ok := globalContext.Deadline(); ok {
+		return
+	}
+	parentCancel := globalCancel
+	ctx, cancel := context.WithTimeout(globalContext, deadline)
+	globalContext = ctx
+	globalCancel = func() {
+		cancel()
+		parentCancel()
+	}
+}
+
+// isDeadlineExceeded returns true once --deadline expired.
+func isDeadlineExceeded() bool {
+	return errors.Is(globalContext.Err(), context.DeadlineExceeded)
+}
+
+// withDeadlineStatus wraps the actions of commands, so that a command
+// stopped by --deadline exits with globalDeadlineExitStatus once it has
+// printed what it did until then.
+func withDeadlineStatus(commands []cli.Command) []cli.Command {
+	for i := range commands {
+		if action, ok := commands[i].Action.(func(*cli.Context) error); ok {
+			commands[i].Action = func(cliCtx *cli.Context) error {
+				e := action(cliCtx)
+				if isDeadlineExceeded() {
+					errorIf(probe.NewError(errDeadlineExceeded), "Command aborted:")
+					return exitStatus(globalDeadlineExitStatus)
+				}
+				return e
+			}
+		}
+		commands[i].Subcommands = withDeadlineStatus(commands[i].Subcommands)
+	}
+	return commands
+}
+
+// requestTimeoutError is returned for a request canceled by
+// --request-timeout.
+type requestTimeoutError struct {
+	Method  string
+	Object  string
+	Timeout time.Duration
+}
+
+func (e requestTimeoutError) Error() string {
+	return fmt.Sprintf("%s %s did not complete within %s", e.Method, e.Object, e.Timeout)
+}
+
+// requestTimeoutTransport cancels requests which take longer than
+// timeout, from sending the request until the response body is read.
+type requestTimeoutTransport struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+}
+
+func newRequestTimeoutTransport(timeout time.Duration, transport http.RoundTripper) http.RoundTripper {
+	if timeout <= 0 {
+		return transport
+	}
+	return &requestTimeoutTransport{transport: transport, timeout: timeout}
+}
+
+func (t *requestTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	timeoutErr := requestTimeoutError{
+		Method:  req.Method,
+		Object:  strings.TrimPrefix(req.URL.Path, "/"),
+		Timeout: t.timeout,
+	}
+	resp, err := t.transport.RoundTrip(req.Clone(ctx))
+	if err != nil {
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil
+		cancel()
+		if timedOut {
+			return nil, timeoutErr
+		}
+		return nil, err
+	}
+	if resp.Body == nil {
+		cancel()
+		return resp, nil
+	}
+	resp.Body = &requestTimeoutBody{ReadCloser: resp.Body, ctx: ctx, parent: req.Context(), cancel: cancel, err: timeoutErr}
+	return resp, nil
+}
+
+// requestTimeoutBody releases the timer of its request once closed.
+type requestTimeoutBody struct {
+	io.ReadCloser
+	ctx, parent context.Context
+	cancel      context.CancelFunc
+	err         requestTimeoutError
+}
+
+func (b *requestTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && errors.Is(b.ctx.Err(), context.DeadlineExceeded) && b.parent.Err() == nil {
+		return n, b.err
+	}
+	return n, err
+}
+
+func (b *requestTimeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/internal/miniotest"
+)
+
+// newSlowServer returns a server which sends the headers of objects
+// right away but never their body.
+func newSlowServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("location") {
+			w.Write([]byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>"))
+			return
+		}
+		w.Header().Set("Content-Length", "1048576")
+		w.Header().Set("Last-Modified", "Tue, 02 Jan 2024 03:04:05 GMT")
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodHead {
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := newSlowServer(t)
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = miniotest.AccessKey
+	conf.SecretKey = miniotest.SecretKey
+	conf.Signature = "S3v4"
+	conf.Region = "us-east-1"
+	conf.RequestTimeout = 200 * time.Millisecond
+	clnt, err := S3New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, _, err := clnt.Get(context.Background(), GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	start := time.Now()
+	_, e := io.Copy(io.Discard, reader)
+	var timeoutErr requestTimeoutError
+	if !errors.As(e, &timeoutErr) {
+		t.Fatalf("expected a request timeout, got %v", e)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("the request was canceled after %s", elapsed)
+	}
+	if timeoutErr.Method != http.MethodGet || timeoutErr.Object != "bucket/object" {
+		t.Fatalf("unexpected request in %v", timeoutErr)
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	initTestConfig(t)
+	server := newSlowServer(t)
+	t.Setenv(mcEnvHostPrefix+"slow", "http://"+miniotest.AccessKey+":"+miniotest.SecretKey+"@"+strings.TrimPrefix(server.URL, "http://"))
+
+	out := jsonErrorOutput(t, "cat")
+	defer func(ctx context.Context, cancel context.CancelFunc, exiter func(int)) {
+		globalContext, globalCancel, cli.OsExiter = ctx, cancel, exiter
+	}(globalContext, globalCancel, cli.OsExiter)
+	cli.OsExiter = osExit
+
+	app := cli.NewApp()
+	app.Commands = withDeadlineStatus([]cli.Command{catCmd})
+	start := time.Now()
+	code := exitCode(func() {
+		app.Run([]string{"mc", "cat", "--deadline", "300ms", "slow/bucket/object"})
+	})
+	if code != globalDeadlineExitStatus {
+		t.Fatalf("expected the exit status %d, got %d: %s", globalDeadlineExitStatus, code, out.String())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("the command was aborted after %s", elapsed)
+	}
+	if !isDeadlineExceeded() {
+		t.Fatal("expected the global context to be past its deadline")
+	}
+	if _, msg := parseJSONError(t, out.String()); msg.Message != "Unable to read from `slow/bucket/object`." {
+		t.Fatalf("unexpected error %+v", msg)
+	}
+
+	// A command returning after the deadline exits with its status too.
+	out.Reset()
+	action := withDeadlineStatus([]cli.Command{{Action: func(*cli.Context) error { return nil }}})[0].Action.(func(*cli.Context) error)
+	var exitErr cli.ExitCoder
+	if e := action(nil); !errors.As(e, &exitErr) || exitErr.ExitCode() != globalDeadlineExitStatus {
+		t.Fatalf("expected the exit status %d, got %v", globalDeadlineExitStatus, e)
+	}
+	if !strings.Contains(out.String(), errDeadlineExceeded.Error()) {
+		t.Fatalf("expected %q to be reported, got %q", errDeadlineExceeded, out.String())
+	}
+}
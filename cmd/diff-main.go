@@ -23,7 +23,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
@@ -56,9 +55,12 @@ DESCRIPTION:
   Diff only calculates differences in object name, size and time. It *DOES NOT* compare objects' contents.
 
 LEGEND:
-  < - object is only in source.
-  > - object is only in destination.
-  ! - newer object is in source.
+  < - object is only in source, '-' in red on a color terminal.
+  > - object is only in destination, '+' in green on a color terminal.
+  ! - newer object is in source, 'Δ' in yellow on a color terminal.
+
+  Colors follow --theme, they are turned off by --theme none, --no-color,
+  --json and when the output is not a terminal.
 
 EXAMPLES:
   1. Compare a local folder with a folder on Amazon S3 cloud storage.
@@ -85,19 +87,19 @@ func (d diffMessage) String() string {
 	msg := ""
 	switch d.Diff {
 	case differInFirst:
-		msg = console.Colorize("DiffOnlyInFirst", "< "+d.FirstURL)
+		msg = console.Colorize("DiffOnlyInFirst", globalTheme.diffMarker(d.Diff)+" "+d.FirstURL)
 	case differInSecond:
-		msg = console.Colorize("DiffOnlyInSecond", "> "+d.SecondURL)
+		msg = console.Colorize("DiffOnlyInSecond", globalTheme.diffMarker(d.Diff)+" "+d.SecondURL)
 	case differInType:
-		msg = console.Colorize("DiffType", "! "+d.SecondURL)
+		msg = console.Colorize("DiffType", globalTheme.diffMarker(d.Diff)+" "+d.SecondURL)
 	case differInSize:
-		msg = console.Colorize("DiffSize", "! "+d.SecondURL)
+		msg = console.Colorize("DiffSize", globalTheme.diffMarker(d.Diff)+" "+d.SecondURL)
 	case differInMetadata:
-		msg = console.Colorize("DiffMetadata", "! "+d.SecondURL)
+		msg = console.Colorize("DiffMetadata", globalTheme.diffMarker(d.Diff)+" "+d.SecondURL)
 	case differInAASourceMTime:
-		msg = console.Colorize("DiffMMSourceMTime", "! "+d.SecondURL)
+		msg = console.Colorize("DiffMMSourceMTime", globalTheme.diffMarker(d.Diff)+" "+d.SecondURL)
 	case differInNone:
-		msg = console.Colorize("DiffInNone", "= "+d.FirstURL)
+		msg = console.Colorize("DiffInNone", globalTheme.diffMarker(d.Diff)+" "+d.FirstURL)
 	default:
 		fatalIf(errDummy().Trace(d.FirstURL, d.SecondURL),
 			"Unhandled difference between `"+d.FirstURL+"` and `"+d.SecondURL+"`.")
@@ -184,7 +186,7 @@ func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
 	}
 
 	// Diff first and second urls.
-	for diffMsg := range objectDifference(ctx, firstClient, secondClient, true) {
+	for diffMsg := range objectDifference(ctx, firstClient, secondClient, true, compareMTime) {
 		if diffMsg.Error != nil {
 			errorIf(diffMsg.Error, "Unable to calculate objects difference.")
 			// Ignore error and proceed to next object.
@@ -209,13 +211,7 @@ func mainDiff(cliCtx *cli.Context) error {
 	checkDiffSyntax(ctx, cliCtx, encKeyDB)
 
 	// Additional command specific theme customization.
-	console.SetColor("DiffMessage", color.New(color.FgGreen, color.Bold))
-	console.SetColor("DiffOnlyInFirst", color.New(color.FgRed))
-	console.SetColor("DiffOnlyInSecond", color.New(color.FgGreen))
-	console.SetColor("DiffType", color.New(color.FgMagenta))
-	console.SetColor("DiffSize", color.New(color.FgYellow, color.Bold))
-	console.SetColor("DiffMetadata", color.New(color.FgYellow, color.Bold))
-	console.SetColor("DiffMMSourceMTime", color.New(color.FgYellow, color.Bold))
+	globalTheme.apply()
 
 	URLs := cliCtx.Args()
 	firstURL := URLs.Get(0)
@@ -19,6 +19,10 @@ package cmd
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -42,6 +46,7 @@ const (
 	differInFirst                    // only in source (FIRST)
 	differInSecond                   // only in target (SECOND)
 	differInAASourceMTime            // differs in active-active source modtime
+	differInChecksum                 // differs in checksum
 )
 
 func (d differType) String() string {
@@ -54,6 +59,8 @@ func (d differType) String() string {
 		return "metadata"
 	case differInAASourceMTime:
 		return "mm-source-mtime"
+	case differInChecksum:
+		return "checksum"
 	case differInType:
 		return "type"
 	case differInFirst:
@@ -64,6 +71,77 @@ func (d differType) String() string {
 	return "unknown"
 }
 
+// compareMode selects how objects of the same size are compared.
+type compareMode string
+
+const (
+	// compareMTime copies sources newer than their target.
+	compareMTime compareMode = "mtime"
+	// compareSize only compares sizes.
+	compareSize compareMode = "size"
+	// compareChecksum compares MD5 sums, and only sizes when either side
+	// has no usable checksum such as a multipart ETag.
+	compareChecksum compareMode = "checksum"
+	// compareETag compares the MD5 sums of local sources, cached by size
+	// and modification time, to single part ETags, and only sizes for
+	// multipart ETags.
+	compareETag compareMode = "etag"
+)
+
+func parseCompareMode(s string) (compareMode, bool) {
+	switch mode := compareMode(strings.ToLower(s)); mode {
+	case compareMTime, compareSize, compareChecksum, compareETag:
+		return mode, true
+	}
+	return "", false
+}
+
+// contentMD5 returns the hex MD5 sum of the content of a local file, or
+// of an object whose ETag is an MD5 sum.
+func contentMD5(content *ClientContent) (string, bool) {
+	if content.URL.Type == fileSystem {
+		f, e := os.Open(content.URL.Path)
+		if e != nil {
+			return "", false
+		}
+		defer f.Close()
+		h := md5.New()
+		if _, e = io.Copy(h, f); e != nil {
+			return "", false
+		}
+		return hex.EncodeToString(h.Sum(nil)), true
+	}
+	etag := strings.Trim(content.ETag, "\"")
+	if !isMD5ETag(etag) {
+		return "", false
+	}
+	return strings.ToLower(etag), true
+}
+
+// sameSizeDiffer compares a source and a target of the same size.
+func sameSizeDiffer(compare compareMode, src, dst *ClientContent) differType {
+	switch compare {
+	case compareSize:
+		return differInNone
+	case compareChecksum:
+		// The target is checked first, it is remote and cheap to check.
+		dstSum, ok := contentMD5(dst)
+		if !ok {
+			return differInNone
+		}
+		if srcSum, ok := contentMD5(src); ok && srcSum != dstSum {
+			return differInChecksum
+		}
+		return differInNone
+	case compareETag:
+		return etagDiffer(src, dst)
+	}
+	if activeActiveModTimeUpdated(src, dst) {
+		return differInAASourceMTime
+	}
+	return differInNone
+}
+
 const activeActiveSourceModTimeKey = "X-Amz-Meta-Mm-Source-Mtime"
 
 func getSourceModTimeKey(metadata map[string]string) string {
@@ -161,14 +239,14 @@ func metadataEqual(m1, m2 map[string]string) bool {
 	return true
 }
 
-func objectDifference(ctx context.Context, sourceClnt, targetClnt Client, isMetadata bool) (diffCh chan diffMessage) {
+func objectDifference(ctx context.Context, sourceClnt, targetClnt Client, isMetadata bool, compare compareMode) (diffCh chan diffMessage) {
 	sourceURL := sourceClnt.GetURL().String()
 	sourceCh := sourceClnt.List(ctx, ListOptions{Recursive: true, WithMetadata: isMetadata, ShowDir: DirNone})
 
 	targetURL := targetClnt.GetURL().String()
 	targetCh := targetClnt.List(ctx, ListOptions{Recursive: true, WithMetadata: isMetadata, ShowDir: DirNone})
 
-	return difference(sourceURL, sourceCh, targetURL, targetCh, isMetadata, false)
+	return difference(sourceURL, sourceCh, targetURL, targetCh, isMetadata, false, compare)
 }
 
 func bucketDifference(ctx context.Context, sourceClnt, targetClnt Client) (diffCh chan diffMessage) {
@@ -215,11 +293,11 @@ func bucketDifference(ctx context.Context, sourceClnt, targetClnt Client) (diffC
 		}
 	}()
 
-	return difference(sourceURL, sourceCh, targetURL, targetCh, false, false)
+	return difference(sourceURL, sourceCh, targetURL, targetCh, false, false, compareMTime)
 }
 
 func differenceInternal(sourceURL string, srcCh <-chan *ClientContent, targetURL string, tgtCh <-chan *ClientContent,
-	cmpMetadata, returnSimilar bool, diffCh chan<- diffMessage,
+	cmpMetadata, returnSimilar bool, compare compareMode, diffCh chan<- diffMessage,
 ) *probe.Error {
 	// Pop first entries from the source and targets
 	srcCtnt, srcOk := <-srcCh
@@ -267,6 +345,9 @@ func differenceInternal(sourceURL string, srcCh <-chan *ClientContent, targetURL
 		}
 
 		srcSuffix := strings.TrimPrefix(srcCtnt.URL.String(), sourceURL)
+		if srcCtnt.targetSuffix != "" {
+			srcSuffix = srcCtnt.targetSuffix
+		}
 		tgtSuffix := strings.TrimPrefix(tgtCtnt.URL.String(), targetURL)
 
 		current := urlJoinPath(targetURL, srcSuffix)
@@ -324,11 +405,11 @@ func differenceInternal(sourceURL string, srcCh <-chan *ClientContent, targetURL
 					firstContent:  srcCtnt,
 					secondContent: tgtCtnt,
 				}
-			} else if activeActiveModTimeUpdated(srcCtnt, tgtCtnt) {
+			} else if diff := sameSizeDiffer(compare, srcCtnt, tgtCtnt); diff != differInNone {
 				diffCh <- diffMessage{
 					FirstURL:      srcCtnt.URL.String(),
 					SecondURL:     tgtCtnt.URL.String(),
-					Diff:          differInAASourceMTime,
+					Diff:          diff,
 					firstContent:  srcCtnt,
 					secondContent: tgtCtnt,
 				}
@@ -344,10 +425,8 @@ func differenceInternal(sourceURL string, srcCh <-chan *ClientContent, targetURL
 					firstContent:  srcCtnt,
 					secondContent: tgtCtnt,
 				}
-			}
-
-			// No differ
-			if returnSimilar {
+			} else if returnSimilar {
+				// No differ
 				diffCh <- diffMessage{
 					FirstURL:      srcCtnt.URL.String(),
 					SecondURL:     tgtCtnt.URL.String(),
@@ -375,13 +454,13 @@ func differenceInternal(sourceURL string, srcCh <-chan *ClientContent, targetURL
 
 // objectDifference function finds the difference between all objects
 // recursively in sorted order from source and target.
-func difference(sourceURL string, sourceCh <-chan *ClientContent, targetURL string, targetCh <-chan *ClientContent, cmpMetadata, returnSimilar bool) (diffCh chan diffMessage) {
+func difference(sourceURL string, sourceCh <-chan *ClientContent, targetURL string, targetCh <-chan *ClientContent, cmpMetadata, returnSimilar bool, compare compareMode) (diffCh chan diffMessage) {
 	diffCh = make(chan diffMessage, 10000)
 
 	go func() {
 		defer close(diffCh)
 
-		err := differenceInternal(sourceURL, sourceCh, targetURL, targetCh, cmpMetadata, returnSimilar, diffCh)
+		err := differenceInternal(sourceURL, sourceCh, targetURL, targetCh, cmpMetadata, returnSimilar, compare, diffCh)
 		if err != nil {
 			// handle this specifically for filesystem related errors.
 			switch v := err.ToGoError().(type) {
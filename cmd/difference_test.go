@@ -18,7 +18,12 @@
 package cmd
 
 import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 var testCases = []struct {
@@ -61,3 +66,65 @@ func TestExcludeOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestDifferenceCompareModes(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	targetURL := "http://localhost:9000/bucket"
+
+	// Sources and targets sorted by name, the target of "multipart" was
+	// uploaded in parts and its ETag is not the MD5 sum of its content.
+	files := []struct {
+		name           string
+		source, target string
+		sourceTime     time.Time
+		multipart      bool
+	}{
+		{name: "content", source: "new data", target: "old data", sourceTime: now.Add(-time.Hour)},
+		{name: "mtime", source: "same data", target: "same data", sourceTime: now.Add(time.Hour)},
+		{name: "multipart", source: "new data", target: "old data", sourceTime: now.Add(-time.Hour), multipart: true},
+		{name: "size", source: "longer data", target: "data", sourceTime: now.Add(-time.Hour)},
+	}
+	var sources, targets []*ClientContent
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if e := os.WriteFile(path, []byte(f.source), 0o600); e != nil {
+			t.Fatal(e)
+		}
+		sources = append(sources, &ClientContent{URL: *newClientURL(path), Size: int64(len(f.source)), Time: f.sourceTime})
+		etag := fmt.Sprintf("%x", md5.Sum([]byte(f.target)))
+		if f.multipart {
+			etag += "-2"
+		}
+		targets = append(targets, &ClientContent{URL: *newClientURL(targetURL + "/" + f.name), Size: int64(len(f.target)), Time: now, ETag: "\"" + etag + "\""})
+	}
+
+	for _, testCase := range []struct {
+		compare  compareMode
+		expected map[string]differType
+	}{
+		{compareMTime, map[string]differType{"mtime": differInAASourceMTime, "size": differInSize}},
+		{compareSize, map[string]differType{"size": differInSize}},
+		{compareChecksum, map[string]differType{"content": differInChecksum, "size": differInSize}},
+	} {
+		srcCh := make(chan *ClientContent, len(sources))
+		tgtCh := make(chan *ClientContent, len(targets))
+		for i := range sources {
+			srcCh <- sources[i]
+			tgtCh <- targets[i]
+		}
+		close(srcCh)
+		close(tgtCh)
+
+		got := map[string]differType{}
+		for diff := range difference(dir, srcCh, targetURL, tgtCh, false, false, testCase.compare) {
+			if diff.Error != nil {
+				t.Fatal(diff.Error)
+			}
+			got[filepath.Base(diff.FirstURL)] = diff.Diff
+		}
+		if fmt.Sprint(got) != fmt.Sprint(testCase.expected) {
+			t.Fatalf("--compare %s: expected %v, got %v", testCase.compare, testCase.expected, got)
+		}
+	}
+}
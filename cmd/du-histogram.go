@@ -0,0 +1,158 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// defaultHistogramBuckets are the upper bounds of the size buckets of
+// du --histogram.
+const defaultHistogramBuckets = "1KiB,16KiB,1MiB,16MiB,1GiB"
+
+var duHistogramFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "histogram",
+		Usage: "print the number of objects and their total size per size bucket",
+	},
+	cli.StringFlag{
+		Name:  "histogram-buckets",
+		Usage: "comma separated upper bounds of the size buckets of --histogram",
+		Value: defaultHistogramBuckets,
+	},
+}
+
+// sizeHistogram counts the objects and their size per size bucket, the
+// bucket i holding the sizes from bounds[i-1] up to, but excluding,
+// bounds[i] and the last one those from the last bound.
+type sizeHistogram struct {
+	bounds  []int64
+	objects []int64
+	sizes   []int64
+}
+
+// newSizeHistogramFromContext returns the histogram of --histogram, nil
+// without it.
+func newSizeHistogramFromContext(cliCtx *cli.Context) (*sizeHistogram, *probe.Error) {
+	if !cliCtx.Bool("histogram") {
+		return nil, nil
+	}
+	return newSizeHistogram(cliCtx.String("histogram-buckets"))
+}
+
+// newSizeHistogram returns a histogram of the comma separated, strictly
+// increasing, upper bounds of its buckets.
+func newSizeHistogram(buckets string) (*sizeHistogram, *probe.Error) {
+	var bounds []int64
+	for _, bucket := range strings.Split(buckets, ",") {
+		bound, e := humanize.ParseBytes(strings.TrimSpace(bucket))
+		if e != nil {
+			return nil, probe.NewError(e).Trace(buckets)
+		}
+		if bound == 0 || len(bounds) > 0 && int64(bound) <= bounds[len(bounds)-1] {
+			return nil, probe.NewError(fmt.Errorf("the bounds of the size buckets `%s` must be positive and increasing", buckets))
+		}
+		bounds = append(bounds, int64(bound))
+	}
+	return &sizeHistogram{
+		bounds:  bounds,
+		objects: make([]int64, len(bounds)+1),
+		sizes:   make([]int64, len(bounds)+1),
+	}, nil
+}
+
+// add counts an object of size bytes.
+func (h *sizeHistogram) add(size int64) {
+	if h == nil {
+		return
+	}
+	i := 0
+	for i < len(h.bounds) && size >= h.bounds[i] {
+		i++
+	}
+	h.objects[i]++
+	h.sizes[i] += size
+}
+
+// message returns the histogram of the objects of prefix.
+func (h *sizeHistogram) message(prefix string) duHistogramMessage {
+	msg := duHistogramMessage{Status: "success", Prefix: prefix}
+	for i := range h.objects {
+		bucket := duHistogramBucket{Objects: h.objects[i], Size: h.sizes[i]}
+		if i > 0 {
+			bucket.Min = h.bounds[i-1]
+		}
+		if i < len(h.bounds) {
+			bucket.Max = h.bounds[i]
+		}
+		msg.Buckets = append(msg.Buckets, bucket)
+	}
+	return msg
+}
+
+// duHistogramBucket is a size bucket of du --histogram, Max is 0 for the
+// last bucket which has no upper bound.
+type duHistogramBucket struct {
+	Min     int64 `json:"min"`
+	Max     int64 `json:"max,omitempty"`
+	Objects int64 `json:"objects"`
+	Size    int64 `json:"size"`
+}
+
+// duHistogramMessage is the size histogram of the objects of a prefix.
+type duHistogramMessage struct {
+	Status  string              `json:"status"`
+	Prefix  string              `json:"prefix"`
+	Buckets []duHistogramBucket `json:"buckets"`
+}
+
+// String colorized size histogram.
+func (m duHistogramMessage) String() string {
+	compact := func(size int64) string {
+		return strings.Join(strings.Fields(formatSize(size)), "")
+	}
+	lines := []string{"Size histogram of " + console.Colorize("Prefix", m.Prefix) + ":"}
+	for _, bucket := range m.Buckets {
+		label := compact(bucket.Min) + " - " + compact(bucket.Max)
+		if bucket.Max == 0 {
+			label = ">= " + compact(bucket.Min)
+		}
+		cnt := fmt.Sprintf("%d object", bucket.Objects)
+		if bucket.Objects != 1 {
+			cnt += "s"
+		}
+		lines = append(lines, fmt.Sprintf("  %-20s\t%s\t%s", label,
+			console.Colorize("Objects", cnt),
+			console.Colorize("Size", compact(bucket.Size))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON jsonified size histogram.
+func (m duHistogramMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	hist, err := newSizeHistogram(defaultHistogramBuckets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int64{0, 1023, 1024, 16<<10 - 1, 16 << 10, 5 << 20, 1 << 30, 3 << 30} {
+		hist.add(size)
+	}
+	msg := hist.message("s3/bucket")
+	var decoded duHistogramMessage
+	if e := json.Unmarshal([]byte(msg.JSON()), &decoded); e != nil {
+		t.Fatal(e)
+	}
+	expected := []duHistogramBucket{
+		{Min: 0, Max: 1 << 10, Objects: 2, Size: 1023},
+		{Min: 1 << 10, Max: 16 << 10, Objects: 2, Size: 1024 + 16<<10 - 1},
+		{Min: 16 << 10, Max: 1 << 20, Objects: 1, Size: 16 << 10},
+		{Min: 1 << 20, Max: 16 << 20, Objects: 1, Size: 5 << 20},
+		{Min: 16 << 20, Max: 1 << 30, Objects: 0, Size: 0},
+		{Min: 1 << 30, Objects: 2, Size: 4 << 30},
+	}
+	if !reflect.DeepEqual(decoded.Buckets, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, decoded.Buckets)
+	}
+
+	for _, buckets := range []string{"", "1KiB,1KiB", "1MiB,1KiB", "0,1KiB", "1KiB,lots"} {
+		if _, err := newSizeHistogram(buckets); err == nil {
+			t.Errorf("%q: expected an error", buckets)
+		}
+	}
+}
+
+func TestDuHistogram(t *testing.T) {
+	initTestConfig(t)
+	dir := t.TempDir()
+	for name, size := range map[string]int{"a": 10, "sub/b": 2 << 10, "sub/deep/c": 20 << 10} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if e := os.MkdirAll(filepath.Dir(path), 0o700); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, make([]byte, size), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	hist, err := newSizeHistogram("1KiB,16KiB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Also counted below --depth.
+	size, objects, e := du(context.Background(), dir, time.Time{}, false, 1, nil, hist)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if objects != 3 || size != 10+2<<10+20<<10 {
+		t.Fatalf("unexpected totals %d objects, %d bytes", objects, size)
+	}
+	if expected := []int64{1, 1, 1}; !reflect.DeepEqual(hist.objects, expected) {
+		t.Fatalf("expected %v objects per bucket, got %v", expected, hist.objects)
+	}
+}
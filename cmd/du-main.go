@@ -25,7 +25,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
@@ -62,7 +61,7 @@ var duCmd = cli.Command{
 	Action:       mainDu,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(duFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(duFlags, duHistogramFlags...), ioFlags...), csvFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -74,6 +73,7 @@ FLAGS:
   {{end}}
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY: list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 EXAMPLES:
   1. Summarize disk usage of 'jazz-songs' bucket recursively.
@@ -87,6 +87,10 @@ EXAMPLES:
 
   4. Summarize disk usage of 'jazz-songs' bucket with all objects versions
      {{.Prompt}} {{.HelpName}} --versions s3/jazz-songs/
+
+  5. Summarize disk usage of 'jazz-songs' bucket with the number and size of its objects per size bucket
+     {{.Prompt}} {{.HelpName}} --histogram s3/jazz-songs/
+     {{.Prompt}} {{.HelpName}} --histogram --histogram-buckets 64KiB,8MiB,128MiB s3/jazz-songs/
 `,
 }
 
@@ -101,7 +105,7 @@ type duMessage struct {
 
 // Colorized message for console printing.
 func (r duMessage) String() string {
-	humanSize := strings.Join(strings.Fields(humanize.IBytes(uint64(r.Size))), "")
+	humanSize := strings.Join(strings.Fields(formatSize(r.Size)), "")
 	cnt := fmt.Sprintf("%d object", r.Objects)
 	if r.IsVersions {
 		cnt = fmt.Sprintf("%d version", r.Objects)
@@ -121,7 +125,9 @@ func (r duMessage) JSON() string {
 	return string(msgBytes)
 }
 
-func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool, depth int, encKeyDB map[string][]prefixSSEPair) (sz, objs int64, err error) {
+// du prints the disk usage of urlStr, down to depth, and counts its
+// objects in hist, if not nil.
+func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool, depth int, encKeyDB map[string][]prefixSSEPair, hist *sizeHistogram) (sz, objs int64, err error) {
 	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
 
 	if !strings.HasSuffix(targetURL, "/") {
@@ -176,7 +182,7 @@ func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool
 			if targetAlias != "" {
 				subDirAlias = targetAlias + "/" + content.URL.Path
 			}
-			used, n, err := du(ctx, subDirAlias, timeRef, withVersions, depth, encKeyDB)
+			used, n, err := du(ctx, subDirAlias, timeRef, withVersions, depth, encKeyDB, hist)
 			if err != nil {
 				return 0, 0, err
 			}
@@ -186,6 +192,7 @@ func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool
 			if !content.IsDeleteMarker && !content.Type.IsDir() {
 				size += content.Size
 				objects++
+				hist.add(content.Size)
 			}
 		}
 	}
@@ -241,6 +248,11 @@ func mainDu(cliCtx *cli.Context) error {
 
 	withVersions := cliCtx.Bool("versions")
 	timeRef := parseRewindFlag(cliCtx.String("rewind"))
+	if cliCtx.IsSet("histogram-buckets") && !cliCtx.Bool("histogram") {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("histogram-buckets")), "--histogram-buckets can only be used with --histogram.")
+	}
+	_, err = newSizeHistogramFromContext(cliCtx)
+	fatalIf(err, "Invalid --histogram-buckets.")
 
 	var duErr error
 	var isDir bool
@@ -250,9 +262,15 @@ func mainDu(cliCtx *cli.Context) error {
 			fatalIf(errInvalidArgument().Trace(urlStr), fmt.Sprintf("Source `%s` is not a folder. Only folders are supported by 'du' command.", urlStr))
 		}
 
-		if _, _, err := du(ctx, urlStr, timeRef, withVersions, depth, encKeyDB); duErr == nil {
+		// A histogram per target.
+		hist, _ := newSizeHistogramFromContext(cliCtx)
+		_, _, err := du(ctx, urlStr, timeRef, withVersions, depth, encKeyDB, hist)
+		if duErr == nil {
 			duErr = err
 		}
+		if err == nil && hist != nil {
+			printMsg(hist.message(strings.TrimSuffix(urlStr, "/")))
+		}
 	}
 
 	return duErr
@@ -88,6 +88,7 @@ func mainEncryptClear(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 	fatalIf(client.DeleteEncryption(ctx), "Unable to clear auto encryption configuration")
 	printMsg(encryptClearMessage{
 		Op:     cliCtx.Command.Name,
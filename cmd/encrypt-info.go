@@ -102,6 +102,7 @@ func mainEncryptInfo(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 	algorithm, keyID, e := client.GetEncryption(ctx)
 	fatalIf(e, "Unable to get encryption info")
 	msg := encryptInfoMessage{
@@ -96,6 +96,7 @@ func mainEncryptSet(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 	var algorithm, keyID string
 	switch len(args) {
 	case 3:
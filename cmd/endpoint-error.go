@@ -0,0 +1,154 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+// endpointError is returned when a request could not reach its endpoint,
+// it tells which logical endpoint it was, where its address came from
+// and what to check. The underlying error stays available to errors.Is/As.
+type endpointError struct {
+	Role     string
+	Endpoint string
+	Source   string
+	Reason   string
+	Hint     string
+	Err      error
+}
+
+func (e endpointError) Error() string {
+	return fmt.Sprintf("%s endpoint %s is unreachable (address from %s): %s (%v). Hint: %s",
+		e.Role, e.Endpoint, e.Source, e.Reason, e.Err, e.Hint)
+}
+
+func (e endpointError) Unwrap() error {
+	return e.Err
+}
+
+// connectionFailure returns a short reason if err happened while
+// resolving or connecting to the endpoint, before any byte was sent.
+func connectionFailure(err error) (reason string, dns, ok bool) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return "", false, false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsNotFound {
+			return fmt.Sprintf("DNS lookup of %s failed: no such host", dnsErr.Name), true, true
+		}
+		return fmt.Sprintf("DNS lookup of %s failed", dnsErr.Name), true, true
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		return "", false, false
+	}
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused", false, true
+	case opErr.Timeout():
+		return "connection timed out", false, true
+	}
+	return "unable to connect", false, true
+}
+
+// controlPlaneEndpointSource returns where the control plane address comes from.
+func controlPlaneEndpointSource() string {
+	if serverEndpointFlag != "" {
+		return "--endpoint"
+	}
+	if os.Getenv("GPU_MALL_SERVER") != "" {
+		return "the GPU_MALL_SERVER environment variable"
+	}
+	return "the built-in default"
+}
+
+// controlPlaneHint returns what to check when the control plane is unreachable.
+func controlPlaneHint(dns bool) string {
+	hint := "check your network and VPN, the gpumall service may be temporarily unavailable"
+	if dns {
+		hint = "check your network, VPN and DNS settings"
+	}
+	if serverEndpointFlag != "" {
+		hint += ", and verify --endpoint"
+	} else if os.Getenv("GPU_MALL_SERVER") != "" {
+		hint += ", and verify GPU_MALL_SERVER"
+	}
+	return hint
+}
+
+// storageEndpointSource returns where the address of the alias comes from.
+func storageEndpointSource(alias string) string {
+	switch {
+	case alias == AuthAlias && globalCredentials != nil:
+		return "--credentials-file"
+	case alias == AuthAlias:
+		return "the endpoint returned by `mc auth`"
+	case os.Getenv(mcEnvHostPrefix+alias) != "":
+		return "the " + mcEnvHostPrefix + alias + " environment variable"
+	}
+	return "alias `" + alias + "` in the mc config"
+}
+
+// storageHint returns what to check when the storage of alias is unreachable.
+func storageHint(alias string, dns bool) string {
+	hint := "check your network and VPN"
+	if dns {
+		hint = "check your network, VPN and DNS settings"
+	}
+	if alias == AuthAlias && globalCredentials != nil {
+		return hint + ", and verify the endpoint in --credentials-file"
+	}
+	if alias == AuthAlias {
+		return hint + ", then re-run `mc auth` with the right --region to refresh the endpoint"
+	}
+	return hint + ", and verify the URL with `mc alias list " + alias + "`"
+}
+
+// endpointErrorTransport wraps connection errors of the storage endpoint
+// of alias into an endpointError.
+type endpointErrorTransport struct {
+	alias     string
+	transport http.RoundTripper
+}
+
+func newEndpointErrorTransport(alias string, transport http.RoundTripper) http.RoundTripper {
+	return &endpointErrorTransport{alias: alias, transport: transport}
+}
+
+func (t *endpointErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if reason, dns, ok := connectionFailure(err); ok {
+		return resp, endpointError{
+			Role:     "storage",
+			Endpoint: req.URL.Host,
+			Source:   storageEndpointSource(t.alias),
+			Reason:   reason,
+			Hint:     storageHint(t.alias, dns),
+			Err:      err,
+		}
+	}
+	return resp, err
+}
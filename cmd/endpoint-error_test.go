@@ -0,0 +1,98 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestConnectionFailure(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		reason string
+		dns    bool
+		ok     bool
+	}{
+		{
+			name:   "no such host",
+			err:    &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "minio-sh-01.internal", Server: "10.0.0.2:53", IsNotFound: true}},
+			reason: "DNS lookup of minio-sh-01.internal failed: no such host",
+			dns:    true,
+			ok:     true,
+		},
+		{
+			name:   "connection refused",
+			err:    &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			reason: "connection refused",
+			ok:     true,
+		},
+		{
+			name: "reset after connecting",
+			err:  &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+		},
+		{
+			name: "not a network error",
+			err:  errors.New("Access Denied"),
+		},
+	}
+	for _, testCase := range testCases {
+		reason, dns, ok := connectionFailure(testCase.err)
+		if reason != testCase.reason || dns != testCase.dns || ok != testCase.ok {
+			t.Errorf("%s: got (%q, %v, %v), expected (%q, %v, %v)", testCase.name,
+				reason, dns, ok, testCase.reason, testCase.dns, testCase.ok)
+		}
+	}
+}
+
+func TestStorageEndpointError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "http://" + l.Addr().String()
+	l.Close()
+
+	for _, testCase := range []struct {
+		alias  string
+		source string
+		hint   string
+	}{
+		{alias: AuthAlias, source: "the endpoint returned by `mc auth`", hint: "re-run `mc auth`"},
+		{alias: "myminio", source: "alias `myminio` in the mc config", hint: "`mc alias list myminio`"},
+	} {
+		client := &http.Client{Transport: getTransportForConfig(&Config{Alias: testCase.alias, HostURL: endpoint}, false)}
+		_, err = client.Get(endpoint + "/bucket/object")
+
+		var epErr endpointError
+		if !errors.As(err, &epErr) {
+			t.Fatalf("%s: expected an endpointError, got %v", testCase.alias, err)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			t.Fatalf("%s: expected the underlying error to be kept, got %v", testCase.alias, err)
+		}
+		if epErr.Role != "storage" || epErr.Source != testCase.source || !strings.Contains(epErr.Hint, testCase.hint) {
+			t.Fatalf("%s: unexpected error context %+v", testCase.alias, epErr)
+		}
+	}
+}
@@ -21,15 +21,66 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
 	"github.com/minio/pkg/v2/console"
 )
 
+var (
+	// errorOutput receives the JSON errors, a single object per line.
+	errorOutput io.Writer = os.Stderr
+
+	// osExit ends mc after a JSON fatal error, tests replace it.
+	osExit = os.Exit
+)
+
+// fatalHooks run with the error of fatal before mc exits, so that the
+// state left behind for other tools, such as --progress-file, is final.
+var fatalHooks = struct {
+	sync.Mutex
+	next  int
+	hooks map[int]func(error)
+}{hooks: make(map[int]func(error))}
+
+// registerFatalHook runs hook if mc exits on a fatal error, until the
+// returned function unregisters it.
+func registerFatalHook(hook func(error)) (unregister func()) {
+	fatalHooks.Lock()
+	defer fatalHooks.Unlock()
+	id := fatalHooks.next
+	fatalHooks.next++
+	fatalHooks.hooks[id] = hook
+	return func() {
+		fatalHooks.Lock()
+		defer fatalHooks.Unlock()
+		delete(fatalHooks.hooks, id)
+	}
+}
+
+// runFatalHooks runs the registered hooks with err, they may unregister
+// themselves meanwhile.
+func runFatalHooks(err error) {
+	fatalHooks.Lock()
+	hooks := make([]func(error), 0, len(fatalHooks.hooks))
+	for _, hook := range fatalHooks.hooks {
+		hooks = append(hooks, hook)
+	}
+	fatalHooks.Unlock()
+	for _, hook := range hooks {
+		hook(err)
+	}
+}
+
 // causeMessage container for golang error messages
 type causeMessage struct {
 	Message string `json:"message"`
@@ -38,13 +89,138 @@ type causeMessage struct {
 
 // errorMessage container for error messages
 type errorMessage struct {
+	Code      string             `json:"code"`
 	Message   string             `json:"message"`
+	Command   string             `json:"command,omitempty"`
+	Resource  string             `json:"resource,omitempty"`
+	Hint      string             `json:"hint,omitempty"`
 	Cause     causeMessage       `json:"cause"`
 	Type      string             `json:"type"`
+	RequestID string             `json:"requestId,omitempty"`
+	HostID    string             `json:"hostId,omitempty"`
 	CallTrace []probe.TracePoint `json:"trace,omitempty"`
 	SysInfo   map[string]string  `json:"sysinfo,omitempty"`
 }
 
+// newErrorMessage builds the JSON error of err, errType is either
+// "fatal" or "error".
+func newErrorMessage(err *probe.Error, errType, msg string) errorMessage {
+	code := errorCode(err)
+	errorMsg := errorMessage{
+		Code:     code,
+		Message:  msg,
+		Command:  globalCommandName,
+		Resource: errorResource(err),
+		Hint:     errorHint(code),
+		Type:     errType,
+		Cause: causeMessage{
+			Message: err.ToGoError().Error(),
+			Error:   err.ToGoError(),
+		},
+	}
+	errorMsg.RequestID, errorMsg.HostID = requestIDs(err)
+	if globalDebug {
+		errorMsg.CallTrace = err.CallTrace
+		errorMsg.SysInfo = err.SysInfo
+	}
+	return errorMsg
+}
+
+// printErrorMessage writes errorMsg on a single line of errorOutput, so
+// that it can be parsed apart from the JSON messages on stdout.
+func printErrorMessage(errorMsg errorMessage) {
+	buf, e := json.Marshal(struct {
+		Status string       `json:"status"`
+		Error  errorMessage `json:"error"`
+	}{
+		Status: "error",
+		Error:  errorMsg,
+	})
+	if e != nil {
+		console.Fatalln(probe.NewError(e))
+	}
+	fmt.Fprintln(errorOutput, string(buf))
+}
+
+// errorCode returns a stable identifier of the cause of err: the S3
+// error code when the server answered, else a name of its kind.
+func errorCode(err *probe.Error) string {
+	e := err.ToGoError()
+	var errResp minio.ErrorResponse
+	var invalidArg invalidArgumentErr
+	var netErr net.Error
+	switch {
+	case errors.As(e, &errResp) && errResp.Code != "":
+		return errResp.Code
+	case errors.Is(e, errTokenExpired):
+		return "ExpiredToken"
+	case errors.Is(e, context.Canceled):
+		return "Canceled"
+	case errors.Is(e, context.DeadlineExceeded):
+		return "Timeout"
+	case errors.As(e, &invalidArg):
+		return "InvalidArgument"
+	}
+	// The typed errors of mc are named after what went wrong.
+	t := reflect.TypeOf(e)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.PkgPath() == reflect.TypeOf(errorMessage{}).PkgPath() && t.Name() != "" && unicode.IsUpper(rune(t.Name()[0])) {
+		return t.Name()
+	}
+	switch {
+	case errors.Is(e, os.ErrNotExist):
+		return "NotFound"
+	case errors.Is(e, os.ErrPermission):
+		return "PermissionDenied"
+	case errors.Is(e, os.ErrExist):
+		return "AlreadyExists"
+	case errors.As(e, &netErr):
+		return "NetworkError"
+	}
+	return "GenericError"
+}
+
+// errorResource returns what err is about: the bucket and object of an
+// S3 error, else the arguments traced along err.
+func errorResource(err *probe.Error) string {
+	var errResp minio.ErrorResponse
+	if errors.As(err.ToGoError(), &errResp) && errResp.BucketName != "" {
+		if errResp.Key == "" {
+			return errResp.BucketName
+		}
+		return errResp.BucketName + "/" + errResp.Key
+	}
+	for _, tp := range err.CallTrace {
+		if tags := tp.Env["Tags"]; len(tags) > 0 {
+			return strings.Join(tags, " ")
+		}
+	}
+	return ""
+}
+
+// errorHint suggests what to do about an error of code.
+func errorHint(code string) string {
+	switch code {
+	case "InvalidArgument":
+		return "Run `mc " + strings.TrimSpace(globalCommandName+" --help") + "` for the usage."
+	case "AccessDenied", "PathInsufficientPermission", "PermissionDenied":
+		return "Check the permissions of the account with `mc whoami`."
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		return "Check the credentials of the alias with `mc alias list`."
+	case "ExpiredToken", "InvalidToken":
+		return "Reauthorize with `mc auth`."
+	case "NoSuchBucket", "BucketDoesNotExist":
+		return "Create the bucket with `mc mb`, or check its name with `mc ls`."
+	case "NoSuchKey", "ObjectMissing", "PathNotFound", "NotFound":
+		return "Check the path with `mc ls`."
+	case "Timeout", "NetworkError", "RequestTimeout":
+		return "Check the network and the endpoint of the alias."
+	}
+	return ""
+}
+
 // fatalIf wrapper function which takes error and selectively prints stack frames if available on debug
 func fatalIf(err *probe.Error, msg string, data ...interface{}) {
 	if err == nil {
@@ -54,31 +230,20 @@ func fatalIf(err *probe.Error, msg string, data ...interface{}) {
 }
 
 func fatal(err *probe.Error, msg string, data ...interface{}) {
+	cause := err.ToGoError()
+	if cause == nil {
+		cause = fmt.Errorf(msg, data...)
+	}
+	runFatalHooks(cause)
+
 	if globalJSON {
-		errorMsg := errorMessage{
-			Message: msg,
-			Type:    "fatal",
-			Cause: causeMessage{
-				Message: err.ToGoError().Error(),
-				Error:   err.ToGoError(),
-			},
+		printErrorMessage(newErrorMessage(err, "fatal", fmt.Sprintf(msg, data...)))
+		if isDeadlineExceeded() {
+			osExit(globalDeadlineExitStatus)
+			return
 		}
-		if globalDebug {
-			errorMsg.CallTrace = err.CallTrace
-			errorMsg.SysInfo = err.SysInfo
-		}
-		json, e := json.MarshalIndent(struct {
-			Status string       `json:"status"`
-			Error  errorMessage `json:"error"`
-		}{
-			Status: "error",
-			Error:  errorMsg,
-		}, "", " ")
-		if e != nil {
-			console.Fatalln(probe.NewError(e))
-		}
-		console.Println(string(json))
-		console.Fatalln()
+		osExit(globalErrorExitStatus)
+		return
 	}
 
 	msg = fmt.Sprintf(msg, data...)
@@ -88,8 +253,10 @@ func fatal(err *probe.Error, msg string, data ...interface{}) {
 		if errors.Is(globalContext.Err(), context.Canceled) {
 			// mc is getting killed
 			e = errors.New("Canceling upon user request")
+		} else if isDeadlineExceeded() {
+			e = errDeadlineExceeded
 		} else {
-			e = err.ToGoError()
+			e = notSupportedError(err.ToGoError())
 		}
 		errmsg = e.Error()
 	}
@@ -115,7 +282,12 @@ func fatal(err *probe.Error, msg string, data ...interface{}) {
 		}
 	}
 
-	console.Fatalln(fmt.Sprintf("%s %s", msg, errmsg))
+	if isDeadlineExceeded() {
+		console.Errorln(fmt.Sprintf("%s %s%s", msg, errmsg, requestIDsSuffix(err)))
+		osExit(globalDeadlineExitStatus)
+		return
+	}
+	console.Fatalln(fmt.Sprintf("%s %s%s", msg, errmsg, requestIDsSuffix(err)))
 }
 
 // Exit coder wraps cli new exit error with a
@@ -133,29 +305,7 @@ func errorIf(err *probe.Error, msg string, data ...interface{}) {
 		return
 	}
 	if globalJSON {
-		errorMsg := errorMessage{
-			Message: fmt.Sprintf(msg, data...),
-			Type:    "error",
-			Cause: causeMessage{
-				Message: err.ToGoError().Error(),
-				Error:   err.ToGoError(),
-			},
-		}
-		if globalDebug {
-			errorMsg.CallTrace = err.CallTrace
-			errorMsg.SysInfo = err.SysInfo
-		}
-		json, e := json.MarshalIndent(struct {
-			Status string       `json:"status"`
-			Error  errorMessage `json:"error"`
-		}{
-			Status: "error",
-			Error:  errorMsg,
-		}, "", " ")
-		if e != nil {
-			console.Fatalln(probe.NewError(e))
-		}
-		console.Println(string(json))
+		printErrorMessage(newErrorMessage(err, "error", fmt.Sprintf(msg, data...)))
 		return
 	}
 	msg = fmt.Sprintf(msg, data...)
@@ -164,13 +314,24 @@ func errorIf(err *probe.Error, msg string, data ...interface{}) {
 		if errors.Is(globalContext.Err(), context.Canceled) {
 			// mc is getting killed
 			e = errors.New("Canceling upon user request")
+		} else if isDeadlineExceeded() {
+			e = errDeadlineExceeded
 		} else {
-			e = err.ToGoError()
+			e = notSupportedError(err.ToGoError())
 		}
-		console.Errorln(fmt.Sprintf("%s %s", msg, e))
+		console.Errorln(fmt.Sprintf("%s %s%s", msg, e, requestIDsSuffix(err)))
 		return
 	}
-	console.Errorln(fmt.Sprintf("%s %s", msg, err))
+	console.Errorln(fmt.Sprintf("%s %s%s", msg, err, requestIDsSuffix(err)))
+}
+
+// notSupportedError explains the NotImplemented answers of servers
+// which do not implement the requested operation.
+func notSupportedError(e error) error {
+	if isNotImplemented(e) {
+		return FeatureNotSupported{Feature: "this operation", Err: e}
+	}
+	return e
 }
 
 // deprecatedError function for deprecated commands
@@ -0,0 +1,217 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+)
+
+// jsonErrorOutput makes fatal and errorIf print their JSON errors to
+// the returned buffer, with fatal panicking with its exit code.
+func jsonErrorOutput(t *testing.T, command string) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	oldJSON, oldCommand, oldOutput, oldExit := globalJSON, globalCommandName, errorOutput, osExit
+	t.Cleanup(func() {
+		globalJSON, globalCommandName, errorOutput, osExit = oldJSON, oldCommand, oldOutput, oldExit
+	})
+	globalJSON, globalCommandName, errorOutput = true, command, buf
+	osExit = func(code int) { panic(code) }
+	return buf
+}
+
+// exitCode returns the exit code of fatal in fn, -1 if it did not exit.
+func exitCode(fn func()) (code int) {
+	defer func() {
+		if r := recover(); r != nil {
+			code = r.(int)
+		}
+	}()
+	fn()
+	return -1
+}
+
+// parseJSONError parses the single line printed by fatal or errorIf.
+func parseJSONError(t *testing.T, out string) (status string, msg errorMessage) {
+	t.Helper()
+	if strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, "\n") {
+		t.Fatalf("expected a single JSON line, got %q", out)
+	}
+	var v struct {
+		Status string `json:"status"`
+		Error  struct {
+			errorMessage
+			// The cause holds an error interface, it is not decoded.
+			Cause json.RawMessage `json:"cause"`
+		} `json:"error"`
+	}
+	if e := json.Unmarshal([]byte(out), &v); e != nil {
+		t.Fatalf("expected a JSON object, got %q: %v", out, e)
+	}
+	return v.Status, v.Error.errorMessage
+}
+
+func TestJSONErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		fn       func()
+		exitCode int
+		expected errorMessage
+	}{
+		{
+			name:    "invalid argument",
+			command: "put",
+			fn: func() {
+				fatalIf(errInvalidArgument().Trace("a.txt"), "Invalid number of arguments.")
+			},
+			exitCode: globalErrorExitStatus,
+			expected: errorMessage{
+				Code:     "InvalidArgument",
+				Message:  "Invalid number of arguments.",
+				Command:  "put",
+				Resource: "a.txt",
+				Hint:     "Run `mc put --help` for the usage.",
+				Type:     "fatal",
+			},
+		},
+		{
+			name:    "expired token",
+			command: "ls",
+			fn: func() {
+				fatalIf(probe.NewError(errTokenExpired), "Token has expired, please reauthorize with `%s`.", "mc auth")
+			},
+			exitCode: globalErrorExitStatus,
+			expected: errorMessage{
+				Code:    "ExpiredToken",
+				Message: "Token has expired, please reauthorize with `mc auth`.",
+				Command: "ls",
+				Hint:    "Reauthorize with `mc auth`.",
+				Type:    "fatal",
+			},
+		},
+		{
+			name:    "access denied",
+			command: "put",
+			fn: func() {
+				errorIf(probe.NewError(minio.ErrorResponse{
+					Code:       "AccessDenied",
+					Message:    "Access Denied.",
+					BucketName: "bucket",
+					Key:        "a.txt",
+					RequestID:  "REQUEST1",
+					HostID:     "HOST1",
+				}), "Unable to upload.")
+			},
+			exitCode: -1,
+			expected: errorMessage{
+				Code:      "AccessDenied",
+				Message:   "Unable to upload.",
+				Command:   "put",
+				Resource:  "bucket/a.txt",
+				Hint:      "Check the permissions of the account with `mc whoami`.",
+				Type:      "error",
+				RequestID: "REQUEST1",
+				HostID:    "HOST1",
+			},
+		},
+		{
+			name:    "missing file",
+			command: "put",
+			fn: func() {
+				_, e := os.Stat(filepath.Join(t.TempDir(), "missing"))
+				errorIf(probe.NewError(e).Trace("missing"), "Unable to read the source.")
+			},
+			exitCode: -1,
+			expected: errorMessage{
+				Code:     "NotFound",
+				Message:  "Unable to read the source.",
+				Command:  "put",
+				Resource: "missing",
+				Hint:     "Check the path with `mc ls`.",
+				Type:     "error",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := jsonErrorOutput(t, tc.command)
+			if code := exitCode(tc.fn); code != tc.exitCode {
+				t.Fatalf("expected the exit code %d, got %d", tc.exitCode, code)
+			}
+			status, msg := parseJSONError(t, out.String())
+			if status != "error" {
+				t.Fatalf("expected the status error, got %q", status)
+			}
+			if !reflect.DeepEqual(msg, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, msg)
+			}
+		})
+	}
+}
+
+func TestJSONUploadError(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	t.Setenv(mcEnvHostPrefix+"jsonerr", server.AliasURL())
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if e := os.WriteFile(path, []byte("hello"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	ctx := context.Background()
+	var urls URLs
+	for cpURLs := range prepareCopyURLs(ctx, prepareCopyURLsOpts{
+		sourceURLs:              []string{path},
+		targetURL:               "jsonerr/missing/a.txt",
+		ignoreBucketExistsCheck: true,
+	}) {
+		if cpURLs.Error != nil {
+			t.Fatal(cpURLs.Error)
+		}
+		urls = doCopy(ctx, doCopyOpts{cpURLs: cpURLs, pg: newAccounter(0)})
+	}
+	if urls.Error == nil {
+		t.Fatal("expected the upload to a missing bucket to fail")
+	}
+
+	out := jsonErrorOutput(t, "put")
+	printPutURLsError(&urls)
+	status, msg := parseJSONError(t, out.String())
+	if status != "error" || msg.Type != "error" || msg.Command != "put" || msg.Message != "Unable to upload." {
+		t.Fatalf("unexpected error %q", out.String())
+	}
+	if msg.Code != "BucketDoesNotExist" {
+		t.Fatalf("expected the code of a missing bucket, got %q", msg.Code)
+	}
+	if msg.Hint == "" || !strings.HasPrefix(msg.RequestID, "MINIOTEST") {
+		t.Fatalf("expected a hint and the request ID of the server, got %q", out.String())
+	}
+}
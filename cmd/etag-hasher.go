@@ -0,0 +1,188 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// etagHasher computes the ETag expected for an unencrypted upload while
+// the object is streamed to the server, so that a checksum verified
+// upload reads its source only once. Both the single PUT ETag (MD5 of
+// the object) and the multipart ETag (MD5 of the part MD5s, suffixed
+// with the number of parts) are computed, the part boundaries are the
+// ones minio-go uses for a multipart upload of the same size.
+type etagHasher struct {
+	whole    hash.Hash
+	part     hash.Hash
+	partSize int64
+	partLen  int64
+	partSums []byte
+	parts    int
+}
+
+func newETagHasher(size int64, partSize uint64) *etagHasher {
+	var ps int64
+	if _, optimal, _, e := minio.OptimalPartInfo(size, partSize); e == nil {
+		ps = optimal
+	}
+	return newPartETagHasher(ps)
+}
+
+// newPartETagHasher returns an etagHasher splitting the object in parts
+// of partSize, the part size of an object already uploaded.
+func newPartETagHasher(partSize int64) *etagHasher {
+	return &etagHasher{whole: md5.New(), part: md5.New(), partSize: partSize}
+}
+
+func (h *etagHasher) Write(p []byte) (int, error) {
+	h.whole.Write(p)
+	n := len(p)
+	for len(p) > 0 {
+		b := p
+		if h.partSize > 0 && int64(len(b)) > h.partSize-h.partLen {
+			b = b[:h.partSize-h.partLen]
+		}
+		h.part.Write(b)
+		h.partLen += int64(len(b))
+		p = p[len(b):]
+		if h.partLen == h.partSize {
+			h.endPart()
+		}
+	}
+	return n, nil
+}
+
+func (h *etagHasher) endPart() {
+	h.partSums = h.part.Sum(h.partSums)
+	h.parts++
+	h.part.Reset()
+	h.partLen = 0
+}
+
+// sums returns the MD5 sums of the parts.
+func (h *etagHasher) sums() [][]byte {
+	if h.partLen > 0 {
+		h.endPart()
+	}
+	sums := make([][]byte, h.parts)
+	for i := range sums {
+		sums[i] = h.partSums[i*md5.Size : (i+1)*md5.Size]
+	}
+	return sums
+}
+
+// etag returns the expected single PUT or multipart ETag.
+func (h *etagHasher) etag(multipart bool) string {
+	if !multipart {
+		return hex.EncodeToString(h.whole.Sum(nil))
+	}
+	if h.partLen > 0 {
+		h.endPart()
+	}
+	sum := md5.Sum(h.partSums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), h.parts)
+}
+
+// verify compares the ETag returned by the server with the one computed
+// from the uploaded bytes, it must be called once the upload is done.
+func (h *etagHasher) verify(object, etag string) error {
+	etag = strings.Trim(etag, "\"")
+	expected := h.etag(strings.Contains(etag, "-"))
+	if !strings.EqualFold(etag, expected) {
+		return ChecksumMismatch{Object: object, Expected: expected, Got: etag}
+	}
+	return nil
+}
+
+// etagVerifySkipReason returns why a download described by objStat and
+// opts cannot be verified against its ETag, or "" if it can: only the
+// ETag of a single part, unencrypted object is the MD5 of its content.
+func etagVerifySkipReason(objStat minio.ObjectInfo, opts GetOptions) string {
+	etag := strings.Trim(objStat.ETag, "\"")
+	switch {
+	case opts.RangeStart != 0:
+		return "partial download"
+	case strings.Contains(etag, "-"):
+		return "multipart ETag"
+	case opts.SSE != nil || objStat.Metadata.Get("X-Amz-Server-Side-Encryption") != "" ||
+		objStat.Metadata.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != "":
+		return "encrypted object"
+	}
+	if !isMD5ETag(etag) {
+		return "ETag is not an MD5 sum"
+	}
+	return ""
+}
+
+// isMD5ETag returns true if etag, without quotes, has the form of an MD5
+// sum, unlike multipart ETags.
+func isMD5ETag(etag string) bool {
+	b, e := hex.DecodeString(etag)
+	return e == nil && len(b) == md5.Size
+}
+
+// isMultipartETag returns true if etag, without quotes, has the form of
+// the ETag of a multipart upload, an MD5 sum and the number of parts.
+func isMultipartETag(etag string) bool {
+	sum, parts, ok := strings.Cut(etag, "-")
+	if !ok || !isMD5ETag(sum) {
+		return false
+	}
+	n, e := strconv.Atoi(parts)
+	return e == nil && n > 0
+}
+
+// etagVerifyReader computes the MD5 sum of an object while it is read
+// and compares it with the ETag of the object once size bytes have been
+// read, readers limited to the object size never see io.EOF. A mismatch
+// is reported as a ChecksumMismatch along with the last bytes.
+type etagVerifyReader struct {
+	io.ReadCloser
+	object   string
+	etag     string
+	size     int64
+	read     int64
+	verified bool
+	hash     hash.Hash
+}
+
+func newETagVerifyReader(reader io.ReadCloser, object, etag string, size int64) io.ReadCloser {
+	return &etagVerifyReader{ReadCloser: reader, object: object, etag: strings.Trim(etag, "\""), size: size, hash: md5.New()}
+}
+
+func (r *etagVerifyReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	r.read += int64(n)
+	if !r.verified && (r.read >= r.size || err == io.EOF) {
+		r.verified = true
+		if sum := hex.EncodeToString(r.hash.Sum(nil)); !strings.EqualFold(sum, r.etag) {
+			return n, ChecksumMismatch{Object: r.object, Expected: r.etag, Got: sum}
+		}
+	}
+	return n, err
+}
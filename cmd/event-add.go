@@ -129,6 +129,7 @@ func mainEventAdd(cliCtx *cli.Context) error {
 	if err != nil {
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
+	defer client.Close()
 
 	s3Client, ok := client.(*S3Client)
 	if !ok {
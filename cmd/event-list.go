@@ -119,6 +119,7 @@ func mainEventList(cliCtx *cli.Context) error {
 	if err != nil {
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
+	defer client.Close()
 
 	s3Client, ok := client.(*S3Client)
 	if !ok {
@@ -0,0 +1,54 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"time"
+)
+
+// expectContinueSize is the smallest body sent with Expect: 100-continue,
+// the smallest part size accepted by S3.
+const expectContinueSize = 5 << 20
+
+// expectContinueTimeout is how long a request with Expect: 100-continue
+// waits for the interim response before sending its body anyway, as
+// servers ignoring the header never send one.
+const expectContinueTimeout = time.Second
+
+// expectContinueTransport asks the server to accept the large PUT
+// requests, the uploads of objects and of parts, before their body is
+// sent. A server rejecting the request, for a denied access or an
+// exceeded quota, answers before the upload instead of after it, and
+// http.Transport then returns the response without sending the body.
+type expectContinueTransport struct {
+	transport http.RoundTripper
+}
+
+func newExpectContinueTransport(transport http.RoundTripper) http.RoundTripper {
+	return &expectContinueTransport{transport: transport}
+}
+
+func (t *expectContinueTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPut || req.ContentLength < expectContinueSize || req.Header.Get("Expect") != "" {
+		return t.transport.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Expect", "100-continue")
+	return t.transport.RoundTrip(req)
+}
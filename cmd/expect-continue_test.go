@@ -0,0 +1,85 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExpectContinue(t *testing.T) {
+	var expect atomic.Value
+	var received int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expect.Store(r.Header.Get("Expect"))
+		if r.URL.Path == "/denied/object" {
+			// Rejected without reading the body, like a quota or a policy.
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>")
+			return
+		}
+		n, _ := io.Copy(io.Discard, r.Body)
+		atomic.StoreInt64(&received, n)
+	}))
+	defer server.Close()
+
+	// put sends size bytes to path and returns the response status and
+	// the number of bytes read from the body.
+	put := func(transport http.RoundTripper, path string, size int64) (int, int64) {
+		t.Helper()
+		body := &countingReader{Reader: bytes.NewReader(make([]byte, size))}
+		req, e := http.NewRequest(http.MethodPut, server.URL+path, body)
+		if e != nil {
+			t.Fatal(e)
+		}
+		req.ContentLength = size
+		resp, e := transport.RoundTrip(req)
+		if e != nil {
+			t.Fatal(e)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp.StatusCode, atomic.LoadInt64(&body.n)
+	}
+
+	transport := getTransportForConfig(&Config{HostURL: server.URL}, false)
+	const size = 64 << 20
+	status, sent := put(transport, "/denied/object", size)
+	if status != http.StatusForbidden || expect.Load() != "100-continue" {
+		t.Fatalf("expected a rejected request with Expect: 100-continue, got %d and %q", status, expect.Load())
+	}
+	if sent != 0 {
+		t.Fatalf("expected the body of a rejected request not to be sent, %d bytes were", sent)
+	}
+	// Without the header, the upload goes on until the response arrives.
+	_, sentWithout := put(newBaseTransport(&Config{HostURL: server.URL}), "/denied/object", size)
+	t.Logf("a rejected PUT of %d bytes sent %d bytes without Expect: 100-continue, %d with it", size, sentWithout, sent)
+
+	// An accepted request is sent in full.
+	if status, sent = put(transport, "/bucket/object", size); status != http.StatusOK || sent != size || atomic.LoadInt64(&received) != size {
+		t.Fatalf("expected %d bytes to be accepted, got %d: %d sent and %d received", size, status, sent, atomic.LoadInt64(&received))
+	}
+	// Small requests are sent right away.
+	if put(transport, "/bucket/object", expectContinueSize-1); expect.Load() != "" {
+		t.Fatalf("expected no Expect header on a small request, got %q", expect.Load())
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+	"golang.org/x/term"
+)
+
+var exploreFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "cache-listing",
+		Usage: "list everything under TARGET once and answer 'ls' from memory",
+	},
+	cli.DurationFlag{
+		Name:  "cache-ttl",
+		Usage: "list TARGET again once the cached listing is older than this, 0 to keep it",
+		Value: 5 * time.Minute,
+	},
+}
+
+// Browse a bucket interactively.
+var exploreCmd = cli.Command{
+	Name:         "explore",
+	Usage:        "browse a bucket or a folder interactively",
+	Action:       mainExplore,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(exploreFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Explore reads commands from the standard input, one per line:
+
+    ls [PATH]   list the folder PATH, or the current folder
+    cd [PATH]   change the current folder, to TARGET without PATH
+    pwd         show the current folder
+    refresh     forget the cached listing
+    help        show the commands
+    exit        leave, like the end of the input
+
+  PATH is relative to the current folder, or to TARGET when it starts with "/".
+
+  Every 'ls' lists its folder from the server. With --cache-listing, everything
+  under TARGET is listed once into memory, and 'ls' answers from it at once
+  until the listing is older than --cache-ttl. The memory used grows with the
+  number of objects under TARGET.
+
+EXAMPLES:
+  1. Browse a bucket.
+     {{.Prompt}} {{.HelpName}} s3/mybucket
+
+  2. Browse a large bucket from a listing cached for 30 minutes.
+     {{.Prompt}} {{.HelpName}} --cache-listing --cache-ttl 30m s3/mybucket
+
+  3. List two folders from a script.
+     {{.Prompt}} printf 'ls logs/\nls data/\n' | {{.HelpName}} --cache-listing s3/mybucket
+`,
+}
+
+// exploreFolderMessage is the current folder of explore.
+type exploreFolderMessage struct {
+	Status string `json:"status"`
+	Folder string `json:"folder"`
+}
+
+func (m exploreFolderMessage) String() string {
+	return m.Folder
+}
+
+func (m exploreFolderMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// explorer runs the commands of explore.
+type explorer struct {
+	// root is the explored URL, ending with "/".
+	root string
+	// cwd is the current folder relative to root, "" or ending with "/".
+	cwd string
+	// trie caches the listing of root with --cache-listing.
+	cache bool
+	ttl   time.Duration
+	trie  *listingTrie
+}
+
+func newExplorer(root string, cache bool, ttl time.Duration) *explorer {
+	if !strings.HasSuffix(root, "/") {
+		root += "/"
+	}
+	return &explorer{root: root, cache: cache, ttl: ttl}
+}
+
+// resolve returns the folder of p relative to root, "" or ending with
+// "/". The folders above root are root.
+func (x *explorer) resolve(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + x.cwd + p
+	}
+	folder := strings.TrimPrefix(path.Clean(p), "/")
+	if folder == "" {
+		return ""
+	}
+	return folder + "/"
+}
+
+// list returns the entries of folder, from the cached listing with
+// --cache-listing.
+func (x *explorer) list(ctx context.Context, folder string) ([]listingEntry, *probe.Error) {
+	if !x.cache {
+		clnt, err := newClient(x.root + folder)
+		if err != nil {
+			return nil, err
+		}
+		return listFolder(ctx, clnt)
+	}
+	if x.trie == nil || x.trie.expired(x.ttl) {
+		clnt, err := newClient(x.root)
+		if err != nil {
+			return nil, err
+		}
+		if x.trie, err = buildListingTrie(ctx, clnt); err != nil {
+			return nil, err
+		}
+	}
+	entries, ok := x.trie.list(folder)
+	if !ok {
+		return nil, probe.NewError(PathNotFound{Path: x.root + folder})
+	}
+	return entries, nil
+}
+
+// exec runs a command line, and returns false on exit.
+func (x *explorer) exec(ctx context.Context, line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+	if len(fields) > 2 {
+		errorIf(errInvalidArgument().Trace(fields...), "Too many arguments for `"+fields[0]+"`.")
+		return true
+	}
+	var arg string
+	if len(fields) == 2 {
+		arg = fields[1]
+	}
+	switch fields[0] {
+	case "ls":
+		folder := x.resolve(arg)
+		entries, err := x.list(ctx, folder)
+		if err != nil {
+			errorIf(err.Trace(x.root+folder), "Unable to list `"+x.root+folder+"`.")
+			return true
+		}
+		now := time.Now()
+		for _, entry := range entries {
+			msg := contentMessage{Filetype: "folder", Time: now, Key: entry.name}
+			if entry.content != nil {
+				msg.Filetype = "file"
+				msg.Time = entry.content.Time.Local()
+				msg.Size = entry.content.Size
+				msg.ETag = strings.Trim(entry.content.ETag, "\"")
+				msg.StorageClass = entry.content.StorageClass
+			}
+			printMsg(msg)
+		}
+	case "cd":
+		folder := x.resolve(arg)
+		if x.cache {
+			if _, err := x.list(ctx, folder); err != nil {
+				errorIf(err.Trace(x.root+folder), "Unable to change to `"+x.root+folder+"`.")
+				return true
+			}
+		}
+		x.cwd = folder
+	case "pwd":
+		printMsg(exploreFolderMessage{Folder: x.root + x.cwd})
+	case "refresh":
+		x.trie = nil
+	case "help":
+		console.Println("Commands: ls [PATH], cd [PATH], pwd, refresh, help, exit.")
+	case "exit", "quit":
+		return false
+	default:
+		errorIf(errInvalidArgument().Trace(fields[0]), "Unknown command `"+fields[0]+"`, type help for the commands.")
+	}
+	return true
+}
+
+// run executes the commands read from in until its end or exit, writing
+// a prompt before each to prompt if it is not nil.
+func (x *explorer) run(ctx context.Context, in io.Reader, prompt io.Writer) *probe.Error {
+	scanner := bufio.NewScanner(in)
+	for {
+		if prompt != nil {
+			fmt.Fprintf(prompt, "%s> ", x.root+x.cwd)
+		}
+		if !scanner.Scan() {
+			if prompt != nil {
+				fmt.Fprintln(prompt)
+			}
+			return probe.NewError(scanner.Err())
+		}
+		if !x.exec(ctx, scanner.Text()) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return probe.NewError(ctx.Err())
+		}
+	}
+}
+
+// mainExplore is the entry point of the explore command.
+func mainExplore(cliCtx *cli.Context) error {
+	ctx, cancelExplore := context.WithCancel(globalContext)
+	defer cancelExplore()
+
+	if cliCtx.NArg() != 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code
+	}
+	target := cliCtx.Args().First()
+	if strings.TrimSpace(target) == "" {
+		fatalIf(errInvalidArgument().Trace(target), "Unable to validate empty argument.")
+	}
+	clnt, err := newClient(target)
+	fatalIf(err.Trace(target), "Unable to initialize `"+target+"`.")
+	defer clnt.Close()
+	if _, err = clnt.Stat(ctx, StatOptions{}); err != nil {
+		fatalIf(err.Trace(target), "Unable to explore `"+target+"`.")
+	}
+
+	var prompt io.Writer
+	if term.IsTerminal(int(os.Stdin.Fd())) && !globalJSON {
+		prompt = os.Stdout
+	}
+	x := newExplorer(target, cliCtx.Bool("cache-listing"), cliCtx.Duration("cache-ttl"))
+	fatalIf(x.run(ctx, os.Stdin, prompt), "Unable to read the commands.")
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// filesFromFlags upload the files of a list instead of the arguments.
+var filesFromFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "files-from",
+		Usage: "upload the files listed in this file, one path per line, in their listed order, '-' reads the list from stdin",
+	},
+	cli.StringFlag{
+		Name:  "base-dir",
+		Usage: "folder the relative paths of --files-from are relative to, and the object names are relative to, the current folder by default",
+	},
+	cli.BoolFlag{
+		Name:  "null, 0",
+		Usage: "with --files-from, the listed paths end with a NUL character instead of a newline, as printed by 'find -print0'",
+	},
+	cli.BoolFlag{
+		Name:  "continue-on-error",
+		Usage: "upload the other files when a file cannot be uploaded, exiting with an error status at the end",
+	},
+}
+
+// filesFromEntry is a file of a --files-from list, uploaded as key under
+// the target prefix. err is set when the listed path cannot be uploaded.
+type filesFromEntry struct {
+	path    string
+	key     string
+	content *ClientContent
+	err     *probe.Error
+}
+
+// maxFilesFromEntry is the longest path of a --files-from list, that
+// of PATH_MAX on Linux.
+const maxFilesFromEntry = 4096
+
+// readFilesFrom returns the paths listed in r, one per line, skipping the
+// blank lines and the comments starting with '#'. With null, the paths
+// end with a NUL character instead and are taken as is, so that they may
+// hold any other character. Malformed entries are reported with their
+// byte offset in r.
+func readFilesFrom(r io.Reader, null bool) ([]string, *probe.Error) {
+	delim := byte('\n')
+	if null {
+		delim = 0
+	}
+	// Room for the longest entry, with "\r\n" line endings.
+	br := bufio.NewReaderSize(r, maxFilesFromEntry+2)
+	var paths []string
+	var offset int64
+	for {
+		b, e := br.ReadSlice(delim)
+		if e != nil && e != io.EOF && e != bufio.ErrBufferFull {
+			return nil, probe.NewError(e)
+		}
+		entry := string(b)
+		start := offset
+		offset += int64(len(b))
+		entry = strings.TrimSuffix(entry, string(delim))
+		if !null {
+			entry = strings.TrimSuffix(entry, "\r")
+		}
+		if e == bufio.ErrBufferFull || len(entry) > maxFilesFromEntry {
+			return nil, probe.NewError(fmt.Errorf("the entry at byte offset %d is longer than %d bytes", start, maxFilesFromEntry))
+		}
+		if null {
+			if entry != "" {
+				paths = append(paths, entry)
+			}
+		} else {
+			if i := strings.IndexByte(entry, 0); i >= 0 {
+				return nil, probe.NewError(fmt.Errorf("the entry at byte offset %d has a NUL character at byte offset %d, use --null for NUL-delimited lists", start, start+int64(i)))
+			}
+			if line := strings.TrimSpace(entry); line != "" && !strings.HasPrefix(line, "#") {
+				paths = append(paths, line)
+			}
+		}
+		if e == io.EOF {
+			return paths, nil
+		}
+	}
+}
+
+// loadFilesFrom reads the list at listPath, '-' being stdin, NUL-delimited
+// with null, and resolves each listed path against baseDir, in order.
+func loadFilesFrom(ctx context.Context, listPath, baseDir string, null bool) ([]filesFromEntry, *probe.Error) {
+	var r io.Reader = os.Stdin
+	if listPath != "-" {
+		f, e := os.Open(listPath)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(listPath)
+		}
+		defer f.Close()
+		r = f
+	}
+	paths, err := readFilesFrom(r, null)
+	if err != nil {
+		return nil, err.Trace(listPath)
+	}
+	if len(paths) == 0 {
+		return nil, probe.NewError(errors.New("no file is listed")).Trace(listPath)
+	}
+	base, e := filepath.Abs(baseDir)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(baseDir)
+	}
+	entries := make([]filesFromEntry, 0, len(paths))
+	for _, p := range paths {
+		entries = append(entries, resolveFilesFromEntry(ctx, base, p))
+	}
+	return entries, nil
+}
+
+// resolveFilesFromEntry stats the listed path p, relative to base unless
+// absolute. Its object name is its path relative to base, so a path out
+// of base is an error.
+func resolveFilesFromEntry(ctx context.Context, base, p string) filesFromEntry {
+	entry := filesFromEntry{path: p}
+	source := p
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(base, source)
+	}
+	rel, e := filepath.Rel(base, source)
+	if e != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		entry.err = probe.NewError(fmt.Errorf("`%s` is not a file under the base folder `%s`", p, base)).Trace(p)
+		return entry
+	}
+	entry.key = filepath.ToSlash(rel)
+
+	_, content, err := url2Stat(ctx, url2StatOptions{urlStr: source})
+	if err != nil {
+		entry.err = err.Trace(p)
+		return entry
+	}
+	if err = checkPutSource(p, content); err != nil {
+		entry.err = err.Trace(p)
+		return entry
+	}
+	entry.content = content
+	return entry
+}
+
+// invalidFilesFrom returns the entries which cannot be uploaded.
+func invalidFilesFrom(entries []filesFromEntry) []filesFromEntry {
+	var invalid []filesFromEntry
+	for _, entry := range entries {
+		if entry.err != nil {
+			invalid = append(invalid, entry)
+		}
+	}
+	return invalid
+}
+
+// prepareFilesFromURLs returns the URLs of the files of a --files-from
+// list in their listed order, each uploaded under the target prefix as
+// its path relative to the base folder. Invalid entries are sent as
+// errors at their place in the list.
+func prepareFilesFromURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
+	urlsCh := make(chan URLs)
+	go func() {
+		defer close(urlsCh)
+		targetAlias, targetURL, _ := mustExpandAlias(o.targetURL)
+		for _, entry := range o.filesFrom {
+			urls := URLs{Error: entry.err}
+			if entry.err == nil {
+				urls = makeCopyContentTypeA(copyURLsContent{
+					sourceContent: entry.content,
+					targetAlias:   targetAlias,
+					targetURL:     urlJoinPath(targetURL, entry.key),
+				})
+			}
+			select {
+			case urlsCh <- urls:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return urlsCh
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestReadFilesFrom(t *testing.T) {
+	list := "# generated by the data preparation step\n\nz.txt\n  a/b.txt  \r\n# a/skipped.txt\n/abs/c.txt\n"
+	paths, err := readFilesFrom(strings.NewReader(list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"z.txt", "a/b.txt", "/abs/c.txt"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestReadFilesFromNull(t *testing.T) {
+	list := "line\nbreak.npz\x00 spaced \x00\x00# not a comment\x00last"
+	paths, err := readFilesFrom(strings.NewReader(list), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"line\nbreak.npz", " spaced ", "# not a comment", "last"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %q, got %q", expected, paths)
+	}
+}
+
+func TestReadFilesFromMalformed(t *testing.T) {
+	long := strings.Repeat("x", maxFilesFromEntry+1)
+	testCases := []struct {
+		list  string
+		null  bool
+		error string
+	}{
+		{"a.txt\nb\x00c.txt\n", false, "the entry at byte offset 6 has a NUL character at byte offset 7, use --null for NUL-delimited lists"},
+		{"a.txt\x00" + long + "\x00", true, fmt.Sprintf("the entry at byte offset 6 is longer than %d bytes", maxFilesFromEntry)},
+		{"a.txt\n" + long, false, fmt.Sprintf("the entry at byte offset 6 is longer than %d bytes", maxFilesFromEntry)},
+	}
+	for i, testCase := range testCases {
+		_, err := readFilesFrom(strings.NewReader(testCase.list), testCase.null)
+		if err == nil || err.ToGoError().Error() != testCase.error {
+			t.Errorf("test %d: expected the error %q, got %v", i+1, testCase.error, err)
+		}
+	}
+	// The longest entry is still accepted, with its line ending.
+	paths, err := readFilesFrom(strings.NewReader(long[1:]+"\r\n"), false)
+	if err != nil || len(paths) != 1 || len(paths[0]) != maxFilesFromEntry {
+		t.Fatalf("expected one entry of %d bytes, got %d entries, %v", maxFilesFromEntry, len(paths), err)
+	}
+}
+
+func TestFilesFrom(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	t.Setenv(mcEnvHostPrefix+"list", server.AliasURL())
+	server.MakeBucket("bucket")
+
+	base := t.TempDir()
+	for _, name := range []string{"z.txt", "a/b.txt", "a/c/d.txt"} {
+		path := filepath.Join(base, filepath.FromSlash(name))
+		if e := os.MkdirAll(filepath.Dir(path), 0o700); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(name), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if e := os.WriteFile(outside, []byte("outside"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	list := filepath.Join(t.TempDir(), "files.txt")
+	content := strings.Join([]string{
+		"# listed out of order on purpose",
+		"z.txt",
+		"missing.txt",
+		filepath.Join(base, "a", "c", "d.txt"),
+		"",
+		"a",
+		outside,
+		"a/b.txt",
+	}, "\n")
+	if e := os.WriteFile(list, []byte(content), 0o600); e != nil {
+		t.Fatal(e)
+	}
+
+	ctx := context.Background()
+	entries, err := loadFilesFrom(ctx, list, base, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys, invalid []string
+	for _, entry := range entries {
+		keys = append(keys, entry.key)
+	}
+	for _, entry := range invalidFilesFrom(entries) {
+		invalid = append(invalid, entry.path)
+	}
+	if expected := []string{"z.txt", "missing.txt", "a/c/d.txt", "a", "", "a/b.txt"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected the keys %q, got %q", expected, keys)
+	}
+	if expected := []string{"missing.txt", "a", outside}; !reflect.DeepEqual(invalid, expected) {
+		t.Fatalf("expected the invalid entries %q, got %q", expected, invalid)
+	}
+
+	// The invalid entries come at their place in the list, the others
+	// are uploaded in the listed order.
+	var errs int
+	for urls := range prepareFilesFromURLs(ctx, prepareCopyURLsOpts{targetURL: "list/bucket/prefix/", filesFrom: entries}) {
+		if urls.Error != nil {
+			errs++
+			continue
+		}
+		if urls = doCopy(ctx, doCopyOpts{cpURLs: urls, pg: newAccounter(0)}); urls.Error != nil {
+			t.Fatal(urls.Error)
+		}
+	}
+	if errs != 3 {
+		t.Fatalf("expected 3 invalid entries, got %d", errs)
+	}
+	var uploaded []string
+	for _, r := range server.Requests() {
+		if r.Method == "PUT" && r.Key != "" {
+			uploaded = append(uploaded, r.Key)
+		}
+	}
+	if expected := []string{"prefix/z.txt", "prefix/a/c/d.txt", "prefix/a/b.txt"}; !reflect.DeepEqual(uploaded, expected) {
+		t.Fatalf("expected the uploads %q, got %q", expected, uploaded)
+	}
+	if o, ok := server.Object("bucket", "prefix/a/c/d.txt"); !ok || string(o.Data) != "a/c/d.txt" {
+		t.Fatal("expected a/c/d.txt to be uploaded")
+	}
+}
@@ -65,6 +65,7 @@ var (
 			Name:  "print",
 			Usage: "print in custom format to STDOUT (see FORMAT)",
 		},
+		print0Flag,
 		cli.StringFlag{
 			Name:  "regex",
 			Usage: "match directory and object name with RE2 regex pattern",
@@ -102,7 +103,7 @@ var findCmd = cli.Command{
 	Action:       mainFind,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(findFlags, globalFlags...),
+	Flags:        append(append(append(append(append(findFlags, humanizeTimeFlags...), listLimitFlags...), csvFlags...), compatFlag), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -117,7 +118,8 @@ UNITS
   suffixes such as "k", "m", "g" and "t" referring to the metric units KB,
   MB, GB and TB respectively. Adding an "i" to these prefixes, uses the IEC
   units, so that "gi" refers to "gibibyte" or "GiB". A "b" at the end is
-  also accepted. Without suffixes the unit is bytes.
+  also accepted. Without suffixes the unit is bytes. They are parsed the
+  same regardless of --units, which only changes how sizes are printed.
 
   --older-than, --newer-than flags accept the string for days, hours and minutes 
   i.e. 1d2h30m states 1 day, 2 hours and 30 minutes.
@@ -130,13 +132,18 @@ FORMAT
      {base}    --> Substitutes to basename of path.
      {dir}     --> Substitutes to dirname of the path.
      {size}    --> Substitutes to object size of the path.
-     {time}    --> Substitutes to object modified time of the path.
+     {time}    --> Substitutes to object modified time of the path, as "3 days ago"
+                   with --humanize-time.
      {version} --> Substitutes to object version identifier.
 
   Keywords supported if target is object storage:
 
      {url} --> Substitutes to a shareable URL of the path.
 
+  The global --format flag instead takes a Go template, which may use the same
+  fields as 'ls': Status, Filetype, Time, Size, Key, ETag, URL, VersionID,
+  VersionOrd, VersionIndex, IsDeleteMarker, StorageClass, Metadata and Tags.
+
 EXAMPLES:
   01. Find all "foo.jpg" in all buckets under "s3" account.
       {{.Prompt}} {{.HelpName}} s3 --name "foo.jpg"
@@ -171,11 +178,26 @@ EXAMPLES:
 
   11. Copy all versions of all objects in bucket in the local machine
       {{.Prompt}} {{.HelpName}} s3/bucket --versions --exec "mc cp --version-id {version} {} /tmp/dir/{}.{version}"
+
+  12. Print the objects under "s3/bucket" with how long ago they were modified, or the date if before last week.
+      {{.Prompt}} {{.HelpName}} s3/bucket --humanize-time --humanize-time-max 7d --print "{time} {}"
+
+  13. Find the first 100 jpeg images under "s3/bucket", then resume the search from the key printed by the first one.
+      {{.Prompt}} {{.HelpName}} s3/bucket --name "*.jpg" --limit 100
+      {{.Prompt}} {{.HelpName}} s3/bucket --name "*.jpg" --limit 100 --start-after "photos/0411.jpg"
+
+  14. Upload all the ".npz" files of a local folder, whatever their names hold, to "s3/bucket/arrays".
+      {{.Prompt}} {{.HelpName}} ./data --name "*.npz" --print0 | mc put --files-from - --null s3/bucket/arrays/
+
+  15. Find all ".csv" objects under "s3/bucket" and print them like 'rclone lsjson'.
+      {{.Prompt}} {{.HelpName}} s3/bucket --name "*.csv" --compat rclone
 `,
 }
 
 // checkFindSyntax - validate the passed arguments
 func checkFindSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
+	fatalIf(probe.NewError(parseHumanizeTimeFlags(cliCtx)), "Unable to parse --humanize-time.")
+
 	args := cliCtx.Args()
 	if !args.Present() {
 		args = []string{"./"} // No args just default to present directory.
@@ -189,6 +211,14 @@ func checkFindSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 		}
 	}
 
+	checkPrint0Syntax(cliCtx)
+	if cliCtx.Bool("print0") && cliCtx.String("exec") != "" {
+		fatalIf(errInvalidArgument().Trace(args...), "--print0 cannot be used with --exec.")
+	}
+	if globalCompat != nil && (cliCtx.String("exec") != "" || cliCtx.String("print") != "" || cliCtx.Bool("watch")) {
+		fatalIf(errInvalidArgument().Trace(args...), "--compat cannot be used with --exec, --print or --watch.")
+	}
+
 	// Extract input URLs and validate.
 	for _, url := range args {
 		_, _, err := url2Stat(ctx, url2StatOptions{urlStr: url, versionID: "", fileAttr: false, encKeyDB: encKeyDB, timeRef: time.Time{}, isZip: false, ignoreBucketExistsCheck: false})
@@ -214,6 +244,7 @@ type findContext struct {
 	regexPattern      *regexp.Regexp
 	maxDepth          uint
 	printFmt          string
+	print0            bool
 	olderThan         string
 	newerThan         string
 	largerSize        uint64
@@ -222,6 +253,7 @@ type findContext struct {
 	withOlderVersions bool
 	matchMeta         map[string]*regexp.Regexp
 	matchTags         map[string]*regexp.Regexp
+	limit             *listLimit
 
 	// Internal values
 	targetAlias   string
@@ -254,6 +286,7 @@ func mainFind(cliCtx *cli.Context) error {
 
 	clnt, err := newClient(args[0])
 	fatalIf(err.Trace(args...), "Unable to initialize `"+args[0]+"`.")
+	defer clnt.Close()
 
 	var olderThan, newerThan string
 
@@ -282,6 +315,13 @@ func mainFind(cliCtx *cli.Context) error {
 	// Get --versions flag
 	withVersions := cliCtx.Bool("versions")
 
+	limit, e := parseListLimitFlags(cliCtx)
+	fatalIf(probe.NewError(e), "Invalid --limit.")
+	if limit.limit > 0 && cliCtx.Bool("watch") {
+		fatalIf(errInvalidArgument(), "--limit cannot be used with --watch.")
+	}
+	fatalIf(probe.NewError(limit.checkTarget(clnt)).Trace(args[0]), "Unable to resume the listing of `"+args[0]+"`.")
+
 	targetAlias, _, hostCfg, err := expandAlias(args[0])
 	fatalIf(err.Trace(args[0]), "Unable to expand alias.")
 
@@ -294,11 +334,12 @@ func mainFind(cliCtx *cli.Context) error {
 		regMatch = regexp.MustCompile(cliCtx.String("regex"))
 	}
 
-	return doFind(ctx, &findContext{
+	e = doFind(ctx, &findContext{
 		Context:           cliCtx,
 		maxDepth:          cliCtx.Uint("maxdepth"),
 		execCmd:           cliCtx.String("exec"),
 		printFmt:          cliCtx.String("print"),
+		print0:            cliCtx.Bool("print0"),
 		namePattern:       cliCtx.String("name"),
 		pathPattern:       cliCtx.String("path"),
 		regexPattern:      regMatch,
@@ -315,5 +356,8 @@ func mainFind(cliCtx *cli.Context) error {
 		clnt:              clnt,
 		matchMeta:         getRegexMap(cliCtx, "metadata"),
 		matchTags:         getRegexMap(cliCtx, "tags"),
+		limit:             limit,
 	})
+	closeCompatOutput()
+	return e
 }
@@ -30,7 +30,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/dustin/go-humanize"
 	"github.com/google/shlex"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
@@ -259,6 +258,16 @@ func find(ctxCtx context.Context, ctx *findContext, fileContent contentMessage)
 	if ctx.printFmt != "" {
 		fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
 	}
+	printFind(ctx, fileContent)
+}
+
+// printFind prints a matching object, only its name followed by a NUL
+// character with --print0.
+func printFind(ctx *findContext, fileContent contentMessage) {
+	if ctx.print0 {
+		printNul(fileContent.Key)
+		return
+	}
 	printMsg(findMessage{fileContent})
 }
 
@@ -278,8 +287,17 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 		WithMetadata:      len(ctx.matchMeta) > 0 || len(ctx.matchTags) > 0,
 	}
 
+	// Results are filtered after listing, the listing requests keep
+	// their default size and only start after --start-after.
+	if ctx.limit != nil {
+		lstOptions.StartAfter = ctx.limit.startAfter
+	}
+	// Stopping at --limit cancels the listing requests left.
+	listCtx, cancelList := context.WithCancel(globalContext)
+	defer cancelList()
+
 	// iterate over all content which is within the given directory
-	for content := range ctx.clnt.List(globalContext, lstOptions) {
+	for content := range ctx.clnt.List(listCtx, lstOptions) {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
 			// handle this specifically for filesystem related errors.
@@ -318,6 +336,11 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 			continue
 		} // For all matching content
 
+		if !ctx.limit.next(contentKey(content)) {
+			cancelList()
+			break
+		}
+
 		// proceed to either exec, format the output string.
 		if ctx.execCmd != "" {
 			execFind(ctxCtx, ctx.execCmd, fileContent)
@@ -327,8 +350,9 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 			fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
 		}
 
-		printMsg(findMessage{fileContent})
+		printFind(ctx, fileContent)
 	}
+	ctx.limit.printCheckpoint()
 
 	// Success, notice watch will execute in defer only if enabled and this call
 	// will return after watch is canceled.
@@ -359,16 +383,16 @@ func stringsReplace(ctx context.Context, args string, fileContent contentMessage
 	str = strings.ReplaceAll(str, `{"dir"}`, strconv.Quote(filepath.Dir(fileContent.Key)))
 
 	// replace all instances of {size}
-	str = strings.ReplaceAll(str, "{size}", humanize.IBytes(uint64(fileContent.Size)))
+	str = strings.ReplaceAll(str, "{size}", formatSize(fileContent.Size))
 
 	// replace all instances of {"size"}
-	str = strings.ReplaceAll(str, `{"size"}`, strconv.Quote(humanize.IBytes(uint64(fileContent.Size))))
+	str = strings.ReplaceAll(str, `{"size"}`, strconv.Quote(formatSize(fileContent.Size)))
 
 	// replace all instances of {time}
-	str = strings.ReplaceAll(str, "{time}", fileContent.Time.Format(printDate))
+	str = strings.ReplaceAll(str, "{time}", formatListTime(fileContent.Time))
 
 	// replace all instances of {"time"}
-	str = strings.ReplaceAll(str, `{"time"}`, strconv.Quote(fileContent.Time.Format(printDate)))
+	str = strings.ReplaceAll(str, `{"time"}`, strconv.Quote(formatListTime(fileContent.Time)))
 
 	// replace all instances of {url}
 	if strings.Contains(str, "{url}") {
@@ -0,0 +1,231 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/pkg/v2/console"
+)
+
+// Every flag can be set from the environment, with a variable named after
+// the command and the flag: MC_PUT_PART_SIZE sets `mc put --part-size`
+// and MC_ADMIN_INFO_OFFLINE sets `mc admin info --offline`. The global
+// flags keep their shorter variables, e.g. MC_LIMIT_UPLOAD. A flag given
+// on the command line wins over its variable, which wins over the
+// default of the flag.
+
+// flagEnvValue is a flag of the running command set from the environment.
+type flagEnvValue struct {
+	Flag   string
+	EnvVar string
+	Value  string
+}
+
+// globalFlagEnv are the flags of the running command set from the
+// environment, printed with --debug.
+var globalFlagEnv []flagEnvValue
+
+// flagEnvVar returns the environment variable of the flag name of the
+// command at path, e.g. MC_PUT_PART_SIZE for "put" and "part-size".
+func flagEnvVar(path []string, name string) string {
+	words := append(append([]string{}, path...), name)
+	return envPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(strings.Join(words, "_")))
+}
+
+// flagName returns the first name of f, e.g. "start-after" for
+// "start-after, marker".
+func flagName(f cli.Flag) string {
+	return strings.TrimSpace(strings.Split(f.GetName(), ",")[0])
+}
+
+// flagEnv returns the environment variable of f, if any.
+func flagEnv(f cli.Flag) string {
+	if field := flagValue(f).FieldByName("EnvVar"); field.IsValid() {
+		return field.String()
+	}
+	return ""
+}
+
+// withFlagEnv returns a copy of f reading its value from envVar, none if
+// envVar is empty.
+func withFlagEnv(f cli.Flag, envVar string) cli.Flag {
+	if reflect.TypeOf(f).Kind() != reflect.Struct {
+		return f
+	}
+	v := reflect.New(reflect.TypeOf(f)).Elem()
+	v.Set(reflect.ValueOf(f))
+	if field := v.FieldByName("EnvVar"); field.IsValid() && field.CanSet() {
+		field.SetString(envVar)
+	}
+	return v.Interface().(cli.Flag)
+}
+
+// setFlagEnvVars returns a copy of commands where every flag, of the
+// commands and of their subcommands, has an environment variable. path
+// are the names of the parent commands.
+func setFlagEnvVars(commands []cli.Command, path []string) []cli.Command {
+	if commands == nil {
+		return nil
+	}
+	named := make([]cli.Command, len(commands))
+	for i, cmd := range commands {
+		cmdPath := append(append([]string{}, path...), cmd.Name)
+		flags := make([]cli.Flag, len(cmd.Flags))
+		for j, f := range cmd.Flags {
+			if flagEnv(f) == "" {
+				f = withFlagEnv(f, flagEnvVar(cmdPath, flagName(f)))
+			}
+			flags[j] = f
+		}
+		cmd.Flags = flags
+		cmd.Subcommands = setFlagEnvVars(cmd.Subcommands, cmdPath)
+		named[i] = cmd
+	}
+	return named
+}
+
+// givenFlags returns the names of the flags in args.
+func givenFlags(args []string) map[string]bool {
+	given := map[string]bool{}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "-" || !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		given[name] = true
+	}
+	return given
+}
+
+// parseFlagEnv parses the value of the environment variable of f like
+// the flag parser parses the flag, so that both fail with the same error.
+// Sizes and durations of string flags are parsed by the commands, from
+// either.
+func parseFlagEnv(f cli.Flag, value string) error {
+	set := flag.NewFlagSet(flagName(f), flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	withFlagEnv(f, "").Apply(set)
+
+	values := []string{value}
+	switch f.(type) {
+	case cli.StringSliceFlag, cli.IntSliceFlag, cli.Int64SliceFlag:
+		// Like the flag, its variable is a list split on commas.
+		values = strings.Split(value, ",")
+	}
+	for _, v := range values {
+		if e := set.Parse([]string{"-" + flagName(f) + "=" + strings.TrimSpace(v)}); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// checkFlagEnv checks the environment variables of the flags of app and
+// of the command run by args, and returns the flags they set. The flags
+// given in args ignore their variable, as do all flags if it is empty.
+func checkFlagEnv(app *cli.App, args []string) ([]flagEnvValue, error) {
+	flagSets := []*[]cli.Flag{&app.Flags}
+	commands := app.Commands
+	rest := args[1:]
+	for len(commands) > 0 {
+		positional := positionalArgs(rest, *flagSets[len(flagSets)-1])
+		if len(positional) == 0 {
+			break
+		}
+		i := positional[0]
+		found := -1
+		for j := range commands {
+			if commands[j].HasName(rest[i]) {
+				found = j
+				break
+			}
+		}
+		if found < 0 {
+			break
+		}
+		flagSets = append(flagSets, &commands[found].Flags)
+		rest = rest[i+1:]
+		commands = commands[found].Subcommands
+	}
+
+	given := givenFlags(args[1:])
+	seen := map[string]bool{}
+	var values []flagEnvValue
+	for _, flags := range flagSets {
+		for j, f := range *flags {
+			envVar := flagEnv(f)
+			value, ok := os.LookupEnv(envVar)
+			if envVar == "" || !ok {
+				continue
+			}
+			isGiven := false
+			eachFlagName(f, func(name string) { isGiven = isGiven || given[name] })
+			if isGiven || value == "" {
+				(*flags)[j] = withFlagEnv(f, "")
+				continue
+			}
+			if e := parseFlagEnv(f, value); e != nil {
+				return nil, fmt.Errorf("%w (from %s)", e, envVar)
+			}
+			if _, ok := f.(cli.BoolFlag); ok {
+				// A false variable leaves the flag unset, which the
+				// commands checking whether it is set expect.
+				if b, _ := strconv.ParseBool(value); !b {
+					(*flags)[j] = withFlagEnv(f, "")
+				}
+			}
+			if !seen[envVar] {
+				seen[envVar] = true
+				values = append(values, flagEnvValue{Flag: flagName(f), EnvVar: envVar, Value: value})
+			}
+		}
+	}
+	return values, nil
+}
+
+// eachFlagName calls fn with every name of f.
+func eachFlagName(f cli.Flag, fn func(string)) {
+	for _, name := range strings.Split(f.GetName(), ",") {
+		fn(strings.TrimSpace(name))
+	}
+}
+
+// printFlagEnv prints the flags set from the environment once, hiding
+// the values of keys and secrets.
+func printFlagEnv() {
+	for _, v := range globalFlagEnv {
+		value := v.Value
+		name := strings.ToLower(v.Flag)
+		if strings.Contains(name, "key") || strings.Contains(name, "secret") || strings.Contains(name, "password") || strings.Contains(name, "token") {
+			value = "<hidden>"
+		}
+		console.Debugln(fmt.Sprintf("--%s=%q from %s", v.Flag, value, v.EnvVar))
+	}
+	globalFlagEnv = nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minio/cli"
+)
+
+func TestSetFlagEnvVars(t *testing.T) {
+	commands := []cli.Command{{
+		Name: "put",
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "part-size"},
+			cli.BoolFlag{Name: "debug", EnvVar: "MC_DEBUG"},
+		},
+		Subcommands: []cli.Command{{
+			Name:  "part",
+			Flags: []cli.Flag{cli.IntFlag{Name: "part-number, n"}},
+		}},
+	}}
+
+	named := setFlagEnvVars(commands, nil)
+	for _, testCase := range []struct {
+		flag     cli.Flag
+		expected string
+	}{
+		{named[0].Flags[0], "MC_PUT_PART_SIZE"},
+		{named[0].Flags[1], "MC_DEBUG"},
+		{named[0].Subcommands[0].Flags[0], "MC_PUT_PART_PART_NUMBER"},
+	} {
+		if got := flagEnv(testCase.flag); got != testCase.expected {
+			t.Fatalf("%s: expected %s, got %s", testCase.flag.GetName(), testCase.expected, got)
+		}
+	}
+	if flagEnv(commands[0].Flags[0]) != "" {
+		t.Fatal("expected the commands to be left untouched")
+	}
+}
+
+func TestCheckFlagEnv(t *testing.T) {
+	run := func(args ...string) (parallel int, recursive bool, err error) {
+		app := cli.NewApp()
+		app.Commands = setFlagEnvVars([]cli.Command{{
+			Name: "put",
+			Flags: []cli.Flag{
+				cli.IntFlag{Name: "parallel, P", Value: 4},
+				cli.BoolFlag{Name: "recursive, r"},
+			},
+			Action: func(ctx *cli.Context) error {
+				parallel, recursive = ctx.Int("parallel"), ctx.IsSet("recursive")
+				return nil
+			},
+		}}, nil)
+		args = append([]string{"mc", "put"}, args...)
+		if globalFlagEnv, err = checkFlagEnv(app, args); err != nil {
+			return 0, false, err
+		}
+		return parallel, recursive, app.Run(args)
+	}
+
+	if parallel, _, e := run(); e != nil || parallel != 4 {
+		t.Fatalf("expected the default, got %d %v", parallel, e)
+	}
+
+	t.Setenv("MC_PUT_PARALLEL", "8")
+	if parallel, _, e := run(); e != nil || parallel != 8 {
+		t.Fatalf("expected the environment, got %d %v", parallel, e)
+	}
+	if len(globalFlagEnv) != 1 || globalFlagEnv[0] != (flagEnvValue{Flag: "parallel", EnvVar: "MC_PUT_PARALLEL", Value: "8"}) {
+		t.Fatalf("unexpected flags from the environment %v", globalFlagEnv)
+	}
+	if parallel, _, e := run("-P", "2"); e != nil || parallel != 2 {
+		t.Fatalf("expected the command line, got %d %v", parallel, e)
+	}
+
+	// Values fail as they would on the command line.
+	t.Setenv("MC_PUT_PARALLEL", "eight")
+	if _, _, e := run(); e == nil || !strings.HasPrefix(e.Error(), `invalid value "eight" for flag -parallel: parse error`) || !strings.Contains(e.Error(), "MC_PUT_PARALLEL") {
+		t.Fatalf("unexpected error %v", e)
+	}
+	if parallel, _, e := run("--parallel=3"); e != nil || parallel != 3 {
+		t.Fatalf("expected the command line to win over an invalid variable, got %d %v", parallel, e)
+	}
+	t.Setenv("MC_PUT_PARALLEL", "")
+
+	t.Setenv("MC_PUT_RECURSIVE", "yes")
+	if _, _, e := run(); e == nil || !strings.HasPrefix(e.Error(), `invalid boolean value "yes" for -recursive: parse error`) {
+		t.Fatalf("unexpected error %v", e)
+	}
+	t.Setenv("MC_PUT_RECURSIVE", "false")
+	if _, recursive, e := run("--parallel=1"); e != nil || recursive {
+		t.Fatalf("expected a false variable to leave the flag unset, got %v %v", recursive, e)
+	}
+	t.Setenv("MC_PUT_RECURSIVE", "true")
+	if _, recursive, e := run("--parallel=1"); e != nil || !recursive {
+		t.Fatalf("expected the flag to be set, got %v %v", recursive, e)
+	}
+}
@@ -49,6 +49,16 @@ var globalFlags = []cli.Flag{
 		Usage:  "disable color theme",
 		EnvVar: envPrefix + "NO_COLOR",
 	},
+	cli.StringFlag{
+		Name:   "theme",
+		Usage:  "color theme of ls and diff: default, high-contrast or none",
+		EnvVar: envPrefix + "THEME",
+	},
+	cli.StringFlag{
+		Name:   "units",
+		Usage:  "print sizes in iec (GiB), si (GB) or raw bytes",
+		EnvVar: envPrefix + "UNITS",
+	},
 	cli.BoolFlag{
 		Name:   "json",
 		Usage:  "enable JSON lines formatted output",
@@ -64,16 +74,131 @@ var globalFlags = []cli.Flag{
 		Usage:  "disable SSL certificate verification",
 		EnvVar: envPrefix + "INSECURE",
 	},
+	cli.BoolFlag{
+		Name:   "http1",
+		Usage:  "only offer HTTP/1.1 to storage endpoints",
+		EnvVar: envPrefix + "HTTP1",
+	},
+	cli.BoolFlag{
+		Name:   "http2",
+		Usage:  "negotiate HTTP/2 with storage endpoints over TLS, unless --http1 is set",
+		EnvVar: envPrefix + "HTTP2",
+	},
+	cli.BoolFlag{
+		Name:   "sync-time",
+		Usage:  "sign requests with the clock of the server, read from one request, when the local clock is skewed",
+		EnvVar: envPrefix + "SYNC_TIME",
+	},
 	cli.StringFlag{
 		Name:   "limit-upload",
-		Usage:  "limits uploads to a maximum rate in KiB/s, MiB/s, GiB/s. (default: unlimited)",
+		Usage:  "limits uploads to a maximum rate in KiB/s, MiB/s, GiB/s, shared by all the concurrent uploads. (default: unlimited)",
 		EnvVar: envPrefix + "LIMIT_UPLOAD",
 	},
 	cli.StringFlag{
 		Name:   "limit-download",
-		Usage:  "limits downloads to a maximum rate in KiB/s, MiB/s, GiB/s. (default: unlimited)",
+		Usage:  "limits downloads to a maximum rate in KiB/s, MiB/s, GiB/s, shared by all the concurrent downloads. (default: unlimited)",
 		EnvVar: envPrefix + "LIMIT_DOWNLOAD",
 	},
+	cli.IntFlag{
+		Name:   "max-host-conns",
+		Usage:  "limits connections per host, excess requests are queued. (default: unlimited)",
+		EnvVar: envPrefix + "MAX_HOST_CONNS",
+	},
+	cli.Float64Flag{
+		Name:   "req-limit",
+		Usage:  "limits requests to a maximum rate per second across all workers. (default: unlimited)",
+		EnvVar: envPrefix + "REQ_LIMIT",
+	},
+	cli.IntFlag{
+		Name:   "req-burst",
+		Usage:  "number of requests allowed in a burst above --req-limit. (default: the per-second rate)",
+		EnvVar: envPrefix + "REQ_BURST",
+	},
+	cli.StringFlag{
+		Name:   "credentials-file",
+		Usage:  "read the endpoint and credentials from a JSON file instead of logging in with auth",
+		EnvVar: envPrefix + "CREDENTIALS_FILE",
+	},
+	cli.StringFlag{
+		Name:   "credential-helper",
+		Usage:  "run this command to get the endpoint and credentials as JSON, and again when they expire, instead of logging in with auth",
+		EnvVar: envPrefix + "CREDENTIAL_HELPER",
+	},
+	cli.StringFlag{
+		Name:   "signing-region",
+		Usage:  "sign gpumall requests for this region instead of the region of the credentials",
+		EnvVar: envPrefix + "SIGNING_REGION",
+	},
+	cli.StringFlag{
+		Name:   "log-file",
+		Usage:  "append a JSON line per transferred or failed object to this file",
+		EnvVar: envPrefix + "LOG_FILE",
+	},
+	cli.StringFlag{
+		Name:   "trace-file",
+		Usage:  "append a JSON line per HTTP request and response to this file, with the credentials redacted and the bodies truncated",
+		EnvVar: envPrefix + "TRACE_FILE",
+	},
+	cli.StringFlag{
+		Name:   "replay-file",
+		Usage:  "answer the HTTP requests with the responses recorded by --trace-file in this file instead of sending them",
+		EnvVar: envPrefix + "REPLAY_FILE",
+	},
+	cli.StringFlag{
+		Name:   "format",
+		Usage:  "print every listed object with a Go template, e.g. '{{.Key}}\\t{{humanize .Size}}'",
+		EnvVar: envPrefix + "FORMAT",
+	},
+	cli.StringFlag{
+		Name:   "temp-dir",
+		Usage:  "buffer streams of unknown size in a file of this folder and upload them with their size, instead of in memory",
+		EnvVar: envPrefix + "TEMP_DIR",
+	},
+	cli.DurationFlag{
+		Name:   "stall-timeout",
+		Usage:  "retry transfers which make no progress for this long on a new connection, 0 disables it",
+		Value:  defaultStallTimeout,
+		EnvVar: envPrefix + "STALL_TIMEOUT",
+	},
+	cli.DurationFlag{
+		Name:   "tcp-keepalive",
+		Usage:  "send TCP keep-alive probes on idle connections this often, so that dead connections are dropped before they are reused, 0 disables them",
+		Value:  defaultTCPKeepAlive,
+		EnvVar: envPrefix + "TCP_KEEPALIVE",
+	},
+	cli.DurationFlag{
+		Name:   "max-retry-time",
+		Usage:  "maximum time spent waiting on a server which is throttling requests",
+		Value:  defaultMaxRetryTime,
+		EnvVar: envPrefix + "MAX_RETRY_TIME",
+	},
+	cli.DurationFlag{
+		Name:   "retry-base",
+		Usage:  "wait before the first retry of a failed transfer, doubled on every retry, 0 keeps the default of 1s",
+		EnvVar: envPrefix + "RETRY_BASE",
+	},
+	cli.DurationFlag{
+		Name:   "retry-cap",
+		Usage:  "maximum wait in between two retries of a failed transfer",
+		Value:  defaultRetryCap,
+		EnvVar: envPrefix + "RETRY_CAP",
+	},
+	cli.Float64Flag{
+		Name:   "retry-jitter",
+		Usage:  "fraction of the wait in between two retries which is randomized, from 0 to 1",
+		Value:  maxJitter,
+		EnvVar: envPrefix + "RETRY_JITTER",
+	},
+	cli.DurationFlag{
+		Name:   "request-timeout",
+		Usage:  "cancel requests which take longer than this, including the transfer of their body, 0 disables it",
+		EnvVar: envPrefix + "REQUEST_TIMEOUT",
+	},
+	cli.DurationFlag{
+		Name:   "deadline",
+		Usage:  "abort the command after this long, exiting with status 124, 0 disables it",
+		EnvVar: envPrefix + "DEADLINE",
+	},
 	cli.DurationFlag{
 		Name:   "conn-read-deadline",
 		Usage:  "custom connection READ deadline",
@@ -95,6 +220,11 @@ var ioFlags = []cli.Flag{
 		Usage:  "encrypt/decrypt objects (using server-side encryption with customer provided keys)",
 		EnvVar: envPrefix + "ENCRYPT_KEY",
 	},
+	cli.StringFlag{
+		Name:   "encrypt-key-file",
+		Usage:  "load the prefix=secret keys of --encrypt-key from a file, one per line, taking precedence for the same prefix",
+		EnvVar: envPrefix + "ENCRYPT_KEY_FILE",
+	},
 	cli.StringFlag{
 		Name:   "encrypt",
 		Usage:  "encrypt/decrypt objects (using server-side encryption with server managed keys)",
@@ -0,0 +1,113 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// recordMessage is a message printed once per listed object, such
+// messages are rendered with the --format template when one is set,
+// or as the rows of --csv and --tsv.
+type recordMessage interface {
+	message
+	isRecord()
+}
+
+func (contentMessage) isRecord() {}
+
+func (statMessage) isRecord() {}
+
+func (duMessage) isRecord() {}
+
+// formatFuncs are the functions available to --format templates.
+var formatFuncs = template.FuncMap{
+	// humanize prints a size in bytes with the units of --units.
+	"humanize": func(size int64) string {
+		return formatSize(size)
+	},
+	// formatTime prints a time with a Go layout such as "2006-01-02".
+	"formatTime": func(layout string, t interface{}) (string, error) {
+		switch t := t.(type) {
+		case time.Time:
+			return t.Format(layout), nil
+		case *time.Time:
+			if t == nil {
+				return "", nil
+			}
+			return t.Format(layout), nil
+		}
+		return "", fmt.Errorf("formatTime expects a time, not %T", t)
+	},
+}
+
+// parseFormat parses the --format template, "\t" and "\n" are read as
+// a tab and a newline so that they can be typed in a shell.
+func parseFormat(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	tmpl, e := template.New("format").Funcs(formatFuncs).Parse(format)
+	if e != nil {
+		return nil, fmt.Errorf("invalid --format: %w", e)
+	}
+	return tmpl, nil
+}
+
+var errFormatField = regexp.MustCompile(`can't evaluate field (\w+)`)
+
+// formatFields returns the names of the fields of record usable in a
+// --format template, including those of embedded structs.
+func formatFields(record interface{}) []string {
+	var fields []string
+	for _, f := range reflect.VisibleFields(reflect.TypeOf(record)) {
+		if f.IsExported() && !f.Anonymous {
+			fields = append(fields, f.Name)
+		}
+	}
+	return fields
+}
+
+// formatRecord renders record with tmpl.
+func formatRecord(tmpl *template.Template, record recordMessage) (string, error) {
+	var buf bytes.Buffer
+	if e := tmpl.Execute(&buf, record); e != nil {
+		if m := errFormatField.FindStringSubmatch(e.Error()); m != nil {
+			return "", fmt.Errorf("no field `%s`, available fields are %s", m[1], strings.Join(formatFields(record), ", "))
+		}
+		return "", e
+	}
+	return buf.String(), nil
+}
+
+// mustFormatRecord renders record with the --format template, exiting
+// on a template which does not apply to it.
+func mustFormatRecord(record recordMessage) string {
+	msg, e := formatRecord(globalFormat, record)
+	fatalIf(probe.NewError(e), "Unable to apply --format.")
+	return msg
+}
+
+var errFormatJSON = errors.New("--json and --format cannot be used together")
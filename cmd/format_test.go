@@ -0,0 +1,66 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatRecord(t *testing.T) {
+	modTime := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	content := contentMessage{Key: "dir/a.txt", Size: 1536, Time: modTime}
+
+	testCases := []struct {
+		format string
+		record recordMessage
+		want   string
+		errMsg string
+	}{
+		{format: `{{.Key}}\t{{humanize .Size}}`, record: content, want: "dir/a.txt\t1.5 KiB"},
+		{format: `{{formatTime "2006-01-02" .Time}}`, record: content, want: "2024-03-01"},
+		{format: `{{.Key}}`, record: findMessage{content}, want: "dir/a.txt"},
+		{format: `{{.Date.Year}} {{.Size}}`, record: statMessage{Date: modTime, Size: 3}, want: "2024 3"},
+		{format: `{{.Name}}`, record: content, errMsg: "no field `Name`, available fields are Status, Filetype, Time, Size, Key"},
+		{format: `{{.Name}}`, record: findMessage{content}, errMsg: "available fields are Status, Filetype"},
+	}
+	for i, tc := range testCases {
+		tmpl, e := parseFormat(tc.format)
+		if e != nil {
+			t.Fatalf("case %d: %v", i+1, e)
+		}
+		got, e := formatRecord(tmpl, tc.record)
+		if tc.errMsg != "" {
+			if e == nil || !strings.Contains(e.Error(), tc.errMsg) {
+				t.Errorf("case %d: expected error containing %q, got %v", i+1, tc.errMsg, e)
+			}
+			continue
+		}
+		if e != nil {
+			t.Fatalf("case %d: %v", i+1, e)
+		}
+		if got != tc.want {
+			t.Errorf("case %d: expected %q, got %q", i+1, tc.want, got)
+		}
+	}
+
+	if _, e := parseFormat(`{{.Key`); e == nil {
+		t.Error("expected an invalid template to fail to parse")
+	}
+}
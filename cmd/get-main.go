@@ -22,12 +22,26 @@ import (
 	"strings"
 
 	"github.com/minio/cli"
-	"github.com/minio/pkg/v2/console"
 )
 
 // get command flags.
 var (
-	getFlags = []cli.Flag{}
+	getFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "recursive, r",
+			Usage: "download all objects under the prefix, recreating the key hierarchy",
+		},
+		cli.BoolFlag{
+			Name:  "verify",
+			Usage: "verify downloaded objects against their ETag, multipart and encrypted objects are not verified",
+		},
+		cli.StringFlag{
+			Name:  "ranges",
+			Usage: "only download the byte ranges of a file of 'offset,length' lines, into the same offsets of TARGET",
+		},
+		onConflictFlag,
+		allowEmptyFlag,
+	}
 )
 
 // Get command.
@@ -44,16 +58,48 @@ var getCmd = cli.Command{
 USAGE:
   {{.HelpName}} [FLAGS] SOURCE TARGET
 
+DESCRIPTION:
+  'mc cp' runs get when the sources are remote and the target is local.
+
 FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
+GLOB PATTERNS:
+  A SOURCE holding '*', '?' or '[...]' downloads every object whose key matches
+  it into the TARGET folder, recreating the keys below the last folder of SOURCE
+  without glob characters. '*', '?' and '[...]' match within a folder, '**'
+  matches across folders and a backslash escapes a glob character. The keys of
+  gpumall paths are matched relative to the gpumall folder. Matching no object
+  is an error unless --allow-empty is set.
+
+RANGES:
+  --ranges reads a file with one 'offset,length' pair of decimal byte counts per
+  line, blank lines and lines starting with '#' are ignored. The ranges must not
+  overlap and must fit within the object. Each range is downloaded with its own
+  ranged request and written at the same offset of TARGET, which is created if
+  missing and otherwise only modified within the ranges.
+
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT:      list of comma delimited prefixes
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 EXAMPLES:
   1. Get an object from S3 storage to local file system 
     {{.Prompt}} {{.HelpName}} ALIAS/BUCKET/object path-to/object 
+  2. Get all objects under a prefix, keys like 'prefix/a/b/c.txt' are written to './local/a/b/c.txt'
+    {{.Prompt}} {{.HelpName}} --recursive ALIAS/BUCKET/prefix/ ./local/
+  3. Get an object and verify its content against its ETag
+    {{.Prompt}} {{.HelpName}} --verify ALIAS/BUCKET/object path-to/object
+  4. Patch a local copy of a disk image with the byte ranges of ranges.txt, such as '0,4096' and '1048576,65536'
+    {{.Prompt}} {{.HelpName}} --ranges ranges.txt ALIAS/BUCKET/disk.img disk.img
+  5. Get all objects under a prefix, keeping the local files which exist as they are
+    {{.Prompt}} {{.HelpName}} --recursive --on-conflict skip ALIAS/BUCKET/prefix/ ./local/
+  6. Get the logs of November 2024, and every '.npz' file at any depth under 'runs/'
+    {{.Prompt}} {{.HelpName}} 'ALIAS/BUCKET/logs/2024-11-*.gz' ./logs/
+    {{.Prompt}} {{.HelpName}} 'ALIAS/BUCKET/runs/**/*.npz' ./runs/
+  7. Get the objects of several encrypted prefixes, with the prefix=secret keys of a file such as 'ALIAS/BUCKET/a/=32byteslongsecretkeymustbegiven1'
+    {{.Prompt}} {{.HelpName}} --recursive --encrypt-key-file ./keys.txt ALIAS/BUCKET/ ./local/
 `,
 }
 
@@ -73,6 +119,30 @@ func mainGet(cliCtx *cli.Context) (e error) {
 	// get source and target
 	sourceURLs := args[:len(args)-1]
 	targetURL := args[len(args)-1]
+	for _, sourceURL := range sourceURLs {
+		if alias, _ := url2Alias(sourceURL); alias == AuthAlias {
+			// fail fast without a valid token.
+			getAuth()
+			break
+		}
+	}
+
+	isGlob := hasGlobMeta(sourceURLs[0])
+	if isGlob && (cliCtx.Bool("recursive") || cliCtx.IsSet("ranges")) {
+		fatalIf(errInvalidArgument().Trace(sourceURLs...), "--recursive and --ranges cannot be used with a glob pattern.")
+	}
+
+	policy := parseConflictPolicy(cliCtx)
+	if rangesFile := cliCtx.String("ranges"); rangesFile != "" {
+		if cliCtx.Bool("recursive") {
+			fatalIf(errInvalidArgument().Trace(rangesFile), "--ranges cannot be used with --recursive.")
+		}
+		if policy != conflictOverwrite {
+			fatalIf(errInvalidArgument().Trace(rangesFile), "--on-conflict cannot be used with --ranges.")
+		}
+		mainGetRanges(ctx, rangesFile, sourceURLs[0], targetURL, encKeyDB)
+		return nil
+	}
 
 	getURLsCh := make(chan URLs, 10000)
 	var totalObjects, totalBytes int64
@@ -81,16 +151,25 @@ func mainGet(cliCtx *cli.Context) (e error) {
 	var pg ProgressReader
 	// Enable progress bar reader only during default mode.
 	if !globalQuiet && !globalJSON { // set up progress bar
-		pg = newProgressBar(totalBytes)
+		pg = newProgressBar(totalBytes, statusOutput)
 	} else {
 		pg = newAccounter(totalBytes)
 	}
+	isRecursive := cliCtx.Bool("recursive")
+	var conflicts *conflictResolver
+	if cliCtx.IsSet("on-conflict") {
+		setConflictColors()
+		conflicts = newConflictResolver(policy, isRecursive)
+		defer func() { printMsg(conflicts.summaryMessage()) }()
+	}
 	go func() {
 		opts := prepareCopyURLsOpts{
 			sourceURLs:              sourceURLs,
 			targetURL:               targetURL,
+			isRecursive:             isRecursive,
 			encKeyDB:                encKeyDB,
 			ignoreBucketExistsCheck: true,
+			allowEmpty:              cliCtx.Bool("allow-empty"),
 		}
 
 		for getURLs := range prepareGetURLs(ctx, opts) {
@@ -98,6 +177,10 @@ func mainGet(cliCtx *cli.Context) (e error) {
 				getURLsCh <- getURLs
 				break
 			}
+			if isRecursive || isGlob {
+				totalBytes += getURLs.SourceContent.Size
+				pg.SetTotal(totalBytes)
+			}
 			totalObjects++
 			getURLsCh <- getURLs
 		}
@@ -118,11 +201,33 @@ func mainGet(cliCtx *cli.Context) (e error) {
 				showLastProgressBar(pg, getURLs.Error.ToGoError())
 				return
 			}
+			if conflicts != nil {
+				action, resolved, err := conflicts.resolve(ctx, getURLs)
+				if err != nil {
+					showLastProgressBar(pg, err.ToGoError())
+					errorIf(err.Trace(getURLs.TargetContent.URL.String()), "Unable to check the target of `"+getURLs.SourceContent.URL.Path+"`.")
+					return exitStatus(globalErrorExitStatus)
+				}
+				if action != "" {
+					printConflict(pg, action, getURLs, resolved, false)
+				}
+				switch action {
+				case conflictSkip:
+					doCopyFake(getURLs, pg)
+					continue
+				case conflictError:
+					doCopyFake(getURLs, pg)
+					e = exitStatus(globalErrorExitStatus)
+					continue
+				}
+				getURLs = resolved
+			}
 			urls := doCopy(ctx, doCopyOpts{
 				cpURLs:              getURLs,
 				pg:                  pg,
 				encKeyDB:            encKeyDB,
-				updateProgressTotal: true,
+				updateProgressTotal: !isRecursive && !isGlob,
+				verifyResponse:      cliCtx.Bool("verify"),
 			})
 			if urls.Error != nil {
 				e = urls.Error.ToGoError()
@@ -133,11 +238,44 @@ func mainGet(cliCtx *cli.Context) (e error) {
 	}
 }
 
+// mainGetRanges downloads the byte ranges of rangesFile of sourceURL
+// into the local file targetURL.
+func mainGetRanges(ctx context.Context, rangesFile, sourceURL, targetURL string, encKeyDB map[string][]prefixSSEPair) {
+	ranges, err := readByteRangeSpec(rangesFile)
+	fatalIf(err, "Unable to read the ranges to download.")
+
+	sourceFullURL := getFullPath(sourceURL)
+	clnt, err := newClient(sourceFullURL)
+	fatalIf(err.Trace(sourceURL), "Unable to initialize source `"+sourceURL+"`.")
+	defer clnt.Close()
+	sourceAlias, _ := url2Alias(sourceFullURL)
+
+	var pg ProgressReader
+	if !globalQuiet && !globalJSON {
+		pg = newProgressBar(byteRangesSize(ranges), statusOutput)
+	} else {
+		pg = newAccounter(byteRangesSize(ranges))
+	}
+	opts := GetOptions{SSE: getSSE(sourceFullURL, encKeyDB[sourceAlias])}
+	err = getByteRanges(ctx, clnt, ranges, targetURL, opts, pg)
+	if err != nil {
+		showLastProgressBar(pg, err.ToGoError())
+	}
+	fatalIf(err.Trace(sourceURL, targetURL), "Unable to download the ranges of `"+sourceURL+"`.")
+	showLastProgressBar(pg, nil)
+	printMsg(getRangesMessage{
+		Source: sourceURL,
+		Target: targetURL,
+		Ranges: ranges,
+		Size:   byteRangesSize(ranges),
+	})
+}
+
 func printGetURLsError(cpURLs *URLs) {
 	// Print in new line and adjust to top so that we
 	// don't print over the ongoing scan bar
 	if !globalQuiet && !globalJSON {
-		console.Eraseline()
+		eraseStatusLine()
 	}
 
 	if strings.Contains(cpURLs.Error.ToGoError().Error(),
@@ -20,6 +20,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio-go/v7"
@@ -30,6 +32,12 @@ func prepareGetURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
 	copyURLsCh := make(chan URLs)
 	go func(o prepareCopyURLsOpts) {
 		defer close(copyURLsCh)
+		if len(o.sourceURLs) == 1 && hasGlobMeta(o.sourceURLs[0]) {
+			for cURLs := range prepareGetGlobURLs(ctx, o) {
+				copyURLsCh <- cURLs
+			}
+			return
+		}
 		copyURLsContent, err := guessGetURLType(ctx, o)
 		if err != nil {
 			copyURLsCh <- URLs{Error: err}
@@ -41,6 +49,10 @@ func prepareGetURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
 			copyURLsCh <- prepareCopyURLsTypeA(ctx, *copyURLsContent, o)
 		case copyURLsTypeB:
 			copyURLsCh <- prepareCopyURLsTypeB(ctx, *copyURLsContent, o)
+		case copyURLsTypeC:
+			for cURLs := range prepareGetURLsTypeC(ctx, *copyURLsContent, o) {
+				copyURLsCh <- cURLs
+			}
 		default:
 			copyURLsCh <- URLs{Error: errInvalidArgument().Trace(o.sourceURLs...)}
 		}
@@ -88,12 +100,9 @@ func guessGetURLType(ctx context.Context, o prepareCopyURLsOpts) (*copyURLsConte
 		if bucket == "" {
 			return cc, probe.NewError(fmt.Errorf("Please set bucket for s3 resource."))
 		}
-		if path == "" {
+		if path == "" && !o.isRecursive {
 			return cc, probe.NewError(fmt.Errorf("Please set a full path for s3 resource."))
 		}
-		cc.sourceContent = s3clnt.objectInfo2ClientContent(bucket, minio.ObjectInfo{
-			Key: path,
-		})
 
 		client, err = newClient(o.targetURL)
 		if err != nil {
@@ -105,6 +114,16 @@ func guessGetURLType(ctx context.Context, o prepareCopyURLsOpts) (*copyURLsConte
 			return cc, probe.NewError(fmt.Errorf("Target is not local filesystem."))
 		}
 
+		// With recursion ON, the whole prefix is downloaded, it is Type C.
+		if o.isRecursive {
+			cc.copyType = copyURLsTypeC
+			return cc, nil
+		}
+
+		cc.sourceContent = s3clnt.objectInfo2ClientContent(bucket, minio.ObjectInfo{
+			Key: path,
+		})
+
 		// If target is a folder, it is Type B.
 		var isDir bool
 		isDir, cc.targetContent = isAliasURLDir(ctx, o.targetURL, o.encKeyDB, o.timeRef, o.ignoreBucketExistsCheck)
@@ -123,3 +142,102 @@ func guessGetURLType(ctx context.Context, o prepareCopyURLsOpts) (*copyURLsConte
 	cc.copyType = copyURLsTypeInvalid
 	return cc, errInvalidArgument().Trace()
 }
+
+// prepareGetURLsTypeC - prepares the URLs to download every object under the
+// source prefix, recreating the key hierarchy under the target folder.
+func prepareGetURLsTypeC(ctx context.Context, cc copyURLsContent, o prepareCopyURLsOpts) <-chan URLs {
+	getURLsCh := make(chan URLs)
+	go func() {
+		defer close(getURLsCh)
+
+		sourceClient, err := newClient(cc.sourceURL)
+		if err != nil {
+			getURLsCh <- URLs{Error: err.Trace(cc.sourceURL)}
+			return
+		}
+
+		for sourceContent := range sourceClient.List(ctx, ListOptions{Recursive: true, TimeRef: o.timeRef, ShowDir: DirNone}) {
+			if sourceContent.Err != nil {
+				// Listing failed.
+				getURLsCh <- URLs{Error: sourceContent.Err.Trace(sourceClient.GetURL().String())}
+				continue
+			}
+			if !sourceContent.Type.IsRegular() {
+				continue
+			}
+			newCC := cc
+			newCC.sourceContent = sourceContent
+			getURLsCh <- makeGetContentTypeC(newCC, sourceClient.GetURL())
+		}
+	}()
+	return getURLsCh
+}
+
+// prepareGetGlobURLs - prepares the URLs to download every object matching
+// the glob pattern of the source, recreating the key hierarchy below the
+// folder of the pattern under the target folder.
+func prepareGetGlobURLs(ctx context.Context, o prepareCopyURLsOpts) <-chan URLs {
+	getURLsCh := make(chan URLs)
+	go func() {
+		defer close(getURLsCh)
+
+		g, err := newRemoteGlob(o.sourceURLs[0])
+		if err != nil {
+			getURLsCh <- URLs{Error: err}
+			return
+		}
+		sourceClient, err := newClient(g.prefixURL)
+		if err != nil {
+			getURLsCh <- URLs{Error: err.Trace(o.sourceURLs[0])}
+			return
+		}
+		targetClient, err := newClient(o.targetURL)
+		if err != nil {
+			getURLsCh <- URLs{Error: err.Trace(o.targetURL)}
+			return
+		}
+		if _, ok := targetClient.(*fsClient); !ok {
+			getURLsCh <- URLs{Error: probe.NewError(fmt.Errorf("Target is not local filesystem."))}
+			return
+		}
+
+		cc := copyURLsContent{sourceURL: g.prefixURL}
+		cc.sourceAlias, _, _ = mustExpandAlias(g.prefixURL)
+		cc.targetAlias, cc.targetURL, _ = mustExpandAlias(o.targetURL)
+		matched := false
+		for sourceContent := range g.list(ctx, sourceClient, o.timeRef) {
+			if sourceContent.Err != nil {
+				getURLsCh <- URLs{Error: sourceContent.Err.Trace(sourceClient.GetURL().String())}
+				continue
+			}
+			matched = true
+			newCC := cc
+			newCC.sourceContent = sourceContent
+			getURLsCh <- makeGetContentTypeC(newCC, sourceClient.GetURL())
+		}
+		if !matched && !o.allowEmpty {
+			getURLsCh <- URLs{Error: errNoGlobMatch(o.sourceURLs[0])}
+		}
+	}()
+	return getURLsCh
+}
+
+// makeGetContentTypeC - maps a listed object to a local file under the target
+// folder, keys which would resolve outside of it (e.g. `../`) are rejected.
+func makeGetContentTypeC(cc copyURLsContent, sourceClientURL ClientURL) URLs {
+	targetDir := cc.targetURL
+	getURLs := makeCopyContentTypeC(cc, sourceClientURL)
+	if !isPathUnderDir(targetDir, getURLs.TargetContent.URL.Path) {
+		return URLs{Error: errUnsafeObjectKey(cc.sourceContent.URL.Path, targetDir).Trace(cc.sourceContent.URL.String())}
+	}
+	return getURLs
+}
+
+// isPathUnderDir returns true if the cleaned path stays under dir.
+func isPathUnderDir(dir, path string) bool {
+	rel, e := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if e != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMakeGetContentTypeC(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "local")
+	sourceClientURL := *newClientURL("http://localhost:9000/bucket/prefix/")
+
+	testCases := []struct {
+		key            string
+		expectedTarget string
+		success        bool
+	}{
+		{"prefix/c.txt", filepath.Join(targetDir, "c.txt"), true},
+		{"prefix/a/b/c.txt", filepath.Join(targetDir, "a", "b", "c.txt"), true},
+		{"prefix/a/../b/c.txt", filepath.Join(targetDir, "b", "c.txt"), true},
+		{"prefix/../c.txt", "", false},
+		{"prefix/a/../../../etc/passwd", "", false},
+	}
+
+	for i, testCase := range testCases {
+		cc := copyURLsContent{
+			sourceAlias: "gpumall",
+			targetURL:   targetDir,
+			sourceContent: &ClientContent{
+				URL: *newClientURL("http://localhost:9000/bucket/" + testCase.key),
+			},
+		}
+		getURLs := makeGetContentTypeC(cc, sourceClientURL)
+		if !testCase.success {
+			if getURLs.Error == nil {
+				t.Fatalf("Test %d: expected key %s to be rejected, got target %s", i+1, testCase.key, getURLs.TargetContent.URL.Path)
+			}
+			continue
+		}
+		if getURLs.Error != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, getURLs.Error)
+		}
+		if got := filepath.Clean(getURLs.TargetContent.URL.Path); got != testCase.expectedTarget {
+			t.Fatalf("Test %d: expected target %s, got %s", i+1, testCase.expectedTarget, got)
+		}
+	}
+}
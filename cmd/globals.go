@@ -21,13 +21,20 @@ package cmd
 import (
 	"context"
 	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
 	"net/url"
+	"os"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
 	"github.com/minio/madmin-go/v3"
+	"github.com/minio/mc/pkg/limiter"
 	"github.com/minio/pkg/v2/console"
 	"github.com/muesli/termenv"
 )
@@ -53,6 +60,9 @@ const (
 	// Global CTRL-C (SIGINT, #2) exit status.
 	globalCancelExitStatus = 130
 
+	// Exit status of a command stopped by --deadline, as timeout(1).
+	globalDeadlineExitStatus = 124
+
 	// Global SIGKILL (#9) exit status.
 	globalKillExitStatus = 137
 
@@ -68,6 +78,10 @@ var (
 	globalNoColor      = false               // No Color flag set via command line
 	globalInsecure     = false               // Insecure flag set via command line
 	globalAirgapped    = false               // Airgapped flag set via command line
+	globalHTTP1        = false               // HTTP/1.1 only flag set via command line
+	globalHTTP2        = false               // HTTP/2 flag set via command line
+	globalSyncTime     = false               // Sign requests with the clock of the server
+	globalCommandName  = ""                  // Name of the running command, such as "put"
 	globalSubnetConfig []madmin.SubsysConfig // Subnet config
 
 	// GlobalDevMode is set to true if the program is running in development mode
@@ -79,8 +93,73 @@ var (
 	globalConnReadDeadline  time.Duration
 	globalConnWriteDeadline time.Duration
 
-	globalLimitUpload   uint64
-	globalLimitDownload uint64
+	// globalUploadBucket and globalDownloadBucket are shared by all the
+	// S3 transports so that --limit-upload and --limit-download cap the
+	// total rate of every concurrent transfer.
+	globalUploadBucket   *limiter.Bucket
+	globalDownloadBucket *limiter.Bucket
+
+	// globalMaxHostConns limits the connections per host, 0 means unlimited.
+	globalMaxHostConns int
+
+	// globalStallTimeout cancels transfers without progress for that long, 0 disables it.
+	globalStallTimeout = defaultStallTimeout
+
+	// globalTCPKeepAlive is the period of the TCP keep-alive probes, negative disables them.
+	globalTCPKeepAlive = defaultTCPKeepAlive
+
+	// globalMaxRetryTime bounds the time spent waiting on a throttling server.
+	globalMaxRetryTime = defaultMaxRetryTime
+
+	// globalRetryBase overrides the first wait of the retries of a
+	// transfer, 0 keeps the one of the command.
+	globalRetryBase time.Duration
+
+	// globalRetryCap is the maximum wait in between two retries of a transfer.
+	globalRetryCap = defaultRetryCap
+
+	// globalRetryJitter is the fraction of the retry waits which is randomized.
+	globalRetryJitter = maxJitter
+
+	// globalRequestTimeout cancels requests taking longer, 0 disables it.
+	globalRequestTimeout time.Duration
+
+	// globalTempDir is the folder of the temporary files when --temp-dir
+	// is set.
+	globalTempDir string
+
+	// globalTransferLog records every transferred object when --log-file is set.
+	globalTransferLog *transferLog
+
+	// globalHTTPTrace records every HTTP request when --trace-file is set.
+	globalHTTPTrace *httpTrace
+
+	// globalHTTPReplay answers the HTTP requests when --replay-file is set.
+	globalHTTPReplay *httpReplay
+
+	// globalTheme colors the output of ls and diff.
+	globalTheme outputTheme
+
+	// globalFormat renders listed objects when --format is set.
+	globalFormat *template.Template
+
+	// globalCSV writes listed objects as CSV or TSV rows when --csv or --tsv is set.
+	globalCSV *csvOutput
+
+	// globalCompat writes listed objects like another tool when --compat is set.
+	globalCompat *compatOutput
+
+	// globalUnits prints sizes in powers of 1024, of 1000 or in bytes.
+	globalUnits = unitsIEC
+
+	// globalRequestBucket is shared by all the S3 transports so that
+	// --req-limit holds across every concurrent worker.
+	globalRequestBucket *limiter.RequestBucket
+
+	// globalPartConcurrency is shared by all the S3 transports so that
+	// the part uploads of every object to a host back off together on
+	// SlowDown.
+	globalPartConcurrency = newHostConcurrency()
 
 	globalContext, globalCancel = context.WithCancel(context.Background())
 )
@@ -105,6 +184,20 @@ func parsePagerDisableFlag(args []string) {
 	}
 }
 
+// parseRate parses a rate in bytes per second such as 50MiB/s, the "/s"
+// is optional.
+func parseRate(s string) (uint64, error) {
+	trimmed := strings.TrimSpace(s)
+	if n := len(trimmed); n > 2 && strings.EqualFold(trimmed[n-2:], "/s") {
+		trimmed = trimmed[:n-2]
+	}
+	rate, e := humanize.ParseBytes(trimmed)
+	if e != nil {
+		return 0, fmt.Errorf("invalid rate %q, it should be like 50MiB/s", s)
+	}
+	return rate, nil
+}
+
 // Set global states. NOTE: It is deliberately kept monolithic to ensure we dont miss out any flags.
 func setGlobalsFromContext(ctx *cli.Context) error {
 	quiet := ctx.IsSet("quiet") || ctx.GlobalIsSet("quiet")
@@ -114,6 +207,9 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	insecure := ctx.IsSet("insecure") || ctx.GlobalIsSet("insecure")
 	devMode := ctx.IsSet("dev") || ctx.GlobalIsSet("dev")
 	airgapped := ctx.IsSet("airgap") || ctx.GlobalIsSet("airgap")
+	http1 := ctx.Bool("http1") || ctx.GlobalBool("http1")
+	http2 := ctx.Bool("http2") || ctx.GlobalBool("http2")
+	syncTime := ctx.Bool("sync-time") || ctx.GlobalBool("sync-time")
 
 	globalQuiet = globalQuiet || quiet
 	globalDebug = globalDebug || debug
@@ -123,6 +219,18 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	globalInsecure = globalInsecure || insecure
 	GlobalDevMode = GlobalDevMode || devMode
 	globalAirgapped = globalAirgapped || airgapped
+	globalHTTP1 = globalHTTP1 || http1
+	globalHTTP2 = globalHTTP2 || http2
+	globalSyncTime = globalSyncTime || syncTime
+	if ctx.Command.Name != "" {
+		globalCommandName = ctx.Command.FullName()
+	}
+
+	themeName := ctx.String("theme")
+	if themeName == "" {
+		themeName = ctx.GlobalString("theme")
+	}
+	globalNoColor = globalNoColor || themeName == themeNone
 
 	// Disable colorified messages if requested.
 	if globalNoColor || globalQuiet {
@@ -130,6 +238,21 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 		lipgloss.SetColorProfile(termenv.Ascii)
 	}
 
+	if globalDebug {
+		printFlagEnv()
+	}
+
+	globalTheme = loadTheme(themeName)
+
+	var e error
+	units := ctx.String("units")
+	if units == "" {
+		units = ctx.GlobalString("units")
+	}
+	if globalUnits, e = parseUnits(units); e != nil {
+		return e
+	}
+
 	globalConnReadDeadline = ctx.Duration("conn-read-deadline")
 	if globalConnReadDeadline <= 0 {
 		globalConnReadDeadline = ctx.GlobalDuration("conn-read-deadline")
@@ -144,25 +267,239 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	if limitUploadStr == "" {
 		limitUploadStr = ctx.GlobalString("limit-upload")
 	}
-	if limitUploadStr != "" {
-		var e error
-		globalLimitUpload, e = humanize.ParseBytes(limitUploadStr)
+	if limitUploadStr != "" && globalUploadBucket == nil {
+		limitUpload, e := parseRate(limitUploadStr)
 		if e != nil {
 			return e
 		}
+		globalUploadBucket = limiter.NewBucket(int64(limitUpload))
 	}
 
 	limitDownloadStr := ctx.String("limit-download")
 	if limitDownloadStr == "" {
 		limitDownloadStr = ctx.GlobalString("limit-download")
 	}
+	if limitDownloadStr != "" && globalDownloadBucket == nil {
+		limitDownload, e := parseRate(limitDownloadStr)
+		if e != nil {
+			return e
+		}
+		globalDownloadBucket = limiter.NewBucket(int64(limitDownload))
+	}
+
+	if maxHostConns := ctx.Int("max-host-conns"); maxHostConns > 0 {
+		globalMaxHostConns = maxHostConns
+	} else if maxHostConns = ctx.GlobalInt("max-host-conns"); maxHostConns > 0 {
+		globalMaxHostConns = maxHostConns
+	}
+
+	credentialsFile := ctx.String("credentials-file")
+	if credentialsFile == "" {
+		credentialsFile = ctx.GlobalString("credentials-file")
+	}
+	credentialHelper := ctx.String("credential-helper")
+	if credentialHelper == "" {
+		credentialHelper = ctx.GlobalString("credential-helper")
+	}
+	if credentialsFile != "" && credentialHelper != "" {
+		return errors.New("--credentials-file and --credential-helper cannot be used together")
+	}
+	if credentialsFile != "" && globalCredentials == nil {
+		if e := useCredentialsFile(credentialsFile); e != nil {
+			return e
+		}
+	}
+	if credentialHelper != "" && globalCredentials == nil {
+		if e := useCredentialHelper(credentialHelper); e != nil {
+			return e
+		}
+	}
+
+	signingRegion := ctx.String("signing-region")
+	if signingRegion == "" {
+		signingRegion = ctx.GlobalString("signing-region")
+	}
+	if authCfg := aliasToConfigMap[AuthAlias]; signingRegion != "" && authCfg != nil {
+		authCfg.SigningRegion = signingRegion
+	}
 
-	if limitDownloadStr != "" {
+	logFile := ctx.String("log-file")
+	if logFile == "" {
+		logFile = ctx.GlobalString("log-file")
+	}
+	if logFile != "" && globalTransferLog == nil {
 		var e error
-		globalLimitDownload, e = humanize.ParseBytes(limitDownloadStr)
-		if e != nil {
+		if globalTransferLog, e = openTransferLog(logFile); e != nil {
+			return e
+		}
+	}
+
+	traceFile := ctx.String("trace-file")
+	if traceFile == "" {
+		traceFile = ctx.GlobalString("trace-file")
+	}
+	if traceFile != "" && globalHTTPTrace == nil {
+		var e error
+		if globalHTTPTrace, e = openHTTPTrace(traceFile); e != nil {
+			return e
+		}
+	}
+
+	replayFile := ctx.String("replay-file")
+	if replayFile == "" {
+		replayFile = ctx.GlobalString("replay-file")
+	}
+	if replayFile != "" && globalHTTPReplay == nil {
+		var e error
+		if globalHTTPReplay, e = openHTTPReplay(replayFile); e != nil {
+			return e
+		}
+	}
+
+	format := ctx.String("format")
+	if format == "" {
+		format = ctx.GlobalString("format")
+	}
+	if format != "" {
+		if globalJSON {
+			return errFormatJSON
+		}
+		var e error
+		if globalFormat, e = parseFormat(format); e != nil {
+			return e
+		}
+	}
+
+	if isCSV, isTSV := ctx.Bool("csv"), ctx.Bool("tsv"); isCSV || isTSV {
+		if (isCSV && isTSV) || globalJSON || globalFormat != nil {
+			return errCSVOutput
+		}
+		comma := ','
+		if isTSV {
+			comma = '\t'
+		}
+		globalCSV = newCSVOutput(os.Stdout, comma)
+	}
+
+	if compat := ctx.String("compat"); compat != "" {
+		if globalJSON || globalFormat != nil || globalCSV != nil {
+			return errCompatOutput
+		}
+		var e error
+		if globalCompat, e = newCompatOutput(os.Stdout, compat); e != nil {
+			return e
+		}
+	}
+
+	switch {
+	case ctx.IsSet("stall-timeout"):
+		globalStallTimeout = ctx.Duration("stall-timeout")
+	case ctx.GlobalIsSet("stall-timeout"):
+		globalStallTimeout = ctx.GlobalDuration("stall-timeout")
+	}
+	if globalStallTimeout < 0 {
+		return errors.New("--stall-timeout cannot be negative")
+	}
+
+	switch {
+	case ctx.IsSet("tcp-keepalive"):
+		globalTCPKeepAlive = ctx.Duration("tcp-keepalive")
+	case ctx.GlobalIsSet("tcp-keepalive"):
+		globalTCPKeepAlive = ctx.GlobalDuration("tcp-keepalive")
+	}
+	switch {
+	case globalTCPKeepAlive < 0:
+		return errors.New("--tcp-keepalive cannot be negative")
+	case globalTCPKeepAlive == 0:
+		globalTCPKeepAlive = -1 // disables the probes of net.Dialer
+	}
+
+	switch {
+	case ctx.IsSet("max-retry-time"):
+		globalMaxRetryTime = ctx.Duration("max-retry-time")
+	case ctx.GlobalIsSet("max-retry-time"):
+		globalMaxRetryTime = ctx.GlobalDuration("max-retry-time")
+	}
+	if globalMaxRetryTime < 0 {
+		return errors.New("--max-retry-time cannot be negative")
+	}
+
+	switch {
+	case ctx.IsSet("retry-base"):
+		globalRetryBase = ctx.Duration("retry-base")
+	case ctx.GlobalIsSet("retry-base"):
+		globalRetryBase = ctx.GlobalDuration("retry-base")
+	}
+	if globalRetryBase < 0 {
+		return errors.New("--retry-base cannot be negative")
+	}
+
+	switch {
+	case ctx.IsSet("retry-cap"):
+		globalRetryCap = ctx.Duration("retry-cap")
+	case ctx.GlobalIsSet("retry-cap"):
+		globalRetryCap = ctx.GlobalDuration("retry-cap")
+	}
+	if globalRetryCap <= 0 {
+		return errors.New("--retry-cap must be positive")
+	}
+
+	switch {
+	case ctx.IsSet("retry-jitter"):
+		globalRetryJitter = ctx.Float64("retry-jitter")
+	case ctx.GlobalIsSet("retry-jitter"):
+		globalRetryJitter = ctx.GlobalFloat64("retry-jitter")
+	}
+	if globalRetryJitter < noJitter || globalRetryJitter > maxJitter {
+		return errors.New("--retry-jitter must be in between 0 and 1")
+	}
+
+	switch {
+	case ctx.IsSet("request-timeout"):
+		globalRequestTimeout = ctx.Duration("request-timeout")
+	case ctx.GlobalIsSet("request-timeout"):
+		globalRequestTimeout = ctx.GlobalDuration("request-timeout")
+	}
+	if globalRequestTimeout < 0 {
+		return errors.New("--request-timeout cannot be negative")
+	}
+
+	deadline := ctx.Duration("deadline")
+	if !ctx.IsSet("deadline") {
+		deadline = ctx.GlobalDuration("deadline")
+	}
+	if deadline < 0 {
+		return errors.New("--deadline cannot be negative")
+	}
+	setGlobalDeadline(deadline)
+
+	reqLimit := ctx.Float64("req-limit")
+	if reqLimit <= 0 {
+		reqLimit = ctx.GlobalFloat64("req-limit")
+	}
+	reqBurst := ctx.Int("req-burst")
+	if reqBurst <= 0 {
+		reqBurst = ctx.GlobalInt("req-burst")
+	}
+	if reqLimit < 0 || reqBurst < 0 {
+		return errors.New("--req-limit and --req-burst cannot be negative")
+	}
+	if reqLimit > 0 && globalRequestBucket == nil {
+		if reqBurst == 0 {
+			reqBurst = int(math.Ceil(reqLimit))
+		}
+		globalRequestBucket = limiter.NewRequestBucket(reqLimit, int64(reqBurst))
+	}
+
+	tempDirStr := ctx.String("temp-dir")
+	if tempDirStr == "" {
+		tempDirStr = ctx.GlobalString("temp-dir")
+	}
+	if tempDirStr != "" && tempDirStr != globalTempDir {
+		if e := checkTempDir(tempDirStr); e != nil {
 			return e
 		}
+		globalTempDir = tempDirStr
 	}
 
 	return nil
@@ -0,0 +1,38 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "testing"
+
+func TestParseRate(t *testing.T) {
+	for s, expected := range map[string]uint64{
+		"50MiB/s": 50 << 20,
+		"50mib/S": 50 << 20,
+		"1MB":     1000000,
+		" 2KiB/s": 2 << 10,
+	} {
+		if rate, e := parseRate(s); e != nil || rate != expected {
+			t.Errorf("expected %q to be %d, got %d: %v", s, expected, rate, e)
+		}
+	}
+	for _, s := range []string{"", "/s", "fast", "50MiB/h"} {
+		if _, e := parseRate(s); e == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
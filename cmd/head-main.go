@@ -71,6 +71,7 @@ FLAGS:
   {{end}}
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 NOTE:
   '{{.HelpName}}' automatically decompresses 'gzip', 'bzip2' compressed objects.
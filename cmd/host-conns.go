@@ -0,0 +1,92 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/pkg/v2/console"
+)
+
+// hostConnsQueueLogInterval is the minimum interval in between two
+// debug messages about requests queued on --max-host-conns.
+const hostConnsQueueLogInterval = 5 * time.Second
+
+// hostConnsTransport counts the requests holding or waiting for one of
+// the maxConns connections of the transport. Excess requests are queued
+// by http.Transport, the queue is reported with --debug so that users
+// know the endpoint is the bottleneck and concurrency can be lowered.
+type hostConnsTransport struct {
+	transport http.RoundTripper
+	maxConns  int64
+
+	inflight int64
+	mu       sync.Mutex
+	lastLog  time.Time
+}
+
+func newHostConnsTransport(maxConns int, transport http.RoundTripper) http.RoundTripper {
+	if maxConns <= 0 {
+		return transport
+	}
+	return &hostConnsTransport{transport: transport, maxConns: int64(maxConns)}
+}
+
+func (t *hostConnsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if queued := atomic.AddInt64(&t.inflight, 1) - t.maxConns; queued > 0 {
+		t.logQueued(req.URL.Host, queued)
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		atomic.AddInt64(&t.inflight, -1)
+		return resp, err
+	}
+	// The connection is held until the body has been closed.
+	resp.Body = &hostConnsBody{ReadCloser: resp.Body, release: func() { atomic.AddInt64(&t.inflight, -1) }}
+	return resp, nil
+}
+
+func (t *hostConnsTransport) logQueued(host string, queued int64) {
+	if !globalDebug {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if time.Since(t.lastLog) < hostConnsQueueLogInterval {
+		return
+	}
+	t.lastLog = time.Now()
+	console.Debugln(fmt.Sprintf("%d requests queued for %s, all %d connections allowed by --max-host-conns are busy. The endpoint is the bottleneck, consider lowering the concurrency.",
+		queued, host, t.maxConns))
+}
+
+type hostConnsBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *hostConnsBody) Close() error {
+	b.once.Do(b.release)
+	return b.ReadCloser.Close()
+}
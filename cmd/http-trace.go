@@ -0,0 +1,348 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// httpTraceBodyLimit is the number of bytes of every body kept in
+// the --trace-file entries.
+const httpTraceBodyLimit = 4 << 10
+
+// httpTraceRedacted replaces the secrets in the --trace-file entries.
+const httpTraceRedacted = "**REDACTED**"
+
+// httpTraceSecretHeaders are never written to --trace-file.
+var httpTraceSecretHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Amz-Security-Token",
+	"X-Amz-Server-Side-Encryption-Customer-Key",
+	"X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key",
+}
+
+// httpTraceAuthParams are the query parameters of presigned requests,
+// they are redacted from --trace-file and ignored by --replay-file.
+var httpTraceAuthParams = []string{
+	"X-Amz-Algorithm",
+	"X-Amz-Credential",
+	"X-Amz-Date",
+	"X-Amz-Expires",
+	"X-Amz-Security-Token",
+	"X-Amz-Signature",
+	"X-Amz-SignedHeaders",
+	"AWSAccessKeyId",
+	"Expires",
+	"Signature",
+}
+
+// httpTraceEntry is the line written to --trace-file for every HTTP
+// request, and read back by --replay-file.
+type httpTraceEntry struct {
+	Time       string             `json:"time"`
+	DurationMs int64              `json:"durationMs"`
+	Request    httpTraceMessage   `json:"request"`
+	Response   *httpTraceResponse `json:"response,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// httpTraceMessage is the request of a httpTraceEntry.
+type httpTraceMessage struct {
+	Method        string      `json:"method"`
+	URL           string      `json:"url"`
+	Header        http.Header `json:"header"`
+	ContentLength int64       `json:"contentLength"`
+	Body          string      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"bodyTruncated,omitempty"`
+}
+
+// httpTraceResponse is the response of a httpTraceEntry.
+type httpTraceResponse struct {
+	StatusCode    int         `json:"statusCode"`
+	Header        http.Header `json:"header"`
+	ContentLength int64       `json:"contentLength"`
+	Body          string      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"bodyTruncated,omitempty"`
+}
+
+// httpTrace appends a JSON line per HTTP request to a file, with the
+// credentials redacted and the bodies truncated.
+type httpTrace struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// openHTTPTrace opens the file at path for appending, creating it if needed.
+func openHTTPTrace(path string) (*httpTrace, error) {
+	f, e := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if e != nil {
+		return nil, e
+	}
+	return &httpTrace{file: f}, nil
+}
+
+// wrap returns transport recording its requests, a nil httpTrace
+// returns transport unchanged.
+func (t *httpTrace) wrap(transport http.RoundTripper) http.RoundTripper {
+	if t == nil {
+		return transport
+	}
+	return &httpTraceTransport{trace: t, transport: transport}
+}
+
+// write appends entry to the file with a single unbuffered write.
+func (t *httpTrace) write(entry *httpTraceEntry) {
+	line, e := json.Marshal(entry)
+	if e != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, e = t.file.Write(line)
+	errorIf(probe.NewError(e), "Unable to write to the trace file `"+t.file.Name()+"`.")
+}
+
+// httpTraceTransport records the requests sent through transport.
+type httpTraceTransport struct {
+	trace     *httpTrace
+	transport http.RoundTripper
+}
+
+func (t *httpTraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	entry := &httpTraceEntry{
+		Time: start.UTC().Format(time.RFC3339Nano),
+		Request: httpTraceMessage{
+			Method:        req.Method,
+			URL:           redactTraceURL(req.URL),
+			Header:        redactTraceHeader(req.Header),
+			ContentLength: req.ContentLength,
+		},
+	}
+	// Only bodies which can be read again are recorded, the others are
+	// streamed uploads.
+	if req.GetBody != nil && req.ContentLength != 0 {
+		if body, e := req.GetBody(); e == nil {
+			entry.Request.Body, entry.Request.BodyTruncated = readTraceBody(body)
+			body.Close()
+		}
+	}
+
+	resp, e := t.transport.RoundTrip(req)
+	if e != nil {
+		entry.DurationMs = time.Since(start).Milliseconds()
+		entry.Error = e.Error()
+		t.trace.write(entry)
+		return resp, e
+	}
+	entry.Response = &httpTraceResponse{
+		StatusCode:    resp.StatusCode,
+		Header:        redactTraceHeader(resp.Header),
+		ContentLength: resp.ContentLength,
+	}
+	resp.Body = &httpTraceBody{ReadCloser: resp.Body, trace: t.trace, entry: entry, start: start}
+	return resp, nil
+}
+
+// httpTraceBody keeps the start of a response body and writes the
+// entry of the response once the body is read or closed.
+type httpTraceBody struct {
+	io.ReadCloser
+	trace *httpTrace
+	entry *httpTraceEntry
+	start time.Time
+	buf   bytes.Buffer
+	read  int64
+	once  sync.Once
+}
+
+func (b *httpTraceBody) Read(p []byte) (int, error) {
+	n, e := b.ReadCloser.Read(p)
+	if room := httpTraceBodyLimit - b.buf.Len(); room > 0 {
+		if room > n {
+			room = n
+		}
+		b.buf.Write(p[:room])
+	}
+	b.read += int64(n)
+	if e != nil {
+		b.finish(e)
+	}
+	return n, e
+}
+
+func (b *httpTraceBody) Close() error {
+	b.finish(nil)
+	return b.ReadCloser.Close()
+}
+
+// finish writes the entry, err is the error which ended the body or
+// nil when it was closed before its end.
+func (b *httpTraceBody) finish(err error) {
+	b.once.Do(func() {
+		b.entry.DurationMs = time.Since(b.start).Milliseconds()
+		b.entry.Response.Body = b.buf.String()
+		b.entry.Response.BodyTruncated = b.read > httpTraceBodyLimit ||
+			(err == nil && b.read != b.entry.Response.ContentLength)
+		if err != nil && !errors.Is(err, io.EOF) {
+			b.entry.Error = err.Error()
+		}
+		b.trace.write(b.entry)
+	})
+}
+
+// readTraceBody returns the start of body and whether it is longer.
+func readTraceBody(body io.Reader) (string, bool) {
+	buf, _ := io.ReadAll(io.LimitReader(body, httpTraceBodyLimit+1))
+	if len(buf) > httpTraceBodyLimit {
+		return string(buf[:httpTraceBodyLimit]), true
+	}
+	return string(buf), false
+}
+
+// redactTraceHeader returns a copy of header without the secrets.
+func redactTraceHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range httpTraceSecretHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, httpTraceRedacted)
+		}
+	}
+	return header
+}
+
+// redactTraceURL returns u without the secrets of presigned requests.
+func redactTraceURL(u *url.URL) string {
+	redacted := *u
+	query := u.Query()
+	for _, key := range httpTraceAuthParams {
+		if query.Has(key) {
+			query.Set(key, httpTraceRedacted)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.Redacted()
+}
+
+// httpTraceKey identifies the requests answered by the same recorded
+// response: their method, path and query without the authentication.
+func httpTraceKey(method string, u *url.URL) string {
+	query := u.Query()
+	for _, key := range httpTraceAuthParams {
+		query.Del(key)
+	}
+	return method + " " + u.EscapedPath() + "?" + query.Encode()
+}
+
+// httpReplay answers requests with the responses recorded by
+// --trace-file instead of sending them, to debug a captured session
+// offline. Every recorded response answers a single request, in the
+// order they were recorded.
+type httpReplay struct {
+	mu      sync.Mutex
+	keys    []string
+	entries []*httpTraceEntry
+}
+
+// openHTTPReplay reads the trace file at path.
+func openHTTPReplay(path string) (*httpReplay, error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, e
+	}
+	defer f.Close()
+
+	replay := &httpReplay{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		entry := &httpTraceEntry{}
+		if e = json.Unmarshal(scanner.Bytes(), entry); e != nil {
+			return nil, fmt.Errorf("line %d of the replay file `%s`: %w", line, path, e)
+		}
+		u, e := url.Parse(entry.Request.URL)
+		if e != nil {
+			return nil, fmt.Errorf("line %d of the replay file `%s`: %w", line, path, e)
+		}
+		replay.keys = append(replay.keys, httpTraceKey(entry.Request.Method, u))
+		replay.entries = append(replay.entries, entry)
+	}
+	if e = scanner.Err(); e != nil {
+		return nil, e
+	}
+	return replay, nil
+}
+
+// next returns the first unused entry recorded for req.
+func (r *httpReplay) next(req *http.Request) *httpTraceEntry {
+	key := httpTraceKey(req.Method, req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, entry := range r.entries {
+		if entry != nil && r.keys[i] == key {
+			r.entries[i] = nil
+			return entry
+		}
+	}
+	return nil
+}
+
+func (r *httpReplay) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	entry := r.next(req)
+	if entry == nil {
+		return nil, fmt.Errorf("no response to %s %s in the replay file", req.Method, redactTraceURL(req.URL))
+	}
+	if entry.Response == nil {
+		return nil, errors.New(entry.Error)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.StatusCode, http.StatusText(entry.Response.StatusCode)),
+		StatusCode:    entry.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Response.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(entry.Response.Body)),
+		ContentLength: entry.Response.ContentLength,
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTTPTrace(t *testing.T) {
+	big := strings.Repeat("x", httpTraceBodyLimit+10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		switch r.URL.Path {
+		case "/bucket/big":
+			io.WriteString(w, big)
+		case "/bucket":
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	trace, e := openHTTPTrace(path)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer func(t *httpTrace) { globalHTTPTrace = t }(globalHTTPTrace)
+	globalHTTPTrace = trace
+
+	// send returns the status and body of a request sent through client.
+	send := func(client *http.Client, method, path, body string) (int, string) {
+		t.Helper()
+		req, e := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if e != nil {
+			t.Fatal(e)
+		}
+		req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=access/20240101, Signature=abcdef")
+		resp, e := client.Do(req)
+		if e != nil {
+			t.Fatal(e)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	client := &http.Client{Transport: getTransportForConfig(&Config{HostURL: server.URL}, false)}
+	send(client, http.MethodGet, "/bucket/big", "")
+	send(client, http.MethodGet, "/bucket/missing?X-Amz-Signature=abcdef&versionId=1", "")
+	send(client, http.MethodPost, "/bucket?delete", "<Delete/>")
+	globalHTTPTrace = nil
+
+	f, e := os.Open(path)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer f.Close()
+	var entries []httpTraceEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry httpTraceEntry
+		if e = json.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), e)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Time == "" || entry.Response == nil || entry.Error != "" {
+			t.Fatalf("unexpected entry %+v", entry)
+		}
+		if got := entry.Request.Header.Get("Authorization"); got != httpTraceRedacted {
+			t.Errorf("expected the authorization to be redacted, got %q", got)
+		}
+		if got := entry.Response.Header.Get("Set-Cookie"); got != httpTraceRedacted {
+			t.Errorf("expected the cookie to be redacted, got %q", got)
+		}
+	}
+	if resp := entries[0].Response; len(resp.Body) != httpTraceBodyLimit || !resp.BodyTruncated {
+		t.Errorf("expected a truncated body of %d bytes, got %d bytes, truncated %v", httpTraceBodyLimit, len(resp.Body), resp.BodyTruncated)
+	}
+	if req := entries[1].Request; strings.Contains(req.URL, "abcdef") || !strings.Contains(req.URL, "versionId=1") {
+		t.Errorf("expected the signature to be redacted from %q", req.URL)
+	}
+	if resp := entries[1].Response; resp.StatusCode != http.StatusNotFound || resp.BodyTruncated || !strings.Contains(resp.Body, "NoSuchKey") {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if req := entries[2].Request; req.Method != http.MethodPost || req.Body != "<Delete/>" {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	replay, e := openHTTPReplay(path)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer func(r *httpReplay) { globalHTTPReplay = r }(globalHTTPReplay)
+	globalHTTPReplay = replay
+	server.Close()
+
+	client = &http.Client{Transport: getTransportForConfig(&Config{HostURL: server.URL}, false)}
+	// A new signature does not prevent the replay.
+	if status, body := send(client, http.MethodGet, "/bucket/missing?X-Amz-Signature=123456&versionId=1", ""); status != http.StatusNotFound || !strings.Contains(body, "NoSuchKey") {
+		t.Errorf("unexpected replayed response %d %q", status, body)
+	}
+	if status, body := send(client, http.MethodPost, "/bucket?delete", "<Delete/>"); status != http.StatusOK || body != "<Delete/>" {
+		t.Errorf("unexpected replayed response %d %q", status, body)
+	}
+	// Every recorded response is replayed once.
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/bucket?delete", nil)
+	if _, e = client.Do(req); e == nil || !strings.Contains(e.Error(), "no response to POST") {
+		t.Errorf("expected no recorded response, got %v", e)
+	}
+}
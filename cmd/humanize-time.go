@@ -0,0 +1,91 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+)
+
+const defaultHumanizeTimeMax = "30d"
+
+// humanizeTimeFlags are the flags of the commands listing modification times.
+var humanizeTimeFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "humanize-time",
+		Usage: "print modification times as \"3 days ago\", older times stay absolute",
+	},
+	cli.StringFlag{
+		Name:  "humanize-time-max",
+		Usage: "print modification times older than this as absolute with --humanize-time",
+		Value: defaultHumanizeTimeMax,
+	},
+}
+
+// timeNow is the clock of relative times, tests replace it.
+var timeNow = time.Now
+
+// globalHumanizeTime is the age up to which listed times are printed
+// relative to now, 0 prints them all as absolute.
+var globalHumanizeTime time.Duration
+
+// parseHumanizeTimeFlags sets globalHumanizeTime from --humanize-time.
+func parseHumanizeTimeFlags(cliCtx *cli.Context) error {
+	if !cliCtx.Bool("humanize-time") {
+		globalHumanizeTime = 0
+		return nil
+	}
+	maxAge, e := ParseDuration(cliCtx.String("humanize-time-max"))
+	if e != nil {
+		return fmt.Errorf("invalid --humanize-time-max: %w", e)
+	}
+	if maxAge <= 0 {
+		return fmt.Errorf("--humanize-time-max must be positive")
+	}
+	globalHumanizeTime = time.Duration(maxAge)
+	return nil
+}
+
+// relativeTime prints t relative to now, e.g. "3 days ago", it returns
+// false if t is more than maxAge away from now. The words are always
+// English, whatever the locale.
+func relativeTime(t, now time.Time, maxAge time.Duration) (string, bool) {
+	if t.IsZero() {
+		return "", false
+	}
+	age := now.Sub(t)
+	if age > maxAge || -age > maxAge {
+		return "", false
+	}
+	return humanize.RelTime(t, now, "ago", "from now"), true
+}
+
+// formatListTime prints a listed modification time, relative to now with
+// --humanize-time and with the layout of printDate otherwise. JSON output
+// always carries absolute times.
+func formatListTime(t time.Time) string {
+	if globalHumanizeTime > 0 {
+		if s, ok := relativeTime(t, timeNow(), globalHumanizeTime); ok {
+			return s
+		}
+	}
+	return t.Format(printDate)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatListTime(t *testing.T) {
+	now := time.Date(2024, 11, 5, 12, 0, 0, 0, time.UTC)
+	defer func(f func() time.Time, d time.Duration) { timeNow, globalHumanizeTime = f, d }(timeNow, globalHumanizeTime)
+	timeNow = func() time.Time { return now }
+
+	testCases := []struct {
+		maxAge   time.Duration
+		t        time.Time
+		expected string
+	}{
+		{0, now.Add(-time.Hour), now.Add(-time.Hour).Format(printDate)},
+		{30 * 24 * time.Hour, now, "now"},
+		{30 * 24 * time.Hour, now.Add(-45 * time.Second), "45 seconds ago"},
+		{30 * 24 * time.Hour, now.Add(-5 * time.Minute), "5 minutes ago"},
+		{30 * 24 * time.Hour, now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{30 * 24 * time.Hour, now.Add(10 * time.Minute), "10 minutes from now"},
+		{30 * 24 * time.Hour, now.Add(-31 * 24 * time.Hour), now.Add(-31 * 24 * time.Hour).Format(printDate)},
+		{24 * time.Hour, now.Add(-3 * 24 * time.Hour), now.Add(-3 * 24 * time.Hour).Format(printDate)},
+		{30 * 24 * time.Hour, time.Time{}, time.Time{}.Format(printDate)},
+	}
+
+	for i, testCase := range testCases {
+		globalHumanizeTime = testCase.maxAge
+		if got := formatListTime(testCase.t); got != testCase.expected {
+			t.Fatalf("Test %d: expected %q, got %q", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestContentMessageHumanizeTime(t *testing.T) {
+	now := time.Date(2024, 11, 5, 12, 0, 0, 0, time.UTC)
+	defer func(f func() time.Time, d time.Duration) { timeNow, globalHumanizeTime = f, d }(timeNow, globalHumanizeTime)
+	timeNow = func() time.Time { return now }
+	globalHumanizeTime = 30 * 24 * time.Hour
+
+	msg := contentMessage{Key: "a.txt", Time: now.Add(-2 * time.Hour)}
+	if got := msg.String(); !strings.Contains(got, "[2 hours ago]") {
+		t.Fatalf("expected a relative time, got %s", got)
+	}
+	if got := msg.JSON(); !strings.Contains(got, `"lastModified":"2024-11-05T10:00:00Z"`) {
+		t.Fatalf("expected an absolute time in JSON, got %s", got)
+	}
+}
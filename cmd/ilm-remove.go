@@ -127,6 +127,7 @@ func mainILMRemove(cliCtx *cli.Context) error {
 
 	client, err := newClient(urlStr)
 	fatalIf(err.Trace(args...), "Unable to initialize client for "+urlStr+".")
+	defer client.Close()
 
 	ilmCfg, _, err := client.GetLifecycle(ctx)
 	fatalIf(err.Trace(urlStr), "Unable to fetch lifecycle rules")
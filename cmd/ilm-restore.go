@@ -26,6 +26,7 @@ import (
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
 )
 
 // ilm restore specific flags.
@@ -48,6 +49,11 @@ var (
 			Name:  "version-id, vid",
 			Usage: "select a specific version id",
 		},
+		cli.StringFlag{
+			Name:  "tier",
+			Value: string(minio.TierExpedited),
+			Usage: restoreTierFlag.Usage,
+		},
 	}
 )
 
@@ -87,6 +93,9 @@ EXAMPLES:
 
   5. Restore an SSE-C encrypted object.
      {{.Prompt}} {{.HelpName}} --encrypt-key "myminio/mybucket/=MzJieXRlc2xvbmdzZWNyZWFiY2RlZmcJZ2l2ZW5uMjE=" myminio/mybucket/myobject.txt
+
+  6. Restore all objects under a specific prefix with the cheaper Bulk tier
+     {{.Prompt}} {{.HelpName}} --recursive --tier Bulk myminio/mybucket/dir/
 `,
 }
 
@@ -106,17 +115,24 @@ func checkILMRestoreSyntax(ctx *cli.Context) {
 }
 
 // Send Restore S3 API
-func restoreObject(ctx context.Context, targetAlias, targetURL, versionID string, days int) *probe.Error {
+func restoreObject(ctx context.Context, targetAlias, targetURL, versionID string, days int, tier minio.TierType) *probe.Error {
 	clnt, err := newClientFromAlias(targetAlias, targetURL)
 	if err != nil {
 		return err
 	}
 
-	return clnt.Restore(ctx, versionID, days)
+	err = clnt.Restore(ctx, versionID, days, tier)
+	if err != nil {
+		if _, ok := err.ToGoError().(ObjectRestoreInProgress); ok {
+			// The restore was requested earlier, wait for it as well.
+			return nil
+		}
+	}
+	return err
 }
 
 // Send restore S3 API request to one or more objects depending on the arguments
-func sendRestoreRequests(ctx context.Context, targetAlias, targetURL, targetVersionID string, recursive, applyOnVersions bool, days int, restoreSentReq chan *probe.Error) {
+func sendRestoreRequests(ctx context.Context, targetAlias, targetURL, targetVersionID string, recursive, applyOnVersions bool, days int, tier minio.TierType, restoreSentReq chan *probe.Error) {
 	defer close(restoreSentReq)
 
 	client, err := newClientFromAlias(targetAlias, targetURL)
@@ -126,7 +142,7 @@ func sendRestoreRequests(ctx context.Context, targetAlias, targetURL, targetVers
 	}
 
 	if !recursive {
-		err := restoreObject(ctx, targetAlias, targetURL, targetVersionID, days)
+		err := restoreObject(ctx, targetAlias, targetURL, targetVersionID, days, tier)
 		restoreSentReq <- err
 		return
 	}
@@ -141,7 +157,7 @@ func sendRestoreRequests(ctx context.Context, targetAlias, targetURL, targetVers
 			errorIf(content.Err.Trace(client.GetURL().String()), "Unable to list folder.")
 			continue
 		}
-		err := restoreObject(ctx, targetAlias, content.URL.String(), content.VersionID, days)
+		err := restoreObject(ctx, targetAlias, content.URL.String(), content.VersionID, days, tier)
 		if err != nil {
 			restoreSentReq <- err
 			continue
@@ -310,6 +326,7 @@ func mainILMRestore(cliCtx *cli.Context) (cErr error) {
 	recursive := cliCtx.Bool("recursive")
 	includeVersions := cliCtx.Bool("versions")
 	days := cliCtx.Int("days")
+	tier := mustParseRestoreTier(cliCtx)
 
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
@@ -328,7 +345,7 @@ func mainILMRestore(cliCtx *cli.Context) (cErr error) {
 		showRestoreStatus(restoreReqStatus, restoreStatus, done)
 	}()
 
-	sendRestoreRequests(ctx, targetAlias, targetURL, versionID, recursive, includeVersions, days, restoreReqStatus)
+	sendRestoreRequests(ctx, targetAlias, targetURL, versionID, recursive, includeVersions, days, tier, restoreReqStatus)
 	checkRestoreStatus(ctx, targetAlias, targetURL, versionID, recursive, includeVersions, encKeyDB, restoreStatus)
 
 	// Wait until the UI printed all the status
@@ -120,6 +120,7 @@ func mainILMEdit(cliCtx *cli.Context) error {
 
 	client, err := newClient(urlStr)
 	fatalIf(err.Trace(urlStr), "Unable to initialize client for "+urlStr)
+	defer client.Close()
 
 	// Configuration that is already set.
 	lfcCfg, _, err := client.GetLifecycle(ctx)
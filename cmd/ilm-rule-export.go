@@ -93,6 +93,7 @@ func mainILMExport(cliCtx *cli.Context) error {
 
 	client, err := newClient(urlStr)
 	fatalIf(err.Trace(args...), "Unable to initialize client for "+urlStr+".")
+	defer client.Close()
 
 	ilmCfg, updatedAt, err := client.GetLifecycle(ctx)
 	fatalIf(err.Trace(args...), "Unable to get lifecycle configuration")
@@ -101,6 +101,7 @@ func mainILMImport(cliCtx *cli.Context) error {
 
 	client, err := newClient(urlStr)
 	fatalIf(err.Trace(urlStr), "Unable to initialize client for "+urlStr)
+	defer client.Close()
 
 	ilmCfg, err := readILMConfig()
 	fatalIf(err.Trace(args...), "Unable to read ILM configuration")
@@ -141,6 +141,7 @@ func mainILMList(cliCtx *cli.Context) error {
 	}
 	client, err := newClient(urlStr)
 	fatalIf(err.Trace(urlStr), "Unable to initialize client for "+urlStr)
+	defer client.Close()
 
 	ilmCfg, updatedAt, err := client.GetLifecycle(ctx)
 	fatalIf(err.Trace(args...), "Unable to get lifecycle")
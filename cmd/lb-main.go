@@ -0,0 +1,144 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// List buckets command.
+var lbCmd = cli.Command{
+	Name:         "lb",
+	Usage:        "list buckets",
+	Action:       mainListBuckets,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] [ALIAS]
+
+DESCRIPTION:
+  List the buckets the credentials of ALIAS can see, ALIAS is an alias set
+  with 'mc alias set' and defaults to 'gpumall'. The gpumall token of 'mc auth'
+  only grants access to your directory, listing the buckets of gpumall needs
+  the credentials of --credentials-file.
+
+FORMAT FIELDS:
+  --format templates are applied to every bucket and may use the fields
+  Status, Name and CreationDate, as well as the functions 'humanize SIZE'
+  and 'formatTime LAYOUT TIME'.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List the buckets of the alias 'play'
+    {{.Prompt}} {{.HelpName}} play
+  2. List the buckets of gpumall with the credentials of a file
+    {{.Prompt}} {{.HelpName}} --credentials-file creds.json
+`,
+}
+
+// errListBucketsToken is returned when listing the buckets of gpumall
+// without the credentials of --credentials-file.
+var errListBucketsToken = errors.New("the gpumall token only grants access to your directory, pass --credentials-file to list buckets")
+
+// bucketMessage describes a bucket listed by lb.
+type bucketMessage struct {
+	Status       string    `json:"status"`
+	Name         string    `json:"name"`
+	CreationDate time.Time `json:"creationDate"`
+}
+
+func (bucketMessage) isRecord() {}
+
+// Colorized message for console printing.
+func (b bucketMessage) String() string {
+	return console.Colorize("Time", fmt.Sprintf("[%s] ", b.CreationDate.Format(printDate))) +
+		console.Colorize("Dir", b.Name+"/")
+}
+
+// JSON'ified message for scripting.
+func (b bucketMessage) JSON() string {
+	b.Status = "success"
+	msgBytes, e := json.MarshalIndent(b, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// listBuckets returns the buckets the credentials of alias can see.
+func listBuckets(ctx context.Context, alias string) ([]bucketMessage, *probe.Error) {
+	if alias == AuthAlias && globalCredentials == nil {
+		return nil, probe.NewError(errListBucketsToken)
+	}
+	clnt, err := newClient(alias)
+	if err != nil {
+		return nil, err.Trace(alias)
+	}
+	buckets, err := clnt.ListBuckets(ctx)
+	if err != nil {
+		return nil, err.Trace(alias)
+	}
+	msgs := make([]bucketMessage, 0, len(buckets))
+	for _, b := range buckets {
+		msgs = append(msgs, bucketMessage{Name: b.BucketName, CreationDate: b.Time})
+	}
+	return msgs, nil
+}
+
+// mainListBuckets is the entry point for lb command.
+func mainListBuckets(cliCtx *cli.Context) error {
+	ctx, cancelListBuckets := context.WithCancel(globalContext)
+	defer cancelListBuckets()
+
+	// Additional command specific theme customization.
+	console.SetColor("Dir", color.New(color.FgCyan, color.Bold))
+	console.SetColor("Time", color.New(color.FgGreen))
+
+	args := cliCtx.Args()
+	if len(args) > 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	alias := strings.TrimSuffix(args.First(), "/")
+	if alias == "" {
+		alias = AuthAlias
+	}
+	if strings.Contains(alias, "/") {
+		fatalIf(errInvalidArgument().Trace(alias), "Unable to list buckets, `"+alias+"` is not an alias.")
+	}
+
+	buckets, err := listBuckets(ctx, alias)
+	fatalIf(err, "Unable to list buckets of `"+alias+"`.")
+	for _, b := range buckets {
+		printMsg(b)
+	}
+	return nil
+}
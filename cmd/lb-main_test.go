@@ -0,0 +1,70 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestListBuckets(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	created := time.Now().UTC().Truncate(time.Second)
+	server.MakeBucket("alpha")
+	server.MakeBucket("beta")
+	t.Setenv(mcEnvHostPrefix+"fake", server.AliasURL())
+
+	buckets, err := listBuckets(context.Background(), "fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"alpha", "beta"}
+	if len(buckets) != len(want) {
+		t.Fatalf("expected %d buckets, got %d", len(want), len(buckets))
+	}
+	for i := range want {
+		if buckets[i].Name != want[i] || buckets[i].CreationDate.Before(created) || buckets[i].CreationDate.After(time.Now()) {
+			t.Errorf("expected the bucket %s created at %s, got %+v", want[i], created, buckets[i])
+		}
+	}
+	if got := buckets[0].String(); !strings.Contains(got, "alpha/") || !strings.Contains(got, buckets[0].CreationDate.Format(printDate)) {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	var fields map[string]interface{}
+	if e := json.Unmarshal([]byte(buckets[1].JSON()), &fields); e != nil {
+		t.Fatal(e)
+	}
+	if fields["status"] != "success" || fields["name"] != "beta" || fields["creationDate"] != buckets[1].CreationDate.Format(time.RFC3339) {
+		t.Errorf("unexpected JSON %v", fields)
+	}
+
+	defer func(creds *AuthData) { globalCredentials = creds }(globalCredentials)
+	globalCredentials = nil
+	if _, err := listBuckets(context.Background(), AuthAlias); err == nil || !errors.Is(err.ToGoError(), errListBucketsToken) {
+		t.Errorf("expected %v, got %v", errListBucketsToken, err)
+	}
+}
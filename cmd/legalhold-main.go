@@ -21,7 +21,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/minio/cli"
@@ -123,10 +122,10 @@ func getBucketLockStatus(ctx context.Context, aliasedURL string) (status string,
 	status, _, _, _, err = clnt.GetObjectLockConfig(ctx)
 	if err != nil {
 		errResp := minio.ToErrorResponse(err.ToGoError())
-		switch {
-		case errResp.Code == "ObjectLockConfigurationNotFoundError":
+		if errResp.Code == "ObjectLockConfigurationNotFoundError" {
 			return "", probe.NewError(errObjectLockConfigNotFound)
-		case errResp.StatusCode == http.StatusNotImplemented:
+		}
+		if _, ok := err.ToGoError().(FeatureNotSupported); ok {
 			return "", probe.NewError(errObjectLockNotSupported)
 		}
 		return "", err
@@ -0,0 +1,151 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// listMaxKeysPerRequest is the most keys a server returns per listing request.
+const listMaxKeysPerRequest = 1000
+
+// listLimitFlags are the pagination flags of ls and find.
+var listLimitFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "limit",
+		Usage: "stop listing after this many results, printing the key to resume from",
+	},
+	cli.StringFlag{
+		Name:  "start-after, marker",
+		Usage: "only list the keys after this one, such as the key printed by --limit",
+	},
+}
+
+// listLimit stops a listing after a number of results, a nil listLimit
+// lists everything.
+type listLimit struct {
+	limit      int
+	startAfter string
+
+	emitted int
+	lastKey string
+	stopped bool
+}
+
+// parseListLimitFlags returns the --limit and --start-after of cliCtx.
+func parseListLimitFlags(cliCtx *cli.Context) (*listLimit, error) {
+	l := &listLimit{limit: cliCtx.Int("limit"), startAfter: cliCtx.String("start-after")}
+	if l.limit < 0 {
+		return nil, errors.New("--limit cannot be negative")
+	}
+	return l, nil
+}
+
+// checkTarget returns an error if --start-after is set for a listing
+// of a local directory, or of several buckets: keys are only ordered
+// within a bucket.
+func (l *listLimit) checkTarget(clnt Client) error {
+	clntURL := clnt.GetURL()
+	if l == nil || l.startAfter == "" {
+		return nil
+	}
+	if clntURL.Type != objectStorage {
+		return errors.New("--start-after is only supported for object storage")
+	}
+	if bucket, _ := url2BucketAndObject(&clntURL); bucket == "" {
+		return errors.New("--start-after needs a bucket to list")
+	}
+	return nil
+}
+
+// listOptions sets the start key and the size of the listing requests
+// of opts, so that no more keys than needed are fetched: one more than
+// the limit tells whether the listing stopped early.
+func (l *listLimit) listOptions(opts ListOptions) ListOptions {
+	if l == nil {
+		return opts
+	}
+	opts.StartAfter = l.startAfter
+	if l.limit > 0 && l.limit < listMaxKeysPerRequest {
+		opts.MaxKeys = l.limit + 1
+	}
+	return opts
+}
+
+// next accounts for a result listed at key, it returns false if the
+// limit was already reached and the listing must stop.
+func (l *listLimit) next(key string) bool {
+	if l == nil {
+		return true
+	}
+	if l.limit > 0 && l.emitted >= l.limit {
+		l.stopped = true
+		return false
+	}
+	l.emitted++
+	l.lastKey = key
+	return true
+}
+
+// listCheckpointMessage is the key to resume a listing stopped by --limit.
+type listCheckpointMessage struct {
+	Status     string `json:"status"`
+	Type       string `json:"type"`
+	Count      int    `json:"count"`
+	StartAfter string `json:"startAfter"`
+}
+
+func (m listCheckpointMessage) String() string {
+	return fmt.Sprintf("Listing stopped after %d results, resume it with --start-after %q", m.Count, m.StartAfter)
+}
+
+func (m listCheckpointMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// printCheckpoint prints the key to resume a listing stopped by --limit,
+// along with the results in JSON and on the status output otherwise.
+func (l *listLimit) printCheckpoint() {
+	if l == nil || !l.stopped {
+		return
+	}
+	msg := listCheckpointMessage{Status: "success", Type: "checkpoint", Count: l.emitted, StartAfter: l.lastKey}
+	if globalJSON {
+		printMsg(msg)
+		return
+	}
+	statusf("%s\n", msg)
+}
+
+// contentKey returns the key of a listed object within its bucket, the
+// value --start-after expects.
+func contentKey(content *ClientContent) string {
+	if content.URL.Type != objectStorage {
+		return content.URL.Path
+	}
+	_, key := url2BucketAndObject(&content.URL)
+	return key
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/internal/miniotest"
+)
+
+// firstListRequests returns the queries of the first request of every
+// listing received by server, dropping the pages a canceled listing may
+// have fetched ahead.
+func firstListRequests(server *miniotest.Server) (queries []url.Values) {
+	for _, r := range server.Requests() {
+		if r.Method == http.MethodGet && r.Key == "" && r.Query.Has("list-type") && r.Query.Get("continuation-token") == "" {
+			queries = append(queries, r.Query)
+		}
+	}
+	server.ResetRequests()
+	return queries
+}
+
+func newListObjectsTestClient(t *testing.T, keys int) (*miniotest.Server, Client) {
+	server := newS3TestServer(t)
+	for i := 0; i < keys; i++ {
+		server.PutObject("bucket", fmt.Sprintf("k%02d", i), []byte("a"))
+	}
+	return server, newS3TestClient(t, "s3test/bucket/")
+}
+
+func TestListStopsWhenCanceled(t *testing.T) {
+	_, clnt := newListObjectsTestClient(t, 2500)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	contentCh := clnt.List(ctx, ListOptions{Recursive: true, MaxKeys: 10})
+	for i := 0; i < 3; i++ {
+		if content := <-contentCh; content == nil || content.Err != nil {
+			t.Fatalf("unexpected content %v", content)
+		}
+	}
+	cancel()
+
+	// The listing goroutine exits instead of blocking on a send.
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case _, ok := <-contentCh:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("the listing did not stop after its context was canceled")
+		}
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	var out, status bytes.Buffer
+	defer func(output, sOutput io.Writer) {
+		color.Output, statusOutput = output, sOutput
+	}(color.Output, statusOutput)
+	color.Output, statusOutput = &out, &status
+	defer func(j bool) { globalJSON = j }(globalJSON)
+	globalJSON = true
+
+	server, clnt := newListObjectsTestClient(t, 25)
+	if e := doList(context.Background(), clnt, doListOptions{isRecursive: true, limit: &listLimit{limit: 5}}); e != nil {
+		t.Fatal(e)
+	}
+	if got := strings.Count(out.String(), `"key"`); got != 5 {
+		t.Fatalf("expected 5 listed objects, got %d: %s", got, out.String())
+	}
+	if queries := firstListRequests(server); len(queries) != 1 || queries[0].Get("max-keys") != "6" {
+		t.Fatalf("expected listing requests of 6 keys, got %v", queries)
+	}
+	if !strings.Contains(strings.Join(strings.Fields(out.String()), ""), `"startAfter":"k04"`) {
+		t.Fatalf("expected the key to resume from, got %q", out.String())
+	}
+
+	// Resume the listing after the last key printed.
+	out.Reset()
+	status.Reset()
+	if e := doList(context.Background(), clnt, doListOptions{isRecursive: true, limit: &listLimit{startAfter: "k04"}}); e != nil {
+		t.Fatal(e)
+	}
+	if got := strings.Count(out.String(), `"key"`); got != 20 || !strings.Contains(out.String(), `"key":"k05"`) || strings.Contains(out.String(), `"key":"k04"`) {
+		t.Fatalf("expected the 20 objects after k04, got %s", out.String())
+	}
+	if queries := firstListRequests(server); len(queries) != 1 || queries[0].Get("start-after") != "k04" {
+		t.Fatalf("expected the listing to start after k04, got %v", queries)
+	}
+	if status.Len() != 0 || strings.Contains(out.String(), "startAfter") {
+		t.Fatalf("expected no checkpoint for a complete listing, got %q", out.String())
+	}
+
+	// The checkpoint goes to the status output without --json.
+	globalJSON = false
+	out.Reset()
+	if e := doList(context.Background(), clnt, doListOptions{isRecursive: true, limit: &listLimit{limit: 5}}); e != nil {
+		t.Fatal(e)
+	}
+	if !strings.Contains(status.String(), `--start-after "k04"`) || strings.Contains(out.String(), "start-after") {
+		t.Fatalf("expected the key to resume from on the status output, got %q", status.String())
+	}
+}
+
+func TestListLimitStartAfterFilesystem(t *testing.T) {
+	clnt, err := fsNew(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (&listLimit{startAfter: "a"}).checkTarget(clnt); e == nil {
+		t.Fatal("expected --start-after to be rejected for a local directory")
+	}
+	if e := (&listLimit{limit: 1}).checkTarget(clnt); e != nil {
+		t.Fatal(e)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// listingTrie holds the keys of a full listing split at every "/", the
+// entries of a folder are the children of its node. Folders keep their
+// trailing "/" so that an object and a folder of the same name differ.
+type listingTrie struct {
+	root    *listingTrieNode
+	objects int
+	builtAt time.Time
+}
+
+type listingTrieNode struct {
+	children map[string]*listingTrieNode
+	// content is the object of the key ending at this node, if any.
+	content *ClientContent
+	// names are the sorted names of children, computed on first use.
+	names []string
+}
+
+// listingEntry is an entry of a folder, an object or a folder named with
+// a trailing "/".
+type listingEntry struct {
+	name    string
+	content *ClientContent
+}
+
+func newListingTrie() *listingTrie {
+	return &listingTrie{root: &listingTrieNode{}, builtAt: time.Now()}
+}
+
+// insert adds the object content at key, relative to the listed folder.
+func (t *listingTrie) insert(key string, content *ClientContent) {
+	node := t.root
+	for _, name := range strings.SplitAfter(key, "/") {
+		if name == "" {
+			continue
+		}
+		child, ok := node.children[name]
+		if !ok {
+			if node.children == nil {
+				node.children = map[string]*listingTrieNode{}
+			}
+			child = &listingTrieNode{}
+			node.children[name] = child
+			node.names = nil
+		}
+		node = child
+	}
+	if node.content == nil {
+		t.objects++
+	}
+	node.content = content
+}
+
+// list returns the entries of folder, "" or a path ending with "/", in
+// the order of a listing, and false if there is no such folder.
+func (t *listingTrie) list(folder string) ([]listingEntry, bool) {
+	node := t.root
+	for _, name := range strings.SplitAfter(folder, "/") {
+		if name == "" {
+			continue
+		}
+		if node = node.children[name]; node == nil || !strings.HasSuffix(name, "/") {
+			return nil, false
+		}
+	}
+	if node.names == nil {
+		node.names = make([]string, 0, len(node.children))
+		for name := range node.children {
+			node.names = append(node.names, name)
+		}
+		sort.Strings(node.names)
+	}
+	entries := make([]listingEntry, 0, len(node.names))
+	for _, name := range node.names {
+		entry := listingEntry{name: name}
+		if !strings.HasSuffix(name, "/") {
+			entry.content = node.children[name].content
+		}
+		entries = append(entries, entry)
+	}
+	return entries, true
+}
+
+// expired tells if the trie is older than ttl, it never expires for a
+// ttl of 0.
+func (t *listingTrie) expired(ttl time.Duration) bool {
+	return ttl > 0 && time.Since(t.builtAt) >= ttl
+}
+
+// buildListingTrie lists everything under the folder of clnt into a trie.
+func buildListingTrie(ctx context.Context, clnt Client) (*listingTrie, *probe.Error) {
+	t := newListingTrie()
+	folder := listingFolderPath(clnt)
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(clnt.GetURL().String())
+		}
+		key := strings.TrimPrefix(filepath.ToSlash(content.URL.Path), folder)
+		if key == "" || strings.HasSuffix(key, "/") {
+			// Folder markers are folders of the trie already.
+			continue
+		}
+		t.insert(key, content)
+	}
+	t.builtAt = time.Now()
+	return t, nil
+}
+
+// listFolder lists the entries of the folder of clnt from the server, in
+// the same form as listingTrie.list.
+func listFolder(ctx context.Context, clnt Client) ([]listingEntry, *probe.Error) {
+	folder := listingFolderPath(clnt)
+	var entries []listingEntry
+	for content := range clnt.List(ctx, ListOptions{ShowDir: DirFirst}) {
+		if content.Err != nil {
+			if _, ok := content.Err.ToGoError().(PathNotFound); ok {
+				break
+			}
+			return nil, content.Err.Trace(clnt.GetURL().String())
+		}
+		name := strings.TrimPrefix(filepath.ToSlash(content.URL.Path), folder)
+		if name == "" {
+			continue
+		}
+		entry := listingEntry{name: name}
+		if content.Type.IsDir() {
+			entry.name = strings.TrimSuffix(name, "/") + "/"
+		} else {
+			entry.content = content
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries, nil
+}
+
+// listingFolderPath returns the path of the folder of clnt, ending with
+// a "/".
+func listingFolderPath(clnt Client) string {
+	folder := filepath.ToSlash(clnt.GetURL().Path)
+	if !strings.HasSuffix(folder, "/") {
+		folder += "/"
+	}
+	return folder
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+// listingTestServer serves keys in the bucket "bucket" of the alias
+// "trie".
+func listingTestServer(tb testing.TB, keys []string) *miniotest.Server {
+	server := miniotest.NewServer()
+	tb.Cleanup(server.Close)
+	server.MakeBucket("bucket")
+	for _, key := range keys {
+		server.PutObject("bucket", key, []byte(key))
+	}
+	tb.Setenv(mcEnvHostPrefix+"trie", server.AliasURL())
+	return server
+}
+
+func formatListingEntries(entries []listingEntry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(entry.name)
+		if entry.content != nil {
+			fmt.Fprintf(&b, "(%d,%s)", entry.content.Size, entry.content.ETag)
+		}
+		b.WriteString(" ")
+	}
+	return b.String()
+}
+
+func TestListingTrie(t *testing.T) {
+	initTestConfig(t)
+	keys := []string{"top", "a-b", "a/x", "a/b/c", "a/b/d", "a/b/e/f", "a", "dir/", "dir/in", "z/y/x/w"}
+	listingTestServer(t, keys)
+	ctx := context.Background()
+
+	clnt, err := newClient("trie/bucket/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie, err := buildListingTrie(ctx, clnt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trie.objects != len(keys)-1 {
+		t.Fatalf("expected %d objects in the trie, got %d", len(keys)-1, trie.objects)
+	}
+
+	// The entries of every folder are those listed by the server.
+	for _, folder := range []string{"", "a/", "a/b/", "a/b/e/", "dir/", "z/", "z/y/x/"} {
+		entries, ok := trie.list(folder)
+		if !ok {
+			t.Fatalf("expected folder %q in the trie", folder)
+		}
+		folderClnt, err := newClient("trie/bucket/" + folder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := listFolder(ctx, folderClnt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := formatListingEntries(entries), formatListingEntries(expected); got != want {
+			t.Fatalf("folder %q: expected %q, got %q", folder, want, got)
+		}
+	}
+	for _, folder := range []string{"missing/", "top/", "a/x/"} {
+		if _, ok := trie.list(folder); ok {
+			t.Fatalf("expected no folder %q in the trie", folder)
+		}
+	}
+
+	if trie.expired(0) || trie.expired(time.Hour) {
+		t.Fatal("expected a new trie not to be expired")
+	}
+	trie.builtAt = time.Now().Add(-2 * time.Hour)
+	if !trie.expired(time.Hour) || trie.expired(0) {
+		t.Fatal("expected the trie to expire after its TTL only")
+	}
+}
+
+func TestExplorer(t *testing.T) {
+	initTestConfig(t)
+	server := listingTestServer(t, []string{"a/b/c", "a/x", "top"})
+	ctx := context.Background()
+
+	x := newExplorer("trie/bucket", true, time.Hour)
+	for path, expected := range map[string]string{"": "", "a": "a/", "/a/b/": "a/b/", "..": ""} {
+		if got := x.resolve(path); got != expected {
+			t.Fatalf("expected %q to resolve to %q, got %q", path, expected, got)
+		}
+	}
+	x.exec(ctx, "cd a")
+	if x.cwd != "a/" {
+		t.Fatalf("expected to be in a/, got %q", x.cwd)
+	}
+	for path, expected := range map[string]string{"": "a/", "b": "a/b/", "..": "", "../..": "", "/top": "top/"} {
+		if got := x.resolve(path); got != expected {
+			t.Fatalf("expected %q to resolve to %q from a/, got %q", path, expected, got)
+		}
+	}
+	x.exec(ctx, "cd missing")
+	if x.cwd != "a/" {
+		t.Fatalf("expected cd to a missing folder to fail, in %q", x.cwd)
+	}
+
+	// Further lookups are answered from the cached listing, until it is
+	// refreshed.
+	lists := server.RequestCount("GET")
+	entries, err := x.list(ctx, "a/b/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatListingEntries(entries); !strings.HasPrefix(got, "c(") {
+		t.Fatalf("expected a/b/ to hold c, got %q", got)
+	}
+	server.PutObject("bucket", "a/new", []byte("new"))
+	if entries, _ = x.list(ctx, "a/"); strings.Contains(formatListingEntries(entries), "new") {
+		t.Fatal("expected the cached listing not to show a new object")
+	}
+	if n := server.RequestCount("GET"); n != lists {
+		t.Fatalf("expected no listing from the cache, got %d", n-lists)
+	}
+	if !x.exec(ctx, "refresh") {
+		t.Fatal("expected refresh not to exit")
+	}
+	if entries, _ = x.list(ctx, "a/"); !strings.Contains(formatListingEntries(entries), "new") {
+		t.Fatal("expected the refreshed listing to show the new object")
+	}
+
+	if x.exec(ctx, "exit") {
+		t.Fatal("expected exit to stop")
+	}
+	if err = x.run(ctx, strings.NewReader("cd /\npwd\nls\n"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if x.cwd != "" {
+		t.Fatalf("expected to be back in the root, got %q", x.cwd)
+	}
+}
+
+// benchmarkListingKeys are 2000 objects in 20 folders of 10 folders.
+func benchmarkListingKeys() (keys []string) {
+	for i := 0; i < 2000; i++ {
+		keys = append(keys, fmt.Sprintf("f%02d/g%d/object%04d", i%20, i%10, i))
+	}
+	return keys
+}
+
+func BenchmarkListingTrie(b *testing.B) {
+	initTestConfig(b)
+	listingTestServer(b, benchmarkListingKeys())
+	x := newExplorer("trie/bucket", true, 0)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := x.list(ctx, fmt.Sprintf("f%02d/g%d/", i%20, i%10)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListingServer(b *testing.B) {
+	initTestConfig(b)
+	listingTestServer(b, benchmarkListingKeys())
+	x := newExplorer("trie/bucket", false, 0)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := x.list(ctx, fmt.Sprintf("f%02d/g%d/", i%20, i%10)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -60,6 +60,11 @@ var (
 			Name:  "zip",
 			Usage: "list files inside zip archive (MinIO servers only)",
 		},
+		cli.BoolFlag{
+			Name:  "with-metadata",
+			Usage: "display the owner, content type and user metadata of objects",
+		},
+		print0Flag,
 	}
 )
 
@@ -70,7 +75,7 @@ var lsCmd = cli.Command{
 	Action:       mainList,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(lsFlags, globalFlags...),
+	Flags:        append(append(append(append(append(append(lsFlags, lsSortFlags...), humanizeTimeFlags...), listLimitFlags...), csvFlags...), compatFlag), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -80,6 +85,22 @@ USAGE:
 FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
+ENVIRONMENT VARIABLES:
+  MC_THEME_LARGE_SIZE:  highlight objects of at least this size, defaults to 1GiB
+  MC_THEME_OLD_AGE:     highlight objects modified longer ago than this, defaults to 365d
+
+FORMAT FIELDS:
+  --format templates are applied to every listed object and may use the fields
+  Status, Filetype, Time, Size, Key, ETag, URL, VersionID, VersionOrd,
+  VersionIndex, IsDeleteMarker, StorageClass, Metadata, Tags, and with
+  --with-metadata Owner and UserMetadata, as well as
+  the functions 'humanize SIZE' and 'formatTime LAYOUT TIME'.
+
+SORTING:
+  --sort and --reverse print the listing once complete, ties keep the key
+  order. With --limit only that many first results of the sorted listing
+  are kept in memory and printed, without a key to resume from.
+
 EXAMPLES:
   1. List buckets on Amazon S3 cloud storage.
      {{.Prompt}} {{.HelpName}} s3
@@ -112,6 +133,35 @@ EXAMPLES:
   
   10. List all objects on mybucket, for the GLACIER storage class
      {{.Prompt}} {{.HelpName}} --storage-class 'GLACIER' s3/mybucket 
+
+  11. List the name and human readable size of all objects on mybucket, separated by a tab.
+     {{.Prompt}} {{.HelpName}} --recursive --format '{{"{{.Key}}\\t{{humanize .Size}}"}}' s3/mybucket
+
+  12. List all objects on mybucket with the high-contrast theme, highlighting objects of 100MiB or more.
+     {{.Prompt}} MC_THEME_LARGE_SIZE=100MiB {{.HelpName}} --recursive --theme high-contrast s3/mybucket
+
+  13. List the names of all objects on mybucket followed by a NUL character, for names holding newlines.
+     {{.Prompt}} {{.HelpName}} --recursive --print0 s3/mybucket | xargs -0 -n1 echo
+
+  14. List all objects on mybucket with sizes in SI units (GB instead of GiB).
+     {{.Prompt}} {{.HelpName}} --recursive --units si s3/mybucket
+
+  15. List all objects on mybucket with how long ago they were modified, for the last 30 days.
+     {{.Prompt}} {{.HelpName}} --recursive --humanize-time s3/mybucket
+
+  16. List the first 1000 objects on mybucket, then the next 1000 from the key printed by the first listing.
+     {{.Prompt}} {{.HelpName}} --recursive --limit 1000 s3/mybucket
+     {{.Prompt}} {{.HelpName}} --recursive --limit 1000 --start-after "photos/2023/0999.jpg" s3/mybucket
+
+  17. List all objects on mybucket with their owner, content type and user metadata.
+     {{.Prompt}} {{.HelpName}} --recursive --with-metadata s3/mybucket
+
+  18. List the 50 largest objects on mybucket, then its oldest objects first.
+     {{.Prompt}} {{.HelpName}} --recursive --sort size --limit 50 s3/mybucket
+     {{.Prompt}} {{.HelpName}} --recursive --sort time --reverse s3/mybucket
+
+  19. List all objects on mybucket like 'aws s3 ls', for the scripts reading its output.
+     {{.Prompt}} {{.HelpName}} --recursive --compat aws s3/mybucket
 `,
 }
 
@@ -168,17 +218,39 @@ func checkListSyntax(cliCtx *cli.Context) ([]string, doListOptions) {
 		}
 	}
 
+	fatalIf(probe.NewError(parseHumanizeTimeFlags(cliCtx)), "Unable to parse --humanize-time.")
+
 	isRecursive := cliCtx.Bool("recursive")
 	isIncomplete := cliCtx.Bool("incomplete")
 	withOlderVersions := cliCtx.Bool("versions")
 	isSummary := cliCtx.Bool("summarize")
 	listZip := cliCtx.Bool("zip")
+	withMetadata := cliCtx.Bool("with-metadata")
+	print0 := cliCtx.Bool("print0")
 
 	timeRef := parseRewindFlag(cliCtx.String("rewind"))
 
 	if listZip && (withOlderVersions || !timeRef.IsZero()) {
 		fatalIf(errInvalidArgument().Trace(args...), "Zip file listing can only be performed on the latest version")
 	}
+	checkPrint0Syntax(cliCtx)
+	if print0 && (withOlderVersions || isSummary) {
+		fatalIf(errInvalidArgument().Trace(args...), "--print0 cannot be used with --versions or --summarize.")
+	}
+	limit, e := parseListLimitFlags(cliCtx)
+	fatalIf(probe.NewError(e), "Invalid --limit.")
+	if (limit.limit > 0 || limit.startAfter != "") && len(args) > 1 {
+		fatalIf(errInvalidArgument().Trace(args...), "--limit and --start-after can only be used with a single target.")
+	}
+
+	sortOpts, e := newLsSortOptionsFromContext(cliCtx)
+	fatalIf(probe.NewError(e), "Invalid --sort.")
+	if sortOpts != nil {
+		// --limit keeps the first results of the sorted listing, the
+		// whole prefix has to be listed to find them.
+		sortOpts.limit, limit.limit = limit.limit, 0
+	}
+
 	storageClasss := cliCtx.String("storage-class")
 	opts := doListOptions{
 		timeRef:           timeRef,
@@ -187,7 +259,11 @@ func checkListSyntax(cliCtx *cli.Context) ([]string, doListOptions) {
 		isSummary:         isSummary,
 		withOlderVersions: withOlderVersions,
 		listZip:           listZip,
+		withMetadata:      withMetadata,
+		print0:            print0,
 		filter:            storageClasss,
+		limit:             limit,
+		sort:              sortOpts,
 	}
 	return args, opts
 }
@@ -198,25 +274,30 @@ func mainList(cliCtx *cli.Context) error {
 	defer cancelList()
 
 	// Additional command specific theme customization.
-	console.SetColor("File", color.New(color.Bold))
 	console.SetColor("DEL", color.New(color.FgRed))
 	console.SetColor("PUT", color.New(color.FgGreen))
 	console.SetColor("VersionID", color.New(color.FgHiBlue))
 	console.SetColor("VersionOrd", color.New(color.FgHiMagenta))
-	console.SetColor("Dir", color.New(color.FgCyan, color.Bold))
-	console.SetColor("Size", color.New(color.FgYellow))
-	console.SetColor("Time", color.New(color.FgGreen))
 	console.SetColor("Summarize", color.New(color.Bold))
 	console.SetColor("SC", color.New(color.FgBlue))
+	console.SetColor("Owner", color.New(color.FgYellow))
+	console.SetColor("Metadata", color.New(color.FgCyan))
+	globalTheme.apply()
 
 	// check 'ls' cliCtx arguments.
 	args, opts := checkListSyntax(cliCtx)
 
+	// Resolve all targets first, gpumall paths fail fast without a valid token.
+	targetURLs := make([]string, len(args))
+	for i, arg := range args {
+		targetURLs[i] = getFullPath(arg)
+	}
+
 	var cErr error
-	for _, targetURL := range args {
-		targetURL = getFullPath(targetURL)
+	for _, targetURL := range targetURLs {
 		clnt, err := newClient(targetURL)
 		fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+		defer clnt.Close()
 		if !strings.HasSuffix(targetURL, string(clnt.GetURL().Separator)) {
 			var st *ClientContent
 			st, err = clnt.Stat(ctx, StatOptions{incomplete: opts.isIncomplete})
@@ -226,9 +307,11 @@ func mainList(cliCtx *cli.Context) error {
 				fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
 			}
 		}
+		fatalIf(probe.NewError(opts.limit.checkTarget(clnt)).Trace(targetURL), "Unable to resume the listing of `"+targetURL+"`.")
 		if e := doList(ctx, clnt, opts); e != nil {
 			cErr = e
 		}
 	}
+	closeCompatOutput()
 	return cErr
 }
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestListWithMetadata(t *testing.T) {
+	for _, listMetadata := range []bool{true, false} {
+		server := newS3TestServer(t)
+		server.NoListMetadata = !listMetadata
+		var keys []string
+		var etag string
+		for i := 0; i < 3*listMetadataConcurrency; i++ {
+			key := fmt.Sprintf("k%02d", i)
+			keys = append(keys, key)
+			etag = server.PutObject("bucket", key, []byte("a"), "X-Amz-Meta-Name", key).ETag
+		}
+
+		var mu sync.Mutex
+		var heads, inFlight, peak int
+		server.Observe = func(r miniotest.Request) {
+			if r.Method != http.MethodHead {
+				return
+			}
+			mu.Lock()
+			heads++
+			inFlight++
+			if inFlight > peak {
+				peak = inFlight
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}
+		clnt := newS3TestClient(t, "s3test/bucket/")
+
+		var listed []string
+		for content := range clnt.List(context.Background(), ListOptions{Recursive: true, WithMetadata: true, StatMissingMetadata: true}) {
+			if content.Err != nil {
+				t.Fatal(content.Err)
+			}
+			key := strings.TrimPrefix(content.URL.Path, "/bucket/")
+			listed = append(listed, key)
+			if content.Owner != "miniotest" || content.StorageClass != "STANDARD" || content.Size != 1 || content.ETag != etag {
+				t.Fatalf("listing fields of %s were not kept: %+v", key, content)
+			}
+			if content.UserMetadata["X-Amz-Meta-Name"] != key || content.UserMetadata["content-type"] != "application/octet-stream" {
+				t.Fatalf("unexpected metadata of %s: %v", key, content.UserMetadata)
+			}
+		}
+
+		if strings.Join(listed, ",") != strings.Join(keys, ",") {
+			t.Fatalf("objects out of the listing order: %v", listed)
+		}
+		if listMetadata {
+			if heads != 0 {
+				t.Fatalf("expected no HEAD when the listing carries the metadata, got %d", heads)
+			}
+			continue
+		}
+		if heads != len(keys) {
+			t.Fatalf("expected %d HEADs, got %d", len(keys), heads)
+		}
+		if peak > listMetadataConcurrency {
+			t.Fatalf("%d HEADs in flight, expected at most %d", peak, listMetadataConcurrency)
+		}
+	}
+}
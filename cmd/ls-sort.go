@@ -0,0 +1,169 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+
+	"github.com/minio/cli"
+)
+
+// lsSortFlags are the flags sorting the results of ls.
+var lsSortFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "sort",
+		Usage: "sort the listing by 'name', 'size' (largest first) or 'time' (newest first)",
+	},
+	cli.BoolFlag{
+		Name:  "reverse",
+		Usage: "reverse the order of --sort, sorts by name without it",
+	},
+}
+
+// lsSortOptions are the parsed --sort and --reverse, a nil lsSortOptions
+// keeps the listing order.
+type lsSortOptions struct {
+	by      string
+	reverse bool
+	// limit keeps only the first results of the sorted listing.
+	limit int
+}
+
+// newLsSortOptionsFromContext returns the sort options of cliCtx, or nil
+// without --sort and --reverse.
+func newLsSortOptionsFromContext(cliCtx *cli.Context) (*lsSortOptions, error) {
+	by, reverse := cliCtx.String("sort"), cliCtx.Bool("reverse")
+	if by == "" && !reverse {
+		return nil, nil
+	}
+	switch by {
+	case "":
+		by = "name"
+	case "name", "size", "time":
+	default:
+		return nil, errors.New("--sort must be one of 'name', 'size' or 'time'")
+	}
+	return &lsSortOptions{by: by, reverse: reverse}, nil
+}
+
+// lsSortEntry is a listed object with all its versions, seq is its
+// position in the listing and keeps the sort stable.
+type lsSortEntry struct {
+	versions []*ClientContent
+	seq      int
+}
+
+// lsSorter collects the listed objects and returns them sorted. With a
+// limit only that many objects are kept in a heap whose root is the one
+// that sorts last, so that the listing is never buffered as a whole.
+type lsSorter struct {
+	opts    lsSortOptions
+	entries []lsSortEntry
+	seq     int
+}
+
+func newLsSorter(opts *lsSortOptions) *lsSorter {
+	if opts == nil {
+		return nil
+	}
+	return &lsSorter{opts: *opts}
+}
+
+// before returns true if a is printed before b.
+func (s *lsSorter) before(a, b lsSortEntry) bool {
+	x, y := a.versions[0], b.versions[0]
+	var cmp int
+	switch s.opts.by {
+	case "size":
+		cmp = compareInt64(y.Size, x.Size)
+	case "time":
+		switch {
+		case x.Time.After(y.Time):
+			cmp = -1
+		case x.Time.Before(y.Time):
+			cmp = 1
+		}
+	default:
+		switch {
+		case x.URL.Path < y.URL.Path:
+			cmp = -1
+		case x.URL.Path > y.URL.Path:
+			cmp = 1
+		}
+	}
+	if s.opts.reverse {
+		cmp = -cmp
+	}
+	if cmp != 0 {
+		return cmp < 0
+	}
+	return a.seq < b.seq
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// heap.Interface of the kept entries, the root sorts last.
+func (s *lsSorter) Len() int           { return len(s.entries) }
+func (s *lsSorter) Less(i, j int) bool { return s.before(s.entries[j], s.entries[i]) }
+func (s *lsSorter) Swap(i, j int)      { s.entries[i], s.entries[j] = s.entries[j], s.entries[i] }
+func (s *lsSorter) Push(x interface{}) { s.entries = append(s.entries, x.(lsSortEntry)) }
+
+func (s *lsSorter) Pop() interface{} {
+	last := s.entries[len(s.entries)-1]
+	s.entries = s.entries[:len(s.entries)-1]
+	return last
+}
+
+// add accounts for the versions of one listed object.
+func (s *lsSorter) add(versions []*ClientContent) {
+	if len(versions) == 0 {
+		return
+	}
+	sortObjectVersions(versions)
+	entry := lsSortEntry{versions: versions, seq: s.seq}
+	s.seq++
+	switch {
+	case s.opts.limit <= 0:
+		s.entries = append(s.entries, entry)
+	case len(s.entries) < s.opts.limit:
+		heap.Push(s, entry)
+	case s.before(entry, s.entries[0]):
+		s.entries[0] = entry
+		heap.Fix(s, 0)
+	}
+}
+
+// sorted returns the versions of the kept objects in print order.
+func (s *lsSorter) sorted() [][]*ClientContent {
+	sort.Slice(s.entries, func(i, j int) bool { return s.before(s.entries[i], s.entries[j]) })
+	sorted := make([][]*ClientContent, len(s.entries))
+	for i, entry := range s.entries {
+		sorted[i] = entry.versions
+	}
+	return sorted
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func sortedKeys(s *lsSorter) (keys []string) {
+	for _, versions := range s.sorted() {
+		keys = append(keys, versions[0].URL.Path)
+	}
+	return keys
+}
+
+func TestLsSorter(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	objects := []struct {
+		key  string
+		size int64
+		age  time.Duration
+	}{
+		{"a", 10, 3 * time.Hour},
+		{"b", 30, 1 * time.Hour},
+		{"c", 10, 2 * time.Hour},
+		{"d", 20, 1 * time.Hour},
+	}
+	testCases := []struct {
+		by       string
+		reverse  bool
+		limit    int
+		expected []string
+	}{
+		{"name", false, 0, []string{"a", "b", "c", "d"}},
+		{"name", true, 0, []string{"d", "c", "b", "a"}},
+		// Ties keep the listing order, also reversed.
+		{"size", false, 0, []string{"b", "d", "a", "c"}},
+		{"size", true, 0, []string{"a", "c", "d", "b"}},
+		{"time", false, 0, []string{"b", "d", "c", "a"}},
+		{"time", true, 0, []string{"a", "c", "b", "d"}},
+		{"size", false, 2, []string{"b", "d"}},
+		{"size", true, 3, []string{"a", "c", "d"}},
+		{"time", false, 10, []string{"b", "d", "c", "a"}},
+	}
+	for _, testCase := range testCases {
+		s := newLsSorter(&lsSortOptions{by: testCase.by, reverse: testCase.reverse, limit: testCase.limit})
+		for _, object := range objects {
+			s.add([]*ClientContent{{URL: ClientURL{Path: object.key}, Size: object.size, Time: base.Add(-object.age)}})
+		}
+		if keys := sortedKeys(s); !reflect.DeepEqual(keys, testCase.expected) {
+			t.Errorf("--sort %s, reverse %v, limit %d: expected %v, got %v", testCase.by, testCase.reverse, testCase.limit, testCase.expected, keys)
+		}
+	}
+}
+
+func TestLsSorterLimit(t *testing.T) {
+	// The top-N heap keeps the first results of the full sort.
+	rng := rand.New(rand.NewSource(1))
+	full := newLsSorter(&lsSortOptions{by: "size"})
+	top := newLsSorter(&lsSortOptions{by: "size", limit: 50})
+	for i := 0; i < 2000; i++ {
+		content := &ClientContent{URL: ClientURL{Path: fmt.Sprintf("k%04d", i)}, Size: rng.Int63n(100)}
+		full.add([]*ClientContent{content})
+		top.add([]*ClientContent{content})
+	}
+	if len(top.entries) != 50 {
+		t.Fatalf("expected 50 kept entries, got %d", len(top.entries))
+	}
+	if expected, keys := sortedKeys(full)[:50], sortedKeys(top); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+}
+
+func TestListSorted(t *testing.T) {
+	var out, status bytes.Buffer
+	defer func(output, sOutput io.Writer) {
+		color.Output, statusOutput = output, sOutput
+	}(color.Output, statusOutput)
+	color.Output, statusOutput = &out, &status
+	defer func(j bool) { globalJSON = j }(globalJSON)
+	globalJSON = true
+
+	_, clnt := newListObjectsTestClient(t, 25)
+	opts := doListOptions{isRecursive: true, limit: &listLimit{}, sort: &lsSortOptions{by: "name", reverse: true, limit: 3}}
+	if e := doList(context.Background(), clnt, opts); e != nil {
+		t.Fatal(e)
+	}
+	var keys []string
+	for _, match := range regexp.MustCompile(`"key":"([^"]*)"`).FindAllStringSubmatch(out.String(), -1) {
+		keys = append(keys, match[1])
+	}
+	if expected := []string{"k24", "k23", "k22"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	if status.Len() != 0 {
+		t.Fatalf("expected no checkpoint for a sorted listing, got %q", status.String())
+	}
+}
@@ -25,7 +25,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/dustin/go-humanize"
 	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/console"
@@ -54,18 +53,26 @@ type contentMessage struct {
 
 	Metadata map[string]string `json:"metadata,omitempty"`
 	Tags     map[string]string `json:"tags,omitempty"`
+
+	// Only set with --with-metadata.
+	Owner        string            `json:"owner,omitempty"`
+	UserMetadata map[string]string `json:"userMetadata,omitempty"`
 }
 
 // String colorized string message.
 func (c contentMessage) String() string {
-	message := console.Colorize("Time", fmt.Sprintf("[%s]", c.Time.Format(printDate)))
-	message += console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(c.Size))), "")))
+	message := console.Colorize(globalTheme.timeTag(c.Time), fmt.Sprintf("[%s]", formatListTime(c.Time)))
+	message += console.Colorize(globalTheme.sizeTag(c.Size), fmt.Sprintf("%7s", strings.Join(strings.Fields(formatSize(c.Size)), "")))
 	fileDesc := ""
 
 	if c.StorageClass != "" {
 		message += " " + console.Colorize("SC", c.StorageClass)
 	}
 
+	if c.Owner != "" {
+		message += " " + console.Colorize("Owner", c.Owner)
+	}
+
 	if c.VersionID != "" {
 		fileDesc += console.Colorize("VersionID", " "+c.VersionID) + console.Colorize("VersionOrd", fmt.Sprintf(" v%d", c.VersionOrd))
 		if c.IsDeleteMarker {
@@ -82,6 +89,17 @@ func (c contentMessage) String() string {
 	} else {
 		message += console.Colorize("File", fileDesc)
 	}
+
+	if len(c.UserMetadata) > 0 {
+		keys := make([]string, 0, len(c.UserMetadata))
+		for k := range c.UserMetadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			message += " " + console.Colorize("Metadata", k+"="+c.UserMetadata[k])
+		}
+	}
 	return message
 }
 
@@ -111,7 +129,7 @@ func getKey(c *ClientContent) string {
 
 // Generate printable listing from a list of sorted client
 // contents, the latest created content comes first.
-func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAllVersions bool) (msgs []contentMessage) {
+func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAllVersions, withMetadata bool) (msgs []contentMessage) {
 	prefixPath := clntURL.Path
 	prefixPath = filepath.ToSlash(prefixPath)
 	if !strings.HasSuffix(prefixPath, "/") {
@@ -142,6 +160,10 @@ func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAll
 		contentMsg.StorageClass = c.StorageClass
 		contentMsg.Metadata = c.Metadata
 		contentMsg.Tags = c.Tags
+		if withMetadata {
+			contentMsg.Owner = c.Owner
+			contentMsg.UserMetadata = c.UserMetadata
+		}
 
 		md5sum := strings.TrimPrefix(c.ETag, "\"")
 		md5sum = strings.TrimSuffix(md5sum, "\"")
@@ -185,7 +207,7 @@ type summaryMessage struct {
 
 // String colorized string message
 func (s summaryMessage) String() string {
-	msg := console.Colorize("Summarize", fmt.Sprintf("\nTotal Size: %s", humanize.IBytes(uint64(s.TotalSize))))
+	msg := console.Colorize("Summarize", fmt.Sprintf("\nTotal Size: %s", formatSize(s.TotalSize)))
 	msg += "\n" + console.Colorize("Summarize", fmt.Sprintf("Total Objects: %d", s.TotalObjects))
 	return msg
 }
@@ -197,11 +219,18 @@ func (s summaryMessage) JSON() string {
 	return string(jsonMessageBytes)
 }
 
-// Pretty print the list of versions belonging to one object
-func printObjectVersions(clntURL ClientURL, ctntVersions []*ClientContent, printAllVersions bool) {
+// Pretty print the list of versions belonging to one object, or only
+// its name with print0.
+func printObjectVersions(clntURL ClientURL, ctntVersions []*ClientContent, printAllVersions, withMetadata, print0 bool) {
 	sortObjectVersions(ctntVersions)
-	msgs := generateContentMessages(clntURL, ctntVersions, printAllVersions)
+	msgs := generateContentMessages(clntURL, ctntVersions, printAllVersions, withMetadata)
 	for _, msg := range msgs {
+		if print0 {
+			if !msg.IsDeleteMarker {
+				printNul(msg.Key)
+			}
+			continue
+		}
 		printMsg(msg)
 	}
 }
@@ -213,7 +242,11 @@ type doListOptions struct {
 	isSummary         bool
 	withOlderVersions bool
 	listZip           bool
+	withMetadata      bool
+	print0            bool
 	filter            string
+	limit             *listLimit
+	sort              *lsSortOptions
 }
 
 // doList - list all entities inside a folder.
@@ -226,7 +259,21 @@ func doList(ctx context.Context, clnt Client, o doListOptions) error {
 		totalObjects      int64
 	)
 
-	for content := range clnt.List(ctx, ListOptions{
+	// A sorted listing is printed once complete.
+	sorter := newLsSorter(o.sort)
+	printVersions := func(versions []*ClientContent) {
+		if sorter != nil {
+			sorter.add(versions)
+			return
+		}
+		printObjectVersions(clnt.GetURL(), versions, o.withOlderVersions, o.withMetadata, o.print0)
+	}
+
+	// Stopping at --limit cancels the listing requests left.
+	listCtx, cancelList := context.WithCancel(ctx)
+	defer cancelList()
+
+	for content := range clnt.List(listCtx, o.limit.listOptions(ListOptions{
 		Recursive:         o.isRecursive,
 		Incomplete:        o.isIncomplete,
 		TimeRef:           o.timeRef,
@@ -234,7 +281,10 @@ func doList(ctx context.Context, clnt Client, o doListOptions) error {
 		WithDeleteMarkers: true,
 		ShowDir:           DirNone,
 		ListZip:           o.listZip,
-	}) {
+		// Fill the metadata from the listing, HEAD only what it lacks.
+		WithMetadata:        o.withMetadata,
+		StatMissingMetadata: o.withMetadata,
+	})) {
 		if content.Err != nil {
 			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
 			cErr = exitStatus(globalErrorExitStatus) // Set the exit status.
@@ -247,9 +297,13 @@ func doList(ctx context.Context, clnt Client, o doListOptions) error {
 
 		if lastPath != content.URL.Path {
 			// Print any object in the current list before reinitializing it
-			printObjectVersions(clnt.GetURL(), perObjectVersions, o.withOlderVersions)
-			lastPath = content.URL.Path
+			printVersions(perObjectVersions)
 			perObjectVersions = []*ClientContent{}
+			if !o.limit.next(contentKey(content)) {
+				cancelList()
+				break
+			}
+			lastPath = content.URL.Path
 		}
 
 		perObjectVersions = append(perObjectVersions, content)
@@ -257,7 +311,13 @@ func doList(ctx context.Context, clnt Client, o doListOptions) error {
 		totalObjects++
 	}
 
-	printObjectVersions(clnt.GetURL(), perObjectVersions, o.withOlderVersions)
+	printVersions(perObjectVersions)
+	if sorter != nil {
+		for _, versions := range sorter.sorted() {
+			printObjectVersions(clnt.GetURL(), versions, o.withOlderVersions, o.withMetadata, o.print0)
+		}
+	}
+	o.limit.printCheckpoint()
 
 	if o.isSummary {
 		printMsg(summaryMessage{
@@ -66,6 +66,11 @@ COMMANDS:
 GLOBAL FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}{{end}}
+ENVIRONMENT VARIABLES:
+  Every flag can be set with an environment variable named after the command and the flag,
+  e.g. MC_PUT_PART_SIZE for 'put --part-size', shown in the help of the command. A flag on the
+  command line wins over its variable, which wins over the default. Use --debug to print them.
+
 TIP:
   Use '{{.Name}} --autocompletion' to enable shell autocompletion
 
@@ -86,13 +91,10 @@ func init() {
 			os.Exit(1)
 		}
 	}
-	auth := getAuth()
-	aliasToConfigMap[AuthAlias] = &aliasConfigV10{
-		URL:          auth.Endpoint,
-		API:          "S3v4",
-		AccessKey:    auth.AccessKey,
-		SecretKey:    auth.SecretKey,
-		SessionToken: auth.SessionToken,
+	// Register the gpumall alias from the stored token, commands which
+	// need it fail later with a re-auth hint if there is none.
+	if auth, err := getAuthWithErr(); err == nil {
+		registerAuthAlias(auth, "")
 	}
 }
 
@@ -141,8 +143,57 @@ func Main(args []string) error {
 	defer globalHelpPager.WaitForExit()
 
 	parsePagerDisableFlag(args)
+	app := registerApp(appName)
+	args, e := expandCommandPrefix(args, app.Commands, app.Flags)
+	if e != nil {
+		fatalIf(probe.NewError(e), "Unable to find the command.")
+	}
+	if globalFlagEnv, e = checkFlagEnv(app, args); e != nil {
+		fatalIf(probe.NewError(e), "Invalid command usage")
+	}
 	// Run the app
-	return registerApp(appName).Run(args)
+	e = app.Run(args)
+	if e != nil && globalJSON {
+		// main prints the errors of the commands as text.
+		fatal(probe.NewError(e), "Unable to run the command.")
+	}
+	return e
+}
+
+// expandCommandPrefix replaces the command in args, after the program
+// name, by the only visible command it is a prefix of, so that `mc mirr`
+// runs mirror. A prefix of several commands is an error listing them.
+func expandCommandPrefix(args []string, cmds []cli.Command, flags []cli.Flag) ([]string, error) {
+	if len(args) < 2 {
+		return args, nil
+	}
+	positional := positionalArgs(args[1:], flags)
+	if len(positional) == 0 {
+		return args, nil
+	}
+	i := positional[0] + 1
+	if args[i] == "" {
+		return args, nil
+	}
+	var candidates []string
+	for _, cmd := range cmds {
+		if cmd.HasName(args[i]) {
+			return args, nil
+		}
+		if !cmd.Hidden && strings.HasPrefix(cmd.Name, args[i]) {
+			candidates = append(candidates, cmd.Name)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return args, nil
+	case 1:
+		expanded := append([]string{}, args...)
+		expanded[i] = candidates[0]
+		return expanded, nil
+	}
+	sort.Strings(candidates)
+	return nil, fmt.Errorf("ambiguous command `%s`, did you mean one of `%s`?", args[i], strings.Join(candidates, "`, `"))
 }
 
 func flagValue(f cli.Flag) reflect.Value {
@@ -423,6 +474,7 @@ func checkUpdate(ctx *cli.Context) {
 }
 
 var appCmds = []cli.Command{
+	aclCmd,
 	aliasCmd,
 	adminCmd,
 	anonymousCmd,
@@ -434,6 +486,7 @@ var appCmds = []cli.Command{
 	duCmd,
 	encryptCmd,
 	eventCmd,
+	exploreCmd,
 	findCmd,
 	getCmd,
 	headCmd,
@@ -441,6 +494,7 @@ var appCmds = []cli.Command{
 	idpCmd,
 	licenseCmd,
 	legalHoldCmd,
+	lbCmd,
 	lsCmd,
 	mbCmd,
 	mvCmd,
@@ -453,19 +507,25 @@ var appCmds = []cli.Command{
 	quotaCmd,
 	rmCmd,
 	retentionCmd,
+	restoreCmd,
 	rbCmd,
+	rotateKeyCmd,
 	replicateCmd,
 	readyCmd,
 	sqlCmd,
 	statCmd,
 	supportCmd,
+	sessionCmd,
 	shareCmd,
 	treeCmd,
 	tagCmd,
+	trashCmd,
 	undoCmd,
 	updateCmd,
+	verifyCmd,
 	versionCmd,
 	watchCmd,
+	whoamiCmd,
 	authCmd,
 }
 
@@ -510,7 +570,7 @@ func registerApp(name string) *cli.App {
 	app.Before = registerBefore
 	app.HideHelpCommand = true
 	app.Usage = "MinIO Client for object storage and filesystems."
-	app.Commands = appCmds
+	app.Commands = withDeadlineStatus(setFlagEnvVars(appCmds, nil))
 	app.Author = "MinIO, Inc."
 	app.Version = ReleaseTag
 	app.Flags = append(mcFlags, globalFlags...)
@@ -18,33 +18,41 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/console"
 	"io"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 )
 
+// errTokenExpired is returned when the stored token has expired.
+var errTokenExpired = errors.New("Token has expired, please reauthorize")
+
 const (
-	DefaultServerEndpoint = "http://localhost:9000"
-	AuthStoreFileName     = "auth"
-	AuthAlias             = "gpumall"
+	AuthStoreFileName = "auth"
+	AuthAlias         = "gpumall"
 )
 
+// serverEndpointFlag is the gpumall server set by `auth --endpoint`.
+var serverEndpointFlag string
+
 // auth command flags.
 var (
 	authFlags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "region",
-			Usage: "Set region,default region is sh-01",
+			Usage: "Set region, or a comma separated list of regions tried in order, default region is sh-01",
 			Value: "sh-01",
 		},
+		cli.StringSliceFlag{
+			Name:  "fallback-region",
+			Usage: "region to try when the auth to the previous regions fails, may be repeated",
+		},
 		cli.StringFlag{
 			Name:  "user",
 			Usage: "Set auth user",
@@ -53,6 +61,10 @@ var (
 			Name:  "password",
 			Usage: "Your auth password",
 		},
+		cli.StringFlag{
+			Name:  "endpoint",
+			Usage: "gpumall server, overrides GPU_MALL_SERVER and the built-in default",
+		},
 	}
 )
 
@@ -77,14 +89,16 @@ FLAGS:
 EXAMPLES:
   1. auth to gpumall.com
     {{.Prompt}} {{.HelpName}} --region sh-01 --user=foo --password=12456
+  2. auth to gpumall.com, falling back to region sh-02 when sh-01 is down
+    {{.Prompt}} {{.HelpName}} --region sh-01 --fallback-region sh-02 --user=foo --password=12456
 `,
 }
 
 // mainAuth is the entry point for auth command.
 func mainAuth(cliCtx *cli.Context) (e error) {
 
-	region := strings.TrimSpace(cliCtx.String("region"))
-	if region == "" {
+	regions := authRegions(cliCtx.String("region"), cliCtx.StringSlice("fallback-region"))
+	if len(regions) == 0 {
 		return errors.New("Please enter regison  by use '--region'")
 	}
 	user := strings.TrimSpace(cliCtx.String("user"))
@@ -95,9 +109,15 @@ func mainAuth(cliCtx *cli.Context) (e error) {
 	if password == "" {
 		return errors.New("Please enter auth password by use '--password'")
 	}
+	serverEndpointFlag = strings.TrimSpace(cliCtx.String("endpoint"))
 
-	authData, err := auth(region, user, password)
+	authData, err := authWithFailover(regions, user, password)
 	if err != nil {
+		var cpErr controlPlaneError
+		var versionErr authAPIVersionError
+		if errors.Is(err, errProxyAuthFailed) || errors.As(err, &cpErr) || errors.As(err, &versionErr) {
+			return err
+		}
 		if globalDebug {
 			console.Errorln(err)
 		}
@@ -110,6 +130,51 @@ func mainAuth(cliCtx *cli.Context) (e error) {
 	return nil
 }
 
+// authRegions returns the regions of --region and --fallback-region in
+// the order they are tried, without duplicates.
+func authRegions(region string, fallbacks []string) []string {
+	var regions []string
+	seen := map[string]bool{}
+	for _, list := range append([]string{region}, fallbacks...) {
+		for _, r := range strings.Split(list, ",") {
+			if r = strings.TrimSpace(r); r != "" && !seen[r] {
+				seen[r] = true
+				regions = append(regions, r)
+			}
+		}
+	}
+	return regions
+}
+
+// authWithFailover tries to auth to the regions in order until one of
+// them succeeds, and records that region in the auth data. Only a region
+// which cannot be reached, or answers with a server error, fails over to
+// the next one: invalid credentials, a failed proxy auth or an
+// unsupported auth API would fail the same way in every region.
+func authWithFailover(regions []string, user string, password string) (AuthInfoResponse, error) {
+	var authRes AuthInfoResponse
+	var err error
+	for i, region := range regions {
+		authRes, err = auth(region, user, password)
+		if err == nil {
+			authRes.Data.Region = region
+			return authRes, nil
+		}
+		var cpErr controlPlaneError
+		if !errors.As(err, &cpErr) {
+			return authRes, err
+		}
+		if i < len(regions)-1 && (globalDebug || (!globalQuiet && !globalJSON)) {
+			reason := ""
+			if globalDebug {
+				reason = fmt.Sprintf(" (%v)", err)
+			}
+			statusf("[Warn] Auth to region %s failed%s, trying region %s.\n", region, reason, regions[i+1])
+		}
+	}
+	return authRes, err
+}
+
 // get minio access info from gpumall.com
 func auth(region string, user string, password string) (AuthInfoResponse, error) {
 
@@ -122,10 +187,13 @@ func auth(region string, user string, password string) (AuthInfoResponse, error)
 		"password": password,
 		"dcId":     region,
 	}
-	p, _ := json.Marshal(params)
 
-	r, err := http.Post(authUrl, "application/json", bytes.NewBuffer(p))
+	r, err := controlPlanePostJSON(authUrl, params)
 	if err != nil {
+		var cpErr controlPlaneError
+		if errors.Is(err, errProxyAuthFailed) || errors.As(err, &cpErr) {
+			return authRes, err
+		}
 		return authRes, errors.New(fmt.Sprintf("Auth to gpumall.com failed: %s", err.Error()))
 	}
 
@@ -135,15 +203,96 @@ func auth(region string, user string, password string) (AuthInfoResponse, error)
 	if err != nil {
 		return authRes, errors.New(fmt.Sprintf("Read auth server response failed: %s", err.Error()))
 	}
-	err = json.Unmarshal(body, &authRes)
+	authRes, err = parseAuthInfoResponse(body)
 	if err != nil {
 		return authRes, err
 	}
-	if authRes.Code == 0 && authRes.Message == "success" {
-		fmt.Println("Auth successful")
-		return authRes, nil
+	if !globalQuiet && !globalJSON {
+		statusf("Auth successful.\n")
+	}
+	return authRes, nil
+}
+
+const (
+	// minAuthAPIVersion is the oldest auth API this mc understands,
+	// responses without an apiVersion are treated as this version.
+	minAuthAPIVersion = 1
+	// maxAuthAPIVersion is the newest auth API this mc understands.
+	maxAuthAPIVersion = 2
+)
+
+// authAPIVersionError is returned when the server speaks an auth API
+// version outside of [minAuthAPIVersion, maxAuthAPIVersion].
+type authAPIVersionError struct {
+	Version int
+}
+
+func (e authAPIVersionError) Error() string {
+	if e.Version > maxAuthAPIVersion {
+		return fmt.Sprintf("server speaks auth API v%d, this mc supports v%d–v%d, please upgrade", e.Version, minAuthAPIVersion, maxAuthAPIVersion)
+	}
+	return fmt.Sprintf("server speaks auth API v%d, this mc supports v%d–v%d", e.Version, minAuthAPIVersion, maxAuthAPIVersion)
+}
+
+// parse and validate the login response, unknown fields are ignored.
+func parseAuthInfoResponse(body []byte) (AuthInfoResponse, error) {
+	var authRes AuthInfoResponse
+	var raw struct {
+		AuthInfoResponse
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return authRes, errors.New(fmt.Sprintf("Unable to parse auth server response: %v", err))
+	}
+	authRes = raw.AuthInfoResponse
+
+	if authRes.APIVersion == 0 {
+		authRes.APIVersion = minAuthAPIVersion
+	}
+	if authRes.APIVersion < minAuthAPIVersion || authRes.APIVersion > maxAuthAPIVersion {
+		return authRes, authAPIVersionError{Version: authRes.APIVersion}
+	}
+	if authRes.Code != 0 || authRes.Message != "success" {
+		return authRes, errors.New(fmt.Sprintf("Auth  failed: %s", authRes.Message))
+	}
+
+	if len(raw.Data) == 0 || string(raw.Data) == "null" {
+		return authRes, errors.New("Auth server response has no data")
+	}
+	if err := json.Unmarshal(raw.Data, &authRes.Data); err != nil {
+		return authRes, errors.New(fmt.Sprintf("Unable to parse auth server response data: %v", err))
+	}
+	if err := authRes.Data.validate(); err != nil {
+		return authRes, err
+	}
+	return authRes, nil
+}
+
+// validate checks that every field needed to reach the storage is set.
+func (a AuthData) validate() error {
+	required := []struct {
+		name, value string
+	}{
+		{"endpoint", a.Endpoint},
+		{"bucket", a.Bucket},
+		{"accessKey", a.AccessKey},
+		{"secretKey", a.SecretKey},
+		{"expireAt", a.ExpireAt},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			return errors.New(fmt.Sprintf("Auth server response is missing required field `%s`", field.name))
+		}
 	}
-	return authRes, errors.New(fmt.Sprintf("Auth  failed: %s", authRes.Message))
+	if _, err := a.expiry(); err != nil {
+		return errors.New(fmt.Sprintf("Auth server response has an invalid expireAt `%s`", a.ExpireAt))
+	}
+	return nil
+}
+
+// expiry returns the time at which the session token expires.
+func (a AuthData) expiry() (time.Time, error) {
+	return time.Parse("2006-01-02 15:04:05", a.ExpireAt)
 }
 
 // store auth data
@@ -171,6 +320,9 @@ func storeAuthData(sId string, v interface{}) error {
 // get auth data
 func getAuthWithErr() (AuthData, error) {
 
+	if globalCredentials != nil {
+		return *globalCredentials, nil
+	}
 	var authData AuthData
 	df, pErr := getSessionDataFile(AuthStoreFileName)
 	if pErr != nil {
@@ -190,35 +342,37 @@ func getAuthWithErr() (AuthData, error) {
 		return authData, err
 	}
 
-	expireAt, err := time.Parse("2006-01-02 15:04:05", authData.ExpireAt)
+	expireAt, err := authData.expiry()
 	if err != nil {
 		return authData, errors.New(fmt.Sprintf("Get session data expiredAt failed:%v", err))
 	}
 
 	if time.Now().After(expireAt) {
-		return authData, errors.New(fmt.Sprintf("Token has expired, please reauthorize"))
+		return authData, errTokenExpired
 	}
 
 	return authData, nil
 }
 
+// getAuth returns the stored auth data, commands which need it call it
+// before building any client so that a missing or expired token fails
+// right away, without any network call.
 func getAuth() AuthData {
 
 	auth, err := getAuthWithErr()
-	if err != nil {
-		if globalDebug {
-			fmt.Println(err)
-		}
-		console.Fatalln("Auth failed, please reauthorize")
+	if errors.Is(err, errTokenExpired) {
+		fatalIf(probe.NewError(err), "Token has expired, please reauthorize with `mc auth`.")
 	}
+	fatalIf(probe.NewError(err), "Auth failed, please reauthorize with `mc auth`.")
 	return auth
 }
 
 type AuthInfoResponse struct {
-	Code    int      `json:"code"`
-	Message string   `json:"message"`
-	TraceId string   `json:"traceid"`
-	Data    AuthData `json:"data"`
+	APIVersion int      `json:"apiVersion"`
+	Code       int      `json:"code"`
+	Message    string   `json:"message"`
+	TraceId    string   `json:"traceid"`
+	Data       AuthData `json:"data"`
 }
 
 type AuthData struct {
@@ -229,11 +383,17 @@ type AuthData struct {
 	SecretKey    string `json:"secretKey" dc:"secretKey"`
 	SessionToken string `json:"sessionToken" dc:"sessionToken"`
 	ExpireAt     string `json:"expireAt" dc:"expireAt"`
+	// Region is the region the auth succeeded in, set by mc.
+	Region string `json:"region,omitempty" dc:"region"`
 }
 
-// get gpumall.com server address
+// get gpumall.com server address, --endpoint takes precedence over
+// GPU_MALL_SERVER which takes precedence over the build-time default.
 func serverEndpoint() string {
 
+	if serverEndpointFlag != "" {
+		return serverEndpointFlag
+	}
 	gpuMallServer := os.Getenv("GPU_MALL_SERVER")
 	if gpuMallServer != "" {
 		return gpuMallServer
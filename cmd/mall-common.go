@@ -1,6 +1,10 @@
 package cmd
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/minio/pkg/v2/env"
+)
 
 func getPrefix() string {
 
@@ -17,8 +21,44 @@ func getUrlWithSeparator(v string) string {
 	return v
 }
 
+// pathAlias returns the alias addressed by path, a leading "NAME/" where
+// NAME is an alias set with `mc alias set` or MC_HOST_NAME. gpumall is
+// never addressed this way, its paths are relative to the gpumall prefix,
+// and neither are the aliases every config starts with, so that a path
+// such as "play/x" is not sent to play.min.io unless play was set.
+func pathAlias(path string) string {
+
+	alias, _, found := strings.Cut(path, "/")
+	if !found || alias == "" || alias == AuthAlias || !isValidAlias(alias) {
+		return ""
+	}
+	if env.IsSet(mcEnvHostPrefix+alias) || aliasToConfigMap[alias] != nil {
+		return alias
+	}
+	if aliasCfg, err := getAliasConfig(alias); err == nil && !isDefaultAlias(alias, aliasCfg) {
+		return alias
+	}
+	return ""
+}
+
+// isDefaultAlias returns true if alias is one of the aliases added by
+// loadDefaults and still has the endpoint and keys it was added with.
+func isDefaultAlias(alias string, aliasCfg *aliasConfigV10) bool {
+	defaults := newConfigV10()
+	defaults.loadDefaults()
+	defaultCfg, ok := defaults.Aliases[alias]
+	return ok && defaultCfg.URL == aliasCfg.URL &&
+		defaultCfg.AccessKey == aliasCfg.AccessKey && defaultCfg.SecretKey == aliasCfg.SecretKey
+}
+
+// getFullPath returns the aliased URL of a command line path, paths of
+// another alias are returned as is while any other path, and always one
+// with a leading "/", is relative to the gpumall prefix.
 func getFullPath(path string) string {
 
+	if pathAlias(path) != "" {
+		return path
+	}
 	if path == "." {
 		path = "/"
 	}
@@ -0,0 +1,222 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// globalCredentials holds the credentials of --credentials-file, they
+// replace the ones stored by `mc auth` when set.
+var globalCredentials *AuthData
+
+// CredentialsFile is the content of --credentials-file: the storage
+// credentials `mc auth` obtains from gpumall, obtained by other means.
+// expireAt is optional for credentials which do not expire.
+type CredentialsFile struct {
+	AuthData
+	Region        string `json:"region"`
+	SigningRegion string `json:"signingRegion"`
+}
+
+// validate checks that every field needed to reach the storage is set
+// and that the credentials have not expired.
+func (c CredentialsFile) validate() error {
+	required := []struct {
+		name, value string
+	}{
+		{"endpoint", c.Endpoint},
+		{"bucket", c.Bucket},
+		{"accessKey", c.AccessKey},
+		{"secretKey", c.SecretKey},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("missing required field `%s`", field.name)
+		}
+	}
+	if c.ExpireAt == "" {
+		return nil
+	}
+	expireAt, e := c.expiry()
+	if e != nil {
+		return fmt.Errorf("invalid expireAt `%s`, expected the format `2006-01-02 15:04:05`", c.ExpireAt)
+	}
+	if time.Now().After(expireAt) {
+		return fmt.Errorf("credentials expired at %s", c.ExpireAt)
+	}
+	return nil
+}
+
+// loadCredentialsFile reads and validates a --credentials-file.
+func loadCredentialsFile(path string) (CredentialsFile, error) {
+	var creds CredentialsFile
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return creds, fmt.Errorf("Unable to read credentials file `%s`: %v", path, e)
+	}
+	if e = json.Unmarshal(data, &creds); e != nil {
+		return creds, fmt.Errorf("Unable to parse credentials file `%s`: %v", path, e)
+	}
+	if e = creds.validate(); e != nil {
+		return creds, fmt.Errorf("Invalid credentials file `%s`: %v", path, e)
+	}
+	return creds, nil
+}
+
+// useCredentialsFile registers the gpumall alias from a --credentials-file,
+// bypassing the gpumall login.
+func useCredentialsFile(path string) error {
+	creds, e := loadCredentialsFile(path)
+	if e != nil {
+		return e
+	}
+	globalCredentials = &creds.AuthData
+	registerAuthAlias(creds.AuthData, creds.Region)
+	aliasToConfigMap[AuthAlias].SigningRegion = creds.SigningRegion
+	return nil
+}
+
+// credentialHelperExpiryWindow is how long before they expire the
+// credentials of a credential helper are refreshed.
+const credentialHelperExpiryWindow = time.Minute
+
+// globalCredentialHelper provides the credentials of the gpumall alias
+// with --credential-helper.
+var globalCredentialHelper *credentialHelper
+
+// credentialHelper is a credentials provider running the command of
+// --credential-helper, which prints the content of a credentials file on
+// its standard output, and running it again when the credentials expire.
+// The credentials are never written to disk.
+type credentialHelper struct {
+	command string
+
+	mu sync.Mutex
+	credentials.Expiry
+	creds CredentialsFile
+}
+
+// newCredentialHelper returns the credential helper running command,
+// with the credentials of its first run.
+func newCredentialHelper(command string) (*credentialHelper, error) {
+	h := &credentialHelper{command: command}
+	if e := h.refresh(); e != nil {
+		return nil, e
+	}
+	return h, nil
+}
+
+// refresh runs the command and keeps the credentials it prints.
+func (h *credentialHelper) refresh() error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", h.command)
+	} else {
+		cmd = exec.Command("sh", "-c", h.command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, e := cmd.Output()
+	if e != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			e = fmt.Errorf("%v: %s", e, msg)
+		}
+		return fmt.Errorf("Credential helper `%s` failed: %v", h.command, e)
+	}
+	var creds CredentialsFile
+	if e = json.Unmarshal(out, &creds); e != nil {
+		return fmt.Errorf("Unable to parse the output of credential helper `%s`: %v", h.command, e)
+	}
+	if e = creds.validate(); e != nil {
+		return fmt.Errorf("Invalid credentials from credential helper `%s`: %v", h.command, e)
+	}
+
+	h.creds = creds
+	if creds.ExpireAt == "" {
+		h.SetExpiration(time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+	} else {
+		expiry, _ := creds.expiry()
+		h.SetExpiration(expiry, credentialHelperExpiryWindow)
+	}
+	return nil
+}
+
+// Retrieve returns the credentials, from a new run of the command when
+// they expired.
+func (h *credentialHelper) Retrieve() (credentials.Value, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.Expiry.IsExpired() {
+		if e := h.refresh(); e != nil {
+			return credentials.Value{}, e
+		}
+	}
+	return credentials.Value{
+		AccessKeyID:     h.creds.AccessKey,
+		SecretAccessKey: h.creds.SecretKey,
+		SessionToken:    h.creds.SessionToken,
+		SignerType:      credentials.SignatureV4,
+	}, nil
+}
+
+// IsExpired returns true when the credentials must be retrieved again.
+func (h *credentialHelper) IsExpired() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.Expiry.IsExpired()
+}
+
+// useCredentialHelper registers the gpumall alias with the credentials
+// of a --credential-helper, bypassing the gpumall login.
+func useCredentialHelper(command string) error {
+	h, e := newCredentialHelper(command)
+	if e != nil {
+		return e
+	}
+	globalCredentialHelper = h
+	auth := h.creds.AuthData
+	globalCredentials = &auth
+	registerAuthAlias(h.creds.AuthData, h.creds.Region)
+	aliasToConfigMap[AuthAlias].SigningRegion = h.creds.SigningRegion
+	return nil
+}
+
+// registerAuthAlias registers the gpumall alias for auth.
+func registerAuthAlias(auth AuthData, region string) {
+	expiry, _ := auth.expiry()
+	aliasToConfigMap[AuthAlias] = &aliasConfigV10{
+		URL:           auth.Endpoint,
+		API:           "S3v4",
+		AccessKey:     auth.AccessKey,
+		SecretKey:     auth.SecretKey,
+		SessionToken:  auth.SessionToken,
+		SessionExpiry: expiry,
+		Region:        region,
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"math"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// --max-object-size guards against uploading a runaway file, such as a
+// device file or a huge sparse file, by failing the uploads of objects
+// larger than a limit before they start, from the size found when the
+// source was scanned.
+
+var maxObjectSizeFlag = cli.StringFlag{
+	Name:  "max-object-size",
+	Usage: "fail the upload of an object larger than this size, such as 50GiB, before it starts",
+}
+
+// parseMaxObjectSize returns the limit of --max-object-size in bytes, or
+// 0 when there is no limit.
+func parseMaxObjectSize(cliCtx *cli.Context) int64 {
+	s := cliCtx.String("max-object-size")
+	if s == "" {
+		return 0
+	}
+	n, e := humanize.ParseBytes(s)
+	fatalIf(probe.NewError(e).Trace(s), "Unable to parse --max-object-size `"+s+"`.")
+	if n == 0 || n >= math.MaxInt64 {
+		fatalIf(errInvalidArgument().Trace(s), "--max-object-size must be a positive size.")
+	}
+	return int64(n)
+}
+
+// checkMaxObjectSize returns an error if an object of size bytes is over
+// limit. Objects of unknown size, a negative size, are not checked.
+func checkMaxObjectSize(source string, size, limit int64) *probe.Error {
+	if limit > 0 && size > limit {
+		return errObjectTooLarge(source, size, limit)
+	}
+	return nil
+}
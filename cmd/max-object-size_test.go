@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestMaxObjectSize(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.MakeBucket("bucket")
+	t.Setenv(mcEnvHostPrefix+"maxsizetest", server.AliasURL())
+
+	dir := t.TempDir()
+	writeTestTree(t, dir, map[string]string{"small.bin": "hello", "large.bin": "hello world"})
+	for i, testCase := range []struct {
+		name     string
+		size     int64
+		tooLarge bool
+	}{
+		{"small.bin", 5, false},
+		{"large.bin", 11, true},
+	} {
+		alias, targetURL, _ := mustExpandAlias("maxsizetest/bucket/" + testCase.name)
+		urls := URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(filepath.Join(dir, testCase.name)), Size: testCase.size},
+			TargetAlias:   alias,
+			TargetContent: &ClientContent{URL: *newClientURL(targetURL)},
+		}
+		puts := server.RequestCount(http.MethodPut)
+		urls = uploadSourceToTargetURL(context.Background(), uploadSourceToTargetURLOpts{urls: urls, progress: newAccounter(0), maxObjectSize: 8})
+		_, uploaded := server.Object("bucket", testCase.name)
+		if !testCase.tooLarge {
+			if urls.Error != nil || !uploaded {
+				t.Fatalf("Test %d: expected %s to be uploaded, got %v", i+1, testCase.name, urls.Error)
+			}
+			continue
+		}
+		if urls.Error == nil {
+			t.Fatalf("Test %d: expected %s to be refused", i+1, testCase.name)
+		}
+		if !strings.Contains(urls.Error.ToGoError().Error(), "larger than the --max-object-size of 8 B") {
+			t.Fatalf("Test %d: expected a size error, got %v", i+1, urls.Error)
+		}
+		if uploaded || server.RequestCount(http.MethodPut) != puts {
+			t.Fatalf("Test %d: expected no upload of %s to start", i+1, testCase.name)
+		}
+	}
+}
+
+func TestMaxObjectSizeStdin(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.MakeBucket("bucket")
+	t.Setenv(mcEnvHostPrefix+"maxsizetest", server.AliasURL())
+	defer func(tempDir string) { globalTempDir = tempDir }(globalTempDir)
+	globalTempDir = t.TempDir()
+
+	defer func(stdin *os.File) { os.Stdin = stdin }(os.Stdin)
+	setStdin := func(data string) {
+		path := filepath.Join(t.TempDir(), "stdin")
+		if e := os.WriteFile(path, []byte(data), 0o600); e != nil {
+			t.Fatal(e)
+		}
+		f, e := os.Open(path)
+		if e != nil {
+			t.Fatal(e)
+		}
+		t.Cleanup(func() { f.Close() })
+		os.Stdin = f
+	}
+
+	// Streams of unknown size are buffered to be checked before the upload.
+	setStdin("hello")
+	if err := putStdin(context.Background(), "maxsizetest/bucket/small", -1, 8, newAccounter(0), PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := server.Object("bucket", "small"); !ok {
+		t.Fatal("expected the stream under the limit to be uploaded")
+	}
+	for _, size := range []int64{-1, 11} {
+		setStdin("hello world")
+		err := putStdin(context.Background(), "maxsizetest/bucket/large", size, 8, newAccounter(0), PutOptions{})
+		if err == nil {
+			t.Fatalf("expected the stream over the limit with size %d to be refused", size)
+		}
+		if !strings.Contains(err.ToGoError().Error(), "larger than the --max-object-size of 8 B") {
+			t.Fatalf("expected a size error, got %v", err)
+		}
+	}
+	if _, ok := server.Object("bucket", "large"); ok || server.RequestCount(http.MethodPut) != 1 {
+		t.Fatal("expected no upload of the stream over the limit to start")
+	}
+	if entries, _ := os.ReadDir(globalTempDir); len(entries) != 0 {
+		t.Fatalf("expected the buffered stream to be removed, found %d files", len(entries))
+	}
+}
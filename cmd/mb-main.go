@@ -140,6 +140,7 @@ func mainMakeBucket(cliCtx *cli.Context) error {
 			cErr = exitStatus(globalErrorExitStatus)
 			continue
 		}
+		defer clnt.Close()
 
 		ctx, cancelMakeBucket := context.WithCancel(globalContext)
 		defer cancelMakeBucket()
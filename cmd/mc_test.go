@@ -110,3 +110,20 @@ func (s *TestSuite) TestHumanizedTime(c *checkv1.C) {
 	hTime = timeDurationToHumanizedDuration(time.Duration(24) * time.Hour)
 	c.Assert(hTime.Days, checkv1.Not(checkv1.Equals), int64(0))
 }
+
+// initTestConfig points mc to a fresh configuration folder for the test.
+func initTestConfig(t testing.TB) {
+	t.Helper()
+	savedConfigDir := mcCustomConfigDir
+	savedLoadMcConfig := loadMcConfig
+	t.Cleanup(func() {
+		mcCustomConfigDir = savedConfigDir
+		loadMcConfig = savedLoadMcConfig
+	})
+
+	setMcConfigDir(t.TempDir())
+	if err := saveMcConfig(newMcConfig()); err != nil {
+		t.Fatal(err)
+	}
+	loadMcConfig = loadMcConfigFactory()
+}
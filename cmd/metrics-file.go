@@ -0,0 +1,202 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// metricsFileFlags write the counters of a transfer for monitoring.
+var metricsFileFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "metrics-file",
+		Usage: "write the counters of the transfer to this file in Prometheus text format once done, for the textfile collector of the node exporter",
+	},
+	cli.DurationFlag{
+		Name:  "metrics-interval",
+		Usage: "with --metrics-file, also rewrite the file at this interval during the transfer",
+	},
+}
+
+// Objects are counted under one of these statuses.
+const (
+	metricsStatusOK      = "ok"
+	metricsStatusFailed  = "failed"
+	metricsStatusSkipped = "skipped"
+)
+
+// metricsFile replaces a file with the counters of a transfer in the
+// Prometheus exposition format, once closed and every interval if
+// set. A nil metricsFile does nothing.
+type metricsFile struct {
+	path  string
+	start time.Time
+
+	ok, failed, skipped int64
+	transferred         int64
+
+	// mu serializes the writes, lastBytes and lastTime are those of the
+	// previous write to compute the current rate.
+	mu        sync.Mutex
+	lastBytes int64
+	lastTime  time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newMetricsFileFromContext returns the metrics file set by
+// --metrics-file, or nil when it is not set.
+func newMetricsFileFromContext(cliCtx *cli.Context) *metricsFile {
+	path := cliCtx.String("metrics-file")
+	interval := cliCtx.Duration("metrics-interval")
+	if path == "" {
+		if cliCtx.IsSet("metrics-interval") {
+			fatalIf(errInvalidArgument(), "--metrics-interval can only be used with --metrics-file.")
+		}
+		return nil
+	}
+	if interval < 0 {
+		fatalIf(errInvalidArgument().Trace(interval.String()), "--metrics-interval cannot be negative.")
+	}
+	m, err := newMetricsFile(path, interval)
+	fatalIf(err, "Unable to write the metrics file.")
+	return m
+}
+
+// newMetricsFile writes the counters to path right away, so that an
+// unwritable path fails before the transfer, then every interval
+// until closed if interval is not zero.
+func newMetricsFile(path string, interval time.Duration) (*metricsFile, *probe.Error) {
+	now := time.Now()
+	m := &metricsFile{
+		path:     path,
+		start:    now,
+		lastTime: now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := m.flush(); err != nil {
+		return nil, err.Trace(path)
+	}
+	if interval == 0 {
+		close(m.done)
+		return m, nil
+	}
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				errorIf(m.flush().Trace(path), "Unable to write the metrics file.")
+			}
+		}
+	}()
+	return m, nil
+}
+
+// objectTransferred accounts for an object of size bytes transferred.
+func (m *metricsFile) objectTransferred(size int64) {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.ok, 1)
+	atomic.AddInt64(&m.transferred, size)
+}
+
+// objectFailed accounts for an object which could not be transferred.
+func (m *metricsFile) objectFailed() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.failed, 1)
+}
+
+// objectSkipped accounts for an object left as it was.
+func (m *metricsFile) objectSkipped() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.skipped, 1)
+}
+
+// flush replaces the file with the current counters.
+func (m *metricsFile) flush() *probe.Error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	transferred := atomic.LoadInt64(&m.transferred)
+	var rate float64
+	if elapsed := now.Sub(m.lastTime).Seconds(); elapsed > 0 {
+		rate = float64(transferred-m.lastBytes) / elapsed
+	}
+	m.lastBytes, m.lastTime = transferred, now
+
+	var b bytes.Buffer
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	value := func(name string, v float64) {
+		fmt.Fprintf(&b, "%s %s\n", name, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	metric("mc_gpumall_objects_total", "counter", "Objects processed by the transfer, by status.")
+	for _, s := range []struct {
+		status string
+		count  *int64
+	}{
+		{metricsStatusOK, &m.ok},
+		{metricsStatusFailed, &m.failed},
+		{metricsStatusSkipped, &m.skipped},
+	} {
+		value(`mc_gpumall_objects_total{status="`+s.status+`"}`, float64(atomic.LoadInt64(s.count)))
+	}
+	metric("mc_gpumall_bytes_transferred_total", "counter", "Bytes of the objects transferred.")
+	value("mc_gpumall_bytes_transferred_total", float64(transferred))
+	metric("mc_gpumall_transfer_duration_seconds", "gauge", "Seconds since the transfer started.")
+	value("mc_gpumall_transfer_duration_seconds", now.Sub(m.start).Seconds())
+	metric("mc_gpumall_current_rate_bytes", "gauge", "Bytes per second transferred since the previous write of the file.")
+	value("mc_gpumall_current_rate_bytes", rate)
+
+	return writeFileAtomicMode(m.path, b.Bytes(), 0o644)
+}
+
+// close stops the periodic writes and writes the final counters.
+func (m *metricsFile) close() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+		errorIf(m.flush().Trace(m.path), "Unable to write the metrics file.")
+	})
+}
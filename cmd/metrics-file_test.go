@@ -0,0 +1,127 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readMetricsFile returns the samples of a metrics file by name and
+// labels, checking that every sample follows its HELP and TYPE lines.
+func readMetricsFile(t *testing.T, path string) map[string]float64 {
+	t.Helper()
+	data, e := os.ReadFile(path)
+	if e != nil {
+		t.Fatal(e)
+	}
+	samples := make(map[string]float64)
+	typed := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			typed[strings.Fields(line)[2]] = true
+			continue
+		}
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("invalid metrics line %q", line)
+		}
+		if family, _, _ := strings.Cut(name, "{"); !typed[family] {
+			t.Fatalf("sample %q has no TYPE", line)
+		}
+		v, e := strconv.ParseFloat(value, 64)
+		if e != nil {
+			t.Fatalf("invalid metrics line %q: %v", line, e)
+		}
+		samples[name] = v
+	}
+	return samples
+}
+
+func TestMetricsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.prom")
+	metrics, err := newMetricsFile(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if samples := readMetricsFile(t, path); len(samples) != 6 || samples[`mc_gpumall_objects_total{status="ok"}`] != 0 {
+		t.Fatalf("unexpected initial metrics %v", samples)
+	}
+
+	metrics.objectTransferred(1 << 10)
+	metrics.objectTransferred(1 << 10)
+	time.Sleep(50 * time.Millisecond)
+	if samples := readMetricsFile(t, path); samples["mc_gpumall_bytes_transferred_total"] != 2<<10 {
+		t.Fatalf("expected the metrics to be written during the transfer, got %v", samples)
+	}
+
+	metrics.objectFailed()
+	metrics.objectSkipped()
+	metrics.objectSkipped()
+	metrics.close()
+	samples := readMetricsFile(t, path)
+	for name, value := range map[string]float64{
+		`mc_gpumall_objects_total{status="ok"}`:      2,
+		`mc_gpumall_objects_total{status="failed"}`:  1,
+		`mc_gpumall_objects_total{status="skipped"}`: 2,
+		"mc_gpumall_bytes_transferred_total":         2 << 10,
+	} {
+		if samples[name] != value {
+			t.Errorf("expected %s to be %v, got %v", name, value, samples[name])
+		}
+	}
+	if samples["mc_gpumall_transfer_duration_seconds"] < 0.05 || samples["mc_gpumall_current_rate_bytes"] < 0 {
+		t.Errorf("unexpected final metrics %v", samples)
+	}
+	if fi, e := os.Stat(path); e != nil || runtime.GOOS != "windows" && fi.Mode().Perm() != 0o644 {
+		t.Errorf("expected the metrics file to be readable by the node exporter, got %v", fi.Mode())
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*.tmp")); len(matches) != 0 {
+		t.Errorf("expected no temporary file left, got %v", matches)
+	}
+
+	// Without an interval the file is only written at the start and once done.
+	metrics, err = newMetricsFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics.objectTransferred(1)
+	time.Sleep(20 * time.Millisecond)
+	if samples = readMetricsFile(t, path); samples[`mc_gpumall_objects_total{status="ok"}`] != 0 {
+		t.Fatalf("expected no write before the end, got %v", samples)
+	}
+	metrics.close()
+	if samples = readMetricsFile(t, path); samples[`mc_gpumall_objects_total{status="ok"}`] != 1 {
+		t.Fatalf("unexpected final metrics %v", samples)
+	}
+
+	if _, err = newMetricsFile(filepath.Join(path, "missing", "file"), time.Hour); err == nil {
+		t.Error("expected an unwritable path to fail")
+	}
+	var none *metricsFile
+	none.objectTransferred(1)
+	none.close()
+}
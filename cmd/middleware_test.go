@@ -0,0 +1,119 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestConfigMiddleware(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		calls    []string
+	)
+	server, front := newS3TestFrontServer(t, func(server *miniotest.Server) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			first := requests == 1
+			mu.Unlock()
+			if r.Header.Get("X-Gateway-Auth") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if first {
+				// Retried by the client.
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			server.ServeHTTP(w, r)
+		})
+	})
+	server.PutObject("bucket", "object", []byte("abc"))
+
+	middleware := func(name string) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+				if name == "inner" {
+					// Added after signing, as gateways expect.
+					req = req.Clone(req.Context())
+					req.Header.Set("X-Gateway-Auth", "token")
+				}
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	newTestClient := func(middleware ...func(http.RoundTripper) http.RoundTripper) Client {
+		conf := newMiniotestConfig(front.URL + "/bucket/object")
+		conf.Region = "us-east-1"
+		conf.MaxRetryTime = time.Minute
+		conf.Middleware = middleware
+		clnt, err := S3New(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return clnt
+	}
+
+	clnt := newTestClient(middleware("inner"), middleware("outer"))
+	content, err := clnt.Stat(context.Background(), StatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content.Size != 3 {
+		t.Fatalf("expected a size of 3, got %d", content.Size)
+	}
+	// Every attempt passes through the chain once, in order.
+	mu.Lock()
+	expected := []string{}
+	for i := 0; i < requests; i++ {
+		expected = append(expected, "outer", "inner")
+	}
+	if requests != 2 || !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected %v for %d requests, got %v", expected, requests, calls)
+	}
+	calls = nil
+	mu.Unlock()
+
+	// A client of the same keys without middleware is not the cached one.
+	if _, err = newTestClient().Stat(context.Background(), StatOptions{}); err == nil {
+		t.Fatal("expected the request without middleware to be rejected")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 0 {
+		t.Fatalf("expected no middleware calls, got %v", calls)
+	}
+}
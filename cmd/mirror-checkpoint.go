@@ -0,0 +1,383 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// A mirror checkpoint is a snapshot of the target of a mirror, written
+// after each successful run, with the size and modification time of the
+// source each object was compared to. The next run to the same target
+// compares the source to the snapshot instead of listing the target, and
+// only checks on the target the objects whose source changed. The target
+// is listed again once the last full listing is older than the full scan
+// interval.
+//
+// The transfers of a run are appended to a journal next to the snapshot,
+// so that an interrupted run is resumed by the next one instead of
+// uploading again what it did.
+
+const (
+	mirrorCheckpointVersion       = "1"
+	mirrorCheckpointJournalSuffix = ".journal"
+)
+
+// mirrorCheckpointEntry is an object of the target, or a change to it in
+// the journal.
+type mirrorCheckpointEntry struct {
+	Key           string    `json:"key"`
+	Size          int64     `json:"size,omitempty"`
+	ETag          string    `json:"etag,omitempty"`
+	ModTime       time.Time `json:"mtime,omitempty"`
+	SourceSize    int64     `json:"sourceSize,omitempty"`
+	SourceModTime time.Time `json:"sourceMtime,omitempty"`
+	Removed       bool      `json:"removed,omitempty"`
+}
+
+// mirrorCheckpointFile is the content of a checkpoint file.
+type mirrorCheckpointFile struct {
+	Version  string                  `json:"version"`
+	Source   string                  `json:"source"`
+	Target   string                  `json:"target"`
+	FullScan time.Time               `json:"fullScan"`
+	Entries  []mirrorCheckpointEntry `json:"entries"`
+}
+
+type mirrorCheckpoint struct {
+	path     string
+	source   string
+	target   string
+	fullScan time.Time
+	// usable is true when the target does not need to be listed.
+	usable bool
+	// known holds the objects of the checkpoint and its journal.
+	known map[string]mirrorCheckpointEntry
+
+	mu      sync.Mutex
+	journal *os.File
+	// targetURLs are the URLs of the target as listed and as given to
+	// the mirror, which differ for a relative local folder.
+	targetURLs []string
+	// sources and targets hold what this run compared, and updates
+	// what it changed on the target.
+	sources map[string]*ClientContent
+	targets map[string]mirrorCheckpointEntry
+	updates []mirrorCheckpointEntry
+}
+
+// loadMirrorCheckpoint loads the checkpoint at path of the mirror of
+// source to target. It can be used instead of listing the target when it
+// is of the same mirror, with a full listing more recent than
+// fullScanInterval.
+func loadMirrorCheckpoint(path, source, target string, fullScanInterval time.Duration) (*mirrorCheckpoint, *probe.Error) {
+	c := &mirrorCheckpoint{
+		path:    path,
+		source:  source,
+		target:  target,
+		known:   map[string]mirrorCheckpointEntry{},
+		sources: map[string]*ClientContent{},
+		targets: map[string]mirrorCheckpointEntry{},
+	}
+	data, e := os.ReadFile(path)
+	if e != nil && !os.IsNotExist(e) {
+		return nil, probe.NewError(e)
+	}
+	if e == nil {
+		var file mirrorCheckpointFile
+		if e = json.Unmarshal(data, &file); e != nil {
+			return nil, probe.NewError(e).Trace(path)
+		}
+		c.usable = file.Version == mirrorCheckpointVersion && file.Source == source && file.Target == target &&
+			UTCNow().Sub(file.FullScan) < fullScanInterval
+		if c.usable {
+			c.fullScan = file.FullScan
+			for _, entry := range file.Entries {
+				c.known[entry.Key] = entry
+			}
+		}
+	}
+
+	journalPath := path + mirrorCheckpointJournalSuffix
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if c.usable {
+		lines, err := readJournalLines(journalPath)
+		if err != nil {
+			return nil, err.Trace(journalPath)
+		}
+		for _, line := range lines {
+			var entry mirrorCheckpointEntry
+			if e = json.Unmarshal(line, &entry); e != nil {
+				return nil, probe.NewError(e).Trace(journalPath)
+			}
+			if entry.Removed {
+				delete(c.known, entry.Key)
+				continue
+			}
+			c.known[entry.Key] = entry
+		}
+		if err = truncateTornLine(journalPath); err != nil {
+			return nil, err.Trace(journalPath)
+		}
+	} else {
+		// The target is listed again, the journal is out of date.
+		flags |= os.O_TRUNC
+	}
+	if c.journal, e = os.OpenFile(journalPath, flags, 0o600); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return c, nil
+}
+
+// content returns the target object of entry.
+func (entry mirrorCheckpointEntry) content(targetURL string) *ClientContent {
+	return &ClientContent{
+		URL:  *newClientURL(urlJoinPath(targetURL, entry.Key)),
+		Size: entry.Size,
+		ETag: entry.ETag,
+		Time: entry.ModTime,
+		Type: os.FileMode(0o664),
+	}
+}
+
+// checkpointKey returns the key of content under the first of baseURLs
+// it is in.
+func checkpointKey(content *ClientContent, baseURLs ...string) string {
+	u := content.URL.String()
+	for _, baseURL := range baseURLs {
+		if strings.HasPrefix(u, baseURL) {
+			u = strings.TrimPrefix(u, baseURL)
+			break
+		}
+	}
+	return filepath.ToSlash(u)
+}
+
+// difference compares the source to the target, as objectDifference
+// does, without listing the target when the checkpoint is usable.
+// mirrorURL is the URL of the target the transfers are made to.
+func (c *mirrorCheckpoint) difference(ctx context.Context, sourceClnt, targetClnt Client, targetAlias, mirrorURL string, opts mirrorOptions) chan diffMessage {
+	sourceURL := sourceClnt.GetURL().String()
+	targetURL := targetClnt.GetURL().String()
+	c.mu.Lock()
+	c.targetURLs = []string{targetURL, mirrorURL}
+	c.mu.Unlock()
+
+	sourceCh := make(chan *ClientContent)
+	targetCh := make(chan *ClientContent)
+	send := func(ch chan<- *ClientContent, content *ClientContent) bool {
+		select {
+		case ch <- content:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(targetCh)
+
+		// The source is listed first, the target objects to check
+		// depend on it.
+		var sources []*ClientContent
+		for content := range sourceClnt.List(ctx, ListOptions{Recursive: true, WithMetadata: opts.isMetadata, ShowDir: DirNone}) {
+			sources = append(sources, content)
+			if content.Err == nil {
+				c.mu.Lock()
+				c.sources[checkpointKey(content, sourceURL)] = content
+				c.mu.Unlock()
+			}
+		}
+		go func() {
+			defer close(sourceCh)
+			for _, content := range sources {
+				if !send(sourceCh, content) {
+					return
+				}
+			}
+		}()
+
+		record := func(content *ClientContent) {
+			key := checkpointKey(content, targetURL)
+			c.mu.Lock()
+			c.targets[key] = mirrorCheckpointEntry{Key: key, Size: content.Size, ETag: content.ETag, ModTime: content.Time}
+			c.mu.Unlock()
+		}
+		if !c.usable {
+			for content := range targetClnt.List(ctx, ListOptions{Recursive: true, WithMetadata: opts.isMetadata, ShowDir: DirNone}) {
+				if content.Err == nil {
+					record(content)
+				}
+				if !send(targetCh, content) {
+					return
+				}
+			}
+			return
+		}
+
+		for _, key := range c.keys() {
+			content := c.targetContent(ctx, key, targetAlias, opts)
+			if content == nil {
+				continue
+			}
+			if content.Err == nil {
+				record(content)
+			}
+			if !send(targetCh, content) {
+				return
+			}
+		}
+	}()
+	return difference(sourceURL, sourceCh, targetURL, targetCh, opts.isMetadata, false, opts.compare)
+}
+
+// keys returns the keys of the source and of the checkpoint, in the
+// order of a listing.
+func (c *mirrorCheckpoint) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.known)+len(c.sources))
+	for key := range c.known {
+		keys = append(keys, key)
+	}
+	for key := range c.sources {
+		if _, ok := c.known[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// targetContent returns the target object of key, from the checkpoint
+// when its source did not change, from the target otherwise. It returns
+// nil when the target object does not exist.
+func (c *mirrorCheckpoint) targetContent(ctx context.Context, key, targetAlias string, opts mirrorOptions) *ClientContent {
+	c.mu.Lock()
+	entry, known := c.known[key]
+	source, inSource := c.sources[key]
+	c.mu.Unlock()
+	if known && (!inSource || source.Size == entry.SourceSize && source.Time.Equal(entry.SourceModTime)) {
+		return entry.content(c.targetURLs[0])
+	}
+
+	targetURL := urlJoinPath(c.targetURLs[0], key)
+	clnt, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		return &ClientContent{Err: err.Trace(targetURL)}
+	}
+	targetPath := filepath.ToSlash(filepath.Join(targetAlias, clnt.GetURL().Path))
+	content, err := clnt.Stat(ctx, StatOptions{sse: getSSE(targetPath, opts.encKeyDB[targetAlias])})
+	switch err.ToGoError().(type) {
+	case nil:
+		return content
+	case ObjectMissing, PathNotFound:
+		return nil
+	}
+	return &ClientContent{Err: err.Trace(targetURL)}
+}
+
+// record appends a successful transfer of a run to the journal.
+func (c *mirrorCheckpoint) record(urls URLs) *probe.Error {
+	if urls.TargetContent == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := mirrorCheckpointEntry{Key: checkpointKey(urls.TargetContent, c.targetURLs...)}
+	if urls.SourceContent != nil {
+		entry.Size = urls.SourceContent.Size
+		entry.ETag = urls.TargetContent.ETag
+		entry.ModTime = UTCNow()
+		entry.SourceSize = urls.SourceContent.Size
+		entry.SourceModTime = urls.SourceContent.Time
+	} else {
+		entry.Removed = true
+	}
+	line, e := json.Marshal(entry)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if _, e = c.journal.Write(append(line, '\n')); e != nil {
+		return probe.NewError(e)
+	}
+	c.updates = append(c.updates, entry)
+	return nil
+}
+
+// save writes the checkpoint of a successful run, and removes its journal.
+func (c *mirrorCheckpoint) save() *probe.Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.targets
+	for key, entry := range entries {
+		if source, ok := c.sources[key]; ok {
+			entry.SourceSize, entry.SourceModTime = source.Size, source.Time
+			entries[key] = entry
+		}
+	}
+	for _, entry := range c.updates {
+		if entry.Removed {
+			delete(entries, entry.Key)
+			continue
+		}
+		entries[entry.Key] = entry
+	}
+
+	file := mirrorCheckpointFile{
+		Version:  mirrorCheckpointVersion,
+		Source:   c.source,
+		Target:   c.target,
+		FullScan: c.fullScan,
+		Entries:  make([]mirrorCheckpointEntry, 0, len(entries)),
+	}
+	if !c.usable {
+		file.FullScan = UTCNow()
+	}
+	for _, entry := range entries {
+		file.Entries = append(file.Entries, entry)
+	}
+	sort.Slice(file.Entries, func(i, j int) bool {
+		return file.Entries[i].Key < file.Entries[j].Key
+	})
+	data, e := json.Marshal(file)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if err := writeFileAtomic(c.path, data); err != nil {
+		return err.Trace(c.path)
+	}
+	c.journal.Close()
+	return probe.NewError(os.Remove(c.path + mirrorCheckpointJournalSuffix))
+}
+
+// close closes the journal of an unsuccessful run, which the next run
+// resumes from.
+func (c *mirrorCheckpoint) close() {
+	c.journal.Close()
+}
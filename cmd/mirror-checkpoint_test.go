@@ -0,0 +1,142 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		if e := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+}
+
+// mirrorCheckpointRun returns the sources to copy from src to dst with the
+// checkpoint at path, after copying them.
+func mirrorCheckpointRun(t *testing.T, path, src, dst string, fullScanInterval time.Duration) (*mirrorCheckpoint, []string) {
+	t.Helper()
+	checkpoint, err := loadMirrorCheckpoint(path, src, dst, fullScanInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copied []string
+	for urls := range prepareMirrorURLs(context.Background(), src, dst, mirrorOptions{isOverwrite: true, checkpoint: checkpoint}) {
+		if urls.Error != nil {
+			t.Fatal(urls.Error)
+		}
+		name := filepath.Base(urls.SourceContent.URL.Path)
+		data, e := os.ReadFile(urls.SourceContent.URL.Path)
+		if e != nil {
+			t.Fatal(e)
+		}
+		writeTestFiles(t, dst, map[string]string{name: string(data)})
+		copied = append(copied, name)
+		if err = checkpoint.record(urls); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Strings(copied)
+	return checkpoint, copied
+}
+
+func TestMirrorCheckpoint(t *testing.T) {
+	initTestConfig(t)
+	src, dst := t.TempDir(), t.TempDir()
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	writeTestFiles(t, src, map[string]string{"a": "a", "b": "bb", "c": "ccc"})
+	writeTestFiles(t, dst, map[string]string{"a": "a", "b": "bb"})
+
+	// Without a checkpoint, the target is listed.
+	checkpoint, copied := mirrorCheckpointRun(t, path, src, dst, time.Hour)
+	if len(copied) != 1 || copied[0] != "c" {
+		t.Fatalf("expected c to be copied, got %v", copied)
+	}
+	if err := checkpoint.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// With the checkpoint, the target is not listed: the removed target
+	// objects go unnoticed, except for b whose source changed.
+	for _, name := range []string{"a", "b"} {
+		if e := os.Remove(filepath.Join(dst, name)); e != nil {
+			t.Fatal(e)
+		}
+	}
+	writeTestFiles(t, src, map[string]string{"b": "bbbb"})
+	checkpoint, copied = mirrorCheckpointRun(t, path, src, dst, time.Hour)
+	if len(copied) != 1 || copied[0] != "b" {
+		t.Fatalf("expected b to be copied, got %v", copied)
+	}
+	// The run is interrupted, the next one resumes from its journal.
+	checkpoint.close()
+	checkpoint, copied = mirrorCheckpointRun(t, path, src, dst, time.Hour)
+	if len(copied) != 0 {
+		t.Fatalf("expected nothing to be copied, got %v", copied)
+	}
+	checkpoint.close()
+
+	// Past the full scan interval, the target is listed again and the
+	// removal of a is noticed.
+	checkpoint, copied = mirrorCheckpointRun(t, path, src, dst, 0)
+	if len(copied) != 1 || copied[0] != "a" {
+		t.Fatalf("expected a to be copied, got %v", copied)
+	}
+	checkpoint.close()
+}
+
+// The checkpoint records the ETag of the uploaded objects.
+func TestMirrorCheckpointETag(t *testing.T) {
+	server := newS3TestServer(t)
+	src := t.TempDir()
+	writeTestFiles(t, src, map[string]string{"object": "data"})
+	checkpoint, err := loadMirrorCheckpoint(filepath.Join(t.TempDir(), "checkpoint"), src, "s3test/bucket", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checkpoint.close()
+
+	alias, targetURL, _ := mustExpandAlias("s3test/bucket/object")
+	urls := uploadSourceToTargetURL(context.Background(), uploadSourceToTargetURLOpts{
+		urls: URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(filepath.Join(src, "object")), Size: 4},
+			TargetAlias:   alias,
+			// The ETag of the object replaced by the upload.
+			TargetContent: &ClientContent{URL: *newClientURL(targetURL), ETag: "stale"},
+		},
+		progress: newAccounter(0),
+	})
+	if urls.Error != nil {
+		t.Fatal(urls.Error)
+	}
+	if err = checkpoint.record(urls); err != nil {
+		t.Fatal(err)
+	}
+	object, _ := server.Object("bucket", "object")
+	if len(checkpoint.updates) != 1 || checkpoint.updates[0].ETag != object.ETag {
+		t.Fatalf("expected the ETag %s of the upload to be recorded, got %+v", object.ETag, checkpoint.updates)
+	}
+}
@@ -46,9 +46,8 @@ import (
 var (
 	mirrorFlags = []cli.Flag{
 		cli.BoolFlag{
-			Name:   "force",
-			Usage:  "force allows forced overwrite or removal of object(s) on target",
-			Hidden: true, // Hidden since this option is deprecated.
+			Name:  "force",
+			Usage: "remove object(s) with --remove without confirmation, required when not run on a terminal, implies --overwrite",
 		},
 		cli.BoolFlag{
 			Name:  "overwrite",
@@ -129,6 +128,11 @@ var (
 			Name:  "monitoring-address",
 			Usage: "if specified, a new prometheus endpoint will be created to report mirroring activity. (eg: localhost:8081)",
 		},
+		cli.StringFlag{
+			Name:  "compare",
+			Value: string(compareMTime),
+			Usage: "copy objects of the same size when the source is newer (mtime), never (size) or when their MD5 sums differ (checksum, or etag to cache the sums of local files, by size for multipart objects)",
+		},
 		cli.BoolFlag{
 			Name:  "retry",
 			Usage: "if specified, will enable retrying on a per object basis if errors occur",
@@ -137,10 +141,30 @@ var (
 			Name:  "summary",
 			Usage: "print a summary of the mirror session",
 		},
+		cli.BoolFlag{
+			Name:  "summary-only",
+			Usage: "suppress per-object output, only print the final summary",
+		},
 		cli.BoolFlag{
 			Name:  "skip-errors",
 			Usage: "skip any errors when mirroring",
 		},
+		cli.StringFlag{
+			Name:  "checkpoint",
+			Usage: "keep the state of the target in this file, so that the next mirror only checks the changed files and resumes an interrupted mirror",
+		},
+		cli.StringFlag{
+			Name:  "full-scan-interval",
+			Usage: "with --checkpoint, list the whole target again once its last listing is older than this",
+			Value: "24h",
+		},
+		cli.BoolFlag{
+			Name:  "verify",
+			Usage: "list the source and the target again after mirroring, and fail if an object is missing on the target or differs in size or MD5 sum",
+		},
+		preflightFlag,
+		maxObjectSizeFlag,
+		storeChecksumFlag,
 	}
 )
 
@@ -151,7 +175,7 @@ var mirrorCmd = cli.Command{
 	Action:       mainMirror,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(mirrorFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(append(mirrorFlags, pathMappingFlags...), metricsFileFlags...), uploadNotifyFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -164,6 +188,7 @@ FLAGS:
 ENVIRONMENT VARIABLES:
    MC_ENCRYPT:      list of comma delimited prefixes
    MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+   MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 EXAMPLES:
   01. Mirror a bucket recursively from MinIO cloud storage to a bucket on Amazon S3 cloud storage.
@@ -182,15 +207,15 @@ EXAMPLES:
       {{.Prompt}} {{.HelpName}} --overwrite s3/miniocloud miniocloud-backup
 
   06. Mirror a bucket from MinIO cloud storage to a bucket on Amazon S3 cloud storage and remove any extraneous
-      files on Amazon S3 cloud storage.
+      files on Amazon S3 cloud storage, after typing the target to confirm.
       {{.Prompt}} {{.HelpName}} --remove play/photos/2014 s3/backup-photos/2014
 
   07. Continuously mirror a local folder recursively to MinIO cloud storage. '--watch' continuously watches for
       new objects, uploads and removes extraneous files on Amazon S3 cloud storage.
-      {{.Prompt}} {{.HelpName}} --remove --watch /var/lib/backups play/backups
+      {{.Prompt}} {{.HelpName}} --remove --force --watch /var/lib/backups play/backups
 
   08. Continuously mirror all buckets and objects from site 1 to site 2, removed buckets and objects will be reflected as well.
-      {{.Prompt}} {{.HelpName}} --remove --watch site1-alias/ site2-alias/
+      {{.Prompt}} {{.HelpName}} --remove --force --watch site1-alias/ site2-alias/
 
   09. Mirror a bucket from aliased Amazon S3 cloud storage to a local folder.
       Exclude all .* files and *.temp files when mirroring.
@@ -222,6 +247,34 @@ EXAMPLES:
   17. Cross mirror between sites in a active-active deployment.
       Site-A: {{.Prompt}} {{.HelpName}} --active-active siteA siteB
       Site-B: {{.Prompt}} {{.HelpName}} --active-active siteB siteA
+
+  18. Mirror a local folder and only overwrite the objects whose content changed, regardless of modification times.
+      {{.Prompt}} {{.HelpName}} --overwrite --compare checksum backup/ s3/archive
+
+  19. Mirror a large local folder every hour, listing the whole bucket only once a day.
+      {{.Prompt}} {{.HelpName}} --checkpoint ~/.backup.checkpoint --full-scan-interval 24h backup/ s3/archive
+
+  20. Mirror a dataset again, skipping the files identical to their object even if their modification time changed.
+      {{.Prompt}} {{.HelpName}} --overwrite --compare etag dataset/ s3/datasets
+
+  21. Mirror a local folder and check afterwards that every file is on the target.
+      {{.Prompt}} {{.HelpName}} --verify backup/ s3/archive
+
+  22. Mirror a local folder without first checking that the endpoint is reachable and accepts the credentials.
+      {{.Prompt}} {{.HelpName}} --preflight=false backup/ s3/archive
+
+  23. Mirror the runs of data/2024 as 'run1/...', without their leading '11/' folder, or all their files directly under the prefix.
+      {{.Prompt}} {{.HelpName}} --strip-components 1 data/2024/ s3/runs
+      {{.Prompt}} {{.HelpName}} --flatten data/2024/ s3/runs
+
+  24. Mirror a local folder, writing its counters for the node exporter once done.
+      {{.Prompt}} {{.HelpName}} --metrics-file /var/lib/node_exporter/mirror.prom backup/ s3/archive
+
+  25. Mirror a local folder, notifying a pipeline once with the list of the uploaded files.
+      {{.Prompt}} MC_NOTIFY_TOKEN=TOKEN {{.HelpName}} --notify https://pipeline.example.com/hooks/upload --notify-batch backup/ s3/archive
+
+  26. Mirror a local folder, storing the SHA-256 sums of the uploaded files in the metadata of their objects.
+      {{.Prompt}} {{.HelpName}} --store-checksum backup/ s3/archive
 `,
 }
 
@@ -487,13 +540,16 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 		})
 	}
 	sURLs.MD5 = mj.opts.md5
+	sURLs.StoreChecksum = mj.opts.storeChecksum
 	sURLs.DisableMultipart = mj.opts.disableMultipart
 
 	var ret URLs
+	start := time.Now()
+	defer func() { mj.opts.notifier.notifyURLs(ret, start) }()
 
 	if !mj.opts.isRetriable {
 		now := time.Now()
-		ret = uploadSourceToTargetURL(ctx, uploadSourceToTargetURLOpts{urls: sURLs, progress: mj.status, encKeyDB: mj.opts.encKeyDB, preserve: mj.opts.isMetadata, isZip: false})
+		ret = uploadSourceToTargetURL(ctx, uploadSourceToTargetURLOpts{urls: sURLs, progress: mj.status, encKeyDB: mj.opts.encKeyDB, preserve: mj.opts.isMetadata, isZip: false, maxObjectSize: mj.opts.maxObjectSize})
 		if ret.Error == nil {
 			durationMs := time.Since(now).Milliseconds()
 			mirrorReplicationDurations.With(prometheus.Labels{"object_size": convertSizeToTag(sURLs.SourceContent.Size)}).Observe(float64(durationMs))
@@ -503,7 +559,7 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 	}
 
 	newRetryManager(ctx, time.Second, 3).retry(func(rm *retryManager) *probe.Error {
-		if rm.retries > 0 {
+		if rm.retries > 0 && !mj.opts.isSummaryOnly {
 			printMsg(retryMessage{
 				SourceURL: sURLs.SourceContent.URL.String(),
 				TargetURL: sURLs.TargetContent.URL.String(),
@@ -512,7 +568,7 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 		}
 
 		now := time.Now()
-		ret = uploadSourceToTargetURL(ctx, uploadSourceToTargetURLOpts{urls: sURLs, progress: mj.status, encKeyDB: mj.opts.encKeyDB, preserve: mj.opts.isMetadata, isZip: false})
+		ret = uploadSourceToTargetURL(ctx, uploadSourceToTargetURLOpts{urls: sURLs, progress: mj.status, encKeyDB: mj.opts.encKeyDB, preserve: mj.opts.isMetadata, isZip: false, maxObjectSize: mj.opts.maxObjectSize})
 		if ret.Error == nil {
 			durationMs := time.Since(now).Milliseconds()
 			mirrorReplicationDurations.With(prometheus.Labels{"object_size": convertSizeToTag(sURLs.SourceContent.Size)}).Observe(float64(durationMs))
@@ -561,7 +617,7 @@ func (mj *mirrorJob) monitorMirrorStatus(cancel context.CancelFunc) (errDuringMi
 							errorIf(sURLs.Error.Trace(sURLs.SourceContent.URL.String()),
 								fmt.Sprintf("Failed to copy `%s`.", sURLs.SourceContent.URL.String()))
 						} else {
-							console.Infof("[Warn] Failed to copy `%s`. %s", sURLs.SourceContent.URL.String(), sURLs.Error.Trace(sURLs.SourceContent.URL.String()))
+							statusf("[Warn] Failed to copy `%s`. %s\n", sURLs.SourceContent.URL.String(), sURLs.Error.Trace(sURLs.SourceContent.URL.String()))
 						}
 					}
 				}
@@ -581,11 +637,18 @@ func (mj *mirrorJob) monitorMirrorStatus(cancel context.CancelFunc) (errDuringMi
 				}
 			}
 
+			if ignoreErr && sURLs.SourceContent != nil {
+				mj.opts.metrics.objectSkipped()
+			}
 			if !ignoreErr {
 				mirrorFailedOps.Inc()
+				mj.opts.metrics.objectFailed()
 				errDuringMirror = true
-				// Quit mirroring if --watch and --active-active are not passed
-				if !mj.opts.skipErrors && !mj.opts.activeActive && !mj.opts.isWatch {
+				// Quit mirroring if --watch and --active-active are not
+				// passed, or once the disk is full as the next downloads
+				// would fail as well.
+				_, diskFull := sURLs.Error.ToGoError().(DiskFull)
+				if diskFull || !mj.opts.skipErrors && !mj.opts.activeActive && !mj.opts.isWatch {
 					cancel()
 					cancelInProgress = true
 				}
@@ -594,9 +657,13 @@ func (mj *mirrorJob) monitorMirrorStatus(cancel context.CancelFunc) (errDuringMi
 			continue
 		}
 
+		if mj.opts.checkpoint != nil && !mj.opts.isFake {
+			errorIf(mj.opts.checkpoint.record(sURLs), "Unable to record the transfer in the checkpoint.")
+		}
 		if sURLs.SourceContent != nil {
 			mirrorTotalUploadedBytes.Add(float64(sURLs.SourceContent.Size))
-		} else if sURLs.TargetContent != nil {
+			mj.opts.metrics.objectTransferred(sURLs.SourceContent.Size)
+		} else if sURLs.TargetContent != nil && !mj.opts.isSummaryOnly {
 			// Construct user facing message and path.
 			targetPath := filepath.ToSlash(filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path))
 			mj.status.PrintMsg(rmMessage{Key: targetPath})
@@ -680,6 +747,7 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 				TargetAlias:      targetAlias,
 				TargetContent:    &ClientContent{URL: *targetURL},
 				MD5:              mj.opts.md5,
+				StoreChecksum:    mj.opts.storeChecksum,
 				DisableMultipart: mj.opts.disableMultipart,
 				encKeyDB:         mj.opts.encKeyDB,
 			}
@@ -707,6 +775,7 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 				TargetAlias:      targetAlias,
 				TargetContent:    &ClientContent{URL: *targetURL},
 				MD5:              mj.opts.md5,
+				StoreChecksum:    mj.opts.storeChecksum,
 				DisableMultipart: mj.opts.disableMultipart,
 				encKeyDB:         mj.opts.encKeyDB,
 			}
@@ -876,7 +945,7 @@ func newMirrorJob(srcURL, dstURL string, opts mirrorOptions) *mirrorJob {
 
 	// we'll define the status to use here,
 	// do we want the quiet status? or the progressbar
-	if globalQuiet {
+	if globalQuiet || opts.isSummaryOnly {
 		mj.status = NewQuietStatus(mj.parallel)
 	} else if globalJSON {
 		mj.status = NewQuietStatus(mj.parallel)
@@ -939,7 +1008,7 @@ func getEventPathURLWin(srcURL, eventPath string) string {
 }
 
 // runMirror - mirrors all buckets to another S3 server
-func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, encKeyDB map[string][]prefixSSEPair) bool {
+func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, encKeyDB map[string][]prefixSSEPair, metrics *metricsFile, notifier *uploadNotifier) bool {
 	// Parse metadata.
 	userMetadata := make(map[string]string)
 	if cli.String("attr") != "" {
@@ -966,6 +1035,7 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 	// preserve is also expected to be overwritten if necessary
 	isMetadata := cli.Bool("a") || isWatch || len(userMetadata) > 0
 	isFake := cli.Bool("fake") || cli.Bool("dry-run")
+	compare, _ := parseCompareMode(cli.String("compare"))
 
 	mopts := mirrorOptions{
 		isFake:                isFake,
@@ -973,9 +1043,11 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 		isOverwrite:           isOverwrite,
 		isWatch:               isWatch,
 		isMetadata:            isMetadata,
-		isSummary:             cli.Bool("summary"),
+		isSummary:             cli.Bool("summary") || cli.Bool("summary-only"),
+		isSummaryOnly:         cli.Bool("summary-only"),
 		isRetriable:           cli.Bool("retry"),
 		md5:                   cli.Bool("md5"),
+		storeChecksum:         cli.Bool("store-checksum"),
 		disableMultipart:      cli.Bool("disable-multipart"),
 		skipErrors:            cli.Bool("skip-errors"),
 		excludeOptions:        cli.StringSlice("exclude"),
@@ -987,6 +1059,22 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 		userMetadata:          userMetadata,
 		encKeyDB:              encKeyDB,
 		activeActive:          isWatch,
+		compare:               compare,
+		pathMapping:           parsePathMapping(cli),
+		maxObjectSize:         parseMaxObjectSize(cli),
+		metrics:               metrics,
+		notifier:              notifier,
+	}
+
+	if checkpointPath := cli.String("checkpoint"); checkpointPath != "" {
+		fullScanInterval, _ := ParseDuration(cli.String("full-scan-interval"))
+		if isMetadata {
+			// Metadata is compared on a listing of the target.
+			fullScanInterval = 0
+		}
+		checkpoint, err := loadMirrorCheckpoint(checkpointPath, srcURL, dstURL, time.Duration(fullScanInterval))
+		fatalIf(err, "Unable to load the checkpoint `"+checkpointPath+"`.")
+		mopts.checkpoint = checkpoint
 	}
 
 	// Create a new mirror job and execute it
@@ -1092,13 +1180,37 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 		}
 	}
 
-	return mj.mirror(ctx)
+	errDuringMirror := mj.mirror(ctx)
+	if compare == compareETag {
+		errorIf(getMD5Cache().save(), "Unable to save the MD5 sums of local files.")
+		printMsg(newETagCompareMessage())
+	}
+	if checkpoint := mj.opts.checkpoint; checkpoint != nil {
+		if !errDuringMirror && ctx.Err() == nil && !isFake {
+			errorIf(checkpoint.save(), "Unable to save the checkpoint `"+cli.String("checkpoint")+"`.")
+		} else {
+			checkpoint.close()
+		}
+	}
+	if cli.Bool("verify") && ctx.Err() == nil {
+		summary, err := verifyMirror(ctx, srcURL, dstURL, mopts)
+		if err != nil {
+			errorIf(err, "Unable to verify the mirror of `"+srcURL+"` to `"+dstURL+"`.")
+			return true
+		}
+		printMsg(summary)
+		if summary.Mismatched > 0 {
+			return true
+		}
+	}
+	return errDuringMirror
 }
 
 // Main entry point for mirror command.
 func mainMirror(cliCtx *cli.Context) error {
 	// Additional command specific theme customization.
 	console.SetColor("Mirror", color.New(color.FgGreen, color.Bold))
+	console.SetColor("MirrorVerifyFailed", color.New(color.FgRed, color.Bold))
 
 	ctx, cancelMirror := context.WithCancel(globalContext)
 	defer cancelMirror()
@@ -1107,6 +1219,11 @@ func mainMirror(cliCtx *cli.Context) error {
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	// Mirrors always scan a folder, check the endpoints before.
+	if isPreflightEnabled(cliCtx, true) {
+		fatalIf(preflight(ctx, cliCtx.Args()...), "Unable to mirror, use --preflight=false to skip this check.")
+	}
+
 	// check 'mirror' cli arguments.
 	srcURL, tgtURL := checkMirrorSyntax(ctx, cliCtx, encKeyDB)
 
@@ -1119,19 +1236,26 @@ func mainMirror(cliCtx *cli.Context) error {
 		}()
 	}
 
+	// The counters and notifications of --watch span the restarts of
+	// the mirror.
+	metrics := newMetricsFileFromContext(cliCtx)
+	defer metrics.close()
+	notifier := newUploadNotifierFromContext(cliCtx)
+	defer notifier.close()
+
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	for {
 		select {
 		case <-ctx.Done():
 			return exitStatus(globalErrorExitStatus)
 		default:
-			errorDetected := runMirror(ctx, srcURL, tgtURL, cliCtx, encKeyDB)
+			errorDetected := runMirror(ctx, srcURL, tgtURL, cliCtx, encKeyDB, metrics, notifier)
 			if cliCtx.Bool("watch") || cliCtx.Bool("multi-master") || cliCtx.Bool("active-active") {
 				mirrorRestarts.Inc()
 				time.Sleep(time.Duration(r.Float64() * float64(2*time.Second)))
 				continue
 			}
-			if errorDetected {
+			if notifier.close() || errorDetected {
 				return exitStatus(globalErrorExitStatus)
 			}
 			return nil
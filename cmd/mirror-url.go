@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/wildcard"
 )
 
@@ -45,12 +46,29 @@ func checkMirrorSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[st
 	srcURL = URLs[0]
 	tgtURL = URLs[1]
 
-	if cliCtx.Bool("force") && cliCtx.Bool("remove") {
-		errorIf(errInvalidArgument().Trace(URLs...), "`--force` is deprecated, please use `--overwrite` instead with `--remove` for the same functionality.")
-	} else if cliCtx.Bool("force") {
+	if cliCtx.Bool("force") && !cliCtx.Bool("remove") {
 		errorIf(errInvalidArgument().Trace(URLs...), "`--force` is deprecated, please use `--overwrite` instead for the same functionality.")
 	}
 
+	compare, ok := parseCompareMode(cliCtx.String("compare"))
+	if !ok {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("compare")), "--compare should be one of checksum, etag, size or mtime.")
+	}
+	if compare != compareMTime && (cliCtx.Bool("active-active") || cliCtx.Bool("multi-master")) {
+		fatalIf(errInvalidArgument().Trace(URLs...), "--compare "+string(compare)+" cannot be used with --active-active, which relies on modification times.")
+	}
+
+	// Renamed sources are listed in full, they cannot be watched or
+	// compared with a checkpoint of the target.
+	if mapping := parsePathMapping(cliCtx); mapping.isSet() {
+		if cliCtx.Bool("watch") || cliCtx.Bool("active-active") || cliCtx.Bool("multi-master") {
+			fatalIf(errInvalidArgument().Trace(URLs...), mapping.String()+" cannot be used with --watch or --active-active.")
+		}
+		if cliCtx.String("checkpoint") != "" {
+			fatalIf(errInvalidArgument().Trace(URLs...), mapping.String()+" cannot be used with --checkpoint.")
+		}
+	}
+
 	_, expandedSourcePath, _ := mustExpandAlias(srcURL)
 	srcClient := newClientURL(expandedSourcePath)
 	_, expandedTargetPath, _ := mustExpandAlias(tgtURL)
@@ -86,6 +104,33 @@ func checkMirrorSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[st
 		}
 	}
 
+	if cliCtx.IsSet("checkpoint") && (cliCtx.Bool("watch") || cliCtx.Bool("active-active") || cliCtx.Bool("multi-master")) {
+		fatalIf(errInvalidArgument().Trace(URLs...), "--checkpoint cannot be used with --watch or --active-active.")
+	}
+	if cliCtx.Bool("verify") && (cliCtx.Bool("watch") || cliCtx.Bool("active-active") || cliCtx.Bool("multi-master") || cliCtx.Bool("dry-run") || cliCtx.Bool("fake")) {
+		fatalIf(errInvalidArgument().Trace(URLs...), "--verify cannot be used with --watch, --active-active or --dry-run.")
+	}
+	if _, e := ParseDuration(cliCtx.String("full-scan-interval")); e != nil {
+		fatalIf(probe.NewError(e).Trace(cliCtx.String("full-scan-interval")), "Unable to parse --full-scan-interval.")
+	}
+
+	// --remove may delete anything on the target, on a terminal the user
+	// may confirm by typing the target instead of passing --force.
+	if cliCtx.Bool("remove") && !cliCtx.Bool("force") && !cliCtx.Bool("dry-run") && !cliCtx.Bool("fake") {
+		if !canConfirm() {
+			fatalIf(errInvalidArgument().Trace(URLs...), "--remove requires --force when not run on a terminal or with --quiet or --json.")
+		}
+		summary, err := summarizeObjects(ctx, tgtURL, false)
+		switch err.ToGoError().(type) {
+		case nil:
+			mustConfirmTarget("`"+tgtURL+"` holds "+summary+", --remove deletes those which are not in `"+srcURL+"`.", tgtURL)
+		case BucketDoesNotExist, PathNotFound:
+			// Nothing to remove on a new target.
+		default:
+			fatalIf(err.Trace(tgtURL), "Unable to list target `"+tgtURL+"`.")
+		}
+	}
+
 	return
 }
 
@@ -126,6 +171,41 @@ func matchExcludeBucketOptions(excludeBuckets []string, srcSuffix string) bool {
 	return false
 }
 
+// isMirrorExcluded returns whether the exclude options skip a difference
+// of the expanded URLs sourceURL and targetURL.
+func isMirrorExcluded(opts mirrorOptions, sourceURL, targetURL string, diffMsg diffMessage) bool {
+	srcSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
+	// Skip the source object if it matches the Exclude options provided
+	if matchExcludeOptions(opts.excludeOptions, srcSuffix, newClientURL(sourceURL).Type) {
+		return true
+	}
+
+	// Skip the source bucket if it matches the Exclude options provided
+	if matchExcludeBucketOptions(opts.excludeBuckets, srcSuffix) {
+		return true
+	}
+
+	tgtSuffix := strings.TrimPrefix(diffMsg.SecondURL, targetURL)
+	// Skip the target object if it matches the Exclude options provided
+	if matchExcludeOptions(opts.excludeOptions, tgtSuffix, newClientURL(targetURL).Type) {
+		return true
+	}
+
+	// Skip the target bucket if it matches the Exclude options provided
+	if matchExcludeBucketOptions(opts.excludeBuckets, tgtSuffix) {
+		return true
+	}
+
+	if diffMsg.firstContent != nil {
+		for _, esc := range opts.excludeStorageClasses {
+			if esc == diffMsg.firstContent.StorageClass {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mirrorOptions, URLsCh chan<- URLs) {
 	// source and targets are always directories
 	sourceSeparator := string(newClientURL(sourceURL).Separator)
@@ -165,54 +245,37 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 	}
 
 	// List both source and target, compare and return values through channel.
-	for diffMsg := range objectDifference(ctx, sourceClnt, targetClnt, opts.isMetadata) {
+	var diffCh chan diffMessage
+	switch {
+	case opts.checkpoint != nil:
+		diffCh = opts.checkpoint.difference(ctx, sourceClnt, targetClnt, targetAlias, targetURL, opts)
+	case opts.pathMapping.isSet():
+		// Collisions are reported before anything is mirrored.
+		diffCh, err = mappedObjectDifference(ctx, sourceClnt, targetClnt, opts.isMetadata, opts.compare, opts.pathMapping)
+		if err != nil {
+			URLsCh <- URLs{Error: err.Trace(sourceURL), ErrorCond: differInUnknown}
+			return
+		}
+	default:
+		diffCh = objectDifference(ctx, sourceClnt, targetClnt, opts.isMetadata, opts.compare)
+	}
+	for diffMsg := range diffCh {
 		if diffMsg.Error != nil {
 			// Send all errors through the channel
 			URLsCh <- URLs{Error: diffMsg.Error, ErrorCond: differInUnknown}
 			continue
 		}
 
-		srcSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
-		// Skip the source object if it matches the Exclude options provided
-		if matchExcludeOptions(opts.excludeOptions, srcSuffix, newClientURL(sourceURL).Type) {
-			continue
-		}
-
-		// Skip the source bucket if it matches the Exclude options provided
-		if matchExcludeBucketOptions(opts.excludeBuckets, srcSuffix) {
+		if isMirrorExcluded(opts, sourceURL, targetURL, diffMsg) {
 			continue
 		}
 
-		tgtSuffix := strings.TrimPrefix(diffMsg.SecondURL, targetURL)
-		// Skip the target object if it matches the Exclude options provided
-		if matchExcludeOptions(opts.excludeOptions, tgtSuffix, newClientURL(targetURL).Type) {
-			continue
-		}
-
-		// Skip the target bucket if it matches the Exclude options provided
-		if matchExcludeBucketOptions(opts.excludeBuckets, tgtSuffix) {
-			continue
-		}
-
-		if diffMsg.firstContent != nil {
-			var found bool
-			for _, esc := range opts.excludeStorageClasses {
-				if esc == diffMsg.firstContent.StorageClass {
-					found = true
-					break
-				}
-			}
-			if found {
-				continue
-			}
-		}
-
 		switch diffMsg.Diff {
 		case differInNone:
 			// No difference, continue.
 		case differInType:
 			URLsCh <- URLs{Error: errInvalidTarget(diffMsg.SecondURL)}
-		case differInSize, differInMetadata, differInAASourceMTime:
+		case differInSize, differInMetadata, differInAASourceMTime, differInChecksum:
 			if !opts.isOverwrite && !opts.isFake && !opts.activeActive {
 				// Size or time or etag differs but --overwrite not set.
 				URLsCh <- URLs{
@@ -222,9 +285,8 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 				continue
 			}
 
-			sourceSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
 			// Either available only in source or size differs and force is set
-			targetPath := urlJoinPath(targetURL, sourceSuffix)
+			targetPath := urlJoinPath(targetURL, mirrorSourceSuffix(sourceURL, diffMsg))
 			sourceContent := diffMsg.firstContent
 			targetContent := &ClientContent{URL: *newClientURL(targetPath)}
 			URLsCh <- URLs{
@@ -235,8 +297,7 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 			}
 		case differInFirst:
 			// Only in first, always copy.
-			sourceSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
-			targetPath := urlJoinPath(targetURL, sourceSuffix)
+			targetPath := urlJoinPath(targetURL, mirrorSourceSuffix(sourceURL, diffMsg))
 			sourceContent := diffMsg.firstContent
 			targetContent := &ClientContent{URL: *newClientURL(targetPath)}
 			URLsCh <- URLs{
@@ -266,14 +327,20 @@ type mirrorOptions struct {
 	isFake, isOverwrite, activeActive                     bool
 	isWatch, isRemove, isMetadata                         bool
 	isRetriable                                           bool
-	isSummary                                             bool
+	isSummary, isSummaryOnly                              bool
 	skipErrors                                            bool
 	excludeOptions, excludeStorageClasses, excludeBuckets []string
 	encKeyDB                                              map[string][]prefixSSEPair
-	md5, disableMultipart                                 bool
+	md5, disableMultipart, storeChecksum                  bool
 	olderThan, newerThan                                  string
 	storageClass                                          string
 	userMetadata                                          map[string]string
+	compare                                               compareMode
+	checkpoint                                            *mirrorCheckpoint
+	pathMapping                                           pathMapping
+	maxObjectSize                                         int64
+	metrics                                               *metricsFile
+	notifier                                              *uploadNotifier
 }
 
 // Prepares urls that need to be copied or removed based on requested options.
@@ -0,0 +1,172 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// mirrorVerifyMessage is a source object `mirror --verify` did not find
+// on the target as expected.
+type mirrorVerifyMessage struct {
+	Status     string `json:"status"`
+	Type       string `json:"type"`
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	Reason     string `json:"reason"`
+	SourceSize int64  `json:"sourceSize"`
+	TargetSize int64  `json:"targetSize"`
+}
+
+func (m mirrorVerifyMessage) String() string {
+	var reason string
+	switch m.Reason {
+	case "missing":
+		reason = "missing on the target"
+	case "size":
+		reason = fmt.Sprintf("size %s on the target, %s expected", formatSize(m.TargetSize), formatSize(m.SourceSize))
+	case "checksum":
+		reason = "content differs from the source"
+	default:
+		reason = "not an object on the target"
+	}
+	return console.Colorize("MirrorVerifyFailed", fmt.Sprintf("`%s` -> `%s`: %s.", m.Source, m.Target, reason))
+}
+
+func (m mirrorVerifyMessage) JSON() string {
+	m.Status = "error"
+	m.Type = "verify"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mirrorVerifySummary is printed after `mirror --verify` compared the
+// source and the target.
+type mirrorVerifySummary struct {
+	Status     string `json:"status"`
+	Type       string `json:"type"`
+	Verified   int64  `json:"verified"`
+	Mismatched int64  `json:"mismatched"`
+}
+
+func (m mirrorVerifySummary) String() string {
+	if m.Mismatched > 0 {
+		return console.Colorize("MirrorVerifyFailed", fmt.Sprintf("Verification failed, %d object(s) verified, %d mismatched.", m.Verified, m.Mismatched))
+	}
+	return console.Colorize("Mirror", fmt.Sprintf("Verified %d object(s).", m.Verified))
+}
+
+func (m mirrorVerifySummary) JSON() string {
+	m.Status = "success"
+	if m.Mismatched > 0 {
+		m.Status = "error"
+	}
+	m.Type = "verify-summary"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// verifyMirror lists the source and the target again after mirror and
+// checks that every source object mirror is responsible for, given the
+// filters of opts, is on the target with the same size and, when the
+// ETag of the target is an MD5 sum, the same content. Mismatches are
+// printed as found.
+func verifyMirror(ctx context.Context, srcURL, dstURL string, opts mirrorOptions) (mirrorVerifySummary, *probe.Error) {
+	var summary mirrorVerifySummary
+
+	// Source and target are compared as directories, like mirror does.
+	if sep := string(newClientURL(srcURL).Separator); !strings.HasSuffix(srcURL, sep) {
+		srcURL += sep
+	}
+	if sep := string(newClientURL(dstURL).Separator); !strings.HasSuffix(dstURL, sep) {
+		dstURL += sep
+	}
+	sourceAlias, sourceURL, _ := mustExpandAlias(srcURL)
+	targetAlias, targetURL, _ := mustExpandAlias(dstURL)
+	sourceClnt, err := newClientFromAlias(sourceAlias, sourceURL)
+	if err != nil {
+		return summary, err.Trace(srcURL)
+	}
+	targetClnt, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		return summary, err.Trace(dstURL)
+	}
+	if sourceAlias == "" {
+		if abs, e := filepath.Abs(sourceURL); e == nil {
+			sourceURL = abs
+		}
+	}
+
+	// The same listings as objectDifference, but the matching objects are
+	// returned too to be counted.
+	listOpts := ListOptions{Recursive: true, ShowDir: DirNone}
+	sourceCh := sourceClnt.List(ctx, listOpts)
+	if opts.pathMapping.isSet() {
+		if sourceCh, err = mapSourceContents(sourceClnt.GetURL().String(), sourceCh, opts.pathMapping); err != nil {
+			return summary, err.Trace(srcURL)
+		}
+	}
+	targetCh := targetClnt.List(ctx, listOpts)
+	for diffMsg := range difference(sourceClnt.GetURL().String(), sourceCh, targetClnt.GetURL().String(), targetCh, false, true, compareChecksum) {
+		if diffMsg.Error != nil {
+			return summary, diffMsg.Error.Trace(srcURL, dstURL)
+		}
+		if diffMsg.Diff == differInSecond || isMirrorExcluded(opts, sourceURL, targetURL, diffMsg) {
+			continue
+		}
+		if src := diffMsg.firstContent; src != nil && (isOlder(src.Time, opts.olderThan) || isNewer(src.Time, opts.newerThan)) {
+			continue
+		}
+		msg := mirrorVerifyMessage{
+			Source: diffMsg.FirstURL,
+			Target: diffMsg.SecondURL,
+		}
+		if diffMsg.firstContent != nil {
+			msg.SourceSize = diffMsg.firstContent.Size
+		}
+		if diffMsg.secondContent != nil {
+			msg.TargetSize = diffMsg.secondContent.Size
+		}
+		switch diffMsg.Diff {
+		case differInNone, differInMetadata, differInAASourceMTime:
+			summary.Verified++
+			continue
+		case differInFirst:
+			msg.Reason = "missing"
+			msg.Target = urlJoinPath(targetURL, mirrorSourceSuffix(sourceURL, diffMsg))
+		case differInSize:
+			msg.Reason = "size"
+		case differInChecksum:
+			msg.Reason = "checksum"
+		default:
+			msg.Reason = "type"
+		}
+		summary.Mismatched++
+		printMsg(msg)
+	}
+	return summary, nil
+}
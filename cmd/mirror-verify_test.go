@@ -0,0 +1,77 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestVerifyMirror(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	t.Setenv(mcEnvHostPrefix+"verifytest", server.AliasURL())
+
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"same":        "hello world",
+		"sub/same":    "hello world",
+		"size":        "hello world",
+		"checksum":    "hello world",
+		"multipart":   "hello world",
+		"missing":     "hello world",
+		"skip.tmp":    "hello world",
+		"sub/missing": "hello world",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if e := os.MkdirAll(filepath.Dir(path), 0o700); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(data), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	server.PutObject("bucket", "backup/same", []byte("hello world"))
+	server.PutObject("bucket", "backup/sub/same", []byte("hello world"))
+	server.PutObject("bucket", "backup/size", []byte("hello"))
+	server.PutObject("bucket", "backup/checksum", []byte("hello there"))
+	// Multipart ETags are not MD5 sums, only their size is compared.
+	server.PutMultipartObject("bucket", "backup/multipart", []byte("hello "), []byte("there"))
+	server.PutObject("bucket", "backup/extra", []byte("only on the target"))
+
+	summary, err := verifyMirror(context.Background(), dir, "verifytest/bucket/backup", mirrorOptions{excludeOptions: []string{"*.tmp"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Verified != 3 || summary.Mismatched != 4 {
+		t.Fatalf("expected 3 verified and 4 mismatched objects, got %+v", summary)
+	}
+
+	summary, err = verifyMirror(context.Background(), filepath.Join(dir, "sub"), "verifytest/bucket/backup/sub", mirrorOptions{excludeOptions: []string{"missing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Verified != 1 || summary.Mismatched != 0 {
+		t.Fatalf("expected 1 verified object, got %+v", summary)
+	}
+}
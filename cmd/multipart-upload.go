@@ -0,0 +1,261 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/v2/console"
+)
+
+// abortUploadTimeout bounds the abort of the multipart uploads of a
+// canceled transfer, whose own context can no longer be used.
+const abortUploadTimeout = 30 * time.Second
+
+// uploadIDRecorder keeps the IDs of the multipart uploads started by the
+// requests of a context. minio-go aborts the multipart upload of a failed
+// PutObject with the context of the upload, which does nothing once that
+// context is canceled, so the caller aborts them instead. The ETags of
+// the uploaded parts are kept as well, to verify the ETag of the object.
+type uploadIDRecorder struct {
+	mu        sync.Mutex
+	uploadIDs []string
+	partETags map[int]string
+}
+
+type uploadIDRecorderKey struct{}
+
+// withUploadIDRecorder returns a context recording the IDs of the
+// multipart uploads started by its requests.
+func withUploadIDRecorder(ctx context.Context) (context.Context, *uploadIDRecorder) {
+	r := &uploadIDRecorder{}
+	return context.WithValue(ctx, uploadIDRecorderKey{}, r), r
+}
+
+// record reads the upload ID of the response to a new multipart upload,
+// and puts the body back for minio-go.
+func (r *uploadIDRecorder) record(resp *http.Response) error {
+	body, e := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if e != nil {
+		return e
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if xml.Unmarshal(body, &result) != nil || result.UploadID == "" {
+		return nil
+	}
+	r.mu.Lock()
+	r.uploadIDs = append(r.uploadIDs, result.UploadID)
+	r.mu.Unlock()
+	return nil
+}
+
+// recordPart keeps the ETag of an uploaded part, that of its last
+// upload if the part was uploaded again.
+func (r *uploadIDRecorder) recordPart(req *http.Request, resp *http.Response) {
+	n, e := strconv.Atoi(req.URL.Query().Get("partNumber"))
+	if e != nil {
+		return
+	}
+	r.mu.Lock()
+	if r.partETags == nil {
+		r.partETags = map[int]string{}
+	}
+	r.partETags[n] = resp.Header.Get("ETag")
+	r.mu.Unlock()
+}
+
+func (r *uploadIDRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.uploadIDs...)
+}
+
+// parts returns the uploaded parts, in order.
+func (r *uploadIDRecorder) parts() []minio.CompletePart {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	parts := make([]minio.CompletePart, 0, len(r.partETags))
+	for n, etag := range r.partETags {
+		parts = append(parts, minio.CompletePart{PartNumber: n, ETag: etag})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts
+}
+
+// uploadIDTransport records the IDs of the new multipart uploads, and the
+// ETags of their parts, in the uploadIDRecorder of their request context,
+// if any.
+type uploadIDTransport struct {
+	transport http.RoundTripper
+}
+
+func newUploadIDTransport(transport http.RoundTripper) http.RoundTripper {
+	return &uploadIDTransport{transport: transport}
+}
+
+func (t *uploadIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	r, ok := req.Context().Value(uploadIDRecorderKey{}).(*uploadIDRecorder)
+	if !ok {
+		return resp, nil
+	}
+	query := req.URL.Query()
+	switch {
+	case req.Method == http.MethodPost && query.Has("uploads"):
+		if e := r.record(resp); e != nil {
+			resp.Body.Close()
+			return nil, e
+		}
+	case req.Method == http.MethodPut && query.Has("uploadId") && query.Has("partNumber") && req.Header.Get("X-Amz-Copy-Source") == "":
+		r.recordPart(req, resp)
+	}
+	return resp, nil
+}
+
+// abortUploads aborts the multipart uploads of object left behind by a
+// failed or canceled upload.
+func (c *S3Client) abortUploads(bucket, object string, uploadIDs []string) {
+	if len(uploadIDs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), abortUploadTimeout)
+	defer cancel()
+	for _, uploadID := range uploadIDs {
+		e := minio.Core{Client: c.api}.AbortMultipartUpload(ctx, bucket, object, uploadID)
+		if e != nil && minio.ToErrorResponse(e).Code != "NoSuchUpload" && globalDebug {
+			console.Debugln(fmt.Sprintf("Unable to abort the multipart upload %s of `%s/%s`: %v", uploadID, bucket, object, e))
+		}
+	}
+}
+
+// minPutPartSize is the default part size of minio-go, the smallest
+// object it uploads in parts.
+const minPutPartSize = 16 << 20
+
+// isMultipartPut returns true if minio-go uploads an object of size bytes
+// in parts.
+func isMultipartPut(size int64, opts minio.PutObjectOptions) bool {
+	partSize := int64(opts.PartSize)
+	if partSize == 0 {
+		partSize = minPutPartSize
+	}
+	return size >= partSize && !opts.DisableMultipart
+}
+
+// streamReadAtParts has minio-go upload the parts of reader, of size
+// bytes, as those of a stream if it would read them in parallel from an
+// io.ReaderAt instead: the workers of that uploader are left blocked
+// forever when the upload fails, or is canceled, with parts in flight.
+// The parts of a stream are read in turn, so that they can be hashed as
+// they are read, and uploaded opts.NumThreads at a time by workers that
+// minio-go waits for.
+func streamReadAtParts(reader io.Reader, size int64, opts *minio.PutObjectOptions) io.Reader {
+	if !isMultipartPut(size, *opts) || opts.SendContentMd5 || (opts.ConcurrentStreamParts && opts.NumThreads > 1) {
+		return reader
+	}
+	if _, ok := reader.(*minio.Object); ok {
+		return reader
+	}
+	if _, ok := reader.(*os.File); ok && !isReadAt(reader) {
+		// Standard input is no io.ReaderAt, whatever its type.
+		return reader
+	}
+	if _, ok := reader.(io.ReaderAt); !ok {
+		return reader
+	}
+	totalParts, partSize, _, e := minio.OptimalPartInfo(size, opts.PartSize)
+	if e != nil {
+		return reader
+	}
+	threads := opts.NumThreads
+	if threads == 0 {
+		threads = 4
+	}
+	if threads > uint(totalParts) {
+		threads = uint(totalParts)
+	}
+	if threads == 1 {
+		return struct{ io.Reader }{reader}
+	}
+	// The part size of a stream is the one of an object of unknown size.
+	opts.PartSize = uint64(partSize)
+	opts.NumThreads = threads
+	opts.ConcurrentStreamParts = true
+	return reader
+}
+
+// multipartETag returns the ETag given by S3 to an object uploaded in
+// parts with the MD5 sums sums: the MD5 sum of the sums followed by
+// their number.
+func multipartETag(sums [][]byte) string {
+	h := md5.New()
+	for _, sum := range sums {
+		h.Write(sum)
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(sums))
+}
+
+// verifyMultipartETag checks etag, returned by the completion of a
+// multipart upload of parts, against the MD5 sums of the parts as they
+// were read, to catch the parts corrupted on their way or assembled in
+// the wrong order. Servers whose ETags are not MD5 sums, such as those
+// of encrypted objects, are detected by none of the parts having its MD5
+// sum as ETag, and are not checked.
+func verifyMultipartETag(bucket, object, etag string, parts []minio.CompletePart, sums [][]byte) error {
+	etag = strings.Trim(etag, `"`)
+	if !isMultipartETag(etag) || len(parts) != len(sums) {
+		return nil
+	}
+	md5ETags := false
+	for i, part := range parts {
+		if sums[i] == nil {
+			return nil
+		}
+		if strings.EqualFold(strings.Trim(part.ETag, `"`), hex.EncodeToString(sums[i])) {
+			md5ETags = true
+		}
+	}
+	if !md5ETags {
+		return nil
+	}
+	if expected := multipartETag(sums); !strings.EqualFold(etag, expected) {
+		return ChecksumMismatch{Object: bucket + "/" + object, Expected: expected, Got: etag}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/minio-go/v7"
+)
+
+// cancelingUploadHandler holds the part uploads until their client goes
+// away, and records the aborted multipart uploads.
+type cancelingUploadHandler struct {
+	*miniotest.Server
+	partStarted chan struct{}
+	once        sync.Once
+
+	abortMu sync.Mutex
+	aborted []string
+}
+
+func (h *cancelingUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case isPartUpload(r):
+		h.once.Do(func() { close(h.partStarted) })
+		// The body is never sent in full, reading it ends with the
+		// connection of the client.
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	case r.Method == http.MethodDelete && r.URL.Query().Has("uploadId"):
+		h.abortMu.Lock()
+		h.aborted = append(h.aborted, r.URL.Query().Get("uploadId"))
+		h.abortMu.Unlock()
+		h.Server.ServeHTTP(w, r)
+	default:
+		h.Server.ServeHTTP(w, r)
+	}
+}
+
+func TestPutCancelAbortsMultipartUpload(t *testing.T) {
+	data := bytes.Repeat([]byte{'a'}, 12<<20)
+	for name, reader := range map[string]func() io.Reader{
+		// Parts read at an offset by minio-go, streamed instead.
+		"readerAt": func() io.Reader { return bytes.NewReader(data) },
+		// Parts streamed by minio-go.
+		"reader": func() io.Reader { return struct{ io.Reader }{bytes.NewReader(data)} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := &cancelingUploadHandler{partStarted: make(chan struct{})}
+			server, front := newS3TestFrontServer(t, func(server *miniotest.Server) http.Handler {
+				handler.Server = server
+				return handler
+			})
+			baseline := runtime.NumGoroutine()
+
+			clnt := newS3TestClient(t, "s3test/bucket/object")
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				<-handler.partStarted
+				cancel()
+			}()
+
+			_, err := clnt.Put(ctx, reader(), int64(len(data)), nil, PutOptions{
+				multipartSize:    5 << 20,
+				multipartThreads: 2,
+			})
+			if err == nil || !errors.Is(err.ToGoError(), context.Canceled) {
+				t.Fatalf("expected %v, got %v", context.Canceled, err)
+			}
+
+			handler.abortMu.Lock()
+			aborted := handler.aborted
+			handler.abortMu.Unlock()
+			if uploads := server.Uploads("bucket"); len(aborted) != 1 || len(uploads) != 0 {
+				t.Fatalf("expected the multipart upload to be aborted, got %v and %v left", aborted, uploads)
+			}
+
+			// Nothing of the upload is left running once the connections
+			// of the client are gone.
+			front.CloseClientConnections()
+			deadline := time.Now().Add(5 * time.Second)
+			for runtime.NumGoroutine() > baseline {
+				if time.Now().After(deadline) {
+					buf := make([]byte, 1<<20)
+					t.Fatalf("expected at most %d goroutines, got %d:\n%s", baseline, runtime.NumGoroutine(), buf[:runtime.Stack(buf, true)])
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestPutObjectParts(t *testing.T) {
+	server := newS3TestServer(t)
+
+	data := make([]byte, 17<<20)
+	for i := range data {
+		data[i] = byte(i / 1000)
+	}
+	var progress progressCounter
+	size, err := newS3TestClient(t, "s3test/bucket/object").Put(context.Background(), bytes.NewReader(data), int64(len(data)), &progress, PutOptions{
+		multipartSize:    5 << 20,
+		multipartThreads: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	object, _ := server.Object("bucket", "object")
+	if size != int64(len(data)) || len(object.PartSizes) != 4 || !bytes.Equal(object.Data, data) {
+		t.Fatalf("expected %d bytes in 4 parts, got %d bytes in %d parts", len(data), size, len(object.PartSizes))
+	}
+	if n := atomic.LoadInt64(&progress.n); n != int64(len(data)) {
+		t.Fatalf("expected a progress of %d bytes, got %d", len(data), n)
+	}
+}
+
+// progressCounter counts the bytes reported to a progress reader.
+type progressCounter struct {
+	n int64
+}
+
+func (r *progressCounter) Read(p []byte) (int, error) {
+	atomic.AddInt64(&r.n, int64(len(p)))
+	return len(p), nil
+}
+
+func TestVerifyMultipartETag(t *testing.T) {
+	// The MD5 sums of "aaaaa", "bbbbb" and "ccc".
+	sums := make([][]byte, 3)
+	parts := make([]minio.CompletePart, 3)
+	for i, data := range []string{"aaaaa", "bbbbb", "ccc"} {
+		sum := md5.Sum([]byte(data))
+		sums[i] = sum[:]
+		parts[i] = minio.CompletePart{PartNumber: i + 1, ETag: fmt.Sprintf("\"%x\"", sums[i])}
+	}
+	if etag := multipartETag(sums); etag != "5221b8125ff31b2c22af573896655769-3" {
+		t.Fatalf("unexpected ETag %s", etag)
+	}
+	if err := verifyMultipartETag("bucket", "object", "\"5221b8125ff31b2c22af573896655769-3\"", parts, sums); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server received "xcc" as third part.
+	corrupted := append([]minio.CompletePart{}, parts...)
+	corrupted[2].ETag = "\"0417c940b9b4f30aae3d4d703bbae4c2\""
+	err := verifyMultipartETag("bucket", "object", "\"074ffe782edde1787bcacbfbdac76536-3\"", corrupted, sums)
+	var mismatch ChecksumMismatch
+	if !errors.As(err, &mismatch) || mismatch.Expected != "5221b8125ff31b2c22af573896655769-3" {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	// The server assembled the parts in another order.
+	reordered := [][]byte{sums[1], sums[0], sums[2]}
+	if err = verifyMultipartETag("bucket", "object", multipartETag(reordered), parts, sums); !errors.As(err, &mismatch) {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+
+	// ETags which are no MD5 sums, or parts read more than once, are not checked.
+	encrypted := []minio.CompletePart{{PartNumber: 1, ETag: "1"}, {PartNumber: 2, ETag: "2"}, {PartNumber: 3, ETag: "3"}}
+	if err = verifyMultipartETag("bucket", "object", "074ffe782edde1787bcacbfbdac76536-3", encrypted, sums); err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyMultipartETag("bucket", "object", "074ffe782edde1787bcacbfbdac76536", parts, sums); err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyMultipartETag("bucket", "object", "074ffe782edde1787bcacbfbdac76536-3", parts, [][]byte{sums[0], nil, sums[2]}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPutObjectPartsCorrupted(t *testing.T) {
+	data := bytes.Repeat([]byte{'a'}, 12<<20)
+	for name, reader := range map[string]func() io.Reader{
+		"readerAt": func() io.Reader { return bytes.NewReader(data) },
+		"reader":   func() io.Reader { return struct{ io.Reader }{bytes.NewReader(data)} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			// The second part is corrupted once it is uploaded.
+			server := newS3TestServer(t)
+			server.CorruptPart = func(key string, partNumber int) bool { return partNumber == 2 }
+
+			_, err := newS3TestClient(t, "s3test/bucket/object").Put(context.Background(), reader(), int64(len(data)), nil, PutOptions{
+				multipartSize:    5 << 20,
+				multipartThreads: 3,
+			})
+			var mismatch ChecksumMismatch
+			if err == nil || !errors.As(err.ToGoError(), &mismatch) {
+				t.Fatalf("expected a checksum mismatch, got %v", err)
+			}
+		})
+	}
+}
@@ -64,6 +64,10 @@ var (
 			Name:  "disable-multipart",
 			Usage: "disable multipart upload feature",
 		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the moves without copying or removing anything",
+		},
 	}
 )
 
@@ -87,6 +91,7 @@ FLAGS:
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT:      list of comma delimited prefixes
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 EXAMPLES:
   01. Move a list of objects from local file system to Amazon S3 cloud storage.
@@ -137,6 +142,12 @@ EXAMPLES:
 
   16. Move a text file to an object storage and disable multipart upload feature.
       {{.Prompt}} {{.HelpName}} --disable-multipart myobject.txt play/mybucket
+
+  17. Rename an object within a bucket, with a server side copy followed by the removal of the original.
+      {{.Prompt}} {{.HelpName}} play/mybucket/old.txt play/mybucket/new.txt
+
+  18. Show which objects would be moved to a new prefix, without moving them.
+      {{.Prompt}} {{.HelpName}} --recursive --dry-run play/mybucket/old/ play/mybucket/new/
 `,
 }
 
@@ -172,6 +183,7 @@ func (rm *removeManager) add(ctx context.Context, targetAlias, targetURL string)
 	if clientInfo == nil {
 		client, pErr := newClientFromAlias(targetAlias, targetURL)
 		if pErr != nil {
+			rm.removeMapMutex.Unlock()
 			errorIf(pErr.Trace(targetURL), "Invalid argument `"+targetURL+"`.")
 			return
 		}
@@ -254,6 +266,9 @@ func mainMove(cliCtx *cli.Context) error {
 
 	var session *sessionV8
 
+	if cliCtx.Bool("dry-run") && cliCtx.Bool("continue") {
+		fatalIf(errInvalidArgument(), "--dry-run cannot be used with --continue.")
+	}
 	if cliCtx.Bool("continue") {
 		sessionID := getHash("mv", cliCtx.Args())
 		if isSessionExists(sessionID) {
@@ -0,0 +1,149 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+// moveTestServer starts a server with the alias "mvtest" holding the
+// given objects in "bucket", and replaces rmManager for the test.
+func moveTestServer(t *testing.T, objects map[string]string) *miniotest.Server {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	t.Cleanup(server.Close)
+	server.MakeBucket("bucket")
+	for key, data := range objects {
+		server.PutObject("bucket", key, []byte(data))
+	}
+	t.Setenv(mcEnvHostPrefix+"mvtest", server.AliasURL())
+
+	defer func(rm *removeManager) { t.Cleanup(func() { rmManager = rm }) }(rmManager)
+	rmManager = &removeManager{removeMap: make(map[string]*removeClientInfo)}
+	return server
+}
+
+// moveURLs moves source to target the way mv does, returning the result
+// of each copy once the removals of the sources are done.
+func moveURLs(t *testing.T, source, target string, recursive bool) (moved []URLs) {
+	ctx := context.Background()
+	for cpURLs := range prepareCopyURLs(ctx, prepareCopyURLsOpts{
+		sourceURLs:  []string{source},
+		targetURL:   target,
+		isRecursive: recursive,
+	}) {
+		if cpURLs.Error != nil {
+			t.Fatal(cpURLs.Error)
+		}
+		moved = append(moved, doCopy(ctx, doCopyOpts{cpURLs: cpURLs, pg: newAccounter(0), isMvCmd: true}))
+	}
+	rmManager.close()
+	return moved
+}
+
+func TestMoveObject(t *testing.T) {
+	server := moveTestServer(t, map[string]string{"old.txt": "hello"})
+
+	for _, urls := range moveURLs(t, "mvtest/bucket/old.txt", "mvtest/bucket/new.txt", false) {
+		if urls.Error != nil {
+			t.Fatal(urls.Error)
+		}
+	}
+	if _, ok := server.Object("bucket", "old.txt"); ok {
+		t.Fatal("expected old.txt to be removed")
+	}
+	if o, ok := server.Object("bucket", "new.txt"); !ok || string(o.Data) != "hello" {
+		t.Fatal("expected new.txt to hold the moved object")
+	}
+	for _, r := range server.Requests() {
+		if r.Method == http.MethodGet && r.Key == "old.txt" {
+			t.Fatal("expected the object to be copied on the server")
+		}
+	}
+}
+
+func TestMoveRecursive(t *testing.T) {
+	server := moveTestServer(t, map[string]string{
+		"src/a.txt":     "a",
+		"src/b.txt":     "b",
+		"src/dir/c.txt": "c",
+		"other.txt":     "other",
+	})
+	server.CopyError = func(key string) string {
+		if key == "dst/b.txt" {
+			return "AccessDenied"
+		}
+		return ""
+	}
+
+	var failed []string
+	for _, urls := range moveURLs(t, "mvtest/bucket/src/", "mvtest/bucket/dst/", true) {
+		if urls.Error != nil {
+			failed = append(failed, urls.SourceContent.URL.Path)
+		}
+	}
+	if len(failed) != 1 || !strings.HasSuffix(failed[0], "src/b.txt") {
+		t.Fatalf("expected only the copy of src/b.txt to fail, got %v", failed)
+	}
+
+	for i, testCase := range []struct {
+		key    string
+		exists bool
+	}{
+		{"src/a.txt", false},
+		{"src/dir/c.txt", false},
+		{"dst/a.txt", true},
+		{"dst/dir/c.txt", true},
+		// A source is kept when its copy fails.
+		{"src/b.txt", true},
+		{"dst/b.txt", false},
+		{"other.txt", true},
+	} {
+		if _, ok := server.Object("bucket", testCase.key); ok != testCase.exists {
+			t.Fatalf("Test %d: expected %s to exist: %v, got %v", i+1, testCase.key, testCase.exists, ok)
+		}
+	}
+}
+
+func TestMoveDryRun(t *testing.T) {
+	server := moveTestServer(t, map[string]string{"src/a.txt": "a", "src/b.txt": "b"})
+
+	for cpURLs := range prepareCopyURLs(context.Background(), prepareCopyURLsOpts{
+		sourceURLs:  []string{"mvtest/bucket/src/"},
+		targetURL:   "mvtest/bucket/dst/",
+		isRecursive: true,
+	}) {
+		if urls := doMoveDryRun(cpURLs, newAccounter(0)); urls.Error != nil {
+			t.Fatal(urls.Error)
+		}
+	}
+	rmManager.close()
+	for _, key := range []string{"src/a.txt", "src/b.txt"} {
+		if _, ok := server.Object("bucket", key); !ok {
+			t.Fatalf("expected %s to be kept", key)
+		}
+	}
+	if server.RequestCount(http.MethodPut) != 0 || server.RequestCount(http.MethodDelete) != 0 {
+		t.Fatal("expected a dry run to modify nothing")
+	}
+}
@@ -25,7 +25,6 @@ import (
 
 	json "github.com/minio/colorjson"
 
-	humanize "github.com/dustin/go-humanize"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
 )
@@ -79,9 +78,9 @@ type odMessage struct {
 }
 
 func (o odMessage) String() string {
-	cleanSize := humanize.IBytes(uint64(o.TotalSize))
+	cleanSize := formatSize(o.TotalSize)
 	elapsed := time.Duration(o.Elapsed) * time.Millisecond
-	speed := humanize.IBytes(uint64(float64(o.TotalSize) / elapsed.Seconds()))
+	speed := formatSize(int64(float64(o.TotalSize) / elapsed.Seconds()))
 	if o.Type == "S3toFS" && o.Parts == 0 {
 		return fmt.Sprintf("Transferred: %s, Full file, Time: %s, Speed: %s/s", cleanSize, elapsed, speed)
 	}
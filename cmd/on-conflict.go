@@ -0,0 +1,299 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// conflictPolicy is what --on-conflict does with an object or a file
+// whose target already exists.
+type conflictPolicy string
+
+const (
+	// conflictOverwrite replaces the target, the default.
+	conflictOverwrite conflictPolicy = "overwrite"
+	// conflictSkip leaves the target alone and goes on.
+	conflictSkip conflictPolicy = "skip"
+	// conflictRename writes to a new target named with a numeric suffix.
+	conflictRename conflictPolicy = "rename"
+	// conflictError fails the item, the others are still transferred.
+	conflictError conflictPolicy = "error"
+)
+
+var onConflictFlag = cli.StringFlag{
+	Name:  "on-conflict",
+	Usage: "when the target exists: overwrite it, skip the item, rename the new target with a numeric suffix, or error",
+	Value: string(conflictOverwrite),
+}
+
+// parseConflictPolicy returns the policy of --on-conflict.
+func parseConflictPolicy(cliCtx *cli.Context) conflictPolicy {
+	policy := conflictPolicy(strings.ToLower(cliCtx.String("on-conflict")))
+	switch policy {
+	case "":
+		return conflictOverwrite
+	case conflictOverwrite, conflictSkip, conflictRename, conflictError:
+		return policy
+	}
+	fatalIf(errInvalidArgument().Trace(string(policy)), "--on-conflict should be one of overwrite, skip, rename or error.")
+	return policy
+}
+
+// conflictResolver applies a conflict policy to the items of a transfer.
+// Its checks for existing targets batch a listing per target folder,
+// rather than a HEAD per item, when batch is set for a run of many
+// items. The targets written by the run are remembered so that renamed
+// targets never replace them.
+type conflictResolver struct {
+	policy  conflictPolicy
+	batch   bool
+	names   map[string]map[string]bool // target folder -> existing names
+	written map[string]bool
+	summary conflictSummaryMessage
+}
+
+func newConflictResolver(policy conflictPolicy, batch bool) *conflictResolver {
+	return &conflictResolver{
+		policy:  policy,
+		batch:   batch,
+		names:   map[string]map[string]bool{},
+		written: map[string]bool{},
+	}
+}
+
+// resolve returns the action for the item urls, "" when its target does
+// not exist, along with the item to transfer, whose target is changed by
+// conflictRename.
+func (r *conflictResolver) resolve(ctx context.Context, urls URLs) (conflictPolicy, URLs, *probe.Error) {
+	target := urls.TargetContent.URL
+	exists, err := r.exists(ctx, urls.TargetAlias, target)
+	if err != nil {
+		return r.policy, urls, err.Trace(target.String())
+	}
+	if !exists {
+		r.written[r.key(urls.TargetAlias, target)] = true
+		return "", urls, nil
+	}
+
+	switch r.policy {
+	case conflictOverwrite:
+		r.summary.Overwritten++
+	case conflictSkip:
+		r.summary.Skipped++
+	case conflictError:
+		r.summary.Failed++
+	case conflictRename:
+		for i := 1; ; i++ {
+			renamed := target.Clone()
+			renamed.Path = renamePath(target.Path, string(target.Separator), i)
+			exists, err = r.exists(ctx, urls.TargetAlias, renamed)
+			if err != nil {
+				return r.policy, urls, err.Trace(renamed.String())
+			}
+			if !exists {
+				content := *urls.TargetContent
+				content.URL = renamed
+				urls.TargetContent = &content
+				r.written[r.key(urls.TargetAlias, renamed)] = true
+				break
+			}
+		}
+		r.summary.Renamed++
+	}
+	return r.policy, urls, nil
+}
+
+// renamePath returns p with the suffix -n before its extension, such as
+// dir/report-2.csv for dir/report.csv.
+func renamePath(p, separator string, n int) string {
+	dir, name := "", p
+	if i := strings.LastIndex(p, separator); i >= 0 {
+		dir, name = p[:i+1], p[i+1:]
+	}
+	ext := path.Ext(name)
+	if ext == name {
+		ext = "" // a dot file such as .env has no extension.
+	}
+	return dir + strings.TrimSuffix(name, ext) + "-" + strconv.Itoa(n) + ext
+}
+
+func (r *conflictResolver) key(alias string, u ClientURL) string {
+	return alias + "|" + u.String()
+}
+
+// exists returns true if the target u exists, or was written by the run.
+// Folders at the target path are not a conflict.
+func (r *conflictResolver) exists(ctx context.Context, alias string, u ClientURL) (bool, *probe.Error) {
+	if r.written[r.key(alias, u)] {
+		return true, nil
+	}
+	if !r.batch {
+		clnt, err := newClientFromAlias(alias, u.String())
+		if err != nil {
+			return false, err
+		}
+		st, err := clnt.Stat(ctx, StatOptions{})
+		switch err.ToGoError().(type) {
+		case nil:
+			return !st.Type.IsDir(), nil
+		case ObjectMissing, PathNotFound, BucketDoesNotExist:
+			return false, nil
+		}
+		return false, err
+	}
+
+	separator := string(u.Separator)
+	i := strings.LastIndex(u.Path, separator)
+	dir := u.Clone()
+	dir.Path = u.Path[:i+1]
+	names, ok := r.names[r.key(alias, dir)]
+	if !ok {
+		var err *probe.Error
+		if names, err = listNames(ctx, alias, dir); err != nil {
+			return false, err
+		}
+		r.names[r.key(alias, dir)] = names
+	}
+	return names[u.Path[i+1:]], nil
+}
+
+// listNames returns the names of the objects or files of the folder dir,
+// with a single listing.
+func listNames(ctx context.Context, alias string, dir ClientURL) (map[string]bool, *probe.Error) {
+	names := map[string]bool{}
+	clnt, err := newClientFromAlias(alias, dir.String())
+	if err != nil {
+		return nil, err
+	}
+	for content := range clnt.List(ctx, ListOptions{ShowDir: DirNone}) {
+		if content.Err != nil {
+			switch content.Err.ToGoError().(type) {
+			case ObjectMissing, PathNotFound, BucketDoesNotExist:
+				continue
+			}
+			return nil, content.Err
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		p := content.URL.Path
+		names[p[strings.LastIndex(p, string(content.URL.Separator))+1:]] = true
+	}
+	return names, nil
+}
+
+// conflictMessage reports the action taken for an item whose target
+// exists.
+type conflictMessage struct {
+	Status  string `json:"status"`
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Action  string `json:"action"`
+	Renamed string `json:"renamed,omitempty"`
+}
+
+func (m conflictMessage) String() string {
+	switch conflictPolicy(m.Action) {
+	case conflictSkip:
+		return console.Colorize("Conflict", fmt.Sprintf("Skipped `%s`, `%s` exists.", m.Source, m.Target))
+	case conflictRename:
+		return console.Colorize("Conflict", fmt.Sprintf("`%s` exists, writing `%s` to `%s`.", m.Target, m.Source, m.Renamed))
+	case conflictError:
+		return console.Colorize("ConflictError", fmt.Sprintf("Unable to write `%s`, `%s` exists.", m.Source, m.Target))
+	}
+	return console.Colorize("Conflict", fmt.Sprintf("Overwriting `%s` with `%s`.", m.Target, m.Source))
+}
+
+func (m conflictMessage) JSON() string {
+	m.Status = "success"
+	if conflictPolicy(m.Action) == conflictError {
+		m.Status = "error"
+	}
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// printConflict reports the action taken for an item whose target
+// exists. Like the messages of the copied items, it is not printed over
+// a progress bar, except for errors.
+func printConflict(pg ProgressReader, action conflictPolicy, original, urls URLs, summaryOnly bool) {
+	msg := conflictMessage{
+		Source: urls.SourceContent.URL.String(),
+		Target: original.TargetContent.URL.String(),
+		Action: string(action),
+	}
+	if action == conflictRename {
+		msg.Renamed = urls.TargetContent.URL.String()
+	}
+	if _, ok := pg.(*progressBar); ok || summaryOnly {
+		if action != conflictError {
+			return
+		}
+		if !globalQuiet && !globalJSON {
+			eraseStatusLine()
+		}
+	}
+	printMsg(msg)
+}
+
+// conflictSummaryMessage counts the items whose target existed, by the
+// action taken.
+type conflictSummaryMessage struct {
+	Status      string `json:"status"`
+	Policy      string `json:"policy"`
+	Overwritten int64  `json:"overwritten"`
+	Skipped     int64  `json:"skipped"`
+	Renamed     int64  `json:"renamed"`
+	Failed      int64  `json:"failed"`
+}
+
+func (m conflictSummaryMessage) String() string {
+	return fmt.Sprintf("Existing targets: %d overwritten, %d skipped, %d renamed, %d failed.", m.Overwritten, m.Skipped, m.Renamed, m.Failed)
+}
+
+func (m conflictSummaryMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// summaryMessage returns the conflict counts of the run.
+func (r *conflictResolver) summaryMessage() conflictSummaryMessage {
+	m := r.summary
+	m.Policy = string(r.policy)
+	return m
+}
+
+// setConflictColors sets the colors of the conflict messages.
+func setConflictColors() {
+	console.SetColor("Conflict", color.New(color.FgYellow))
+	console.SetColor("ConflictError", color.New(color.FgRed, color.Bold))
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestRenamePath(t *testing.T) {
+	testCases := []struct {
+		path, separator string
+		n               int
+		expected        string
+	}{
+		{"/bucket/dir/report.csv", "/", 1, "/bucket/dir/report-1.csv"},
+		{"/bucket/dir/archive.tar.gz", "/", 2, "/bucket/dir/archive.tar-2.gz"},
+		{"/bucket/README", "/", 3, "/bucket/README-3"},
+		{"/bucket/dir.d/.env", "/", 1, "/bucket/dir.d/.env-1"},
+		{`C:\data\a.txt`, `\`, 1, `C:\data\a-1.txt`},
+	}
+	for i, testCase := range testCases {
+		if got := renamePath(testCase.path, testCase.separator, testCase.n); got != testCase.expected {
+			t.Fatalf("Test %d: expected %s, got %s", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func countListings(server *miniotest.Server) (n int) {
+	for _, r := range server.Requests() {
+		if r.Method == http.MethodGet && r.Query.Has("list-type") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestConflictResolverPut(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.MakeBucket("bucket")
+	server.PutObject("bucket", "dir/a.txt", []byte("a"))
+	server.PutObject("bucket", "dir/a-1.txt", []byte("a"))
+	server.PutObject("bucket", "dir/sub/c.txt", []byte("c"))
+	t.Setenv(mcEnvHostPrefix+"conflicttest", server.AliasURL())
+
+	putURLs := func(name string) URLs {
+		alias, targetURL, _ := mustExpandAlias("conflicttest/bucket/dir/" + name)
+		return URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(filepath.Join("src", name))},
+			TargetAlias:   alias,
+			TargetContent: &ClientContent{URL: *newClientURL(targetURL)},
+		}
+	}
+	for _, batch := range []bool{true, false} {
+		for _, testCase := range []struct {
+			policy  conflictPolicy
+			renamed string
+		}{
+			{policy: conflictOverwrite},
+			{policy: conflictSkip},
+			{policy: conflictError},
+			{policy: conflictRename, renamed: "/bucket/dir/a-2.txt"},
+		} {
+			r := newConflictResolver(testCase.policy, batch)
+			heads, listings := server.RequestCount(http.MethodHead), countListings(server)
+
+			// b.txt and sub are not conflicts, a folder is not a conflict.
+			for _, name := range []string{"b.txt", "sub"} {
+				action, urls, err := r.resolve(context.Background(), putURLs(name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if action != "" || urls.TargetContent.URL.Path != "/bucket/dir/"+name {
+					t.Fatalf("%s batch=%v: expected no conflict for %s, got %q to %s", testCase.policy, batch, name, action, urls.TargetContent.URL.Path)
+				}
+			}
+			action, urls, err := r.resolve(context.Background(), putURLs("a.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if action != testCase.policy {
+				t.Fatalf("%s batch=%v: expected action %s, got %s", testCase.policy, batch, testCase.policy, action)
+			}
+			expected := testCase.renamed
+			if expected == "" {
+				expected = "/bucket/dir/a.txt"
+			}
+			if urls.TargetContent.URL.Path != expected {
+				t.Fatalf("%s batch=%v: expected target %s, got %s", testCase.policy, batch, expected, urls.TargetContent.URL.Path)
+			}
+
+			// The target b.txt written by the run is a conflict from now on.
+			if action, _, _ := r.resolve(context.Background(), putURLs("b.txt")); action != testCase.policy {
+				t.Fatalf("%s batch=%v: expected a target of the run to conflict, got %q", testCase.policy, batch, action)
+			}
+			summary := r.summaryMessage()
+			if n := summary.Overwritten + summary.Skipped + summary.Renamed + summary.Failed; n != 2 || summary.Policy != string(testCase.policy) {
+				t.Fatalf("%s batch=%v: unexpected summary %+v", testCase.policy, batch, summary)
+			}
+			if batch {
+				if n := server.RequestCount(http.MethodHead) - heads; n != 0 {
+					t.Fatalf("%s: expected no HEAD requests when batching, got %d", testCase.policy, n)
+				}
+				if n := countListings(server) - listings; n != 1 {
+					t.Fatalf("%s: expected a single listing, got %d", testCase.policy, n)
+				}
+			}
+		}
+	}
+}
+
+func TestConflictResolverGet(t *testing.T) {
+	initTestConfig(t)
+	dir := t.TempDir()
+	writeTestTree(t, dir, map[string]string{"a.txt": "a", "a-1.txt": "a", "a-2.txt": "a"})
+	getURLs := func(name string) URLs {
+		return URLs{
+			SourceContent: &ClientContent{URL: *newClientURL("conflicttest/bucket/" + name)},
+			TargetContent: &ClientContent{URL: *newClientURL(filepath.Join(dir, name))},
+		}
+	}
+	for _, batch := range []bool{true, false} {
+		r := newConflictResolver(conflictRename, batch)
+		action, urls, err := r.resolve(context.Background(), getURLs("a.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if action != conflictRename || urls.TargetContent.URL.Path != filepath.Join(dir, "a-3.txt") {
+			t.Fatalf("batch=%v: expected a.txt to be renamed to a-3.txt, got %q to %s", batch, action, urls.TargetContent.URL.Path)
+		}
+		if action, _, _ := r.resolve(context.Background(), getURLs("b.txt")); action != "" {
+			t.Fatalf("batch=%v: expected no conflict for b.txt, got %q", batch, action)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "github.com/minio/mc/pkg/probe"
+
+// A multipart upload has at most maxPartNumber parts, so that a part
+// size set by --part-size bounds the size of the objects uploaded with
+// it. The uploads of larger objects are failed before they start, or
+// their part size raised with --auto-part-size.
+
+// minPartSizeFor returns the smallest part size, a multiple of 1MiB,
+// uploading size bytes in at most maxPartNumber parts.
+func minPartSizeFor(size int64) uint64 {
+	const mib = 1 << 20
+	perPart := (size + maxPartNumber - 1) / maxPartNumber
+	return uint64((perPart + mib - 1) / mib * mib)
+}
+
+// fitPartSize returns the part size of the upload of size bytes from
+// source in parts of partSize, 0 letting the client choose. A part size
+// too small to upload size bytes in maxPartNumber parts is raised to
+// the smallest which is large enough with auto, and an error otherwise.
+// Sources of unknown size, a negative size, are not checked.
+func fitPartSize(source string, size int64, partSize uint64, auto bool) (uint64, *probe.Error) {
+	if partSize == 0 || size < 0 || uint64(size) <= partSize*maxPartNumber {
+		return partSize, nil
+	}
+	minSize := minPartSizeFor(size)
+	if !auto {
+		return 0, errPartSizeTooSmall(source, size, partSize, minSize)
+	}
+	return minSize, nil
+}
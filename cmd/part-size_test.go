@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFitPartSize(t *testing.T) {
+	const mib = 1 << 20
+	for i, testCase := range []struct {
+		size     int64
+		partSize uint64
+		auto     bool
+		expected uint64
+		err      bool
+	}{
+		{16 * mib * maxPartNumber, 16 * mib, false, 16 * mib, false},
+		{16*mib*maxPartNumber + 1, 16 * mib, false, 0, true},
+		{16*mib*maxPartNumber + 1, 16 * mib, true, 17 * mib, false},
+		{500 << 30, 16 * mib, true, 52 * mib, false},
+		{500 << 30, 64 * mib, true, 64 * mib, false},
+		{1 << 40, 0, false, 0, false},
+		{-1, 5 * mib, false, 5 * mib, false},
+	} {
+		got, err := fitPartSize("disk.img", testCase.size, testCase.partSize, testCase.auto)
+		if testCase.err {
+			if err == nil || !strings.Contains(err.ToGoError().Error(), "use a --part-size of at least 17 MiB or --auto-part-size") {
+				t.Fatalf("Test %d: expected the minimum part size in the error, got %v", i+1, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if got != testCase.expected {
+			t.Fatalf("Test %d: expected a part size of %d, got %d", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestPartSizeTooSmall(t *testing.T) {
+	server := newS3TestServer(t)
+	const partSize = 5 << 20
+
+	// A sparse file one byte over the size of 10000 parts.
+	source := filepath.Join(t.TempDir(), "disk.img")
+	f, e := os.Create(source)
+	if e != nil {
+		t.Fatal(e)
+	}
+	size := int64(partSize*maxPartNumber + 1)
+	if e = f.Truncate(size); e != nil {
+		t.Fatal(e)
+	}
+	f.Close()
+
+	alias, targetURL, _ := mustExpandAlias("s3test/bucket/disk.img")
+	urls := uploadSourceToTargetURL(context.Background(), uploadSourceToTargetURLOpts{
+		urls: URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(source), Size: size},
+			TargetAlias:   alias,
+			TargetContent: &ClientContent{URL: *newClientURL(targetURL)},
+		},
+		progress:      newAccounter(0),
+		multipartSize: "5MiB",
+	})
+	if _, ok := urls.Error.ToGoError().(partSizeTooSmallErr); !ok {
+		t.Fatalf("expected a part size error, got %v", urls.Error)
+	}
+	if !strings.Contains(urls.Error.ToGoError().Error(), "needs more than 10000 parts of 5.0 MiB, use a --part-size of at least 6.0 MiB") {
+		t.Fatalf("unexpected error %v", urls.Error)
+	}
+	if requests := server.Requests(); len(requests) != 0 {
+		t.Fatalf("expected no upload to start, got %d requests", len(requests))
+	}
+}
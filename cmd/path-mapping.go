@@ -0,0 +1,151 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"golang.org/x/text/unicode/norm"
+)
+
+// pathMappingFlags rename the files of a folder upload.
+var pathMappingFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "flatten",
+		Usage: "upload the files of the folder under their base names only, two files of the same name are an error",
+	},
+	cli.IntFlag{
+		Name:  "strip-components",
+		Usage: "remove this many leading folders from the paths of the files relative to the source",
+	},
+}
+
+// pathMapping renames the paths of uploaded files relative to their
+// source folder, as set by --flatten and --strip-components.
+type pathMapping struct {
+	flatten         bool
+	stripComponents int
+}
+
+// parsePathMapping returns the path mapping of the flags of cliCtx.
+func parsePathMapping(cliCtx *cli.Context) pathMapping {
+	m := pathMapping{flatten: cliCtx.Bool("flatten"), stripComponents: cliCtx.Int("strip-components")}
+	if m.stripComponents < 0 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("strip-components")), "--strip-components cannot be negative.")
+	}
+	if m.flatten && m.stripComponents > 0 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--flatten and --strip-components cannot be used together.")
+	}
+	return m
+}
+
+func (m pathMapping) isSet() bool {
+	return m.flatten || m.stripComponents > 0
+}
+
+// String returns the flag setting the mapping, for error messages.
+func (m pathMapping) String() string {
+	if m.flatten {
+		return "--flatten"
+	}
+	return "--strip-components"
+}
+
+// apply returns the mapped path of a slash separated relative path.
+func (m pathMapping) apply(p string) (string, *probe.Error) {
+	if m.flatten {
+		return path.Base(p), nil
+	}
+	stripped, ok := stripPathComponents(p, m.stripComponents)
+	if !ok {
+		return "", errStripAllComponents(p, m.stripComponents)
+	}
+	return stripped, nil
+}
+
+// pathCollisions finds source files mapped to the same target, it maps
+// the targets to their source.
+type pathCollisions map[string]string
+
+func (c pathCollisions) add(target, source string, m pathMapping) *probe.Error {
+	if other, ok := c[target]; ok {
+		return errPathCollision(m.String(), target, other, source)
+	}
+	c[target] = source
+	return nil
+}
+
+// mapSourceContents lists the files of a mirror source and sets the path
+// of each relative to the target with m. The files are sent sorted by
+// this path, as compared with the target listing, and only once every
+// file is listed so that collisions are found before uploading.
+func mapSourceContents(sourceURL string, sourceCh <-chan *ClientContent, m pathMapping) (<-chan *ClientContent, *probe.Error) {
+	var contents []*ClientContent
+	collisions := pathCollisions{}
+	for content := range sourceCh {
+		if content.Err != nil {
+			return nil, content.Err.Trace(sourceURL)
+		}
+		suffix := strings.TrimPrefix(content.URL.String(), sourceURL)
+		mapped, err := m.apply(strings.ReplaceAll(suffix, string(content.URL.Separator), "/"))
+		if err != nil {
+			return nil, err.Trace(content.URL.String())
+		}
+		if err = collisions.add(mapped, content.URL.String(), m); err != nil {
+			return nil, err
+		}
+		content.targetSuffix = mapped
+		contents = append(contents, content)
+	}
+	sort.Slice(contents, func(i, j int) bool {
+		return norm.NFC.String(contents[i].targetSuffix) < norm.NFC.String(contents[j].targetSuffix)
+	})
+	mappedCh := make(chan *ClientContent, len(contents))
+	for _, content := range contents {
+		mappedCh <- content
+	}
+	close(mappedCh)
+	return mappedCh, nil
+}
+
+// mappedObjectDifference is objectDifference with the source files renamed
+// by m, the source is listed in full first.
+func mappedObjectDifference(ctx context.Context, sourceClnt, targetClnt Client, isMetadata bool, compare compareMode, m pathMapping) (chan diffMessage, *probe.Error) {
+	listOpts := ListOptions{Recursive: true, WithMetadata: isMetadata, ShowDir: DirNone}
+	sourceURL := sourceClnt.GetURL().String()
+	sourceCh, err := mapSourceContents(sourceURL, sourceClnt.List(ctx, listOpts), m)
+	if err != nil {
+		return nil, err
+	}
+	targetURL := targetClnt.GetURL().String()
+	return difference(sourceURL, sourceCh, targetURL, targetClnt.List(ctx, listOpts), isMetadata, false, compare), nil
+}
+
+// mirrorSourceSuffix returns the path of the source of diffMsg relative
+// to the target, sourceURL is the expanded URL of the source folder.
+func mirrorSourceSuffix(sourceURL string, diffMsg diffMessage) string {
+	if diffMsg.firstContent != nil && diffMsg.firstContent.targetSuffix != "" {
+		return diffMsg.firstContent.targetSuffix
+	}
+	return strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
+}
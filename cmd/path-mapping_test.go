@@ -0,0 +1,133 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func writeTestTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if e := os.MkdirAll(filepath.Dir(path), 0o700); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(data), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+}
+
+func TestPutPathMapping(t *testing.T) {
+	initTestConfig(t)
+	src := filepath.Join(t.TempDir(), "data")
+	writeTestTree(t, src, map[string]string{"2024/run1/a.bin": "a", "2024/run2/b.bin": "b"})
+
+	for i, testCase := range []struct {
+		mapping  pathMapping
+		expected []string
+	}{
+		{pathMapping{}, []string{"gpumall/bucket/dir/data/2024/run1/a.bin", "gpumall/bucket/dir/data/2024/run2/b.bin"}},
+		{pathMapping{stripComponents: 2}, []string{"gpumall/bucket/dir/run1/a.bin", "gpumall/bucket/dir/run2/b.bin"}},
+		{pathMapping{flatten: true}, []string{"gpumall/bucket/dir/a.bin", "gpumall/bucket/dir/b.bin"}},
+	} {
+		var targets []string
+		opts := prepareCopyURLsOpts{sourceURLs: []string{src}, targetURL: "gpumall/bucket/dir", isRecursive: true, pathMapping: testCase.mapping}
+		for urls := range preparePutURLs(context.Background(), opts) {
+			if urls.Error != nil {
+				t.Fatalf("Test %d: %v", i+1, urls.Error)
+			}
+			targets = append(targets, urls.TargetContent.URL.Path)
+		}
+		sort.Strings(targets)
+		if strings.Join(targets, " ") != strings.Join(testCase.expected, " ") {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, testCase.expected, targets)
+		}
+	}
+
+	// A collision is reported before any upload is prepared.
+	writeTestTree(t, src, map[string]string{"2024/run2/a.bin": "a"})
+	opts := prepareCopyURLsOpts{sourceURLs: []string{src}, targetURL: "gpumall/bucket/dir", isRecursive: true, pathMapping: pathMapping{flatten: true}}
+	var urls []URLs
+	for u := range preparePutURLs(context.Background(), opts) {
+		urls = append(urls, u)
+	}
+	if len(urls) != 1 || urls[0].Error == nil || !strings.Contains(urls[0].Error.ToGoError().Error(), "--flatten maps both") {
+		t.Fatalf("expected a single collision error, got %v", urls)
+	}
+
+	// Names are also checked across sources.
+	run1, run2 := filepath.Join(t.TempDir(), "run1"), filepath.Join(t.TempDir(), "run2")
+	writeTestTree(t, run1, map[string]string{"x/a.bin": "a"})
+	writeTestTree(t, run2, map[string]string{"y/a.bin": "a", "y/b.bin": "b"})
+	opts = prepareCopyURLsOpts{sourceURLs: []string{run1, run2}, targetURL: "gpumall/bucket/dir", isRecursive: true, pathMapping: pathMapping{flatten: true}}
+	urls = nil
+	for u := range preparePutURLs(context.Background(), opts) {
+		urls = append(urls, u)
+	}
+	if len(urls) != 1 || urls[0].Error == nil || !strings.Contains(urls[0].Error.ToGoError().Error(), "--flatten maps both") {
+		t.Fatalf("expected a single collision error, got %v", urls)
+	}
+}
+
+func TestMirrorPathMapping(t *testing.T) {
+	initTestConfig(t)
+	src, dst := t.TempDir(), t.TempDir()
+	writeTestTree(t, src, map[string]string{"run1/a.bin": "a", "run1/sub/b.bin": "b", "run2/c.bin": "c"})
+	writeTestTree(t, dst, map[string]string{"c.bin": "c"})
+
+	mirrorTargets := func(mapping pathMapping) ([]string, []URLs) {
+		var targets []string
+		var errs []URLs
+		for urls := range prepareMirrorURLs(context.Background(), src, dst, mirrorOptions{pathMapping: mapping}) {
+			if urls.Error != nil {
+				errs = append(errs, urls)
+				continue
+			}
+			rel, e := filepath.Rel(dst, urls.TargetContent.URL.Path)
+			if e != nil {
+				t.Fatal(e)
+			}
+			targets = append(targets, filepath.ToSlash(rel))
+		}
+		sort.Strings(targets)
+		return targets, errs
+	}
+
+	// c.bin is already on the target under its flattened name.
+	targets, errs := mirrorTargets(pathMapping{flatten: true})
+	if len(errs) != 0 || strings.Join(targets, " ") != "a.bin b.bin" {
+		t.Fatalf("expected a.bin and b.bin, got %v %v", targets, errs)
+	}
+	targets, errs = mirrorTargets(pathMapping{stripComponents: 1})
+	if len(errs) != 0 || strings.Join(targets, " ") != "a.bin sub/b.bin" {
+		t.Fatalf("expected a.bin and sub/b.bin, got %v %v", targets, errs)
+	}
+
+	writeTestTree(t, src, map[string]string{"run2/a.bin": "other"})
+	targets, errs = mirrorTargets(pathMapping{flatten: true})
+	if len(targets) != 0 || len(errs) != 1 || !strings.Contains(errs[0].Error.ToGoError().Error(), "--flatten maps both") {
+		t.Fatalf("expected a single collision error, got %v %v", targets, errs)
+	}
+}
@@ -84,6 +84,7 @@ FLAGS:
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT:      list of comma delimited prefix values
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 EXAMPLES:
   1. Write contents of stdin to a file on local filesystem.
@@ -153,7 +154,7 @@ func pipe(ctx *cli.Context, targetURL string, encKeyDB map[string][]prefixSSEPai
 
 	var reader io.Reader
 	if !quiet {
-		pg := newProgressBar(0)
+		pg := newProgressBar(0, statusOutput)
 		reader = io.TeeReader(os.Stdin, pg)
 	} else {
 		reader = os.Stdin
@@ -0,0 +1,204 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/v2/console"
+)
+
+// A recursive put or a mirror can scan thousands of files before sending
+// its first request to the target. --preflight probes the endpoints first,
+// like `mc ping` does, and signs one listing, so that an unreachable
+// endpoint or invalid credentials fail the command right away. A put
+// also checks that its target bucket exists.
+
+const (
+	// preflightTimeout bounds the probes of every endpoint.
+	preflightTimeout = 10 * time.Second
+
+	// preflightSizeThreshold is the total size of the files from which a
+	// put which is not recursive is checked by default.
+	preflightSizeThreshold = 1 << 30
+)
+
+var preflightFlag = cli.BoolFlag{
+	Name:  "preflight",
+	Usage: "check the endpoints are reachable and accept the credentials, and that the target bucket of a put exists, before transferring, on by default for recursive and large transfers",
+}
+
+var skipPreflightFlag = cli.BoolFlag{
+	Name:  "skip-preflight",
+	Usage: "do not check the target bucket nor the endpoints before transferring, for servers forbidding HEAD requests on buckets",
+}
+
+// isPreflightEnabled returns whether --preflight is set, or whether the
+// transfer is large if it is not.
+func isPreflightEnabled(cliCtx *cli.Context, isLarge bool) bool {
+	if cliCtx.IsSet("preflight") {
+		return cliCtx.Bool("preflight")
+	}
+	return isLarge
+}
+
+// isLargePut returns whether a put of sourceURLs is likely to be large,
+// recursive or of local files of at least preflightSizeThreshold bytes.
+func isLargePut(sourceURLs []string, isRecursive bool) bool {
+	if isRecursive {
+		return true
+	}
+	var size int64
+	for _, sourceURL := range sourceURLs {
+		if fi, e := os.Stat(sourceURL); e == nil {
+			size += fi.Size()
+		}
+	}
+	return size >= preflightSizeThreshold
+}
+
+// isCredentialsError returns true for the errors of requests the server
+// rejects because of their credentials, rather than their permissions.
+func isCredentialsError(e error) bool {
+	switch minio.ToErrorResponse(e).Code {
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken", "InvalidToken", "InvalidTokenId":
+		return true
+	}
+	return false
+}
+
+// preflight probes the endpoint of every aliased URL of urls, local paths
+// are skipped. It fails if an endpoint cannot be reached or rejects the
+// credentials of its alias.
+func preflight(ctx context.Context, urls ...string) *probe.Error {
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	probed := map[string]bool{}
+	for _, aliasedURL := range urls {
+		alias, urlStrFull, aliasCfg, err := expandAlias(aliasedURL)
+		if err != nil || aliasCfg == nil || probed[alias] {
+			continue
+		}
+		probed[alias] = true
+
+		anonClient, err := newAnonymousClient(aliasedURL)
+		if err != nil {
+			return err.Trace(aliasedURL)
+		}
+		// Only MinIO serves the health check, any answer shows that the
+		// endpoint is reachable.
+		for result := range anonClient.Alive(ctx, madmin.AliveOpts{}) {
+			if result.Error != nil {
+				return probe.NewError(fmt.Errorf("endpoint `%s` of `%s` is unreachable: %w", aliasCfg.URL, alias, result.Error)).Trace(aliasedURL)
+			}
+		}
+
+		clnt, err := newClientFromAlias(alias, urlStrFull)
+		if err != nil {
+			return err.Trace(aliasedURL)
+		}
+		s3Clnt, ok := clnt.(*S3Client)
+		if !ok {
+			continue
+		}
+		bucket, object := s3Clnt.url2BucketAndObject()
+		if bucket == "" {
+			continue
+		}
+		for info := range s3Clnt.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, MaxKeys: 1}) {
+			if isCredentialsError(info.Err) {
+				return probe.NewError(fmt.Errorf("endpoint `%s` rejected the credentials of `%s`: %w", aliasCfg.URL, alias, info.Err)).Trace(aliasedURL)
+			}
+			if info.Err != nil && ctx.Err() != nil {
+				return probe.NewError(fmt.Errorf("endpoint `%s` of `%s` did not answer within %s", aliasCfg.URL, alias, preflightTimeout)).Trace(aliasedURL)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// preflightBucket checks that the bucket of aliasedURL exists and that
+// the credentials of its alias can access it. A transfer otherwise only
+// fails once its sources are scanned, with an error for every object. It
+// runs with the other checks of --preflight.
+func preflightBucket(ctx context.Context, aliasedURL string) *probe.Error {
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	alias, urlStrFull, aliasCfg, err := expandAlias(aliasedURL)
+	if err != nil {
+		return err.Trace(aliasedURL)
+	}
+	if aliasCfg == nil {
+		return nil
+	}
+	clnt, err := newClientFromAlias(alias, urlStrFull)
+	if err != nil {
+		return err.Trace(aliasedURL)
+	}
+	s3Clnt, ok := clnt.(*S3Client)
+	if !ok {
+		return nil
+	}
+	bucket, _ := s3Clnt.url2BucketAndObject()
+	if bucket == "" {
+		return nil
+	}
+	region, err := s3Clnt.probeBucket(ctx)
+	if err == nil {
+		if globalDebug {
+			console.Debugln(fmt.Sprintf("Bucket `%s` of `%s` is in region %q", bucket, alias, region))
+		}
+		return nil
+	}
+
+	target := fmt.Sprintf("bucket `%s` of `%s`", bucket, alias)
+	if alias == AuthAlias {
+		target += fmt.Sprintf(" with the base path `%s`", getAuth().BasePath)
+	}
+	e := err.ToGoError()
+	if minio.ToErrorResponse(e).Code == "AccessDenied" {
+		// Credentials limited to objects may be denied the HEAD request
+		// of their bucket, whose access is then unknown.
+		if globalDebug {
+			console.Debugln(fmt.Sprintf("Unable to check %s: %v", target, e))
+		}
+		return nil
+	}
+	_, missing := e.(BucketDoesNotExist)
+	switch {
+	case missing:
+		e = fmt.Errorf("%s does not exist", target)
+	case isCredentialsError(e):
+		e = fmt.Errorf("endpoint `%s` rejected the credentials of `%s`: %w", aliasCfg.URL, alias, e)
+	case ctx.Err() != nil:
+		e = fmt.Errorf("endpoint `%s` of `%s` did not answer within %s", aliasCfg.URL, alias, preflightTimeout)
+	default:
+		e = fmt.Errorf("unable to check %s: %w", target, e)
+	}
+	return probe.NewError(e).Trace(aliasedURL)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestPreflight(t *testing.T) {
+	initTestConfig(t)
+	dir := t.TempDir()
+	if e := os.WriteFile(filepath.Join(dir, "file"), []byte("hello"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if isLargePut([]string{filepath.Join(dir, "file")}, false) || !isLargePut([]string{dir}, true) {
+		t.Fatal("unexpected estimate of the size of the put")
+	}
+
+	// The endpoint is gone, the preflight fails before anything is listed.
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	t.Setenv(mcEnvHostPrefix+"preflightdown", "http://"+miniotest.AccessKey+":"+miniotest.SecretKey+"@"+strings.TrimPrefix(down.URL, "http://"))
+	start := time.Now()
+	err := preflight(context.Background(), dir, "preflightdown/bucket/prefix/")
+	if err == nil || !strings.Contains(err.ToGoError().Error(), "unreachable") {
+		t.Fatalf("expected the endpoint to be unreachable, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > preflightTimeout {
+		t.Fatalf("expected the preflight to fail fast, took %s", elapsed)
+	}
+
+	server := miniotest.NewServer()
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	t.Setenv(mcEnvHostPrefix+"preflightbad", "http://"+miniotest.AccessKey+":wrong-secret@"+host)
+	if err = preflight(context.Background(), dir, "preflightbad/bucket"); err == nil || !strings.Contains(err.ToGoError().Error(), "rejected the credentials") {
+		t.Fatalf("expected the credentials to be rejected, got %v", err)
+	}
+
+	// Missing buckets are created by the transfers.
+	t.Setenv(mcEnvHostPrefix+"preflightok", server.AliasURL())
+	server.ResetRequests()
+	if err = preflight(context.Background(), dir, "preflightok/bucket/prefix/", "preflightok/other"); err != nil {
+		t.Fatal(err)
+	}
+	if n := server.RequestCount(http.MethodGet); n != 2 {
+		t.Fatalf("expected the health check and one listing, got %d requests", n)
+	}
+}
+
+func TestPreflightBucket(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.MakeBucket("bucket")
+	t.Setenv(mcEnvHostPrefix+"preflightbucket", server.AliasURL())
+
+	if err := preflightBucket(context.Background(), "preflightbucket/bucket/prefix/"); err != nil {
+		t.Fatal(err)
+	}
+	if n := server.RequestCount(http.MethodHead); n != 1 {
+		t.Fatalf("expected one HEAD request, got %d", n)
+	}
+	err := preflightBucket(context.Background(), "preflightbucket/buckte/prefix/")
+	if err == nil || err.ToGoError().Error() != "bucket `buckte` of `preflightbucket` does not exist" {
+		t.Fatalf("expected the bucket to be missing, got %v", err)
+	}
+	// Local paths and aliases without a bucket are not checked.
+	if err = preflightBucket(context.Background(), t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The error of a gpumall path tells its base path.
+	defer func(creds *AuthData) { globalCredentials = creds }(globalCredentials)
+	defer func(cfg *aliasConfigV10) { aliasToConfigMap[AuthAlias] = cfg }(aliasToConfigMap[AuthAlias])
+	globalCredentials = &AuthData{Endpoint: server.URL, Bucket: "missing", BasePath: "/u1", AccessKey: miniotest.AccessKey, SecretKey: miniotest.SecretKey}
+	registerAuthAlias(*globalCredentials, "")
+	err = preflightBucket(context.Background(), getFullPath("data/"))
+	if err == nil || err.ToGoError().Error() != "bucket `missing` of `gpumall` with the base path `/u1` does not exist" {
+		t.Fatalf("expected the gpumall bucket to be missing, got %v", err)
+	}
+
+	t.Setenv(mcEnvHostPrefix+"preflightbucketbad", "http://"+miniotest.AccessKey+":wrong-secret@"+strings.TrimPrefix(server.URL, "http://"))
+	if err = preflightBucket(context.Background(), "preflightbucketbad/bucket"); err == nil || !strings.Contains(err.ToGoError().Error(), "rejected the credentials") {
+		t.Fatalf("expected the credentials to be rejected, got %v", err)
+	}
+
+	// Servers forbidding HEAD requests on buckets leave the check unknown.
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && strings.Trim(r.URL.Path, "/") == "bucket" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer forbidden.Close()
+	t.Setenv(mcEnvHostPrefix+"preflightbucketforbidden", "http://"+miniotest.AccessKey+":"+miniotest.SecretKey+"@"+strings.TrimPrefix(forbidden.URL, "http://"))
+	if err = preflightBucket(context.Background(), "preflightbucketforbidden/bucket/prefix/"); err != nil {
+		t.Fatalf("expected a forbidden HEAD request to be ignored, got %v", err)
+	}
+}
@@ -20,8 +20,12 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"os"
 	"strings"
 
+	"github.com/minio/mc/pkg/probe"
+
 	"github.com/minio/pkg/v2/console"
 )
 
@@ -34,7 +38,30 @@ type message interface {
 // printMsg prints message string or JSON structure depending on the type of output console.
 func printMsg(msg message) {
 	var msgStr string
-	if !globalJSON {
+	if globalCompat != nil {
+		if record, ok := msg.(recordMessage); ok {
+			written, e := globalCompat.write(record)
+			fatalIf(probe.NewError(e), "Unable to write the output.")
+			if written {
+				return
+			}
+		}
+		fmt.Fprintln(os.Stderr, strings.TrimSuffix(msg.String(), "\n"))
+		return
+	}
+	if globalCSV != nil {
+		if record, ok := msg.(recordMessage); ok {
+			fatalIf(probe.NewError(globalCSV.write(record)), "Unable to write the output.")
+		} else {
+			// Only the records are rows, other messages such as
+			// summaries are kept out of the table.
+			fmt.Fprintln(os.Stderr, strings.TrimSuffix(msg.String(), "\n"))
+		}
+		return
+	}
+	if record, ok := msg.(recordMessage); ok && globalFormat != nil {
+		msgStr = mustFormatRecord(record)
+	} else if !globalJSON {
 		msgStr = msg.String()
 	} else {
 		msgStr = msg.JSON()
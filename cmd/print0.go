@@ -0,0 +1,49 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// print0Flag prints the listed names for 'xargs -0' and 'mc put --null'.
+var print0Flag = cli.BoolFlag{
+	Name:  "print0",
+	Usage: "print each name followed by a NUL character instead of a newline, for 'xargs -0' or 'mc put --files-from - --null'",
+}
+
+// print0Output is where --print0 writes the names.
+var print0Output io.Writer = os.Stdout
+
+// checkPrint0Syntax rejects the output flags that --print0 replaces.
+func checkPrint0Syntax(cliCtx *cli.Context) {
+	if cliCtx.Bool("print0") && (globalJSON || globalFormat != nil || globalCSV != nil || globalCompat != nil) {
+		fatalIf(errInvalidArgument(), "--print0 cannot be used with --json, --format, --csv, --tsv or --compat.")
+	}
+}
+
+// printNul prints name followed by a NUL character, so that names
+// holding newlines stay apart.
+func printNul(name string) {
+	_, e := io.WriteString(print0Output, name+"\x00")
+	fatalIf(probe.NewError(e), "Unable to write the output.")
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrint0(t *testing.T) {
+	initTestConfig(t)
+	dir := t.TempDir()
+	names := []string{"a.npz", "line\nbreak.npz", "skip.txt"}
+	for _, name := range names {
+		if e := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	clnt, err := newClient(dir + string(filepath.Separator))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	print0Output = &out
+	defer func() { print0Output = os.Stdout }()
+
+	if e := doList(context.Background(), clnt, doListOptions{print0: true}); e != nil {
+		t.Fatal(e)
+	}
+	if listed := strings.Split(strings.TrimSuffix(out.String(), "\x00"), "\x00"); !reflect.DeepEqual(listed, names) {
+		t.Fatalf("expected ls to print %q, got %q", names, listed)
+	}
+
+	out.Reset()
+	if e := doFind(context.Background(), &findContext{
+		namePattern: "*.npz",
+		print0:      true,
+		targetURL:   dir,
+		clnt:        clnt,
+	}); e != nil {
+		t.Fatal(e)
+	}
+	found, err := readFilesFrom(&out, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(dir, "a.npz"), filepath.Join(dir, "line\nbreak.npz")}
+	if !reflect.DeepEqual(found, expected) {
+		t.Fatalf("expected find to print %q, got %q", expected, found)
+	}
+}
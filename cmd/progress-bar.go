@@ -18,6 +18,7 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"runtime"
 	"strings"
@@ -33,7 +34,8 @@ type progressBar struct {
 	*pb.ProgressBar
 }
 
-func newPB(total int64) *pb.ProgressBar {
+// newPB returns a started progress bar drawn on w.
+func newPB(total int64, w io.Writer) *pb.ProgressBar {
 	// Progress bar specific theme customization.
 	console.SetColor("Bar", color.New(color.FgGreen, color.Bold))
 
@@ -41,7 +43,7 @@ func newPB(total int64) *pb.ProgressBar {
 	bar := pb.New64(total)
 
 	// Set new human friendly print units.
-	bar.SetUnits(pb.U_BYTES)
+	bar.SetUnits(globalUnits.pbUnits())
 
 	// Refresh rate for progress bar is set to 125 milliseconds.
 	bar.SetRefreshRate(time.Millisecond * 125)
@@ -54,7 +56,7 @@ func newPB(total int64) *pb.ProgressBar {
 
 	// Custom callback with colorized bar.
 	bar.Callback = func(s string) {
-		console.Print(console.Colorize("Bar", "\r"+s))
+		fmt.Fprint(w, console.Colorize("Bar", "\r"+s))
 	}
 
 	// Use different unicodes for Linux, OS X and Windows.
@@ -74,8 +76,8 @@ func newPB(total int64) *pb.ProgressBar {
 	return bar.Start()
 }
 
-func newProgressReader(r io.Reader, caption string, total int64) *pb.Reader {
-	bar := newPB(total)
+func newProgressReader(r io.Reader, caption string, total int64, w io.Writer) *pb.Reader {
+	bar := newPB(total, w)
 
 	if caption != "" {
 		bar.Prefix(caption)
@@ -84,9 +86,9 @@ func newProgressReader(r io.Reader, caption string, total int64) *pb.Reader {
 	return bar.NewProxyReader(r)
 }
 
-// newProgressBar - instantiate a progress bar.
-func newProgressBar(total int64) *progressBar {
-	bar := newPB(total)
+// newProgressBar - instantiate a progress bar drawn on w.
+func newProgressBar(total int64, w io.Writer) *progressBar {
+	bar := newPB(total, w)
 
 	// Return new progress bar here.
 	return &progressBar{ProgressBar: bar}
@@ -0,0 +1,209 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// progressFileInterval is how often --progress-file is rewritten.
+const progressFileInterval = 2 * time.Second
+
+// progressFileState is the content of --progress-file.
+type progressFileState struct {
+	Status           string    `json:"status"`
+	StartTime        time.Time `json:"startTime"`
+	UpdateTime       time.Time `json:"updateTime"`
+	BytesTransferred int64     `json:"bytesTransferred"`
+	TotalBytes       int64     `json:"totalBytes"`
+	CompletedObjects int64     `json:"completedObjects"`
+	TotalObjects     int64     `json:"totalObjects"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// progressFile periodically replaces a file with the progress of a
+// transfer, so that it can be monitored and a crash leaves the last
+// known progress behind. A nil progressFile does nothing.
+type progressFile struct {
+	path  string
+	pg    Progress
+	start time.Time
+
+	totalBytes   int64
+	totalObjects int64
+	completed    int64
+
+	// mu serializes the writes, transferred keeps the bytes written
+	// last so that the file never goes backwards.
+	mu          sync.Mutex
+	transferred int64
+
+	stopOnce   sync.Once
+	stop       chan struct{}
+	done       chan struct{}
+	unregister func()
+}
+
+// newProgressFile writes the progress of pg to path every interval
+// until closed, an unwritable path fails right away. Without a path,
+// the progress is only counted for the progress records.
+func newProgressFile(path string, pg Progress, interval time.Duration) (*progressFile, *probe.Error) {
+	p := &progressFile{
+		path:  path,
+		pg:    pg,
+		start: timeNow().UTC(),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if path == "" {
+		close(p.done)
+		return p, nil
+	}
+	if err := p.flush("running", nil); err != nil {
+		return nil, err.Trace(path)
+	}
+	// A fatal error exits without returning to the command.
+	p.unregister = registerFatalHook(p.close)
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				errorIf(p.flush("running", nil).Trace(path), "Unable to write the progress file.")
+			}
+		}
+	}()
+	return p, nil
+}
+
+// addTotal accounts for an object of size bytes to transfer.
+func (p *progressFile) addTotal(size int64) {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.totalBytes, size)
+	atomic.AddInt64(&p.totalObjects, 1)
+}
+
+// objectDone accounts for an object transferred or skipped.
+func (p *progressFile) objectDone() {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.completed, 1)
+}
+
+// flush replaces the file with the current progress, through a
+// temporary file in the same directory so that readers never see a
+// partial write.
+func (p *progressFile) flush(status string, transferErr error) *probe.Error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := p.state(status)
+	if transferErr != nil {
+		state.Error = transferErr.Error()
+	}
+	data, e := json.MarshalIndent(state, "", " ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+
+	return writeFileAtomic(p.path, append(data, '\n'))
+}
+
+// state returns the current progress, it must be called with p.mu held.
+func (p *progressFile) state(status string) progressFileState {
+	if n := p.pg.Get(); n > p.transferred {
+		p.transferred = n
+	}
+	return progressFileState{
+		Status:           status,
+		StartTime:        p.start,
+		UpdateTime:       timeNow().UTC(),
+		BytesTransferred: p.transferred,
+		TotalBytes:       atomic.LoadInt64(&p.totalBytes),
+		CompletedObjects: atomic.LoadInt64(&p.completed),
+		TotalObjects:     atomic.LoadInt64(&p.totalObjects),
+	}
+}
+
+// snapshot returns the current progress.
+func (p *progressFile) snapshot() progressFileState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state("running")
+}
+
+// writeFileAtomic replaces the file at path with data, through a temporary
+// file renamed over it, so that readers never see a partial file.
+func writeFileAtomic(path string, data []byte) *probe.Error {
+	return writeFileAtomicMode(path, data, 0o600)
+}
+
+// writeFileAtomicMode is writeFileAtomic for a file with the permissions
+// perm, such as one read by another user.
+func writeFileAtomicMode(path string, data []byte, perm os.FileMode) *probe.Error {
+	tmp, e := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	defer os.Remove(tmp.Name())
+	if e = tmp.Chmod(perm); e == nil {
+		_, e = tmp.Write(data)
+	}
+	if e == nil {
+		e = tmp.Sync()
+	}
+	if ce := tmp.Close(); e == nil {
+		e = ce
+	}
+	if e != nil {
+		return probe.NewError(e)
+	}
+	return probe.NewError(os.Rename(tmp.Name(), path))
+}
+
+// close stops the periodic writes and writes the final progress, as
+// "failed" when transferErr is set and "done" otherwise.
+func (p *progressFile) close(transferErr error) {
+	if p == nil || p.path == "" {
+		return
+	}
+	p.stopOnce.Do(func() {
+		p.unregister()
+		close(p.stop)
+		<-p.done
+		status := "done"
+		if transferErr != nil {
+			status = "failed"
+		}
+		errorIf(p.flush(status, transferErr).Trace(p.path), "Unable to write the progress file.")
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func readProgressFile(t *testing.T, path string) progressFileState {
+	t.Helper()
+	data, e := os.ReadFile(path)
+	if e != nil {
+		t.Fatal(e)
+	}
+	var state progressFileState
+	if e = json.Unmarshal(data, &state); e != nil {
+		t.Fatalf("partial progress file %q: %v", data, e)
+	}
+	return state
+}
+
+// fakeClock makes timeNow advance by a second on every call.
+func fakeClock(t *testing.T) {
+	t.Helper()
+	saved := timeNow
+	t.Cleanup(func() { timeNow = saved })
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	timeNow = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func TestProgressFile(t *testing.T) {
+	fakeClock(t)
+	path := filepath.Join(t.TempDir(), "upload.progress")
+	const objects, objectSize = 5, 1 << 10
+	pg := newAccounter(objects * objectSize)
+	// The periodic writes are replaced by explicit flushes.
+	progress, err := newProgressFile(path, pg, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := readProgressFile(t, path); state.Status != "running" || state.BytesTransferred != 0 {
+		t.Fatalf("unexpected initial progress %+v", state)
+	}
+
+	last := readProgressFile(t, path)
+	for i := 0; i < objects; i++ {
+		progress.addTotal(objectSize)
+		// Uploads report the bytes sent by reading them through the progress.
+		if _, e := pg.Read(make([]byte, objectSize)); e != nil {
+			t.Fatal(e)
+		}
+		progress.objectDone()
+		if err = progress.flush("running", nil); err != nil {
+			t.Fatal(err)
+		}
+
+		state := readProgressFile(t, path)
+		if state.BytesTransferred != int64(i+1)*objectSize || state.CompletedObjects != int64(i+1) {
+			t.Fatalf("unexpected progress %+v after %d objects", state, i+1)
+		}
+		if !state.UpdateTime.After(last.UpdateTime) || !state.StartTime.Equal(last.StartTime) {
+			t.Fatalf("unexpected update from %+v to %+v", last, state)
+		}
+		last = state
+	}
+
+	progress.close(nil)
+	state := readProgressFile(t, path)
+	if state.Status != "done" || state.BytesTransferred != objects*objectSize || state.CompletedObjects != objects || state.TotalObjects != objects || state.TotalBytes != objects*objectSize {
+		t.Errorf("unexpected final progress %+v", state)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*.tmp")); len(matches) != 0 {
+		t.Errorf("expected no temporary file left, got %v", matches)
+	}
+
+	progress, err = newProgressFile(path, pg, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	progress.close(errors.New("connection reset"))
+	if state = readProgressFile(t, path); state.Status != "failed" || state.Error != "connection reset" {
+		t.Errorf("unexpected failed progress %+v", state)
+	}
+
+	if _, err = newProgressFile(filepath.Join(path, "missing", "file"), pg, time.Hour); err == nil {
+		t.Error("expected an unwritable path to fail")
+	}
+}
+
+// A fatal error writes the failure before mc exits.
+func TestProgressFileFatal(t *testing.T) {
+	jsonErrorOutput(t, "put")
+	path := filepath.Join(t.TempDir(), "upload.progress")
+	progress, err := newProgressFile(path, newAccounter(0), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.close(nil)
+
+	if code := exitCode(func() { fatalIf(probe.NewError(errors.New("connection reset")), "Unable to upload.") }); code != globalErrorExitStatus {
+		t.Fatalf("expected exit code %d, got %d", globalErrorExitStatus, code)
+	}
+	if state := readProgressFile(t, path); state.Status != "failed" || state.Error != "connection reset" {
+		t.Fatalf("unexpected progress after a fatal error %+v", state)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb"
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// progressRecordFlags print the progress of a transfer in --json mode.
+var progressRecordFlags = []cli.Flag{
+	cli.DurationFlag{
+		Name:  "progress-interval",
+		Usage: "with --json, print a progress record at this interval during the transfer, 0 disables them",
+		Value: 5 * time.Second,
+	},
+	cli.StringFlag{
+		Name:  "progress-bytes",
+		Usage: "with --json, also print a progress record every time this many more bytes are transferred, such as 1GiB",
+	},
+}
+
+// progressRecordPoll is how often the transferred bytes are checked
+// against --progress-bytes.
+const progressRecordPoll = 250 * time.Millisecond
+
+// printProgressRecord prints a progress record, tests replace it.
+var printProgressRecord = func(r progressRecord) { printMsg(r) }
+
+// progressRecord is the progress of a transfer, printed as a single JSON
+// line told apart from the final summary by its type.
+type progressRecord struct {
+	Status       string  `json:"status"`
+	Type         string  `json:"type"`
+	Transferred  int64   `json:"transferred"`
+	Total        int64   `json:"total"`
+	Objects      int64   `json:"objects"`
+	TotalObjects int64   `json:"totalObjects"`
+	Speed        float64 `json:"speed"`
+	Elapsed      float64 `json:"elapsed"`
+}
+
+func (r progressRecord) String() string {
+	return fmt.Sprintf("Transferred: %s of %s, Objects: %d of %d, Speed: %s/s", pb.Format(r.Transferred).To(globalUnits.pbUnits()),
+		pb.Format(r.Total).To(globalUnits.pbUnits()), r.Objects, r.TotalObjects, pb.Format(int64(r.Speed)).To(globalUnits.pbUnits()))
+}
+
+// JSON is a single line, whether stdout is a terminal or not.
+func (r progressRecord) JSON() string {
+	r.Status = "success"
+	r.Type = "progress"
+	msgBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// progressRecords is an accounter printing progress records every
+// interval, and every time bytes more bytes are transferred if set,
+// until its final summary is taken.
+type progressRecords struct {
+	*accounter
+	progress *progressFile
+	interval time.Duration
+	bytes    int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newProgressRecordsFromContext returns acct printing the progress
+// records set by the flags of cliCtx, or acct itself without --json.
+// The objects are counted by progress.
+func newProgressRecordsFromContext(cliCtx *cli.Context, acct *accounter, progress *progressFile) ProgressReader {
+	interval := cliCtx.Duration("progress-interval")
+	bytesStr := cliCtx.String("progress-bytes")
+	if !globalJSON {
+		if cliCtx.IsSet("progress-interval") || bytesStr != "" {
+			fatalIf(errInvalidArgument(), "--progress-interval and --progress-bytes can only be used with --json.")
+		}
+		return acct
+	}
+	if interval < 0 {
+		fatalIf(errInvalidArgument().Trace(interval.String()), "--progress-interval cannot be negative.")
+	}
+	var size uint64
+	if bytesStr != "" {
+		var e error
+		size, e = humanize.ParseBytes(bytesStr)
+		fatalIf(probe.NewError(e), "Unable to parse --progress-bytes `"+bytesStr+"`.")
+	}
+	if interval == 0 && size == 0 {
+		return acct
+	}
+	return newProgressRecords(acct, progress, interval, int64(size))
+}
+
+func newProgressRecords(acct *accounter, progress *progressFile, interval time.Duration, bytes int64) *progressRecords {
+	r := &progressRecords{
+		accounter: acct,
+		progress:  progress,
+		interval:  interval,
+		bytes:     bytes,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	poll := interval
+	if bytes > 0 && (poll == 0 || poll > progressRecordPoll) {
+		poll = progressRecordPoll
+	}
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(poll)
+		defer ticker.Stop()
+		next, lastBytes := time.Now().Add(interval), int64(0)
+		for {
+			select {
+			case <-r.stop:
+				return
+			case now := <-ticker.C:
+				n := r.Get()
+				if (interval > 0 && !now.Before(next)) || (bytes > 0 && n-lastBytes >= bytes) {
+					printProgressRecord(r.record())
+					next, lastBytes = now.Add(interval), n
+				}
+			}
+		}
+	}()
+	return r
+}
+
+// record returns the current progress.
+func (r *progressRecords) record() progressRecord {
+	n := r.Get()
+	rec := progressRecord{
+		Transferred: n,
+		Speed:       r.write(n),
+		Elapsed:     time.Since(r.startTime).Seconds(),
+	}
+	if r.progress != nil {
+		state := r.progress.snapshot()
+		rec.Total, rec.Objects, rec.TotalObjects = state.TotalBytes, state.CompletedObjects, state.TotalObjects
+	}
+	return rec
+}
+
+// close stops the progress records.
+func (r *progressRecords) close() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+	})
+}
+
+// Stat stops the progress records before taking the final summary, so
+// that no record follows it.
+func (r *progressRecords) Stat() accountStat {
+	r.close()
+	return r.accounter.Stat()
+}
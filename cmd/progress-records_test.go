@@ -0,0 +1,127 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordProgress collects the printed progress records.
+func recordProgress(t *testing.T) func() []progressRecord {
+	t.Helper()
+	var mu sync.Mutex
+	var records []progressRecord
+	old := printProgressRecord
+	t.Cleanup(func() { printProgressRecord = old })
+	printProgressRecord = func(r progressRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, r)
+	}
+	return func() []progressRecord {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]progressRecord(nil), records...)
+	}
+}
+
+func TestProgressRecords(t *testing.T) {
+	records := recordProgress(t)
+	acct := newAccounter(0)
+	progress, err := newProgressFile("", acct, progressFileInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newProgressRecords(acct, progress, 20*time.Millisecond, 0)
+	for i := 0; i < 3; i++ {
+		progress.addTotal(100)
+	}
+	for i := 0; i < 3; i++ {
+		r.Add(100)
+		progress.objectDone()
+		time.Sleep(50 * time.Millisecond)
+	}
+	summary := r.Stat()
+	got := records()
+	if len(got) < 3 {
+		t.Fatalf("expected a record every 20ms for 150ms, got %d", len(got))
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := len(records()); n != len(got) {
+		t.Fatalf("expected no record after the summary, got %d more", n-len(got))
+	}
+
+	for i, rec := range got {
+		if rec.Total != 300 || rec.TotalObjects != 3 {
+			t.Fatalf("expected the totals of 3 objects of 100 bytes, got %+v", rec)
+		}
+		if i > 0 && (rec.Transferred < got[i-1].Transferred || rec.Objects < got[i-1].Objects) {
+			t.Fatalf("expected cumulative records, got %+v after %+v", rec, got[i-1])
+		}
+	}
+	if last := got[len(got)-1]; last.Transferred != 300 || last.Objects != 3 {
+		t.Fatalf("expected the last record to count all 3 objects, got %+v", last)
+	}
+
+	line := got[0].JSON()
+	var fields map[string]interface{}
+	if e := json.Unmarshal([]byte(line), &fields); e != nil || strings.Contains(line, "\n") {
+		t.Fatalf("expected a single JSON line, got %q: %v", line, e)
+	}
+	if fields["type"] != "progress" || fields["status"] != "success" {
+		t.Fatalf("expected a progress record, got %q", line)
+	}
+	if e := json.Unmarshal([]byte(summary.JSON()), &fields); e != nil || fields["type"] != "summary" || fields["transferred"] != float64(300) {
+		t.Fatalf("expected the summary, got %q: %v", summary.JSON(), e)
+	}
+}
+
+func TestProgressRecordsBytes(t *testing.T) {
+	records := recordProgress(t)
+	acct := newAccounter(0)
+	// Without an interval, a record is printed every 100 bytes.
+	r := newProgressRecords(acct, nil, 0, 100)
+	defer r.close()
+	waitRecords := func(n int) []progressRecord {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if got := records(); len(got) >= n {
+				return got
+			}
+		}
+		t.Fatalf("expected %d records, got %d", n, len(records()))
+		return nil
+	}
+	r.Add(50)
+	time.Sleep(2 * progressRecordPoll)
+	if n := len(records()); n != 0 {
+		t.Fatalf("expected no record before 100 bytes, got %d", n)
+	}
+	r.Add(60)
+	if got := waitRecords(1); got[0].Transferred != 110 {
+		t.Fatalf("expected a record of 110 bytes, got %+v", got[0])
+	}
+	r.Add(100)
+	if got := waitRecords(2); got[1].Transferred != 210 {
+		t.Fatalf("expected a record of 210 bytes, got %+v", got[1])
+	}
+}
@@ -19,19 +19,27 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
-	"github.com/minio/pkg/v2/console"
 )
 
 // put command flags.
 var (
 	putFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "recursive, r",
+			Usage: "upload a folder and all its contents",
+		},
 		cli.IntFlag{
 			Name:  "parallel, P",
 			Usage: "upload number of parts in parallel",
@@ -39,9 +47,80 @@ var (
 		},
 		cli.StringFlag{
 			Name:  "part-size, s",
-			Usage: "each part size",
+			Usage: "each part size, \"MiB\" is 1024*1024 bytes and \"MB\" 1000*1000 bytes",
 			Value: "16MiB",
 		},
+		cli.BoolFlag{
+			Name:  "auto-part-size",
+			Usage: "raise the part size of files too large to upload in 10000 parts of --part-size, instead of failing their upload",
+		},
+		cli.BoolFlag{
+			Name:  "summary-only",
+			Usage: "suppress per-object output, only print the final summary",
+		},
+		cli.StringFlag{
+			Name:  "size",
+			Usage: "size of the stream when uploading from stdin, uploads fail if the stream size differs",
+		},
+		cli.BoolFlag{
+			Name:  "no-clobber",
+			Usage: "skip objects whose target exists with the same size and checksum, refuse to replace a differing target",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "with --no-clobber, replace targets which differ from their source",
+		},
+		cli.BoolFlag{
+			Name:  "only-newer",
+			Usage: "skip files whose target was modified after them, or in the same second with the same size",
+		},
+		cli.StringFlag{
+			Name:  "compare",
+			Usage: "skip objects whose target has the same size and MD5 sum (checksum, or etag to cache the sums of local files) or only the same size (size), by size for multipart objects",
+		},
+		cli.StringFlag{
+			Name:  "progress-file",
+			Usage: "rewrite this file with the bytes and objects transferred every few seconds, for monitoring long uploads",
+		},
+		cli.StringFlag{
+			Name:  "upload-id",
+			Usage: "multipart upload to add parts to with 'put part', or to finish with 'put complete'",
+		},
+		cli.StringFlag{
+			Name:  "part-number",
+			Usage: "parts to upload with 'put part', as a list of numbers and ranges such as 1,4-6",
+		},
+		cli.StringFlag{
+			Name:  "ranges",
+			Usage: "upload the parts holding the byte ranges of a file of 'offset,length' lines with 'put part'",
+		},
+		cli.BoolFlag{
+			Name:  "session",
+			Usage: "keep a session of an interrupted upload to finish it with 'mc session resume', whatever its size",
+		},
+		cli.StringFlag{
+			Name:  "session-threshold",
+			Usage: "keep a session of interrupted recursive uploads of at least this size",
+			Value: "10GiB",
+		},
+		cli.StringFlag{
+			Name:  "session-expiry",
+			Usage: "remove sessions which are not resumed within this duration, such as 12h or 7d",
+			Value: "7d",
+		},
+		preflightFlag,
+		skipPreflightFlag,
+		maxObjectSizeFlag,
+		onConflictFlag,
+		storeChecksumFlag,
+		cli.BoolFlag{
+			Name:  "checksum",
+			Usage: "send the MD5 sum of every upload and verify the ETag returned against the uploaded bytes, for unencrypted uploads",
+		},
+		cli.IntFlag{
+			Name:  "retry-on-checksum-mismatch",
+			Usage: "with --checksum, upload an object again up to this many times when its checksum mismatches",
+		},
 	}
 )
 
@@ -52,12 +131,57 @@ var putCmd = cli.Command{
 	Action:       mainPut,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(ioFlags, globalFlags...), putFlags...),
+	Flags:        append(append(append(append(append(append(append(append(ioFlags, globalFlags...), putFlags...), pathMappingFlags...), partitionFlags...), filesFromFlags...), progressRecordFlags...), metricsFileFlags...), uploadNotifyFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
 USAGE:
-  {{.HelpName}} [FLAGS] SOURCE TARGET
+  {{.HelpName}} [FLAGS] SOURCE [SOURCE...] TARGET
+  {{.HelpName}} --files-from LIST [--base-dir DIR] [FLAGS] TARGET
+  {{.HelpName}} part --part-number PARTS [--upload-id UPLOAD-ID] [FLAGS] SOURCE TARGET
+  {{.HelpName}} part --ranges RANGES-FILE [--upload-id UPLOAD-ID] [FLAGS] SOURCE TARGET
+  {{.HelpName}} complete --upload-id UPLOAD-ID TARGET
+
+DESCRIPTION:
+  'mc cp' runs put when the sources are local and the target is remote.
+
+  A recursive upload, or an upload with --session, records its progress in a
+  session. When it is interrupted, the session is kept if --session is set or
+  the upload is larger than --session-threshold, and 'mc session resume' uploads
+  the files which are not uploaded yet, with the same flags, if they did not
+  change since. 'mc session list' shows the sessions which can be resumed.
+
+  'put part' uploads byte ranges of a local file as parts of a multipart upload,
+  part N holding the bytes from (N-1)*part-size. Without --upload-id a new upload
+  is started and its id printed before any part is sent. Several processes or
+  machines can upload different parts of the same upload, 'put complete' then
+  assembles the object from all the uploaded parts.
+
+  With --ranges, the parts to upload are those holding the byte ranges of a file
+  with one 'offset,length' pair per line, such as the ranges changed since the
+  last upload. The ranges must not overlap, must fit within SOURCE, and must start
+  and end on a part boundary or at the end of SOURCE. TARGET must already exist
+  with the size of SOURCE, the parts outside of the ranges are copied from it on
+  the server so that 'put complete' assembles the whole patched object.
+
+  With --files-from, the files listed in LIST, one path per line, are uploaded in
+  their listed order under the TARGET prefix. Blank lines and lines starting with
+  '#' are skipped. With --null, the paths end with a NUL character instead, as
+  printed by 'find -print0' or 'mc find --print0', and may hold newlines.
+  Relative paths are relative to --base-dir, the current folder by default, and
+  each file is uploaded as its path relative to --base-dir. Every
+  listed file is checked before the upload starts, unless --continue-on-error is
+  set, which uploads the other files and reports the invalid ones in their turn.
+
+  With --partition, every object is uploaded under a prefix of the target
+  formatted from a time with a Go time layout, where 2006 is the year, 01 the
+  month, 02 the day and 15 the hour. The time is that of the start of the upload
+  by default, or the modification time of each file with --partition-time mtime.
+  An object name target is uploaded under the partition in its folder.
+
+  A named pipe or a device is uploaded as a stream until its end, like stdin of
+  unknown size, and the progress of such uploads shows no total. A recursive
+  upload skips them, sockets cannot be uploaded.
 
 FLAGS:
   {{range .VisibleFlags}}{{.}}
@@ -65,6 +189,8 @@ FLAGS:
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT:      list of comma delimited prefixes
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
+  MC_NOTIFY_TOKEN: bearer token of the notifications of --notify
 
 EXAMPLES:
   1. Put an object from local file system to S3 storage
@@ -73,15 +199,85 @@ EXAMPLES:
     {{.Prompt}} {{.HelpName}} path-to/object ALIAS/BUCKET/OBJECT-NAME
   3. Put an object from local file system to S3 bucket under a prefix
     {{.Prompt}} {{.HelpName}} path-to/object ALIAS/BUCKET/PREFIX/
+  4. Put an object and only print the final summary
+    {{.Prompt}} {{.HelpName}} --summary-only path-to/object ALIAS/BUCKET/PREFIX/
+  5. Put the output of a command of a known size with a single upload
+    {{.Prompt}} tar -c path-to/dir | {{.HelpName}} --size 5GiB - ALIAS/BUCKET/OBJECT-NAME
+  6. Start a multipart upload of a disk image with its first 8 parts of 64MiB
+    {{.Prompt}} {{.HelpName}} part --part-number 1-8 --part-size 64MiB disk.img ALIAS/BUCKET/disk.img
+  7. Upload the next 8 parts from another machine
+    {{.Prompt}} {{.HelpName}} part --upload-id UPLOAD-ID --part-number 9-16 --part-size 64MiB disk.img ALIAS/BUCKET/disk.img
+  8. Complete the upload once all parts are uploaded
+    {{.Prompt}} {{.HelpName}} complete --upload-id UPLOAD-ID ALIAS/BUCKET/disk.img
+  9. Upload a folder again, skipping the files which are already uploaded and replacing the changed ones
+    {{.Prompt}} {{.HelpName}} --recursive --no-clobber --overwrite path-to/dir/ ALIAS/BUCKET/PREFIX/
+    {{.Prompt}} {{.HelpName}} --recursive --compare etag path-to/dir/ ALIAS/BUCKET/PREFIX/
+  10. Upload a large folder, keeping its progress in a file which monitoring tools can read
+    {{.Prompt}} {{.HelpName}} --recursive --progress-file /var/run/upload.progress path-to/dir/ ALIAS/BUCKET/PREFIX/
+  11. Upload a folder as 'build/x/y' under PREFIX, then as 'x/y' without its top-level folder
+    {{.Prompt}} {{.HelpName}} --recursive ./build ALIAS/BUCKET/PREFIX/
+    {{.Prompt}} {{.HelpName}} --recursive --strip-components 1 ./build ALIAS/BUCKET/PREFIX/
+  12. Upload again the parts of 64MiB holding the byte ranges of ranges.txt, such as '0,67108864' and '536870912,134217728'
+    {{.Prompt}} {{.HelpName}} part --upload-id UPLOAD-ID --ranges ranges.txt --part-size 64MiB disk.img ALIAS/BUCKET/disk.img
+  13. Upload a folder keeping a session, and finish the upload after it is interrupted
+    {{.Prompt}} {{.HelpName}} --recursive --session path-to/dir/ ALIAS/BUCKET/PREFIX/
+    {{.Prompt}} mc session resume SESSION-ID
+  14. Upload a large file without first checking that the endpoint is reachable and the bucket exists
+    {{.Prompt}} {{.HelpName}} --preflight=false disk.img ALIAS/BUCKET/disk.img
+  15. Upload the files of two folder trees directly under PREFIX, failing before any upload if two have the same name
+    {{.Prompt}} {{.HelpName}} --recursive --flatten data/2024/11/run1 data/2024/11/run2 ALIAS/BUCKET/PREFIX/
+  16. Upload a folder, failing the upload of any file larger than 50GiB
+    {{.Prompt}} {{.HelpName}} --recursive --max-object-size 50GiB path-to/dir/ ALIAS/BUCKET/PREFIX/
+  17. Upload a folder, uploading the files whose object exists as 'name-1.ext' instead of replacing it
+    {{.Prompt}} {{.HelpName}} --recursive --on-conflict rename path-to/dir/ ALIAS/BUCKET/PREFIX/
+  18. Upload a folder, writing its counters for the node exporter every minute and once done
+    {{.Prompt}} {{.HelpName}} --recursive --metrics-file /var/lib/node_exporter/upload.prom --metrics-interval 1m path-to/dir/ ALIAS/BUCKET/PREFIX/
+  19. Upload a folder, notifying a pipeline of every uploaded file
+    {{.Prompt}} MC_NOTIFY_TOKEN=TOKEN {{.HelpName}} --recursive --notify https://pipeline.example.com/hooks/upload path-to/dir/ ALIAS/BUCKET/PREFIX/
+  20. Upload a folder, storing the SHA-256 sum of every file in the metadata of its object for 'mc verify'
+    {{.Prompt}} {{.HelpName}} --recursive --store-checksum path-to/dir/ ALIAS/BUCKET/PREFIX/
+  21. Upload the files listed by a data preparation step, as their paths relative to /data
+    {{.Prompt}} {{.HelpName}} --files-from files.txt --base-dir /data ALIAS/BUCKET/PREFIX/
+    {{.Prompt}} find /data/run1 -name '*.parquet' | {{.HelpName}} --files-from - --base-dir /data --continue-on-error ALIAS/BUCKET/PREFIX/
+    {{.Prompt}} find /data/run1 -name '*.npz' -print0 | {{.HelpName}} --files-from - -0 --base-dir /data ALIAS/BUCKET/PREFIX/
+  22. Upload a folder over a flaky link, uploading a file up to 3 more times when its checksum mismatches
+    {{.Prompt}} {{.HelpName}} --recursive --checksum --retry-on-checksum-mismatch 3 path-to/dir/ ALIAS/BUCKET/PREFIX/
+  23. Upload a folder, printing a JSON progress record every 10 seconds for a dashboard
+    {{.Prompt}} {{.HelpName}} --json --progress-interval 10s --recursive path-to/dir/ ALIAS/BUCKET/PREFIX/
+  24. Upload a folder again, skipping the files whose object was uploaded after they last changed
+    {{.Prompt}} {{.HelpName}} --recursive --only-newer path-to/dir/ ALIAS/BUCKET/PREFIX/
+  25. Upload a disk image of 500GiB in parts of 16MiB, raised to 52MiB to fit in 10000 parts
+    {{.Prompt}} {{.HelpName}} --part-size 16MiB --auto-part-size disk.img ALIAS/BUCKET/disk.img
+  26. Upload a folder under a Hive-style prefix of the day of the upload, such as 'PREFIX/year=2024/month=11/day=05/'
+    {{.Prompt}} {{.HelpName}} --recursive --partition 'year=2006/month=01/day=02' path-to/dir/ ALIAS/BUCKET/PREFIX/
+  27. Upload log files under a prefix of the hour each was last modified
+    {{.Prompt}} {{.HelpName}} --recursive --partition 'dt=2006-01-02/hour=15' --partition-time mtime path-to/logs/ ALIAS/BUCKET/logs/
+  28. Upload the output of a program written to a named pipe
+    {{.Prompt}} mkfifo dump.pipe
+    {{.Prompt}} pg_dump mydb > dump.pipe &
+    {{.Prompt}} {{.HelpName}} dump.pipe ALIAS/BUCKET/mydb.sql
 `,
 }
 
 // mainPut is the entry point for put command.
 func mainPut(cliCtx *cli.Context) (e error) {
 	args := cliCtx.Args()
-	if len(args) < 2 {
+	// A local file may be named after a subcommand, the subcommands
+	// are recognized by their mandatory flags.
+	switch {
+	case args.First() == "part" && (cliCtx.IsSet("part-number") || cliCtx.IsSet("ranges")):
+		return mainPutPart(cliCtx)
+	case args.First() == "complete" && cliCtx.IsSet("upload-id"):
+		return mainPutComplete(cliCtx)
+	case cliCtx.IsSet("part-number") || cliCtx.IsSet("upload-id") || cliCtx.IsSet("ranges"):
+		fatalIf(errInvalidArgument().Trace(args...), "--part-number, --ranges and --upload-id can only be used with 'put part' and 'put complete'.")
+	}
+	filesFromPath := cliCtx.String("files-from")
+	if len(args) == 0 || (len(args) < 2 && filesFromPath == "") {
 		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
 	}
+	// gpumall targets fail fast without a valid token.
+	targetURL := getFullPath(args[len(args)-1])
 
 	ctx, cancelPut := context.WithCancel(globalContext)
 	defer cancelPut()
@@ -103,43 +299,277 @@ func mainPut(cliCtx *cli.Context) (e error) {
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
-	if len(args) < 2 {
-		fatalIf(errInvalidArgument().Trace(args...), "Invalid number of arguments.")
+	if filesFromPath != "" && len(args) != 1 {
+		fatalIf(errInvalidArgument().Trace(args...), "--files-from takes TARGET as the only argument.")
+	}
+	if filesFromPath == "" && (cliCtx.IsSet("base-dir") || cliCtx.Bool("null")) {
+		fatalIf(errInvalidArgument().Trace(args...), "--base-dir and --null can only be used with --files-from.")
 	}
 	// get source and target
 	sourceURLs := args[:len(args)-1]
-	targetURL := getFullPath(args[len(args)-1])
 
-	fmt.Println(targetURL)
+	isStdin := len(sourceURLs) == 1 && sourceURLs[0] == "-"
+	noClobber, isOverwrite := cliCtx.Bool("no-clobber"), cliCtx.Bool("overwrite")
+	if isOverwrite && !noClobber {
+		fatalIf(errInvalidArgument().Trace(args...), "--overwrite can only be used with --no-clobber.")
+	}
+	if noClobber && isStdin {
+		fatalIf(errInvalidArgument().Trace(args...), "--no-clobber cannot be used when uploading from stdin.")
+	}
+	onlyNewer := cliCtx.Bool("only-newer")
+	if onlyNewer && isStdin {
+		fatalIf(errInvalidArgument().Trace(args...), "--only-newer cannot be used when uploading from stdin.")
+	}
+	// --no-clobber compares checksums unless told otherwise, --compare
+	// alone replaces the targets which differ.
+	compare := compareChecksum
+	if compareStr := cliCtx.String("compare"); compareStr != "" {
+		var ok bool
+		if compare, ok = parseCompareMode(compareStr); !ok || compare == compareMTime {
+			fatalIf(errInvalidArgument().Trace(compareStr), "--compare should be one of checksum, etag or size.")
+		}
+		if isStdin {
+			fatalIf(errInvalidArgument().Trace(args...), "--compare cannot be used when uploading from stdin.")
+		}
+		if !noClobber {
+			noClobber, isOverwrite = true, true
+		}
+	}
+	policy := parseConflictPolicy(cliCtx)
+	if policy != conflictOverwrite && (noClobber || isStdin) {
+		fatalIf(errInvalidArgument().Trace(args...), "--on-conflict cannot be used with --no-clobber, --compare or when uploading from stdin.")
+	}
+	isRecursive, mapping := cliCtx.Bool("recursive"), parsePathMapping(cliCtx)
+	if isRecursive && filesFromPath != "" {
+		fatalIf(errInvalidArgument().Trace(args...), "--recursive cannot be used with --files-from.")
+	}
+	if mapping.isSet() && !isRecursive {
+		fatalIf(errInvalidArgument().Trace(args...), mapping.String()+" can only be used with --recursive.")
+	}
+	partition := parseTimePartition(cliCtx)
+	if partition.isSet() && isStdin {
+		fatalIf(errInvalidArgument().Trace(args...), "--partition cannot be used when uploading from stdin.")
+	}
+	streamSize := int64(-1)
+	if sizeStr := cliCtx.String("size"); sizeStr != "" {
+		if !isStdin {
+			fatalIf(errInvalidArgument().Trace(sizeStr), "--size can only be used when uploading from stdin.")
+		}
+		n, e := humanize.ParseBytes(sizeStr)
+		fatalIf(probe.NewError(e), "Unable to parse --size `"+sizeStr+"`.")
+		streamSize = int64(n)
+	}
+	maxObjectSize := parseMaxObjectSize(cliCtx)
+	autoPartSize := cliCtx.Bool("auto-part-size")
+	isSession := cliCtx.Bool("session")
+	if isSession && isStdin {
+		fatalIf(errInvalidArgument().Trace(args...), "--session cannot be used when uploading from stdin.")
+	}
+	if isSession && filesFromPath == "-" {
+		fatalIf(errInvalidArgument().Trace(args...), "--session cannot be used when reading --files-from from stdin.")
+	}
+	verifyChecksum, checksumRetries := cliCtx.Bool("checksum"), cliCtx.Int("retry-on-checksum-mismatch")
+	if checksumRetries < 0 {
+		fatalIf(errInvalidArgument().Trace(strconv.Itoa(checksumRetries)), "--retry-on-checksum-mismatch cannot be negative.")
+	}
+	if checksumRetries > 0 && !verifyChecksum {
+		fatalIf(errInvalidArgument().Trace(args...), "--retry-on-checksum-mismatch can only be used with --checksum.")
+	}
+	if checksumRetries > 0 && isStdin {
+		fatalIf(errInvalidArgument().Trace(args...), "--retry-on-checksum-mismatch cannot be used when uploading from stdin.")
+	}
+	storeChecksum := cliCtx.Bool("store-checksum")
+	if storeChecksum && isStdin {
+		fatalIf(errInvalidArgument().Trace(args...), "--store-checksum cannot be used when uploading from stdin.")
+	}
+	sessionThreshold, e := humanize.ParseBytes(cliCtx.String("session-threshold"))
+	fatalIf(probe.NewError(e), "Unable to parse --session-threshold `"+cliCtx.String("session-threshold")+"`.")
+	sessionExpiry, e := ParseDuration(cliCtx.String("session-expiry"))
+	fatalIf(probe.NewError(e), "Unable to parse --session-expiry `"+cliCtx.String("session-expiry")+"`.")
+
+	continueOnError := cliCtx.Bool("continue-on-error")
+	var filesFrom []filesFromEntry
+	if filesFromPath != "" {
+		filesFrom, err = loadFilesFrom(ctx, filesFromPath, cliCtx.String("base-dir"), cliCtx.Bool("null"))
+		fatalIf(err, "Unable to read --files-from `"+filesFromPath+"`.")
+		if invalid := invalidFilesFrom(filesFrom); len(invalid) > 0 && !continueOnError {
+			for _, entry := range invalid {
+				errorIf(entry.err, "Unable to upload `"+entry.path+"`.")
+			}
+			fatalIf(probe.NewError(fmt.Errorf("%d of the %d listed files cannot be uploaded", len(invalid), len(filesFrom))).Trace(filesFromPath),
+				"Nothing was uploaded, use --continue-on-error to upload the other files.")
+		}
+		for _, entry := range filesFrom {
+			if entry.err == nil {
+				sourceURLs = append(sourceURLs, entry.content.URL.Path)
+			}
+		}
+	}
+
+	if !cliCtx.Bool("skip-preflight") {
+		if isPreflightEnabled(cliCtx, isLargePut(sourceURLs, isRecursive)) {
+			preflightURLs := append([]string{targetURL}, sourceURLs...)
+			fatalIf(preflight(ctx, preflightURLs...), "Unable to upload to `"+targetURL+"`, use --preflight=false to skip this check.")
+			fatalIf(preflightBucket(ctx, targetURL), "Unable to upload to `"+targetURL+"`, use --preflight=false to skip this check.")
+		}
+	} else if cliCtx.IsSet("preflight") {
+		fatalIf(errInvalidArgument().Trace(args...), "--preflight cannot be used with --skip-preflight.")
+	}
+
+	isSummaryOnly := cliCtx.Bool("summary-only")
+	if checksumRetries > 0 {
+		setChecksumRetryColors()
+	}
+	if !isSummaryOnly {
+		fmt.Fprintln(statusOutput, targetURL)
+	}
 
 	putURLsCh := make(chan URLs, 10000)
 	var totalObjects, totalBytes int64
+	var hasStreams bool
 
 	// Store a progress bar or an accounter
 	var pg ProgressReader
 
 	// Enable progress bar reader only during default mode.
-	if !globalQuiet && !globalJSON { // set up progress bar
-		pg = newProgressBar(totalBytes)
+	if !globalQuiet && !globalJSON && !isSummaryOnly { // set up progress bar
+		pg = newProgressBar(totalBytes, statusOutput)
 	} else {
 		pg = newAccounter(totalBytes)
 	}
+	var progress *progressFile
+	// The progress records of --json count the objects too.
+	if progressPath := cliCtx.String("progress-file"); progressPath != "" || globalJSON {
+		progress, err = newProgressFile(progressPath, pg, progressFileInterval)
+		fatalIf(err, "Unable to write the progress file.")
+	}
+	if acct, ok := pg.(*accounter); ok {
+		pg = newProgressRecordsFromContext(cliCtx, acct, progress)
+	}
+	if records, ok := pg.(*progressRecords); ok {
+		defer records.close()
+	}
+	defer func() {
+		transferErr := e
+		if transferErr == nil {
+			transferErr = ctx.Err() // interrupted uploads are not done.
+		}
+		progress.close(transferErr)
+	}()
+	metrics := newMetricsFileFromContext(cliCtx)
+	defer metrics.close()
+	notifier := newUploadNotifierFromContext(cliCtx)
+	defer func() {
+		if notifier.close() && e == nil {
+			e = exitStatus(globalErrorExitStatus)
+		}
+	}()
+
+	if isStdin {
+		partSize, _ := humanize.ParseBytes(size)
+		partSize, err = fitPartSize("stdin", streamSize, partSize, autoPartSize)
+		fatalIf(err.Trace(targetURL), "Unable to upload from stdin.")
+		targetAlias, _ := url2Alias(targetURL)
+		start := time.Now()
+		uploadCtx, result := withUploadResult(ctx)
+		err = putStdin(uploadCtx, targetURL, streamSize, maxObjectSize, pg, PutOptions{
+			sse:              getSSE(targetURL, encKeyDB[targetAlias]),
+			multipartSize:    partSize,
+			multipartThreads: uint(threads),
+			md5:              verifyChecksum,
+		})
+		globalTransferLog.log("-", targetURL, pg.Get(), start, err)
+		targetAlias, targetPath, _ := mustExpandAlias(targetURL)
+		notifier.notify(targetAlias, targetPath, result.ETag(), pg.Get(), start, err)
+		if err == nil {
+			progress.objectDone()
+			metrics.objectTransferred(pg.Get())
+		} else {
+			metrics.objectFailed()
+		}
+		progress.close(err.ToGoError())
+		metrics.close()
+		notifyFailed := notifier.close()
+		showLastProgressBar(pg, err.ToGoError())
+		fatalIf(err.Trace(targetURL), "Unable to upload from stdin.")
+		if notifyFailed {
+			return exitStatus(globalErrorExitStatus)
+		}
+		return nil
+	}
+
+	session := putSessionToResume
+	if session == nil && (isRecursive || isSession) {
+		removeExpiredPutSessions()
+		sessionArgs, secretFlags := putSessionArgs(cliCtx)
+		session, err = newPutSession(sessionArgs, secretFlags, time.Duration(sessionExpiry))
+		fatalIf(err, "Unable to create a session.")
+	}
+	if session != nil {
+		defer func() {
+			_, _, _, plannedBytes := session.progress()
+			switch {
+			case session.isComplete():
+				errorIf(session.remove().Trace(session.header.ID), "Unable to remove the finished session.")
+			case isSession || session == putSessionToResume || uint64(plannedBytes) >= sessionThreshold:
+				errorIf(session.close().Trace(session.header.ID), "Unable to save the session.")
+				printMsg(putSessionMessage{SessionID: session.header.ID})
+			default:
+				session.remove()
+			}
+		}()
+	}
+	if compare == compareETag {
+		defer func() {
+			errorIf(getMD5Cache().save(), "Unable to save the MD5 sums of local files.")
+			printMsg(newETagCompareMessage())
+		}()
+	}
+	var conflicts *conflictResolver
+	if cliCtx.IsSet("on-conflict") {
+		setConflictColors()
+		conflicts = newConflictResolver(policy, isRecursive || len(sourceURLs) > 1)
+		defer func() { printMsg(conflicts.summaryMessage()) }()
+	}
 	go func() {
 		opts := prepareCopyURLsOpts{
 			sourceURLs:              sourceURLs,
 			targetURL:               targetURL,
 			encKeyDB:                encKeyDB,
 			ignoreBucketExistsCheck: true,
+			isRecursive:             isRecursive,
+			pathMapping:             mapping,
+			partition:               partition,
+			filesFrom:               filesFrom,
+			continueOnError:         continueOnError,
 		}
 
-		for putURLs := range preparePutURLs(ctx, opts) {
+		urlsCh := preparePutSessionURLs(ctx, session, opts)
+		if onlyNewer {
+			urlsCh = filterOnlyNewer(ctx, urlsCh, encKeyDB)
+		}
+		for putURLs := range urlsCh {
 			if putURLs.Error != nil {
 				putURLsCh <- putURLs
+				if _, ok := putURLs.Error.ToGoError().(sourceChangedErr); ok || continueOnError {
+					continue
+				}
 				break
 			}
-			totalBytes += putURLs.SourceContent.Size
-			pg.SetTotal(totalBytes)
+			size := putURLs.SourceContent.Size
+			if size < 0 {
+				// Streams have no size, the total of the upload is
+				// unknown with them and the progress shows no total.
+				hasStreams, size = true, 0
+			}
+			totalBytes += size
+			if hasStreams {
+				pg.SetTotal(0)
+			} else {
+				pg.SetTotal(totalBytes)
+			}
 			totalObjects++
+			progress.addTotal(size)
 			putURLsCh <- putURLs
 		}
 		close(putURLsCh)
@@ -154,37 +584,162 @@ func mainPut(cliCtx *cli.Context) (e error) {
 				showLastProgressBar(pg, nil)
 				return
 			}
+			if _, ok := putURLs.Error.ToGoError().(sourceChangedErr); ok {
+				errorIf(putURLs.Error.Trace(), "Unable to resume the upload.")
+				metrics.objectFailed()
+				e = exitStatus(globalErrorExitStatus)
+				continue
+			}
 			if putURLs.Error != nil {
 				printPutURLsError(&putURLs)
+				metrics.objectFailed()
+				if continueOnError {
+					e = exitStatus(globalErrorExitStatus)
+					continue
+				}
 				showLastProgressBar(pg, putURLs.Error.ToGoError())
 				return
 			}
-			urls := doCopy(ctx, doCopyOpts{
+			if putURLs.targetNewer {
+				doCopyFake(putURLs, pg)
+				progress.objectDone()
+				metrics.objectSkipped()
+				errorIf(session.complete(putURLs), "Unable to record the upload in the session.")
+				continue
+			}
+			if noClobber {
+				decision, err := noClobberDecision(ctx, putURLs, encKeyDB, compare, isOverwrite)
+				if err != nil {
+					showLastProgressBar(pg, err.ToGoError())
+					errorIf(err.Trace(putURLs.TargetContent.URL.String()), "Unable to check the target of `"+putURLs.SourceContent.URL.Path+"`.")
+					return exitStatus(globalErrorExitStatus)
+				}
+				switch decision {
+				case clobberSkip:
+					doCopyFake(putURLs, pg)
+					progress.objectDone()
+					metrics.objectSkipped()
+					errorIf(session.complete(putURLs), "Unable to record the upload in the session.")
+					continue
+				case clobberRefuse:
+					errorIf(errOverWriteNotAllowed(putURLs.TargetContent.URL.String()),
+						"Target differs from `"+putURLs.SourceContent.URL.Path+"`.")
+					doCopyFake(putURLs, pg)
+					metrics.objectFailed()
+					e = exitStatus(globalErrorExitStatus)
+					continue
+				}
+			}
+			if conflicts != nil {
+				action, resolved, err := conflicts.resolve(ctx, putURLs)
+				if err != nil {
+					showLastProgressBar(pg, err.ToGoError())
+					errorIf(err.Trace(putURLs.TargetContent.URL.String()), "Unable to check the target of `"+putURLs.SourceContent.URL.Path+"`.")
+					return exitStatus(globalErrorExitStatus)
+				}
+				if action != "" {
+					printConflict(pg, action, putURLs, resolved, isSummaryOnly)
+				}
+				switch action {
+				case conflictSkip:
+					doCopyFake(putURLs, pg)
+					progress.objectDone()
+					metrics.objectSkipped()
+					errorIf(session.complete(putURLs), "Unable to record the upload in the session.")
+					continue
+				case conflictError:
+					doCopyFake(putURLs, pg)
+					metrics.objectFailed()
+					e = exitStatus(globalErrorExitStatus)
+					continue
+				}
+				putURLs = resolved
+			}
+			putURLs.StoreChecksum = storeChecksum
+			putURLs.MD5 = verifyChecksum
+			start := time.Now()
+			urls := doCopyWithChecksumRetry(ctx, doCopyOpts{
 				cpURLs:           putURLs,
 				pg:               pg,
 				encKeyDB:         encKeyDB,
 				multipartSize:    size,
 				multipartThreads: strconv.Itoa(threads),
-			})
+				isSummaryOnly:    isSummaryOnly,
+				maxObjectSize:    maxObjectSize,
+				autoPartSize:     autoPartSize,
+			}, checksumRetries)
+			notifier.notifyURLs(urls, start)
 			if urls.Error != nil {
-				e = urls.Error.ToGoError()
-				showLastProgressBar(pg, e)
-				return
+				metrics.objectFailed()
+				if continueOnError {
+					printPutURLsError(&urls)
+					e = exitStatus(globalErrorExitStatus)
+					continue
+				}
+				showLastProgressBar(pg, urls.Error.ToGoError())
+				if globalJSON {
+					// The returned error would be printed as text, and
+					// without the request IDs of urls.Error.
+					printPutURLsError(&urls)
+					return exitStatus(globalErrorExitStatus)
+				}
+				return urls.Error.ToGoError()
 			}
+			progress.objectDone()
+			metrics.objectTransferred(urls.SourceContent.Size)
+			errorIf(session.complete(putURLs), "Unable to record the upload in the session.")
 		}
 	}
 }
 
+// clobberDecision is what --no-clobber does with an object.
+type clobberDecision int
+
+const (
+	// clobberUpload uploads an object which is missing or may be replaced.
+	clobberUpload clobberDecision = iota
+	// clobberSkip skips an object whose target has the same content.
+	clobberSkip
+	// clobberRefuse leaves a differing target alone without --overwrite.
+	clobberRefuse
+)
+
+// noClobberDecision compares the source of urls to its target, by size
+// and by checksum as compare tells when the target ETag is an MD5 sum.
+func noClobberDecision(ctx context.Context, urls URLs, encKeyDB map[string][]prefixSSEPair, compare compareMode, overwrite bool) (clobberDecision, *probe.Error) {
+	targetURL := urls.TargetContent.URL.String()
+	clnt, err := newClientFromAlias(urls.TargetAlias, targetURL)
+	if err != nil {
+		return clobberUpload, err.Trace(targetURL)
+	}
+	targetPath := filepath.ToSlash(filepath.Join(urls.TargetAlias, urls.TargetContent.URL.Path))
+	st, err := clnt.Stat(ctx, StatOptions{sse: getSSE(targetPath, encKeyDB[urls.TargetAlias])})
+	switch err.ToGoError().(type) {
+	case nil:
+	case ObjectMissing, PathNotFound:
+		return clobberUpload, nil
+	default:
+		return clobberUpload, err.Trace(targetURL)
+	}
+	if st.Size == urls.SourceContent.Size && !st.Type.IsDir() && sameSizeDiffer(compare, urls.SourceContent, st) == differInNone {
+		return clobberSkip, nil
+	}
+	if overwrite {
+		return clobberUpload, nil
+	}
+	return clobberRefuse, nil
+}
+
 func printPutURLsError(putURLs *URLs) {
 	// Print in new line and adjust to top so that we
 	// don't print over the ongoing scan bar
 	if !globalQuiet && !globalJSON {
-		console.Eraseline()
+		eraseStatusLine()
 	}
 	if strings.Contains(putURLs.Error.ToGoError().Error(),
 		" is a folder.") {
 		errorIf(putURLs.Error.Trace(),
-			"Folder cannot be copied. Please use `...` suffix.")
+			"Folder cannot be uploaded. Please use --recursive.")
 	} else {
 		errorIf(putURLs.Error.Trace(),
 			"Unable to upload.")
@@ -195,15 +750,104 @@ func showLastProgressBar(pg ProgressReader, e error) {
 	if e != nil {
 		// We only erase a line if we are displaying a progress bar
 		if !globalQuiet && !globalJSON {
-			console.Eraseline()
+			eraseStatusLine()
 		}
 		return
 	}
 	if progressReader, ok := pg.(*progressBar); ok {
 		progressReader.Finish()
 	} else {
-		if accntReader, ok := pg.(*accounter); ok {
+		if accntReader, ok := pg.(interface{ Stat() accountStat }); ok {
 			printMsg(accntReader.Stat())
 		}
 	}
 }
+
+// putStdin uploads stdin to targetURL. When size is known (>= 0) the
+// upload is sent as a single PUT, or in exactly sized parts, instead of
+// buffering multipart uploads until EOF. With a maxSize limit, stdin of
+// unknown size is first buffered in a temporary file until EOF, so that
+// a stream over the limit fails before its upload starts.
+func putStdin(ctx context.Context, targetURL string, size, maxSize int64, pg ProgressReader, opts PutOptions) *probe.Error {
+	if strings.HasSuffix(targetURL, "/") {
+		return probe.NewError(errors.New("target must be an object name when uploading from stdin")).Trace(targetURL)
+	}
+	alias, urlStrFull, _, err := expandAlias(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+
+	var reader io.Reader = os.Stdin
+	switch {
+	case size >= 0:
+		if err = checkMaxObjectSize("stdin", size, maxSize); err != nil {
+			return err.Trace(targetURL)
+		}
+		pg.SetTotal(size)
+		reader = newSizeCheckReader(reader, size)
+	case maxSize > 0:
+		f, n, e := spoolStream(reader, maxSize+1)
+		if e != nil {
+			return probe.NewError(e).Trace(targetURL)
+		}
+		defer removeTempFile(f)
+		if n > maxSize {
+			return errObjectTooLarge("stdin", -1, maxSize).Trace(targetURL)
+		}
+		size = n
+		pg.SetTotal(size)
+		reader = io.NewSectionReader(f, 0, size)
+	}
+	opts.metadata = map[string]string{"Content-Type": guessURLContentType(targetURL)}
+	_, err = putTargetStream(ctx, alias, urlStrFull, "", "", "", reader, size, pg, opts)
+	return err
+}
+
+// sizeCheckReader fails reads of a stream which is shorter or longer
+// than its declared size. The check for a longer stream is done before
+// handing over the last bytes, so that a request sending exactly size
+// bytes fails instead of silently truncating the stream.
+type sizeCheckReader struct {
+	reader io.Reader
+	size   int64
+	read   int64
+}
+
+func newSizeCheckReader(reader io.Reader, size int64) io.Reader {
+	return &sizeCheckReader{reader: reader, size: size}
+}
+
+func (s *sizeCheckReader) Read(p []byte) (n int, err error) {
+	remaining := s.size - s.read
+	if remaining == 0 {
+		return 0, s.checkEOF()
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err = s.reader.Read(p)
+	s.read += int64(n)
+	if err == io.EOF && s.read < s.size {
+		return n, fmt.Errorf("stream ended after %d bytes, shorter than the declared size of %d bytes", s.read, s.size)
+	}
+	if err == nil && s.read == s.size {
+		if e := s.checkEOF(); e != io.EOF {
+			return 0, e
+		}
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// checkEOF returns io.EOF if the underlying stream has no more data.
+func (s *sizeCheckReader) checkEOF() error {
+	var b [1]byte
+	n, e := io.ReadFull(s.reader, b[:])
+	if n > 0 {
+		return fmt.Errorf("stream is longer than the declared size of %d bytes", s.size)
+	}
+	if e == io.ErrUnexpectedEOF {
+		e = io.EOF
+	}
+	return e
+}
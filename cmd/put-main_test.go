@@ -0,0 +1,202 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestSizeCheckReader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		size    int64
+		errText string
+	}{
+		{name: "declared size", data: "hello world", size: 11},
+		{name: "empty stream", data: "", size: 0},
+		{name: "under-declared size", data: "hello world", size: 5, errText: "longer than the declared size of 5 bytes"},
+		{name: "over-declared size", data: "hello", size: 11, errText: "ended after 5 bytes, shorter than the declared size of 11 bytes"},
+	}
+
+	for _, testCase := range testCases {
+		// io.ReadFull is what multipart uploads use to fill a part.
+		buf := make([]byte, testCase.size)
+		_, err := io.ReadFull(newSizeCheckReader(strings.NewReader(testCase.data), testCase.size), buf)
+		if testCase.errText == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error %v", testCase.name, err)
+			}
+			if string(buf) != testCase.data {
+				t.Fatalf("%s: expected %q, got %q", testCase.name, testCase.data, buf)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), testCase.errText) {
+			t.Fatalf("%s: expected error %q, got %v", testCase.name, testCase.errText, err)
+		}
+	}
+}
+
+func TestPutDeclaredSize(t *testing.T) {
+	// Failed uploads are not worth retrying here.
+	defer func(maxRetry int) { minio.MaxRetry = maxRetry }(minio.MaxRetry)
+	minio.MaxRetry = 1
+
+	newS3TestServer(t)
+	s3c := newS3TestClient(t, "s3test/bucket/object")
+
+	testCases := []struct {
+		name    string
+		data    string
+		size    int64
+		success bool
+	}{
+		{name: "declared size", data: "hello world", size: 11, success: true},
+		{name: "under-declared size", data: "hello world, and more", size: 11},
+		{name: "over-declared size", data: "hello", size: 11},
+	}
+	for _, testCase := range testCases {
+		reader := newSizeCheckReader(bytes.NewReader([]byte(testCase.data)), testCase.size)
+		_, err := s3c.Put(context.Background(), reader, testCase.size, nil, PutOptions{metadata: map[string]string{}})
+		if testCase.success && err != nil {
+			t.Fatalf("%s: unexpected error %v", testCase.name, err)
+		}
+		if !testCase.success && err == nil {
+			t.Fatalf("%s: expected the upload to fail", testCase.name)
+		}
+	}
+}
+
+func TestNoClobberDecision(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.PutObject("bucket", "same.txt", []byte("hello world"))
+	server.PutObject("bucket", "changed.txt", []byte("hello there"))
+	server.PutMultipartObject("bucket", "multi.txt", []byte("hello "), []byte("there"))
+	t.Setenv(mcEnvHostPrefix+"noclobber", server.AliasURL())
+
+	dir := t.TempDir()
+	defer func(c *md5Cache, stats etagCompareStats) {
+		globalMD5Cache, globalETagCompareStats = c, stats
+	}(globalMD5Cache, globalETagCompareStats)
+	globalMD5Cache = loadMD5Cache("")
+	globalETagCompareStats = etagCompareStats{}
+
+	for _, testCase := range []struct {
+		name      string
+		data      string
+		compare   compareMode
+		overwrite bool
+		expected  clobberDecision
+	}{
+		{name: "same.txt", data: "hello world", expected: clobberSkip},
+		{name: "changed.txt", data: "hello world", expected: clobberRefuse},
+		{name: "changed.txt", data: "hello world", overwrite: true, expected: clobberUpload},
+		{name: "longer.txt", data: "hello world!", expected: clobberUpload},
+		{name: "same.txt", data: "hello world!", expected: clobberRefuse},
+		{name: "changed.txt", data: "hello world", compare: compareSize, expected: clobberSkip},
+		{name: "same.txt", data: "hello world", compare: compareETag, expected: clobberSkip},
+		{name: "changed.txt", data: "hello world", compare: compareETag, overwrite: true, expected: clobberUpload},
+		{name: "multi.txt", data: "hello world", compare: compareETag, expected: clobberSkip},
+	} {
+		if testCase.compare == "" {
+			testCase.compare = compareChecksum
+		}
+		source := filepath.Join(dir, testCase.name)
+		if e := os.WriteFile(source, []byte(testCase.data), 0o600); e != nil {
+			t.Fatal(e)
+		}
+		_, targetURL, _ := mustExpandAlias("noclobber/bucket/" + testCase.name)
+		urls := URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(source), Size: int64(len(testCase.data))},
+			TargetAlias:   "noclobber",
+			TargetContent: &ClientContent{URL: *newClientURL(targetURL)},
+		}
+		decision, err := noClobberDecision(context.Background(), urls, nil, testCase.compare, testCase.overwrite)
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.name, err)
+		}
+		if decision != testCase.expected {
+			t.Errorf("%s %q (compare %s, overwrite %v): expected decision %d, got %d", testCase.name, testCase.data, testCase.compare, testCase.overwrite, testCase.expected, decision)
+		}
+	}
+	// The multipart ETag is compared by size only, and reported apart.
+	if msg := newETagCompareMessage(); msg.SkippedIdentical != 1 || msg.SkippedBySameSize != 1 {
+		t.Errorf("unexpected skipped counts %+v", msg)
+	}
+}
+
+func TestMD5Cache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	if e := os.WriteFile(path, []byte("hello world"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if e := os.Chtimes(path, modTime, modTime); e != nil {
+		t.Fatal(e)
+	}
+	content := &ClientContent{URL: *newClientURL(path), Size: 11, Time: modTime}
+	expected := fmt.Sprintf("%x", md5.Sum([]byte("hello world")))
+
+	cachePath := filepath.Join(dir, md5CacheFile)
+	cache := loadMD5Cache(cachePath)
+	if sum, err := cache.sum(content); err != nil || sum != expected {
+		t.Fatalf("unexpected sum %q: %v", sum, err)
+	}
+	if err := cache.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A same size change keeping the modification time is not noticed,
+	// the sum comes from the saved cache.
+	if e := os.WriteFile(path, []byte("hello there"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.Chtimes(path, modTime, modTime); e != nil {
+		t.Fatal(e)
+	}
+	cache = loadMD5Cache(cachePath)
+	if sum, err := cache.sum(content); err != nil || sum != expected {
+		t.Fatalf("expected the cached sum, got %q: %v", sum, err)
+	}
+
+	// A new modification time computes the sum again.
+	modTime = modTime.Add(time.Second)
+	if e := os.Chtimes(path, modTime, modTime); e != nil {
+		t.Fatal(e)
+	}
+	content.Time = modTime
+	if sum, err := cache.sum(content); err != nil || sum != fmt.Sprintf("%x", md5.Sum([]byte("hello there"))) {
+		t.Fatalf("expected a new sum, got %q: %v", sum, err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+)
+
+// onlyNewerConcurrency bounds the HEAD requests sent at once by
+// put --only-newer.
+const onlyNewerConcurrency = 16
+
+// isTargetNewer returns true if target need not be replaced by source:
+// it was modified after source, or in the same second with the same
+// size. Object times have a precision of one second.
+func isTargetNewer(source, target *ClientContent) bool {
+	sourceTime := source.Time.Truncate(time.Second)
+	targetTime := target.Time.Truncate(time.Second)
+	if targetTime.After(sourceTime) {
+		return true
+	}
+	return targetTime.Equal(sourceTime) && target.Size == source.Size
+}
+
+// checkTargetNewer sets targetNewer on urls if its target exists and is
+// newer than its source.
+func checkTargetNewer(ctx context.Context, urls URLs, encKeyDB map[string][]prefixSSEPair) URLs {
+	targetURL := urls.TargetContent.URL.String()
+	clnt, err := newClientFromAlias(urls.TargetAlias, targetURL)
+	if err != nil {
+		return urls.WithError(err.Trace(targetURL))
+	}
+	targetPath := filepath.ToSlash(filepath.Join(urls.TargetAlias, urls.TargetContent.URL.Path))
+	st, err := clnt.Stat(ctx, StatOptions{sse: getSSE(targetPath, encKeyDB[urls.TargetAlias])})
+	switch err.ToGoError().(type) {
+	case nil:
+	case ObjectMissing, PathNotFound:
+		return urls
+	default:
+		return urls.WithError(err.Trace(targetURL))
+	}
+	urls.targetNewer = !st.Type.IsDir() && isTargetNewer(urls.SourceContent, st)
+	return urls
+}
+
+// filterOnlyNewer checks the targets of the URLs of urlsCh for
+// --only-newer. At most onlyNewerConcurrency HEAD requests are in
+// flight, URLs are sent in the order of urlsCh.
+func filterOnlyNewer(ctx context.Context, urlsCh <-chan URLs, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
+	pending := make(chan chan URLs, onlyNewerConcurrency)
+	slots := make(chan struct{}, onlyNewerConcurrency)
+	go func() {
+		defer close(pending)
+		for urls := range urlsCh {
+			result := make(chan URLs, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+			if urls.Error != nil {
+				result <- urls
+				continue
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(urls URLs) {
+				defer func() { <-slots }()
+				result <- checkTargetNewer(ctx, urls, encKeyDB)
+			}(urls)
+		}
+	}()
+
+	checkedCh := make(chan URLs)
+	go func() {
+		defer close(checkedCh)
+		for result := range pending {
+			var urls URLs
+			select {
+			case urls = <-result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case checkedCh <- urls:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return checkedCh
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestFilterOnlyNewer(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.PutObject("bucket", "uploaded.txt", []byte("hello world"))
+	t.Setenv(mcEnvHostPrefix+"onlynewer", server.AliasURL())
+
+	now := time.Now()
+	testCases := []struct {
+		name     string
+		object   string
+		modTime  time.Time
+		size     int64
+		err      bool
+		expected bool
+	}{
+		{name: "remote newer", object: "uploaded.txt", modTime: now.Add(-time.Hour), size: 5, expected: true},
+		{name: "local newer", object: "uploaded.txt", modTime: now.Add(time.Hour), size: 11},
+		{name: "no remote", object: "missing.txt", modTime: now.Add(-time.Hour), size: 11},
+		{name: "listing error", err: true},
+	}
+	urlsCh := make(chan URLs)
+	go func() {
+		defer close(urlsCh)
+		for _, testCase := range testCases {
+			if testCase.err {
+				urlsCh <- URLs{Error: probe.NewError(errors.New(testCase.name))}
+				continue
+			}
+			_, targetURL, _ := mustExpandAlias("onlynewer/bucket/" + testCase.object)
+			urlsCh <- URLs{
+				SourceContent: &ClientContent{URL: *newClientURL("/tmp/" + testCase.object), Size: testCase.size, Time: testCase.modTime},
+				TargetAlias:   "onlynewer",
+				TargetContent: &ClientContent{URL: *newClientURL(targetURL)},
+			}
+		}
+	}()
+
+	// The URLs keep their order.
+	i := 0
+	for urls := range filterOnlyNewer(context.Background(), urlsCh, nil) {
+		testCase := testCases[i]
+		i++
+		if testCase.err {
+			if urls.Error == nil || urls.Error.ToGoError().Error() != testCase.name {
+				t.Errorf("%s: expected the error to pass through, got %v", testCase.name, urls.Error)
+			}
+			continue
+		}
+		if urls.Error != nil {
+			t.Errorf("%s: %v", testCase.name, urls.Error)
+			continue
+		}
+		if urls.TargetContent.URL.Path != "/bucket/"+testCase.object {
+			t.Errorf("%s: expected the target %s, got %s", testCase.name, testCase.object, urls.TargetContent.URL.Path)
+		}
+		if urls.targetNewer != testCase.expected {
+			t.Errorf("%s: expected targetNewer %v, got %v", testCase.name, testCase.expected, urls.targetNewer)
+		}
+	}
+	if i != len(testCases) {
+		t.Fatalf("expected %d URLs, got %d", len(testCases), i)
+	}
+}
+
+func TestIsTargetNewer(t *testing.T) {
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+	for _, testCase := range []struct {
+		targetTime time.Time
+		targetSize int64
+		expected   bool
+	}{
+		{mtime.Add(time.Second), 1, true},
+		{mtime.Add(-time.Second), 10, false},
+		// Object times are truncated to the second.
+		{mtime.Truncate(time.Second), 10, true},
+		{mtime.Truncate(time.Second), 1, false},
+	} {
+		source := &ClientContent{Time: mtime, Size: 10}
+		target := &ClientContent{Time: testCase.targetTime, Size: testCase.targetSize}
+		if got := isTargetNewer(source, target); got != testCase.expected {
+			t.Errorf("target of %s and %d bytes: expected %v, got %v", testCase.targetTime, testCase.targetSize, testCase.expected, got)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// maxPartNumber is the highest part number of a multipart upload.
+const maxPartNumber = 10000
+
+// putPartMessage is printed for every part uploaded by `put part`.
+type putPartMessage struct {
+	Status     string `json:"status"`
+	Key        string `json:"key"`
+	UploadID   string `json:"uploadId"`
+	PartNumber int    `json:"partNumber"`
+	Offset     int64  `json:"offset"`
+	Size       int64  `json:"size"`
+	ETag       string `json:"etag"`
+	Copied     bool   `json:"copied,omitempty"`
+}
+
+func (p putPartMessage) String() string {
+	if p.Copied {
+		return fmt.Sprintf("Copied part %d (%s at offset %d) of `%s` to upload `%s`.",
+			p.PartNumber, formatSize(p.Size), p.Offset, p.Key, p.UploadID)
+	}
+	return fmt.Sprintf("Uploaded part %d (%s at offset %d) of `%s` to upload `%s`.",
+		p.PartNumber, formatSize(p.Size), p.Offset, p.Key, p.UploadID)
+}
+
+func (p putPartMessage) JSON() string {
+	p.Status = "success"
+	msgBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// putUploadMessage is printed by `put part` once it started a new
+// multipart upload, before any part is sent, so that the upload can be
+// resumed or aborted even if its first part fails.
+type putUploadMessage struct {
+	Status   string `json:"status"`
+	Key      string `json:"key"`
+	UploadID string `json:"uploadId"`
+}
+
+func (p putUploadMessage) String() string {
+	return fmt.Sprintf("Started upload `%s` of `%s`.", p.UploadID, p.Key)
+}
+
+func (p putUploadMessage) JSON() string {
+	p.Status = "success"
+	msgBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// putCompleteMessage is printed by `put complete`.
+type putCompleteMessage struct {
+	Status   string `json:"status"`
+	Key      string `json:"key"`
+	UploadID string `json:"uploadId"`
+	ETag     string `json:"etag"`
+	Parts    int    `json:"parts"`
+}
+
+func (p putCompleteMessage) String() string {
+	return fmt.Sprintf("Completed upload `%s` of `%s` with %d parts.", p.UploadID, p.Key, p.Parts)
+}
+
+func (p putCompleteMessage) JSON() string {
+	p.Status = "success"
+	msgBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// parsePartNumbers parses a comma separated list of part numbers and
+// ranges of part numbers, such as "1,4-6", into sorted part numbers.
+func parsePartNumbers(s string) ([]int, error) {
+	seen := map[int]bool{}
+	var parts []int
+	for _, field := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(field), "-")
+		from, e := strconv.Atoi(first)
+		if e != nil {
+			return nil, fmt.Errorf("invalid part number `%s`", field)
+		}
+		to := from
+		if isRange {
+			if to, e = strconv.Atoi(last); e != nil || to < from {
+				return nil, fmt.Errorf("invalid part range `%s`", field)
+			}
+		}
+		if from < 1 || to > maxPartNumber {
+			return nil, fmt.Errorf("part numbers must be between 1 and %d, got `%s`", maxPartNumber, field)
+		}
+		for n := from; n <= to; n++ {
+			if !seen[n] {
+				seen[n] = true
+				parts = append(parts, n)
+			}
+		}
+	}
+	sort.Ints(parts)
+	return parts, nil
+}
+
+// uploadFileParts uploads the given parts of the file at path to a
+// multipart upload, part N holds the bytes of the file starting at
+// (N-1)*partSize. done is called after each uploaded part.
+func uploadFileParts(ctx context.Context, clnt Client, uploadID, path string, partNumbers []int, partSize int64, opts PutOptions, done func(putPartMessage)) *probe.Error {
+	f, e := os.Open(path)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	defer f.Close()
+	st, e := f.Stat()
+	if e != nil {
+		return probe.NewError(e)
+	}
+	for _, partNumber := range partNumbers {
+		offset := int64(partNumber-1) * partSize
+		if offset >= st.Size() {
+			return probe.NewError(fmt.Errorf("part %d starts at offset %d, past the end of `%s`", partNumber, offset, path))
+		}
+		size := partSize
+		if offset+size > st.Size() {
+			size = st.Size() - offset
+		}
+		etag, err := clnt.UploadPart(ctx, uploadID, partNumber, io.NewSectionReader(f, offset, size), size, opts)
+		if err != nil {
+			return err.Trace(path, strconv.Itoa(partNumber))
+		}
+		done(putPartMessage{
+			UploadID:   uploadID,
+			PartNumber: partNumber,
+			Offset:     offset,
+			Size:       size,
+			ETag:       strings.Trim(etag, "\""),
+		})
+	}
+	return nil
+}
+
+// rangesPartNumbers returns the numbers of the parts of partSize bytes
+// of the file at path holding the byte ranges of rangesFile.
+func rangesPartNumbers(rangesFile, path string, partSize int64) ([]int, *probe.Error) {
+	ranges, err := readByteRangeSpec(rangesFile)
+	if err != nil {
+		return nil, err
+	}
+	st, e := os.Stat(path)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	parts, e := byteRangeParts(ranges, partSize, st.Size())
+	if e != nil {
+		return nil, probe.NewError(e).Trace(rangesFile, path)
+	}
+	return parts, nil
+}
+
+// uploadByteRangeParts uploads the parts of the file at path holding
+// the byte ranges of rangesFile, and copies every other part missing
+// from the upload from the object being patched on the server, so that
+// the completed upload holds the whole file and not only its ranges.
+// The object must have the size of the file.
+func uploadByteRangeParts(ctx context.Context, clnt Client, uploadID, path, rangesFile string, partSize int64, opts PutOptions, done func(putPartMessage)) *probe.Error {
+	st, e := os.Stat(path)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	object, err := clnt.Stat(ctx, StatOptions{sse: opts.sse})
+	if err != nil {
+		return err.Trace(path)
+	}
+	if object.Size != st.Size() {
+		return probe.NewError(fmt.Errorf("the object has %d bytes and `%s` %d, --ranges only patches an object of the size of the file", object.Size, path, st.Size()))
+	}
+	partNumbers, err := rangesPartNumbers(rangesFile, path, partSize)
+	if err != nil {
+		return err
+	}
+	if err = uploadFileParts(ctx, clnt, uploadID, path, partNumbers, partSize, opts, done); err != nil {
+		return err
+	}
+
+	uploaded, err := clnt.UploadedParts(ctx, uploadID)
+	if err != nil {
+		return err.Trace(uploadID)
+	}
+	isUploaded := make(map[int]bool, len(uploaded))
+	for _, partNumber := range uploaded {
+		isUploaded[partNumber] = true
+	}
+	for partNumber, offset := 1, int64(0); offset < st.Size(); partNumber, offset = partNumber+1, offset+partSize {
+		if isUploaded[partNumber] {
+			continue
+		}
+		size := partSize
+		if offset+size > st.Size() {
+			size = st.Size() - offset
+		}
+		etag, err := clnt.CopyPart(ctx, uploadID, partNumber, offset, size, object.ETag, opts)
+		if err != nil {
+			return err.Trace(strconv.Itoa(partNumber))
+		}
+		done(putPartMessage{
+			UploadID:   uploadID,
+			PartNumber: partNumber,
+			Offset:     offset,
+			Size:       size,
+			ETag:       strings.Trim(etag, "\""),
+			Copied:     true,
+		})
+	}
+	return nil
+}
+
+// mainPutPart uploads parts of a local file to a multipart upload,
+// starting a new upload unless --upload-id is given.
+func mainPutPart(cliCtx *cli.Context) error {
+	args := cliCtx.Args().Tail()
+	if len(args) != 2 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	partSize, e := humanize.ParseBytes(cliCtx.String("part-size"))
+	fatalIf(probe.NewError(e), "Unable to parse part size")
+	if partSize == 0 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("part-size")), "Part size should be greater than 0.")
+	}
+	if cliCtx.Bool("auto-part-size") {
+		fatalIf(errInvalidArgument().Trace(args...), "--auto-part-size cannot be used with 'put part', all the parts of an upload have the same size.")
+	}
+	if st, e := os.Stat(args[0]); e == nil {
+		_, err := fitPartSize(args[0], st.Size(), partSize, false)
+		fatalIf(err.Trace(args[0]), "Unable to upload `"+args[0]+"` in parts.")
+	}
+	rangesFile := cliCtx.String("ranges")
+	var partNumbers []int
+	if rangesFile != "" {
+		if cliCtx.IsSet("part-number") {
+			fatalIf(errInvalidArgument().Trace(rangesFile), "--ranges and --part-number cannot be used together.")
+		}
+		// The ranges are checked against the file before any upload.
+		_, err := rangesPartNumbers(rangesFile, args[0], int64(partSize))
+		fatalIf(err, "Unable to select the parts holding the ranges of `"+rangesFile+"`.")
+	} else {
+		partNumbers, e = parsePartNumbers(cliCtx.String("part-number"))
+		fatalIf(probe.NewError(e), "Invalid --part-number.")
+	}
+	// gpumall targets fail fast without a valid token.
+	targetURL := getFullPath(args[1])
+
+	ctx, cancelPut := context.WithCancel(globalContext)
+	defer cancelPut()
+
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	if strings.HasSuffix(targetURL, "/") {
+		fatalIf(probe.NewError(errors.New("target must be an object name")).Trace(args[1]), "Invalid target.")
+	}
+	clnt, err := newClient(targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+args[1]+"`.")
+	defer clnt.Close()
+	targetAlias, _ := url2Alias(targetURL)
+	opts := PutOptions{sse: getSSE(targetURL, encKeyDB[targetAlias])}
+
+	uploadID := cliCtx.String("upload-id")
+	if uploadID == "" {
+		uploadID, err = clnt.NewMultipartUpload(ctx, opts)
+		fatalIf(err.Trace(targetURL), "Unable to start a multipart upload of `"+args[1]+"`.")
+		printMsg(putUploadMessage{Key: args[1], UploadID: uploadID})
+	}
+	printPart := func(msg putPartMessage) {
+		msg.Key = args[1]
+		printMsg(msg)
+	}
+	if rangesFile != "" {
+		err = uploadByteRangeParts(ctx, clnt, uploadID, args[0], rangesFile, int64(partSize), opts, printPart)
+	} else {
+		err = uploadFileParts(ctx, clnt, uploadID, args[0], partNumbers, int64(partSize), opts, printPart)
+	}
+	fatalIf(err.Trace(targetURL), "Unable to upload the parts of `"+args[0]+"` to upload `"+uploadID+"`.")
+	return nil
+}
+
+// mainPutComplete completes a multipart upload with all of its parts.
+func mainPutComplete(cliCtx *cli.Context) error {
+	args := cliCtx.Args().Tail()
+	if len(args) != 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	// gpumall targets fail fast without a valid token.
+	targetURL := getFullPath(args[0])
+
+	ctx, cancelPut := context.WithCancel(globalContext)
+	defer cancelPut()
+
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	clnt, err := newClient(targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+args[0]+"`.")
+	defer clnt.Close()
+
+	targetAlias, _ := url2Alias(targetURL)
+	uploadID := cliCtx.String("upload-id")
+	etag, parts, err := clnt.CompleteMultipartUpload(ctx, uploadID, PutOptions{sse: getSSE(targetURL, encKeyDB[targetAlias])})
+	fatalIf(err.Trace(targetURL), "Unable to complete upload `"+uploadID+"` of `"+args[0]+"`.")
+	printMsg(putCompleteMessage{
+		Key:      args[0],
+		UploadID: uploadID,
+		ETag:     strings.Trim(etag, "\""),
+		Parts:    parts,
+	})
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParsePartNumbers(t *testing.T) {
+	for _, testCase := range []struct {
+		parts    string
+		expected []int
+		fail     bool
+	}{
+		{parts: "3", expected: []int{3}},
+		{parts: "4-6,1", expected: []int{1, 4, 5, 6}},
+		{parts: "1-2,2-3", expected: []int{1, 2, 3}},
+		{parts: "0", fail: true},
+		{parts: "5-4", fail: true},
+		{parts: "1-10001", fail: true},
+		{parts: "a", fail: true},
+	} {
+		got, e := parsePartNumbers(testCase.parts)
+		if (e != nil) != testCase.fail {
+			t.Fatalf("%s: unexpected error %v", testCase.parts, e)
+		}
+		if !testCase.fail && !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%s: expected %v, got %v", testCase.parts, testCase.expected, got)
+		}
+	}
+}
+
+func TestUploadFilePartsFromTwoProcesses(t *testing.T) {
+	server := newS3TestServer(t)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4000)
+	path := filepath.Join(t.TempDir(), "disk.img")
+	if e := os.WriteFile(path, data, 0o600); e != nil {
+		t.Fatal(e)
+	}
+
+	newTestClient := func() Client {
+		return newS3TestClient(t, "s3test/bucket/object")
+	}
+	ctx := context.Background()
+
+	uploadID, err := newTestClient().NewMultipartUpload(ctx, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first part is not enough to complete the upload.
+	const partSize = 10000
+	if err := uploadFileParts(ctx, newTestClient(), uploadID, path, []int{3}, partSize, PutOptions{}, func(putPartMessage) {}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = newTestClient().CompleteMultipartUpload(ctx, uploadID, PutOptions{})
+	if missing, ok := err.ToGoError().(UploadPartMissing); !ok || missing.PartNumber != 1 {
+		t.Fatalf("expected part 1 to be missing, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var uploaded []int
+	for _, partNumbers := range [][]int{{1, 2}, {4, 5, 6, 7}} {
+		wg.Add(1)
+		go func(partNumbers []int) {
+			defer wg.Done()
+			err := uploadFileParts(ctx, newTestClient(), uploadID, path, partNumbers, partSize, PutOptions{}, func(msg putPartMessage) {
+				mu.Lock()
+				uploaded = append(uploaded, msg.PartNumber)
+				mu.Unlock()
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(partNumbers)
+	}
+	wg.Wait()
+	if len(uploaded) != 6 {
+		t.Fatalf("expected 6 uploaded parts, got %v", uploaded)
+	}
+
+	// Part 8 would start past the end of the file.
+	if err := uploadFileParts(ctx, newTestClient(), uploadID, path, []int{8}, partSize, PutOptions{}, func(putPartMessage) {}); err == nil {
+		t.Fatal("expected part 8 to be rejected")
+	}
+
+	etag, parts, err := newTestClient().CompleteMultipartUpload(ctx, uploadID, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	object, _ := server.Object("bucket", "object")
+	if parts != 7 || !bytes.Equal(object.Data, data) {
+		t.Fatalf("unexpected object of %d parts and %d bytes", parts, len(object.Data))
+	}
+	hasher := newPartETagHasher(partSize)
+	hasher.Write(data)
+	if expected := hasher.etag(true); strings.Trim(etag, "\"") != expected {
+		t.Fatalf("expected ETag %s, got %s", expected, etag)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+)
+
+// partitionFlags upload the files under a prefix derived from a time.
+var partitionFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "partition",
+		Usage: "upload under a prefix of this Go time layout, such as 'year=2006/month=01/day=02', in UTC",
+	},
+	cli.StringFlag{
+		Name:  "partition-time",
+		Usage: "time of the --partition prefix, the modification time of each file (mtime) or the start of the upload (now)",
+		Value: "now",
+	},
+}
+
+// timePartition prefixes the keys of uploaded objects with a time
+// formatted with layout, as set by --partition and --partition-time.
+// The time is the modification time of each file with useMTime, now
+// otherwise, so that all the files of an upload share the same prefix.
+type timePartition struct {
+	layout   string
+	useMTime bool
+	now      time.Time
+}
+
+// parseTimePartition returns the time partition of the flags of cliCtx.
+func parseTimePartition(cliCtx *cli.Context) timePartition {
+	p := timePartition{layout: strings.Trim(cliCtx.String("partition"), "/"), now: time.Now().UTC()}
+	switch timeSource := cliCtx.String("partition-time"); timeSource {
+	case "mtime":
+		p.useMTime = true
+	case "now":
+	default:
+		fatalIf(errInvalidArgument().Trace(timeSource), "--partition-time should be mtime or now.")
+	}
+	if p.layout == "" {
+		if cliCtx.IsSet("partition") || cliCtx.IsSet("partition-time") {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--partition should be a time layout such as 'year=2006/month=01/day=02'.")
+		}
+		return p
+	}
+	for _, component := range strings.Split(p.layout, "/") {
+		if component == "" || component == "." || component == ".." {
+			fatalIf(errInvalidArgument().Trace(p.layout), "--partition should be a relative path without empty, '.' or '..' folders.")
+		}
+	}
+	return p
+}
+
+func (p timePartition) isSet() bool {
+	return p.layout != ""
+}
+
+// prefix returns the partition of an object uploaded from source.
+func (p timePartition) prefix(source *ClientContent) string {
+	t := p.now
+	if p.useMTime {
+		t = source.Time.UTC()
+	}
+	return t.Format(p.layout)
+}
+
+// partitionKey inserts partition in objectPath after the path of the
+// target. An object uploaded to an object name, where objectPath is the
+// target path, is partitioned in the folder of that name.
+func partitionKey(targetPath, objectPath, partition string) string {
+	if objectPath == targetPath {
+		dir, base := path.Split(objectPath)
+		return dir + partition + "/" + base
+	}
+	if !strings.HasSuffix(targetPath, "/") {
+		targetPath += "/"
+	}
+	return targetPath + partition + "/" + strings.TrimPrefix(objectPath, targetPath)
+}
+
+// apply moves the target of urls under its partition, targetPath is the
+// path of the expanded target URL of the upload.
+func (p timePartition) apply(targetPath string, urls URLs) URLs {
+	if !p.isSet() || urls.Error != nil {
+		return urls
+	}
+	targetContent := *urls.TargetContent
+	targetContent.URL.Path = partitionKey(targetPath, targetContent.URL.Path, p.prefix(urls.SourceContent))
+	urls.TargetContent = &targetContent
+	return urls
+}
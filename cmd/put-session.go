@@ -0,0 +1,647 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// A put session lives in its own folder under the session folder, with
+// three files:
+//
+//   - session.json, the arguments of the put and where it ran.
+//   - manifest, a JSON line per planned transfer, appended while the
+//     source is listed.
+//   - journal, the line number in the manifest of every completed
+//     transfer, appended as they finish.
+//
+// Both the manifest and the journal are only appended to, with a single
+// write per line, so that a killed put leaves at worst a torn last line,
+// which is ignored and truncated when the session is loaded again. Every
+// journal line is synced to disk, after the manifest lines it refers to,
+// so that a crash of the machine does not lose completed transfers.
+//
+// The secret flags of the put, such as --encrypt-key, are not saved and
+// have to be given again to 'session resume'.
+const (
+	putSessionsDir       = "put"
+	putSessionHeaderFile = "session.json"
+	putSessionManifest   = "manifest"
+	putSessionJournal    = "journal"
+	putSessionVersion    = "1"
+)
+
+// putSessionHeader is the session.json of a put session.
+type putSessionHeader struct {
+	Version       string    `json:"version"`
+	ID            string    `json:"id"`
+	Created       time.Time `json:"created"`
+	Expires       time.Time `json:"expires"`
+	WorkingFolder string    `json:"workingFolder"`
+	Args          []string  `json:"args"`
+	// SecretFlags are the flags of the put left out of Args.
+	SecretFlags []string `json:"secretFlags,omitempty"`
+	// Planned is set once every transfer of the put is in the manifest.
+	Planned bool `json:"planned"`
+}
+
+// putSessionEntry is a manifest line, the upload of a local file.
+type putSessionEntry struct {
+	Source      string    `json:"source"`
+	TargetAlias string    `json:"targetAlias"`
+	Target      string    `json:"target"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+}
+
+// putSession is a resumable put.
+type putSession struct {
+	dir    string
+	header putSessionHeader
+
+	mu       sync.Mutex
+	manifest *os.File
+	journal  *os.File
+	entries  []putSessionEntry
+	// synced is the number of entries synced to the manifest on disk.
+	synced int
+	// index maps the sources to their entry.
+	index map[string]int
+	done  map[int]bool
+}
+
+// putSessionMessage is printed when a put keeps its session.
+type putSessionMessage struct {
+	Status    string `json:"status"`
+	SessionID string `json:"sessionId"`
+}
+
+func (p putSessionMessage) String() string {
+	return "Upload is not finished, resume it with `mc session resume " + p.SessionID + "`."
+}
+
+func (p putSessionMessage) JSON() string {
+	p.Status = "success"
+	msgBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// putSessionToResume is the session run by 'session resume'.
+var putSessionToResume *putSession
+
+// getPutSessionsDir returns the folder of the put sessions.
+func getPutSessionsDir() (string, *probe.Error) {
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(sessionDir, putSessionsDir), nil
+}
+
+// newPutSessionID returns a random session ID.
+func newPutSessionID() (string, *probe.Error) {
+	b := make([]byte, 4)
+	if _, e := rand.Read(b); e != nil {
+		return "", probe.NewError(e)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newPutSession creates a session for a put run with args, and with the
+// secret flags secretFlags which are not saved, which expires after expiry.
+func newPutSession(args, secretFlags []string, expiry time.Duration) (*putSession, *probe.Error) {
+	sessionsDir, err := getPutSessionsDir()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	id, err := newPutSessionID()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	wd, e := os.Getwd()
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+
+	s := &putSession{
+		dir: filepath.Join(sessionsDir, id),
+		header: putSessionHeader{
+			Version:       putSessionVersion,
+			ID:            id,
+			Created:       UTCNow(),
+			Expires:       UTCNow().Add(expiry),
+			WorkingFolder: wd,
+			Args:          args,
+			SecretFlags:   secretFlags,
+		},
+		index: map[string]int{},
+		done:  map[int]bool{},
+	}
+	if e = os.MkdirAll(s.dir, 0o700); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if err = s.saveHeader(); err != nil {
+		s.remove()
+		return nil, err.Trace(id)
+	}
+	if err = s.openFiles(); err != nil {
+		s.remove()
+		return nil, err.Trace(id)
+	}
+	if e = syncDir(sessionsDir); e != nil {
+		s.remove()
+		return nil, probe.NewError(e).Trace(id)
+	}
+	return s, nil
+}
+
+// loadPutSessionHeader reads the header of the session in dir.
+func loadPutSessionHeader(dir string) (putSessionHeader, *probe.Error) {
+	var header putSessionHeader
+	data, e := os.ReadFile(filepath.Join(dir, putSessionHeaderFile))
+	if e != nil {
+		return header, probe.NewError(e)
+	}
+	if e = json.Unmarshal(data, &header); e != nil {
+		return header, probe.NewError(e)
+	}
+	if header.Version != putSessionVersion {
+		return header, probe.NewError(fmt.Errorf("session version %s does not match mc session version %s", header.Version, putSessionVersion))
+	}
+	return header, nil
+}
+
+// readPutSession reads the session id, without changing its files which
+// a running put may be appending to.
+func readPutSession(id string) (*putSession, *probe.Error) {
+	sessionsDir, err := getPutSessionsDir()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	if id == "" || id != filepath.Base(id) {
+		return nil, errInvalidArgument().Trace(id)
+	}
+	s := &putSession{
+		dir:   filepath.Join(sessionsDir, id),
+		index: map[string]int{},
+		done:  map[int]bool{},
+	}
+	if s.header, err = loadPutSessionHeader(s.dir); err != nil {
+		return nil, err.Trace(id)
+	}
+
+	lines, err := readJournalLines(filepath.Join(s.dir, putSessionManifest))
+	if err != nil {
+		return nil, err.Trace(id)
+	}
+	for _, line := range lines {
+		var entry putSessionEntry
+		if e := json.Unmarshal(line, &entry); e != nil {
+			return nil, probe.NewError(e).Trace(id)
+		}
+		s.index[entry.Source] = len(s.entries)
+		s.entries = append(s.entries, entry)
+	}
+	if lines, err = readJournalLines(filepath.Join(s.dir, putSessionJournal)); err != nil {
+		return nil, err.Trace(id)
+	}
+	for _, line := range lines {
+		n, e := strconv.Atoi(string(line))
+		if e != nil || n < 0 || n >= len(s.entries) {
+			return nil, probe.NewError(fmt.Errorf("invalid journal line %q", line)).Trace(id)
+		}
+		s.done[n] = true
+	}
+	s.synced = len(s.entries)
+	return s, nil
+}
+
+// loadPutSession loads the session id, ready to record more transfers.
+func loadPutSession(id string) (*putSession, *probe.Error) {
+	s, err := readPutSession(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{putSessionManifest, putSessionJournal} {
+		if err = truncateTornLine(filepath.Join(s.dir, name)); err != nil {
+			return nil, err.Trace(id)
+		}
+	}
+	if err = s.openFiles(); err != nil {
+		return nil, err.Trace(id)
+	}
+	return s, nil
+}
+
+// readJournalLines returns the complete lines of the file at path,
+// without the torn line a killed writer may have left at its end.
+func readJournalLines(path string) ([][]byte, *probe.Error) {
+	data, e := os.ReadFile(path)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return nil, nil
+		}
+		return nil, probe.NewError(e)
+	}
+	var lines [][]byte
+	for _, line := range bytes.Split(data[:bytes.LastIndexByte(data, '\n')+1], []byte{'\n'}) {
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// truncateTornLine removes the torn line at the end of the file at path,
+// if any, before more lines are appended to it.
+func truncateTornLine(path string) *probe.Error {
+	data, e := os.ReadFile(path)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return nil
+		}
+		return probe.NewError(e)
+	}
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		return probe.NewError(os.Truncate(path, int64(end)))
+	}
+	return nil
+}
+
+// openFiles opens the manifest and the journal for appending.
+func (s *putSession) openFiles() *probe.Error {
+	var e error
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if s.manifest, e = os.OpenFile(filepath.Join(s.dir, putSessionManifest), flags, 0o600); e != nil {
+		return probe.NewError(e)
+	}
+	if s.journal, e = os.OpenFile(filepath.Join(s.dir, putSessionJournal), flags, 0o600); e != nil {
+		s.manifest.Close()
+		return probe.NewError(e)
+	}
+	if e = syncDir(s.dir); e != nil {
+		s.manifest.Close()
+		s.journal.Close()
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// saveHeader replaces session.json, atomically.
+func (s *putSession) saveHeader() *probe.Error {
+	data, e := json.MarshalIndent(s.header, "", " ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, putSessionHeaderFile), append(data, '\n')); err != nil {
+		return err
+	}
+	return probe.NewError(syncDir(s.dir))
+}
+
+// syncDir syncs the folder at path to disk, so that the files created or
+// renamed in it survive a crash. Folders cannot be synced on Windows.
+func syncDir(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, e := os.Open(path)
+	if e != nil {
+		return e
+	}
+	e = d.Sync()
+	if ce := d.Close(); e == nil {
+		e = ce
+	}
+	return e
+}
+
+// plan records the upload of urls in the manifest, and returns whether
+// it still has to be done. A source already in the manifest must not
+// have changed since it was recorded.
+func (s *putSession) plan(urls URLs) (bool, *probe.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source := urls.SourceContent.URL.Path
+	if n, ok := s.index[source]; ok {
+		if s.done[n] {
+			return false, nil
+		}
+		entry := s.entries[n]
+		if entry.Size != urls.SourceContent.Size || !entry.ModTime.Equal(urls.SourceContent.Time) {
+			return false, errSourceChanged(source)
+		}
+		return true, nil
+	}
+
+	entry := putSessionEntry{
+		Source:      source,
+		TargetAlias: urls.TargetAlias,
+		Target:      urls.TargetContent.URL.String(),
+		Size:        urls.SourceContent.Size,
+		ModTime:     urls.SourceContent.Time,
+	}
+	line, e := json.Marshal(entry)
+	if e != nil {
+		return false, probe.NewError(e)
+	}
+	if _, e = s.manifest.Write(append(line, '\n')); e != nil {
+		return false, probe.NewError(e)
+	}
+	s.index[source] = len(s.entries)
+	s.entries = append(s.entries, entry)
+	return true, nil
+}
+
+// setPlanned records that every transfer of the put is in the manifest.
+func (s *putSession) setPlanned() *probe.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.manifest.Sync(); e != nil {
+		return probe.NewError(e)
+	}
+	s.synced = len(s.entries)
+	s.header.Planned = true
+	return s.saveHeader()
+}
+
+// complete records the completed upload of urls in the journal, if the
+// put has a session.
+func (s *putSession) complete(urls URLs) *probe.Error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.index[urls.SourceContent.URL.Path]
+	if !ok || s.done[n] {
+		return nil
+	}
+	// The entry is on disk before the journal line referring to it.
+	if n >= s.synced {
+		if e := s.manifest.Sync(); e != nil {
+			return probe.NewError(e)
+		}
+		s.synced = len(s.entries)
+	}
+	if _, e := s.journal.Write([]byte(strconv.Itoa(n) + "\n")); e != nil {
+		return probe.NewError(e)
+	}
+	if e := s.journal.Sync(); e != nil {
+		return probe.NewError(e)
+	}
+	s.done[n] = true
+	return nil
+}
+
+// pending returns the entries of the manifest not completed yet.
+func (s *putSession) pending() []putSessionEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []putSessionEntry
+	for n, entry := range s.entries {
+		if !s.done[n] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// isComplete returns true if every transfer of the put is done.
+func (s *putSession) isComplete() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header.Planned && len(s.done) == len(s.entries)
+}
+
+// progress returns the completed and the planned objects and bytes.
+func (s *putSession) progress() (objects, totalObjects, bytes, totalBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for n, entry := range s.entries {
+		if s.done[n] {
+			objects++
+			bytes += entry.Size
+		}
+		totalBytes += entry.Size
+	}
+	return objects, int64(len(s.entries)), bytes, totalBytes
+}
+
+// close closes the files of the session, which is kept for a resume.
+func (s *putSession) close() *probe.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.manifest.Close()
+	if je := s.journal.Close(); e == nil {
+		e = je
+	}
+	return probe.NewError(e)
+}
+
+// remove deletes the session.
+func (s *putSession) remove() *probe.Error {
+	if s.manifest != nil {
+		s.close()
+	}
+	return probe.NewError(os.RemoveAll(s.dir))
+}
+
+// entryURLs returns the URLs to upload entry again, if its source did
+// not change since the session started.
+func (s *putSession) entryURLs(ctx context.Context, entry putSessionEntry) URLs {
+	clnt, err := newClientFromAlias("", entry.Source)
+	if err != nil {
+		return URLs{Error: err.Trace(entry.Source)}
+	}
+	sourceContent, err := clnt.Stat(ctx, StatOptions{})
+	if err != nil {
+		return URLs{Error: err.Trace(entry.Source)}
+	}
+	if sourceContent.Size != entry.Size || !sourceContent.Time.Equal(entry.ModTime) {
+		return URLs{Error: errSourceChanged(entry.Source)}
+	}
+	return makeCopyContentTypeA(copyURLsContent{
+		sourceContent: sourceContent,
+		targetAlias:   entry.TargetAlias,
+		targetURL:     entry.Target,
+	})
+}
+
+// preparePutSessionURLs returns the URLs of the uploads of a put, of
+// those not completed yet when it has a session. The manifest of a new
+// session is written as the source is listed, a session interrupted
+// before the listing ended lists the source again.
+func preparePutSessionURLs(ctx context.Context, s *putSession, o prepareCopyURLsOpts) <-chan URLs {
+	if s == nil {
+		return preparePutURLs(ctx, o)
+	}
+	urlsCh := make(chan URLs)
+	go func() {
+		defer close(urlsCh)
+		send := func(urls URLs) bool {
+			select {
+			case urlsCh <- urls:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if s.header.Planned {
+			for _, entry := range s.pending() {
+				if !send(s.entryURLs(ctx, entry)) {
+					return
+				}
+			}
+			return
+		}
+
+		for urls := range preparePutURLs(ctx, o) {
+			if urls.Error != nil {
+				if !send(urls) || !o.continueOnError {
+					return
+				}
+				continue
+			}
+			pending, err := s.plan(urls)
+			if err != nil {
+				urls.Error = err
+			}
+			if (pending || err != nil) && !send(urls) {
+				return
+			}
+		}
+		if ctx.Err() == nil {
+			if err := s.setPlanned(); err != nil {
+				send(URLs{Error: err.Trace(s.header.ID)})
+			}
+		}
+	}()
+	return urlsCh
+}
+
+// putSessionSecretFlags are the flags of put which hold secrets, they
+// are never saved in a session.
+var putSessionSecretFlags = map[string]bool{"encrypt-key": true}
+
+// putSessionArgs returns the arguments of the put run by cliCtx, with
+// every flag set on the command line or from the environment, before or
+// after the command name, so that a resume runs with the same flags. The
+// secret flags are left out, their names are returned instead.
+func putSessionArgs(cliCtx *cli.Context) (args, secretFlags []string) {
+	seen := map[string]bool{}
+	addFlags := func(flags []cli.Flag, isSet func(string) bool, value func(string) interface{}) {
+		for _, f := range flags {
+			name := flagName(f)
+			if seen[name] || !isSet(name) {
+				continue
+			}
+			v, ok := value(name).(flag.Value)
+			if !ok {
+				continue
+			}
+			seen[name] = true
+			if putSessionSecretFlags[name] {
+				secretFlags = append(secretFlags, name)
+				continue
+			}
+			if _, ok := f.(cli.BoolFlag); ok {
+				if v.String() == "true" {
+					args = append(args, "--"+name)
+				}
+				continue
+			}
+			args = append(args, "--"+name+"="+v.String())
+		}
+	}
+	addFlags(cliCtx.Command.Flags, cliCtx.IsSet, cliCtx.Generic)
+	addFlags(cliCtx.App.Flags, cliCtx.GlobalIsSet, cliCtx.GlobalGeneric)
+	return append(args, cliCtx.Args()...), secretFlags
+}
+
+// removeExpiredPutSessions removes the put sessions past their expiry.
+func removeExpiredPutSessions() {
+	sessionsDir, err := getPutSessionsDir()
+	if err != nil {
+		return
+	}
+	dirs, e := os.ReadDir(sessionsDir)
+	if e != nil {
+		return
+	}
+	now := UTCNow()
+	for _, dir := range dirs {
+		header, err := loadPutSessionHeader(filepath.Join(sessionsDir, dir.Name()))
+		if err == nil && now.After(header.Expires) {
+			os.RemoveAll(filepath.Join(sessionsDir, dir.Name()))
+		}
+	}
+}
+
+// listPutSessions returns the put sessions, oldest first.
+func listPutSessions() ([]*putSession, *probe.Error) {
+	sessionsDir, err := getPutSessionsDir()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	dirs, e := os.ReadDir(sessionsDir)
+	if e != nil {
+		if errors.Is(e, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, probe.NewError(e)
+	}
+	var sessions []*putSession
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
+		s, err := readPutSession(dir.Name())
+		if err != nil {
+			errorIf(err.Trace(dir.Name()), "Unable to load session `"+dir.Name()+"`.")
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].header.Created.Before(sessions[j].header.Created)
+	})
+	return sessions, nil
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+)
+
+func putSessionTestURLs(t *testing.T, s *putSession, dir string) []URLs {
+	t.Helper()
+	var urls []URLs
+	for u := range preparePutSessionURLs(context.Background(), s, prepareCopyURLsOpts{
+		sourceURLs:              []string{dir},
+		targetURL:               "local/bucket/",
+		ignoreBucketExistsCheck: true,
+		isRecursive:             true,
+	}) {
+		urls = append(urls, u)
+	}
+	// Errors last.
+	sort.SliceStable(urls, func(i, j int) bool {
+		if urls[i].Error != nil || urls[j].Error != nil {
+			return urls[j].Error != nil && urls[i].Error == nil
+		}
+		return urls[i].SourceContent.URL.Path < urls[j].SourceContent.URL.Path
+	})
+	return urls
+}
+
+func TestPutSessionResume(t *testing.T) {
+	initTestConfig(t)
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if e := os.WriteFile(filepath.Join(dir, name), []byte("data of "+name), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	s, err := newPutSession([]string{"--recursive", dir, "local/bucket/"}, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := putSessionTestURLs(t, s, dir)
+	if len(urls) != 3 {
+		t.Fatalf("expected 3 uploads, got %d", len(urls))
+	}
+	for _, u := range urls {
+		if u.Error != nil {
+			t.Fatal(u.Error)
+		}
+	}
+	if !s.header.Planned {
+		t.Fatal("expected the session to be planned after listing the source")
+	}
+	if err = s.complete(urls[0]); err != nil {
+		t.Fatal(err)
+	}
+	s.close()
+
+	// A put killed while writing the journal leaves a torn line.
+	journal, e := os.OpenFile(filepath.Join(s.dir, putSessionJournal), os.O_APPEND|os.O_WRONLY, 0o600)
+	if e != nil {
+		t.Fatal(e)
+	}
+	journal.WriteString("1")
+	journal.Close()
+
+	if s, err = loadPutSession(s.header.ID); err != nil {
+		t.Fatal(err)
+	}
+	defer s.remove()
+	objects, totalObjects, bytes, totalBytes := s.progress()
+	if objects != 1 || totalObjects != 3 || bytes != 9 || totalBytes != 27 {
+		t.Fatalf("unexpected progress %d/%d objects, %d/%d bytes", objects, totalObjects, bytes, totalBytes)
+	}
+
+	// The source of c changes, b is uploaded again.
+	later := time.Now().Add(time.Hour)
+	if e = os.Chtimes(filepath.Join(dir, "c"), later, later); e != nil {
+		t.Fatal(e)
+	}
+	urls = putSessionTestURLs(t, s, dir)
+	if len(urls) != 2 {
+		t.Fatalf("expected 2 pending uploads, got %d", len(urls))
+	}
+	if urls[0].Error != nil || urls[0].SourceContent.URL.Path != filepath.Join(dir, "b") {
+		t.Fatalf("expected the upload of b, got %+v", urls[0])
+	}
+	if _, ok := urls[1].Error.ToGoError().(sourceChangedErr); !ok {
+		t.Fatalf("expected the source of c to have changed, got %v", urls[1].Error)
+	}
+	if err = s.complete(urls[0]); err != nil {
+		t.Fatal(err)
+	}
+	if s.isComplete() {
+		t.Fatal("expected the session not to be complete")
+	}
+
+	sessions, err := listPutSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || newSessionListMessage(sessions[0]).Objects != 2 {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+}
+
+func TestPutSessionExpiry(t *testing.T) {
+	initTestConfig(t)
+	expired, err := newPutSession(nil, nil, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.close()
+	kept, err := newPutSession(nil, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kept.remove()
+
+	removeExpiredPutSessions()
+	if _, e := os.Stat(expired.dir); !os.IsNotExist(e) {
+		t.Fatalf("expected the expired session to be removed, got %v", e)
+	}
+	if _, e := os.Stat(kept.dir); e != nil {
+		t.Fatal(e)
+	}
+}
+
+func TestPutSessionSecretFlags(t *testing.T) {
+	initTestConfig(t)
+	const key = "local/bucket/=32byteslongsecretkeymustbegiven1"
+	newContext := func(flags []cli.Flag, args ...string) *cli.Context {
+		t.Helper()
+		set := flag.NewFlagSet("", flag.ContinueOnError)
+		for _, f := range flags {
+			f.Apply(set)
+		}
+		if e := set.Parse(args); e != nil {
+			t.Fatal(e)
+		}
+		cliCtx := cli.NewContext(cli.NewApp(), set, nil)
+		cliCtx.Command.Flags = flags
+		return cliCtx
+	}
+
+	args, secretFlags := putSessionArgs(newContext(putCmd.Flags, "--encrypt-key="+key, "--recursive", "dir/", "local/bucket/"))
+	if !reflect.DeepEqual(args, []string{"--recursive", "dir/", "local/bucket/"}) || !reflect.DeepEqual(secretFlags, []string{"encrypt-key"}) {
+		t.Fatalf("expected --encrypt-key to be left out, got %v and %v", args, secretFlags)
+	}
+	s, err := newPutSession(args, secretFlags, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.remove()
+	header, e := os.ReadFile(filepath.Join(s.dir, putSessionHeaderFile))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if strings.Contains(string(header), "32byteslongsecretkeymustbegiven1") {
+		t.Fatalf("expected the key not to be saved, got %s", header)
+	}
+	if msg := newSessionListMessage(s).String(); strings.Contains(msg, "32byteslongsecretkeymustbegiven1") {
+		t.Fatalf("expected the key not to be listed, got %s", msg)
+	}
+
+	// The key has to be given again to resume the session.
+	if _, err = putSessionResumeArgs(newContext(sessionResumeCmd.Flags, s.header.ID), s); err == nil {
+		t.Fatal("expected a resume without --encrypt-key to fail")
+	}
+	args, err = putSessionResumeArgs(newContext(sessionResumeCmd.Flags, "--encrypt-key="+key, s.header.ID), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(args, []string{"--encrypt-key=" + key, "--recursive", "dir/", "local/bucket/"}) {
+		t.Fatalf("unexpected resume args %v", args)
+	}
+}
@@ -0,0 +1,139 @@
+//go:build !windows
+// +build !windows
+
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestCheckPutSource(t *testing.T) {
+	dir := t.TempDir()
+	pipe := filepath.Join(dir, "pipe")
+	if e := syscall.Mkfifo(pipe, 0o600); e != nil {
+		t.Fatal(e)
+	}
+	socket := filepath.Join(dir, "socket")
+	l, e := net.Listen("unix", socket)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer l.Close()
+	file := filepath.Join(dir, "file")
+	if e = os.WriteFile(file, []byte("hello"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+
+	for i, testCase := range []struct {
+		path    string
+		size    int64
+		checkFn func(error) bool
+	}{
+		{file, 5, nil},
+		{pipe, -1, nil},
+		{"/dev/null", -1, nil},
+		{socket, 0, func(e error) bool { _, ok := e.(sourceIsSocketErr); return ok }},
+		{dir, 0, func(e error) bool { _, ok := e.(sourceIsDirErr); return ok }},
+	} {
+		fi, e := os.Stat(testCase.path)
+		if e != nil {
+			t.Fatal(e)
+		}
+		content := &ClientContent{Size: fi.Size(), Type: fi.Mode()}
+		err := checkPutSource(testCase.path, content)
+		if testCase.checkFn != nil {
+			if err == nil || !testCase.checkFn(err.ToGoError()) {
+				t.Fatalf("Test %d: unexpected error %v", i+1, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if content.Size != testCase.size {
+			t.Fatalf("Test %d: expected size %d, got %d", i+1, testCase.size, content.Size)
+		}
+	}
+}
+
+func TestPutNamedPipe(t *testing.T) {
+	server := newS3TestServer(t)
+	pipe := filepath.Join(t.TempDir(), "stream.pipe")
+	if e := syscall.Mkfifo(pipe, 0o600); e != nil {
+		t.Fatal(e)
+	}
+	// More than two parts, the pipe is stat'd with a size of 0.
+	data := make([]byte, 12<<20+123)
+	rand.New(rand.NewSource(1)).Read(data)
+	written := make(chan error, 1)
+	go func() {
+		f, e := os.OpenFile(pipe, os.O_WRONLY, 0)
+		if e != nil {
+			written <- e
+			return
+		}
+		_, e = f.Write(data)
+		f.Close()
+		written <- e
+	}()
+
+	var urls []URLs
+	for u := range preparePutURLs(context.Background(), prepareCopyURLsOpts{sourceURLs: []string{pipe}, targetURL: "s3test/bucket/stream.bin"}) {
+		if u.Error != nil {
+			t.Fatal(u.Error)
+		}
+		urls = append(urls, u)
+	}
+	if len(urls) != 1 || urls[0].SourceContent.Size != -1 {
+		t.Fatalf("expected the pipe to be uploaded as a stream, got %v", urls)
+	}
+
+	result := uploadSourceToTargetURL(context.Background(), uploadSourceToTargetURLOpts{
+		urls:          urls[0],
+		progress:      newAccounter(0),
+		multipartSize: "5MiB",
+	})
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if e := <-written; e != nil {
+		t.Fatal(e)
+	}
+	if result.SourceContent.Size != int64(len(data)) {
+		t.Fatalf("expected the stream to be sized %d once uploaded, got %d", len(data), result.SourceContent.Size)
+	}
+	object, ok := server.Object("bucket", "stream.bin")
+	if !ok {
+		t.Fatal("the stream was not uploaded")
+	}
+	if !bytes.Equal(object.Data, data) {
+		t.Fatalf("expected %d bytes to be uploaded, got %d differing bytes", len(data), len(object.Data))
+	}
+	if len(object.PartSizes) != 3 {
+		t.Fatalf("expected a multipart upload of 3 parts, got %v", object.PartSizes)
+	}
+}
@@ -20,6 +20,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/minio/mc/pkg/probe"
@@ -28,25 +29,82 @@ import (
 
 // preparePutURLs - prepares target and source clientURLs for copying.
 func preparePutURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
+	if o.filesFrom != nil {
+		return partitionURLs(prepareFilesFromURLs(ctx, o), o)
+	}
+	o.allowStreams = true
 	copyURLsCh := make(chan URLs)
 	go func(o prepareCopyURLsOpts) {
 		defer close(copyURLsCh)
-		copyURLsContent, err := guessPutURLType(ctx, o)
-		if err != nil {
-			copyURLsCh <- URLs{Error: err}
-			return
-		}
 
-		switch copyURLsContent.copyType {
-		case copyURLsTypeA:
-			copyURLsCh <- prepareCopyURLsTypeA(ctx, *copyURLsContent, o)
-		case copyURLsTypeB:
-			copyURLsCh <- prepareCopyURLsTypeB(ctx, *copyURLsContent, o)
-		default:
-			copyURLsCh <- URLs{Error: errInvalidArgument().Trace(o.sourceURLs...)}
+		// Mapped paths of all the sources may collide, they are all
+		// checked before uploading.
+		var mapped []URLs
+		collisions := pathCollisions{}
+		for _, sourceURL := range o.sourceURLs {
+			sourceURLsCh := preparePutSourceURLs(ctx, sourceURL, len(o.sourceURLs) > 1, o)
+			for putURLs := range sourceURLsCh {
+				if !o.pathMapping.isSet() || putURLs.Error != nil {
+					copyURLsCh <- putURLs
+					continue
+				}
+				if err := collisions.add(putURLs.TargetContent.URL.String(), putURLs.SourceContent.URL.Path, o.pathMapping); err != nil {
+					copyURLsCh <- URLs{Error: err.Trace(sourceURL)}
+					go func() {
+						for range sourceURLsCh {
+						}
+					}()
+					return
+				}
+				mapped = append(mapped, putURLs)
+			}
+		}
+		for _, putURLs := range mapped {
+			copyURLsCh <- putURLs
 		}
 	}(o)
 
+	return partitionURLs(copyURLsCh, o)
+}
+
+// preparePutSourceURLs - prepares the URLs to upload a single source,
+// the target is a folder when several sources are uploaded.
+func preparePutSourceURLs(ctx context.Context, sourceURL string, targetIsDir bool, o prepareCopyURLsOpts) <-chan URLs {
+	o.sourceURLs = []string{sourceURL}
+	putURLsCh := make(chan URLs, 1)
+	copyURLsContent, err := guessPutURLType(ctx, o)
+	if err != nil {
+		putURLsCh <- URLs{Error: err}
+		close(putURLsCh)
+		return putURLsCh
+	}
+	if targetIsDir && copyURLsContent.copyType == copyURLsTypeA {
+		copyURLsContent.copyType = copyURLsTypeB
+	}
+
+	switch copyURLsContent.copyType {
+	case copyURLsTypeA:
+		putURLsCh <- prepareCopyURLsTypeA(ctx, *copyURLsContent, o)
+	case copyURLsTypeB:
+		putURLsCh <- prepareCopyURLsTypeB(ctx, *copyURLsContent, o)
+	case copyURLsTypeC:
+		if !o.isRecursive {
+			putURLsCh <- URLs{Error: errRequiresRecursive(copyURLsContent.sourceURL).Trace(copyURLsContent.sourceURL)}
+			break
+		}
+		return preparePutURLsTypeC(ctx, *copyURLsContent, o)
+	default:
+		putURLsCh <- URLs{Error: errInvalidArgument().Trace(o.sourceURLs...)}
+	}
+	close(putURLsCh)
+	return putURLsCh
+}
+
+// partitionURLs moves the targets of the URLs of copyURLsCh under the
+// time partition of o.
+func partitionURLs(copyURLsCh chan URLs, o prepareCopyURLsOpts) chan URLs {
+	_, targetURL, _ := mustExpandAlias(o.targetURL)
+	targetPath := newClientURL(targetURL).Path
 	finalCopyURLsCh := make(chan URLs)
 	go func() {
 		defer close(finalCopyURLsCh)
@@ -55,7 +113,7 @@ func preparePutURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
 				finalCopyURLsCh <- cpURLs
 				continue
 			}
-			finalCopyURLsCh <- cpURLs
+			finalCopyURLsCh <- o.partition.apply(targetPath, cpURLs)
 		}
 	}()
 
@@ -92,6 +150,10 @@ func guessPutURLType(ctx context.Context, o prepareCopyURLsOpts) (*copyURLsConte
 			cc.copyType = copyURLsTypeC
 			return cc, nil
 		}
+		if err = checkPutSource(cc.sourceURL, cc.sourceContent); err != nil {
+			cc.copyType = copyURLsTypeInvalid
+			return cc, err.Trace(cc.sourceURL)
+		}
 		client, err = newClient(o.targetURL)
 		if err != nil {
 			cc.copyType = copyURLsTypeInvalid
@@ -133,3 +195,81 @@ func guessPutURLType(ctx context.Context, o prepareCopyURLsOpts) (*copyURLsConte
 	cc.copyType = copyURLsTypeInvalid
 	return cc, errInvalidArgument().Trace()
 }
+
+// preparePutURLsTypeC - prepares the URLs to upload every file under the
+// source folder, recreating the folder hierarchy under the target prefix.
+func preparePutURLsTypeC(ctx context.Context, cc copyURLsContent, o prepareCopyURLsOpts) <-chan URLs {
+	putURLsCh := make(chan URLs)
+	go func() {
+		defer close(putURLsCh)
+
+		sourceClient, err := newClient(cc.sourceURL)
+		if err != nil {
+			putURLsCh <- URLs{Error: err.Trace(cc.sourceURL)}
+			return
+		}
+
+		for sourceContent := range sourceClient.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+			if sourceContent.Err != nil {
+				// Listing failed.
+				putURLsCh <- URLs{Error: sourceContent.Err.Trace(sourceClient.GetURL().String())}
+				continue
+			}
+			if !sourceContent.Type.IsRegular() {
+				continue
+			}
+			newCC := cc
+			newCC.sourceContent = sourceContent
+			putURLsCh <- makePutContentTypeC(newCC, sourceClient.GetURL(), o.pathMapping)
+		}
+	}()
+	return putURLsCh
+}
+
+// makePutContentTypeC - maps a listed file to an object under the target
+// prefix, its path relative to the source renamed by mapping.
+func makePutContentTypeC(cc copyURLsContent, sourceClientURL ClientURL, mapping pathMapping) URLs {
+	suffix, err := mapping.apply(copySourceSuffix(cc, sourceClientURL))
+	if err != nil {
+		return URLs{Error: err.Trace(cc.sourceContent.URL.String())}
+	}
+	cc.targetURL = urlJoinPath(cc.targetURL, suffix)
+	return makeCopyContentTypeA(cc)
+}
+
+// isStreamSource reports whether a local source is read as a stream of
+// unknown length, such as a named pipe, a terminal or /dev/stdin, whose
+// size is not that of its content.
+func isStreamSource(content *ClientContent) bool {
+	return content.Type&(os.ModeNamedPipe|os.ModeDevice|os.ModeCharDevice) != 0
+}
+
+// checkPutSource returns the error of a local source which cannot be
+// uploaded. The size of stream sources is set to -1, so that they are
+// uploaded like stdin of unknown size instead of as empty files.
+func checkPutSource(sourceURL string, content *ClientContent) *probe.Error {
+	switch {
+	case content.Type&os.ModeSocket != 0:
+		return errSourceIsSocket(sourceURL)
+	case content.Type.IsDir():
+		return errSourceIsDir(sourceURL)
+	case isStreamSource(content):
+		content.Size = -1
+	case !content.Type.IsRegular():
+		return errInvalidSource(sourceURL)
+	}
+	return nil
+}
+
+// stripPathComponents removes the first n components of a slash separated
+// path, it returns false if no component would be left.
+func stripPathComponents(path string, n int) (string, bool) {
+	if n <= 0 {
+		return path, true
+	}
+	components := strings.Split(strings.Trim(path, "/"), "/")
+	if n >= len(components) {
+		return "", false
+	}
+	return strings.Join(components[n:], "/"), true
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStripPathComponents(t *testing.T) {
+	testCases := []struct {
+		path     string
+		n        int
+		expected string
+		ok       bool
+	}{
+		{"/build/x/y.txt", 0, "/build/x/y.txt", true},
+		{"/build/x/y.txt", 1, "x/y.txt", true},
+		{"/build/x/y.txt", 2, "y.txt", true},
+		{"/build/x/y.txt", 3, "", false},
+		{"/build/x/y.txt", 4, "", false},
+	}
+
+	for i, testCase := range testCases {
+		got, ok := stripPathComponents(testCase.path, testCase.n)
+		if ok != testCase.ok || got != testCase.expected {
+			t.Fatalf("Test %d: expected (%q, %v), got (%q, %v)", i+1, testCase.expected, testCase.ok, got, ok)
+		}
+	}
+}
+
+func TestMakePutContentTypeC(t *testing.T) {
+	sourceDir := filepath.Join(t.TempDir(), "build")
+	sourceClientURL := *newClientURL(sourceDir)
+
+	testCases := []struct {
+		file           string
+		mapping        pathMapping
+		expectedTarget string
+		success        bool
+	}{
+		{filepath.Join("x", "y.txt"), pathMapping{}, "gpumall/bucket/dir/build/x/y.txt", true},
+		{filepath.Join("x", "y.txt"), pathMapping{stripComponents: 1}, "gpumall/bucket/dir/x/y.txt", true},
+		{filepath.Join("x", "y.txt"), pathMapping{stripComponents: 2}, "gpumall/bucket/dir/y.txt", true},
+		{"y.txt", pathMapping{stripComponents: 2}, "", false},
+		{filepath.Join("x", "y.txt"), pathMapping{flatten: true}, "gpumall/bucket/dir/y.txt", true},
+		{"y.txt", pathMapping{flatten: true}, "gpumall/bucket/dir/y.txt", true},
+	}
+
+	for i, testCase := range testCases {
+		cc := copyURLsContent{
+			targetAlias: "gpumall",
+			targetURL:   "gpumall/bucket/dir",
+			sourceContent: &ClientContent{
+				URL: *newClientURL(filepath.Join(sourceDir, testCase.file)),
+			},
+		}
+		putURLs := makePutContentTypeC(cc, sourceClientURL, testCase.mapping)
+		if !testCase.success {
+			if putURLs.Error == nil {
+				t.Fatalf("Test %d: expected %s to be rejected, got target %s", i+1, testCase.file, putURLs.TargetContent.URL.Path)
+			}
+			continue
+		}
+		if putURLs.Error != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, putURLs.Error)
+		}
+		if got := putURLs.TargetContent.URL.Path; got != testCase.expectedTarget {
+			t.Fatalf("Test %d: expected target %s, got %s", i+1, testCase.expectedTarget, got)
+		}
+	}
+}
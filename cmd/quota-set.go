@@ -78,13 +78,13 @@ func (q quotaMessage) String() string {
 	switch q.op {
 	case "set":
 		return console.Colorize("QuotaMessage",
-			fmt.Sprintf("Successfully set bucket quota of %s on `%s`", humanize.IBytes(q.Quota), q.Bucket))
+			fmt.Sprintf("Successfully set bucket quota of %s on `%s`", formatSize(int64(q.Quota)), q.Bucket))
 	case "clear":
 		return console.Colorize("QuotaMessage",
 			fmt.Sprintf("Successfully cleared bucket quota configured on `%s`", q.Bucket))
 	default:
 		return console.Colorize("QuotaInfo",
-			fmt.Sprintf("Bucket `%s` has %s quota of %s", q.Bucket, q.QuotaType, humanize.IBytes(q.Quota)))
+			fmt.Sprintf("Bucket `%s` has %s quota of %s", q.Bucket, q.QuotaType, formatSize(int64(q.Quota))))
 	}
 }
 
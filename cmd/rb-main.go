@@ -254,6 +254,7 @@ func mainRemoveBucket(cliCtx *cli.Context) error {
 			cErr = exitStatus(globalErrorExitStatus)
 			continue
 		}
+		defer clnt.Close()
 		_, err = clnt.Stat(ctx, StatOptions{})
 		if err != nil {
 			switch err.ToGoError().(type) {
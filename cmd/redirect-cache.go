@@ -0,0 +1,377 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/minio/pkg/v2/console"
+)
+
+// bucketEndpoint is the endpoint a server redirected the requests of a
+// bucket to, along with the region to sign them for.
+type bucketEndpoint struct {
+	Scheme      string
+	Host        string
+	Region      string
+	VirtualHost bool
+}
+
+func (e bucketEndpoint) String() string {
+	style := "path"
+	if e.VirtualHost {
+		style = "virtual host"
+	}
+	return fmt.Sprintf("%s://%s (region %s, %s style)", e.Scheme, e.Host, e.Region, style)
+}
+
+// redirectCache remembers where the requests of each bucket of an
+// endpoint have been redirected, so that later requests go there
+// directly instead of paying a round trip to the original endpoint.
+type redirectCache struct {
+	origin string
+
+	mu      sync.Mutex
+	buckets map[string]bucketEndpoint
+}
+
+func newRedirectCache(origin string) *redirectCache {
+	return &redirectCache{origin: origin, buckets: make(map[string]bucketEndpoint)}
+}
+
+func (c *redirectCache) get(bucket string) (bucketEndpoint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.buckets[bucket]
+	return e, ok
+}
+
+func (c *redirectCache) set(bucket string, e bucketEndpoint) {
+	c.mu.Lock()
+	c.buckets[bucket] = e
+	c.mu.Unlock()
+	c.debug(fmt.Sprintf("Caching %s for bucket %s", e, bucket))
+}
+
+func (c *redirectCache) invalidate(bucket, reason string) {
+	c.mu.Lock()
+	delete(c.buckets, bucket)
+	c.mu.Unlock()
+	c.debug(fmt.Sprintf("Dropping the cached endpoint of bucket %s: %s", bucket, reason))
+}
+
+// String lists the cached endpoints, one bucket per line.
+func (c *redirectCache) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	buckets := make([]string, 0, len(c.buckets))
+	for bucket := range c.buckets {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	var b strings.Builder
+	for _, bucket := range buckets {
+		fmt.Fprintf(&b, "  %s -> %s\n", bucket, c.buckets[bucket])
+	}
+	return b.String()
+}
+
+func (c *redirectCache) debug(event string) {
+	if globalDebug {
+		console.Debugln(fmt.Sprintf("%s, redirect cache of %s:\n%s", event, c.origin, c))
+	}
+}
+
+// redirectTransport follows the redirects of a bucket to its regional
+// endpoint and sends the later requests of the bucket there directly.
+// Requests are signed again for the new host, which is only possible
+// for SigV4 requests with a signed or unsigned payload, other requests
+// are sent as is.
+type redirectTransport struct {
+	transport http.RoundTripper
+	host      string
+	region    string
+
+	accessKey    string
+	secretKey    string
+	sessionToken string
+
+	cache *redirectCache
+}
+
+func newRedirectTransport(config *Config, transport http.RoundTripper) http.RoundTripper {
+	u, e := url.Parse(config.HostURL)
+	if e != nil || u.Host == "" {
+		return transport
+	}
+	return &redirectTransport{
+		transport:    transport,
+		host:         u.Host,
+		region:       config.signingRegion(),
+		accessKey:    config.AccessKey,
+		secretKey:    config.SecretKey,
+		sessionToken: config.SessionToken,
+		cache:        newRedirectCache(u.Host),
+	}
+}
+
+// requestBucket returns the bucket of a request sent to the original
+// endpoint, in either lookup style, or "" for any other request.
+func (t *redirectTransport) requestBucket(req *http.Request) string {
+	if strings.HasSuffix(req.URL.Host, "."+t.host) {
+		return strings.TrimSuffix(req.URL.Host, "."+t.host)
+	}
+	if req.URL.Host != t.host {
+		return ""
+	}
+	bucket, _, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	return bucket
+}
+
+// canSignAgain returns true if the request can be sent to another host,
+// presigned and streaming signed requests are bound to their host.
+func (t *redirectTransport) canSignAgain(req *http.Request) bool {
+	if req.URL.Query().Has("X-Amz-Signature") {
+		return false
+	}
+	if strings.HasPrefix(req.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return false
+	}
+	auth := req.Header.Get("Authorization")
+	if auth == "" {
+		return true
+	}
+	return strings.HasPrefix(auth, signV4Algorithm) && t.accessKey != "" && t.secretKey != ""
+}
+
+// signV4Algorithm prefixes the Authorization header of SigV4 requests.
+const signV4Algorithm = "AWS4-HMAC-SHA256"
+
+// requestRegion returns the region of the credential scope of a SigV4
+// request.
+func requestRegion(req *http.Request) string {
+	_, scope, _ := strings.Cut(req.Header.Get("Authorization"), "Credential=")
+	scope, _, _ = strings.Cut(scope, ",")
+	if fields := strings.Split(scope, "/"); len(fields) == 5 {
+		return fields[2]
+	}
+	return ""
+}
+
+// redirect returns a copy of req sent to endpoint, signed again if req
+// was signed.
+func (t *redirectTransport) redirect(req *http.Request, bucket string, endpoint bucketEndpoint) *http.Request {
+	r := req.Clone(req.Context())
+	r.Body = req.Body
+	virtualHost := req.URL.Host != t.host
+	if !virtualHost {
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/"+bucket)
+		r.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, "/"+bucket)
+	}
+	if !endpoint.VirtualHost {
+		r.URL.Path = "/" + bucket + r.URL.Path
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = "/" + bucket + r.URL.RawPath
+		}
+	}
+	if r.URL.Path == "" {
+		r.URL.Path = "/"
+	}
+	r.URL.Scheme = endpoint.Scheme
+	r.URL.Host = endpoint.Host
+	r.Host = endpoint.Host
+	if req.Header.Get("Authorization") == "" {
+		return r
+	}
+	r.Header.Del("Authorization")
+	return signer.SignV4(*r, t.accessKey, t.secretKey, t.sessionToken, endpoint.Region)
+}
+
+// redirectEndpoint returns where a redirect response sends bucket: the
+// Location header of temporary redirects, or the Endpoint element of
+// the body of permanent ones. The body is restored.
+func (t *redirectTransport) redirectEndpoint(req *http.Request, bucket string, resp *http.Response) (bucketEndpoint, bool) {
+	endpoint := bucketEndpoint{Scheme: req.URL.Scheme}
+	if location, e := resp.Location(); e == nil {
+		endpoint.Scheme = location.Scheme
+		endpoint.Host = location.Host
+	} else if resp.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		var redirect struct {
+			Endpoint string
+		}
+		if xml.Unmarshal(body, &redirect) == nil {
+			endpoint.Host = redirect.Endpoint
+		}
+	}
+	if endpoint.Host == "" || endpoint.Host == req.URL.Host {
+		return endpoint, false
+	}
+	if !t.trustedRedirect(req, endpoint) {
+		t.cache.debug(fmt.Sprintf("Not following the redirect of bucket %s to %s://%s", bucket, endpoint.Scheme, endpoint.Host))
+		return endpoint, false
+	}
+	endpoint.VirtualHost = strings.HasPrefix(endpoint.Host, bucket+".")
+	endpoint.Region = resp.Header.Get("X-Amz-Bucket-Region")
+	if endpoint.Region == "" {
+		endpoint.Region = s3utils.GetRegionFromURL(url.URL{Host: endpoint.Host})
+	}
+	if endpoint.Region == "" {
+		endpoint.Region = requestRegion(req)
+	}
+	if endpoint.Region == "" {
+		endpoint.Region = t.region
+	}
+	return endpoint, true
+}
+
+// trustedRedirect returns true if requests may be signed again for
+// endpoint: it uses the scheme of req and is the host of the original
+// endpoint or a host of its parent domain, as regional endpoints are.
+// The keys of the user never sign requests for another server, nor
+// requests sent in plain text after a TLS one.
+func (t *redirectTransport) trustedRedirect(req *http.Request, endpoint bucketEndpoint) bool {
+	if endpoint.Scheme != req.URL.Scheme || strings.ContainsAny(endpoint.Host, "/?#@\\ ") {
+		return false
+	}
+	host, origin := (&url.URL{Host: endpoint.Host}).Hostname(), (&url.URL{Host: t.host}).Hostname()
+	if host == origin {
+		return true
+	}
+	if net.ParseIP(origin) != nil {
+		return false
+	}
+	_, parent, _ := strings.Cut(origin, ".")
+	return strings.Contains(parent, ".") && strings.HasSuffix(host, "."+parent)
+}
+
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// staleEndpointReason returns why the response of a cached endpoint
+// shows that the bucket is no longer served there, or "" otherwise.
+// The body of a 400 or 403 response is read to find its error code and
+// restored.
+func staleEndpointReason(resp *http.Response) string {
+	if isRedirectStatus(resp.StatusCode) {
+		return resp.Status
+	}
+	if resp.Body == nil || (resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusForbidden) {
+		return ""
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	for _, code := range []string{"AuthorizationHeaderMalformed", "InvalidRegion", "PermanentRedirect", "TemporaryRedirect"} {
+		if bytes.Contains(body, []byte("<Code>"+code+"</Code>")) {
+			return code
+		}
+	}
+	return ""
+}
+
+// rewindRequest returns a copy of req with a fresh body.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, e := req.GetBody()
+	if e != nil {
+		return nil, e
+	}
+	req = req.Clone(req.Context())
+	req.Body = body
+	return req, nil
+}
+
+func discardResponse(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bucket := t.requestBucket(req)
+	if bucket == "" || !t.canSignAgain(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	if endpoint, ok := t.cache.get(bucket); ok {
+		resp, err := t.transport.RoundTrip(t.redirect(req, bucket, endpoint))
+		if err != nil {
+			return nil, err
+		}
+		reason := staleEndpointReason(resp)
+		if reason == "" {
+			if region := resp.Header.Get("X-Amz-Bucket-Region"); region != "" && region != endpoint.Region {
+				endpoint.Region = region
+				t.cache.set(bucket, endpoint)
+			}
+			return resp, nil
+		}
+		t.cache.invalidate(bucket, fmt.Sprintf("%s returned %s", endpoint.Host, reason))
+		if !isReplayableRequest(req) {
+			return resp, nil
+		}
+		discardResponse(resp)
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || !isRedirectStatus(resp.StatusCode) {
+		return resp, err
+	}
+	endpoint, ok := t.redirectEndpoint(req, bucket, resp)
+	if !ok {
+		return resp, nil
+	}
+	t.cache.set(bucket, endpoint)
+	if !isReplayableRequest(req) {
+		// The next attempt of minio-go goes to the new endpoint.
+		return resp, nil
+	}
+	next, e := rewindRequest(req)
+	if e != nil {
+		return resp, nil
+	}
+	discardResponse(resp)
+	return t.transport.RoundTrip(t.redirect(next, bucket, endpoint))
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/minio-go/v7/pkg/signer"
+)
+
+func TestRedirectTransport(t *testing.T) {
+	var regionalRequests, staleResponses int32
+	regional := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&regionalRequests, 1)
+		if r.URL.Path != "/bucket/object" || !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/s3/") {
+			t.Errorf("unexpected request %s, %s", r.URL, r.Header.Get("Authorization"))
+		}
+		if atomic.AddInt32(&staleResponses, -1) >= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, "<Error><Code>AuthorizationHeaderMalformed</Code></Error>")
+			return
+		}
+		io.WriteString(w, "data")
+	}))
+	defer regional.Close()
+
+	origin := miniotest.NewServer()
+	defer origin.Close()
+	origin.Faults = func(miniotest.Request) *miniotest.Fault {
+		f := miniotest.PermanentRedirect(strings.TrimPrefix(regional.URL, "http://"))
+		f.Region = "eu-west-1"
+		return f
+	}
+
+	transport := newRedirectTransport(&Config{HostURL: origin.URL, AccessKey: miniotest.AccessKey, SecretKey: miniotest.SecretKey}, http.DefaultTransport)
+	get := func() {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, origin.URL+"/bucket/object", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+		req = signer.SignV4(*req, miniotest.AccessKey, miniotest.SecretKey, "", miniotest.Region)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if body, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(body) != "data" {
+			t.Fatalf("unexpected response %s %q", resp.Status, body)
+		}
+	}
+	expect := func(origins, regionals int32) {
+		t.Helper()
+		if o, r := int32(len(origin.Requests())), atomic.LoadInt32(&regionalRequests); o != origins || r != regionals {
+			t.Fatalf("expected %d/%d requests to the origin/regional endpoint, got %d/%d", origins, regionals, o, r)
+		}
+	}
+
+	// The first request follows the redirect, the next goes direct.
+	get()
+	expect(1, 1)
+	get()
+	expect(1, 2)
+
+	cache := transport.(*redirectTransport).cache
+	if got := cache.String(); !strings.Contains(got, "bucket -> "+regional.URL+" (region eu-west-1, path style)") {
+		t.Fatalf("unexpected redirect cache %q", got)
+	}
+
+	// A region error from the cached endpoint drops the entry and the
+	// request goes through the origin again.
+	atomic.StoreInt32(&staleResponses, 1)
+	get()
+	expect(2, 4)
+	if _, ok := cache.get("bucket"); !ok {
+		t.Fatal("expected the redirect to be cached again")
+	}
+}
+
+func TestRedirectTransportTrustedRedirect(t *testing.T) {
+	transport := newRedirectTransport(&Config{HostURL: "https://s3.amazonaws.com", AccessKey: testAccessKey, SecretKey: testSecretKey}, http.DefaultTransport).(*redirectTransport)
+	req, err := http.NewRequest(http.MethodGet, "https://s3.amazonaws.com/bucket/object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, testCase := range []struct {
+		scheme, host string
+		trusted      bool
+	}{
+		{"https", "s3.amazonaws.com:8443", true},
+		{"https", "s3.eu-west-1.amazonaws.com", true},
+		{"https", "bucket.s3.eu-west-1.amazonaws.com", true},
+		// Another domain.
+		{"https", "s3.example.com", false},
+		{"https", "amazonaws.com.example.com", false},
+		{"https", "evil.com#.amazonaws.com", false},
+		// A downgrade to plain text.
+		{"http", "s3.eu-west-1.amazonaws.com", false},
+	} {
+		if got := transport.trustedRedirect(req, bucketEndpoint{Scheme: testCase.scheme, Host: testCase.host}); got != testCase.trusted {
+			t.Errorf("Test %d: %s://%s, expected trusted %v", i+1, testCase.scheme, testCase.host, testCase.trusted)
+		}
+	}
+
+	// Only the same host is trusted for an IP address.
+	transport = newRedirectTransport(&Config{HostURL: "http://10.0.0.1:9000"}, http.DefaultTransport).(*redirectTransport)
+	req.URL.Scheme = "http"
+	if transport.trustedRedirect(req, bucketEndpoint{Scheme: "http", Host: "10.0.0.2:9000"}) {
+		t.Fatal("expected another IP address not to be trusted")
+	}
+}
+
+func TestRedirectTransportUntrusted(t *testing.T) {
+	var redirectedRequests int32
+	redirected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&redirectedRequests, 1)
+		io.WriteString(w, "data")
+	}))
+	defer redirected.Close()
+	otherHost := strings.Replace(redirected.URL, "127.0.0.1", "localhost", 1)
+
+	for name, testCase := range map[string]struct {
+		location string
+		tls      bool
+	}{
+		"another host":             {location: otherHost + "/bucket/object"},
+		"a downgrade to plaintext": {location: redirected.URL + "/bucket/object", tls: true},
+	} {
+		origin := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Location", testCase.location)
+			w.WriteHeader(http.StatusTemporaryRedirect)
+		}))
+		if testCase.tls {
+			origin.StartTLS()
+		} else {
+			origin.Start()
+		}
+		transport := newRedirectTransport(&Config{HostURL: origin.URL, AccessKey: testAccessKey, SecretKey: testSecretKey}, origin.Client().Transport)
+
+		req, err := http.NewRequest(http.MethodGet, origin.URL+"/bucket/object", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+		req = signer.SignV4(*req, testAccessKey, testSecretKey, "", "us-east-1")
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		origin.Close()
+		if resp.StatusCode != http.StatusTemporaryRedirect || atomic.LoadInt32(&redirectedRequests) != 0 {
+			t.Fatalf("%s: expected the redirect not to be followed, got %s and %d requests", name, resp.Status, redirectedRequests)
+		}
+		if _, ok := transport.(*redirectTransport).cache.get("bucket"); ok {
+			t.Fatalf("%s: expected the redirect not to be cached", name)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var allowEmptyFlag = cli.BoolFlag{
+	Name:  "allow-empty",
+	Usage: "succeed when a glob pattern matches no objects",
+}
+
+// remoteGlob matches the object keys of a remote path holding the glob
+// characters '*', '?' and '[...]', '**' also matching across folders.
+type remoteGlob struct {
+	pattern string
+	// prefixURL is the aliased URL of the folder listed, the longest
+	// folder of the pattern without any glob character.
+	prefixURL string
+	// keyPrefix is that folder, relative to the bucket or to the
+	// gpumall folder.
+	keyPrefix string
+	re        *regexp.Regexp
+}
+
+// hasGlobMeta returns true if path holds a glob character which is not
+// escaped with a backslash.
+func hasGlobMeta(path string) bool {
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '*', '?', '[':
+			return true
+		}
+	}
+	return false
+}
+
+// anyGlobMeta returns true if any of paths holds a glob character.
+func anyGlobMeta(paths []string) bool {
+	for _, path := range paths {
+		if hasGlobMeta(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRemoteGlob parses the glob pattern urlStr. The pattern of a gpumall
+// path applies to the keys relative to the gpumall folder, which the
+// path may either leave out or give in full, any other pattern applies
+// to the keys of the bucket.
+func newRemoteGlob(urlStr string) (*remoteGlob, *probe.Error) {
+	alias, rest, _ := strings.Cut(urlStr, "/")
+	var base, pattern string
+	if alias == AuthAlias {
+		base = strings.TrimSuffix(getPrefix(), "/") + "/"
+		if strings.HasPrefix(urlStr, base) {
+			pattern = strings.TrimPrefix(urlStr, base)
+		} else {
+			pattern = strings.TrimPrefix(rest, "/")
+		}
+	} else {
+		bucket, key, _ := strings.Cut(rest, "/")
+		if hasGlobMeta(alias + "/" + bucket) {
+			return nil, errInvalidArgument().Trace(urlStr)
+		}
+		base = alias + "/" + bucket + "/"
+		pattern = key
+	}
+	re, e := regexp.Compile(globRegexp(pattern))
+	if e != nil {
+		return nil, probe.NewError(fmt.Errorf("invalid glob pattern `%s`: %w", urlStr, e))
+	}
+	keyPrefix := globLiteralPrefix(pattern)
+	return &remoteGlob{
+		pattern:   urlStr,
+		prefixURL: base + keyPrefix,
+		keyPrefix: keyPrefix,
+		re:        re,
+	}, nil
+}
+
+// globLiteralPrefix returns the folder of pattern up to its first glob
+// character, unescaped.
+func globLiteralPrefix(pattern string) string {
+	var prefix, folder strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*', '?', '[':
+			return folder.String()
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				c = pattern[i]
+			}
+		}
+		prefix.WriteByte(c)
+		if c == '/' {
+			folder.Reset()
+			folder.WriteString(prefix.String())
+		}
+	}
+	return folder.String()
+}
+
+// globRegexp translates the glob pattern into a regular expression of
+// the whole key. '*', '?' and '[...]' never match a '/', unlike '**'.
+func globRegexp(pattern string) string {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[i:], "**/"):
+				re.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				re.WriteString(".*")
+				i++
+			default:
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		case '[':
+			class, n := globClass(pattern[i:])
+			if n == 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			re.WriteString(class)
+			i += n - 1
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return re.String()
+}
+
+// globClass translates the character class starting pattern, '!' or '^'
+// negating it, and returns its length in pattern, 0 if it is not closed.
+func globClass(pattern string) (string, int) {
+	i := 1
+	negate := i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^')
+	if negate {
+		i++
+	}
+	start := i
+	var class strings.Builder
+	for ; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == ']' && i > start:
+			if negate {
+				return "[^/" + class.String() + "]", i + 1
+			}
+			return "[" + class.String() + "]", i + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			c = pattern[i]
+		}
+		if strings.IndexByte(`\[]^`, c) >= 0 {
+			class.WriteByte('\\')
+		}
+		class.WriteByte(c)
+	}
+	return "", 0
+}
+
+// match returns true if the key of content, listed by clnt, matches g.
+func (g *remoteGlob) match(clnt Client, content *ClientContent) bool {
+	return g.re.MatchString(g.keyPrefix + g.relativePath(clnt, content))
+}
+
+// url returns the aliased URL of content, listed by clnt.
+func (g *remoteGlob) url(clnt Client, content *ClientContent) string {
+	return g.prefixURL + g.relativePath(clnt, content)
+}
+
+func (g *remoteGlob) relativePath(clnt Client, content *ClientContent) string {
+	return strings.TrimPrefix(strings.TrimPrefix(content.URL.Path, clnt.GetURL().Path), "/")
+}
+
+// list sends the objects matching g and the listing errors.
+func (g *remoteGlob) list(ctx context.Context, clnt Client, timeRef time.Time) <-chan *ClientContent {
+	matchesCh := make(chan *ClientContent)
+	go func() {
+		defer close(matchesCh)
+		for content := range clnt.List(ctx, ListOptions{Recursive: true, TimeRef: timeRef, ShowDir: DirNone}) {
+			if content.Err == nil && (!content.Type.IsRegular() || !g.match(clnt, content)) {
+				continue
+			}
+			select {
+			case matchesCh <- content:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return matchesCh
+}
+
+// expandRemoteGlob returns the aliased URLs of the objects matching the
+// glob pattern urlStr, no match being an error unless allowEmpty.
+func expandRemoteGlob(ctx context.Context, urlStr string, allowEmpty bool) ([]string, *probe.Error) {
+	g, err := newRemoteGlob(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	clnt, err := newClient(g.prefixURL)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	defer clnt.Close()
+
+	listCtx, cancelList := context.WithCancel(ctx)
+	defer cancelList()
+	var urls []string
+	for content := range g.list(listCtx, clnt, time.Time{}) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(urlStr)
+		}
+		urls = append(urls, g.url(clnt, content))
+	}
+	if len(urls) == 0 && !allowEmpty {
+		return nil, errNoGlobMatch(urlStr)
+	}
+	return urls, nil
+}
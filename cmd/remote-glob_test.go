@@ -0,0 +1,200 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		key     string
+		match   bool
+	}{
+		{"logs/2024-11-*.gz", "logs/2024-11-01.gz", true},
+		{"logs/2024-11-*.gz", "logs/2024-11-01.tar", false},
+		{"logs/2024-11-*.gz", "logs/2024-11-01/a.gz", false},
+		{"logs/?.gz", "logs/a.gz", true},
+		{"logs/?.gz", "logs/ab.gz", false},
+		{"logs/[ab].gz", "logs/b.gz", true},
+		{"logs/[!ab].gz", "logs/b.gz", false},
+		{"logs/[!ab].gz", "logs/c.gz", true},
+		{"logs/[a-c]d.gz", "logs/bd.gz", true},
+		{"logs/[]].gz", "logs/].gz", true},
+		{"runs/**/*.npz", "runs/x.npz", true},
+		{"runs/**/*.npz", "runs/a/b/x.npz", true},
+		{"runs/**", "runs/a/b/x.npz", true},
+		{"**.npz", "a/b.npz", true},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+		{"a.b+c(d)", "a.b+c(d)", true},
+		{"a.b", "axb", false},
+		{"[abc", "[abc", true},
+	}
+	for _, testCase := range testCases {
+		re := regexp.MustCompile(globRegexp(testCase.pattern))
+		if re.MatchString(testCase.key) != testCase.match {
+			t.Errorf("%s: expected the match of %s to be %v", testCase.pattern, testCase.key, testCase.match)
+		}
+	}
+}
+
+func TestGlobLiteralPrefix(t *testing.T) {
+	for pattern, expected := range map[string]string{
+		"logs/2024-11-*.gz": "logs/",
+		"a/b/**/c":          "a/b/",
+		"*.gz":              "",
+		`a\*/b*`:            "a*/",
+		"a/b/c":             "a/b/",
+	} {
+		if prefix := globLiteralPrefix(pattern); prefix != expected {
+			t.Errorf("%s: expected %q, got %q", pattern, expected, prefix)
+		}
+	}
+	for path, expected := range map[string]bool{"a/b*": true, "a/[b]": true, "a?": true, `a\*`: false, "a/b": false} {
+		if hasGlobMeta(path) != expected {
+			t.Errorf("%s: expected %v", path, expected)
+		}
+	}
+}
+
+func newGlobTestServer(t *testing.T) *miniotest.Server {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	t.Cleanup(server.Close)
+	t.Setenv(mcEnvHostPrefix+"glob", server.AliasURL())
+	server.MakeBucket("bucket")
+
+	dir := t.TempDir()
+	var sources []string
+	for _, key := range []string{"logs/2024-11-01.gz", "logs/2024-11-02.gz", "logs/2024-12-01.gz", "runs/a/b/x.npz", "runs/y.npz", "runs/z.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(key))
+		if e := os.MkdirAll(filepath.Dir(path), 0o700); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(key), 0o600); e != nil {
+			t.Fatal(e)
+		}
+		sources = append(sources, path)
+	}
+	ctx := context.Background()
+	for _, source := range sources {
+		key, _ := filepath.Rel(dir, source)
+		for cpURLs := range prepareCopyURLs(ctx, prepareCopyURLsOpts{sourceURLs: []string{source}, targetURL: "glob/bucket/" + filepath.ToSlash(key)}) {
+			if cpURLs.Error != nil {
+				t.Fatal(cpURLs.Error)
+			}
+			if urls := doCopy(ctx, doCopyOpts{cpURLs: cpURLs, pg: newAccounter(0)}); urls.Error != nil {
+				t.Fatal(urls.Error)
+			}
+		}
+	}
+	return server
+}
+
+func TestGetGlob(t *testing.T) {
+	newGlobTestServer(t)
+	ctx := context.Background()
+
+	target := t.TempDir()
+	var downloaded []string
+	for getURLs := range prepareGetURLs(ctx, prepareCopyURLsOpts{sourceURLs: []string{"glob/bucket/runs/**/*.npz"}, targetURL: target}) {
+		if getURLs.Error != nil {
+			t.Fatal(getURLs.Error)
+		}
+		if urls := doCopy(ctx, doCopyOpts{cpURLs: getURLs, pg: newAccounter(0)}); urls.Error != nil {
+			t.Fatal(urls.Error)
+		}
+		rel, _ := filepath.Rel(target, getURLs.TargetContent.URL.Path)
+		downloaded = append(downloaded, filepath.ToSlash(rel))
+	}
+	if expected := []string{"a/b/x.npz", "y.npz"}; !reflect.DeepEqual(downloaded, expected) {
+		t.Fatalf("expected %q to be downloaded, got %q", expected, downloaded)
+	}
+	if data, e := os.ReadFile(filepath.Join(target, "a", "b", "x.npz")); e != nil || string(data) != "runs/a/b/x.npz" {
+		t.Fatalf("unexpected content %q, %v", data, e)
+	}
+
+	for _, allowEmpty := range []bool{false, true} {
+		var errs []string
+		for getURLs := range prepareGetURLs(ctx, prepareCopyURLsOpts{sourceURLs: []string{"glob/bucket/logs/2023-*"}, targetURL: target, allowEmpty: allowEmpty}) {
+			if getURLs.Error == nil {
+				t.Fatalf("unexpected match %s", getURLs.SourceContent.URL)
+			}
+			errs = append(errs, getURLs.Error.ToGoError().Error())
+		}
+		if expected := !allowEmpty; (len(errs) == 1) != expected {
+			t.Fatalf("allowEmpty %v: unexpected errors %q", allowEmpty, errs)
+		}
+	}
+}
+
+func TestExpandRemoteGlob(t *testing.T) {
+	newGlobTestServer(t)
+
+	urls, err := expandRemoteGlob(context.Background(), "glob/bucket/logs/2024-1[1]-*.gz", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"glob/bucket/logs/2024-11-01.gz", "glob/bucket/logs/2024-11-02.gz"}; !reflect.DeepEqual(urls, expected) {
+		t.Fatalf("expected %q, got %q", expected, urls)
+	}
+	if _, err = expandRemoteGlob(context.Background(), "glob/bucket/none/*", false); err == nil {
+		t.Fatal("expected an error without any match")
+	}
+	if urls, err = expandRemoteGlob(context.Background(), "glob/bucket/none/*", true); err != nil || len(urls) != 0 {
+		t.Fatalf("expected no match and no error, got %q, %v", urls, err)
+	}
+}
+
+func TestRemoteGlobGpumall(t *testing.T) {
+	initTestConfig(t)
+	defer func(creds *AuthData) { globalCredentials = creds }(globalCredentials)
+	globalCredentials = &AuthData{Endpoint: "https://oss.gpumall.invalid", Bucket: "bucket1", BasePath: "/u[1]", AccessKey: "ci-access"}
+
+	// The base path is never taken as a pattern.
+	testCases := []struct {
+		path      string
+		prefixURL string
+		key       string
+	}{
+		{"gpumall/logs/*.gz", "gpumall/bucket1/u[1]/logs/", "logs/a.gz"},
+		{"gpumall/bucket1/u[1]/logs/*.gz", "gpumall/bucket1/u[1]/logs/", "logs/a.gz"},
+		{"gpumall/*.gz", "gpumall/bucket1/u[1]/", "a.gz"},
+	}
+	for _, testCase := range testCases {
+		g, err := newRemoteGlob(testCase.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.prefixURL != testCase.prefixURL || !g.re.MatchString(testCase.key) {
+			t.Errorf("%s: expected to list %s matching %s, got %s matching %s", testCase.path, testCase.prefixURL, testCase.key, g.prefixURL, g.re)
+		}
+	}
+	if _, err := newRemoteGlob("glob/bucket*/a"); err == nil {
+		t.Error("expected glob characters in the bucket to be rejected")
+	}
+}
@@ -287,6 +287,7 @@ func mainReplicateAdd(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "unable to initialize connection.")
+	defer client.Close()
 
 	var sourceBucket string
 	switch c := client.(type) {
@@ -98,6 +98,7 @@ func mainReplicateExport(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 	rCfg, err := client.GetReplication(ctx)
 	fatalIf(err.Trace(args...), "Unable to get replication configuration")
 	printMsg(replicateExportMessage{
@@ -106,6 +106,7 @@ func mainReplicateImport(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 	rCfg, err := readReplicationConfig()
 	fatalIf(err.Trace(args...), "Unable to read replication configuration")
 
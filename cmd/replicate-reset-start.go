@@ -113,6 +113,7 @@ func mainReplicateResyncStart(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 	var olderThanStr string
 	var olderThan time.Duration
 	if cliCtx.IsSet("older-than") {
@@ -148,6 +148,7 @@ func mainreplicateResyncStatus(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 
 	rinfo, err := client.ReplicationResyncStatus(ctx, cliCtx.String("remote-bucket"))
 	fatalIf(err.Trace(args...), "Unable to get replication resync status")
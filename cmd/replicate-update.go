@@ -281,6 +281,7 @@ func mainReplicateUpdate(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "unable to initialize connection.")
+	defer client.Close()
 	rcfg, err := client.GetReplication(ctx)
 	fatalIf(err.Trace(args...), "unable to get replication configuration")
 
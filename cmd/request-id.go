@@ -0,0 +1,146 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+)
+
+// Keys of the request IDs recorded in the SysInfo of a probe error.
+const (
+	sysInfoRequestID = "request.id"
+	sysInfoHostID    = "request.hostId"
+)
+
+// requestIDRecorder keeps the x-amz-request-id and x-amz-id-2 headers
+// of the last response to the requests of a context matched by its
+// filter, all of them without one, storage vendors ask for them to
+// investigate a failure. Requests running in parallel race on it, the
+// context must be the one of a single request or the filter must match
+// only one of its requests.
+type requestIDRecorder struct {
+	filter    func(*http.Request) bool
+	mu        sync.Mutex
+	requestID string
+	hostID    string
+}
+
+type requestIDRecorderKey struct{}
+
+// withRequestIDRecorder returns a context recording the request IDs of
+// the responses to its requests matched by filter, if not nil.
+func withRequestIDRecorder(ctx context.Context, filter func(*http.Request) bool) (context.Context, *requestIDRecorder) {
+	r := &requestIDRecorder{filter: filter}
+	return context.WithValue(ctx, requestIDRecorderKey{}, r), r
+}
+
+func (r *requestIDRecorder) record(req *http.Request, resp *http.Response) {
+	requestID := resp.Header.Get("X-Amz-Request-Id")
+	if requestID == "" || (r.filter != nil && !r.filter(req)) {
+		return
+	}
+	r.mu.Lock()
+	r.requestID, r.hostID = requestID, resp.Header.Get("X-Amz-Id-2")
+	r.mu.Unlock()
+}
+
+func (r *requestIDRecorder) get() (requestID, hostID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requestID, r.hostID
+}
+
+// isObjectWrite tells if req writes an object: a single PUT or copy, or
+// the completion of a multipart upload, but not the upload of a part.
+func isObjectWrite(req *http.Request) bool {
+	query := req.URL.Query()
+	switch req.Method {
+	case http.MethodPut:
+		return !query.Has("partNumber") && !query.Has("tagging") && !query.Has("retention") && !query.Has("legal-hold")
+	case http.MethodPost:
+		return query.Has("uploadId")
+	}
+	return false
+}
+
+// requestIDTransport records the request IDs of every response in the
+// requestIDRecorder of its request context, if any.
+type requestIDTransport struct {
+	transport http.RoundTripper
+}
+
+func newRequestIDTransport(transport http.RoundTripper) http.RoundTripper {
+	return &requestIDTransport{transport: transport}
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err == nil {
+		if r, ok := req.Context().Value(requestIDRecorderKey{}).(*requestIDRecorder); ok {
+			r.record(req, resp)
+		}
+	}
+	return resp, err
+}
+
+// withRequestIDs records the request IDs of the response behind err in
+// err, errors of S3 responses carry their own.
+func withRequestIDs(err *probe.Error, requestID, hostID string) *probe.Error {
+	if err == nil || requestID == "" {
+		return err
+	}
+	if id, _ := requestIDs(err); id != "" {
+		return err
+	}
+	if err.SysInfo == nil {
+		err.SysInfo = map[string]string{}
+	}
+	err.SysInfo[sysInfoRequestID] = requestID
+	if hostID != "" {
+		err.SysInfo[sysInfoHostID] = hostID
+	}
+	return err
+}
+
+// requestIDs returns the request IDs of the response behind err, if any.
+func requestIDs(err *probe.Error) (requestID, hostID string) {
+	var errResp minio.ErrorResponse
+	if errors.As(err.ToGoError(), &errResp) && errResp.RequestID != "" {
+		return errResp.RequestID, errResp.HostID
+	}
+	return err.SysInfo[sysInfoRequestID], err.SysInfo[sysInfoHostID]
+}
+
+// requestIDsSuffix formats the request IDs of err for an error line.
+func requestIDsSuffix(err *probe.Error) string {
+	requestID, hostID := requestIDs(err)
+	if requestID == "" {
+		return ""
+	}
+	suffix := " requestID=" + requestID
+	if hostID != "" {
+		suffix += " hostID=" + hostID
+	}
+	return suffix
+}
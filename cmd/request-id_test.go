@@ -0,0 +1,102 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+// lastRequestID returns the ID of the last request to key received by
+// server, "" for the bucket.
+func lastRequestID(server *miniotest.Server, key string) (id string) {
+	for _, r := range server.Requests() {
+		if r.Key == key {
+			id = r.ID
+		}
+	}
+	return id
+}
+
+func TestRequestIDsOfTypedErrors(t *testing.T) {
+	server := newS3TestServer(t)
+
+	_, _, err := newS3TestClient(t, "s3test/bucket/object").Get(context.Background(), GetOptions{})
+	if _, ok := err.ToGoError().(ObjectMissing); !ok {
+		t.Fatalf("expected ObjectMissing, got %v", err)
+	}
+	expected := " requestID=" + lastRequestID(server, "object") + " hostID=" + miniotest.HostID
+	if got := requestIDsSuffix(err); got != expected {
+		t.Fatalf("expected request IDs %q, got %q", expected, got)
+	}
+}
+
+func TestRequestIDsOfRemoveErrors(t *testing.T) {
+	server := newS3TestServer(t)
+	server.DeleteError = func(key string) string {
+		if key == "b" {
+			return "AccessDenied"
+		}
+		return ""
+	}
+
+	clnt := newS3TestClient(t, "s3test/bucket")
+	contentCh := make(chan *ClientContent, 2)
+	for _, key := range []string{"a", "b"} {
+		server.PutObject("bucket", key, []byte(key))
+		contentCh <- &ClientContent{URL: *newClientURL(server.URL + "/bucket/" + key)}
+	}
+	close(contentCh)
+
+	var errs int
+	for result := range clnt.Remove(context.Background(), false, false, false, false, contentCh) {
+		if result.Err == nil {
+			continue
+		}
+		errs++
+		expected := lastRequestID(server, "")
+		if requestID, hostID := requestIDs(result.Err); requestID != expected || hostID != miniotest.HostID {
+			t.Fatalf("unexpected request IDs %q %q for %v", requestID, hostID, result.Err)
+		}
+	}
+	if errs != 1 {
+		t.Fatalf("expected 1 error, got %d", errs)
+	}
+}
+
+func TestIsObjectWrite(t *testing.T) {
+	for target, expected := range map[string]bool{
+		"PUT /bucket/object":                          true,
+		"PUT /bucket/object?partNumber=1&uploadId=id": false,
+		"PUT /bucket/object?tagging":                  false,
+		"POST /bucket/object?uploads":                 false,
+		"POST /bucket/object?uploadId=id":             true,
+		"GET /bucket/object":                          false,
+		"HEAD /bucket/object":                         false,
+	} {
+		method, path, _ := strings.Cut(target, " ")
+		req := httptest.NewRequest(method, path, nil)
+		if got := isObjectWrite(req); got != expected {
+			t.Errorf("%s: expected %v, got %v", target, expected, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/v2/console"
+)
+
+// restoreTierFlag is the retrieval tier of restore, ilm restore keeps
+// restoring with the Expedited tier by default.
+var restoreTierFlag = cli.StringFlag{
+	Name:  "tier",
+	Value: string(minio.TierStandard),
+	Usage: "retrieval tier, one of Standard, Bulk or Expedited",
+}
+
+// restore command flags.
+var (
+	restoreFlags = []cli.Flag{
+		cli.IntFlag{
+			Name:  "days",
+			Value: 1,
+			Usage: "keep the restored copy for N days",
+		},
+		restoreTierFlag,
+		cli.StringFlag{
+			Name:  "version-id, vid",
+			Usage: "select a specific version id",
+		},
+	}
+)
+
+// Restore command.
+var restoreCmd = cli.Command{
+	Name:         "restore",
+	Usage:        "restore an object from the archive tier",
+	Action:       mainRestore,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(restoreFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+DESCRIPTION:
+  Request a temporary copy of an archived object, the copy expires after the
+  given number of days. Use 'stat' to follow the progress of the restore.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Restore an object for 1 day
+    {{.Prompt}} {{.HelpName}} path-to/object
+  2. Restore an object for 7 days with the cheapest tier
+    {{.Prompt}} {{.HelpName}} --days 7 --tier Bulk path-to/object
+`,
+}
+
+// restoreMessage is the result of a restore request.
+type restoreMessage struct {
+	Status     string `json:"status"`
+	Key        string `json:"key"`
+	VersionID  string `json:"versionId,omitempty"`
+	Days       int    `json:"days"`
+	Tier       string `json:"tier"`
+	InProgress bool   `json:"alreadyInProgress"`
+}
+
+// Colorized message for console printing.
+func (r restoreMessage) String() string {
+	key := console.Colorize("Restore", fmt.Sprintf("`%s`", r.Key))
+	if r.InProgress {
+		return fmt.Sprintf("Restore of %s is already in progress.", key)
+	}
+	return fmt.Sprintf("Restore of %s requested for %d day(s) with the %s tier.", key, r.Days, r.Tier)
+}
+
+// JSON'ified message for scripting.
+func (r restoreMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// parseRestoreTier returns the retrieval tier named tier, case insensitive.
+func parseRestoreTier(tier string) (minio.TierType, bool) {
+	for _, t := range []minio.TierType{minio.TierStandard, minio.TierBulk, minio.TierExpedited} {
+		if strings.EqualFold(tier, string(t)) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// mustParseRestoreTier returns the tier of --tier, exiting on an
+// unknown one.
+func mustParseRestoreTier(cliCtx *cli.Context) minio.TierType {
+	tier, ok := parseRestoreTier(cliCtx.String("tier"))
+	if !ok {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("tier")), "--tier should be one of Standard, Bulk or Expedited.")
+	}
+	return tier
+}
+
+// mainRestore is the entry point for restore command.
+func mainRestore(cliCtx *cli.Context) error {
+	args := cliCtx.Args()
+	if len(args) != 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	days := cliCtx.Int("days")
+	if days <= 0 {
+		fatalIf(errInvalidArgument().Trace(args...), "--days should be equal or greater than 1.")
+	}
+	tier := mustParseRestoreTier(cliCtx)
+	// gpumall targets fail fast without a valid token.
+	targetURL := getFullPath(args.Get(0))
+
+	ctx, cancelRestore := context.WithCancel(globalContext)
+	defer cancelRestore()
+
+	console.SetColor("Restore", color.New(color.FgGreen, color.Bold))
+
+	clnt, err := newClient(targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+args.Get(0)+"`.")
+	defer clnt.Close()
+
+	msg := restoreMessage{
+		Key:       args.Get(0),
+		VersionID: cliCtx.String("version-id"),
+		Days:      days,
+		Tier:      string(tier),
+	}
+	err = clnt.Restore(ctx, msg.VersionID, days, tier)
+	if err != nil {
+		if _, ok := err.ToGoError().(ObjectRestoreInProgress); !ok {
+			fatalIf(err.Trace(targetURL), "Unable to restore `"+args.Get(0)+"`.")
+		}
+		msg.InProgress = true
+	}
+	printMsg(msg)
+	return nil
+}
@@ -1,33 +1,106 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+const (
+	// defaultRetryCap is the maximum time to wait in between two retries.
+	defaultRetryCap = 30 * time.Second
+
+	// maxJitter randomizes the whole backoff window (full jitter).
+	maxJitter = 1.0
+
+	// noJitter disables jitter, every retry waits the exact backoff.
+	noJitter = 0.0
 )
 
 type retryManager struct {
-	retries       int
-	maxRetries    int
-	retryInterval time.Duration
-	commandCtx    context.Context
-	retryCtx      context.Context
-	cancelRetry   context.CancelFunc
+	retries     int
+	maxRetries  int
+	retryUnit   time.Duration
+	retryCap    time.Duration
+	jitter      float64
+	commandCtx  context.Context
+	retryCtx    context.Context
+	cancelRetry context.CancelFunc
 }
 
-func newRetryManager(ctx context.Context, retryInterval time.Duration, maxRetries int) *retryManager {
+// newRetryManager returns a retryManager backing off from retryUnit,
+// or from --retry-base when set, up to --retry-cap with --retry-jitter.
+func newRetryManager(ctx context.Context, retryUnit time.Duration, maxRetries int) *retryManager {
 	retryCtx, cancelFunc := context.WithCancel(context.Background())
-	return &retryManager{
-		retryInterval: retryInterval,
-		maxRetries:    maxRetries,
-		commandCtx:    ctx,
-		retryCtx:      retryCtx,
-		cancelRetry:   cancelFunc,
+	r := &retryManager{
+		retryUnit:   retryUnit,
+		retryCap:    defaultRetryCap,
+		jitter:      maxJitter,
+		maxRetries:  maxRetries,
+		commandCtx:  ctx,
+		retryCtx:    retryCtx,
+		cancelRetry: cancelFunc,
+	}
+	return r.withBackoff(globalRetryBase, globalRetryCap, globalRetryJitter)
+}
+
+// withBackoff overrides the base unit, the cap and the jitter
+// used to compute the wait in between two retries.
+func (r *retryManager) withBackoff(unit, cap time.Duration, jitter float64) *retryManager {
+	if unit > 0 {
+		r.retryUnit = unit
+	}
+	if cap > 0 {
+		r.retryCap = cap
 	}
+	r.jitter = jitter
+	return r
+}
+
+// backoff returns the time to wait before the given attempt, it grows
+// exponentially from the retry unit, never exceeds the retry cap and is
+// randomized by the jitter so that concurrent clients do not retry in
+// lockstep.
+func (r *retryManager) backoff(attempt int) time.Duration {
+	return exponentialBackoff(r.retryUnit, r.retryCap, r.jitter, attempt)
+}
+
+func exponentialBackoff(unit, cap time.Duration, jitter float64, attempt int) time.Duration {
+	if jitter < noJitter {
+		jitter = noJitter
+	}
+	if jitter > maxJitter {
+		jitter = maxJitter
+	}
+	if attempt < 0 {
+		attempt = 0
+	}
+	// Avoid overflowing the shift, anything beyond 30 is capped anyway.
+	if attempt > 30 {
+		attempt = 30
+	}
+
+	sleep := unit * time.Duration(1<<uint(attempt))
+	if sleep <= 0 || sleep > cap {
+		sleep = cap
+	}
+	if jitter != noJitter {
+		sleep -= time.Duration(rand.Float64() * float64(sleep) * jitter)
+	}
+	return sleep
 }
 
 type retryMessage struct {
@@ -60,9 +133,251 @@ func (r *retryManager) retry(action func(rm *retryManager) *probe.Error) {
 			return
 		case <-r.commandCtx.Done():
 			return
-		case <-time.After(r.retryInterval/2 + time.Duration(rand.Int63n(int64(r.retryInterval)))):
+		case <-time.After(r.backoff(r.retries)):
 			r.retries++
 		}
 
 	}
 }
+
+const (
+	// transientRetryMaxAttempts bounds the attempts of a single HTTP
+	// request that failed with a transient network error.
+	transientRetryMaxAttempts = 3
+
+	// transientRetryCap is the maximum wait in between two attempts.
+	transientRetryCap = 2 * time.Second
+)
+
+// transientRetryUnit is the base backoff in between two attempts.
+var transientRetryUnit = 200 * time.Millisecond
+
+// isTransientNetworkError returns true for transport errors that are
+// likely to go away when the request is sent again: connections reset
+// by the peer, connections closed in the middle of a response, stalled
+// transfers, temporary DNS failures and TLS handshake timeouts.
+func isTransientNetworkError(err error) bool {
+	var stalled transferStalledError
+	if errors.As(err, &stalled) {
+		return true
+	}
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound && (dnsErr.IsTemporary || dnsErr.IsTimeout)
+	}
+	return strings.Contains(err.Error(), "TLS handshake timeout")
+}
+
+// isReplayableRequest returns true if req can safely be sent again,
+// idempotent methods always can, PUTs only when their body can be
+// rewound.
+func isReplayableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodPut:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+// transientNetworkError is returned once a request still fails with a
+// transient network error after transientRetryMaxAttempts. It unwraps
+// to context.DeadlineExceeded so that minio-go does not send it again,
+// the attempts of both retry layers would otherwise multiply.
+type transientNetworkError struct {
+	Attempts int
+	Err      error
+}
+
+func (e transientNetworkError) Error() string {
+	cause := e.Err.Error()
+	if errors.Is(e.Err, io.ErrUnexpectedEOF) {
+		// minio-go retries any error mentioning EOF, whatever it unwraps to.
+		cause = "connection closed unexpectedly"
+	}
+	return fmt.Sprintf("gave up after %d attempts: %s", e.Attempts, cause)
+}
+
+func (e transientNetworkError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// retryTransport retries replayable requests which failed with a
+// transient network error, with exponential backoff and full jitter.
+// It is the only layer retrying them, see transientNetworkError.
+type retryTransport struct {
+	transport http.RoundTripper
+}
+
+func newRetryTransport(transport http.RoundTripper) http.RoundTripper {
+	return &retryTransport{transport: transport}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if !isReplayableRequest(req) {
+		return resp, err
+	}
+	for attempt := 1; attempt < transientRetryMaxAttempts && isTransientNetworkError(err); attempt++ {
+		if globalDebug {
+			console.Debugln(fmt.Sprintf("Retrying %s %s (attempt %d/%d) after: %v",
+				req.Method, req.URL.Redacted(), attempt+1, transientRetryMaxAttempts, err))
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(exponentialBackoff(transientRetryUnit, transientRetryCap, maxJitter, attempt-1)):
+		}
+		if req.GetBody != nil {
+			body, e := req.GetBody()
+			if e != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err = t.transport.RoundTrip(req)
+	}
+	if isTransientNetworkError(err) {
+		return nil, transientNetworkError{Attempts: transientRetryMaxAttempts, Err: err}
+	}
+	return resp, err
+}
+
+const (
+	// defaultMaxRetryTime is the default --max-retry-time.
+	defaultMaxRetryTime = 5 * time.Minute
+
+	// throttleRetryCap is the maximum wait in between two attempts of a
+	// throttled request without a Retry-After header.
+	throttleRetryCap = time.Minute
+)
+
+// throttleRetryUnit is the base backoff of a throttled request, longer
+// than transientRetryUnit so that an overloaded server gets to recover.
+var throttleRetryUnit = time.Second
+
+// serverThrottlingError is returned when a request is still throttled
+// once --max-retry-time has been spent waiting. It unwraps to
+// context.DeadlineExceeded so that no other retry layer sends it again.
+type serverThrottlingError struct {
+	Host   string
+	Code   string
+	Waited time.Duration
+}
+
+func (e serverThrottlingError) Error() string {
+	return fmt.Sprintf("server is throttling requests (%s from %s), gave up after waiting %s, try again later or raise --max-retry-time",
+		e.Code, e.Host, e.Waited.Round(time.Second))
+}
+
+func (e serverThrottlingError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// throttleCode returns the reason a response asks the client to slow
+// down, 503 responses and RequestTimeout errors, or "" otherwise. The
+// body of a 400 response is read to find its error code and restored.
+func throttleCode(resp *http.Response) string {
+	switch resp.StatusCode {
+	case http.StatusServiceUnavailable:
+		return "503 SlowDown"
+	case http.StatusBadRequest:
+		if resp.Body == nil {
+			return ""
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		if bytes.Contains(body, []byte("<Code>RequestTimeout</Code>")) {
+			return "400 RequestTimeout"
+		}
+	}
+	return ""
+}
+
+// retryAfter returns the wait asked by the Retry-After header of resp,
+// given either in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, e := strconv.Atoi(v); e == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, e := http.ParseTime(v); e == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// throttleTransport retries replayable requests the server asks to
+// slow down. It honors Retry-After, otherwise it backs off longer than
+// for transient network errors, and gives up once maxRetryTime has been
+// spent waiting. Other requests are left to the retries of minio-go.
+type throttleTransport struct {
+	transport    http.RoundTripper
+	maxRetryTime time.Duration
+}
+
+// newThrottleTransport returns a throttleTransport over transport, a
+// maxRetryTime of zero, that of a Config without one, is the default.
+func newThrottleTransport(maxRetryTime time.Duration, transport http.RoundTripper) http.RoundTripper {
+	if maxRetryTime <= 0 {
+		maxRetryTime = defaultMaxRetryTime
+	}
+	return &throttleTransport{transport: transport, maxRetryTime: maxRetryTime}
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		resp, err := t.transport.RoundTrip(req)
+		if err != nil || !isReplayableRequest(req) {
+			return resp, err
+		}
+		code := throttleCode(resp)
+		if code == "" {
+			return resp, nil
+		}
+		wait, ok := retryAfter(resp)
+		if !ok {
+			wait = exponentialBackoff(throttleRetryUnit, throttleRetryCap, maxJitter, attempt)
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		if waited+wait > t.maxRetryTime {
+			return nil, serverThrottlingError{Host: req.URL.Host, Code: code, Waited: waited}
+		}
+		if globalDebug {
+			console.Debugln(fmt.Sprintf("Server is throttling %s %s (%s), retrying in %s",
+				req.Method, req.URL.Redacted(), code, wait))
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		waited += wait
+		if req.GetBody != nil {
+			body, e := req.GetBody()
+			if e != nil {
+				return nil, e
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
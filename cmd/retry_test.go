@@ -0,0 +1,381 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestExponentialBackoffBounds(t *testing.T) {
+	unit := 100 * time.Millisecond
+	cap := 2 * time.Second
+
+	for attempt := 0; attempt < 10; attempt++ {
+		upper := unit * time.Duration(1<<uint(attempt))
+		if upper > cap {
+			upper = cap
+		}
+		if got := exponentialBackoff(unit, cap, noJitter, attempt); got != upper {
+			t.Fatalf("attempt %d: expected %v without jitter, got %v", attempt, upper, got)
+		}
+
+		const samples = 2000
+		var sum time.Duration
+		seen := make(map[time.Duration]struct{})
+		for i := 0; i < samples; i++ {
+			d := exponentialBackoff(unit, cap, maxJitter, attempt)
+			if d < 0 || d > upper {
+				t.Fatalf("attempt %d: backoff %v outside of [0, %v]", attempt, d, upper)
+			}
+			sum += d
+			seen[d] = struct{}{}
+		}
+		if len(seen) < samples/2 {
+			t.Fatalf("attempt %d: expected jittered backoff, only %d distinct values", attempt, len(seen))
+		}
+		// Full jitter is uniform over the window, its mean sits in the middle.
+		mean := sum / samples
+		if mean < upper*35/100 || mean > upper*65/100 {
+			t.Fatalf("attempt %d: mean backoff %v is not centered in [0, %v]", attempt, mean, upper)
+		}
+	}
+}
+
+func TestExponentialBackoffCap(t *testing.T) {
+	cap := 5 * time.Second
+	for _, attempt := range []int{20, 40, 63, 1000} {
+		if got := exponentialBackoff(time.Second, cap, noJitter, attempt); got != cap {
+			t.Fatalf("attempt %d: expected backoff capped at %v, got %v", attempt, cap, got)
+		}
+	}
+}
+
+func TestRetryManagerWithBackoff(t *testing.T) {
+	rm := newRetryManager(globalContext, time.Second, 3).withBackoff(10*time.Millisecond, 50*time.Millisecond, noJitter)
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond}
+	for attempt, want := range expected {
+		if got := rm.backoff(attempt); got != want {
+			t.Fatalf("attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+}
+
+func TestRetryManagerFlags(t *testing.T) {
+	defer func(base, cap time.Duration, jitter float64) {
+		globalRetryBase, globalRetryCap, globalRetryJitter = base, cap, jitter
+	}(globalRetryBase, globalRetryCap, globalRetryJitter)
+
+	globalRetryCap, globalRetryJitter = 3*time.Second, noJitter
+	rm := newRetryManager(globalContext, time.Second, 3)
+	if got := rm.backoff(0); got != time.Second {
+		t.Fatalf("expected the unit of the command without --retry-base, got %v", got)
+	}
+	if got := rm.backoff(5); got != 3*time.Second {
+		t.Fatalf("expected the backoff capped by --retry-cap, got %v", got)
+	}
+
+	globalRetryBase = 100 * time.Millisecond
+	if got := newRetryManager(globalContext, time.Second, 3).backoff(1); got != 200*time.Millisecond {
+		t.Fatalf("expected the backoff from --retry-base, got %v", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransport(t *testing.T) {
+	defer func(unit time.Duration) { transientRetryUnit = unit }(transientRetryUnit)
+	transientRetryUnit = time.Millisecond
+
+	reset := &url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}
+	refused := &url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}
+
+	testCases := []struct {
+		name     string
+		newReq   func() *http.Request
+		errs     []error
+		attempts int
+		success  bool
+	}{
+		{
+			name: "GET retried on connection reset",
+			newReq: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+			},
+			errs:     []error{reset, reset},
+			attempts: 3,
+			success:  true,
+		},
+		{
+			name: "GET not retried on a connection closed before the response",
+			newReq: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+			},
+			errs:     []error{io.EOF},
+			attempts: 1,
+		},
+		{
+			name: "GET gives up after the last attempt",
+			newReq: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+			},
+			errs:     []error{reset, reset, reset, reset},
+			attempts: transientRetryMaxAttempts,
+		},
+		{
+			name: "GET not retried on connection refused",
+			newReq: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+			},
+			errs:     []error{refused},
+			attempts: 1,
+		},
+		{
+			name: "PUT with a replayable body is retried",
+			newReq: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPut, "http://localhost/bucket/object", bytes.NewReader([]byte("data")))
+				return req
+			},
+			errs:     []error{reset},
+			attempts: 2,
+			success:  true,
+		},
+		{
+			name: "PUT with a streamed body is not retried",
+			newReq: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPut, "http://localhost/bucket/object", io.NopCloser(strings.NewReader("data")))
+				return req
+			},
+			errs:     []error{reset},
+			attempts: 1,
+		},
+		{
+			name: "POST is not retried",
+			newReq: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "http://localhost/bucket?delete", bytes.NewReader([]byte("data")))
+				return req
+			},
+			errs:     []error{reset},
+			attempts: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var attempts int
+		transport := newRetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if req.Body != nil && req.Body != http.NoBody {
+				if body, _ := io.ReadAll(req.Body); string(body) != "data" {
+					t.Fatalf("%s: unexpected body %q on attempt %d", testCase.name, body, attempts)
+				}
+			}
+			if attempts <= len(testCase.errs) {
+				return nil, testCase.errs[attempts-1]
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}))
+		_, err := transport.RoundTrip(testCase.newReq())
+		if attempts != testCase.attempts {
+			t.Errorf("%s: expected %d attempts, got %d", testCase.name, testCase.attempts, attempts)
+		}
+		if (err == nil) != testCase.success {
+			t.Errorf("%s: unexpected error %v", testCase.name, err)
+		}
+		if !testCase.success && attempts == transientRetryMaxAttempts && !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: expected the last error not to be retried again, got %v", testCase.name, err)
+		}
+	}
+}
+
+// A request reset on every attempt is sent transientRetryMaxAttempts
+// times in all, minio-go does not retry it on top of retryTransport.
+func TestRetryTransportSingleLayer(t *testing.T) {
+	fastRetries(t)
+	defer func(unit time.Duration) { transientRetryUnit = unit }(transientRetryUnit)
+	transientRetryUnit = time.Millisecond
+
+	var resets int32
+	server, _ := newS3TestFrontServer(t, func(server *miniotest.Server) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodHead || r.URL.Path != "/bucket/object" {
+				// net/http sends a request reset on a reused connection
+				// once more on its own.
+				w.Header().Set("Connection", "close")
+				server.ServeHTTP(w, r)
+				return
+			}
+			atomic.AddInt32(&resets, 1)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		})
+	})
+	server.PutObject("bucket", "object", []byte("data"))
+
+	_, err := newS3TestClient(t, "s3test/bucket/object").Stat(context.Background(), StatOptions{})
+	if err == nil {
+		t.Fatal("expected the reset stat to fail")
+	}
+	if n := atomic.LoadInt32(&resets); n != transientRetryMaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", transientRetryMaxAttempts, n)
+	}
+}
+
+func TestThrottleTransport(t *testing.T) {
+	defer func(unit time.Duration) { throttleRetryUnit = unit }(throttleRetryUnit)
+	throttleRetryUnit = time.Millisecond
+
+	slowDown := func(retryAfter string) *http.Response {
+		resp := &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("<Error><Code>SlowDown</Code></Error>")),
+		}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+	requestTimeout := func(string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("<Error><Code>RequestTimeout</Code></Error>")),
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		method       string
+		respond      func(string) *http.Response
+		retryAfter   string
+		throttled    int
+		maxRetryTime time.Duration
+		attempts     int
+		minWait      time.Duration
+		errThrottled bool
+	}{
+		{
+			name:         "SlowDown retried with backoff",
+			method:       http.MethodGet,
+			respond:      slowDown,
+			throttled:    3,
+			maxRetryTime: time.Minute,
+			attempts:     4,
+		},
+		{
+			name:         "Retry-After is honored",
+			method:       http.MethodGet,
+			respond:      slowDown,
+			retryAfter:   "1",
+			throttled:    1,
+			maxRetryTime: time.Minute,
+			attempts:     2,
+			minWait:      time.Second,
+		},
+		{
+			name:         "RequestTimeout retried",
+			method:       http.MethodHead,
+			respond:      requestTimeout,
+			throttled:    2,
+			maxRetryTime: time.Minute,
+			attempts:     3,
+		},
+		{
+			name:         "gives up once --max-retry-time is spent",
+			method:       http.MethodGet,
+			respond:      slowDown,
+			retryAfter:   "1",
+			throttled:    10,
+			maxRetryTime: 1500 * time.Millisecond,
+			attempts:     2,
+			errThrottled: true,
+		},
+		{
+			name:      "no --max-retry-time is the default",
+			method:    http.MethodGet,
+			respond:   slowDown,
+			throttled: 2,
+			attempts:  3,
+		},
+		{
+			name:         "POST left to minio-go",
+			method:       http.MethodPost,
+			respond:      slowDown,
+			throttled:    1,
+			maxRetryTime: time.Minute,
+			attempts:     1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var attempts int
+		transport := newThrottleTransport(testCase.maxRetryTime, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts <= testCase.throttled {
+				return testCase.respond(testCase.retryAfter), nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}))
+		start := time.Now()
+		resp, err := transport.RoundTrip(httptest.NewRequest(testCase.method, "http://localhost/bucket/object", nil))
+		if attempts != testCase.attempts {
+			t.Errorf("%s: expected %d attempts, got %d", testCase.name, testCase.attempts, attempts)
+		}
+		if elapsed := time.Since(start); elapsed < testCase.minWait {
+			t.Errorf("%s: expected to wait at least %v, waited %v", testCase.name, testCase.minWait, elapsed)
+		}
+		var throttleErr serverThrottlingError
+		if testCase.errThrottled {
+			if !errors.As(err, &throttleErr) || !strings.Contains(err.Error(), "server is throttling requests") {
+				t.Errorf("%s: expected a throttling error, got %v", testCase.name, err)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("%s: expected the throttling error not to be retried by minio-go", testCase.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.name, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
@@ -49,7 +49,7 @@ var (
 		},
 		cli.BoolFlag{
 			Name:  "force",
-			Usage: "allow a recursive remove operation",
+			Usage: "allow a recursive remove operation without confirmation, required when not run on a terminal",
 		},
 		cli.BoolFlag{
 			Name:  "dangerous",
@@ -96,11 +96,20 @@ var (
 			Name:  "non-current",
 			Usage: "remove object(s) versions that are non-current",
 		},
+		cli.BoolFlag{
+			Name:  "summary-only",
+			Usage: "suppress per-object output, only print the final summary",
+		},
+		cli.BoolFlag{
+			Name:  "trash",
+			Usage: "copy objects to the trash before removing them, see 'mc trash'",
+		},
 		cli.BoolFlag{
 			Name:   "purge",
 			Usage:  "attempt a prefix purge, requires confirmation please use with caution - only works with '--force'",
 			Hidden: true,
 		},
+		allowEmptyFlag,
 	}
 )
 
@@ -118,11 +127,25 @@ var rmCmd = cli.Command{
 USAGE:
   {{.HelpName}} [FLAGS] TARGET [TARGET ...]
 
+DESCRIPTION:
+  Recursive removals without --force summarize the objects about to be
+  removed and ask to type the target to continue, they fail when not run
+  on a terminal or with --quiet or --json.
+
+  A TARGET holding '*', '?' or '[...]' removes every object whose key matches
+  it, '*', '?' and '[...]' matching within a folder and '**' across folders. A
+  backslash escapes a glob character. The keys of gpumall paths are matched
+  relative to the gpumall folder. Matching no object is an error unless
+  --allow-empty is set. Without --force, the number of matching objects is
+  confirmed the same way as recursive removals.
+
 FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY: list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
+  MC_RM_TRASH:    set to true to move objects to the trash by default
 
 EXAMPLES:
   01. Remove a file.
@@ -167,6 +190,15 @@ EXAMPLES:
   14. Perform a fake removal of object(s) versions that are non-current and older than 10 days. If top-level version is a delete 
   marker, this will also be deleted when --non-current flag is specified.
       {{.Prompt}} {{.HelpName}} s3/docs/ --recursive --force --versions --non-current --older-than 10d --dry-run
+
+  15. Remove all objects under a prefix and only print the number of removed objects.
+      {{.Prompt}} {{.HelpName}} --recursive --force --summary-only s3/jazz-songs/louis/
+
+  16. Remove all objects under a prefix, keeping a copy in the trash of the bucket to restore with 'mc trash restore'.
+      {{.Prompt}} {{.HelpName}} --recursive --force --trash s3/jazz-songs/louis/
+
+  17. Remove the logs of November 2024 and every '.tmp' object at any depth under a prefix.
+      {{.Prompt}} {{.HelpName}} --force 's3/logs/2024-11-*.gz' 's3/runs/**/*.tmp'
 `,
 }
 
@@ -234,6 +266,16 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 			"You cannot specify --non-current without --versions --recursive, please use --non-current --versions --recursive.")
 	}
 
+	if cliCtx.Bool("trash") && (isVersions || isNoncurrentVersion || isForceDel || versionID != "" || rewind != "" || cliCtx.Bool("incomplete")) {
+		fatalIf(errDummy().Trace(),
+			"You cannot specify --trash with any of --versions, --non-current, --version-id, --rewind, --incomplete and --purge flags.")
+	}
+
+	if anyGlobMeta(cliCtx.Args()) && (isRecursive || isVersions || versionID != "" || rewind != "" || isForceDel || cliCtx.Bool("incomplete")) {
+		fatalIf(errDummy().Trace(),
+			"You cannot use a glob pattern with any of --recursive, --versions, --version-id, --rewind, --incomplete and --purge flags.")
+	}
+
 	if isForceDel && !isForce {
 		fatalIf(errDummy().Trace(),
 			"You cannot specify --purge without --force.")
@@ -251,6 +293,9 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 
 	if !isForceDel {
 		for _, url := range cliCtx.Args() {
+			if hasGlobMeta(url) {
+				continue
+			}
 			// clean path for aliases like s3/.
 			// Note: UNC path using / works properly in go 1.9.2 even though it breaks the UNC specification.
 			url = filepath.ToSlash(filepath.Clean(url))
@@ -277,16 +322,51 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 		showCommandHelpAndExit(cliCtx, exitCode)
 	}
 
-	// For all recursive or versions bulk deletion operations make sure to check for 'force' flag.
-	if (isVersions || isRecursive || isStdin) && !isForce {
-		fatalIf(errDummy().Trace(),
-			"Removal requires --force flag. This operation is *IRREVERSIBLE*. Please review carefully before performing this *DANGEROUS* operation.")
-	}
-
 	if isNamespaceRemoval && !(isDangerous && isForce) {
 		fatalIf(errDummy().Trace(),
 			"This operation results in site-wide removal of objects. If you are really sure, retry this command with ‘--dangerous’ and ‘--force’ flags.")
 	}
+
+	// For all recursive or versions bulk deletion operations make sure to check for 'force' flag,
+	// on a terminal the user may confirm by typing the target instead.
+	if (isVersions || isRecursive || isStdin) && !isForce {
+		if isStdin || !canConfirm() {
+			fatalIf(errDummy().Trace(),
+				"Removal requires --force flag. This operation is *IRREVERSIBLE*. Please review carefully before performing this *DANGEROUS* operation.")
+		}
+		if !cliCtx.Bool("dry-run") && !cliCtx.Bool("fake") {
+			for _, url := range cliCtx.Args() {
+				summary, err := summarizeObjects(ctx, url, isVersions)
+				fatalIf(err.Trace(url), "Unable to list `"+url+"`.")
+				mustConfirmTarget("About to remove up to "+summary+" under `"+url+"`.", url)
+			}
+		}
+	}
+}
+
+// expandRmGlobs returns the targets with the glob patterns replaced by the
+// objects they match, which must be confirmed on a terminal without --force.
+func expandRmGlobs(ctx context.Context, cliCtx *cli.Context, isForce, isFake bool) []string {
+	var urls []string
+	for _, url := range cliCtx.Args() {
+		if !hasGlobMeta(url) {
+			urls = append(urls, url)
+			continue
+		}
+		matches, err := expandRemoteGlob(ctx, url, cliCtx.Bool("allow-empty"))
+		fatalIf(err.Trace(url), "Unable to expand `"+url+"`.")
+		if len(matches) > 0 && !isForce {
+			if !canConfirm() {
+				fatalIf(errDummy().Trace(),
+					"Removal requires --force flag. This operation is *IRREVERSIBLE*. Please review carefully before performing this *DANGEROUS* operation.")
+			}
+			if !isFake {
+				mustConfirmTarget(fmt.Sprintf("About to remove %d objects matching `%s`.", len(matches), url), url)
+			}
+		}
+		urls = append(urls, matches...)
+	}
+	return urls
 }
 
 // Remove a single object or a single version in a versioned bucket
@@ -304,6 +384,7 @@ func removeSingle(url, versionID string, opts removeOpts) error {
 
 		isDir   bool
 		modTime time.Time
+		size    int64
 	)
 
 	targetAlias, targetURL, _ := mustExpandAlias(url)
@@ -332,6 +413,7 @@ func removeSingle(url, versionID string, opts removeOpts) error {
 		} else {
 			isDir = content.Type.IsDir()
 			modTime = content.Time
+			size = content.Size
 		}
 
 		// We should not proceed
@@ -351,9 +433,20 @@ func removeSingle(url, versionID string, opts removeOpts) error {
 		}
 
 		if opts.isFake {
-			printDryRunMsg(targetAlias, content, opts.withVersions)
+			printDryRunMsg(targetAlias, content, opts.withVersions, opts)
 			return nil
 		}
+
+		if opts.trashStamp != "" && !isDir {
+			root, err := trashRoot(url)
+			if err == nil {
+				err = trashObject(ctx, root, url, size, opts.trashStamp)
+			}
+			if err != nil {
+				errorIf(err.Trace(url), "Unable to move `"+url+"` to the trash, it is not removed.")
+				return exitStatus(globalErrorExitStatus)
+			}
+		}
 	}
 
 	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
@@ -390,7 +483,7 @@ func removeSingle(url, versionID string, opts removeOpts) error {
 			msg.DeleteMarker = true
 			msg.VersionID = result.DeleteMarkerVersionID
 		}
-		printMsg(msg)
+		printRmMsg(msg, opts)
 	}
 	return nil
 }
@@ -408,9 +501,48 @@ type removeOpts struct {
 	olderThan         string
 	newerThan         string
 	encKeyDB          map[string][]prefixSSEPair
+	summary           *rmSummaryMessage
+	// trashStamp is the time objects are moved to the trash under, they
+	// are not without one.
+	trashStamp string
+}
+
+// rmSummaryMessage - the only output of `rm --summary-only`.
+type rmSummaryMessage struct {
+	Status  string `json:"status"`
+	Type    string `json:"type"`
+	Removed int64  `json:"removed"`
+	DryRun  bool   `json:"dryRun"`
+}
+
+// Colorized message for console printing.
+func (r rmSummaryMessage) String() string {
+	if r.DryRun {
+		return fmt.Sprintf("DRYRUN: %d object(s) would be removed.", r.Removed)
+	}
+	return fmt.Sprintf("Removed %d object(s).", r.Removed)
+}
+
+// JSON'ified message for scripting.
+func (r rmSummaryMessage) JSON() string {
+	r.Status = "success"
+	r.Type = "summary"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// printRmMsg prints a removed object, with --summary-only it is only accounted
+// for the final summary.
+func printRmMsg(msg rmMessage, opts removeOpts) {
+	if opts.summary != nil {
+		opts.summary.Removed++
+		return
+	}
+	printMsg(msg)
 }
 
-func printDryRunMsg(targetAlias string, content *ClientContent, printModTime bool) {
+func printDryRunMsg(targetAlias string, content *ClientContent, printModTime bool, opts removeOpts) {
 	if content == nil {
 		return
 	}
@@ -423,7 +555,7 @@ func printDryRunMsg(targetAlias string, content *ClientContent, printModTime boo
 	if printModTime {
 		msg.ModTime = &content.Time
 	}
-	printMsg(msg)
+	printRmMsg(msg, opts)
 }
 
 // listAndRemove uses listing before removal, it can list recursively or not, with versions or not.
@@ -452,6 +584,15 @@ func listAndRemove(url string, opts removeOpts) error {
 	}
 	atLeastOneObjectFound := false
 
+	var trashRootURL string
+	trashFailed := false
+	if opts.trashStamp != "" {
+		if trashRootURL, pErr = trashRoot(url); pErr != nil {
+			errorIf(pErr.Trace(url), "Unable to move `"+url+"` to the trash, it is not removed.")
+			return exitStatus(globalErrorExitStatus)
+		}
+	}
+
 	resultCh := clnt.Remove(ctx, opts.isIncomplete, isRemoveBucket, opts.isBypass, false, contentCh)
 
 	var lastPath string
@@ -506,7 +647,7 @@ func listAndRemove(url string, opts removeOpts) error {
 					}
 
 					if opts.isFake {
-						printDryRunMsg(targetAlias, content, true)
+						printDryRunMsg(targetAlias, content, true, opts)
 						continue
 					}
 
@@ -536,7 +677,7 @@ func listAndRemove(url string, opts removeOpts) error {
 								msg.DeleteMarker = true
 								msg.VersionID = result.DeleteMarkerVersionID
 							}
-							printMsg(msg)
+							printRmMsg(msg, opts)
 						}
 					}
 				}
@@ -547,6 +688,11 @@ func listAndRemove(url string, opts removeOpts) error {
 			continue
 		}
 
+		if opts.trashStamp != "" && isTrashed(trashRootURL, targetAlias+getKey(content)) {
+			// The trash is only emptied by 'mc trash empty'.
+			continue
+		}
+
 		// This will mark that we found at least one target object
 		// even that it could be ineligible for deletion. So we can
 		// inform the user that he was searching in an empty area
@@ -568,6 +714,14 @@ func listAndRemove(url string, opts removeOpts) error {
 		}
 
 		if !opts.isFake {
+			if opts.trashStamp != "" {
+				objectURL := targetAlias + getKey(content)
+				if err := trashObject(ctx, trashRootURL, objectURL, content.Size, opts.trashStamp); err != nil {
+					errorIf(err.Trace(objectURL), "Unable to move `"+objectURL+"` to the trash, it is not removed.")
+					trashFailed = true
+					continue
+				}
+			}
 			sent := false
 			for !sent {
 				select {
@@ -598,11 +752,11 @@ func listAndRemove(url string, opts removeOpts) error {
 						msg.DeleteMarker = true
 						msg.VersionID = result.DeleteMarkerVersionID
 					}
-					printMsg(msg)
+					printRmMsg(msg, opts)
 				}
 			}
 		} else {
-			printDryRunMsg(targetAlias, content, opts.withVersions)
+			printDryRunMsg(targetAlias, content, opts.withVersions, opts)
 		}
 	}
 
@@ -627,7 +781,7 @@ func listAndRemove(url string, opts removeOpts) error {
 			}
 
 			if opts.isFake {
-				printDryRunMsg(targetAlias, content, true)
+				printDryRunMsg(targetAlias, content, true, opts)
 				continue
 			}
 
@@ -657,7 +811,7 @@ func listAndRemove(url string, opts removeOpts) error {
 						msg.DeleteMarker = true
 						msg.VersionID = result.DeleteMarkerVersionID
 					}
-					printMsg(msg)
+					printRmMsg(msg, opts)
 				}
 			}
 		}
@@ -686,7 +840,11 @@ func listAndRemove(url string, opts removeOpts) error {
 			msg.DeleteMarker = true
 			msg.VersionID = result.DeleteMarkerVersionID
 		}
-		printMsg(msg)
+		printRmMsg(msg, opts)
+	}
+
+	if trashFailed {
+		return exitStatus(globalErrorExitStatus)
 	}
 
 	if !atLeastOneObjectFound {
@@ -729,6 +887,17 @@ func mainRm(cliCtx *cli.Context) error {
 	versionID := cliCtx.String("version-id")
 	rewind := parseRewindFlag(cliCtx.String("rewind"))
 
+	var trashStamp string
+	if cliCtx.Bool("trash") {
+		trashStamp = UTCNow().Format(trashTimeFormat)
+	}
+
+	var summary *rmSummaryMessage
+	if cliCtx.Bool("summary-only") {
+		summary = &rmSummaryMessage{DryRun: isFake}
+		defer func() { printMsg(*summary) }()
+	}
+
 	if withVersions && rewind.IsZero() {
 		rewind = time.Now().UTC()
 	}
@@ -736,10 +905,12 @@ func mainRm(cliCtx *cli.Context) error {
 	// Set color.
 	console.SetColor("Removed", color.New(color.FgGreen, color.Bold))
 
+	urls := expandRmGlobs(ctx, cliCtx, isForce, isFake)
+
 	var rerr error
 	var e error
 	// Support multiple targets.
-	for _, url := range cliCtx.Args() {
+	for _, url := range urls {
 		if isRecursive || withVersions {
 			e = listAndRemove(url, removeOpts{
 				timeRef:           rewind,
@@ -753,6 +924,8 @@ func mainRm(cliCtx *cli.Context) error {
 				olderThan:         olderThan,
 				newerThan:         newerThan,
 				encKeyDB:          encKeyDB,
+				summary:           summary,
+				trashStamp:        trashStamp,
 			})
 		} else {
 			e = removeSingle(url, versionID, removeOpts{
@@ -764,6 +937,8 @@ func mainRm(cliCtx *cli.Context) error {
 				olderThan:    olderThan,
 				newerThan:    newerThan,
 				encKeyDB:     encKeyDB,
+				summary:      summary,
+				trashStamp:   trashStamp,
 			})
 		}
 		if rerr == nil {
@@ -791,6 +966,8 @@ func mainRm(cliCtx *cli.Context) error {
 				olderThan:         olderThan,
 				newerThan:         newerThan,
 				encKeyDB:          encKeyDB,
+				summary:           summary,
+				trashStamp:        trashStamp,
 			})
 		} else {
 			e = removeSingle(url, versionID, removeOpts{
@@ -802,6 +979,8 @@ func mainRm(cliCtx *cli.Context) error {
 				olderThan:    olderThan,
 				newerThan:    newerThan,
 				encKeyDB:     encKeyDB,
+				summary:      summary,
+				trashStamp:   trashStamp,
 			})
 		}
 		if rerr == nil {
@@ -0,0 +1,152 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/internal/miniotest"
+	minio "github.com/minio/minio-go/v7"
+)
+
+func TestRmSummaryOnly(t *testing.T) {
+	initTestConfig(t)
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c/d.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	savedOutput := color.Output
+	color.Output = &out
+	defer func() { color.Output = savedOutput }()
+
+	savedJSON := globalJSON
+	globalJSON = true
+	defer func() { globalJSON = savedJSON }()
+
+	summary := &rmSummaryMessage{}
+	if err := listAndRemove(dir+string(filepath.Separator), removeOpts{
+		isRecursive: true,
+		isForce:     true,
+		summary:     summary,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no per-object output, got %q", out.String())
+	}
+
+	printMsg(*summary)
+	var msg map[string]interface{}
+	if e := json.Unmarshal(out.Bytes(), &msg); e != nil {
+		t.Fatalf("expected a single summary object, got %q: %v", out.String(), e)
+	}
+	if msg["type"] != "summary" {
+		t.Fatalf("expected only the summary to be emitted, got %q", out.String())
+	}
+	if summary.Removed < 3 {
+		t.Fatalf("expected at least 3 removed objects, got %d", summary.Removed)
+	}
+}
+
+func TestRmRetriesFailedDeletes(t *testing.T) {
+	initTestConfig(t)
+	defer func(unit time.Duration) { multiDeleteRetryUnit = unit }(multiDeleteRetryUnit)
+	multiDeleteRetryUnit = time.Millisecond
+
+	server := miniotest.NewServer()
+	defer server.Close()
+	t.Setenv(mcEnvHostPrefix+"rmretry", server.AliasURL())
+	for i := 0; i < 10; i++ {
+		server.PutObject("bucket", fmt.Sprintf("dir/%d", i), []byte("x"))
+	}
+	server.PutObject("bucket", "keep", []byte("keep"))
+
+	// The first multi-delete fails half the keys.
+	failed := map[string]bool{}
+	server.DeleteError = func(key string) string {
+		if key[len(key)-1]%2 == 0 && !failed[key] {
+			failed[key] = true
+			return "SlowDown"
+		}
+		return ""
+	}
+	server.ResetRequests()
+	if e := listAndRemove("rmretry/bucket/dir/", removeOpts{isRecursive: true, isForce: true}); e != nil {
+		t.Fatal(e)
+	}
+	if keys := server.Keys("bucket"); !reflect.DeepEqual(keys, []string{"keep"}) {
+		t.Fatalf("expected only keep to be left, got %v", keys)
+	}
+	if len(failed) != 5 {
+		t.Fatalf("expected 5 failed keys, got %v", failed)
+	}
+	if n := server.RequestCount(http.MethodPost); n != 2 {
+		t.Fatalf("expected 2 multi-delete requests, got %d", n)
+	}
+
+	// Only transient errors are retried, the others are returned.
+	server.PutObject("bucket", "denied", []byte("x"))
+	server.PutObject("bucket", "throttled", []byte("x"))
+	server.DeleteError = func(key string) string {
+		switch key {
+		case "denied":
+			return "AccessDenied"
+		case "throttled":
+			return "SlowDown"
+		}
+		return ""
+	}
+	clnt, err := newClient("rmretry/bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.ResetRequests()
+	results := clnt.(*S3Client).DeleteAll(context.Background(), "bucket", []string{"denied", "keep", "throttled"}, minio.RemoveObjectsOptions{})
+	var still []string
+	for _, result := range results {
+		still = append(still, result.ObjectName+": "+minio.ToErrorResponse(result.Err).Code)
+	}
+	if expected := []string{"denied: AccessDenied", "throttled: SlowDown"}; !reflect.DeepEqual(still, expected) {
+		t.Fatalf("expected %v to be left, got %v", expected, still)
+	}
+	if n := server.RequestCount(http.MethodPost); n != multiDeleteMaxAttempts {
+		t.Fatalf("expected %d multi-delete requests, got %d", multiDeleteMaxAttempts, n)
+	}
+	if keys := server.Keys("bucket"); !reflect.DeepEqual(keys, []string{"denied", "throttled"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+}
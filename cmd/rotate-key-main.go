@@ -0,0 +1,222 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/pkg/v2/console"
+)
+
+var rotateKeyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "old-key",
+		Usage:  "SSE-C key the objects are encrypted with, 32 bytes or their base64 encoding",
+		EnvVar: "MC_ROTATE_OLD_KEY",
+	},
+	cli.StringFlag{
+		Name:   "new-key",
+		Usage:  "SSE-C key to encrypt the objects with, 32 bytes or their base64 encoding",
+		EnvVar: "MC_ROTATE_NEW_KEY",
+	},
+	cli.BoolFlag{
+		Name:  "recursive, r",
+		Usage: "rotate the key of all the objects under TARGET",
+	},
+}
+
+// Re-encrypt objects under a new SSE-C key.
+var rotateKeyCmd = cli.Command{
+	Name:         "rotate-key",
+	Usage:        "re-encrypt objects under a new SSE-C key without downloading them",
+	Action:       mainRotateKey,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(rotateKeyFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET [TARGET ...]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Rotate-key copies every object onto itself on the server, decrypting it
+  with --old-key and encrypting it with --new-key. The object data never
+  leaves the server. Objects not encrypted with --old-key are reported and
+  left as they are. Only the latest version of an object is re-encrypted.
+
+ENVIRONMENT VARIABLES:
+  MC_ROTATE_OLD_KEY:  SSE-C key the objects are encrypted with
+  MC_ROTATE_NEW_KEY:  SSE-C key to encrypt the objects with
+
+EXAMPLES:
+  1. Rotate the key of one object.
+     {{.Prompt}} {{.HelpName}} --old-key 32byteslongsecretkeymustbegiven1 --new-key 32byteslongsecretkeymustbegiven2 s3/mybucket/object.dat
+
+  2. Rotate the key of all the objects under a prefix, with base64 keys from the environment.
+     {{.Prompt}} export MC_ROTATE_OLD_KEY=MzJieXRlc2xvbmdzZWNyZXRrZXltdXN0YmVnaXZlbjE=
+     {{.Prompt}} export MC_ROTATE_NEW_KEY=MzJieXRlc2xvbmdzZWNyZXRrZXltdXN0YmVnaXZlbjI=
+     {{.Prompt}} {{.HelpName}} --recursive s3/mybucket/backups/
+`,
+}
+
+// rotateKeyMessage is printed for every re-encrypted object.
+type rotateKeyMessage struct {
+	Status string `json:"status"`
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+}
+
+func (m rotateKeyMessage) String() string {
+	return console.Colorize("RotateKey", fmt.Sprintf("Rotated the SSE-C key of `%s`.", m.URL))
+}
+
+func (m rotateKeyMessage) JSON() string {
+	m.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// decodeSSECKey returns the 32 bytes of an SSE-C key, given as is or
+// base64 encoded.
+func decodeSSECKey(key string) ([]byte, error) {
+	if len(key) == 32 {
+		return []byte(key), nil
+	}
+	decoded, e := base64.StdEncoding.DecodeString(key)
+	if e != nil || len(decoded) != 32 {
+		return nil, errors.New("an SSE-C key must be 32 bytes long or their base64 encoding")
+	}
+	return decoded, nil
+}
+
+// checkRotateKeySyntax returns the old and new SSE-C keys of cliCtx.
+func checkRotateKeySyntax(cliCtx *cli.Context) (oldSSE, newSSE encrypt.ServerSide) {
+	args := cliCtx.Args()
+	if len(args) == 0 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	if cliCtx.String("old-key") == "" || cliCtx.String("new-key") == "" {
+		fatalIf(errInvalidArgument().Trace(args...), "Both --old-key and --new-key are required.")
+	}
+	oldKey, e := decodeSSECKey(cliCtx.String("old-key"))
+	fatalIf(probe.NewError(e), "Invalid --old-key.")
+	newKey, e := decodeSSECKey(cliCtx.String("new-key"))
+	fatalIf(probe.NewError(e), "Invalid --new-key.")
+	if bytes.Equal(oldKey, newKey) {
+		fatalIf(errInvalidArgument().Trace(args...), "--old-key and --new-key must differ.")
+	}
+	oldSSE, e = encrypt.NewSSEC(oldKey)
+	fatalIf(probe.NewError(e), "Invalid --old-key.")
+	newSSE, e = encrypt.NewSSEC(newKey)
+	fatalIf(probe.NewError(e), "Invalid --new-key.")
+	return oldSSE, newSSE
+}
+
+// rotateObjectKey copies the object at source of clnt onto itself,
+// decrypting it with oldSSE and encrypting it with newSSE.
+func rotateObjectKey(ctx context.Context, clnt Client, source string, size int64, oldSSE, newSSE encrypt.ServerSide) *probe.Error {
+	return clnt.Copy(ctx, source, CopyOptions{
+		size:   size,
+		srcSSE: oldSSE,
+		tgtSSE: newSSE,
+	}, nil)
+}
+
+// rotateKey rotates the key of the object at targetURL, or of all the
+// objects under it if recursive.
+func rotateKey(ctx context.Context, targetURL string, recursive bool, oldSSE, newSSE encrypt.ServerSide) error {
+	clnt, err := newClient(targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+	defer clnt.Close()
+	if clnt.GetURL().Type != objectStorage {
+		fatalIf(errInvalidArgument().Trace(targetURL), "`"+targetURL+"` is not an object storage target.")
+	}
+
+	var cErr error
+	alias, _ := url2Alias(targetURL)
+	rotate := func(content *ClientContent) {
+		objectURL := content.URL.String()
+		objectClnt, err := newClientFromAlias(alias, objectURL)
+		if err == nil {
+			err = rotateObjectKey(ctx, objectClnt, content.URL.Path, content.Size, oldSSE, newSSE)
+		}
+		if err != nil {
+			errorIf(err.Trace(objectURL), "Unable to rotate the SSE-C key of `"+objectURL+"`.")
+			cErr = exitStatus(globalErrorExitStatus)
+			return
+		}
+		printMsg(rotateKeyMessage{URL: objectURL, Size: content.Size})
+	}
+
+	if !recursive {
+		content, err := clnt.Stat(ctx, StatOptions{sse: oldSSE})
+		if err != nil {
+			errorIf(err.Trace(targetURL), "Unable to stat `"+targetURL+"`.")
+			return exitStatus(globalErrorExitStatus)
+		}
+		rotate(content)
+		return cErr
+	}
+
+	// The objects are rotated as they are listed.
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(targetURL), "Unable to list `"+targetURL+"`.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		rotate(content)
+	}
+	return cErr
+}
+
+func mainRotateKey(cliCtx *cli.Context) error {
+	ctx, cancelRotateKey := context.WithCancel(globalContext)
+	defer cancelRotateKey()
+
+	console.SetColor("RotateKey", color.New(color.FgGreen))
+
+	oldSSE, newSSE := checkRotateKeySyntax(cliCtx)
+
+	targetURLs := make([]string, len(cliCtx.Args()))
+	for i, arg := range cliCtx.Args() {
+		targetURLs[i] = getFullPath(arg)
+	}
+
+	var cErr error
+	for _, targetURL := range targetURLs {
+		if e := rotateKey(ctx, targetURL, cliCtx.Bool("recursive"), oldSSE, newSSE); e != nil {
+			cErr = e
+		}
+	}
+	return cErr
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+func TestRotateObjectKey(t *testing.T) {
+	var copyHeader http.Header
+	server, _ := newS3TestFrontServer(t, func(server *miniotest.Server) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				copyHeader = r.Header.Clone()
+			}
+			server.ServeHTTP(w, r)
+		})
+	})
+	server.PutObject("bucket", "object", bytes.Repeat([]byte("a"), 100))
+	clnt := newS3TestClient(t, "s3test/bucket/object")
+
+	oldKey, newKey := "32byteslongsecretkeymustbegiven1", "32byteslongsecretkeymustbegiven2"
+	oldRaw, e := decodeSSECKey(oldKey)
+	if e != nil {
+		t.Fatal(e)
+	}
+	// The base64 encoding of a key is accepted too.
+	newRaw, e := decodeSSECKey(base64.StdEncoding.EncodeToString([]byte(newKey)))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if string(newRaw) != newKey {
+		t.Fatalf("expected the decoded key %q, got %q", newKey, newRaw)
+	}
+	if _, e := decodeSSECKey("short"); e == nil {
+		t.Fatal("expected an error for a short key")
+	}
+
+	oldSSE, e := encrypt.NewSSEC(oldRaw)
+	if e != nil {
+		t.Fatal(e)
+	}
+	newSSE, e := encrypt.NewSSEC(newRaw)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if err := rotateObjectKey(context.Background(), clnt, "/bucket/object", 100, oldSSE, newSSE); err != nil {
+		t.Fatal(err)
+	}
+
+	if copyHeader == nil || server.RequestCount(http.MethodPut) != 1 {
+		t.Fatal("expected a copy request")
+	}
+	if source := copyHeader.Get("X-Amz-Copy-Source"); source != "bucket/object" {
+		t.Fatalf("expected a copy of the object onto itself, got source %q", source)
+	}
+	for prefix, key := range map[string]string{
+		"X-Amz-Copy-Source-Server-Side-Encryption-Customer-": oldKey,
+		"X-Amz-Server-Side-Encryption-Customer-":             newKey,
+	} {
+		if algorithm := copyHeader.Get(prefix + "Algorithm"); algorithm != "AES256" {
+			t.Errorf("expected %sAlgorithm AES256, got %q", prefix, algorithm)
+		}
+		if got := copyHeader.Get(prefix + "Key"); got != base64.StdEncoding.EncodeToString([]byte(key)) {
+			t.Errorf("expected %sKey of %q, got %q", prefix, key, got)
+		}
+		if copyHeader.Get(prefix+"Key-Md5") == "" {
+			t.Errorf("expected %sKey-Md5", prefix)
+		}
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"strings"
 
 	"github.com/dustin/go-humanize"
-	"github.com/minio/pkg/v2/console"
 )
 
 // fixateScanBar truncates or stretches text to fit within the terminal size.
@@ -52,7 +51,7 @@ func scanBarFactory() scanBarFunc {
 		scanPrefix := fmt.Sprintf("[%s] %s ", humanize.Comma(int64(fileCount)), <-cursorCh)
 		source = fixateScanBar(source, globalTermWidth-len([]rune(scanPrefix)))
 		barText := scanPrefix + source
-		console.PrintC("\r" + barText + "\r")
+		fmt.Fprint(statusOutput, "\r"+barText+"\r")
 		fileCount++
 	}
 }
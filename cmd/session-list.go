@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var sessionListCmd = cli.Command{
+	Name:         "list",
+	Aliases:      []string{"ls"},
+	Usage:        "list the uploads which can be resumed",
+	Action:       mainSessionList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List the uploads which can be resumed, with how much of them is done
+     {{.Prompt}} {{.HelpName}}
+`,
+}
+
+// sessionListMessage is a session printed by 'session list'.
+type sessionListMessage struct {
+	Status       string    `json:"status"`
+	SessionID    string    `json:"sessionId"`
+	Created      time.Time `json:"created"`
+	Expires      time.Time `json:"expires"`
+	Percent      float64   `json:"percent"`
+	Objects      int64     `json:"objects"`
+	TotalObjects int64     `json:"totalObjects"`
+	Bytes        int64     `json:"bytes"`
+	TotalBytes   int64     `json:"totalBytes"`
+	Planned      bool      `json:"planned"`
+	Args         []string  `json:"args"`
+}
+
+func (s sessionListMessage) String() string {
+	total := fmt.Sprint(s.TotalObjects)
+	if !s.Planned {
+		total += "+" // the source was not listed entirely.
+	}
+	return fmt.Sprintf("%s  [%s]  %5.1f%%  %d/%s objects  %s/%s  mc put %s",
+		s.SessionID, formatListTime(s.Created.Local()), s.Percent, s.Objects, total,
+		formatSize(s.Bytes), formatSize(s.TotalBytes), strings.Join(s.Args, " "))
+}
+
+func (s sessionListMessage) JSON() string {
+	s.Status = "success"
+	msgBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// newSessionListMessage returns the message of s, complete by bytes or,
+// without any, by objects.
+func newSessionListMessage(s *putSession) sessionListMessage {
+	objects, totalObjects, bytes, totalBytes := s.progress()
+	var percent float64
+	switch {
+	case totalBytes > 0:
+		percent = float64(bytes) * 100 / float64(totalBytes)
+	case totalObjects > 0:
+		percent = float64(objects) * 100 / float64(totalObjects)
+	}
+	return sessionListMessage{
+		SessionID:    s.header.ID,
+		Created:      s.header.Created,
+		Expires:      s.header.Expires,
+		Percent:      percent,
+		Objects:      objects,
+		TotalObjects: totalObjects,
+		Bytes:        bytes,
+		TotalBytes:   totalBytes,
+		Planned:      s.header.Planned,
+		Args:         s.header.Args,
+	}
+}
+
+// mainSessionList is the handle for "mc session list" command.
+func mainSessionList(cliCtx *cli.Context) error {
+	if cliCtx.NArg() != 0 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	removeExpiredPutSessions()
+	sessions, err := listPutSessions()
+	fatalIf(err, "Unable to list the sessions.")
+	for _, s := range sessions {
+		printMsg(newSessionListMessage(s))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var sessionResumeCmd = cli.Command{
+	Name:         "resume",
+	Usage:        "finish an interrupted upload",
+	Action:       mainSessionResume,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append([]cli.Flag{ioFlags[0]}, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] SESSION-ID
+
+DESCRIPTION:
+  Upload the files of an interrupted 'mc put' which are not uploaded yet, with
+  its flags and from its working folder. Files which changed since the upload
+  started are reported and not uploaded.
+
+  Secret flags, such as --encrypt-key, are not saved in the session and must
+  be given again to resume it.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Resume the upload of session 'a3f09c1e'
+     {{.Prompt}} {{.HelpName}} a3f09c1e
+  2. Resume the upload of session 'a3f09c1e', started with --encrypt-key
+     {{.Prompt}} {{.HelpName}} --encrypt-key "myminio/mybucket/=32byteslongsecretkeymustbegiven1" a3f09c1e
+`,
+}
+
+// mainSessionResume is the handle for "mc session resume" command.
+func mainSessionResume(cliCtx *cli.Context) error {
+	if cliCtx.NArg() != 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	id := cliCtx.Args().First()
+	removeExpiredPutSessions()
+	session, err := loadPutSession(id)
+	fatalIf(err.Trace(id), "Unable to load session `"+id+"`.")
+	fatalIf(probe.NewError(os.Chdir(session.header.WorkingFolder)).Trace(session.header.WorkingFolder),
+		"Unable to change to the working folder of session `"+id+"`.")
+
+	args, err := putSessionResumeArgs(cliCtx, session)
+	fatalIf(err.Trace(id), "Unable to resume session `"+id+"`.")
+
+	putSessionToResume = session
+	set := flag.NewFlagSet(putCmd.Name, flag.ContinueOnError)
+	if e := set.Parse(append([]string{putCmd.Name}, args...)); e != nil {
+		return e
+	}
+	return putCmd.Run(cli.NewContext(cliCtx.App, set, cliCtx.Parent()))
+}
+
+// putSessionResumeArgs returns the arguments of the put of session, with
+// the secret flags it was started with given again to cliCtx.
+func putSessionResumeArgs(cliCtx *cli.Context, session *putSession) ([]string, *probe.Error) {
+	var args []string
+	for _, name := range session.header.SecretFlags {
+		if !cliCtx.IsSet(name) {
+			return nil, probe.NewError(fmt.Errorf("the session was started with --%s, which is not saved, give it again", name))
+		}
+		args = append(args, "--"+name+"="+cliCtx.String(name))
+	}
+	return append(args, session.header.Args...), nil
+}
@@ -122,6 +122,7 @@ func doShareDownloadURL(ctx context.Context, targetURL, versionID string, isRecu
 	if err != nil {
 		return err.Trace(targetURL)
 	}
+	expiry = clampShareExpiry(clnt, expiry)
 
 	// Load previously saved upload-shares. Add new entries and write it back.
 	shareDB := newShareDBV1()
@@ -23,7 +23,6 @@ import (
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
-	"github.com/minio/pkg/v2/console"
 )
 
 var shareFlags = []cli.Flag{}
@@ -57,7 +56,7 @@ func migrateShare() {
 		// Old file exits.
 		e := os.Remove(oldShareFile)
 		fatalIf(probe.NewError(e), "Unable to delete old `"+oldShareFile+"`.")
-		console.Infof("Removed older version of share `%s` file.\n", oldShareFile)
+		statusf("Removed older version of share `%s` file.\n", oldShareFile)
 	}
 }
 
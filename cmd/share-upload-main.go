@@ -155,6 +155,7 @@ func doShareUploadURL(ctx context.Context, objectURL string, isRecursive bool, e
 	if err != nil {
 		return err.Trace(objectURL)
 	}
+	expiry = clampShareExpiry(clnt, expiry)
 
 	// Generate pre-signed access info.
 	shareURL, uploadInfo, err := clnt.ShareUpload(ctx, isRecursive, expiry, contentType)
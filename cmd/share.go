@@ -103,6 +103,24 @@ func shareSetColor() {
 	console.SetColor("File", color.New(color.FgRed, color.Bold))
 }
 
+// clampShareExpiry returns expiry clamped to the remaining lifetime of the
+// session token used by clnt, warning when the expiry had to be shortened.
+// It exits if the session token has expired.
+func clampShareExpiry(clnt Client, expiry time.Duration) time.Duration {
+	s3Clnt, ok := clnt.(*S3Client)
+	if !ok {
+		return expiry
+	}
+	clamped, err := s3Clnt.presignExpiry(expiry)
+	fatalIf(err.Trace(clnt.GetURL().String()), "Unable to share `"+clnt.GetURL().String()+"`.")
+	if clamped < expiry && !globalJSON {
+		statusf("[Warn] Expiry reduced from %s to %s, the session token expires at %s.\n",
+			timeDurationToHumanizedDuration(expiry), timeDurationToHumanizedDuration(clamped),
+			s3Clnt.sessionExpiry.Format(printDate))
+	}
+	return clamped
+}
+
 // Get share dir name.
 func getShareDir() (string, *probe.Error) {
 	configDir, err := getMcConfigDir()
@@ -180,7 +198,7 @@ func initShareConfig() {
 		fatalIf(createShareDir().Trace(mustGetShareDir()),
 			"Failed to create share `"+mustGetShareDir()+"` folder.")
 		if !globalQuiet && !globalJSON {
-			console.Infof("Successfully created `%s`.\n", mustGetShareDir())
+			statusf("Successfully created `%s`.\n", mustGetShareDir())
 		}
 	}
 
@@ -189,7 +207,7 @@ func initShareConfig() {
 		fatalIf(initShareUploadsFile().Trace(getShareUploadsFile()),
 			"Failed to initialize share uploads `"+getShareUploadsFile()+"` file.")
 		if !globalQuiet && !globalJSON {
-			console.Infof("Initialized share uploads `%s` file.\n", getShareUploadsFile())
+			statusf("Initialized share uploads `%s` file.\n", getShareUploadsFile())
 		}
 	}
 
@@ -198,7 +216,7 @@ func initShareConfig() {
 		fatalIf(initShareDownloadsFile().Trace(getShareDownloadsFile()),
 			"Failed to initialize share downloads `"+getShareDownloadsFile()+"` file.")
 		if !globalQuiet && !globalJSON {
-			console.Infof("Initialized share downloads `%s` file.\n", getShareDownloadsFile())
+			statusf("Initialized share downloads `%s` file.\n", getShareDownloadsFile())
 		}
 	}
 }
@@ -45,6 +45,9 @@ func trapSignals(sig ...os.Signal) {
 	// Cancel the global context
 	globalCancel()
 
+	// The transfers are not waited for, remove their temporary files.
+	removeTempFiles()
+
 	var exitCode int
 	switch s.String() {
 	case "interrupt":
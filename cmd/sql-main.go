@@ -92,6 +92,7 @@ FLAGS:
   {{end}}{{end}}
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY: list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
 
 SERIALIZATION OPTIONS:
   For query serialization options, refer to https://min.io/docs/minio/linux/reference/minio-mc/mc-sql.html#command-mc.sql
@@ -471,6 +472,7 @@ func mainSQL(cliCtx *cli.Context) error {
 			errorIf(err.Trace(url), "Unable to initialize target `"+url+"`.")
 			continue
 		}
+		defer clnt.Close()
 
 		for content := range clnt.List(ctx, ListOptions{Recursive: cliCtx.Bool("recursive"), WithMetadata: true, ShowDir: DirNone}) {
 			if content.Err != nil {
@@ -0,0 +1,291 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultStallTimeout is the default --stall-timeout.
+const defaultStallTimeout = 2 * time.Minute
+
+// transferStalledError is returned for a request canceled by the stall
+// watchdog, it is a transient error so that the request is sent again
+// on a fresh connection.
+type transferStalledError struct {
+	Method     string
+	Object     string
+	PartNumber string
+	Timeout    time.Duration
+}
+
+func (e transferStalledError) Error() string {
+	return fmt.Sprintf("no progress for %s on %s, the connection looks stalled", e.Timeout, e.identity())
+}
+
+func (e transferStalledError) identity() string {
+	if e.PartNumber != "" {
+		return fmt.Sprintf("%s %s part %s", e.Method, e.Object, e.PartNumber)
+	}
+	return e.Method + " " + e.Object
+}
+
+// isCompleteMultipartUpload returns true for CompleteMultipartUpload
+// requests, the server may legitimately take minutes to answer them
+// while only sending whitespace to keep the connection alive.
+func isCompleteMultipartUpload(req *http.Request) bool {
+	return req.Method == http.MethodPost && req.URL.Query().Has("uploadId")
+}
+
+// stallTransport cancels requests whose body stops moving for longer
+// than timeout, in either direction. Half-open connections, typically
+// through a NAT, otherwise hang a transfer without any error. Only the
+// transfer of bodies is watched, the wait for the response headers is
+// left to the connection deadlines.
+type stallTransport struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+}
+
+func newStallTransport(timeout time.Duration, transport http.RoundTripper) http.RoundTripper {
+	if timeout <= 0 {
+		return transport
+	}
+	return &stallTransport{transport: transport, timeout: timeout}
+}
+
+func (t *stallTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isCompleteMultipartUpload(req) {
+		return t.transport.RoundTrip(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	w := &stallWatch{
+		timeout: t.timeout,
+		cancel:  cancel,
+		err: transferStalledError{
+			Method:     req.Method,
+			Object:     strings.TrimPrefix(req.URL.Path, "/"),
+			PartNumber: req.URL.Query().Get("partNumber"),
+			Timeout:    t.timeout,
+		},
+		done: make(chan struct{}),
+	}
+	go w.run(ctx)
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { w.setConn(info.Conn) },
+	})
+
+	req = req.Clone(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		w.start()
+		req.Body = &stallBody{ReadCloser: req.Body, watch: w, request: true}
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		w.close()
+		if w.fired() {
+			return nil, w.err
+		}
+		return nil, err
+	}
+	if resp.Body == nil {
+		w.close()
+		return resp, nil
+	}
+	// The response body is watched while it is read.
+	w.pause()
+	resp.Body = &stallBody{ReadCloser: resp.Body, watch: w}
+	return resp, nil
+}
+
+// stallWatch cancels a request once no byte has been transferred for
+// timeout while the watch is active. The connection of the request is
+// closed too when nothing at all went through it meanwhile, so that a
+// dead connection is not reused, by the retry or by the other requests
+// sharing it over HTTP/2.
+type stallWatch struct {
+	timeout time.Duration
+	cancel  context.CancelFunc
+	err     transferStalledError
+	conn    atomic.Value // *activityConn
+
+	active       int32
+	lastProgress int64
+	stalled      int32
+	done         chan struct{}
+	once         sync.Once
+}
+
+func (w *stallWatch) progress() {
+	atomic.StoreInt64(&w.lastProgress, time.Now().UnixNano())
+}
+
+// start watches the transfer from now on.
+func (w *stallWatch) start() {
+	w.progress()
+	atomic.StoreInt32(&w.active, 1)
+}
+
+// pause stops watching, until the next start.
+func (w *stallWatch) pause() {
+	atomic.StoreInt32(&w.active, 0)
+}
+
+// setConn records the connection of the request, if it was dialed by
+// newCustomDialContext.
+func (w *stallWatch) setConn(conn net.Conn) {
+	if tlsConn, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = tlsConn.NetConn()
+	}
+	if c, ok := conn.(*activityConn); ok {
+		w.conn.Store(c)
+	}
+}
+
+// closeDeadConn closes the connection of the request if it made no
+// progress for timeout.
+func (w *stallWatch) closeDeadConn() {
+	if c, ok := w.conn.Load().(*activityConn); ok && c.idle() >= w.timeout {
+		c.Close()
+	}
+}
+
+func (w *stallWatch) fired() bool {
+	return atomic.LoadInt32(&w.stalled) == 1
+}
+
+func (w *stallWatch) close() {
+	w.once.Do(func() {
+		close(w.done)
+		w.cancel()
+	})
+}
+
+func (w *stallWatch) run(ctx context.Context) {
+	ticker := time.NewTicker(w.timeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&w.active) == 0 {
+				continue
+			}
+			if time.Since(time.Unix(0, atomic.LoadInt64(&w.lastProgress))) < w.timeout {
+				continue
+			}
+			atomic.StoreInt32(&w.stalled, 1)
+			if globalDebug || (!globalQuiet && !globalJSON) {
+				statusf("[Warn] %s has made no progress for %s, retrying it on a new connection.\n", w.err.identity(), w.timeout)
+			}
+			w.cancel()
+			w.closeDeadConn()
+			return
+		}
+	}
+}
+
+// stallBody reports the progress of a request or a response body. Only
+// the time spent waiting for the connection is watched: a request body
+// is watched in between the reads of the transport, while it is sent,
+// and not while its source is read, a response body while it is read,
+// and not while its consumer handles what it read.
+type stallBody struct {
+	io.ReadCloser
+	watch   *stallWatch
+	request bool
+}
+
+func (b *stallBody) Read(p []byte) (n int, err error) {
+	if b.request {
+		b.watch.pause()
+	} else {
+		b.watch.start()
+	}
+	n, err = b.ReadCloser.Read(p)
+	if err != nil && b.watch.fired() {
+		return n, b.watch.err
+	}
+	if err == io.EOF || !b.request {
+		// Either the server is processing the request or the
+		// response is complete, or its consumer handles what it
+		// read, there is nothing left to watch for now.
+		b.watch.pause()
+	} else {
+		b.watch.start()
+	}
+	return n, err
+}
+
+func (b *stallBody) Close() error {
+	err := b.ReadCloser.Close()
+	if !b.request {
+		b.watch.close()
+	}
+	return err
+}
+
+// activityConn records when data last went through a connection either
+// way, to tell a dead connection from a stalled request.
+type activityConn struct {
+	net.Conn
+	lastActivity int64
+}
+
+func newActivityConn(conn net.Conn) *activityConn {
+	c := &activityConn{Conn: conn}
+	c.active()
+	return c
+}
+
+func (c *activityConn) active() {
+	atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
+}
+
+// idle returns the time since data last went through the connection.
+func (c *activityConn) idle() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&c.lastActivity)))
+}
+
+func (c *activityConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.active()
+	}
+	return n, err
+}
+
+func (c *activityConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.active()
+	}
+	return n, err
+}
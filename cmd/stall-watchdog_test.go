@@ -0,0 +1,365 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStallTransportResponseBody(t *testing.T) {
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("some"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Transport: newStallTransport(100*time.Millisecond, http.DefaultTransport)}
+	resp, err := client.Get(server.URL + "/bucket/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	_, err = io.ReadAll(resp.Body)
+	var stalled transferStalledError
+	if !errors.As(err, &stalled) {
+		t.Fatalf("expected a stalled transfer, got %v", err)
+	}
+	if stalled.Object != "bucket/object" || !isResumableReadError(err) {
+		t.Fatalf("unexpected stalled transfer %+v", stalled)
+	}
+}
+
+// slowReader returns its data a byte at a time, after a delay.
+type slowReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	n := copy(p[:1], r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestStallTransportSlowEnds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer server.Close()
+
+	// Neither the slow source of the request nor the slow consumer of
+	// the response stall the connection.
+	client := &http.Client{Transport: newStallTransport(100*time.Millisecond, http.DefaultTransport)}
+	resp, err := client.Post(server.URL+"/bucket/object", "application/octet-stream", &slowReader{data: []byte("some"), delay: 150 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := resp.Body.Read(buf)
+		body = append(body, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(150 * time.Millisecond)
+	}
+	if string(body) != "some" {
+		t.Fatalf("unexpected response %q", body)
+	}
+}
+
+func TestStallTransportSkipsCompleteMultipartUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server sends the headers and then takes its time to
+		// complete the upload.
+		w.Write([]byte(" "))
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("<CompleteMultipartUploadResult/>"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newStallTransport(100*time.Millisecond, http.DefaultTransport)}
+	resp, err := client.Post(server.URL+"/bucket/object?uploadId=upload", "application/xml", strings.NewReader("<CompleteMultipartUpload/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expected CompleteMultipartUpload to be left alone, got %v", err)
+	}
+	if !strings.Contains(string(body), "CompleteMultipartUploadResult") {
+		t.Fatalf("unexpected response %q", body)
+	}
+}
+
+func TestStallTransportRetriesUpload(t *testing.T) {
+	defer func(unit time.Duration) { transientRetryUnit = unit }(transientRetryUnit)
+	transientRetryUnit = time.Millisecond
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	var attempts int32
+	var mu sync.Mutex
+	var stuck []net.Conn
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// Never read the body, like a half-open connection.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			stuck = append(stuck, conn)
+			mu.Unlock()
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range stuck {
+			conn.Close()
+		}
+	}()
+
+	client := &http.Client{Transport: getTransportForConfig(&Config{HostURL: server.URL, StallTimeout: 200 * time.Millisecond}, false)}
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/bucket/object?partNumber=3&uploadId=upload", bytes.NewReader(make([]byte, 64<<20)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expected the stalled part to be retried, got %v", err)
+	}
+	resp.Body.Close()
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+}
+
+// deadConnProxy forwards TCP connections to a server, until kill leaves
+// the connections open so far without forwarding anything either way,
+// like a NAT which silently dropped them.
+type deadConnProxy struct {
+	listener net.Listener
+	target   string
+	killed   int32
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func newDeadConnProxy(t *testing.T, target string) *deadConnProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &deadConnProxy{listener: listener, target: target}
+	go p.serve()
+	return p
+}
+
+func (p *deadConnProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		server, err := net.Dial("tcp", p.target)
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		p.mu.Lock()
+		p.conns = append(p.conns, conn)
+		p.mu.Unlock()
+		killed := atomic.LoadInt32(&p.killed) == 1
+		go p.forward(conn, server, killed)
+		go p.forward(server, conn, killed)
+	}
+}
+
+// forward copies src to dst until the connections opened before kill
+// stop being forwarded, or one of them is closed.
+func (p *deadConnProxy) forward(dst, src net.Conn, openedKilled bool) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := src.Read(buf)
+		if !openedKilled && atomic.LoadInt32(&p.killed) == 1 {
+			return
+		}
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				src.Close()
+				return
+			}
+		}
+		if err != nil {
+			dst.Close()
+			return
+		}
+	}
+}
+
+func (p *deadConnProxy) kill() {
+	atomic.StoreInt32(&p.killed, 1)
+}
+
+func (p *deadConnProxy) close() {
+	p.listener.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+}
+
+func (p *deadConnProxy) accepted() []net.Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]net.Conn(nil), p.conns...)
+}
+
+func TestStallTransportDeadConnection(t *testing.T) {
+	defer func(unit time.Duration) { transientRetryUnit = unit }(transientRetryUnit)
+	transientRetryUnit = time.Millisecond
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+	proxy := newDeadConnProxy(t, server.Listener.Addr().String())
+	defer proxy.close()
+
+	const stallTimeout = 200 * time.Millisecond
+	config := &Config{HostURL: "http://" + proxy.listener.Addr().String(), StallTimeout: stallTimeout, TCPKeepAlive: time.Second}
+	client := &http.Client{Transport: getTransportForConfig(config, false)}
+	put := func() error {
+		req, err := http.NewRequest(http.MethodPut, config.HostURL+"/bucket/object", bytes.NewReader(make([]byte, 64<<20)))
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	// The idle connection of the first upload dies before the second
+	// upload reuses it.
+	if err := put(); err != nil {
+		t.Fatal(err)
+	}
+	proxy.kill()
+	start := time.Now()
+	if err := put(); err != nil {
+		t.Fatalf("expected the upload to recover from the dead connection, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*stallTimeout {
+		t.Fatalf("expected the upload to recover within %s, took %s", 5*stallTimeout, elapsed)
+	}
+
+	conns := proxy.accepted()
+	if len(conns) != 2 {
+		t.Fatalf("expected a new connection after the dead one, got %d connections", len(conns))
+	}
+	// The dead connection is closed by the client, not left in the pool.
+	conns[0].SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.Copy(io.Discard, conns[0]); err != nil {
+		t.Fatalf("expected the dead connection to be closed, got %v", err)
+	}
+}
+
+func TestStallWatchClosesDeadConn(t *testing.T) {
+	for _, testCase := range []struct {
+		idle   time.Duration
+		closed bool
+	}{
+		{idle: time.Minute, closed: true},
+		{idle: 0, closed: false},
+	} {
+		client, server := net.Pipe()
+		defer server.Close()
+		conn := newActivityConn(client)
+		atomic.StoreInt64(&conn.lastActivity, time.Now().Add(-testCase.idle).UnixNano())
+		w := &stallWatch{timeout: 10 * time.Second}
+		w.setConn(conn)
+		w.closeDeadConn()
+
+		go io.Copy(io.Discard, server)
+		_, err := conn.Write([]byte("x"))
+		if closed := errors.Is(err, io.ErrClosedPipe); closed != testCase.closed {
+			t.Fatalf("idle for %s: expected closed=%v, got %v", testCase.idle, testCase.closed, err)
+		}
+		conn.Close()
+	}
+}
+
+func TestJitterKeepAlive(t *testing.T) {
+	if d := jitterKeepAlive(-1); d != -1 {
+		t.Fatalf("expected disabled keep-alives to stay disabled, got %s", d)
+	}
+	for _, d := range []time.Duration{0, time.Second, 30 * time.Second} {
+		expected := d
+		if d == 0 {
+			expected = defaultTCPKeepAlive
+		}
+		for i := 0; i < 100; i++ {
+			if got := jitterKeepAlive(d); got < expected*9/10 || got > expected*11/10 {
+				t.Fatalf("expected %s within 10%% of %s", got, expected)
+			}
+		}
+	}
+}
@@ -68,6 +68,13 @@ FLAGS:
   {{end}}
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY_FILE: file of prefix=secret values, one per line
+
+FORMAT FIELDS:
+  --format templates are applied to every object and may use the fields
+  Status, Key, Date, Size, ETag, Type, Expires, Expiration, ExpirationRuleID,
+  ReplicationStatus, Metadata, VersionID, DeleteMarker and Restore, as well
+  as the functions 'humanize SIZE' and 'formatTime LAYOUT TIME'.
 
 EXAMPLES:
   1. Stat all contents of mybucket on Amazon S3 cloud storage.
@@ -91,6 +98,9 @@ EXAMPLES:
 
   7. Stat all objects versions recursively created before 1st January 2020.
      {{.Prompt}} {{.HelpName}} --versions --rewind 2020.01.01T00:00 s3/personal-docs/
+
+  8. Print the modification day and content type of an object.
+     {{.Prompt}} {{.HelpName}} --format '{{"{{formatTime \"2006-01-02\" .Date}} {{index .Metadata \"Content-Type\"}}"}}' s3/personal-docs/2018-account_report.docx
 `,
 }
 
@@ -45,6 +45,7 @@ type statMessage struct {
 	Date              time.Time          `json:"lastModified"`
 	Size              int64              `json:"size"`
 	ETag              string             `json:"etag"`
+	SHA256            string             `json:"sha256,omitempty"`
 	Type              string             `json:"type,omitempty"`
 	Expires           *time.Time         `json:"expires,omitempty"`
 	Expiration        *time.Time         `json:"expiration,omitempty"`
@@ -65,12 +66,15 @@ func (stat statMessage) String() (msg string) {
 		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s ", "Date", stat.Date.Format(printDate)) + "\n")
 	}
 	if stat.Type != "folder" {
-		msgBuilder.WriteString(fmt.Sprintf("%-10s: %-6s ", "Size", humanize.IBytes(uint64(stat.Size))) + "\n")
+		msgBuilder.WriteString(fmt.Sprintf("%-10s: %-6s ", "Size", formatSize(stat.Size)) + "\n")
 	}
 
 	if stat.ETag != "" {
 		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s ", "ETag", stat.ETag) + "\n")
 	}
+	if stat.SHA256 != "" {
+		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s ", "SHA256", stat.SHA256) + "\n")
+	}
 	if stat.VersionID != "" {
 		versionIDField := stat.VersionID
 		if stat.DeleteMarker {
@@ -163,6 +167,7 @@ func parseStat(c *ClientContent) statMessage {
 	content.Metadata = c.Metadata
 	content.ETag = strings.TrimPrefix(c.ETag, "\"")
 	content.ETag = strings.TrimSuffix(content.ETag, "\"")
+	content.SHA256 = storedSHA256(c)
 	if !c.Expires.IsZero() {
 		content.Expires = &c.Expires
 	}
@@ -451,7 +456,7 @@ func (v bucketInfoMessage) String() string {
 
 	fmt.Fprint(&b, console.Colorize("Title", "Usage:\n"))
 
-	fmt.Fprintf(&b, "%16s: %s\n", "Total size", console.Colorize("Count", humanize.IBytes(v.Usage.Size)))
+	fmt.Fprintf(&b, "%16s: %s\n", "Total size", console.Colorize("Count", formatSize(int64(v.Usage.Size))))
 	fmt.Fprintf(&b, "%16s: %s\n", "Objects count", console.Colorize("Count", humanize.Comma(int64(v.Usage.ObjectsCount))))
 	fmt.Fprintf(&b, "%16s: %s\n", "Versions count", console.Colorize("Count", humanize.Comma(int64(v.Usage.VersionsCount))))
 	fmt.Fprintf(&b, "\n")
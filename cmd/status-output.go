@@ -0,0 +1,42 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/minio/pkg/v2/console"
+)
+
+// statusOutput receives progress bars, scan bars, prompts and
+// informational messages, so that stdout only carries object data and
+// records, the latter as NDJSON with --json.
+var statusOutput io.Writer = os.Stderr
+
+// eraseStatusLine clears the line of an ongoing progress bar, so that
+// the next message is not printed over it.
+func eraseStatusLine() {
+	fmt.Fprintf(statusOutput, "%c[2K\n%c[A", 27, 27)
+}
+
+// statusf prints an informational message to statusOutput.
+func statusf(format string, a ...interface{}) {
+	fmt.Fprintf(statusOutput, console.ProgramName()+": "+format, a...)
+}
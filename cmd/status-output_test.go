@@ -0,0 +1,61 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStatusOutput(t *testing.T) {
+	r, w, e := os.Pipe()
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer func(stdout *os.File, output io.Writer) {
+		os.Stdout, statusOutput = stdout, output
+	}(os.Stdout, statusOutput)
+	var status bytes.Buffer
+	os.Stdout, statusOutput = w, &status
+
+	bar := newProgressBar(int64(len("object data")), statusOutput)
+	if _, e = io.Copy(io.Discard, io.TeeReader(strings.NewReader("object data"), bar)); e != nil {
+		t.Fatal(e)
+	}
+	bar.Finish()
+	scanBarFactory()("gpumall/dir/object")
+	eraseStatusLine()
+	statusf("[Warn] %s\n", "retrying")
+	if _, e = io.Copy(io.Discard, newProgressReader(strings.NewReader("update"), "mc", 6, statusOutput)); e != nil {
+		t.Fatal(e)
+	}
+
+	w.Close()
+	stdout, _ := io.ReadAll(r)
+	if len(stdout) != 0 {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	for _, want := range []string{"\r", "gpumall/dir/object", "[Warn] retrying"} {
+		if !strings.Contains(status.String(), want) {
+			t.Errorf("expected %q in the status output, got %q", want, status.String())
+		}
+	}
+}
@@ -18,11 +18,11 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"sync/atomic"
 
 	"github.com/minio/mc/pkg/probe"
-	"github.com/minio/pkg/v2/console"
 )
 
 // Status implements a interface that can be used in quit mode or with progressbar.
@@ -144,7 +144,7 @@ func (qs *QuietStatus) fatalIf(err *probe.Error, msg string) {
 // NewProgressStatus returns a progress status object
 func NewProgressStatus(hook io.Reader) Status {
 	return &ProgressStatus{
-		progressBar: newProgressBar(0),
+		progressBar: newProgressBar(0, statusOutput),
 		hook:        hook,
 	}
 }
@@ -209,8 +209,8 @@ func (ps *ProgressStatus) Add(v int64) Status {
 
 // Println prints line, ignored for quietstatus
 func (ps *ProgressStatus) Println(data ...interface{}) {
-	console.Eraseline()
-	console.Println(data...)
+	eraseStatusLine()
+	fmt.Fprintln(statusOutput, data...)
 }
 
 // PrintMsg prints message
@@ -234,7 +234,7 @@ func (ps *ProgressStatus) Update() {
 
 func (ps *ProgressStatus) errorIf(err *probe.Error, msg string) {
 	// remove progressbar
-	console.Eraseline()
+	eraseStatusLine()
 	errorIf(err, msg)
 
 	ps.progressBar.Update()
@@ -242,7 +242,7 @@ func (ps *ProgressStatus) errorIf(err *probe.Error, msg string) {
 
 func (ps *ProgressStatus) fatalIf(err *probe.Error, msg string) {
 	// remove progressbar
-	console.Eraseline()
+	eraseStatusLine()
 	fatalIf(err, msg)
 
 	ps.progressBar.Update()
@@ -0,0 +1,155 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/pkg/v2/console"
+)
+
+// --store-checksum records the SHA-256 sum of an uploaded object in its
+// metadata, where stat shows it and verify checks it. Unlike the ETag,
+// the sum does not depend on how the object was split in parts or on its
+// encryption.
+
+// sha256MetaKey is the metadata header holding the SHA-256 sum of an
+// object uploaded with --store-checksum.
+const sha256MetaKey = "X-Amz-Meta-Sha256"
+
+var storeChecksumFlag = cli.BoolFlag{
+	Name:  "store-checksum",
+	Usage: "store the SHA-256 sum of the uploaded objects in their metadata, for stat and verify",
+}
+
+// storedSHA256 returns the SHA-256 sum stored with the object of content,
+// or "" when it has none.
+func storedSHA256(content *ClientContent) string {
+	if content == nil {
+		return ""
+	}
+	for k, v := range content.Metadata {
+		if strings.EqualFold(k, sha256MetaKey) {
+			return strings.ToLower(v)
+		}
+	}
+	for k, v := range content.UserMetadata {
+		if strings.EqualFold(k, sha256MetaKey) {
+			return strings.ToLower(v)
+		}
+	}
+	return ""
+}
+
+// isSHA256Sum tells if s is a SHA-256 sum in hex.
+func isSHA256Sum(s string) bool {
+	if len(s) != 2*sha256.Size {
+		return false
+	}
+	_, e := hex.DecodeString(s)
+	return e == nil
+}
+
+// maxCopyObjectSize is the largest object copied with a single request.
+const maxCopyObjectSize = 5 << 30
+
+// storeSHA256 stores sum in the metadata of object, uploaded with opts,
+// by copying the object onto itself with its metadata replaced: the sum
+// is only known once the object is uploaded, after its metadata was sent.
+// The object is only copied if it is still the one uploaded, the version
+// uploaded without the sum is removed.
+func (c *S3Client) storeSHA256(ctx context.Context, bucket, object string, ui minio.UploadInfo, sum string, opts minio.PutObjectOptions) error {
+	metadata := make(map[string]string, len(opts.UserMetadata)+1)
+	for k, v := range opts.UserMetadata {
+		metadata[k] = v
+	}
+	for k, v := range map[string]string{
+		"Content-Type":        opts.ContentType,
+		"Cache-Control":       opts.CacheControl,
+		"Content-Disposition": opts.ContentDisposition,
+		"Content-Encoding":    opts.ContentEncoding,
+		"Content-Language":    opts.ContentLanguage,
+		"X-Amz-Storage-Class": opts.StorageClass,
+	} {
+		if v != "" {
+			metadata[k] = v
+		}
+	}
+	metadata[sha256MetaKey] = sum
+
+	src := minio.CopySrcOptions{
+		Bucket:    bucket,
+		Object:    object,
+		VersionID: ui.VersionID,
+		MatchETag: ui.ETag,
+	}
+	if opts.ServerSideEncryption != nil && opts.ServerSideEncryption.Type() == encrypt.SSEC {
+		src.Encryption = opts.ServerSideEncryption
+	}
+	dst := minio.CopyDestOptions{
+		Bucket:          bucket,
+		Object:          object,
+		Encryption:      opts.ServerSideEncryption,
+		UserMetadata:    metadata,
+		ReplaceMetadata: true,
+		Mode:            opts.Mode,
+		RetainUntilDate: opts.RetainUntilDate,
+		LegalHold:       opts.LegalHold,
+	}
+	var info minio.UploadInfo
+	var e error
+	if ui.Size > maxCopyObjectSize {
+		info, e = c.api.ComposeObject(ctx, dst, src)
+	} else {
+		info, e = c.api.CopyObject(ctx, dst, src)
+	}
+	if e != nil {
+		return e
+	}
+	if ui.VersionID != "" && ui.VersionID != info.VersionID {
+		e = c.api.RemoveObject(ctx, bucket, object, minio.RemoveObjectOptions{VersionID: ui.VersionID})
+		if e != nil && globalDebug {
+			console.Debugln(fmt.Sprintf("Unable to remove the version %s of `%s/%s` without its SHA-256 sum: %v", ui.VersionID, bucket, object, e))
+		}
+	}
+	return nil
+}
+
+// fileSHA256 computes the SHA-256 sum of a local file.
+func fileSHA256(path string) (string, *probe.Error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, e = io.Copy(hasher, f); e != nil {
+		return "", probe.NewError(e)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
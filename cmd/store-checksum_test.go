@@ -0,0 +1,175 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestStoreChecksum(t *testing.T) {
+	initTestConfig(t)
+	defer func(c *md5Cache) { globalMD5Cache = c }(globalMD5Cache)
+	globalMD5Cache = loadMD5Cache("")
+	server := miniotest.NewServer()
+	defer server.Close()
+	t.Setenv(mcEnvHostPrefix+"sum", server.AliasURL())
+	server.MakeBucket("bucket")
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"small": []byte("hello"),
+		// Large enough to be uploaded in parts read in parallel.
+		"large": bytes.Repeat([]byte("0123456789abcdef"), 6<<20/16),
+	}
+	ctx := context.Background()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if e := os.WriteFile(path, data, 0o600); e != nil {
+			t.Fatal(e)
+		}
+		for cpURLs := range prepareCopyURLs(ctx, prepareCopyURLsOpts{
+			sourceURLs: []string{path},
+			targetURL:  "sum/bucket/prefix/" + name,
+		}) {
+			if cpURLs.Error != nil {
+				t.Fatal(cpURLs.Error)
+			}
+			cpURLs.StoreChecksum = true
+			urls := doCopy(ctx, doCopyOpts{cpURLs: cpURLs, pg: newAccounter(0), multipartSize: "5MiB", multipartThreads: "2"})
+			if urls.Error != nil {
+				t.Fatal(urls.Error)
+			}
+		}
+
+		sum := sha256.Sum256(data)
+		expected := hex.EncodeToString(sum[:])
+		o, ok := server.Object("bucket", "prefix/"+name)
+		if !ok || !bytes.Equal(o.Data, data) {
+			t.Fatalf("expected %s to be uploaded", name)
+		}
+		if o.UserMetadata[sha256MetaKey] != expected {
+			t.Fatalf("expected the SHA-256 sum %s in the metadata of %s, got %v", expected, name, o.UserMetadata)
+		}
+		if name == "large" && len(o.PartSizes) < 2 {
+			t.Fatalf("expected %s to be uploaded in parts, got %v", name, o.PartSizes)
+		}
+
+		clnt, err := newClient("sum/bucket/prefix/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := clnt.Stat(ctx, StatOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := parseStat(content); msg.SHA256 != expected {
+			t.Fatalf("expected stat to show the SHA-256 sum %s, got %q", expected, msg.SHA256)
+		}
+	}
+
+	// The multipart ETag of large is not computed again, its stored sum
+	// is compared. Changing the file makes it differ.
+	summary, err := verifyLocal(ctx, dir, "sum/bucket/prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (verifySummary{Matched: 2}); summary != expected {
+		t.Fatalf("expected %+v, got %+v", expected, summary)
+	}
+	for _, r := range server.Requests() {
+		if r.Method == "HEAD" && r.Query.Has("partNumber") {
+			t.Fatal("expected the part size of large not to be queried")
+		}
+	}
+	changed := append([]byte{}, files["large"]...)
+	changed[0] = 'x'
+	if e := os.WriteFile(filepath.Join(dir, "large"), changed, 0o600); e != nil {
+		t.Fatal(e)
+	}
+	summary, err = verifyLocal(ctx, dir, "sum/bucket/prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (verifySummary{Matched: 1, Mismatched: 1}); summary != expected {
+		t.Fatalf("expected %+v, got %+v", expected, summary)
+	}
+
+	// A manifest of sha256sum compares the stored sums, objects without
+	// one are skipped.
+	server.PutObject("bucket", "prefix/unsummed", []byte("hello"))
+	var manifest strings.Builder
+	for _, name := range []string{"small", "large", "unsummed"} {
+		data := files[name]
+		if name == "large" {
+			data = changed
+		} else if name == "unsummed" {
+			data = []byte("hello")
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(&manifest, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	manifestPath := filepath.Join(t.TempDir(), "manifest.sha256")
+	if e := os.WriteFile(manifestPath, []byte(manifest.String()), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	summary, err = verifyManifest(ctx, manifestPath, "sum/bucket/prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (verifySummary{Matched: 1, Mismatched: 1, Skipped: 1}); summary != expected {
+		t.Fatalf("expected %+v, got %+v", expected, summary)
+	}
+}
+
+func TestStoreChecksumSinglePass(t *testing.T) {
+	server := newS3TestServer(t)
+	data := bytes.Repeat([]byte("0123456789abcdef"), 6<<20/16)
+	for name, opts := range map[string]PutOptions{
+		"single": {storeSHA256: true, metadata: map[string]string{"X-Amz-Meta-Color": "blue"}},
+		"parts":  {storeSHA256: true, metadata: map[string]string{"X-Amz-Meta-Color": "blue"}, multipartSize: 5 << 20, multipartThreads: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reader := &countingReader{Reader: bytes.NewReader(data)}
+			clnt := newS3TestClient(t, "s3test/bucket/"+name)
+			if _, err := clnt.Put(context.Background(), reader, int64(len(data)), nil, opts); err != nil {
+				t.Fatal(err)
+			}
+			if reader.n != int64(len(data)) {
+				t.Fatalf("expected the source to be read once, %d bytes were read", reader.n)
+			}
+			sum := sha256.Sum256(data)
+			o, ok := server.Object("bucket", name)
+			if !ok || !bytes.Equal(o.Data, data) {
+				t.Fatalf("expected %s to be uploaded", name)
+			}
+			if o.UserMetadata[sha256MetaKey] != hex.EncodeToString(sum[:]) || o.UserMetadata["X-Amz-Meta-Color"] != "blue" {
+				t.Fatalf("expected the SHA-256 sum along with the metadata of %s, got %v", name, o.UserMetadata)
+			}
+		})
+	}
+}
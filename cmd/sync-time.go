@@ -0,0 +1,196 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/minio/pkg/v2/console"
+)
+
+const (
+	// iso8601DateFormat is the format of X-Amz-Date.
+	iso8601DateFormat = "20060102T150405Z"
+	// syncTimeMinOffset is the smallest clock offset worth signing the
+	// requests again for, the Date header only has a resolution of a
+	// second.
+	syncTimeMinOffset = time.Second
+)
+
+// syncTimeTransport signs the SigV4 requests again as of the clock of
+// the server for --sync-time, so that a skewed local clock does not fail
+// them with RequestTimeTooSkewed. The offset of the clock of the server
+// is measured once, from the Date header of an unsigned request sent
+// before the first request. Like redirectTransport, only the requests
+// signed with the keys of the alias, with a signed or unsigned payload,
+// can be signed again, others are sent as is.
+type syncTimeTransport struct {
+	transport http.RoundTripper
+	accessKey string
+	secretKey string
+
+	once   sync.Once
+	offset time.Duration
+}
+
+func newSyncTimeTransport(config *Config, transport http.RoundTripper) http.RoundTripper {
+	if !config.SyncTime {
+		return transport
+	}
+	return &syncTimeTransport{
+		transport: transport,
+		accessKey: config.AccessKey,
+		secretKey: config.SecretKey,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *syncTimeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(func() { t.offset = t.measureOffset(req) })
+	if t.offset > -syncTimeMinOffset && t.offset < syncTimeMinOffset || !t.canSignAgain(req) {
+		return t.transport.RoundTrip(req)
+	}
+	return t.transport.RoundTrip(signV4At(req, t.accessKey, t.secretKey, time.Now().Add(t.offset)))
+}
+
+// measureOffset returns how far the clock of the server of req is ahead
+// of the local clock, 0 if the server does not tell its time.
+func (t *syncTimeTransport) measureOffset(req *http.Request) time.Duration {
+	host := req.URL.Scheme + "://" + req.URL.Host
+	headReq, e := http.NewRequestWithContext(req.Context(), http.MethodHead, host+"/", nil)
+	if e != nil {
+		return 0
+	}
+	sent := time.Now()
+	resp, e := t.transport.RoundTrip(headReq)
+	if e != nil {
+		errorIf(probe.NewError(e).Trace(host), "Unable to read the time of `%s`, requests are signed with the local time.", host)
+		return 0
+	}
+	resp.Body.Close()
+	received := time.Now()
+	serverTime, e := http.ParseTime(resp.Header.Get("Date"))
+	if e != nil {
+		errorIf(probe.NewError(e).Trace(host), "Unable to read the time of `%s`, requests are signed with the local time.", host)
+		return 0
+	}
+	// The Date header is truncated to the second, compare its middle to
+	// the middle of the round trip.
+	offset := serverTime.Add(time.Second / 2).Sub(sent.Add(received.Sub(sent) / 2)).Round(time.Second)
+	if globalDebug {
+		console.Debugln(fmt.Sprintf("The clock of %s is %s ahead of the local clock.", host, offset))
+	}
+	return offset
+}
+
+// canSignAgain returns true if req is signed with SigV4 in its
+// Authorization header with the keys of the alias, and not a streaming
+// signature whose chunks are signed with the time of the request.
+func (t *syncTimeTransport) canSignAgain(req *http.Request) bool {
+	if t.accessKey == "" || t.secretKey == "" {
+		return false
+	}
+	if strings.HasPrefix(req.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return false
+	}
+	auth := req.Header.Get("Authorization")
+	return strings.HasPrefix(auth, signV4Algorithm+" Credential="+t.accessKey+"/")
+}
+
+// signV4At returns a copy of the SigV4 request req signed again as of t,
+// for the same headers, region and service.
+func signV4At(req *http.Request, accessKey, secretKey string, t time.Time) *http.Request {
+	t = t.UTC()
+	var credential, signedHeaders string
+	for _, field := range strings.Split(strings.TrimPrefix(req.Header.Get("Authorization"), signV4Algorithm), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch name {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		}
+	}
+	scope := strings.Split(credential, "/")
+	if len(scope) != 5 || scope[3] != signer.ServiceTypeS3 || signedHeaders == "" {
+		return req
+	}
+	region := scope[2]
+
+	r := req.Clone(req.Context())
+	r.Body = req.Body
+	r.Header.Set("X-Amz-Date", t.Format(iso8601DateFormat))
+	if r.Header.Get("Date") != "" {
+		r.Header.Set("Date", t.Format(http.TimeFormat))
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		canonicalHeaders.WriteString(name + ":")
+		if name == "host" {
+			canonicalHeaders.WriteString(requestHost(r))
+		} else {
+			values := r.Header.Values(name)
+			for i, value := range values {
+				values[i] = strings.Join(strings.Fields(value), " ")
+			}
+			canonicalHeaders.WriteString(strings.Join(values, ","))
+		}
+		canonicalHeaders.WriteString("\n")
+	}
+	hashedPayload := r.Header.Get("X-Amz-Content-Sha256")
+	if hashedPayload == "" {
+		hashedPayload = "UNSIGNED-PAYLOAD"
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		s3utils.EncodePath(r.URL.Path),
+		strings.ReplaceAll(r.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashedPayload,
+	}, "\n")
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	credentialScope := strings.Join([]string{t.Format("20060102"), region, signer.ServiceTypeS3, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{signV4Algorithm, t.Format(iso8601DateFormat), credentialScope, hex.EncodeToString(sum[:])}, "\n")
+	// The signature of a POST policy is that of any string to sign.
+	signature := signer.PostPresignSignatureV4(stringToSign, t, secretKey, region)
+
+	r.Header.Set("Authorization", signV4Algorithm+" Credential="+accessKey+"/"+credentialScope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return r
+}
+
+// requestHost returns the host signed for req, as minio-go does.
+func requestHost(req *http.Request) string {
+	if host := req.Header.Get("Host"); host != "" && req.Host != host {
+		return host
+	}
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
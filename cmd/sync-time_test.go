@@ -0,0 +1,85 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/minio-go/v7/pkg/signer"
+)
+
+func TestSignV4At(t *testing.T) {
+	req, e := http.NewRequest(http.MethodGet, "http://localhost:9000/bucket/a%20b/c+d.txt?prefix=x%20y&list-type=2&delimiter=%2F", nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	req.Header.Set("X-Amz-Meta-Note", "  two   spaces ")
+	req.Header.Add("X-Amz-Meta-List", "a")
+	req.Header.Add("X-Amz-Meta-List", "b")
+	req.Header.Set("User-Agent", "MinIO")
+	signed := signer.SignV4(*req, testAccessKey, testSecretKey, "", "us-east-1")
+
+	// Signed again as of the same time, the signature is that of minio-go.
+	at, e := time.Parse(iso8601DateFormat, signed.Header.Get("X-Amz-Date"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if auth := signV4At(signed, testAccessKey, testSecretKey, at).Header.Get("Authorization"); auth != signed.Header.Get("Authorization") {
+		t.Fatalf("expected %s, got %s", signed.Header.Get("Authorization"), auth)
+	}
+
+	later := signV4At(signed, testAccessKey, testSecretKey, at.Add(25*time.Hour))
+	if date := later.Header.Get("X-Amz-Date"); date != at.Add(25*time.Hour).Format(iso8601DateFormat) {
+		t.Fatalf("unexpected X-Amz-Date %s", date)
+	}
+	if auth := later.Header.Get("Authorization"); !strings.Contains(auth, "/"+at.Add(25*time.Hour).Format("20060102")+"/us-east-1/s3/aws4_request") {
+		t.Fatalf("expected the credential scope of the new date, got %s", auth)
+	}
+}
+
+func TestSyncTime(t *testing.T) {
+	initTestConfig(t)
+	defer func() { globalSyncTime = false }()
+	for _, syncTime := range []bool{false, true} {
+		// A server each, the clients are cached by host.
+		server := miniotest.NewServer()
+		defer server.Close()
+		server.ClockSkew = 2 * time.Hour
+		server.PutObject("bucket", "object", []byte("data"))
+		t.Setenv(mcEnvHostPrefix+"skew", server.AliasURL())
+		globalSyncTime = syncTime
+		clnt, err := newClient("skew/bucket/object")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = clnt.Stat(context.Background(), StatOptions{})
+		clnt.Close()
+		if syncTime && err != nil {
+			t.Fatalf("expected the request to be signed with the time of the server, got %v", err)
+		}
+		if !syncTime && err == nil {
+			t.Fatal("expected the request signed with the local time to fail")
+		}
+	}
+}
@@ -216,6 +216,7 @@ func mainListTag(cliCtx *cli.Context) error {
 
 	clnt, err := newClient(targetURL)
 	fatalIf(err, "Unable to initialize target "+targetURL)
+	defer clnt.Close()
 
 	alias, urlStr, _ := mustExpandAlias(targetURL)
 	if timeRef.IsZero() && !withVersions && !recursive {
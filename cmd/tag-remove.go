@@ -175,6 +175,7 @@ func mainRemoveTag(cliCtx *cli.Context) error {
 
 	clnt, pErr := newClient(targetURL)
 	fatalIf(pErr, "Unable to initialize target "+targetURL)
+	defer clnt.Close()
 
 	alias, urlStr, _ := mustExpandAlias(targetURL)
 	if timeRef.IsZero() && !withVersions && !recursive {
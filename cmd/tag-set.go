@@ -186,6 +186,7 @@ func mainSetTag(cliCtx *cli.Context) error {
 
 	clnt, err := newClient(targetURL)
 	fatalIf(err.Trace(cliCtx.Args()...), "Unable to initialize target "+targetURL)
+	defer clnt.Close()
 
 	alias, urlStr, _ := mustExpandAlias(targetURL)
 	if timeRef.IsZero() && !withVersions && !recursive && !excludeFolders {
@@ -0,0 +1,132 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/minio/mc/pkg/disk"
+)
+
+// With --temp-dir, streams of unknown size are buffered in a temporary
+// file of that directory and uploaded with their size, instead of in
+// parts buffered in memory. Streams checked against --max-object-size
+// are buffered in the temp directory, --temp-dir or else the default one
+// of the platform, which honors TMPDIR. The temporary files still open
+// are removed when mc is interrupted.
+
+// minTempDirFree is the space the temp directory must have left, the
+// default size of the parts of a stream.
+const minTempDirFree = minPutPartSize
+
+// tempFiles are the temporary files which are not removed yet.
+var tempFiles = struct {
+	sync.Mutex
+	paths map[string]struct{}
+}{paths: map[string]struct{}{}}
+
+// tempDir returns the directory of the temporary files.
+func tempDir() string {
+	if globalTempDir != "" {
+		return globalTempDir
+	}
+	return os.TempDir()
+}
+
+// checkTempDir returns an error if dir is not a writable directory with
+// at least minTempDirFree bytes available.
+func checkTempDir(dir string) error {
+	fi, e := os.Stat(dir)
+	if e != nil {
+		return fmt.Errorf("temp directory: %w", e)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("temp directory %s is not a directory", dir)
+	}
+	f, e := os.CreateTemp(dir, ".mc-check-*")
+	if e != nil {
+		return fmt.Errorf("temp directory %s is not writable: %w", dir, e)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	free, e := disk.Free(dir)
+	if e != nil && !errors.Is(e, disk.ErrFreeNotSupported) {
+		return fmt.Errorf("temp directory %s: %w", dir, e)
+	}
+	if e == nil && free < minTempDirFree {
+		return fmt.Errorf("temp directory %s has only %s available, at least %s are needed", dir,
+			formatSize(int64(free)), formatSize(minTempDirFree))
+	}
+	return nil
+}
+
+// createTempFile creates a temporary file in the temp directory, like
+// os.CreateTemp does, to be removed with removeTempFile.
+func createTempFile(pattern string) (*os.File, error) {
+	f, e := os.CreateTemp(tempDir(), pattern)
+	if e != nil {
+		return nil, e
+	}
+	tempFiles.Lock()
+	tempFiles.paths[f.Name()] = struct{}{}
+	tempFiles.Unlock()
+	return f, nil
+}
+
+// removeTempFile closes and removes a file of createTempFile.
+func removeTempFile(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+	tempFiles.Lock()
+	delete(tempFiles.paths, f.Name())
+	tempFiles.Unlock()
+}
+
+// removeTempFiles removes the temporary files which are left, when mc
+// exits before the transfers using them are over.
+func removeTempFiles() {
+	tempFiles.Lock()
+	defer tempFiles.Unlock()
+	for path := range tempFiles.paths {
+		os.Remove(path)
+		delete(tempFiles.paths, path)
+	}
+}
+
+// spoolStream copies the next size bytes of reader, fewer at its end, to
+// a temporary file.
+func spoolStream(reader io.Reader, size int64) (*os.File, int64, error) {
+	f, e := createTempFile("mc-stream-*")
+	if e != nil {
+		return nil, 0, fmt.Errorf("unable to buffer the stream in %s: %w", tempDir(), e)
+	}
+	fw := &fileWriter{file: f}
+	n, e := io.CopyN(fw, reader, size)
+	if e != nil && e != io.EOF {
+		removeTempFile(f)
+		if fw.err != nil {
+			return nil, n, fmt.Errorf("unable to buffer the stream in %s: %w", tempDir(), fw.err)
+		}
+		return nil, n, e
+	}
+	return f, n, nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+// dirWatchReader lists the files of dir whenever it is read.
+type dirWatchReader struct {
+	reader io.Reader
+	dir    string
+	seen   map[string]bool
+}
+
+func (r *dirWatchReader) Read(p []byte) (int, error) {
+	entries, _ := os.ReadDir(r.dir)
+	for _, entry := range entries {
+		r.seen[entry.Name()] = true
+	}
+	return r.reader.Read(p)
+}
+
+func TestPutStreamTempDir(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.MakeBucket("bucket")
+	t.Setenv(mcEnvHostPrefix+"tempdirtest", server.AliasURL())
+
+	dir := t.TempDir()
+	if e := checkTempDir(dir); e != nil {
+		t.Fatal(e)
+	}
+	if e := checkTempDir(filepath.Join(dir, "missing")); e == nil {
+		t.Fatal("expected a missing temp directory to be rejected")
+	}
+	defer func(tempDir string) { globalTempDir = tempDir }(globalTempDir)
+	globalTempDir = dir
+
+	clnt, err := newClient("tempdirtest/bucket/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("0123456789abcdef"), (12<<20)/16)
+	reader := &dirWatchReader{reader: bytes.NewReader(data), dir: dir, seen: map[string]bool{}}
+	n, err := clnt.Put(context.Background(), reader, -1, nil, PutOptions{multipartSize: 5 << 20, multipartThreads: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("expected %d bytes to be uploaded, got %d", len(data), n)
+	}
+	if object, ok := server.Object("bucket", "stream"); !ok || !bytes.Equal(object.Data, data) {
+		t.Fatal("unexpected uploaded object")
+	}
+	if parts := server.RequestCount(http.MethodPut); parts != 3 {
+		t.Fatalf("expected 3 parts, got %d", parts)
+	}
+
+	buffered := 0
+	for name := range reader.seen {
+		if strings.HasPrefix(name, "mc-stream-") {
+			buffered++
+		}
+	}
+	if buffered == 0 {
+		t.Fatalf("expected the stream to be buffered in %s, saw %v", dir, reader.seen)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected the buffers to be removed, found %v", entries)
+	}
+
+	// Without --temp-dir, streams are not buffered on disk.
+	t.Setenv("TMPDIR", dir)
+	globalTempDir = ""
+	reader = &dirWatchReader{reader: bytes.NewReader(data), dir: dir, seen: map[string]bool{}}
+	if _, err = clnt.Put(context.Background(), reader, -1, nil, PutOptions{multipartSize: 5 << 20}); err != nil {
+		t.Fatal(err)
+	}
+	if len(reader.seen) != 0 {
+		t.Fatalf("expected no buffer in %s, saw %v", dir, reader.seen)
+	}
+
+	// Files left by an interrupted upload are removed on exit.
+	f, e := createTempFile("mc-stream-*")
+	if e != nil {
+		t.Fatal(e)
+	}
+	f.Close()
+	removeTempFiles()
+	if _, e = os.Stat(f.Name()); !os.IsNotExist(e) {
+		t.Fatalf("expected %s to be removed, got %v", f.Name(), e)
+	}
+}
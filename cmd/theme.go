@@ -0,0 +1,185 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+	"github.com/minio/pkg/v2/env"
+)
+
+const (
+	themeNone    = "none"
+	themeDefault = "default"
+
+	// Environment variables for the thresholds above which ls highlights objects.
+	envThemeLargeSize = envPrefix + "THEME_LARGE_SIZE"
+	envThemeOldAge    = envPrefix + "THEME_OLD_AGE"
+
+	defaultThemeLargeSize = "1GiB"
+	defaultThemeOldAge    = "365d"
+)
+
+// builtinThemes are the colors of the console tags of ls and diff output
+// for every --theme, "none" prints them plain.
+var builtinThemes = map[string]map[string]*color.Color{
+	themeDefault: {
+		"Dir":               color.New(color.FgCyan, color.Bold),
+		"File":              color.New(color.Bold),
+		"Time":              color.New(color.FgGreen),
+		"Size":              color.New(color.FgYellow),
+		"LargeObject":       color.New(color.FgRed, color.Bold),
+		"OldObject":         color.New(color.FgHiBlack),
+		"DiffMessage":       color.New(color.FgGreen, color.Bold),
+		"DiffOnlyInFirst":   color.New(color.FgRed),
+		"DiffOnlyInSecond":  color.New(color.FgGreen),
+		"DiffType":          color.New(color.FgMagenta),
+		"DiffSize":          color.New(color.FgYellow, color.Bold),
+		"DiffMetadata":      color.New(color.FgYellow, color.Bold),
+		"DiffMMSourceMTime": color.New(color.FgYellow, color.Bold),
+	},
+	"high-contrast": {
+		"Dir":               color.New(color.FgHiCyan, color.Bold),
+		"File":              color.New(color.FgHiWhite, color.Bold),
+		"Time":              color.New(color.FgHiGreen),
+		"Size":              color.New(color.FgHiYellow),
+		"LargeObject":       color.New(color.FgHiRed, color.Bold, color.Underline),
+		"OldObject":         color.New(color.FgHiMagenta),
+		"DiffMessage":       color.New(color.FgHiGreen, color.Bold),
+		"DiffOnlyInFirst":   color.New(color.FgHiRed, color.Bold),
+		"DiffOnlyInSecond":  color.New(color.FgHiGreen, color.Bold),
+		"DiffType":          color.New(color.FgHiMagenta, color.Bold),
+		"DiffSize":          color.New(color.FgHiYellow, color.Bold),
+		"DiffMetadata":      color.New(color.FgHiYellow, color.Bold),
+		"DiffMMSourceMTime": color.New(color.FgHiYellow, color.Bold),
+	},
+	themeNone: {},
+}
+
+// themeNames returns the names accepted by --theme.
+func themeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// outputTheme colors the output of ls and diff.
+type outputTheme struct {
+	name   string
+	colors map[string]*color.Color
+
+	// ls highlights objects of at least largeSize bytes and objects
+	// last modified more than oldAge ago.
+	largeSize int64
+	oldAge    time.Duration
+}
+
+// loadTheme returns the theme called name with the thresholds of the
+// environment, it is "none" when the output is not for a human to read.
+// An unknown theme or an invalid threshold is reported and replaced by
+// the default one, a typo in the environment does not fail every command.
+func loadTheme(name string) outputTheme {
+	if name == "" {
+		name = themeDefault
+	}
+	colors, ok := builtinThemes[name]
+	if !ok {
+		errorIf(probe.NewError(fmt.Errorf("expected one of %s", strings.Join(themeNames(), ", "))),
+			"Unknown theme `%s`, using the %s theme:", name, themeDefault)
+		name, colors = themeDefault, builtinThemes[themeDefault]
+	}
+	if globalNoColor || globalJSON || !isTerminal() {
+		name, colors = themeNone, builtinThemes[themeNone]
+	}
+
+	value := env.Get(envThemeLargeSize, defaultThemeLargeSize)
+	largeSize, e := humanize.ParseBytes(value)
+	if e != nil {
+		errorIf(probe.NewError(fmt.Errorf("invalid size `%s`", value)), "Ignoring %s, using %s:", envThemeLargeSize, defaultThemeLargeSize)
+		largeSize, _ = humanize.ParseBytes(defaultThemeLargeSize)
+	}
+	value = env.Get(envThemeOldAge, defaultThemeOldAge)
+	oldAge, e := ParseDuration(value)
+	if e != nil {
+		errorIf(probe.NewError(fmt.Errorf("invalid age `%s`", value)), "Ignoring %s, using %s:", envThemeOldAge, defaultThemeOldAge)
+		oldAge, _ = ParseDuration(defaultThemeOldAge)
+	}
+	return outputTheme{name: name, colors: colors, largeSize: int64(largeSize), oldAge: time.Duration(oldAge)}
+}
+
+// apply sets the colors of the theme, commands call it after setting
+// their own colors so that the theme takes precedence.
+func (t outputTheme) apply() {
+	for tag, c := range t.colors {
+		console.SetColor(tag, c)
+	}
+}
+
+// enabled returns true if the output is colored.
+func (t outputTheme) enabled() bool {
+	return t.name != "" && t.name != themeNone
+}
+
+// sizeTag returns the tag of the size of an object.
+func (t outputTheme) sizeTag(size int64) string {
+	if t.enabled() && t.largeSize > 0 && size >= t.largeSize {
+		return "LargeObject"
+	}
+	return "Size"
+}
+
+// timeTag returns the tag of the modification time of an object.
+func (t outputTheme) timeTag(modTime time.Time) string {
+	if t.enabled() && t.oldAge > 0 && !modTime.IsZero() && time.Since(modTime) > t.oldAge {
+		return "OldObject"
+	}
+	return "Time"
+}
+
+// diffMarker returns the mark of a difference, "+", "-" and "Δ" when the
+// output is colored and the plain "<", ">" and "!" otherwise.
+func (t outputTheme) diffMarker(d differType) string {
+	switch d {
+	case differInNone:
+		return "="
+	case differInFirst:
+		if t.enabled() {
+			return "-"
+		}
+		return "<"
+	case differInSecond:
+		if t.enabled() {
+			return "+"
+		}
+		return ">"
+	}
+	if t.enabled() {
+		return "Δ"
+	}
+	return "!"
+}
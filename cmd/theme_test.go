@@ -0,0 +1,95 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTheme(t *testing.T) {
+	defer func(f func() bool, noColor, json bool) {
+		isTerminal, globalNoColor, globalJSON = f, noColor, json
+	}(isTerminal, globalNoColor, globalJSON)
+	isTerminal = func() bool { return true }
+	globalNoColor, globalJSON = false, false
+
+	for _, name := range []string{"", "default", "high-contrast"} {
+		th := loadTheme(name)
+		if !th.enabled() || th.largeSize != 1<<30 || th.oldAge != 365*24*time.Hour {
+			t.Errorf("%q: unexpected theme %+v", name, th)
+		}
+	}
+	if th := loadTheme("rainbow"); th.name != themeDefault {
+		t.Errorf("expected an unknown theme to fall back to the default, got %+v", th)
+	}
+
+	t.Setenv(envThemeLargeSize, "10MiB")
+	t.Setenv(envThemeOldAge, "7d")
+	th := loadTheme("")
+	if th.largeSize != 10<<20 || th.oldAge != 7*24*time.Hour {
+		t.Errorf("expected the thresholds of the environment, got %+v", th)
+	}
+	t.Setenv(envThemeLargeSize, "big")
+	t.Setenv(envThemeOldAge, "old")
+	if th = loadTheme("high-contrast"); th.name != "high-contrast" || th.largeSize != 1<<30 || th.oldAge != 365*24*time.Hour {
+		t.Errorf("expected invalid thresholds to fall back to the defaults, got %+v", th)
+	}
+	t.Setenv(envThemeLargeSize, "10MiB")
+	t.Setenv(envThemeOldAge, "7d")
+
+	for _, disable := range []func(){
+		func() { isTerminal = func() bool { return false } },
+		func() { globalNoColor = true },
+		func() { globalJSON = true },
+	} {
+		isTerminal = func() bool { return true }
+		globalNoColor, globalJSON = false, false
+		disable()
+		if th = loadTheme("default"); th.enabled() {
+			t.Errorf("expected the theme to be disabled, got %+v", th)
+		}
+	}
+}
+
+func TestThemeTags(t *testing.T) {
+	th := outputTheme{name: themeDefault, largeSize: 100, oldAge: time.Hour}
+	if th.sizeTag(99) != "Size" || th.sizeTag(100) != "LargeObject" {
+		t.Error("expected objects of at least 100 bytes to be highlighted")
+	}
+	if th.timeTag(time.Now()) != "Time" || th.timeTag(time.Now().Add(-2*time.Hour)) != "OldObject" {
+		t.Error("expected objects older than an hour to be highlighted")
+	}
+	markers := map[differType][2]string{
+		differInFirst:  {"-", "<"},
+		differInSecond: {"+", ">"},
+		differInSize:   {"Δ", "!"},
+		differInNone:   {"=", "="},
+	}
+	for d, want := range markers {
+		if got := th.diffMarker(d); got != want[0] {
+			t.Errorf("%v: expected %q, got %q", d, want[0], got)
+		}
+		if got := (outputTheme{name: themeNone, largeSize: 100}).diffMarker(d); got != want[1] {
+			t.Errorf("%v: expected %q without colors, got %q", d, want[1], got)
+		}
+	}
+	if (outputTheme{name: themeNone, largeSize: 100}).sizeTag(100) != "Size" {
+		t.Error("expected no highlighting without colors")
+	}
+}
@@ -142,7 +142,7 @@ func promptTrustSelfSignedCert(ctx context.Context, endpoint, alias string) (*x5
 	}
 
 	fingerprint := sha256.Sum256(peerCert.RawSubjectPublicKeyInfo)
-	fmt.Printf("Fingerprint of %s public key: %s\nConfirm public key y/N: ", color.GreenString(alias), color.YellowString(hex.EncodeToString(fingerprint[:])))
+	fmt.Fprintf(statusOutput, "Fingerprint of %s public key: %s\nConfirm public key y/N: ", color.GreenString(alias), color.YellowString(hex.EncodeToString(fingerprint[:])))
 	answer, e := bufio.NewReader(os.Stdin).ReadString('\n')
 	if e != nil {
 		return nil, probe.NewError(e)
@@ -0,0 +1,97 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// transferLogEntry is the line written to --log-file for every object
+// transferred or failed.
+type transferLogEntry struct {
+	Time       string `json:"time"`
+	Source     string `json:"source"`
+	Key        string `json:"key"`
+	Bytes      int64  `json:"bytes"`
+	DurationMs int64  `json:"durationMs"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// transferLog appends a JSON line per object to a file, independently
+// of --quiet and --json, so that unattended transfers leave a trail.
+type transferLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// openTransferLog opens the file at path for appending, creating it if needed.
+func openTransferLog(path string) (*transferLog, error) {
+	f, e := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if e != nil {
+		return nil, e
+	}
+	return &transferLog{file: f}, nil
+}
+
+// log writes an entry for the transfer of source to target started at
+// start, a nil transferLog logs nothing. Every entry is written with a
+// single unbuffered write so that it survives the process being killed.
+func (l *transferLog) log(source, target string, size int64, start time.Time, err *probe.Error) {
+	if l == nil {
+		return
+	}
+	entry := transferLogEntry{
+		Time:       time.Now().UTC().Format(time.RFC3339Nano),
+		Source:     source,
+		Key:        target,
+		Bytes:      size,
+		DurationMs: time.Since(start).Milliseconds(),
+		Status:     "success",
+	}
+	if err != nil {
+		entry.Status = "error"
+		entry.Error = err.ToGoError().Error()
+	}
+	line, e := json.Marshal(entry)
+	if e != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, e = l.file.Write(line)
+	errorIf(probe.NewError(e), "Unable to write to the log file `"+l.file.Name()+"`.")
+}
+
+// logURLs logs the transfer of urls started at start.
+func (l *transferLog) logURLs(urls URLs, start time.Time) {
+	if l == nil || urls.SourceContent == nil || urls.TargetContent == nil {
+		return
+	}
+	source := filepath.ToSlash(filepath.Join(urls.SourceAlias, urls.SourceContent.URL.Path))
+	target := filepath.ToSlash(filepath.Join(urls.TargetAlias, urls.TargetContent.URL.Path))
+	l.log(source, target, urls.SourceContent.Size, start, urls.Error)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransferLog(t *testing.T) {
+	initTestConfig(t)
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "transfer.log")
+	// Entries are appended to an existing log.
+	if e := os.WriteFile(logFile, []byte("{}\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	l, e := openTransferLog(logFile)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer l.file.Close()
+	defer func(l *transferLog) { globalTransferLog = l }(globalTransferLog)
+	globalTransferLog = l
+
+	sources := map[string]string{"a.txt": "hello", "b.txt": "hello world", "missing.txt": ""}
+	for name, data := range sources {
+		if data == "" {
+			continue
+		}
+		if e := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	if e := os.Mkdir(filepath.Join(dir, "target"), 0o700); e != nil {
+		t.Fatal(e)
+	}
+	for name, data := range sources {
+		urls := URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(filepath.Join(dir, name)), Size: int64(len(data))},
+			TargetContent: &ClientContent{URL: *newClientURL(filepath.Join(dir, "target", name))},
+		}
+		uploadSourceToTargetURL(context.Background(), uploadSourceToTargetURLOpts{urls: urls, progress: newAccounter(0)})
+	}
+
+	f, e := os.Open(logFile)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != "{}" {
+		t.Fatalf("expected the existing line to be kept, got %q", scanner.Text())
+	}
+	entries := map[string]transferLogEntry{}
+	for scanner.Scan() {
+		var entry transferLogEntry
+		if e := json.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			t.Fatal(e)
+		}
+		entries[filepath.Base(entry.Key)] = entry
+	}
+	if len(entries) != len(sources) {
+		t.Fatalf("expected %d entries, got %v", len(sources), entries)
+	}
+	for name, data := range sources {
+		entry := entries[name]
+		if entry.Time == "" || entry.Source != filepath.ToSlash(filepath.Join(dir, name)) || entry.Bytes != int64(len(data)) || entry.DurationMs < 0 {
+			t.Fatalf("unexpected entry for %s: %+v", name, entry)
+		}
+		switch {
+		case data == "" && (entry.Status != "error" || entry.Error == ""):
+			t.Fatalf("expected an error entry for %s: %+v", name, entry)
+		case data != "" && (entry.Status != "success" || entry.Error != ""):
+			t.Fatalf("expected a success entry for %s: %+v", name, entry)
+		}
+	}
+}
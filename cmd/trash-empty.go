@@ -0,0 +1,126 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var trashEmptyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "older-than",
+		Usage: "remove the objects removed longer ago than value in duration string (e.g. 7d10h31s), 0 for all of them",
+		Value: "7d",
+	},
+	cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "only print what would be removed from the trash",
+	},
+}
+
+var trashEmptyCmd = cli.Command{
+	Name:         "empty",
+	Usage:        "remove objects from the trash for good",
+	Action:       mainTrashEmpty,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(trashEmptyFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] [TARGET]
+
+DESCRIPTION:
+  Remove the objects in the trash of TARGET, the gpumall base path by
+  default, which were removed with 'rm --trash' longer ago than the
+  retention of --older-than. MC_TRASH_EMPTY_OLDER_THAN sets the default
+  retention.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Remove the objects trashed more than 7 days ago from the trash of the gpumall base path.
+     {{.Prompt}} {{.HelpName}}
+
+  2. Empty the trash of the bucket 'jazz-songs'.
+     {{.Prompt}} {{.HelpName}} --older-than 0 s3/jazz-songs
+`,
+}
+
+// trashEmptyMessage is printed by 'trash empty'.
+type trashEmptyMessage struct {
+	Status  string `json:"status"`
+	Removed int    `json:"removed"`
+	Size    int64  `json:"size"`
+	DryRun  bool   `json:"dryRun"`
+}
+
+func (t trashEmptyMessage) String() string {
+	if t.DryRun {
+		return fmt.Sprintf("DRYRUN: %d object(s), %s, would be removed from the trash.", t.Removed, formatSize(t.Size))
+	}
+	return fmt.Sprintf("Removed %d object(s), %s, from the trash.", t.Removed, formatSize(t.Size))
+}
+
+func (t trashEmptyMessage) JSON() string {
+	t.Status = "success"
+	msgBytes, e := json.MarshalIndent(t, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainTrashEmpty is the handle for "mc trash empty" command.
+func mainTrashEmpty(cliCtx *cli.Context) error {
+	ctx, cancelEmpty := context.WithCancel(globalContext)
+	defer cancelEmpty()
+
+	if cliCtx.NArg() > 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	olderThan, e := ParseDuration(cliCtx.String("older-than"))
+	fatalIf(probe.NewError(e), "Unable to parse --older-than=`"+cliCtx.String("older-than")+"`.")
+	isFake := cliCtx.Bool("dry-run")
+
+	target, root := trashTarget(cliCtx)
+	entries, err := listTrash(ctx, root)
+	fatalIf(err, "Unable to list the trash of `"+root+"`.")
+
+	before := UTCNow().Add(-time.Duration(olderThan))
+	msg := trashEmptyMessage{DryRun: isFake}
+	var expired []trashEntry
+	for _, entry := range entries {
+		if isUnder(entry.Path, target) && !entry.Trashed.After(before) {
+			expired = append(expired, entry)
+			msg.Removed++
+			msg.Size += entry.Size
+		}
+	}
+	if !isFake {
+		fatalIf(removeTrashed(ctx, root, expired), "Unable to empty the trash of `"+root+"`.")
+	}
+	printMsg(msg)
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+var trashListCmd = cli.Command{
+	Name:         "list",
+	Aliases:      []string{"ls"},
+	Usage:        "list the objects in the trash",
+	Action:       mainTrashList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] [TARGET]
+
+DESCRIPTION:
+  List the objects removed with 'rm --trash' at or under TARGET, the
+  gpumall base path by default, most recently removed first for each
+  path.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List the objects removed from the gpumall base path.
+     {{.Prompt}} {{.HelpName}}
+
+  2. List the objects removed from the bucket 'jazz-songs' under 'louis/'.
+     {{.Prompt}} {{.HelpName}} s3/jazz-songs/louis/
+`,
+}
+
+// trashListMessage is an object printed by 'trash list'.
+type trashListMessage struct {
+	Status   string    `json:"status"`
+	Path     string    `json:"path"`
+	Trashed  time.Time `json:"trashed"`
+	Size     int64     `json:"size"`
+	TrashURL string    `json:"trashURL"`
+}
+
+func (t trashListMessage) String() string {
+	return fmt.Sprintf("%s %s %s",
+		console.Colorize("Time", "["+formatListTime(t.Trashed.Local())+"]"),
+		console.Colorize("Size", fmt.Sprintf("%7s", formatSize(t.Size))),
+		console.Colorize("Path", t.Path))
+}
+
+func (t trashListMessage) JSON() string {
+	t.Status = "success"
+	msgBytes, e := json.MarshalIndent(t, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainTrashList is the handle for "mc trash list" command.
+func mainTrashList(cliCtx *cli.Context) error {
+	ctx, cancelList := context.WithCancel(globalContext)
+	defer cancelList()
+
+	if cliCtx.NArg() > 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	console.SetColor("Time", color.New(color.FgGreen))
+	console.SetColor("Size", color.New(color.FgYellow))
+	console.SetColor("Path", color.New(color.Bold))
+
+	target, root := trashTarget(cliCtx)
+	entries, err := listTrash(ctx, root)
+	fatalIf(err, "Unable to list the trash of `"+root+"`.")
+	for _, entry := range entries {
+		if !isUnder(entry.Path, target) {
+			continue
+		}
+		printMsg(trashListMessage{
+			Path:     trashDisplayPath(root, entry.Path),
+			Trashed:  entry.Trashed,
+			Size:     entry.Size,
+			TrashURL: entry.URL,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "github.com/minio/cli"
+
+var trashSubcommands = []cli.Command{
+	trashListCmd,
+	trashRestoreCmd,
+	trashEmptyCmd,
+}
+
+var trashCmd = cli.Command{
+	Name:        "trash",
+	Usage:       "list, restore and empty the objects removed with 'rm --trash'",
+	Action:      mainTrash,
+	Before:      setGlobalsFromContext,
+	Flags:       globalFlags,
+	Subcommands: trashSubcommands,
+}
+
+// mainTrash is the handle for "mc trash" command.
+func mainTrash(ctx *cli.Context) error {
+	commandNotFound(ctx, trashSubcommands)
+	return nil
+}
+
+// trashTarget returns the aliased URL of the optional TARGET argument of
+// the trash commands, the gpumall base path by default, and the root of
+// its trash.
+func trashTarget(cliCtx *cli.Context) (target, root string) {
+	arg := "/"
+	if cliCtx.NArg() == 1 {
+		arg = cliCtx.Args().Get(0)
+	}
+	target = getFullPath(arg)
+	root, err := trashRoot(target)
+	fatalIf(err.Trace(arg), "Unable to find the trash of `"+arg+"`.")
+	return target, root
+}
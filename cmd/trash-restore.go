@@ -0,0 +1,137 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+var trashRestoreFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "force",
+		Usage: "overwrite the objects which exist again since they were removed",
+	},
+}
+
+var trashRestoreCmd = cli.Command{
+	Name:         "restore",
+	Usage:        "copy objects back from the trash",
+	Action:       mainTrashRestore,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(trashRestoreFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] PATH
+
+DESCRIPTION:
+  Copy the objects removed with 'rm --trash' at or under PATH back where
+  they were removed from, the most recently removed copy of each, and
+  remove them from the trash. Objects which exist again are skipped
+  unless --force is given.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Restore a removed file of the gpumall base path.
+     {{.Prompt}} {{.HelpName}} backups/2024-01-01.tgz
+
+  2. Restore all the objects removed from the bucket 'jazz-songs' under 'louis/'.
+     {{.Prompt}} {{.HelpName}} s3/jazz-songs/louis/
+`,
+}
+
+// trashRestoreMessage is an object restored by 'trash restore'.
+type trashRestoreMessage struct {
+	Status  string    `json:"status"`
+	Path    string    `json:"path"`
+	Trashed time.Time `json:"trashed"`
+}
+
+func (t trashRestoreMessage) String() string {
+	return fmt.Sprintf("Restored %s removed at %s.",
+		console.Colorize("Restored", "`"+t.Path+"`"), formatListTime(t.Trashed.Local()))
+}
+
+func (t trashRestoreMessage) JSON() string {
+	t.Status = "success"
+	msgBytes, e := json.MarshalIndent(t, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainTrashRestore is the handle for "mc trash restore" command.
+func mainTrashRestore(cliCtx *cli.Context) error {
+	ctx, cancelRestore := context.WithCancel(globalContext)
+	defer cancelRestore()
+
+	if cliCtx.NArg() != 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	isForce := cliCtx.Bool("force")
+	console.SetColor("Restored", color.New(color.FgGreen, color.Bold))
+
+	target, root := trashTarget(cliCtx)
+	entries, err := listTrash(ctx, root)
+	fatalIf(err, "Unable to list the trash of `"+root+"`.")
+
+	var restored []trashEntry
+	var rerr error
+	for i, entry := range entries {
+		// The entries of a path are sorted most recently trashed first.
+		if !isUnder(entry.Path, target) || (i > 0 && entries[i-1].Path == entry.Path) {
+			continue
+		}
+		path := trashDisplayPath(root, entry.Path)
+		if !isForce {
+			if clnt, err := newClient(entry.Path); err == nil {
+				if _, err = clnt.Stat(ctx, StatOptions{}); err == nil {
+					errorIf(errDummy().Trace(entry.Path), "Unable to restore `"+path+"`, it exists again, use --force to overwrite it.")
+					rerr = exitStatus(globalErrorExitStatus)
+					continue
+				}
+			}
+		}
+		if err := copyServerSide(ctx, entry.URL, entry.Path, entry.Size); err != nil {
+			errorIf(err, "Unable to restore `"+path+"`.")
+			rerr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		restored = append(restored, entry)
+		printMsg(trashRestoreMessage{Path: path, Trashed: entry.Trashed})
+	}
+	if len(restored) == 0 && rerr == nil {
+		fatalIf(errDummy().Trace(target), "Nothing to restore at `"+cliCtx.Args().Get(0)+"` in the trash.")
+	}
+	if err := removeTrashed(ctx, root, restored); err != nil {
+		errorIf(err, "Unable to remove the restored objects from the trash.")
+		rerr = exitStatus(globalErrorExitStatus)
+	}
+	return rerr
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// `rm --trash` copies every object it removes to the trash first, the
+// ".trash/<time>/" prefix of the gpumall base path for gpumall objects
+// and of their bucket otherwise, where <time> is when rm started. `mc
+// trash` lists, restores and empties the trash.
+
+const (
+	trashDir = ".trash"
+
+	// trashTimeFormat formats the time of the trashed objects in their
+	// trash URL, it sorts like the time.
+	trashTimeFormat = "20060102T150405Z"
+)
+
+var errTrashLocal = errors.New("only objects can be moved to the trash")
+
+// trashRoot returns the aliased URL whose trash an object is moved to, the
+// gpumall prefix for gpumall objects under it and their bucket otherwise.
+func trashRoot(aliasedURL string) (string, *probe.Error) {
+	alias, path := url2Alias(aliasedURL)
+	if _, _, aliasCfg := mustExpandAlias(aliasedURL); aliasCfg == nil {
+		return "", probe.NewError(errTrashLocal)
+	}
+	if alias == AuthAlias {
+		if prefix := strings.TrimSuffix(getPrefix(), "/"); strings.HasPrefix(aliasedURL, prefix+"/") {
+			return prefix, nil
+		}
+	}
+	bucket, _, _ := strings.Cut(path, "/")
+	if bucket == "" {
+		return "", probe.NewError(BucketNameEmpty{})
+	}
+	return alias + "/" + bucket, nil
+}
+
+// trashPrefix returns the aliased URL of the trash of root.
+func trashPrefix(root string) string {
+	return root + "/" + trashDir + "/"
+}
+
+// isTrashed returns whether aliasedURL is in the trash of root.
+func isTrashed(root, aliasedURL string) bool {
+	return strings.HasPrefix(aliasedURL, trashPrefix(root))
+}
+
+// trashObject copies the object at aliasedURL of size bytes into the trash
+// of root under the time stamp, server-side. Objects too large for a
+// single copy are copied in parts.
+func trashObject(ctx context.Context, root, aliasedURL string, size int64, stamp string) *probe.Error {
+	if isTrashed(root, aliasedURL) {
+		return probe.NewError(errors.New("object is already in the trash, use `mc trash empty` to remove it")).Trace(aliasedURL)
+	}
+	targetURL := trashPrefix(root) + stamp + "/" + strings.TrimPrefix(aliasedURL, root+"/")
+	return copyServerSide(ctx, aliasedURL, targetURL, size)
+}
+
+// copyServerSide copies the object at sourceURL of size bytes to
+// targetURL, both of the same alias.
+func copyServerSide(ctx context.Context, sourceURL, targetURL string, size int64) *probe.Error {
+	source, err := newClient(sourceURL)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	target, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	return target.Copy(ctx, source.GetURL().Path, CopyOptions{size: size}, nil).Trace(sourceURL, targetURL)
+}
+
+// trashEntry is an object in the trash.
+type trashEntry struct {
+	// URL is the aliased URL of the object in the trash.
+	URL string
+	// Path is the aliased URL the object was removed from.
+	Path    string
+	Trashed time.Time
+	Size    int64
+}
+
+// listTrash returns the objects in the trash of root, sorted by path and
+// most recently trashed first.
+func listTrash(ctx context.Context, root string) ([]trashEntry, *probe.Error) {
+	prefix := trashPrefix(root)
+	alias, _ := url2Alias(prefix)
+	clnt, err := newClient(prefix)
+	if err != nil {
+		return nil, err.Trace(prefix)
+	}
+	var entries []trashEntry
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(prefix)
+		}
+		trashURL := alias + getKey(content)
+		stamp, rest, _ := strings.Cut(strings.TrimPrefix(trashURL, prefix), "/")
+		trashed, e := time.Parse(trashTimeFormat, stamp)
+		if e != nil || rest == "" {
+			// Not moved there by rm.
+			continue
+		}
+		entries = append(entries, trashEntry{
+			URL:     trashURL,
+			Path:    root + "/" + rest,
+			Trashed: trashed,
+			Size:    content.Size,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Trashed.After(entries[j].Trashed)
+	})
+	return entries, nil
+}
+
+// isUnder returns whether aliasedURL is target or below it.
+func isUnder(aliasedURL, target string) bool {
+	target = strings.TrimSuffix(target, "/")
+	return aliasedURL == target || strings.HasPrefix(aliasedURL, target+"/")
+}
+
+// trashDisplayPath returns how path of the trash of root is printed,
+// relative to the gpumall prefix for gpumall objects like the commands
+// take it.
+func trashDisplayPath(root, path string) string {
+	if alias, _ := url2Alias(root); alias == AuthAlias {
+		return "/" + strings.TrimPrefix(path, root+"/")
+	}
+	return path
+}
+
+// removeTrashed removes entries from the trash of root, it returns the
+// first error but tries to remove all of them.
+func removeTrashed(ctx context.Context, root string, entries []trashEntry) *probe.Error {
+	alias, prefixURL, _ := mustExpandAlias(trashPrefix(root))
+	clnt, err := newClientFromAlias(alias, prefixURL)
+	if err != nil {
+		return err.Trace(root)
+	}
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		for _, entry := range entries {
+			_, entryURL, _ := mustExpandAlias(entry.URL)
+			contentCh <- &ClientContent{URL: *newClientURL(entryURL)}
+		}
+	}()
+	var rerr *probe.Error
+	for result := range clnt.Remove(ctx, false, false, false, false, contentCh) {
+		if result.Err != nil && rerr == nil {
+			rerr = result.Err.Trace(root)
+		}
+	}
+	return rerr
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestTrash(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.PutObject("bucket", "dir/a", []byte("a"))
+	server.PutObject("bucket", "dir/b", []byte("bb"))
+	server.PutObject("bucket", "keep", []byte("keep"))
+	server.PutObject("bucket", ".trash/not-a-time/x", []byte("x"))
+	t.Setenv(mcEnvHostPrefix+"trashtest", server.AliasURL())
+
+	root, err := trashRoot("trashtest/bucket/dir/a")
+	if err != nil || root != "trashtest/bucket" {
+		t.Fatalf("unexpected trash root %q: %v", root, err)
+	}
+	if _, err = trashRoot(t.TempDir()); err == nil {
+		t.Fatal("expected local paths to have no trash")
+	}
+
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Format(trashTimeFormat)
+	if e := listAndRemove("trashtest/bucket/dir/", removeOpts{isRecursive: true, isForce: true, trashStamp: stamp}); e != nil {
+		t.Fatal(e)
+	}
+	expected := []string{".trash/20240102T030405Z/dir/a", ".trash/20240102T030405Z/dir/b", ".trash/not-a-time/x", "keep"}
+	if keys := server.Keys("bucket"); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected keys %v, got %v", expected, keys)
+	}
+
+	// Large objects are copied in parts.
+	server.ResetRequests()
+	later := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC).Format(trashTimeFormat)
+	if err = trashObject(context.Background(), root, "trashtest/bucket/keep", 64<<20, later); err != nil {
+		t.Fatal(err)
+	}
+	partCopies := 0
+	for _, r := range server.Requests() {
+		if r.Method == http.MethodPut && r.Query.Has("uploadId") {
+			partCopies++
+		}
+	}
+	if partCopies == 0 {
+		t.Fatal("expected a multipart copy")
+	}
+	if err = trashObject(context.Background(), root, "trashtest/bucket/.trash/"+later+"/keep", 4, stamp); err == nil {
+		t.Fatal("expected trashed objects not to be trashed again")
+	}
+	if e := removeSingle("trashtest/bucket/dir/a", "", removeOpts{trashStamp: later}); e == nil {
+		t.Fatal("expected removing a missing object to fail")
+	}
+
+	entries, err := listTrash(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.Path+"@"+entry.Trashed.Format(trashTimeFormat))
+	}
+	expected = []string{
+		"trashtest/bucket/dir/a@" + stamp,
+		"trashtest/bucket/dir/b@" + stamp,
+		"trashtest/bucket/keep@" + later,
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected trash %v, got %v", expected, paths)
+	}
+	if !isUnder(entries[0].Path, "trashtest/bucket/dir/") || isUnder(entries[2].Path, "trashtest/bucket/ke") {
+		t.Fatal("unexpected isUnder")
+	}
+
+	if err = removeTrashed(context.Background(), root, entries[:2]); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{".trash/20240109T000000Z/keep", ".trash/not-a-time/x", "keep"}
+	if keys := server.Keys("bucket"); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected keys %v, got %v", expected, keys)
+	}
+}
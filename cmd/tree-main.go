@@ -293,6 +293,7 @@ func mainTree(cliCtx *cli.Context) error {
 			}
 			clnt, err := newClientFromAlias(targetAlias, targetURL)
 			fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+			defer clnt.Close()
 			opts := doListOptions{
 				timeRef:           timeRef,
 				isRecursive:       true,
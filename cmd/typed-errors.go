@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/minio/mc/pkg/probe"
 )
@@ -32,11 +33,15 @@ var errDummy = func() *probe.Error {
 	return probe.NewError(dummyErr(errors.New(msg))).Untrace()
 }
 
-type invalidArgumentErr error
+// invalidArgumentErr marks the errors of invalid arguments, whatever
+// their message.
+type invalidArgumentErr struct{ error }
+
+func (e invalidArgumentErr) Unwrap() error { return e.error }
 
 var errInvalidArgument = func() *probe.Error {
 	msg := "Invalid arguments provided, please refer " + "`mc <command> -h` for relevant documentation."
-	return probe.NewError(invalidArgumentErr(errors.New(msg))).Untrace()
+	return probe.NewError(invalidArgumentErr{errors.New(msg)}).Untrace()
 }
 
 type unableToGuessErr error
@@ -148,9 +153,78 @@ var errSourceIsDir = func(URL string) *probe.Error {
 	return probe.NewError(sourceIsDirErr(errors.New(msg))).Untrace()
 }
 
+type sourceIsSocketErr error
+
+var errSourceIsSocket = func(URL string) *probe.Error {
+	msg := "Source `" + URL + "` is a socket, only files, named pipes and devices can be uploaded."
+	return probe.NewError(sourceIsSocketErr(errors.New(msg))).Untrace()
+}
+
+type sessionTokenExpiredErr error
+
+var errSessionTokenExpired = func(expiry time.Time) *probe.Error {
+	msg := "The session token expired at " + expiry.Format(printDate) + ", run `mc auth` again."
+	return probe.NewError(sessionTokenExpiredErr(errors.New(msg)))
+}
+
 type conflictSSEErr error
 
 var errConflictSSE = func(sseServer, sseKeys string) *probe.Error {
 	err := fmt.Errorf("SSE alias '%s' overlaps with SSE-C aliases '%s'", sseServer, sseKeys)
 	return probe.NewError(conflictSSEErr(err)).Untrace()
 }
+
+type unsafeObjectKeyErr error
+
+var errUnsafeObjectKey = func(key, target string) *probe.Error {
+	msg := "Object key `" + key + "` resolves outside of the target folder `" + target + "`, refusing to download it."
+	return probe.NewError(unsafeObjectKeyErr(errors.New(msg)))
+}
+
+type stripAllComponentsErr error
+
+var errStripAllComponents = func(path string, n int) *probe.Error {
+	msg := fmt.Sprintf("--strip-components %d would strip the whole path of `%s`.", n, path)
+	return probe.NewError(stripAllComponentsErr(errors.New(msg)))
+}
+
+type objectTooLargeErr error
+
+var errObjectTooLarge = func(source string, size, limit int64) *probe.Error {
+	msg := fmt.Sprintf("`%s` is larger than the --max-object-size of %s.", source, formatSize(limit))
+	if size >= 0 {
+		msg = fmt.Sprintf("`%s` of %s is larger than the --max-object-size of %s.", source, formatSize(size), formatSize(limit))
+	}
+	return probe.NewError(objectTooLargeErr(errors.New(msg)))
+}
+
+type partSizeTooSmallErr error
+
+var errPartSizeTooSmall = func(source string, size int64, partSize, minSize uint64) *probe.Error {
+	msg := fmt.Sprintf("`%s` of %s needs more than %d parts of %s, use a --part-size of at least %s or --auto-part-size.",
+		source, formatSize(size), maxPartNumber, formatSize(int64(partSize)), formatSize(int64(minSize)))
+	return probe.NewError(partSizeTooSmallErr(errors.New(msg)))
+}
+
+type pathCollisionErr error
+
+var errPathCollision = func(option, target, first, second string) *probe.Error {
+	msg := fmt.Sprintf("%s maps both `%s` and `%s` to `%s`.", option, first, second, target)
+	return probe.NewError(pathCollisionErr(errors.New(msg)))
+}
+
+type sourceChangedErr struct {
+	error
+}
+
+var errSourceChanged = func(URL string) *probe.Error {
+	msg := "Source `" + URL + "` changed since the session started, upload it again without the session."
+	return probe.NewError(sourceChangedErr{errors.New(msg)})
+}
+
+type noGlobMatchErr error
+
+var errNoGlobMatch = func(pattern string) *probe.Error {
+	msg := "No objects match `" + pattern + "`, use --allow-empty to succeed without any."
+	return probe.NewError(noGlobMatchErr(errors.New(msg)))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cheggaaa/pb"
+	"github.com/dustin/go-humanize"
+)
+
+// sizeUnits selects how sizes are printed with --units. Sizes given on
+// the command line, such as --part-size, are parsed independently of it:
+// "MiB" is always 1024*1024 bytes and "MB" always 1000*1000 bytes.
+type sizeUnits string
+
+const (
+	// unitsIEC prints sizes in powers of 1024, e.g. "1.5 GiB".
+	unitsIEC sizeUnits = "iec"
+	// unitsSI prints sizes in powers of 1000, e.g. "1.6 GB".
+	unitsSI sizeUnits = "si"
+	// unitsRaw prints the exact number of bytes, e.g. "1610612736 B".
+	unitsRaw sizeUnits = "raw"
+)
+
+// parseUnits validates the value of --units, it defaults to IEC.
+func parseUnits(s string) (sizeUnits, error) {
+	switch u := sizeUnits(s); u {
+	case "":
+		return unitsIEC, nil
+	case unitsIEC, unitsSI, unitsRaw:
+		return u, nil
+	}
+	return "", fmt.Errorf("unknown units `%s`, expected one of %s, %s or %s", s, unitsIEC, unitsSI, unitsRaw)
+}
+
+// format prints size in bytes with the units of u.
+func (u sizeUnits) format(size uint64) string {
+	switch u {
+	case unitsSI:
+		return humanize.Bytes(size)
+	case unitsRaw:
+		return strconv.FormatUint(size, 10) + " B"
+	}
+	return humanize.IBytes(size)
+}
+
+// pbUnits returns the units of the progress bars.
+func (u sizeUnits) pbUnits() pb.Units {
+	switch u {
+	case unitsSI:
+		return pb.U_BYTES_DEC
+	case unitsRaw:
+		return pb.U_NO
+	}
+	return pb.U_BYTES
+}
+
+// formatSize prints a size in bytes with the units of --units, every
+// size shown to the user goes through it.
+func formatSize(size int64) string {
+	if size < 0 {
+		return "-" + globalUnits.format(uint64(-size))
+	}
+	return globalUnits.format(uint64(size))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestFormatSize(t *testing.T) {
+	defer func(u sizeUnits) { globalUnits = u }(globalUnits)
+
+	testCases := []struct {
+		units    string
+		size     int64
+		expected string
+	}{
+		{"", 1536, "1.5 KiB"},
+		{"iec", 1 << 30, "1.0 GiB"},
+		{"si", 1 << 30, "1.1 GB"},
+		{"si", 1500, "1.5 kB"},
+		{"raw", 1 << 30, "1073741824 B"},
+		{"raw", 0, "0 B"},
+	}
+
+	for i, testCase := range testCases {
+		units, e := parseUnits(testCase.units)
+		if e != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, e)
+		}
+		globalUnits = units
+		if got := formatSize(testCase.size); got != testCase.expected {
+			t.Fatalf("Test %d: expected %s, got %s", i+1, testCase.expected, got)
+		}
+	}
+
+	if _, e := parseUnits("GiB"); e == nil {
+		t.Fatal("expected unknown units to be rejected")
+	}
+}
+
+// The sizes of the errors are printed with --units too.
+func TestSizeErrorUnits(t *testing.T) {
+	defer func(u sizeUnits) { globalUnits = u }(globalUnits)
+	globalUnits = unitsRaw
+
+	for _, err := range []*probe.Error{
+		errObjectTooLarge("file", 2<<30, 1<<30),
+		errPartSizeTooSmall("file", 2<<30, 1<<20, 1<<30),
+	} {
+		if msg := err.ToGoError().Error(); !strings.Contains(msg, "1073741824 B") {
+			t.Errorf("expected the sizes in raw units, got %s", msg)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/env"
+)
+
+// uploadNotifyFlags POST a notification of the uploaded objects.
+var uploadNotifyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "notify",
+		Usage: "POST a JSON notification of each uploaded object to this URL, with the bearer token of MC_NOTIFY_TOKEN if set",
+	},
+	cli.BoolFlag{
+		Name:  "notify-batch",
+		Usage: "with --notify, POST a single notification listing the uploaded objects once done",
+	},
+	cli.BoolFlag{
+		Name:  "notify-strict",
+		Usage: "with --notify, fail when a notification cannot be delivered",
+	},
+}
+
+const (
+	// notifyAttempts is how many times a notification is sent before it
+	// is given up on.
+	notifyAttempts = 3
+	// notifyParallel is how many notifications are sent at once.
+	notifyParallel = 4
+	// notifyQueueSize is how many notifications wait to be sent before
+	// the transfers wait for them.
+	notifyQueueSize = 1000
+	notifyTimeout   = 30 * time.Second
+)
+
+// uploadNotification is the notification of an uploaded object.
+type uploadNotification struct {
+	Key      string  `json:"key"`
+	Size     int64   `json:"size"`
+	ETag     string  `json:"etag,omitempty"`
+	Duration float64 `json:"duration"`
+	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// uploadNotificationBatch is the notification of --notify-batch.
+type uploadNotificationBatch struct {
+	Status   string               `json:"status"`
+	Count    int                  `json:"count"`
+	Size     int64                `json:"size"`
+	Duration float64              `json:"duration"`
+	Objects  []uploadNotification `json:"objects"`
+}
+
+// uploadNotifier POSTs the notifications of uploaded objects in the
+// background with notifyParallel workers, so that a slow or failing
+// endpoint only holds the transfers up once notifyQueueSize
+// notifications are waiting. A nil uploadNotifier does nothing.
+type uploadNotifier struct {
+	url, token    string
+	batch, strict bool
+	client        *http.Client
+	retryDelay    time.Duration
+	start         time.Time
+
+	queue chan uploadNotification
+	wg    sync.WaitGroup
+
+	mu          sync.Mutex
+	objects     []uploadNotification
+	sent        int
+	undelivered int
+	lastErr     error
+
+	closeOnce sync.Once
+	failed    bool
+}
+
+// newUploadNotifierFromContext returns the notifier of --notify, or nil
+// when it is not set.
+func newUploadNotifierFromContext(cliCtx *cli.Context) *uploadNotifier {
+	notifyURL := cliCtx.String("notify")
+	if notifyURL == "" {
+		if cliCtx.Bool("notify-batch") || cliCtx.Bool("notify-strict") {
+			fatalIf(errInvalidArgument(), "--notify-batch and --notify-strict can only be used with --notify.")
+		}
+		return nil
+	}
+	if u, e := url.Parse(notifyURL); e != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fatalIf(errInvalidArgument().Trace(notifyURL), "--notify should be an http or https URL.")
+	}
+	n := newUploadNotifier(notifyURL, env.Get("MC_NOTIFY_TOKEN", ""), cliCtx.Bool("notify-batch"))
+	n.strict = cliCtx.Bool("notify-strict")
+	return n
+}
+
+func newUploadNotifier(notifyURL, token string, batch bool) *uploadNotifier {
+	n := &uploadNotifier{
+		url:        notifyURL,
+		token:      token,
+		batch:      batch,
+		client:     httpClient(notifyTimeout),
+		retryDelay: time.Second,
+		start:      time.Now(),
+		queue:      make(chan uploadNotification, notifyQueueSize),
+	}
+	if !batch {
+		for i := 0; i < notifyParallel; i++ {
+			n.wg.Add(1)
+			go func() {
+				defer n.wg.Done()
+				for notification := range n.queue {
+					n.send(notification)
+				}
+			}()
+		}
+	}
+	return n
+}
+
+// notify notifies the upload of size bytes to target, started at start,
+// which was given etag by the server.
+func (n *uploadNotifier) notify(targetAlias, targetURL, etag string, size int64, start time.Time, err *probe.Error) {
+	if n == nil {
+		return
+	}
+	notification := uploadNotification{
+		Key:      notifyKey(targetAlias, targetURL),
+		Size:     size,
+		ETag:     etag,
+		Duration: time.Since(start).Seconds(),
+		Status:   "success",
+	}
+	if err != nil {
+		notification.Status = "error"
+		notification.Error = err.ToGoError().Error()
+		notification.ETag = ""
+	}
+	if n.batch {
+		n.mu.Lock()
+		n.objects = append(n.objects, notification)
+		n.mu.Unlock()
+		return
+	}
+	n.queue <- notification
+}
+
+// notifyURLs notifies the upload of urls started at start.
+func (n *uploadNotifier) notifyURLs(urls URLs, start time.Time) {
+	if n == nil || urls.SourceContent == nil || urls.TargetContent == nil {
+		return
+	}
+	n.notify(urls.TargetAlias, urls.TargetContent.URL.String(), urls.TargetContent.ETag, urls.SourceContent.Size, start, urls.Error)
+}
+
+// notifyKey returns the key of targetURL in its bucket, or its path for
+// a local target.
+func notifyKey(targetAlias, targetURL string) string {
+	if clnt, err := newClientFromAlias(targetAlias, targetURL); err == nil {
+		if s3Clnt, ok := clnt.(*S3Client); ok {
+			_, object := s3Clnt.url2BucketAndObject()
+			return object
+		}
+	}
+	return filepath.ToSlash(newClientURL(targetURL).Path)
+}
+
+// send POSTs a notification, a few times if it fails, and accounts
+// for it.
+func (n *uploadNotifier) send(notification interface{}) {
+	body, e := json.Marshal(notification)
+	if e == nil {
+		e = n.post(body)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent++
+	if e != nil {
+		n.undelivered++
+		n.lastErr = e
+	}
+}
+
+func (n *uploadNotifier) post(body []byte) error {
+	var e error
+	for attempt := 0; attempt < notifyAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(n.retryDelay << (attempt - 1))
+		}
+		var retry bool
+		if retry, e = n.postOnce(body); e == nil || !retry {
+			return e
+		}
+	}
+	return e
+}
+
+// postOnce POSTs a notification once, reporting whether a failure is
+// worth retrying.
+func (n *uploadNotifier) postOnce(body []byte) (retry bool, e error) {
+	req, e := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if e != nil {
+		return false, e
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", getUserAgent())
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+	resp, e := n.client.Do(req)
+	if e != nil {
+		return true, e
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// Other client errors would fail again.
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, errors.New(resp.Status)
+}
+
+// close waits for the notifications in flight, sends the batch of
+// --notify-batch and reports the notifications which could not be
+// delivered. It returns true when they fail the command, with
+// --notify-strict.
+func (n *uploadNotifier) close() bool {
+	if n == nil {
+		return false
+	}
+	n.closeOnce.Do(func() { n.failed = n.finish() })
+	return n.failed
+}
+
+func (n *uploadNotifier) finish() bool {
+	close(n.queue)
+	n.wg.Wait()
+	if n.batch {
+		batch := uploadNotificationBatch{
+			Status:   "success",
+			Count:    len(n.objects),
+			Duration: time.Since(n.start).Seconds(),
+			Objects:  n.objects,
+		}
+		for _, o := range n.objects {
+			batch.Size += o.Size
+			if o.Status != "success" {
+				batch.Status = "error"
+			}
+		}
+		if batch.Objects == nil {
+			batch.Objects = []uploadNotification{}
+		}
+		n.send(batch)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.undelivered == 0 {
+		return false
+	}
+	msg := fmt.Sprintf("%d of %d notifications to %s could not be delivered", n.undelivered, n.sent, n.url)
+	if n.strict {
+		errorIf(probe.NewError(n.lastErr), msg+".")
+		return true
+	}
+	statusf("[Warn] %s: %v\n", msg, n.lastErr)
+	return false
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/internal/miniotest"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// notifyEndpoint records the notifications it receives, answering the
+// first failures requests with status.
+type notifyEndpoint struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	status   int
+	requests int
+	auth     []string
+	bodies   [][]byte
+}
+
+func newNotifyEndpoint(t *testing.T, failures, status int) *notifyEndpoint {
+	e := &notifyEndpoint{failures: failures, status: status}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.requests++
+		if e.requests <= e.failures {
+			w.WriteHeader(e.status)
+			return
+		}
+		e.auth = append(e.auth, r.Header.Get("Authorization"))
+		e.bodies = append(e.bodies, body)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func testUploadNotifier(endpoint *notifyEndpoint, batch bool) *uploadNotifier {
+	n := newUploadNotifier(endpoint.URL, "secret", batch)
+	n.retryDelay = time.Millisecond
+	return n
+}
+
+func TestUploadNotifier(t *testing.T) {
+	initTestConfig(t)
+	server := miniotest.NewServer()
+	defer server.Close()
+	server.MakeBucket("bucket")
+	t.Setenv(mcEnvHostPrefix+"notifytest", server.AliasURL())
+
+	// The first notification is retried after a server error.
+	endpoint := newNotifyEndpoint(t, 1, http.StatusServiceUnavailable)
+	n := testUploadNotifier(endpoint, false)
+	alias, targetURL, _ := mustExpandAlias("notifytest/bucket/a.txt")
+	n.notify(alias, targetURL, "5d41402abc4b2a76b9719d911017c592", 5, time.Now(), nil)
+	alias, targetURL, _ = mustExpandAlias("notifytest/bucket/b.txt")
+	n.notify(alias, targetURL, "", 7, time.Now(), probe.NewError(errors.New("connection reset")))
+	if n.close() {
+		t.Fatal("expected the notifications to be delivered")
+	}
+
+	var notifications []uploadNotification
+	for _, body := range endpoint.bodies {
+		var notification uploadNotification
+		if e := json.Unmarshal(body, &notification); e != nil {
+			t.Fatal(e)
+		}
+		notifications = append(notifications, notification)
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].Key < notifications[j].Key })
+	for i, want := range []uploadNotification{
+		{Key: "a.txt", Size: 5, ETag: "5d41402abc4b2a76b9719d911017c592", Status: "success"},
+		{Key: "b.txt", Size: 7, Status: "error", Error: "connection reset"},
+	} {
+		if i >= len(notifications) {
+			t.Fatalf("expected %d notifications, got %d", 2, len(notifications))
+		}
+		got := notifications[i]
+		got.Duration = 0
+		if got != want {
+			t.Errorf("expected the notification %+v, got %+v", want, got)
+		}
+	}
+	for _, auth := range endpoint.auth {
+		if auth != "Bearer secret" {
+			t.Errorf("expected the bearer token, got %q", auth)
+		}
+	}
+	if endpoint.requests != 3 {
+		t.Errorf("expected a single retry, got %d requests", endpoint.requests)
+	}
+}
+
+func TestUploadNotifierBatch(t *testing.T) {
+	initTestConfig(t)
+	endpoint := newNotifyEndpoint(t, 0, 0)
+	n := testUploadNotifier(endpoint, true)
+	n.notify("", "/data/a", "", 5, time.Now(), probe.NewError(errors.New("disk full")))
+	n.notify("", "/data/b", "", 7, time.Now(), nil)
+	n.close()
+	// Closing again sends nothing more.
+	n.close()
+
+	if len(endpoint.bodies) != 1 {
+		t.Fatalf("expected a single notification, got %d", len(endpoint.bodies))
+	}
+	var batch uploadNotificationBatch
+	if e := json.Unmarshal(endpoint.bodies[0], &batch); e != nil {
+		t.Fatal(e)
+	}
+	if batch.Status != "error" || batch.Count != 2 || batch.Size != 12 || len(batch.Objects) != 2 {
+		t.Errorf("unexpected batch %+v", batch)
+	}
+}
+
+func TestUploadNotifierUndelivered(t *testing.T) {
+	initTestConfig(t)
+	for i, testCase := range []struct {
+		status   int
+		strict   bool
+		requests int
+	}{
+		// Server errors are retried, client errors would fail again.
+		{http.StatusInternalServerError, false, notifyAttempts},
+		{http.StatusBadRequest, false, 1},
+		{http.StatusInternalServerError, true, notifyAttempts},
+	} {
+		endpoint := newNotifyEndpoint(t, notifyAttempts, testCase.status)
+		n := testUploadNotifier(endpoint, false)
+		n.strict = testCase.strict
+		n.notify("", "/data/a", "", 5, time.Now(), nil)
+		if failed := n.close(); failed != testCase.strict {
+			t.Errorf("Test %d: expected the command to fail: %v, got %v", i+1, testCase.strict, failed)
+		}
+		if n.undelivered != 1 || endpoint.requests != testCase.requests {
+			t.Errorf("Test %d: expected %d requests and an undelivered notification, got %d and %d", i+1, testCase.requests, endpoint.requests, n.undelivered)
+		}
+	}
+}
+
+func TestUploadNotifierParallel(t *testing.T) {
+	initTestConfig(t)
+	var mu sync.Mutex
+	var inFlight, maxInFlight, requests int
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		requests++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	defer endpoint.Close()
+
+	n := newUploadNotifier(endpoint.URL, "", false)
+	for i := 0; i < 5*notifyParallel; i++ {
+		n.notify("", "/data/a", "", 5, time.Now(), nil)
+	}
+	if n.close() {
+		t.Fatal("expected the notifications to be delivered")
+	}
+	if requests != 5*notifyParallel || maxInFlight > notifyParallel {
+		t.Fatalf("expected %d notifications, %d at most at once, got %d, %d at once", 5*notifyParallel, notifyParallel, requests, maxInFlight)
+	}
+}
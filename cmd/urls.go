@@ -30,10 +30,14 @@ type URLs struct {
 	TotalCount       int64
 	TotalSize        int64
 	MD5              bool
+	StoreChecksum    bool
 	DisableMultipart bool
 	encKeyDB         map[string][]prefixSSEPair
 	Error            *probe.Error `json:"-"`
 	ErrorCond        differType   `json:"-"`
+
+	// targetNewer is set by put --only-newer to skip the upload.
+	targetNewer bool
 }
 
 // WithError sets the error and returns object
@@ -148,8 +148,18 @@ func NewS3Config(alias, urlStr string, aliasCfg *aliasConfigV10) *Config {
 	s3Config.Insecure = globalInsecure
 	s3Config.ConnReadDeadline = globalConnReadDeadline
 	s3Config.ConnWriteDeadline = globalConnWriteDeadline
-	s3Config.UploadLimit = int64(globalLimitUpload)
-	s3Config.DownloadLimit = int64(globalLimitDownload)
+	s3Config.UploadBucket = globalUploadBucket
+	s3Config.DownloadBucket = globalDownloadBucket
+	s3Config.MaxHostConns = globalMaxHostConns
+	s3Config.StallTimeout = globalStallTimeout
+	s3Config.RequestTimeout = globalRequestTimeout
+	s3Config.TCPKeepAlive = globalTCPKeepAlive
+	s3Config.MaxRetryTime = globalMaxRetryTime
+	s3Config.RequestBucket = globalRequestBucket
+	s3Config.PartConcurrency = globalPartConcurrency
+	s3Config.HTTP1 = globalHTTP1
+	s3Config.HTTP2 = globalHTTP2
+	s3Config.SyncTime = globalSyncTime
 
 	s3Config.HostURL = urlStr
 	s3Config.Alias = alias
@@ -157,9 +167,15 @@ func NewS3Config(alias, urlStr string, aliasCfg *aliasConfigV10) *Config {
 		s3Config.AccessKey = aliasCfg.AccessKey
 		s3Config.SecretKey = aliasCfg.SecretKey
 		s3Config.SessionToken = aliasCfg.SessionToken
+		s3Config.SessionExpiry = aliasCfg.SessionExpiry
+		s3Config.Region = aliasCfg.Region
+		s3Config.SigningRegion = aliasCfg.SigningRegion
 		s3Config.Signature = aliasCfg.API
 		s3Config.Lookup = getLookupType(aliasCfg.Path)
 	}
+	if alias == AuthAlias && globalCredentialHelper != nil {
+		s3Config.CredsProvider = globalCredentialHelper
+	}
 	return s3Config
 }
 
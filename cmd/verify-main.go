@@ -0,0 +1,537 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+var verifyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "manifest",
+		Usage: "verify the MD5 or SHA-256 sums listed in a file in the md5sum or sha256sum format instead of a local folder",
+	},
+}
+
+// Compare local files with their copies on a remote.
+var verifyCmd = cli.Command{
+	Name:         "verify",
+	Usage:        "compare the checksums of local files with their copies on a remote",
+	Action:       mainVerify,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(verifyFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] LOCAL-PATH TARGET
+  {{.HelpName}} [FLAGS] --manifest FILE TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Verify computes the MD5 sum of every file of LOCAL-PATH and compares it
+  with the ETag of the object of the same name under TARGET. The ETag of
+  an object uploaded in parts is computed again from the file with the part
+  size of the object. Files missing on either side are reported too.
+
+  The ETag of an object uploaded with --store-checksum, in parts or encrypted,
+  need not be computed again: the SHA-256 sum of the file is compared with the
+  one stored in the metadata of the object.
+
+  With --manifest, the MD5 sums are read from FILE, the output of md5sum run
+  from the uploaded folder, instead of computed. The ETags of objects uploaded
+  in parts cannot be computed without the files, these objects are skipped. The
+  SHA-256 sums of a manifest written by sha256sum are compared with those stored
+  by --store-checksum, objects without one are skipped.
+
+  Verify exits with an error if any file differs or is missing on either side.
+
+EXAMPLES:
+  1. Verify the upload of a local folder.
+     {{.Prompt}} {{.HelpName}} ~/Photos s3/mybucket/Photos
+
+  2. Verify an upload against a manifest, in JSON.
+     {{.Prompt}} {{.HelpName}} --json --manifest ~/Photos.md5 s3/mybucket/Photos
+
+  3. Verify an upload made with --store-checksum against the output of sha256sum.
+     {{.Prompt}} {{.HelpName}} --manifest ~/Photos.sha256 s3/mybucket/Photos
+`,
+}
+
+// Results of the comparison of a file.
+const (
+	verifyMatch         = "match"
+	verifyMismatch      = "mismatch"
+	verifyMissingRemote = "missing-remote"
+	verifyMissingLocal  = "missing-local"
+	verifySkipped       = "skipped"
+)
+
+// verifyMessage is the result of the comparison of a local file with its
+// copy on the remote.
+type verifyMessage struct {
+	Status     string `json:"status"`
+	Type       string `json:"type"`
+	Result     string `json:"result"`
+	Reason     string `json:"reason,omitempty"`
+	Local      string `json:"local"`
+	Remote     string `json:"remote"`
+	LocalSize  int64  `json:"localSize,omitempty"`
+	RemoteSize int64  `json:"remoteSize,omitempty"`
+	Checksum   string `json:"checksum,omitempty"`
+	ETag       string `json:"etag,omitempty"`
+	SHA256     string `json:"sha256,omitempty"`
+}
+
+func (m verifyMessage) String() string {
+	switch m.Result {
+	case verifyMatch:
+		return console.Colorize("VerifyMatch", fmt.Sprintf("`%s` matches `%s`.", m.Local, m.Remote))
+	case verifyMissingRemote:
+		return console.Colorize("VerifyMissing", fmt.Sprintf("`%s` is missing on the remote, expected at `%s`.", m.Local, m.Remote))
+	case verifyMissingLocal:
+		return console.Colorize("VerifyMissing", fmt.Sprintf("`%s` is missing locally, expected at `%s`.", m.Remote, m.Local))
+	case verifySkipped:
+		var reason string
+		switch m.Reason {
+		case "multipart":
+			reason = "uploaded in parts, the file is needed to compute its ETag"
+		case "sha256":
+			reason = "no SHA-256 sum stored with the object"
+		default:
+			reason = fmt.Sprintf("ETag %s is not an MD5 sum", m.ETag)
+		}
+		return console.Colorize("VerifySkipped", fmt.Sprintf("`%s` -> `%s`: skipped, %s.", m.Local, m.Remote, reason))
+	}
+	var reason string
+	switch m.Reason {
+	case "size":
+		reason = fmt.Sprintf("size %s on the remote, %s expected", formatSize(m.RemoteSize), formatSize(m.LocalSize))
+	case "checksum":
+		reason = fmt.Sprintf("ETag %s on the remote, %s expected", m.ETag, m.Checksum)
+	case "sha256":
+		reason = fmt.Sprintf("SHA-256 %s on the remote, %s expected", m.SHA256, m.Checksum)
+	default:
+		reason = "not an object on the remote"
+	}
+	return console.Colorize("VerifyMismatch", fmt.Sprintf("`%s` -> `%s`: %s.", m.Local, m.Remote, reason))
+}
+
+func (m verifyMessage) JSON() string {
+	m.Status = "success"
+	if m.Result != verifyMatch && m.Result != verifySkipped {
+		m.Status = "error"
+	}
+	m.Type = "verify"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// verifySummary counts the results of verify.
+type verifySummary struct {
+	Status        string `json:"status"`
+	Type          string `json:"type"`
+	Matched       int64  `json:"matched"`
+	Mismatched    int64  `json:"mismatched"`
+	MissingRemote int64  `json:"missingRemote"`
+	MissingLocal  int64  `json:"missingLocal"`
+	Skipped       int64  `json:"skipped"`
+}
+
+func (s *verifySummary) add(m verifyMessage) {
+	switch m.Result {
+	case verifyMatch:
+		s.Matched++
+	case verifyMismatch:
+		s.Mismatched++
+	case verifyMissingRemote:
+		s.MissingRemote++
+	case verifyMissingLocal:
+		s.MissingLocal++
+	case verifySkipped:
+		s.Skipped++
+	}
+}
+
+func (s verifySummary) failed() bool {
+	return s.Mismatched+s.MissingRemote+s.MissingLocal > 0
+}
+
+func (s verifySummary) String() string {
+	msg := fmt.Sprintf("%d matched, %d mismatched, %d missing on the remote, %d missing locally, %d skipped.",
+		s.Matched, s.Mismatched, s.MissingRemote, s.MissingLocal, s.Skipped)
+	if s.failed() {
+		return console.Colorize("VerifyMismatch", "Verification failed, "+msg)
+	}
+	return console.Colorize("VerifyMatch", "Verified, "+msg)
+}
+
+func (s verifySummary) JSON() string {
+	s.Status = "success"
+	if s.failed() {
+		s.Status = "error"
+	}
+	s.Type = "verify-summary"
+	msgBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// checkVerifySyntax validates the arguments of verify.
+func checkVerifySyntax(cliCtx *cli.Context) {
+	args := cliCtx.Args()
+	manifest := cliCtx.String("manifest")
+	if manifest == "" && len(args) != 2 || manifest != "" && len(args) != 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code
+	}
+	for _, arg := range args {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(args...), "Unable to validate empty argument.")
+		}
+	}
+	if alias, _, _ := mustExpandAlias(args[len(args)-1]); alias == "" {
+		fatalIf(errInvalidArgument().Trace(args...), fmt.Sprintf("`%s` is not on a remote.", args[len(args)-1]))
+	}
+	if manifest != "" {
+		return
+	}
+	if alias, _, _ := mustExpandAlias(args[0]); alias != "" {
+		fatalIf(errInvalidArgument().Trace(args...), fmt.Sprintf("`%s` is not a local path.", args[0]))
+	}
+	st, e := os.Stat(args[0])
+	fatalIf(probe.NewError(e).Trace(args[0]), fmt.Sprintf("Unable to stat `%s`.", args[0]))
+	if !st.IsDir() {
+		fatalIf(errInvalidArgument().Trace(args[0]), fmt.Sprintf("`%s` is not a folder.", args[0]))
+	}
+}
+
+// newVerifyClient returns a client for urlStr as a folder, with its URL.
+func newVerifyClient(urlStr string) (Client, string, *probe.Error) {
+	if sep := string(newClientURL(urlStr).Separator); !strings.HasSuffix(urlStr, sep) {
+		urlStr += sep
+	}
+	alias, expandedURL, _ := mustExpandAlias(urlStr)
+	clnt, err := newClientFromAlias(alias, expandedURL)
+	if err != nil {
+		return nil, "", err.Trace(urlStr)
+	}
+	return clnt, clnt.GetURL().String(), nil
+}
+
+// verifyLocal compares the files of localPath with the objects under
+// targetURL, printing the result for every file.
+func verifyLocal(ctx context.Context, localPath, targetURL string) (verifySummary, *probe.Error) {
+	var summary verifySummary
+	localClnt, localURL, err := newVerifyClient(localPath)
+	if err != nil {
+		return summary, err
+	}
+	targetClnt, remoteURL, err := newVerifyClient(targetURL)
+	if err != nil {
+		return summary, err
+	}
+	targetAlias, _, _ := mustExpandAlias(targetURL)
+
+	listOpts := ListOptions{Recursive: true, ShowDir: DirNone}
+	localCh := localClnt.List(ctx, listOpts)
+	targetCh := targetClnt.List(ctx, listOpts)
+	for diffMsg := range difference(localURL, localCh, remoteURL, targetCh, false, true, compareSize) {
+		if diffMsg.Error != nil {
+			return summary, diffMsg.Error.Trace(localPath, targetURL)
+		}
+		msg := verifyMessage{Local: diffMsg.FirstURL, Remote: diffMsg.SecondURL}
+		if diffMsg.firstContent != nil {
+			msg.LocalSize = diffMsg.firstContent.Size
+		}
+		if diffMsg.secondContent != nil {
+			msg.RemoteSize = diffMsg.secondContent.Size
+		}
+		switch diffMsg.Diff {
+		case differInNone:
+			if err = verifyContent(ctx, targetAlias, targetClnt, diffMsg.firstContent, diffMsg.secondContent, &msg); err != nil {
+				return summary, err.Trace(msg.Local, msg.Remote)
+			}
+		case differInFirst:
+			msg.Result = verifyMissingRemote
+			msg.Remote = urlJoinPath(remoteURL, strings.TrimPrefix(diffMsg.FirstURL, localURL))
+		case differInSecond:
+			msg.Result = verifyMissingLocal
+			msg.Local = filepath.Join(localURL, filepath.FromSlash(strings.TrimPrefix(diffMsg.SecondURL, remoteURL)))
+		case differInSize:
+			msg.Result, msg.Reason = verifyMismatch, "size"
+		default:
+			msg.Result, msg.Reason = verifyMismatch, "type"
+		}
+		summary.add(msg)
+		printMsg(msg)
+	}
+	errorIf(getMD5Cache().save(), "Unable to save the MD5 sums of local files.")
+	return summary, nil
+}
+
+// verifyContent compares a local file with the remote object of the same
+// size. The ETag of an object uploaded in parts is the MD5 sum of the MD5
+// sums of its parts, it is computed from the file split in parts of the
+// size of the first part of the object, unless the object has a SHA-256
+// sum stored by --store-checksum.
+func verifyContent(ctx context.Context, alias string, clnt Client, local, remote *ClientContent, msg *verifyMessage) *probe.Error {
+	msg.ETag = strings.ToLower(strings.Trim(remote.ETag, "\""))
+	if !isMD5ETag(msg.ETag) {
+		sum, err := remoteSHA256(ctx, alias, remote)
+		if err != nil {
+			return err
+		}
+		if sum != "" {
+			msg.SHA256 = sum
+			if msg.Checksum, err = fileSHA256(local.URL.Path); err != nil {
+				return err
+			}
+			if msg.Checksum != msg.SHA256 {
+				msg.Result, msg.Reason = verifyMismatch, "sha256"
+				return nil
+			}
+			msg.Result = verifyMatch
+			return nil
+		}
+	}
+	switch {
+	case isMD5ETag(msg.ETag):
+		sum, err := getMD5Cache().sum(local)
+		if err != nil {
+			return err
+		}
+		msg.Checksum = sum
+	case isMultipartETag(msg.ETag):
+		s3Clnt, ok := clnt.(*S3Client)
+		if !ok {
+			msg.Result, msg.Reason = verifySkipped, "etag"
+			return nil
+		}
+		bucket, object := url2BucketAndObject(&remote.URL)
+		partSize, err := s3Clnt.PartSize(ctx, bucket, object)
+		if err != nil {
+			return err
+		}
+		f, e := os.Open(local.URL.Path)
+		if e != nil {
+			return probe.NewError(e)
+		}
+		defer f.Close()
+		hasher := newPartETagHasher(partSize)
+		if _, e = io.Copy(hasher, f); e != nil {
+			return probe.NewError(e)
+		}
+		msg.Checksum = hasher.etag(true)
+	default:
+		msg.Result, msg.Reason = verifySkipped, "etag"
+		return nil
+	}
+	if msg.Checksum != msg.ETag {
+		msg.Result, msg.Reason = verifyMismatch, "checksum"
+		return nil
+	}
+	msg.Result = verifyMatch
+	return nil
+}
+
+// remoteSHA256 returns the SHA-256 sum stored with the object of remote,
+// which listings do not return, or "" when it has none.
+func remoteSHA256(ctx context.Context, alias string, remote *ClientContent) (string, *probe.Error) {
+	if sum := storedSHA256(remote); sum != "" {
+		return sum, nil
+	}
+	clnt, err := newClientFromAlias(alias, remote.URL.String())
+	if err != nil {
+		return "", err
+	}
+	content, err := clnt.Stat(ctx, StatOptions{versionID: remote.VersionID})
+	if err != nil {
+		return "", err.Trace(remote.URL.String())
+	}
+	return storedSHA256(content), nil
+}
+
+// verifyManifestEntry is a line of a manifest, the MD5 or SHA-256 sum of a
+// file and its name relative to the uploaded folder.
+type verifyManifestEntry struct {
+	sum  string
+	name string
+}
+
+// readVerifyManifest parses a manifest in the format of md5sum or sha256sum,
+// lines of a sum, a space, a space or '*' and a file name.
+func readVerifyManifest(path string) ([]verifyManifestEntry, *probe.Error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer f.Close()
+
+	var entries []verifyManifestEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		sum, name, ok := strings.Cut(text, " ")
+		if ok && (strings.HasPrefix(name, " ") || strings.HasPrefix(name, "*")) {
+			name = name[1:]
+		}
+		if !ok || !isMD5ETag(sum) && !isSHA256Sum(strings.ToLower(sum)) || name == "" {
+			return nil, probe.NewError(fmt.Errorf("invalid line %d of `%s`", line, path))
+		}
+		entries = append(entries, verifyManifestEntry{
+			sum:  strings.ToLower(sum),
+			name: strings.TrimPrefix(filepath.ToSlash(name), "./"),
+		})
+	}
+	if e = scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return entries, nil
+}
+
+// verifyManifest compares the MD5 sums of a manifest with the ETags of
+// the objects under targetURL, and its SHA-256 sums with those stored with
+// the objects, printing the result for every file.
+func verifyManifest(ctx context.Context, manifest, targetURL string) (verifySummary, *probe.Error) {
+	var summary verifySummary
+	entries, err := readVerifyManifest(manifest)
+	if err != nil {
+		return summary, err.Trace(manifest)
+	}
+	targetClnt, remoteURL, err := newVerifyClient(targetURL)
+	if err != nil {
+		return summary, err
+	}
+	targetAlias, _, _ := mustExpandAlias(targetURL)
+
+	remotes := map[string]*ClientContent{}
+	for content := range targetClnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return summary, content.Err.Trace(targetURL)
+		}
+		remotes[strings.TrimPrefix(content.URL.String(), remoteURL)] = content
+	}
+
+	for _, entry := range entries {
+		msg := verifyMessage{Local: entry.name, Remote: urlJoinPath(remoteURL, entry.name), Checksum: entry.sum}
+		remote, ok := remotes[entry.name]
+		delete(remotes, entry.name)
+		switch {
+		case !ok:
+			msg.Result = verifyMissingRemote
+		case !remote.Type.IsRegular():
+			msg.Result, msg.Reason = verifyMismatch, "type"
+		case isSHA256Sum(entry.sum):
+			msg.RemoteSize = remote.Size
+			if msg.SHA256, err = remoteSHA256(ctx, targetAlias, remote); err != nil {
+				return summary, err
+			}
+			switch msg.SHA256 {
+			case entry.sum:
+				msg.Result = verifyMatch
+			case "":
+				msg.Result, msg.Reason = verifySkipped, "sha256"
+			default:
+				msg.Result, msg.Reason = verifyMismatch, "sha256"
+			}
+		default:
+			msg.RemoteSize = remote.Size
+			msg.ETag = strings.ToLower(strings.Trim(remote.ETag, "\""))
+			switch {
+			case isMD5ETag(msg.ETag) && msg.ETag == entry.sum:
+				msg.Result = verifyMatch
+			case isMD5ETag(msg.ETag):
+				msg.Result, msg.Reason = verifyMismatch, "checksum"
+			case isMultipartETag(msg.ETag):
+				msg.Result, msg.Reason = verifySkipped, "multipart"
+			default:
+				msg.Result, msg.Reason = verifySkipped, "etag"
+			}
+		}
+		summary.add(msg)
+		printMsg(msg)
+	}
+
+	names := make([]string, 0, len(remotes))
+	for name := range remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		msg := verifyMessage{
+			Result:     verifyMissingLocal,
+			Local:      name,
+			Remote:     urlJoinPath(remoteURL, name),
+			RemoteSize: remotes[name].Size,
+		}
+		summary.add(msg)
+		printMsg(msg)
+	}
+	return summary, nil
+}
+
+// mainVerify is the entry point of the verify command.
+func mainVerify(cliCtx *cli.Context) error {
+	ctx, cancelVerify := context.WithCancel(globalContext)
+	defer cancelVerify()
+
+	checkVerifySyntax(cliCtx)
+
+	// Additional command specific theme customization.
+	console.SetColor("VerifyMatch", color.New(color.FgGreen))
+	console.SetColor("VerifyMismatch", color.New(color.FgRed, color.Bold))
+	console.SetColor("VerifyMissing", color.New(color.FgYellow, color.Bold))
+	console.SetColor("VerifySkipped", color.New(color.FgCyan))
+
+	args := cliCtx.Args()
+	var (
+		summary verifySummary
+		err     *probe.Error
+	)
+	if manifest := cliCtx.String("manifest"); manifest != "" {
+		summary, err = verifyManifest(ctx, manifest, args[0])
+	} else {
+		summary, err = verifyLocal(ctx, args[0], args[1])
+	}
+	fatalIf(err, "Unable to verify `"+args[len(args)-1]+"`.")
+	printMsg(summary)
+	if summary.failed() {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
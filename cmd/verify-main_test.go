@@ -0,0 +1,103 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/internal/miniotest"
+)
+
+func TestVerify(t *testing.T) {
+	initTestConfig(t)
+	defer func(c *md5Cache) { globalMD5Cache = c }(globalMD5Cache)
+	globalMD5Cache = loadMD5Cache("")
+	server := miniotest.NewServer()
+	defer server.Close()
+	t.Setenv(mcEnvHostPrefix+"verify", server.AliasURL())
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"match":           "hello",
+		"mismatch":        "hello",
+		"missing":         "hello",
+		"sub/multipart":   "0123456789abc",
+		"sub/multibroken": "0123456789abc",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if e := os.MkdirAll(filepath.Dir(path), 0o700); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(content), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	server.PutObject("bucket", "prefix/match", []byte("hello"))
+	server.PutObject("bucket", "prefix/mismatch", []byte("jello"))
+	server.PutObject("bucket", "prefix/remote-only", []byte("hello"))
+	// The ETag of the file split in parts of 5 bytes is computed again.
+	server.PutMultipartObject("bucket", "prefix/sub/multipart", []byte("01234"), []byte("56789"), []byte("abc"))
+	server.PutMultipartObject("bucket", "prefix/sub/multibroken", []byte("01234"), []byte("56789"), []byte("abd"))
+
+	summary, err := verifyLocal(context.Background(), dir, "verify/bucket/prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := verifySummary{Matched: 2, Mismatched: 2, MissingRemote: 1, MissingLocal: 1}
+	if summary != expected {
+		t.Fatalf("expected %+v, got %+v", expected, summary)
+	}
+	if !summary.failed() {
+		t.Fatal("expected the verification to fail")
+	}
+
+	// Only the files of the manifest are compared, the ETags of objects
+	// uploaded in parts cannot be computed without their content.
+	var manifest strings.Builder
+	for _, name := range []string{"match", "mismatch", "missing", "sub/multipart"} {
+		sum := md5.Sum([]byte(files[name]))
+		fmt.Fprintf(&manifest, "%s  ./%s\n", hex.EncodeToString(sum[:]), name)
+	}
+	manifestPath := filepath.Join(t.TempDir(), "manifest.md5")
+	if e := os.WriteFile(manifestPath, []byte(manifest.String()), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	summary, err = verifyManifest(context.Background(), manifestPath, "verify/bucket/prefix/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = verifySummary{Matched: 1, Mismatched: 1, MissingRemote: 1, MissingLocal: 2, Skipped: 1}
+	if summary != expected {
+		t.Fatalf("expected %+v, got %+v", expected, summary)
+	}
+
+	if e := os.WriteFile(manifestPath, []byte("not a checksum  file\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if _, err = verifyManifest(context.Background(), manifestPath, "verify/bucket/prefix"); err == nil {
+		t.Fatal("expected an invalid manifest to be rejected")
+	}
+}
@@ -93,6 +93,7 @@ func mainVersionSuspend(cliCtx *cli.Context) error {
 	// Create a new Client
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to initialize connection.")
+	defer client.Close()
 	fatalIf(client.SetVersion(ctx, "suspend", nil, false), "Unable to suspend versioning")
 	printMsg(versionSuspendMessage{
 		Op:     cliCtx.Command.Name,
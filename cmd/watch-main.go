@@ -23,7 +23,6 @@ import (
 	"strings"
 	"sync"
 
-	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
@@ -122,7 +121,7 @@ func (u watchMessage) JSON() string {
 func (u watchMessage) String() string {
 	msg := console.Colorize("Time", fmt.Sprintf("[%s] ", u.Event.Time))
 	if strings.HasPrefix(string(u.Event.Type), "s3:ObjectCreated:") {
-		msg += console.Colorize("Size", fmt.Sprintf("%6s ", humanize.IBytes(uint64(u.Event.Size))))
+		msg += console.Colorize("Size", fmt.Sprintf("%6s ", formatSize(u.Event.Size)))
 	} else {
 		msg += fmt.Sprintf("%6s ", "")
 	}
@@ -151,6 +150,7 @@ func mainWatch(cliCtx *cli.Context) error {
 	if pErr != nil {
 		fatalIf(pErr.Trace(), "Unable to parse the provided url.")
 	}
+	defer s3Client.Close()
 
 	options := WatchOptions{
 		Recursive: recursive,
@@ -0,0 +1,130 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Whoami command.
+var whoamiCmd = cli.Command{
+	Name:         "whoami",
+	Usage:        "show the alias and credentials a path is accessed with",
+	Action:       mainWhoami,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] [PATH]
+
+DESCRIPTION:
+  Paths starting with the name of an alias set with 'mc alias set' are
+  accessed with the credentials of that alias, any other path is relative
+  to your gpumall directory.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Show the gpumall account used by default
+    {{.Prompt}} {{.HelpName}}
+  2. Show the alias and credentials used for a path of the alias 'myminio'
+    {{.Prompt}} {{.HelpName}} myminio/mybucket/object
+`,
+}
+
+// whoamiMessage describes how a path is accessed.
+type whoamiMessage struct {
+	Status    string `json:"status"`
+	Path      string `json:"path"`
+	Alias     string `json:"alias"`
+	URL       string `json:"url"`
+	AccessKey string `json:"accessKey"`
+	Source    string `json:"source"`
+	ExpireAt  string `json:"expireAt,omitempty"`
+}
+
+// Colorized message for console printing.
+func (w whoamiMessage) String() string {
+	lines := []string{
+		fmt.Sprintf("Path:      %s", w.Path),
+		fmt.Sprintf("Alias:     %s", w.Alias),
+		fmt.Sprintf("URL:       %s", w.URL),
+		fmt.Sprintf("AccessKey: %s", w.AccessKey),
+		fmt.Sprintf("Source:    %s", w.Source),
+	}
+	if w.ExpireAt != "" {
+		lines = append(lines, fmt.Sprintf("ExpireAt:  %s", w.ExpireAt))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON'ified message for scripting.
+func (w whoamiMessage) JSON() string {
+	w.Status = "success"
+	msgBytes, e := json.MarshalIndent(w, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// whoami describes the alias and credentials path is accessed with.
+func whoami(path string) whoamiMessage {
+	alias := pathAlias(path)
+	if alias == "" {
+		auth := getAuth()
+		return whoamiMessage{
+			Path:      getFullPath(path),
+			Alias:     AuthAlias,
+			URL:       auth.Endpoint,
+			AccessKey: auth.AccessKey,
+			Source:    storageEndpointSource(AuthAlias),
+			ExpireAt:  auth.ExpireAt,
+		}
+	}
+	_, _, aliasCfg, err := expandAlias(path)
+	fatalIf(err.Trace(path), "Unable to expand alias `"+alias+"`.")
+	return whoamiMessage{
+		Path:      path,
+		Alias:     alias,
+		URL:       aliasCfg.URL,
+		AccessKey: aliasCfg.AccessKey,
+		Source:    storageEndpointSource(alias),
+	}
+}
+
+// mainWhoami is the entry point for whoami command.
+func mainWhoami(cliCtx *cli.Context) error {
+	args := cliCtx.Args()
+	if len(args) > 1 {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code.
+	}
+	path := args.First()
+	if path == "" {
+		path = "."
+	}
+	printMsg(whoami(path))
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package miniotest
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+const (
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+	// AllUsers is the URI of the group of anonymous users.
+	AllUsers = "http://acs.amazonaws.com/groups/global/AllUsers"
+	// AuthenticatedUsers is the URI of the group of signed requests.
+	AuthenticatedUsers = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// Grant is a permission granted by the ACL of an object.
+type Grant struct {
+	// GranteeType is CanonicalUser, AmazonCustomerByEmail or Group.
+	GranteeType  string
+	ID           string
+	EmailAddress string
+	URI          string
+	Permission   string
+}
+
+// ownerGrant gives full control of objects to their owner.
+var ownerGrant = Grant{GranteeType: "CanonicalUser", ID: testOwner.ID, Permission: "FULL_CONTROL"}
+
+// cannedACLGrants returns the grants of a canned ACL, false if it is
+// not supported.
+func cannedACLGrants(canned string) ([]Grant, bool) {
+	group := func(uri, permission string) Grant {
+		return Grant{GranteeType: "Group", URI: uri, Permission: permission}
+	}
+	switch canned {
+	case "private":
+		return []Grant{ownerGrant}, true
+	case "public-read":
+		return []Grant{ownerGrant, group(AllUsers, "READ")}, true
+	case "public-read-write":
+		return []Grant{ownerGrant, group(AllUsers, "READ"), group(AllUsers, "WRITE")}, true
+	case "authenticated-read":
+		return []Grant{ownerGrant, group(AuthenticatedUsers, "READ")}, true
+	}
+	return nil, false
+}
+
+// requestACL returns the grants of the canned ACL of a request, nil
+// for the private ACL if it has none.
+func requestACL(r *http.Request) ([]Grant, *apiError) {
+	canned := r.Header.Get("X-Amz-Acl")
+	if canned == "" {
+		return nil, nil
+	}
+	grants, ok := cannedACLGrants(canned)
+	if !ok {
+		return nil, errInvalidArgument.withMessage("Unsupported canned ACL " + r.Header.Get("X-Amz-Acl") + ".")
+	}
+	return grants, nil
+}
+
+// publicRead reports whether the grants allow anonymous reads.
+func publicRead(grants []Grant) bool {
+	for _, g := range grants {
+		if g.URI == AllUsers && (g.Permission == "READ" || g.Permission == "FULL_CONTROL") {
+			return true
+		}
+	}
+	return false
+}
+
+type xmlGrantee struct {
+	XMLNS        string `xml:"xmlns:xsi,attr"`
+	Type         string `xml:"xsi:type,attr"`
+	ID           string `xml:",omitempty"`
+	DisplayName  string `xml:",omitempty"`
+	EmailAddress string `xml:",omitempty"`
+	URI          string `xml:",omitempty"`
+}
+
+type xmlGrant struct {
+	Grantee    xmlGrantee
+	Permission string
+}
+
+// objectACL gets or replaces the ACL of an object, with ?acl.
+func (s *Server) objectACL(w http.ResponseWriter, r *http.Request, b *bucket, key string, payload []byte) {
+	o, ok := b.objects[key]
+	if !ok {
+		writeError(w, r, errNoSuchKey)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		result := struct {
+			XMLName xml.Name   `xml:"http://s3.amazonaws.com/doc/2006-03-01/ AccessControlPolicy"`
+			Owner   owner      `xml:"Owner"`
+			Grants  []xmlGrant `xml:"AccessControlList>Grant"`
+		}{Owner: testOwner}
+		grants := o.Grants
+		if grants == nil {
+			grants = []Grant{ownerGrant}
+		}
+		for _, g := range grants {
+			result.Grants = append(result.Grants, xmlGrant{
+				Grantee: xmlGrantee{
+					XMLNS: xsiNamespace, Type: g.GranteeType,
+					ID: g.ID, EmailAddress: g.EmailAddress, URI: g.URI,
+				},
+				Permission: g.Permission,
+			})
+		}
+		writeXML(w, http.StatusOK, result)
+	case http.MethodPut:
+		if err := checkContentMD5(r, payload); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		grants, err := requestACL(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if len(payload) > 0 {
+			if r.Header.Get("X-Amz-Acl") != "" {
+				writeError(w, r, errInvalidRequest.withMessage("Specifying both Canned ACLs and Header Grants is not allowed."))
+				return
+			}
+			if grants, err = parseACL(payload); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		o.Grants = grants
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, r, errMethodNotAllowed)
+	}
+}
+
+// parseACL parses the grants of an AccessControlPolicy.
+func parseACL(payload []byte) ([]Grant, *apiError) {
+	var policy struct {
+		XMLName xml.Name `xml:"AccessControlPolicy"`
+		Owner   owner
+		Grants  []struct {
+			Grantee struct {
+				Type         string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+				ID           string
+				EmailAddress string
+				URI          string
+			}
+			Permission string
+		} `xml:"AccessControlList>Grant"`
+	}
+	if xml.Unmarshal(payload, &policy) != nil {
+		return nil, errMalformedXML
+	}
+	if policy.Owner.ID != testOwner.ID {
+		return nil, errAccessDenied.withMessage("The owner of an object cannot be changed.")
+	}
+	grants := []Grant{}
+	for _, g := range policy.Grants {
+		switch g.Permission {
+		case "READ", "WRITE", "READ_ACP", "WRITE_ACP", "FULL_CONTROL":
+		default:
+			return nil, errMalformedXML
+		}
+		if g.Grantee.Type == "" {
+			return nil, errMalformedXML
+		}
+		grants = append(grants, Grant{
+			GranteeType:  g.Grantee.Type,
+			ID:           g.Grantee.ID,
+			EmailAddress: g.Grantee.EmailAddress,
+			URI:          g.Grantee.URI,
+			Permission:   g.Permission,
+		})
+	}
+	return grants, nil
+}
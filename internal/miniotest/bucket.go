@@ -0,0 +1,343 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package miniotest
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxListKeys is the most keys a listing returns per request.
+const maxListKeys = 1000
+
+func (s *Server) serveBucket(w http.ResponseWriter, r *http.Request, bucketName string, query url.Values, payload []byte) {
+	b, ok := s.buckets[bucketName]
+	switch r.Method {
+	case http.MethodPut:
+		if ok {
+			writeError(w, r, errBucketExists)
+			return
+		}
+		s.makeBucket(bucketName)
+		w.Header().Set("Location", "/"+bucketName)
+		w.WriteHeader(http.StatusOK)
+		return
+	case http.MethodHead, http.MethodGet, http.MethodDelete, http.MethodPost:
+	default:
+		writeError(w, r, errMethodNotAllowed)
+		return
+	}
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodDelete:
+		if len(b.objects) > 0 || len(s.bucketUploads(bucketName)) > 0 {
+			writeError(w, r, errBucketNotEmpty)
+			return
+		}
+		delete(s.buckets, bucketName)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && query.Has("delete"):
+		s.deleteObjects(w, r, b, payload)
+	case r.Method == http.MethodPost:
+		writeError(w, r, errMethodNotAllowed)
+	case query.Has("location"):
+		writeXML(w, http.StatusOK, struct {
+			XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LocationConstraint"`
+			Location string   `xml:",chardata"`
+		}{})
+	case query.Has("uploads"):
+		s.listUploads(w, r, bucketName, query)
+	case query.Get("list-type") == "2":
+		s.listObjectsV2(w, r, bucketName, b, query)
+	default:
+		s.listObjectsV1(w, r, bucketName, b, query)
+	}
+}
+
+func (b *bucket) sortedKeys() []string {
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// listing is one page of a listing.
+type listing struct {
+	objects   []*Object
+	prefixes  []string
+	truncated bool
+	// next is the last key or common prefix of the page, listing the
+	// next page starts after it.
+	next string
+}
+
+// list returns the objects and the common prefixes of the keys with
+// prefix after the key after, up to maxKeys of them.
+func (b *bucket) list(prefix, delimiter, after string, maxKeys int) (l listing) {
+	count := 0
+	for _, key := range b.sortedKeys() {
+		if key <= after || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		entry, isPrefix := key, false
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				entry, isPrefix = key[:len(prefix)+i+len(delimiter)], true
+				if entry <= after || (len(l.prefixes) > 0 && l.prefixes[len(l.prefixes)-1] == entry) {
+					continue
+				}
+			}
+		}
+		if count == maxKeys {
+			l.truncated = true
+			break
+		}
+		count++
+		l.next = entry
+		if isPrefix {
+			l.prefixes = append(l.prefixes, entry)
+		} else {
+			l.objects = append(l.objects, b.objects[key])
+		}
+	}
+	return l
+}
+
+// xmlUserMetadata marshals as elements named after the metadata, like
+// MinIO lists it with ?metadata=true.
+type xmlUserMetadata map[string]string
+
+func (m xmlUserMetadata) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if e := e.EncodeToken(start); e != nil {
+		return e
+	}
+	for _, k := range keys {
+		if e := e.EncodeElement(m[k], xml.StartElement{Name: xml.Name{Local: k}}); e != nil {
+			return e
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+type xmlContent struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+	Owner        *owner `xml:",omitempty"`
+	StorageClass string
+	UserMetadata xmlUserMetadata `xml:",omitempty"`
+}
+
+type xmlPrefix struct {
+	Prefix string
+}
+
+func listContents(l listing, fetchOwner, withMetadata bool) (contents []xmlContent, prefixes []xmlPrefix) {
+	for _, o := range l.objects {
+		c := xmlContent{
+			Key:          o.Key,
+			LastModified: formatTime(o.LastModified),
+			ETag:         `"` + o.ETag + `"`,
+			Size:         int64(len(o.Data)),
+			StorageClass: "STANDARD",
+		}
+		if fetchOwner {
+			c.Owner = &testOwner
+		}
+		if withMetadata {
+			c.UserMetadata = xmlUserMetadata{"content-type": o.ContentType}
+			for k, v := range o.UserMetadata {
+				c.UserMetadata[k] = v
+			}
+		}
+		contents = append(contents, c)
+	}
+	for _, p := range l.prefixes {
+		prefixes = append(prefixes, xmlPrefix{Prefix: p})
+	}
+	return contents, prefixes
+}
+
+func parseMaxKeys(query url.Values, name string, limit int) (int, *apiError) {
+	s := query.Get(name)
+	if s == "" {
+		return limit, nil
+	}
+	n, e := strconv.Atoi(s)
+	if e != nil || n < 0 {
+		return 0, errInvalidArgument.withMessage("Argument " + name + " must be a positive integer.")
+	}
+	if n > limit {
+		n = limit
+	}
+	return n, nil
+}
+
+// pageSize returns the largest number of keys of a listing page.
+func (s *Server) pageSize() int {
+	if s.MaxKeys > 0 && s.MaxKeys < maxListKeys {
+		return s.MaxKeys
+	}
+	return maxListKeys
+}
+
+func (s *Server) listObjectsV2(w http.ResponseWriter, r *http.Request, bucketName string, b *bucket, query url.Values) {
+	maxKeys, err := parseMaxKeys(query, "max-keys", s.pageSize())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	after := query.Get("start-after")
+	token := query.Get("continuation-token")
+	if token != "" {
+		decoded, e := base64.StdEncoding.DecodeString(token)
+		if e != nil {
+			writeError(w, r, errInvalidArgument.withMessage("The continuation token provided is incorrect."))
+			return
+		}
+		after = string(decoded)
+	}
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	l := b.list(prefix, delimiter, after, maxKeys)
+
+	result := struct {
+		XMLName               xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+		Name                  string
+		Prefix                string
+		StartAfter            string `xml:",omitempty"`
+		ContinuationToken     string `xml:",omitempty"`
+		NextContinuationToken string `xml:",omitempty"`
+		KeyCount              int
+		MaxKeys               int
+		Delimiter             string `xml:",omitempty"`
+		IsTruncated           bool
+		Contents              []xmlContent
+		CommonPrefixes        []xmlPrefix
+	}{
+		Name:              bucketName,
+		Prefix:            prefix,
+		StartAfter:        query.Get("start-after"),
+		ContinuationToken: token,
+		KeyCount:          len(l.objects) + len(l.prefixes),
+		MaxKeys:           maxKeys,
+		Delimiter:         delimiter,
+		IsTruncated:       l.truncated,
+	}
+	if l.truncated {
+		result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(l.next))
+	}
+	result.Contents, result.CommonPrefixes = listContents(l, query.Get("fetch-owner") == "true", query.Get("metadata") == "true" && !s.NoListMetadata)
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) listObjectsV1(w http.ResponseWriter, r *http.Request, bucketName string, b *bucket, query url.Values) {
+	maxKeys, err := parseMaxKeys(query, "max-keys", s.pageSize())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	prefix, delimiter, marker := query.Get("prefix"), query.Get("delimiter"), query.Get("marker")
+	l := b.list(prefix, delimiter, marker, maxKeys)
+
+	result := struct {
+		XMLName        xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+		Name           string
+		Prefix         string
+		Marker         string
+		NextMarker     string `xml:",omitempty"`
+		MaxKeys        int
+		Delimiter      string `xml:",omitempty"`
+		IsTruncated    bool
+		Contents       []xmlContent
+		CommonPrefixes []xmlPrefix
+	}{
+		Name:        bucketName,
+		Prefix:      prefix,
+		Marker:      marker,
+		MaxKeys:     maxKeys,
+		Delimiter:   delimiter,
+		IsTruncated: l.truncated,
+	}
+	if l.truncated {
+		result.NextMarker = l.next
+	}
+	result.Contents, result.CommonPrefixes = listContents(l, true, query.Get("metadata") == "true")
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request, b *bucket, payload []byte) {
+	if err := checkContentMD5(r, payload); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var request struct {
+		Quiet   bool
+		Objects []struct {
+			Key string
+		} `xml:"Object"`
+	}
+	if e := xml.Unmarshal(payload, &request); e != nil || len(request.Objects) == 0 {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+	type deleted struct {
+		Key string
+	}
+	type deleteError struct {
+		Key     string
+		Code    string
+		Message string
+	}
+	result := struct {
+		XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ DeleteResult"`
+		Deleted []deleted
+		Error   []deleteError
+	}{}
+	for _, o := range request.Objects {
+		if s.DeleteError != nil {
+			if code := s.DeleteError(o.Key); code != "" {
+				result.Error = append(result.Error, deleteError{Key: o.Key, Code: code, Message: code})
+				continue
+			}
+		}
+		delete(b.objects, o.Key)
+		if !request.Quiet {
+			result.Deleted = append(result.Deleted, deleted{Key: o.Key})
+		}
+	}
+	writeXML(w, http.StatusOK, result)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package miniotest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+// Fault is a response the server sends to a request instead of serving
+// it, such as an error to retry or a redirect.
+type Fault struct {
+	Status int
+	// Code and Message are those of the XML error body, no body is sent
+	// without Code.
+	Code    string
+	Message string
+	// Endpoint and Region are set in the error body of redirects and of
+	// requests signed for the wrong region.
+	Endpoint string
+	Region   string
+	// Header holds the headers of the response, such as Location or
+	// Retry-After.
+	Header http.Header
+}
+
+// SlowDown is the fault of a throttling server.
+func SlowDown() *Fault {
+	return &Fault{Status: http.StatusServiceUnavailable, Code: "SlowDown", Message: "Please reduce your request rate."}
+}
+
+// InternalError is the fault of a server failing a request it may serve
+// when retried.
+func InternalError() *Fault {
+	return &Fault{Status: http.StatusInternalServerError, Code: "InternalError", Message: "We encountered an internal error. Please try again."}
+}
+
+// PermanentRedirect is the fault of a bucket which must be addressed at
+// another endpoint.
+func PermanentRedirect(endpoint string) *Fault {
+	return &Fault{
+		Status:   http.StatusMovedPermanently,
+		Code:     "PermanentRedirect",
+		Message:  "The bucket you are attempting to access must be addressed using the specified endpoint.",
+		Endpoint: endpoint,
+	}
+}
+
+// TemporaryRedirect is the fault of a request redirected to location.
+func TemporaryRedirect(location string) *Fault {
+	return &Fault{
+		Status:  http.StatusTemporaryRedirect,
+		Code:    "TemporaryRedirect",
+		Message: "Please re-send this request to the specified temporary endpoint.",
+		Header:  http.Header{"Location": []string{location}},
+	}
+}
+
+// FailFirst returns a Faults function failing the first n requests of a
+// method with the fault returned by fault, any method if it is empty.
+func FailFirst(n int, method string, fault func() *Fault) func(Request) *Fault {
+	return func(r Request) *Fault {
+		if n == 0 || (method != "" && r.Method != method) {
+			return nil
+		}
+		n--
+		return fault()
+	}
+}
+
+// faultOf returns the fault a request is answered with, if any.
+func (s *Server) faultOf(r *http.Request) *Fault {
+	if s.Faults == nil {
+		return nil
+	}
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	return s.Faults(Request{Method: r.Method, Bucket: bucketName, Key: key, Query: r.URL.Query()})
+}
+
+func writeFault(w http.ResponseWriter, r *http.Request, f *Fault) {
+	for k, v := range f.Header {
+		w.Header()[k] = v
+	}
+	if f.Region != "" {
+		w.Header().Set("X-Amz-Bucket-Region", f.Region)
+	}
+	if f.Code == "" || r.Method == http.MethodHead {
+		w.WriteHeader(f.Status)
+		return
+	}
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	writeXML(w, f.Status, struct {
+		XMLName    xml.Name `xml:"Error"`
+		Code       string
+		Message    string
+		BucketName string `xml:",omitempty"`
+		Key        string `xml:",omitempty"`
+		Endpoint   string `xml:",omitempty"`
+		Region     string `xml:",omitempty"`
+		Resource   string
+		RequestID  string `xml:"RequestId"`
+		HostID     string `xml:"HostId"`
+	}{
+		Code:       f.Code,
+		Message:    f.Message,
+		BucketName: bucketName,
+		Key:        key,
+		Endpoint:   f.Endpoint,
+		Region:     f.Region,
+		Resource:   r.URL.Path,
+		RequestID:  w.Header().Get("X-Amz-Request-Id"),
+		HostID:     w.Header().Get("X-Amz-Id-2"),
+	})
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package miniotest
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPartNumber is the highest part number of a multipart upload.
+const maxPartNumber = 10000
+
+type upload struct {
+	bucket, key, id string
+	initiated       time.Time
+	contentType     string
+	userMetadata    map[string]string
+	parts           map[int]*part
+}
+
+type part struct {
+	data     []byte
+	etag     string
+	modified time.Time
+}
+
+// bucketUploads returns the uploads of a bucket sorted by key and ID.
+func (s *Server) bucketUploads(bucketName string) []*upload {
+	var uploads []*upload
+	for _, u := range s.uploads {
+		if u.bucket == bucketName {
+			uploads = append(uploads, u)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].key != uploads[j].key {
+			return uploads[i].key < uploads[j].key
+		}
+		return uploads[i].id < uploads[j].id
+	})
+	return uploads
+}
+
+// findUpload returns the upload of an object with an ID.
+func (s *Server) findUpload(bucketName, key, uploadID string) (*upload, *apiError) {
+	u, ok := s.uploads[uploadID]
+	if !ok || u.bucket != bucketName || u.key != key {
+		return nil, errNoSuchUpload
+	}
+	return u, nil
+}
+
+func (s *Server) newUpload(w http.ResponseWriter, r *http.Request, bucketName, key string) {
+	s.uploadID++
+	u := &upload{
+		bucket:    bucketName,
+		key:       key,
+		id:        fmt.Sprintf("miniotest-upload-%d", s.uploadID),
+		initiated: s.modTime(),
+		parts:     map[int]*part{},
+	}
+	u.contentType, u.userMetadata = requestMetadata(r)
+	s.uploads[u.id] = u
+	writeXML(w, http.StatusOK, struct {
+		XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InitiateMultipartUploadResult"`
+		Bucket   string
+		Key      string
+		UploadID string `xml:"UploadId"`
+	}{Bucket: bucketName, Key: key, UploadID: u.id})
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, bucketName, key string, query url.Values, payload []byte) {
+	u, err := s.findUpload(bucketName, key, query.Get("uploadId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	partNumber, e := strconv.Atoi(query.Get("partNumber"))
+	if e != nil || partNumber < 1 || partNumber > maxPartNumber {
+		writeError(w, r, errInvalidArgument.withMessage(fmt.Sprintf("Part number must be an integer between 1 and %d, inclusive.", maxPartNumber)))
+		return
+	}
+
+	isCopy := r.Header.Get("X-Amz-Copy-Source") != ""
+	if isCopy {
+		source, err := s.copySource(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		payload = source.Data
+		if rangeHeader := r.Header.Get("X-Amz-Copy-Source-Range"); rangeHeader != "" {
+			offset, length, ok, err := parseRange(rangeHeader, int64(len(source.Data)))
+			if err != nil || !ok {
+				writeError(w, r, errInvalidRange)
+				return
+			}
+			payload = source.Data[offset : offset+length]
+		}
+	} else if err := checkContentMD5(r, payload); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if !isCopy && s.CorruptPart != nil && s.CorruptPart(key, partNumber) && len(payload) > 0 {
+		payload = append([]byte(nil), payload...)
+		payload[0] ^= 0xff
+	}
+
+	sum := md5.Sum(payload)
+	p := &part{data: append([]byte(nil), payload...), etag: hex.EncodeToString(sum[:]), modified: s.modTime()}
+	u.parts[partNumber] = p
+	if isCopy {
+		writeXML(w, http.StatusOK, struct {
+			XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CopyPartResult"`
+			LastModified string
+			ETag         string
+		}{LastModified: formatTime(p.modified), ETag: `"` + p.etag + `"`})
+		return
+	}
+	w.Header().Set("ETag", `"`+p.etag+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request, b *bucket, bucketName, key, uploadID string, payload []byte) {
+	u, err := s.findUpload(bucketName, key, uploadID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var request struct {
+		Parts []struct {
+			PartNumber int
+			ETag       string
+		} `xml:"Part"`
+	}
+	if e := xml.Unmarshal(payload, &request); e != nil || len(request.Parts) == 0 {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+
+	var data []byte
+	etags := make([]string, len(request.Parts))
+	partSizes := make([]int64, len(request.Parts))
+	for i, requested := range request.Parts {
+		if i > 0 && requested.PartNumber <= request.Parts[i-1].PartNumber {
+			writeError(w, r, errInvalidPartOrder)
+			return
+		}
+		p, ok := u.parts[requested.PartNumber]
+		if !ok || strings.Trim(requested.ETag, `"`) != p.etag {
+			writeError(w, r, errInvalidPart)
+			return
+		}
+		if i < len(request.Parts)-1 && int64(len(p.data)) < s.MinPartSize {
+			writeError(w, r, errEntityTooSmall)
+			return
+		}
+		data = append(data, p.data...)
+		etags[i] = p.etag
+		partSizes[i] = int64(len(p.data))
+	}
+
+	o := &Object{
+		Key:          key,
+		Data:         data,
+		ETag:         multipartETag(etags),
+		LastModified: s.modTime(),
+		ContentType:  u.contentType,
+		UserMetadata: u.userMetadata,
+		PartSizes:    partSizes,
+	}
+	b.objects[key] = o
+	delete(s.uploads, uploadID)
+	writeXML(w, http.StatusOK, struct {
+		XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
+		Location string
+		Bucket   string
+		Key      string
+		ETag     string
+	}{Location: s.URL + "/" + bucketName + "/" + key, Bucket: bucketName, Key: key, ETag: `"` + o.ETag + `"`})
+}
+
+func (s *Server) abortUpload(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) {
+	if _, err := s.findUpload(bucketName, key, uploadID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	delete(s.uploads, uploadID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listParts(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string, query url.Values) {
+	u, err := s.findUpload(bucketName, key, uploadID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	maxParts, err := parseMaxKeys(query, "max-parts", maxListKeys)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+
+	type xmlPart struct {
+		PartNumber   int
+		LastModified string
+		ETag         string
+		Size         int64
+	}
+	result := struct {
+		XMLName              xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListPartsResult"`
+		Bucket               string
+		Key                  string
+		UploadID             string `xml:"UploadId"`
+		PartNumberMarker     int
+		NextPartNumberMarker int
+		MaxParts             int
+		IsTruncated          bool
+		Parts                []xmlPart `xml:"Part"`
+	}{Bucket: bucketName, Key: key, UploadID: uploadID, PartNumberMarker: marker, MaxParts: maxParts}
+
+	numbers := make([]int, 0, len(u.parts))
+	for n := range u.parts {
+		if n > marker {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	if len(numbers) > maxParts {
+		numbers, result.IsTruncated = numbers[:maxParts], true
+	}
+	for _, n := range numbers {
+		p := u.parts[n]
+		result.Parts = append(result.Parts, xmlPart{PartNumber: n, LastModified: formatTime(p.modified), ETag: `"` + p.etag + `"`, Size: int64(len(p.data))})
+		result.NextPartNumberMarker = n
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) listUploads(w http.ResponseWriter, r *http.Request, bucketName string, query url.Values) {
+	maxUploads, err := parseMaxKeys(query, "max-uploads", maxListKeys)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	prefix, keyMarker, idMarker := query.Get("prefix"), query.Get("key-marker"), query.Get("upload-id-marker")
+
+	type xmlUpload struct {
+		Key          string
+		UploadID     string `xml:"UploadId"`
+		Initiated    string
+		StorageClass string
+	}
+	result := struct {
+		XMLName            xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListMultipartUploadsResult"`
+		Bucket             string
+		KeyMarker          string
+		UploadIDMarker     string `xml:"UploadIdMarker"`
+		NextKeyMarker      string
+		NextUploadIDMarker string `xml:"NextUploadIdMarker"`
+		Prefix             string
+		MaxUploads         int
+		IsTruncated        bool
+		Uploads            []xmlUpload `xml:"Upload"`
+	}{Bucket: bucketName, KeyMarker: keyMarker, UploadIDMarker: idMarker, Prefix: prefix, MaxUploads: maxUploads}
+
+	for _, u := range s.bucketUploads(bucketName) {
+		if !strings.HasPrefix(u.key, prefix) || u.key < keyMarker || (u.key == keyMarker && u.id <= idMarker) {
+			continue
+		}
+		if len(result.Uploads) == maxUploads {
+			result.IsTruncated = true
+			break
+		}
+		result.Uploads = append(result.Uploads, xmlUpload{Key: u.key, UploadID: u.id, Initiated: formatTime(u.initiated), StorageClass: "STANDARD"})
+		result.NextKeyMarker, result.NextUploadIDMarker = u.key, u.id
+	}
+	writeXML(w, http.StatusOK, result)
+}
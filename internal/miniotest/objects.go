@@ -0,0 +1,305 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package miniotest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucketName, key string, query url.Values, payload []byte) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	uploadID := query.Get("uploadId")
+	switch {
+	case query.Has("acl"):
+		s.objectACL(w, r, b, key, payload)
+	case r.Method == http.MethodPut && uploadID != "":
+		s.uploadPart(w, r, bucketName, key, query, payload)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copyObject(w, r, b, key)
+	case r.Method == http.MethodPut:
+		s.putObjectRequest(w, r, b, key, payload)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.newUpload(w, r, bucketName, key)
+	case r.Method == http.MethodPost && uploadID != "":
+		s.completeUpload(w, r, b, bucketName, key, uploadID, payload)
+	case r.Method == http.MethodGet && uploadID != "":
+		s.listParts(w, r, bucketName, key, uploadID, query)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.getObject(w, r, b, key, query)
+	case r.Method == http.MethodDelete && uploadID != "":
+		s.abortUpload(w, r, bucketName, key, uploadID)
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, r, errMethodNotAllowed)
+	}
+}
+
+// checkContentMD5 verifies the Content-Md5 header of a request if any.
+func checkContentMD5(r *http.Request, payload []byte) *apiError {
+	contentMD5 := r.Header.Get("Content-Md5")
+	if contentMD5 == "" {
+		return nil
+	}
+	sum := md5.Sum(payload)
+	if base64.StdEncoding.EncodeToString(sum[:]) != contentMD5 {
+		return errBadDigest
+	}
+	return nil
+}
+
+// requestMetadata returns the content type and the user metadata sent
+// with a request.
+func requestMetadata(r *http.Request) (string, map[string]string) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	userMetadata := map[string]string{}
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			userMetadata[k] = strings.Join(v, ",")
+		}
+	}
+	return contentType, userMetadata
+}
+
+func (s *Server) putObjectRequest(w http.ResponseWriter, r *http.Request, b *bucket, key string, payload []byte) {
+	if err := checkContentMD5(r, payload); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	grants, err := requestACL(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if s.CorruptPut != nil && s.CorruptPut(key) && len(payload) > 0 {
+		payload = append([]byte(nil), payload...)
+		payload[0] ^= 0xff
+	}
+	sum := md5.Sum(payload)
+	o := &Object{
+		Key:          key,
+		Data:         payload,
+		ETag:         hex.EncodeToString(sum[:]),
+		LastModified: s.modTime(),
+		Grants:       grants,
+	}
+	o.ContentType, o.UserMetadata = requestMetadata(r)
+	b.objects[key] = o
+	w.Header().Set("ETag", `"`+o.ETag+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// copySource returns the object named by the X-Amz-Copy-Source header.
+func (s *Server) copySource(r *http.Request) (*Object, *apiError) {
+	source, e := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if e != nil {
+		return nil, errInvalidArgument.withMessage("Copy Source must mention the source bucket and key: sourcebucket/sourcekey.")
+	}
+	source, _, _ = strings.Cut(source, "?")
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if bucketName == "" || key == "" {
+		return nil, errInvalidArgument.withMessage("Copy Source must mention the source bucket and key: sourcebucket/sourcekey.")
+	}
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, errNoSuchBucket
+	}
+	o, ok := b.objects[key]
+	if !ok {
+		return nil, errNoSuchKey
+	}
+	if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); match != "" && strings.Trim(match, `"`) != o.ETag {
+		return nil, errPreconditionFailed
+	}
+	return o, nil
+}
+
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	if s.CopyError != nil {
+		if code := s.CopyError(key); code != "" {
+			writeError(w, r, &apiError{code, code, http.StatusForbidden})
+			return
+		}
+	}
+	source, err := s.copySource(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	replace := r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE"
+	// Copies encrypting the object, such as with another key, change it.
+	encrypt := r.Header.Get("X-Amz-Server-Side-Encryption") != "" || r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != ""
+	if source == b.objects[key] && !replace && !encrypt {
+		writeError(w, r, errInvalidRequest.withMessage("This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata."))
+		return
+	}
+	o := source.clone()
+	o.Key = key
+	o.LastModified = s.modTime()
+	if replace {
+		o.ContentType, o.UserMetadata = requestMetadata(r)
+	}
+	b.objects[key] = &o
+	writeXML(w, http.StatusOK, struct {
+		XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CopyObjectResult"`
+		LastModified string
+		ETag         string
+	}{LastModified: formatTime(o.LastModified), ETag: `"` + o.ETag + `"`})
+}
+
+// parseRange returns the offset and the length of the Range header of a
+// request for size bytes, and false without one.
+func parseRange(header string, size int64) (offset, length int64, ok bool, err *apiError) {
+	if header == "" {
+		return 0, size, false, nil
+	}
+	spec := strings.TrimPrefix(header, "bytes=")
+	start, end, dash := strings.Cut(spec, "-")
+	if spec == header || !dash || strings.Contains(spec, ",") {
+		// Invalid ranges are ignored like S3 does.
+		return 0, size, false, nil
+	}
+	if start == "" {
+		n, e := strconv.ParseInt(end, 10, 64)
+		if e != nil || n <= 0 {
+			return 0, 0, false, errInvalidRange
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, true, nil
+	}
+	first, e := strconv.ParseInt(start, 10, 64)
+	if e != nil || first >= size {
+		return 0, 0, false, errInvalidRange
+	}
+	last := size - 1
+	if end != "" {
+		if last, e = strconv.ParseInt(end, 10, 64); e != nil || last < first {
+			return 0, 0, false, errInvalidRange
+		}
+		if last >= size {
+			last = size - 1
+		}
+	}
+	return first, last - first + 1, true, nil
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, b *bucket, key string, query url.Values) {
+	o, ok := b.objects[key]
+	if !ok {
+		writeError(w, r, errNoSuchKey)
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" && strings.Trim(match, `"`) != o.ETag {
+		writeError(w, r, errPreconditionFailed)
+		return
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.Trim(match, `"`) == o.ETag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	size := int64(len(o.Data))
+	var (
+		offset, length int64
+		isRange        bool
+		err            *apiError
+	)
+	if query.Has("partNumber") {
+		offset, length, isRange, err = partRange(query.Get("partNumber"), o)
+	} else {
+		offset, length, isRange, err = parseRange(r.Header.Get("Range"), size)
+	}
+	if err != nil {
+		w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+		writeError(w, r, err)
+		return
+	}
+
+	h := w.Header()
+	h.Set("ETag", `"`+o.ETag+`"`)
+	h.Set("Last-Modified", o.LastModified.UTC().Format(http.TimeFormat))
+	h.Set("Content-Type", o.ContentType)
+	h.Set("Accept-Ranges", "bytes")
+	if len(o.PartSizes) > 0 {
+		h.Set("X-Amz-Mp-Parts-Count", strconv.Itoa(len(o.PartSizes)))
+	}
+	for k, v := range o.UserMetadata {
+		h.Set(k, v)
+	}
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	status := http.StatusOK
+	if isRange {
+		h.Set("Content-Range", "bytes "+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10)+"/"+strconv.FormatInt(size, 10))
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(o.Data[offset : offset+length])
+	}
+}
+
+// partRange returns the range of the part numbered partNumber of o, the
+// whole object being its only part unless it was uploaded in parts.
+func partRange(partNumber string, o *Object) (offset, length int64, isRange bool, err *apiError) {
+	n, e := strconv.Atoi(partNumber)
+	if e != nil || n < 1 {
+		return 0, 0, false, errInvalidArgument.withMessage("Part number must be an integer between 1 and 10000, inclusive")
+	}
+	if len(o.PartSizes) == 0 {
+		if n > 1 {
+			return 0, 0, false, errInvalidRange
+		}
+		return 0, int64(len(o.Data)), false, nil
+	}
+	if n > len(o.PartSizes) {
+		return 0, 0, false, errInvalidRange
+	}
+	for _, partSize := range o.PartSizes[:n-1] {
+		offset += partSize
+	}
+	return offset, o.PartSizes[n-1], true, nil
+}
+
+// multipartETag returns the ETag of a multipart object of parts with
+// etags, the MD5 sum of their MD5 sums followed by their number.
+func multipartETag(etags []string) string {
+	var sums bytes.Buffer
+	for _, etag := range etags {
+		sum, _ := hex.DecodeString(etag)
+		sums.Write(sum)
+	}
+	sum := md5.Sum(sums.Bytes())
+	return hex.EncodeToString(sum[:]) + "-" + strconv.Itoa(len(etags))
+}
@@ -0,0 +1,560 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package miniotest implements an in-memory S3 compatible server for
+// hermetic tests. It serves path-style requests signed with AWS
+// Signature Version 4 for objects, multipart uploads and listings, and
+// offers helpers to seed objects and to assert what was stored.
+package miniotest
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials and region the server accepts requests signed with.
+const (
+	AccessKey = "MINIOTESTACCESSKEY"
+	SecretKey = "miniotest/secret/key/for/tests"
+	Region    = "us-east-1"
+)
+
+// HostID is the X-Amz-Id-2 header of the responses of the server.
+const HostID = "miniotest"
+
+// Object is an object stored by the server.
+type Object struct {
+	Key          string
+	Data         []byte
+	ETag         string // without quotes
+	LastModified time.Time
+	ContentType  string
+	// UserMetadata is keyed by canonical header names, such as
+	// "X-Amz-Meta-Name".
+	UserMetadata map[string]string
+	// PartSizes are the sizes of the parts of a multipart object.
+	PartSizes []int64
+	// Grants are the ACL of the object, nil for the private ACL.
+	Grants []Grant
+}
+
+func (o *Object) clone() Object {
+	c := *o
+	c.Data = append([]byte(nil), o.Data...)
+	c.PartSizes = append([]int64(nil), o.PartSizes...)
+	if o.Grants != nil {
+		c.Grants = append([]Grant{}, o.Grants...)
+	}
+	c.UserMetadata = make(map[string]string, len(o.UserMetadata))
+	for k, v := range o.UserMetadata {
+		c.UserMetadata[k] = v
+	}
+	return c
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Bucket string
+	Key    string
+	Query  url.Values
+	// ID is the X-Amz-Request-Id header of the response.
+	ID string
+}
+
+type bucket struct {
+	created time.Time
+	objects map[string]*Object
+}
+
+// Server is an in-memory S3 compatible server, started by NewServer and
+// stopped with Close.
+type Server struct {
+	*httptest.Server
+
+	// MinPartSize is the smallest size of the parts of a multipart
+	// upload but the last one, S3 requires 5MiB. It is not enforced by
+	// default, set it before sending requests.
+	MinPartSize int64
+
+	// DeleteError returns the error code a multi-delete request reports
+	// for key instead of deleting it, or "" to delete it. It is called
+	// with the server locked, set it before sending requests.
+	DeleteError func(key string) string
+
+	// CopyError returns the error code a copy request to key fails with,
+	// with a 403 status which is not retried, or "" to copy it. It is
+	// called with the server locked, set it before sending requests.
+	CopyError func(key string) string
+
+	// CorruptPut returns true to store the object of a single PUT
+	// request to key with its first byte flipped, as if it was
+	// corrupted after its Content-Md5 was checked, the ETag being that
+	// of the stored bytes. It is called with the server locked, set it
+	// before sending requests.
+	CorruptPut func(key string) bool
+
+	// CorruptPart is CorruptPut for the parts of multipart uploads to
+	// key. It is called with the server locked, set it before sending
+	// requests.
+	CorruptPart func(key string, partNumber int) bool
+
+	// Faults returns the fault a request is answered with instead of
+	// being served, after its signature is verified, or nil to serve
+	// it. It is called with the server locked, set it before sending
+	// requests.
+	Faults func(r Request) *Fault
+
+	// MaxKeys is the largest number of keys of a page of an object
+	// listing, 1000 by default, lower it to test truncated listings.
+	MaxKeys int
+
+	// NoListMetadata ignores the metadata=true parameter of MinIO in
+	// listings, like AWS S3 does, so that the listed objects carry no
+	// metadata. Set it before sending requests.
+	NoListMetadata bool
+
+	// ClockSkew shifts the clock of the server, against which signed
+	// requests are verified and which is sent in the Date header of the
+	// responses. Set it before sending requests.
+	ClockSkew time.Duration
+
+	// Observe is called with every request before it is served, without
+	// the server locked, so that it may block to hold requests in
+	// flight. Set it before sending requests.
+	Observe func(r Request)
+
+	mu       sync.Mutex
+	now      func() time.Time
+	buckets  map[string]*bucket
+	uploads  map[string]*upload
+	uploadID int
+	requests []Request
+	// requestID numbers the requests, across calls to ResetRequests.
+	requestID int
+}
+
+// NewServer starts a server without buckets.
+func NewServer() *Server {
+	s := &Server{
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+		uploads: map[string]*upload{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// AliasURL returns the URL of the server with the credentials it
+// accepts, as set in an MC_HOST_<alias> environment variable.
+func (s *Server) AliasURL() string {
+	return "http://" + AccessKey + ":" + SecretKey + "@" + strings.TrimPrefix(s.URL, "http://")
+}
+
+// clock returns the time of the server.
+func (s *Server) clock() time.Time {
+	return s.now().Add(s.ClockSkew)
+}
+
+// modTime returns the modification time of an object stored now, to the
+// second like the Last-Modified header.
+func (s *Server) modTime() time.Time {
+	return s.clock().UTC().Truncate(time.Second)
+}
+
+// MakeBucket creates a bucket if it does not exist.
+func (s *Server) MakeBucket(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.makeBucket(name)
+}
+
+func (s *Server) makeBucket(name string) *bucket {
+	b, ok := s.buckets[name]
+	if !ok {
+		b = &bucket{created: s.modTime(), objects: map[string]*Object{}}
+		s.buckets[name] = b
+	}
+	return b
+}
+
+// PutObject stores an object with the ETag of a single part upload,
+// creating its bucket if needed. userMetadata is optional, alternating
+// names and values such as "X-Amz-Meta-Name", "value".
+func (s *Server) PutObject(bucketName, key string, data []byte, userMetadata ...string) Object {
+	sum := md5.Sum(data)
+	return s.putObject(bucketName, key, data, hex.EncodeToString(sum[:]), nil, userMetadata)
+}
+
+// PutMultipartObject stores the concatenation of parts with the ETag of
+// a multipart upload of these parts, creating its bucket if needed.
+func (s *Server) PutMultipartObject(bucketName, key string, parts ...[]byte) Object {
+	var data []byte
+	etags := make([]string, len(parts))
+	partSizes := make([]int64, len(parts))
+	for i, part := range parts {
+		data = append(data, part...)
+		sum := md5.Sum(part)
+		etags[i] = hex.EncodeToString(sum[:])
+		partSizes[i] = int64(len(part))
+	}
+	return s.putObject(bucketName, key, data, multipartETag(etags), partSizes, nil)
+}
+
+func (s *Server) putObject(bucketName, key string, data []byte, etag string, partSizes []int64, userMetadata []string) Object {
+	o := &Object{
+		Key:          key,
+		Data:         append([]byte(nil), data...),
+		ETag:         etag,
+		PartSizes:    partSizes,
+		ContentType:  "application/octet-stream",
+		UserMetadata: map[string]string{},
+	}
+	for i := 0; i+1 < len(userMetadata); i += 2 {
+		o.UserMetadata[http.CanonicalHeaderKey(userMetadata[i])] = userMetadata[i+1]
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o.LastModified = s.modTime()
+	s.makeBucket(bucketName).objects[key] = o
+	return o.clone()
+}
+
+// SetObject stores a copy of o as is, such as with an ETag which is not
+// that of its data, creating its bucket if needed.
+func (s *Server) SetObject(bucketName string, o Object) {
+	c := o.clone()
+	if c.ContentType == "" {
+		c.ContentType = "application/octet-stream"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.LastModified.IsZero() {
+		c.LastModified = s.modTime()
+	}
+	s.makeBucket(bucketName).objects[c.Key] = &c
+}
+
+// Object returns a copy of a stored object.
+func (s *Server) Object(bucketName, key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return Object{}, false
+	}
+	o, ok := b.objects[key]
+	if !ok {
+		return Object{}, false
+	}
+	return o.clone(), true
+}
+
+// Buckets returns the sorted names of the buckets.
+func (s *Server) Buckets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.buckets))
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Keys returns the sorted keys of the objects of a bucket.
+func (s *Server) Keys(bucketName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	return b.sortedKeys()
+}
+
+// Uploads returns the sorted keys of the multipart uploads in progress
+// in a bucket.
+func (s *Server) Uploads(bucketName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for _, u := range s.uploads {
+		if u.bucket == bucketName {
+			keys = append(keys, u.key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UploadParts returns copies of the data of the parts uploaded so far
+// to a multipart upload, keyed by part number, nil if there is no such
+// upload.
+func (s *Server) UploadParts(bucketName, key, uploadID string) map[int][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, err := s.findUpload(bucketName, key, uploadID)
+	if err != nil {
+		return nil
+	}
+	parts := make(map[int][]byte, len(u.parts))
+	for n, p := range u.parts {
+		parts[n] = append([]byte(nil), p.data...)
+	}
+	return parts
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestCount returns the number of requests received with a method.
+func (s *Server) RequestCount(method string) (n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.requests {
+		if r.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// ResetRequests forgets the requests received so far.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// ServeHTTP serves the S3 API for path-style requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	s.requestID++
+	req := Request{Method: r.Method, Bucket: bucketName, Key: key, Query: query, ID: fmt.Sprintf("MINIOTEST%d", s.requestID)}
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	if s.Observe != nil {
+		s.Observe(req)
+	}
+
+	w.Header().Set("X-Amz-Request-Id", req.ID)
+	w.Header().Set("X-Amz-Id-2", HostID)
+	w.Header().Set("Date", s.clock().UTC().Format(http.TimeFormat))
+	w.Header().Set("Server", "miniotest")
+
+	payload, err := s.authenticate(r)
+	if err != nil && !s.anonymousRead(r, bucketName, key) {
+		writeError(w, r, err)
+		return
+	}
+	if unsupported := unsupportedQuery(query); unsupported != "" {
+		writeError(w, r, errNotImplemented.withMessage("?"+unsupported+" is not implemented by miniotest"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f := s.faultOf(r); f != nil {
+		writeFault(w, r, f)
+		return
+	}
+	switch {
+	case bucketName == "" && r.Method == http.MethodGet:
+		s.listBuckets(w)
+	case bucketName == "":
+		writeError(w, r, errNotImplemented)
+	case key == "":
+		s.serveBucket(w, r, bucketName, query, payload)
+	default:
+		s.serveObject(w, r, bucketName, key, query, payload)
+	}
+}
+
+// anonymousRead reports whether r is an unsigned GET or HEAD of an
+// object whose ACL allows anonymous reads.
+func (s *Server) anonymousRead(r *http.Request, bucketName, key string) bool {
+	if r.Header.Get("Authorization") != "" || r.URL.RawQuery != "" || key == "" {
+		return false
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return false
+	}
+	o, ok := b.objects[key]
+	return ok && publicRead(o.Grants)
+}
+
+// supportedQuery are the query parameters of the implemented requests,
+// other subresources such as ?tagging or ?versioning are not.
+var supportedQuery = map[string]bool{
+	"list-type": true, "prefix": true, "delimiter": true, "max-keys": true,
+	"continuation-token": true, "start-after": true, "encoding-type": true,
+	"fetch-owner": true, "metadata": true, "marker": true,
+	"location": true, "delete": true, "acl": true,
+	"uploads": true, "uploadId": true, "partNumber": true, "max-uploads": true,
+	"key-marker": true, "upload-id-marker": true, "max-parts": true, "part-number-marker": true,
+	"x-id":            true,
+	"X-Amz-Algorithm": true, "X-Amz-Credential": true, "X-Amz-Date": true, "X-Amz-Expires": true,
+	"X-Amz-SignedHeaders": true, "X-Amz-Signature": true, "X-Amz-Security-Token": true,
+}
+
+func unsupportedQuery(query url.Values) string {
+	var unsupported []string
+	for k := range query {
+		if !supportedQuery[k] && !strings.HasPrefix(k, "response-") {
+			unsupported = append(unsupported, k)
+		}
+	}
+	sort.Strings(unsupported)
+	return strings.Join(unsupported, ",")
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	type xmlBucket struct {
+		Name         string
+		CreationDate string
+	}
+	result := struct {
+		XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListAllMyBucketsResult"`
+		Owner   owner
+		Buckets []xmlBucket `xml:"Buckets>Bucket"`
+	}{Owner: testOwner}
+	for _, name := range sortedBucketNames(s.buckets) {
+		result.Buckets = append(result.Buckets, xmlBucket{Name: name, CreationDate: formatTime(s.buckets[name].created)})
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func sortedBucketNames(buckets map[string]*bucket) []string {
+	names := make([]string, 0, len(buckets))
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type owner struct {
+	ID          string
+	DisplayName string
+}
+
+var testOwner = owner{ID: "miniotest", DisplayName: "miniotest"}
+
+// formatTime formats times in listings.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	data, e := xml.Marshal(v)
+	if e != nil {
+		http.Error(w, e.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// apiError is an S3 error response.
+type apiError struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e *apiError) withMessage(message string) *apiError {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+var (
+	errAccessDenied          = &apiError{"AccessDenied", "Access Denied.", http.StatusForbidden}
+	errBadDigest             = &apiError{"BadDigest", "The Content-Md5 you specified did not match what we received.", http.StatusBadRequest}
+	errBucketExists          = &apiError{"BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.", http.StatusConflict}
+	errBucketNotEmpty        = &apiError{"BucketNotEmpty", "The bucket you tried to delete is not empty.", http.StatusConflict}
+	errEntityTooSmall        = &apiError{"EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.", http.StatusBadRequest}
+	errIncompleteBody        = &apiError{"IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.", http.StatusBadRequest}
+	errInvalidAccessKeyID    = &apiError{"InvalidAccessKeyId", "The Access Key Id you provided does not exist in our records.", http.StatusForbidden}
+	errInvalidArgument       = &apiError{"InvalidArgument", "Invalid argument.", http.StatusBadRequest}
+	errInvalidPart           = &apiError{"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	errInvalidPartOrder      = &apiError{"InvalidPartOrder", "The list of parts was not in ascending order.", http.StatusBadRequest}
+	errInvalidRange          = &apiError{"InvalidRange", "The requested range is not satisfiable.", http.StatusRequestedRangeNotSatisfiable}
+	errInvalidRequest        = &apiError{"InvalidRequest", "Invalid request.", http.StatusBadRequest}
+	errMalformedXML          = &apiError{"MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.", http.StatusBadRequest}
+	errMethodNotAllowed      = &apiError{"MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed}
+	errNoSuchBucket          = &apiError{"NoSuchBucket", "The specified bucket does not exist.", http.StatusNotFound}
+	errNoSuchKey             = &apiError{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	errNoSuchUpload          = &apiError{"NoSuchUpload", "The specified multipart upload does not exist.", http.StatusNotFound}
+	errNotImplemented        = &apiError{"NotImplemented", "A header or query you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	errPreconditionFailed    = &apiError{"PreconditionFailed", "At least one of the preconditions you specified did not hold.", http.StatusPreconditionFailed}
+	errRequestTimeTooSkewed  = &apiError{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden}
+	errSignatureDoesNotMatch = &apiError{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	errContentSHA256Mismatch = &apiError{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+)
+
+func writeError(w http.ResponseWriter, r *http.Request, err *apiError) {
+	if r.Method == http.MethodHead {
+		// Responses to HEAD requests have no body.
+		w.WriteHeader(err.Status)
+		return
+	}
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	writeXML(w, err.Status, struct {
+		XMLName    xml.Name `xml:"Error"`
+		Code       string
+		Message    string
+		BucketName string `xml:",omitempty"`
+		Key        string `xml:",omitempty"`
+		Resource   string
+		RequestID  string `xml:"RequestId"`
+		HostID     string `xml:"HostId"`
+	}{
+		Code:       err.Code,
+		Message:    err.Message,
+		BucketName: bucketName,
+		Key:        key,
+		Resource:   r.URL.Path,
+		RequestID:  w.Header().Get("X-Amz-Request-Id"),
+		HostID:     w.Header().Get("X-Amz-Id-2"),
+	})
+}
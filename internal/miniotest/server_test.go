@@ -0,0 +1,469 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package miniotest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func newClient(t *testing.T, s *Server, creds *credentials.Credentials) *minio.Core {
+	t.Helper()
+	if creds == nil {
+		creds = credentials.NewStaticV4(AccessKey, SecretKey, "")
+	}
+	c, e := minio.NewCore(strings.TrimPrefix(s.URL, "http://"), &minio.Options{
+		Creds:  creds,
+		Region: Region,
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+	return c
+}
+
+func errorCode(e error) string {
+	return minio.ToErrorResponse(e).Code
+}
+
+func TestObjects(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := newClient(t, s, nil)
+	ctx := context.Background()
+
+	if e := c.MakeBucket(ctx, "bucket", minio.MakeBucketOptions{}); e != nil {
+		t.Fatal(e)
+	}
+	if e := c.MakeBucket(ctx, "bucket", minio.MakeBucketOptions{}); errorCode(e) != "BucketAlreadyOwnedByYou" {
+		t.Fatalf("expected BucketAlreadyOwnedByYou, got %v", e)
+	}
+
+	data := []byte("hello world")
+	info, e := c.Client.PutObject(ctx, "bucket", "dir/hello.txt", bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"Name": "value"},
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+	sum := md5.Sum(data)
+	if info.ETag != hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected ETag %x, got %s", sum, info.ETag)
+	}
+	stored, ok := s.Object("bucket", "dir/hello.txt")
+	if !ok || !bytes.Equal(stored.Data, data) || stored.ContentType != "text/plain" || stored.UserMetadata["X-Amz-Meta-Name"] != "value" {
+		t.Fatalf("unexpected stored object %+v", stored)
+	}
+
+	stat, e := c.Client.StatObject(ctx, "bucket", "dir/hello.txt", minio.StatObjectOptions{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if stat.Size != int64(len(data)) || stat.ETag != info.ETag || stat.UserMetadata["Name"] != "value" || stat.ContentType != "text/plain" {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+
+	opts := minio.GetObjectOptions{}
+	opts.SetRange(6, 10)
+	reader, _, _, e := c.GetObject(ctx, "bucket", "dir/hello.txt", opts)
+	if e != nil {
+		t.Fatal(e)
+	}
+	got, e := io.ReadAll(reader)
+	reader.Close()
+	if e != nil || string(got) != "world" {
+		t.Fatalf("expected range \"world\", got %q (%v)", got, e)
+	}
+
+	if _, e = c.CopyObject(ctx, "bucket", "dir/hello.txt", "bucket", "copy.txt", nil, minio.CopySrcOptions{}, minio.PutObjectOptions{}); e != nil {
+		t.Fatal(e)
+	}
+	if copied, ok := s.Object("bucket", "copy.txt"); !ok || copied.ETag != info.ETag || copied.UserMetadata["X-Amz-Meta-Name"] != "value" {
+		t.Fatalf("unexpected copy %+v", copied)
+	}
+	// A copy onto itself must change the object.
+	if _, e = c.CopyObject(ctx, "bucket", "copy.txt", "bucket", "copy.txt", nil, minio.CopySrcOptions{}, minio.PutObjectOptions{}); errorCode(e) != "InvalidRequest" {
+		t.Fatalf("expected InvalidRequest, got %v", e)
+	}
+	if _, e = c.CopyObject(ctx, "bucket", "copy.txt", "bucket", "copy.txt", map[string]string{"X-Amz-Server-Side-Encryption": "AES256"}, minio.CopySrcOptions{}, minio.PutObjectOptions{}); e != nil {
+		t.Fatal(e)
+	}
+
+	if e = c.RemoveObject(ctx, "bucket", "dir/hello.txt", minio.RemoveObjectOptions{}); e != nil {
+		t.Fatal(e)
+	}
+	if _, e = c.Client.StatObject(ctx, "bucket", "dir/hello.txt", minio.StatObjectOptions{}); errorCode(e) != "NoSuchKey" {
+		t.Fatalf("expected NoSuchKey, got %v", e)
+	}
+	if e = c.RemoveBucket(ctx, "bucket"); errorCode(e) != "BucketNotEmpty" {
+		t.Fatalf("expected BucketNotEmpty, got %v", e)
+	}
+	if got := s.Keys("bucket"); !reflect.DeepEqual(got, []string{"copy.txt"}) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	if n := s.RequestCount(http.MethodDelete); n != 2 {
+		t.Fatalf("expected 2 DELETE requests, got %d", n)
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.MinPartSize = 5 << 20
+	s.MakeBucket("bucket")
+	c := newClient(t, s, nil)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (11<<20)/16)
+	info, e := c.Client.PutObject(ctx, "bucket", "large", bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{PartSize: 5 << 20})
+	if e != nil {
+		t.Fatal(e)
+	}
+	want := s.PutMultipartObject("other", "large", data[:5<<20], data[5<<20:10<<20], data[10<<20:])
+	if info.ETag != want.ETag || !strings.HasSuffix(info.ETag, "-3") {
+		t.Fatalf("expected ETag %s, got %s", want.ETag, info.ETag)
+	}
+	if stored, _ := s.Object("bucket", "large"); !bytes.Equal(stored.Data, data) {
+		t.Fatal("unexpected data of the multipart object")
+	}
+
+	uploadID, e := c.NewMultipartUpload(ctx, "bucket", "parts", minio.PutObjectOptions{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	part1, e := c.PutObjectPart(ctx, "bucket", "parts", uploadID, 1, bytes.NewReader([]byte("small")), 5, minio.PutObjectPartOptions{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	part2, e := c.PutObjectPart(ctx, "bucket", "parts", uploadID, 2, bytes.NewReader([]byte("last")), 4, minio.PutObjectPartOptions{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	parts, e := c.ListObjectParts(ctx, "bucket", "parts", uploadID, 0, 1)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(parts.ObjectParts) != 1 || !parts.IsTruncated || parts.ObjectParts[0].Size != 5 {
+		t.Fatalf("unexpected parts %+v", parts)
+	}
+	uploads, e := c.ListMultipartUploads(ctx, "bucket", "", "", "", "", 10)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(uploads.Uploads) != 1 || uploads.Uploads[0].UploadID != uploadID {
+		t.Fatalf("unexpected uploads %+v", uploads)
+	}
+
+	complete := []minio.CompletePart{{PartNumber: 1, ETag: part1.ETag}, {PartNumber: 2, ETag: part2.ETag}}
+	if _, e = c.CompleteMultipartUpload(ctx, "bucket", "parts", uploadID, complete, minio.PutObjectOptions{}); errorCode(e) != "EntityTooSmall" {
+		t.Fatalf("expected EntityTooSmall, got %v", e)
+	}
+	if _, e = c.CompleteMultipartUpload(ctx, "bucket", "parts", uploadID, []minio.CompletePart{{PartNumber: 3, ETag: part1.ETag}}, minio.PutObjectOptions{}); errorCode(e) != "InvalidPart" {
+		t.Fatalf("expected InvalidPart, got %v", e)
+	}
+	s.MinPartSize = 0
+	if _, e = c.CompleteMultipartUpload(ctx, "bucket", "parts", uploadID, complete, minio.PutObjectOptions{}); e != nil {
+		t.Fatal(e)
+	}
+	if stored, _ := s.Object("bucket", "parts"); string(stored.Data) != "smalllast" || !strings.HasSuffix(stored.ETag, "-2") {
+		t.Fatalf("unexpected stored object %+v", stored)
+	}
+
+	uploadID, e = c.NewMultipartUpload(ctx, "bucket", "aborted", minio.PutObjectOptions{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if got := s.Uploads("bucket"); !reflect.DeepEqual(got, []string{"aborted"}) {
+		t.Fatalf("unexpected uploads %v", got)
+	}
+	if e = c.AbortMultipartUpload(ctx, "bucket", "aborted", uploadID); e != nil {
+		t.Fatal(e)
+	}
+	if got := s.Uploads("bucket"); len(got) != 0 {
+		t.Fatalf("unexpected uploads %v", got)
+	}
+
+	// A corrupted part gets the ETag of the stored bytes.
+	s.CorruptPart = func(key string, partNumber int) bool { return partNumber == 2 }
+	uploadID, e = c.NewMultipartUpload(ctx, "bucket", "corrupted", minio.PutObjectOptions{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	part2, e = c.PutObjectPart(ctx, "bucket", "corrupted", uploadID, 2, bytes.NewReader([]byte("last")), 4, minio.PutObjectPartOptions{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if sum := md5.Sum([]byte("last")); part2.ETag == hex.EncodeToString(sum[:]) {
+		t.Fatal("expected the part to be corrupted")
+	}
+	if parts := s.UploadParts("bucket", "corrupted", uploadID); len(parts) != 1 || string(parts[2]) == "last" {
+		t.Fatalf("unexpected parts %q", parts)
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	for _, key := range []string{"a", "b/1", "b/2", "c/d/1", "c/e", "d"} {
+		s.PutObject("bucket", key, []byte(key), "X-Amz-Meta-Key", key)
+	}
+	c := newClient(t, s, nil)
+	ctx := context.Background()
+
+	list := func(opts minio.ListObjectsOptions) (keys []string) {
+		t.Helper()
+		for o := range c.Client.ListObjects(ctx, "bucket", opts) {
+			if o.Err != nil {
+				t.Fatal(o.Err)
+			}
+			keys = append(keys, o.Key)
+		}
+		return keys
+	}
+	for _, testCase := range []struct {
+		opts minio.ListObjectsOptions
+		keys []string
+	}{
+		// minio-go returns the objects of a page before its common prefixes.
+		{minio.ListObjectsOptions{}, []string{"a", "d", "b/", "c/"}},
+		{minio.ListObjectsOptions{Recursive: true}, []string{"a", "b/1", "b/2", "c/d/1", "c/e", "d"}},
+		{minio.ListObjectsOptions{Recursive: true, MaxKeys: 1}, []string{"a", "b/1", "b/2", "c/d/1", "c/e", "d"}},
+		{minio.ListObjectsOptions{MaxKeys: 1}, []string{"a", "b/", "c/", "d"}},
+		{minio.ListObjectsOptions{Prefix: "c/"}, []string{"c/e", "c/d/"}},
+		{minio.ListObjectsOptions{Prefix: "c/", UseV1: true}, []string{"c/e", "c/d/"}},
+		{minio.ListObjectsOptions{Recursive: true, MaxKeys: 2, UseV1: true}, []string{"a", "b/1", "b/2", "c/d/1", "c/e", "d"}},
+		{minio.ListObjectsOptions{Recursive: true, StartAfter: "c/d/1"}, []string{"c/e", "d"}},
+	} {
+		if got := list(testCase.opts); !reflect.DeepEqual(got, testCase.keys) {
+			t.Errorf("%+v: expected %v, got %v", testCase.opts, testCase.keys, got)
+		}
+	}
+
+	for o := range c.Client.ListObjects(ctx, "bucket", minio.ListObjectsOptions{Prefix: "b/1", WithMetadata: true}) {
+		if o.Err != nil {
+			t.Fatal(o.Err)
+		}
+		if o.UserMetadata["X-Amz-Meta-Key"] != "b/1" || o.UserMetadata["content-type"] != "application/octet-stream" {
+			t.Fatalf("unexpected metadata %v", o.UserMetadata)
+		}
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PutObject("bucket", "object", []byte("data"))
+	ctx := context.Background()
+
+	for _, testCase := range []struct {
+		creds *credentials.Credentials
+		code  string
+	}{
+		{credentials.NewStaticV4(AccessKey, "wrong", ""), "SignatureDoesNotMatch"},
+		{credentials.NewStaticV4("UNKNOWN", SecretKey, ""), "InvalidAccessKeyId"},
+		{credentials.NewStaticV2(AccessKey, SecretKey, ""), "AccessDenied"},
+		{credentials.New(&credentials.Static{Value: credentials.Value{SignerType: credentials.SignatureAnonymous}}), "AccessDenied"},
+	} {
+		c := newClient(t, s, testCase.creds)
+		_, _, _, e := c.GetObject(ctx, "bucket", "object", minio.GetObjectOptions{})
+		if errorCode(e) != testCase.code {
+			t.Errorf("expected %s, got %v", testCase.code, e)
+		}
+	}
+
+	streaming := newClient(t, s, credentials.New(&credentials.Static{Value: credentials.Value{
+		AccessKeyID:     AccessKey,
+		SecretAccessKey: SecretKey,
+		SignerType:      credentials.SignatureV4Streaming,
+	}}))
+	data := bytes.Repeat([]byte("x"), 200<<10)
+	if _, e := streaming.Client.PutObject(ctx, "bucket", "streamed", bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{}); e != nil {
+		t.Fatal(e)
+	}
+	if stored, _ := s.Object("bucket", "streamed"); !bytes.Equal(stored.Data, data) {
+		t.Fatal("unexpected data of the streamed object")
+	}
+
+	c := newClient(t, s, nil)
+	presigned, e := c.Client.PresignedGetObject(ctx, "bucket", "object", time.Minute, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	resp, e := http.Get(presigned.String())
+	if e != nil {
+		t.Fatal(e)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "data" {
+		t.Fatalf("unexpected presigned response %d %q", resp.StatusCode, body)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	resp, e = http.Get(presigned.String())
+	if e != nil {
+		t.Fatal(e)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected an expired presigned URL to be denied, got %d", resp.StatusCode)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	defer func(unit time.Duration) { minio.DefaultRetryUnit = unit }(minio.DefaultRetryUnit)
+	minio.DefaultRetryUnit = time.Millisecond
+	s := NewServer()
+	defer s.Close()
+	s.PutObject("bucket", "object", []byte("data"))
+	c := newClient(t, s, nil)
+	ctx := context.Background()
+
+	// Retryable faults are retried by the client.
+	s.Faults = FailFirst(2, http.MethodGet, SlowDown)
+	if _, _, _, e := c.GetObject(ctx, "bucket", "object", minio.GetObjectOptions{}); e != nil {
+		t.Fatal(e)
+	}
+	if n := s.RequestCount(http.MethodGet); n != 3 {
+		t.Fatalf("expected 3 GET requests, got %d", n)
+	}
+
+	for _, testCase := range []struct {
+		fault *Fault
+		code  string
+	}{
+		{PermanentRedirect("bucket.s3.example.com"), "PermanentRedirect"},
+		{TemporaryRedirect("http://bucket.s3.example.com/object"), "TemporaryRedirect"},
+		{&Fault{Status: http.StatusForbidden, Code: "QuotaExceeded", Message: "Bucket quota exceeded."}, "QuotaExceeded"},
+	} {
+		s.Faults = func(Request) *Fault { return testCase.fault }
+		_, _, _, e := c.GetObject(ctx, "bucket", "object", minio.GetObjectOptions{})
+		if errorCode(e) != testCase.code {
+			t.Errorf("expected %s, got %v", testCase.code, e)
+		}
+		requests := s.Requests()
+		if resp := minio.ToErrorResponse(e); resp.RequestID != requests[len(requests)-1].ID || resp.HostID != HostID {
+			t.Errorf("unexpected request IDs %q %q", resp.RequestID, resp.HostID)
+		}
+	}
+
+	// Signatures are verified before faults are injected.
+	s.Faults = func(Request) *Fault { return InternalError() }
+	wrong := newClient(t, s, credentials.NewStaticV4(AccessKey, "wrong", ""))
+	if _, _, _, e := wrong.GetObject(ctx, "bucket", "object", minio.GetObjectOptions{}); errorCode(e) != "SignatureDoesNotMatch" {
+		t.Fatalf("expected SignatureDoesNotMatch, got %v", e)
+	}
+}
+
+func TestMaxKeys(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		s.PutObject("bucket", key, []byte(key))
+	}
+	s.MaxKeys = 2
+	c := newClient(t, s, nil)
+
+	var keys []string
+	for o := range c.Client.ListObjects(context.Background(), "bucket", minio.ListObjectsOptions{Recursive: true}) {
+		if o.Err != nil {
+			t.Fatal(o.Err)
+		}
+		keys = append(keys, o.Key)
+	}
+	if strings.Join(keys, ",") != "a,b,c,d,e" {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if n := s.RequestCount(http.MethodGet); n != 3 {
+		t.Fatalf("expected 3 pages, got %d", n)
+	}
+}
+
+func TestListMetadata(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PutObject("bucket", "object", []byte("data"), "X-Amz-Meta-Name", "value")
+	c := newClient(t, s, nil)
+
+	var observed []string
+	s.Observe = func(r Request) { observed = append(observed, r.Method+" "+r.Key) }
+	for _, noListMetadata := range []bool{false, true} {
+		s.NoListMetadata = noListMetadata
+		for o := range c.Client.ListObjects(context.Background(), "bucket", minio.ListObjectsOptions{WithMetadata: true}) {
+			if o.Err != nil {
+				t.Fatal(o.Err)
+			}
+			if got := o.UserMetadata["X-Amz-Meta-Name"]; (got == "value") == noListMetadata {
+				t.Fatalf("unexpected metadata %v without listing metadata %v", o.UserMetadata, noListMetadata)
+			}
+		}
+	}
+	if strings.Join(observed, ",") != "GET ,GET " {
+		t.Fatalf("unexpected observed requests %v", observed)
+	}
+}
+
+func TestSetObject(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetObject("bucket", Object{Key: "object", Data: []byte("data"), ETag: "faked"})
+	c := newClient(t, s, nil)
+
+	opts := minio.GetObjectOptions{}
+	opts.SetMatchETag("faked")
+	r, info, _, e := c.GetObject(context.Background(), "bucket", "object", opts)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer r.Close()
+	if data, _ := io.ReadAll(r); string(data) != "data" || info.ETag != "faked" {
+		t.Fatalf("unexpected object %q with ETag %s", data, info.ETag)
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.MakeBucket("bucket")
+	s.ClockSkew = 2 * time.Hour
+	c := newClient(t, s, nil)
+
+	if _, _, _, e := c.GetObject(context.Background(), "bucket", "object", minio.GetObjectOptions{}); errorCode(e) != "RequestTimeTooSkewed" {
+		t.Fatalf("expected RequestTimeTooSkewed, got %v", e)
+	}
+	resp, e := http.Get(s.URL + "/bucket")
+	if e != nil {
+		t.Fatal(e)
+	}
+	resp.Body.Close()
+	if date, e := http.ParseTime(resp.Header.Get("Date")); e != nil || time.Until(date) < time.Hour {
+		t.Fatalf("expected the date of the skewed clock, got %q", resp.Header.Get("Date"))
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package miniotest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+)
+
+const (
+	signV4Algorithm = "AWS4-HMAC-SHA256"
+	iso8601Format   = "20060102T150405Z"
+	yyyymmdd        = "20060102"
+
+	unsignedPayload          = "UNSIGNED-PAYLOAD"
+	streamingPayload         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingPayloadTrailer  = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	streamingUnsignedTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	emptySHA256              = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// maxClockSkew is how far the date of a request may be from the
+	// clock of the server.
+	maxClockSkew = 15 * time.Minute
+)
+
+// signature is the AWS Signature Version 4 of a request.
+type signature struct {
+	date          time.Time
+	scope         string
+	signedHeaders []string
+	signature     string
+}
+
+// signingKey derives the key signing the requests of a day.
+func (sig signature) signingKey() []byte {
+	key := sumHMAC([]byte("AWS4"+SecretKey), []byte(sig.date.Format(yyyymmdd)))
+	key = sumHMAC(key, []byte(Region))
+	key = sumHMAC(key, []byte("s3"))
+	return sumHMAC(key, []byte("aws4_request"))
+}
+
+func (sig signature) sign(stringToSign string) string {
+	return hex.EncodeToString(sumHMAC(sig.signingKey(), []byte(stringToSign)))
+}
+
+func sumHMAC(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func sum256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// parseCredential parses "ACCESSKEY/20060102/REGION/s3/aws4_request".
+func parseCredential(sig *signature, credential, date string) *apiError {
+	fields := strings.Split(credential, "/")
+	if len(fields) != 5 || fields[3] != "s3" || fields[4] != "aws4_request" {
+		return errAccessDenied.withMessage("Invalid credential " + credential + ".")
+	}
+	if fields[0] != AccessKey {
+		return errInvalidAccessKeyID
+	}
+	t, e := time.Parse(iso8601Format, date)
+	if e != nil || fields[1] != t.Format(yyyymmdd) {
+		return errAccessDenied.withMessage("Invalid date " + date + " of credential " + credential + ".")
+	}
+	if fields[2] != Region {
+		return errAccessDenied.withMessage("Invalid region " + fields[2] + ", expecting " + Region + ".")
+	}
+	sig.date, sig.scope = t, strings.Join(fields[1:], "/")
+	return nil
+}
+
+// parseAuthorization parses the Authorization header of a request.
+func parseAuthorization(r *http.Request) (signature, *apiError) {
+	var sig signature
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return sig, errAccessDenied.withMessage("Anonymous requests are not allowed.")
+	}
+	if !strings.HasPrefix(auth, signV4Algorithm+" ") {
+		return sig, errAccessDenied.withMessage("Only AWS Signature Version 4 is supported.")
+	}
+	fields := map[string]string{}
+	for _, field := range strings.Split(strings.TrimPrefix(auth, signV4Algorithm+" "), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(field), "=")
+		fields[k] = v
+	}
+	date := r.Header.Get("X-Amz-Date")
+	if err := parseCredential(&sig, fields["Credential"], date); err != nil {
+		return sig, err
+	}
+	sig.signedHeaders = strings.Split(fields["SignedHeaders"], ";")
+	sig.signature = fields["Signature"]
+	return sig, nil
+}
+
+// parsePresigned parses the signature of a presigned URL.
+func parsePresigned(query url.Values, now time.Time) (signature, *apiError) {
+	var sig signature
+	if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+		return sig, errAccessDenied.withMessage("Only AWS Signature Version 4 is supported.")
+	}
+	if err := parseCredential(&sig, query.Get("X-Amz-Credential"), query.Get("X-Amz-Date")); err != nil {
+		return sig, err
+	}
+	expires, e := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if e != nil || expires < 0 {
+		return sig, errAccessDenied.withMessage("X-Amz-Expires should be a number of seconds.")
+	}
+	if now.After(sig.date.Add(time.Duration(expires) * time.Second)) {
+		return sig, errAccessDenied.withMessage("Request has expired.")
+	}
+	sig.signedHeaders = strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+	sig.signature = query.Get("X-Amz-Signature")
+	return sig, nil
+}
+
+// canonicalRequest returns the canonical form of a request which is
+// signed, as defined by AWS Signature Version 4.
+func canonicalRequest(r *http.Request, query url.Values, signedHeaders []string, payloadHash string) string {
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		headers.WriteString(name)
+		headers.WriteByte(':')
+		switch name {
+		case "host":
+			headers.WriteString(r.Host)
+		case "content-length":
+			contentLength := r.Header.Get("Content-Length")
+			if contentLength == "" {
+				contentLength = strconv.FormatInt(r.ContentLength, 10)
+			}
+			headers.WriteString(contentLength)
+		default:
+			values := r.Header.Values(name)
+			for i, v := range values {
+				if i > 0 {
+					headers.WriteByte(',')
+				}
+				headers.WriteString(strings.Join(strings.Fields(v), " "))
+			}
+		}
+		headers.WriteByte('\n')
+	}
+	return strings.Join([]string{
+		r.Method,
+		s3utils.EncodePath(r.URL.Path),
+		strings.ReplaceAll(query.Encode(), "+", "%20"),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func (sig signature) stringToSign(canonical string) string {
+	return strings.Join([]string{signV4Algorithm, sig.date.Format(iso8601Format), sig.scope, sum256([]byte(canonical))}, "\n")
+}
+
+// authenticate verifies the signature of a request and returns its
+// payload, decoded if it was sent in signed chunks.
+func (s *Server) authenticate(r *http.Request) ([]byte, *apiError) {
+	query := r.URL.Query()
+	if query.Has("X-Amz-Signature") {
+		sig, err := parsePresigned(query, s.clock())
+		if err != nil {
+			return nil, err
+		}
+		query.Del("X-Amz-Signature")
+		canonical := canonicalRequest(r, query, sig.signedHeaders, unsignedPayload)
+		if !hmac.Equal([]byte(sig.sign(sig.stringToSign(canonical))), []byte(sig.signature)) {
+			return nil, errSignatureDoesNotMatch
+		}
+		payload, e := io.ReadAll(r.Body)
+		if e != nil {
+			return nil, errIncompleteBody
+		}
+		return payload, nil
+	}
+
+	sig, err := parseAuthorization(r)
+	if err != nil {
+		return nil, err
+	}
+	if skew := s.clock().Sub(sig.date); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, errRequestTimeTooSkewed
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		return nil, errInvalidRequest.withMessage("Missing required header for this request: x-amz-content-sha256.")
+	}
+	canonical := canonicalRequest(r, query, sig.signedHeaders, payloadHash)
+	seed := sig.sign(sig.stringToSign(canonical))
+	if !hmac.Equal([]byte(seed), []byte(sig.signature)) {
+		return nil, errSignatureDoesNotMatch
+	}
+
+	payload, e := io.ReadAll(r.Body)
+	if e != nil {
+		return nil, errIncompleteBody
+	}
+	switch payloadHash {
+	case unsignedPayload:
+	case streamingPayload, streamingPayloadTrailer:
+		if payload, err = decodeChunks(payload, sig, seed); err != nil {
+			return nil, err
+		}
+	case streamingUnsignedTrailer:
+		if payload, err = decodeChunks(payload, sig, ""); err != nil {
+			return nil, err
+		}
+	default:
+		if sum256(payload) != payloadHash {
+			return nil, errContentSHA256Mismatch
+		}
+	}
+	if decoded := r.Header.Get("X-Amz-Decoded-Content-Length"); decoded != "" && decoded != strconv.Itoa(len(payload)) {
+		return nil, errIncompleteBody
+	}
+	return payload, nil
+}
+
+// decodeChunks decodes an aws-chunked payload, verifying the signature
+// of every chunk chained to the seed signature unless it is empty. The
+// trailers following the last chunk are ignored.
+func decodeChunks(encoded []byte, sig signature, seed string) ([]byte, *apiError) {
+	var payload []byte
+	previous := seed
+	for {
+		header, rest, ok := bytes.Cut(encoded, []byte("\r\n"))
+		if !ok {
+			return nil, errIncompleteBody
+		}
+		sizeHex, params, _ := strings.Cut(string(header), ";")
+		size, e := strconv.ParseInt(sizeHex, 16, 64)
+		if e != nil || size < 0 || int64(len(rest)) < size {
+			return nil, errIncompleteBody
+		}
+		chunk := rest[:size]
+		if seed != "" {
+			chunkSignature := strings.TrimPrefix(params, "chunk-signature=")
+			expected := sig.sign(strings.Join([]string{
+				"AWS4-HMAC-SHA256-PAYLOAD",
+				sig.date.Format(iso8601Format),
+				sig.scope,
+				previous,
+				emptySHA256,
+				sum256(chunk),
+			}, "\n"))
+			if !hmac.Equal([]byte(expected), []byte(chunkSignature)) {
+				return nil, errSignatureDoesNotMatch
+			}
+			previous = chunkSignature
+		}
+		if size == 0 {
+			return payload, nil
+		}
+		payload = append(payload, chunk...)
+		encoded = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+}
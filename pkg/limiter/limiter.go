@@ -15,28 +15,79 @@
 // You should have received a copy of the GNU Affero General Public License
 // along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
-// Package limiter implements throughput upload and download limits via http.RoundTripper
+// Package limiter implements throughput upload and download limits and
+// request rate limits via http.RoundTripper
 package limiter
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/juju/ratelimit"
 )
 
+// Bucket is a token bucket of bytes, share a single bucket between
+// transports to enforce one rate across all of them.
+type Bucket = ratelimit.Bucket
+
+// NewBucket returns a bucket allowing rate bytes per second with bursts
+// of up to a second, nil if rate is not positive.
+func NewBucket(rate int64) *Bucket {
+	if rate <= 0 {
+		return nil
+	}
+	return ratelimit.NewBucketWithRate(float64(rate), rate)
+}
+
+// maxReadChunk is the most bytes read at once from a limited body, so
+// that the bytes are handed out, and counted by progress bars, at the
+// rate of the bucket rather than in bursts.
+const maxReadChunk = 32 << 10
+
+type limitedReader struct {
+	ctx    context.Context
+	r      io.Reader
+	bucket *Bucket
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	chunk := int64(maxReadChunk)
+	if capacity := l.bucket.Capacity(); capacity < chunk {
+		chunk = capacity
+	}
+	if int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+	n, err := l.r.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+	if wait := l.bucket.Take(int64(n)); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-l.ctx.Done():
+			timer.Stop()
+			return n, l.ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return n, err
+}
+
 type limiter struct {
-	upload    *ratelimit.Bucket
-	download  *ratelimit.Bucket
+	upload    *Bucket
+	download  *Bucket
 	transport http.RoundTripper // HTTP transport that needs to be intercepted
 }
 
-func (l limiter) limitReader(r io.Reader, b *ratelimit.Bucket) io.Reader {
+func (l limiter) limitReader(ctx context.Context, r io.Reader, b *Bucket) io.Reader {
 	if b == nil {
 		return r
 	}
-	return ratelimit.Reader(r, b)
+	return &limitedReader{ctx: ctx, r: r, bucket: b}
 }
 
 // RoundTrip executes user provided request and response hooks for each HTTP call.
@@ -50,9 +101,9 @@ func (l limiter) RoundTrip(req *http.Request) (res *http.Response, err error) {
 		io.Closer
 	}
 
-	if req.Body != nil {
+	if req.Body != nil && req.Body != http.NoBody {
 		req.Body = &readCloser{
-			Reader: l.limitReader(req.Body, l.upload),
+			Reader: l.limitReader(req.Context(), req.Body, l.upload),
 			Closer: req.Body,
 		}
 	}
@@ -60,7 +111,7 @@ func (l limiter) RoundTrip(req *http.Request) (res *http.Response, err error) {
 	res, err = l.transport.RoundTrip(req)
 	if res != nil && res.Body != nil {
 		res.Body = &readCloser{
-			Reader: l.limitReader(res.Body, l.download),
+			Reader: l.limitReader(req.Context(), res.Body, l.download),
 			Closer: res.Body,
 		}
 	}
@@ -68,28 +119,17 @@ func (l limiter) RoundTrip(req *http.Request) (res *http.Response, err error) {
 	return res, err
 }
 
-// New return a ratelimited transport
-func New(uploadLimit, downloadLimit int64, transport http.RoundTripper) http.RoundTripper {
-	if uploadLimit == 0 && downloadLimit == 0 {
+// New returns a transport limiting the bodies of the requests to the rate
+// of upload and the bodies of the responses to the rate of download,
+// either may be nil for no limit. Waiting for the buckets is aborted when
+// the context of the request is canceled.
+func New(upload, download *Bucket, transport http.RoundTripper) http.RoundTripper {
+	if upload == nil && download == nil {
 		return transport
 	}
-
-	var (
-		uploadBucket   *ratelimit.Bucket
-		downloadBucket *ratelimit.Bucket
-	)
-
-	if uploadLimit > 0 {
-		uploadBucket = ratelimit.NewBucketWithRate(float64(uploadLimit), uploadLimit)
-	}
-
-	if downloadLimit > 0 {
-		downloadBucket = ratelimit.NewBucketWithRate(float64(downloadLimit), downloadLimit)
-	}
-
 	return &limiter{
-		upload:    uploadBucket,
-		download:  downloadBucket,
+		upload:    upload,
+		download:  download,
 		transport: transport,
 	}
 }
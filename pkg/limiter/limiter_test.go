@@ -0,0 +1,116 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package limiter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterSharedDownload(t *testing.T) {
+	const rate = 64 << 10
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(make([]byte, rate)))}, nil
+	})
+
+	// Two transports sharing one bucket, like the clients of a mirror
+	// downloading two objects at once.
+	download := NewBucket(rate)
+	transports := []http.RoundTripper{New(nil, download, base), New(nil, download, base)}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, transport := range transports {
+		wg.Add(1)
+		go func(transport http.RoundTripper) {
+			defer wg.Done()
+			res, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if n, err := io.Copy(io.Discard, res.Body); err != nil || n != rate {
+				t.Errorf("expected %d bytes, got %d: %v", rate, n, err)
+			}
+		}(transport)
+	}
+	wg.Wait()
+
+	// The burst serves one second of the rate, the second at the rate.
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+		t.Fatalf("2 bodies of a second of the rate took only %s", elapsed)
+	}
+}
+
+func TestLimiterIndependentBuckets(t *testing.T) {
+	const rate = 64 << 10
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if _, err := io.Copy(io.Discard, req.Body); err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(make([]byte, rate)))}, nil
+	})
+	transport := New(NewBucket(rate), NewBucket(rate), base)
+
+	// The upload does not take the tokens of the download.
+	start := time.Now()
+	res, err := transport.RoundTrip(httptest.NewRequest(http.MethodPut, "http://localhost/bucket/object", bytes.NewReader(make([]byte, rate))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.Copy(io.Discard, res.Body); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("an upload and a download within their bursts took %s", elapsed)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(make([]byte, 1<<20)))}, nil
+	})
+	transport := New(nil, NewBucket(1<<10), base)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	res, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil).WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the body takes about 17 minutes at 1KiB/s.
+	start := time.Now()
+	n, err := io.Copy(io.Discard, res.Body)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if n > 2<<10 {
+		t.Fatalf("expected at most the burst to be read, got %d bytes", n)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("canceled download waited %s for tokens", elapsed)
+	}
+}
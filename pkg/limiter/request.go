@@ -0,0 +1,79 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package limiter
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/juju/ratelimit"
+)
+
+// RequestBucket is a token bucket of requests, share a single bucket
+// between transports to enforce one rate across all of them.
+type RequestBucket = ratelimit.Bucket
+
+// NewRequestBucket returns a bucket allowing rate requests per second
+// with bursts of up to burst requests.
+func NewRequestBucket(rate float64, burst int64) *RequestBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return ratelimit.NewBucketWithRate(rate, burst)
+}
+
+type requestLimiter struct {
+	bucket    *RequestBucket
+	onDelay   func(req *http.Request, wait time.Duration)
+	transport http.RoundTripper // HTTP transport that needs to be intercepted
+}
+
+func (l requestLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if l.transport == nil {
+		return nil, errors.New("Invalid Argument")
+	}
+
+	if wait := l.bucket.Take(1); wait > 0 {
+		if l.onDelay != nil {
+			l.onDelay(req, wait)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	return l.transport.RoundTrip(req)
+}
+
+// NewRequestLimiter delays the start of every request until a token is
+// available in bucket, onDelay (if any) is called before each wait.
+// Waiting is aborted when the context of the request is canceled.
+func NewRequestLimiter(bucket *RequestBucket, onDelay func(req *http.Request, wait time.Duration), transport http.RoundTripper) http.RoundTripper {
+	if bucket == nil {
+		return transport
+	}
+	return &requestLimiter{
+		bucket:    bucket,
+		onDelay:   onDelay,
+		transport: transport,
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package limiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRequestLimiterRate(t *testing.T) {
+	var sent int32
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		atomic.AddInt32(&sent, 1)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	// Two transports sharing one bucket, like two clients of one command.
+	bucket := NewRequestBucket(20, 2)
+	var delayed int32
+	onDelay := func(*http.Request, time.Duration) { atomic.AddInt32(&delayed, 1) }
+	transports := []http.RoundTripper{
+		NewRequestLimiter(bucket, onDelay, base),
+		NewRequestLimiter(bucket, onDelay, base),
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+			if _, err := transports[i%2].RoundTrip(req); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 2 requests are served by the burst, the other 6 at 20 req/s.
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Fatalf("8 requests at 20 req/s with a burst of 2 took only %s", elapsed)
+	}
+	if sent != 8 {
+		t.Fatalf("expected 8 requests to be sent, got %d", sent)
+	}
+	if delayed != 6 {
+		t.Fatalf("expected 6 requests to be delayed, got %d", delayed)
+	}
+}
+
+func TestRequestLimiterCancel(t *testing.T) {
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	transport := NewRequestLimiter(NewRequestBucket(0.01, 1), nil, base)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next token is 100s away, cancellation must not wait for it.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := transport.RoundTrip(req.WithContext(ctx))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("canceled request waited %s for a token", elapsed)
+	}
+}